@@ -0,0 +1,96 @@
+// Package clock provides the Clock used by the clue packages to measure
+// durations and schedule work as well as a Fake clock for tests.
+package clock
+
+import (
+	"context"
+	"time"
+)
+
+type (
+	// Clock provides the current time, durations and timers. Clue packages
+	// that measure durations or schedule work use the clock given via
+	// their options or stored in the context (see Context) so that tests
+	// can control time with a Fake clock.
+	Clock interface {
+		// Now returns the current time.
+		Now() time.Time
+		// Since returns the time elapsed since t.
+		Since(t time.Time) time.Duration
+		// NewTimer creates a timer that fires after d.
+		NewTimer(d time.Duration) Timer
+		// NewTicker creates a ticker that fires every d.
+		NewTicker(d time.Duration) Ticker
+	}
+
+	// Timer is the interface implemented by clock timers, see time.Timer.
+	Timer interface {
+		// C returns the channel on which the time is delivered.
+		C() <-chan time.Time
+		// Stop prevents the timer from firing, it returns false if the
+		// timer already fired or was stopped.
+		Stop() bool
+		// Reset changes the timer to fire after d, it returns true if
+		// the timer had been active.
+		Reset(d time.Duration) bool
+	}
+
+	// Ticker is the interface implemented by clock tickers, see
+	// time.Ticker.
+	Ticker interface {
+		// C returns the channel on which the ticks are delivered.
+		C() <-chan time.Time
+		// Stop turns off the ticker.
+		Stop()
+	}
+
+	// systemClock is the Clock backed by the time package.
+	systemClock struct{}
+
+	// systemTimer wraps a time.Timer.
+	systemTimer struct{ *time.Timer }
+
+	// systemTicker wraps a time.Ticker.
+	systemTicker struct{ *time.Ticker }
+
+	// private type used to define context keys.
+	ctxKey int
+)
+
+// System is the Clock backed by the time package.
+var System Clock = systemClock{}
+
+// ctxClock is the context key used to store the clock.
+const ctxClock ctxKey = iota + 1
+
+// Context returns a copy of ctx that carries c. Clue packages that accept a
+// context at initialization (e.g. metrics.Context or health.NewPollingChecker)
+// use the clock stored in the context unless one is given explicitly.
+//
+// Example:
+//
+//	clk := clock.NewFake(time.Now())
+//	ctx := clock.Context(context.Background(), clk)
+//	chk := health.NewPollingChecker(ctx, time.Minute, db)
+//	clk.Advance(time.Minute) // triggers the next poll
+func Context(ctx context.Context, c Clock) context.Context {
+	return context.WithValue(ctx, ctxClock, c)
+}
+
+// FromContext returns the clock stored in ctx, System if there is none.
+func FromContext(ctx context.Context) Clock {
+	if c, ok := ctx.Value(ctxClock).(Clock); ok {
+		return c
+	}
+	return System
+}
+
+func (systemClock) Now() time.Time                  { return time.Now() }
+func (systemClock) Since(t time.Time) time.Duration { return time.Since(t) }
+func (systemClock) NewTimer(d time.Duration) Timer  { return systemTimer{time.NewTimer(d)} }
+func (systemClock) NewTicker(d time.Duration) Ticker {
+	return systemTicker{time.NewTicker(d)}
+}
+
+func (t systemTimer) C() <-chan time.Time  { return t.Timer.C }
+func (t systemTicker) C() <-chan time.Time { return t.Ticker.C }
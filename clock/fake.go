@@ -0,0 +1,182 @@
+package clock
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+type (
+	// Fake is a Clock whose time only changes when Advance or Set is
+	// called. Timers and tickers created with the fake clock fire
+	// synchronously when the time reaches their deadline which makes
+	// duration dependent behavior deterministic in tests. Fake is safe for
+	// concurrent use.
+	Fake struct {
+		lock    sync.Mutex
+		cond    *sync.Cond
+		now     time.Time
+		waiters []*fakeWaiter
+	}
+
+	// fakeWaiter implements the timers and tickers of the fake clock.
+	fakeWaiter struct {
+		clock    *Fake
+		deadline time.Time
+		period   time.Duration // zero for timers
+		c        chan time.Time
+	}
+
+	// fakeTicker adapts fakeWaiter to the Ticker interface.
+	fakeTicker struct{ *fakeWaiter }
+)
+
+// NewFake returns a fake clock set to now.
+//
+// Example:
+//
+//	clk := clock.NewFake(time.Date(2022, time.January, 9, 20, 29, 45, 0, time.UTC))
+//	start := clk.Now()
+//	clk.Advance(100 * time.Millisecond)
+//	clk.Since(start) // 100ms
+func NewFake(now time.Time) *Fake {
+	f := &Fake{now: now}
+	f.cond = sync.NewCond(&f.lock)
+	return f
+}
+
+// Now returns the current fake time.
+func (f *Fake) Now() time.Time {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.now
+}
+
+// Since returns the fake time elapsed since t.
+func (f *Fake) Since(t time.Time) time.Duration {
+	return f.Now().Sub(t)
+}
+
+// NewTimer creates a timer that fires once the fake time is advanced by d.
+func (f *Fake) NewTimer(d time.Duration) Timer {
+	return f.newWaiter(d, 0)
+}
+
+// NewTicker creates a ticker that fires each time the fake time crosses a
+// multiple of d. As with time.Ticker ticks are dropped if the receiver is
+// not keeping up. NewTicker panics if d is not positive.
+func (f *Fake) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("non-positive interval for NewTicker")
+	}
+	return fakeTicker{f.newWaiter(d, d)}
+}
+
+// Advance moves the fake time forward by d and fires the timers and tickers
+// whose deadline is reached, in deadline order.
+func (f *Fake) Advance(d time.Duration) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.set(f.now.Add(d))
+}
+
+// Set sets the fake time to t and fires the timers and tickers whose
+// deadline is reached, in deadline order. Set does nothing if t is before
+// the current fake time.
+func (f *Fake) Set(t time.Time) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if t.Before(f.now) {
+		return
+	}
+	f.set(t)
+}
+
+// BlockUntil blocks until at least n timers and tickers are active. This
+// makes it possible for tests to wait for the code under test to create its
+// timers before advancing the time.
+func (f *Fake) BlockUntil(n int) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	for len(f.waiters) < n {
+		f.cond.Wait()
+	}
+}
+
+// set fires the waiters up to t, f.lock must be held.
+func (f *Fake) set(t time.Time) {
+	for {
+		sort.SliceStable(f.waiters, func(i, j int) bool {
+			return f.waiters[i].deadline.Before(f.waiters[j].deadline)
+		})
+		if len(f.waiters) == 0 || f.waiters[0].deadline.After(t) {
+			break
+		}
+		w := f.waiters[0]
+		f.now = w.deadline
+		select {
+		case w.c <- w.deadline:
+		default:
+		}
+		if w.period > 0 {
+			w.deadline = w.deadline.Add(w.period)
+			continue
+		}
+		f.waiters = f.waiters[1:]
+	}
+	f.now = t
+}
+
+// newWaiter registers a new timer or ticker.
+func (f *Fake) newWaiter(d, period time.Duration) *fakeWaiter {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	w := &fakeWaiter{clock: f, deadline: f.now.Add(d), period: period, c: make(chan time.Time, 1)}
+	if d <= 0 {
+		w.c <- f.now
+		return w
+	}
+	f.waiters = append(f.waiters, w)
+	f.cond.Broadcast()
+	return w
+}
+
+// remove unregisters w and returns true if it was registered, f.lock must be
+// held.
+func (f *Fake) remove(w *fakeWaiter) bool {
+	for i, o := range f.waiters {
+		if o == w {
+			f.waiters = append(f.waiters[:i], f.waiters[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func (w *fakeWaiter) C() <-chan time.Time { return w.c }
+
+func (w *fakeWaiter) Stop() bool {
+	w.clock.lock.Lock()
+	defer w.clock.lock.Unlock()
+	return w.clock.remove(w)
+}
+
+func (w *fakeWaiter) Reset(d time.Duration) bool {
+	f := w.clock
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	active := f.remove(w)
+	w.deadline = f.now.Add(d)
+	if d <= 0 {
+		select {
+		case w.c <- f.now:
+		default:
+		}
+		return active
+	}
+	f.waiters = append(f.waiters, w)
+	f.cond.Broadcast()
+	return active
+}
+
+func (t fakeTicker) Stop() { t.fakeWaiter.Stop() }
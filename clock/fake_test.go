@@ -0,0 +1,129 @@
+package clock
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+var epoch = time.Date(2022, time.January, 9, 20, 29, 45, 0, time.UTC)
+
+func TestFakeNowSince(t *testing.T) {
+	f := NewFake(epoch)
+	if got := f.Now(); !got.Equal(epoch) {
+		t.Errorf("got now %v, want %v", got, epoch)
+	}
+	f.Advance(100 * time.Millisecond)
+	if got := f.Since(epoch); got != 100*time.Millisecond {
+		t.Errorf("got since %v, want 100ms", got)
+	}
+	f.Set(epoch.Add(time.Hour))
+	if got := f.Since(epoch); got != time.Hour {
+		t.Errorf("got since %v, want 1h", got)
+	}
+	f.Set(epoch)
+	if got := f.Since(epoch); got != time.Hour {
+		t.Errorf("expected Set to ignore times in the past, got since %v", got)
+	}
+}
+
+func TestFakeTimer(t *testing.T) {
+	f := NewFake(epoch)
+	timer := f.NewTimer(time.Second)
+	f.Advance(999 * time.Millisecond)
+	assertNotFired(t, timer.C())
+	f.Advance(time.Millisecond)
+	assertFired(t, timer.C(), epoch.Add(time.Second))
+	if timer.Stop() {
+		t.Errorf("expected Stop to return false once the timer fired")
+	}
+
+	if timer.Reset(time.Second) {
+		t.Errorf("expected Reset to return false once the timer fired")
+	}
+	if !timer.Stop() {
+		t.Errorf("expected Stop to return true for an active timer")
+	}
+	f.Advance(time.Minute)
+	assertNotFired(t, timer.C())
+}
+
+func TestFakeTicker(t *testing.T) {
+	f := NewFake(epoch)
+	ticker := f.NewTicker(time.Second)
+	f.Advance(time.Second)
+	assertFired(t, ticker.C(), epoch.Add(time.Second))
+	f.Advance(3 * time.Second)
+	assertFired(t, ticker.C(), epoch.Add(2*time.Second))
+	assertNotFired(t, ticker.C())
+	ticker.Stop()
+	f.Advance(time.Second)
+	assertNotFired(t, ticker.C())
+}
+
+func TestFakeOrder(t *testing.T) {
+	f := NewFake(epoch)
+	late := f.NewTimer(2 * time.Second)
+	early := f.NewTimer(time.Second)
+	var fired []time.Time
+	f.Advance(time.Minute)
+	for _, c := range []<-chan time.Time{early.C(), late.C()} {
+		select {
+		case tm := <-c:
+			fired = append(fired, tm)
+		default:
+			t.Fatal("expected timer to fire")
+		}
+	}
+	if !fired[0].Before(fired[1]) {
+		t.Errorf("expected timers to fire in deadline order, got %v", fired)
+	}
+}
+
+func TestFakeBlockUntil(t *testing.T) {
+	f := NewFake(epoch)
+	done := make(chan struct{})
+	go func() {
+		timer := f.NewTimer(time.Second)
+		<-timer.C()
+		close(done)
+	}()
+	f.BlockUntil(1)
+	f.Advance(time.Second)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for the timer")
+	}
+}
+
+func TestContext(t *testing.T) {
+	if FromContext(context.Background()) != System {
+		t.Errorf("expected the system clock by default")
+	}
+	f := NewFake(epoch)
+	if FromContext(Context(context.Background(), f)) != f {
+		t.Errorf("expected the clock stored in the context")
+	}
+}
+
+func assertFired(t *testing.T, c <-chan time.Time, want time.Time) {
+	t.Helper()
+	select {
+	case got := <-c:
+		if !got.Equal(want) {
+			t.Errorf("got time %v, want %v", got, want)
+		}
+	default:
+		t.Errorf("expected channel to fire")
+	}
+}
+
+func assertNotFired(t *testing.T, c <-chan time.Time) {
+	t.Helper()
+	select {
+	case got := <-c:
+		t.Errorf("unexpected fire at %v", got)
+	default:
+	}
+}
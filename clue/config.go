@@ -0,0 +1,311 @@
+package clue
+
+import (
+	"context"
+
+	"go.opentelemetry.io/contrib/propagators/b3"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+	"go.opentelemetry.io/otel/propagation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"goa.design/clue/log"
+	cluetrace "goa.design/clue/trace"
+)
+
+type (
+	// Config is used to initialize metrics and tracing.
+	Config struct {
+		// MeterProvider is the OpenTelemetry meter provider set as global
+		// by ConfigureOpenTelemetry. Note that the clue metrics package
+		// records metrics in its own Prometheus registry and does not use
+		// MeterProvider.
+		MeterProvider metric.MeterProvider
+		// TracerProvider is the OpenTelemetry tracer provider set as
+		// global by ConfigureOpenTelemetry. The clue trace package uses it
+		// when the context is initialized with TraceOptions.
+		TracerProvider trace.TracerProvider
+		// Propagators is the OpenTelemetry propagator used to propagate
+		// trace context across process boundaries.
+		Propagators propagation.TextMapPropagator
+		// ErrorHandler is the error handler used by OpenTelemetry.
+		ErrorHandler otel.ErrorHandler
+
+		// flushers are flushed by Shutdown.
+		flushers []Flusher
+		// profiler is started by ConfigureOpenTelemetry and stopped by
+		// Shutdown.
+		profiler *Profiler
+		// control is the runtime control applied by the sampler of
+		// TracerProvider.
+		control *cluetrace.Control
+	}
+)
+
+// ConfigureOpenTelemetry sets the global OpenTelemetry meter provider, tracer
+// provider, propagators and error handler to the values in cfg and starts the
+// profiler configured via WithProfiling if any. Shutdown flushes the telemetry
+// recorded with cfg. The clue trace package does not use the globals, the
+// context given to its middlewares and interceptors must be initialized with
+// TraceOptions for the spans to be recorded with cfg.
+//
+// Usage:
+//
+//	cfg, err := clue.NewConfig(ctx, "svc", "1.0.0", metricExporter, spanExporter)
+//	if err != nil {
+//	        log.Fatal(ctx, err)
+//	}
+//	clue.ConfigureOpenTelemetry(ctx, cfg)
+//	defer clue.Shutdown(ctx)
+//	ctx, err = trace.Context(ctx, "svc", cfg.TraceOptions()...)
+func ConfigureOpenTelemetry(ctx context.Context, cfg *Config) {
+	configured.Store(cfg)
+	otel.SetMeterProvider(cfg.MeterProvider)
+	otel.SetTracerProvider(cfg.TracerProvider)
+	otel.SetTextMapPropagator(cfg.Propagators)
+	otel.SetErrorHandler(cfg.ErrorHandler)
+	if cfg.profiler != nil {
+		cfg.profiler.Start(ctx)
+	}
+}
+
+// NewConfig creates a new Config object adequate for use by
+// ConfigureOpenTelemetry. The metricExporter and spanExporter are used to
+// record telemetry. Additional exporters may be configured via options such as
+// WithJaegerExporter or WithOTLPMetricExporter and additional span processors
+// via WithSpanProcessor. If there is no metric exporter or no span exporter or
+// processor then the corresponding package will not record any telemetry. The
+// OpenTelemetry resource is created from the service name and version and
+// merged with the resources detected by the detectors configured via
+// WithResourceDetectors and with the resource configured via WithResource if
+// any. The tracer provider uses a parent based sampler with an adaptive root
+// sampler (see trace.AdaptiveSampler) unless configured otherwise via
+// WithSampler or WithTailSampling. The length of span attribute values is
+// capped, see WithAttributeValueLengthLimit.
+//
+// NewConfig is the single place where sampling, propagation and exporters are
+// configured: the trace package uses the resulting configuration when its
+// context is initialized with Config.TraceOptions.
+func NewConfig(
+	ctx context.Context,
+	svcName string,
+	svcVersion string,
+	metricExporter sdkmetric.Exporter,
+	spanExporter sdktrace.SpanExporter,
+	opts ...Option,
+) (*Config, error) {
+	options := defaultOptions(ctx)
+	for _, o := range opts {
+		o(options)
+	}
+	res, err := resource.Merge(
+		resource.Default(),
+		resource.NewWithAttributes(
+			semconv.SchemaURL,
+			semconv.ServiceName(svcName),
+			semconv.ServiceVersion(svcVersion),
+		))
+	if err != nil {
+		return nil, err
+	}
+	if len(options.resourceDetectors) > 0 {
+		detected, err := resource.New(ctx, resource.WithDetectors(options.resourceDetectors...))
+		if err != nil {
+			options.errorHandler.Handle(err)
+		}
+		if detected != nil {
+			res, err = resource.Merge(res, detected)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	if options.resource != nil {
+		res, err = resource.Merge(res, options.resource)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var metricExporters []sdkmetric.Exporter
+	if metricExporter != nil {
+		metricExporters = append(metricExporters, metricExporter)
+	}
+	if options.debugExporters {
+		metricExporters = append(metricExporters, newDebugMetricExporter(options.debugOutput))
+	}
+	for _, newExporter := range options.metricExporters {
+		exporter, err := newExporter(ctx)
+		if err != nil {
+			return nil, err
+		}
+		metricExporters = append(metricExporters, exporter)
+	}
+
+	var meterProvider metric.MeterProvider
+	if len(metricExporters) == 0 {
+		meterProvider = noop.NewMeterProvider()
+	} else {
+		mpOptions := []sdkmetric.Option{sdkmetric.WithResource(res)}
+		for _, exporter := range metricExporters {
+			reader := sdkmetric.NewPeriodicReader(
+				exporter,
+				sdkmetric.WithInterval(options.readerInterval),
+			)
+			mpOptions = append(mpOptions, sdkmetric.WithReader(reader))
+		}
+		meterProvider = sdkmetric.NewMeterProvider(mpOptions...)
+	}
+
+	var processors []sdktrace.SpanProcessor
+	if spanExporter != nil {
+		processors = append(processors, sdktrace.NewBatchSpanProcessor(spanExporter))
+	}
+	if options.debugExporters {
+		exporter, err := newDebugSpanExporter(options.debugOutput)
+		if err != nil {
+			return nil, err
+		}
+		processors = append(processors, sdktrace.NewSimpleSpanProcessor(exporter))
+	}
+	for _, se := range options.spanExporters {
+		exporter, err := se.newExporter(ctx)
+		if err != nil {
+			return nil, err
+		}
+		processors = append(processors, sdktrace.NewBatchSpanProcessor(exporter, se.batchOptions...))
+	}
+	if options.tailSampling && len(processors) > 0 {
+		processors = []sdktrace.SpanProcessor{
+			cluetrace.NewTailSampler(spanProcessors(processors), options.tailOptions...),
+		}
+	}
+	processors = append(processors, options.spanProcessors...)
+
+	control := &cluetrace.Control{}
+	var tracerProvider trace.TracerProvider
+	if len(processors) == 0 {
+		tracerProvider = trace.NewNoopTracerProvider()
+	} else {
+		root := cluetrace.AdaptiveSampler(options.maxSamplingRate, options.sampleSize)
+		if options.tailSampling {
+			root = sdktrace.AlwaysSample()
+		}
+		sampler := cluetrace.ControlSampler(control, cluetrace.RouteSampler(root, options.samplerOptions...))
+		tpOptions := []sdktrace.TracerProviderOption{
+			sdktrace.WithSampler(sampler),
+			sdktrace.WithResource(res),
+			sdktrace.WithRawSpanLimits(options.spanLimits),
+		}
+		if options.xray {
+			tpOptions = append(tpOptions, sdktrace.WithIDGenerator(cluetrace.NewXRayIDGenerator()))
+		}
+		if len(options.spanStartHooks) > 0 {
+			tpOptions = append(tpOptions, sdktrace.WithSpanProcessor(spanStartHooks(options.spanStartHooks)))
+		}
+		for _, processor := range processors {
+			tpOptions = append(tpOptions, sdktrace.WithSpanProcessor(processor))
+		}
+		tracerProvider = sdktrace.NewTracerProvider(tpOptions...)
+	}
+
+	propagators := options.propagators
+	if options.b3 {
+		propagators = propagation.NewCompositeTextMapPropagator(propagators, b3.New(options.b3Options...))
+	}
+	if options.xray {
+		propagators = propagation.NewCompositeTextMapPropagator(propagators, cluetrace.XRayPropagator{})
+	}
+
+	var profiler *Profiler
+	if options.profiler != nil {
+		popts := append([]ProfilingOption{WithProfilingErrorHandler(options.errorHandler)}, options.profiler.opts...)
+		profiler = NewProfiler(svcName, svcVersion, options.profiler.uploader, popts...)
+	}
+
+	return &Config{
+		MeterProvider:  meterProvider,
+		TracerProvider: tracerProvider,
+		Propagators:    propagators,
+		ErrorHandler:   options.errorHandler,
+		flushers:       options.flushers,
+		profiler:       profiler,
+		control:        control,
+	}, nil
+}
+
+// TraceOptions returns the options that make trace.Context use the tracer
+// provider, propagators and runtime control of cfg so that the spans created
+// by the trace package middlewares and interceptors are sampled and exported
+// as configured by NewConfig:
+//
+//	ctx, err := trace.Context(ctx, "svc", cfg.TraceOptions()...)
+func (cfg *Config) TraceOptions() []cluetrace.TraceOption {
+	return []cluetrace.TraceOption{
+		cluetrace.WithTracerProvider(cfg.TracerProvider),
+		cluetrace.WithPropagator(cfg.Propagators),
+		cluetrace.WithControl(cfg.control),
+	}
+}
+
+// spanProcessors is a span processor that forwards spans to a list of
+// processors, it makes it possible to tail sample the spans of all the
+// exporters at once.
+type spanProcessors []sdktrace.SpanProcessor
+
+// OnStart calls the processors in order.
+func (ps spanProcessors) OnStart(ctx context.Context, span sdktrace.ReadWriteSpan) {
+	for _, p := range ps {
+		p.OnStart(ctx, span)
+	}
+}
+
+// OnEnd calls the processors in order.
+func (ps spanProcessors) OnEnd(span sdktrace.ReadOnlySpan) {
+	for _, p := range ps {
+		p.OnEnd(span)
+	}
+}
+
+// Shutdown shuts down the processors and returns the first error.
+func (ps spanProcessors) Shutdown(ctx context.Context) error {
+	var firstErr error
+	for _, p := range ps {
+		if err := p.Shutdown(ctx); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// ForceFlush flushes the processors and returns the first error.
+func (ps spanProcessors) ForceFlush(ctx context.Context) error {
+	var firstErr error
+	for _, p := range ps {
+		if err := p.ForceFlush(ctx); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// errorHandler logs OpenTelemetry errors using the logger in ctx.
+type errorHandler struct {
+	ctx context.Context
+}
+
+// NewErrorHandler returns an error handler that logs errors using the clue
+// logger configured in logCtx.
+func NewErrorHandler(logCtx context.Context) otel.ErrorHandler {
+	return errorHandler{logCtx}
+}
+
+// Handle logs the given error.
+func (h errorHandler) Handle(err error) {
+	log.Error(h.ctx, err)
+}
@@ -0,0 +1,177 @@
+package clue
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric/noop"
+	"go.opentelemetry.io/otel/propagation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/aggregation"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"goa.design/clue/log"
+	cluetrace "goa.design/clue/trace"
+)
+
+func TestNewConfig(t *testing.T) {
+	ctx := log.Context(context.Background())
+	cfg, err := NewConfig(ctx, "svc", "1.0", &dummyMetricExporter{}, tracetest.NewInMemoryExporter())
+	require.NoError(t, err)
+	assert.IsType(t, &sdkmetric.MeterProvider{}, cfg.MeterProvider)
+	assert.IsType(t, &sdktrace.TracerProvider{}, cfg.TracerProvider)
+	assert.Equal(t, propagation.TraceContext{}, cfg.Propagators)
+	assert.Equal(t, NewErrorHandler(ctx), cfg.ErrorHandler)
+}
+
+func TestNewConfigNoExporter(t *testing.T) {
+	cfg, err := NewConfig(context.Background(), "svc", "1.0", nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, noop.NewMeterProvider(), cfg.MeterProvider)
+	assert.Equal(t, trace.NewNoopTracerProvider(), cfg.TracerProvider)
+}
+
+func TestNewConfigSampler(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	cfg, err := NewConfig(context.Background(), "svc", "1.0", nil, exporter,
+		WithMaxSamplingRate(1000),
+		WithSampler(cluetrace.RouteRatio(0, "/search"), cluetrace.RouteRatio(1, "/payments/*")))
+	require.NoError(t, err)
+	tracer := cfg.TracerProvider.Tracer("test")
+	cases := []struct {
+		target  string
+		sampled bool
+	}{
+		{"/search?q=clue", false},
+		{"/payments/42", true},
+		{"/cart", true},
+	}
+	for _, c := range cases {
+		_, span := tracer.Start(context.Background(), "svc", trace.WithAttributes(semconv.HTTPTarget(c.target)))
+		assert.Equal(t, c.sampled, span.SpanContext().IsSampled(), c.target)
+		span.End()
+	}
+}
+
+func TestNewConfigSpanLimits(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	cfg, err := NewConfig(context.Background(), "svc", "1.0", nil, exporter,
+		WithSampler(cluetrace.ParentRatio(1)),
+		WithAttributeValueLengthLimit(3),
+		WithAttributeCountLimit(1),
+		WithEventCountLimit(1))
+	require.NoError(t, err)
+	_, span := cfg.TracerProvider.Tracer("test").Start(context.Background(), "span")
+	span.SetAttributes(attribute.String("a", "abcdef"), attribute.String("b", "b"))
+	span.AddEvent("e1")
+	span.AddEvent("e2")
+	span.End()
+	require.NoError(t, cfg.TracerProvider.(*sdktrace.TracerProvider).ForceFlush(context.Background()))
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+	require.Len(t, spans[0].Attributes, 1)
+	assert.Equal(t, "abc", spans[0].Attributes[0].Value.AsString())
+	assert.Len(t, spans[0].Events, 1)
+}
+
+func TestConfigTraceOptions(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	cfg, err := NewConfig(context.Background(), "svc", "1.0", nil, exporter,
+		WithSampler(cluetrace.ParentRatio(1)))
+	require.NoError(t, err)
+	ctx, err := cluetrace.Context(context.Background(), "svc", cfg.TraceOptions()...)
+	require.NoError(t, err)
+	handler := cluetrace.HTTP(ctx)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/orders", nil))
+	cluetrace.TracingControl(ctx).SetEnabled(false)
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/orders", nil))
+
+	require.NoError(t, cfg.TracerProvider.(*sdktrace.TracerProvider).ForceFlush(context.Background()))
+	assert.Len(t, exporter.GetSpans(), 1)
+}
+
+func TestNewConfigTailSampling(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	cfg, err := NewConfig(context.Background(), "svc", "1.0", nil, exporter,
+		WithTailSampling(cluetrace.WithTailRatio(0)))
+	require.NoError(t, err)
+	tracer := cfg.TracerProvider.Tracer("test")
+	_, span := tracer.Start(context.Background(), "ok")
+	span.End()
+	_, span = tracer.Start(context.Background(), "failed")
+	span.SetStatus(codes.Error, "boom")
+	span.End()
+	require.NoError(t, cfg.TracerProvider.(*sdktrace.TracerProvider).ForceFlush(context.Background()))
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "failed", spans[0].Name)
+}
+
+func TestNewConfigPropagation(t *testing.T) {
+	cfg, err := NewConfig(context.Background(), "svc", "1.0", nil, tracetest.NewInMemoryExporter(),
+		WithB3Propagation(), WithXRay())
+	require.NoError(t, err)
+	fields := cfg.Propagators.Fields()
+	assert.Contains(t, fields, "traceparent")
+	assert.Contains(t, fields, "x-b3-traceid")
+	assert.Contains(t, fields, "X-Amzn-Trace-Id")
+}
+
+func TestNewConfigInvalidResource(t *testing.T) {
+	res := resource.NewWithAttributes("https://invalid/schema", attribute.String("key", "value"))
+	_, err := NewConfig(context.Background(), "svc", "1.0", nil, nil, WithResource(res))
+	assert.Error(t, err)
+}
+
+func TestConfigureOpenTelemetry(t *testing.T) {
+	cfg, err := NewConfig(context.Background(), "svc", "1.0", nil, tracetest.NewInMemoryExporter(),
+		WithPropagators(propagation.Baggage{}))
+	require.NoError(t, err)
+	ConfigureOpenTelemetry(context.Background(), cfg)
+	assert.Equal(t, cfg.TracerProvider, otel.GetTracerProvider())
+	assert.Equal(t, propagation.Baggage{}, otel.GetTextMapPropagator())
+}
+
+func TestErrorHandler(t *testing.T) {
+	var buf bytes.Buffer
+	ctx := log.Context(context.Background(), log.WithOutput(&buf), log.WithFormat(log.FormatJSON))
+	NewErrorHandler(ctx).Handle(errors.New("boom"))
+	assert.Contains(t, buf.String(), `"err":"boom"`)
+}
+
+type dummyMetricExporter struct{}
+
+func (*dummyMetricExporter) Temporality(sdkmetric.InstrumentKind) metricdata.Temporality {
+	return metricdata.CumulativeTemporality
+}
+
+func (*dummyMetricExporter) Aggregation(sdkmetric.InstrumentKind) aggregation.Aggregation {
+	return nil
+}
+
+func (*dummyMetricExporter) Export(context.Context, *metricdata.ResourceMetrics) error {
+	return nil
+}
+
+func (*dummyMetricExporter) ForceFlush(context.Context) error {
+	return nil
+}
+
+func (*dummyMetricExporter) Shutdown(context.Context) error {
+	return nil
+}
@@ -0,0 +1,75 @@
+package clue
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/aggregation"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// debugMetricExporter is a metric exporter that pretty prints metrics.
+type debugMetricExporter struct {
+	lock sync.Mutex
+	enc  *json.Encoder
+}
+
+// WithDebugExporters configures exporters that pretty print spans and metrics
+// to stdout so that developers can inspect telemetry locally without running
+// a collector. Spans are printed as soon as they end. Metrics are printed at
+// the interval configured with WithReaderInterval. The exporters are used in
+// addition to the exporters given to NewConfig if any. Not for use in
+// production.
+func WithDebugExporters() Option {
+	return func(o *options) {
+		o.debugExporters = true
+	}
+}
+
+// newDebugSpanExporter returns a span exporter that pretty prints spans to w.
+func newDebugSpanExporter(w io.Writer) (sdktrace.SpanExporter, error) {
+	return stdouttrace.New(stdouttrace.WithWriter(w), stdouttrace.WithPrettyPrint())
+}
+
+// newDebugMetricExporter returns a metric exporter that pretty prints metrics
+// to w.
+func newDebugMetricExporter(w io.Writer) sdkmetric.Exporter {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "\t")
+	return &debugMetricExporter{enc: enc}
+}
+
+// Temporality returns the temporality used for the given instrument kind.
+func (*debugMetricExporter) Temporality(k sdkmetric.InstrumentKind) metricdata.Temporality {
+	return sdkmetric.DefaultTemporalitySelector(k)
+}
+
+// Aggregation returns the aggregation used for the given instrument kind.
+func (*debugMetricExporter) Aggregation(k sdkmetric.InstrumentKind) aggregation.Aggregation {
+	return sdkmetric.DefaultAggregationSelector(k)
+}
+
+// Export pretty prints the given metrics.
+func (e *debugMetricExporter) Export(ctx context.Context, rm *metricdata.ResourceMetrics) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	return e.enc.Encode(rm)
+}
+
+// ForceFlush does nothing, metrics are printed as they are exported.
+func (*debugMetricExporter) ForceFlush(ctx context.Context) error {
+	return ctx.Err()
+}
+
+// Shutdown does nothing.
+func (*debugMetricExporter) Shutdown(ctx context.Context) error {
+	return ctx.Err()
+}
@@ -0,0 +1,41 @@
+package clue
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+func TestDebugExporters(t *testing.T) {
+	var buf bytes.Buffer
+	cfg, err := NewConfig(context.Background(), "svc", "1.0", nil, nil,
+		WithDebugExporters(),
+		func(o *options) { o.debugOutput = &buf })
+	require.NoError(t, err)
+	require.IsType(t, &sdktrace.TracerProvider{}, cfg.TracerProvider)
+	require.IsType(t, &sdkmetric.MeterProvider{}, cfg.MeterProvider)
+
+	_, span := cfg.TracerProvider.Tracer("test").Start(context.Background(), "test-span")
+	span.End()
+	assert.Contains(t, buf.String(), `"Name": "test-span"`)
+
+	counter, err := cfg.MeterProvider.Meter("test").Int64Counter("test.counter")
+	require.NoError(t, err)
+	counter.Add(context.Background(), 1)
+	require.NoError(t, cfg.MeterProvider.(*sdkmetric.MeterProvider).Shutdown(context.Background()))
+	assert.Contains(t, buf.String(), `"Name": "test.counter"`)
+}
+
+func TestDebugExportersWithExporters(t *testing.T) {
+	cfg, err := NewConfig(context.Background(), "svc", "1.0", &dummyMetricExporter{}, nil,
+		WithDebugExporters(),
+		func(o *options) { o.debugOutput = &bytes.Buffer{} })
+	require.NoError(t, err)
+	assert.IsType(t, &sdkmetric.MeterProvider{}, cfg.MeterProvider)
+	assert.IsType(t, &sdktrace.TracerProvider{}, cfg.TracerProvider)
+}
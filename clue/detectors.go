@@ -0,0 +1,344 @@
+package clue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+)
+
+type (
+	// kubernetesDetector detects Kubernetes pod attributes.
+	kubernetesDetector struct {
+		// namespaceFile is the path to the service account namespace file.
+		namespaceFile string
+	}
+
+	// ec2Detector detects AWS EC2 instance attributes.
+	ec2Detector struct {
+		// endpoint is the URL of the instance metadata service.
+		endpoint string
+		client   *http.Client
+	}
+
+	// ecsDetector detects AWS ECS task attributes.
+	ecsDetector struct {
+		client *http.Client
+	}
+
+	// gceDetector detects GCP Compute Engine and GKE attributes.
+	gceDetector struct {
+		// endpoint is the URL of the metadata server.
+		endpoint string
+		client   *http.Client
+	}
+
+	// cloudRunDetector detects GCP Cloud Run attributes.
+	cloudRunDetector struct {
+		// endpoint is the URL of the metadata server.
+		endpoint string
+		client   *http.Client
+	}
+)
+
+const (
+	// metadataTimeout is the timeout of requests made to the cloud
+	// metadata services.
+	metadataTimeout = 2 * time.Second
+	// ec2MetadataEndpoint is the URL of the EC2 instance metadata service.
+	ec2MetadataEndpoint = "http://169.254.169.254"
+	// gceMetadataEndpoint is the URL of the GCP metadata server.
+	gceMetadataEndpoint = "http://metadata.google.internal"
+	// k8sNamespaceFile is the path of the file containing the pod namespace
+	// in pods that mount a service account.
+	k8sNamespaceFile = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+)
+
+// KubernetesDetector returns a resource detector that records the pod name,
+// namespace and UID, the node name and the cluster name of services running in
+// Kubernetes. The values are read from the K8S_POD_NAME, K8S_NAMESPACE_NAME,
+// K8S_POD_UID, K8S_NODE_NAME and K8S_CLUSTER_NAME environment variables which
+// should be set using the downward API, for example:
+//
+//	env:
+//	- name: K8S_POD_NAME
+//	  valueFrom:
+//	    fieldRef:
+//	      fieldPath: metadata.name
+//
+// The pod name defaults to the host name and the namespace to the namespace of
+// the pod service account. The detector returns an empty resource outside of
+// Kubernetes.
+func KubernetesDetector() resource.Detector {
+	return &kubernetesDetector{namespaceFile: k8sNamespaceFile}
+}
+
+// EC2Detector returns a resource detector that records the account ID,
+// region, availability zone, instance ID, instance type and image ID of
+// services running on AWS EC2 using the instance metadata service (IMDSv2).
+// The detector returns an empty resource when the metadata service is not
+// reachable, which may take up to 2 seconds outside of EC2.
+func EC2Detector() resource.Detector {
+	return &ec2Detector{endpoint: ec2MetadataEndpoint, client: &http.Client{Timeout: metadataTimeout}}
+}
+
+// ECSDetector returns a resource detector that records the cluster, task ARN,
+// family, revision and launch type as well as the container name and ID of
+// services running on AWS ECS (including Fargate) using the task metadata
+// endpoint v4. The detector returns an empty resource outside of ECS.
+func ECSDetector() resource.Detector {
+	return &ecsDetector{client: &http.Client{Timeout: metadataTimeout}}
+}
+
+// GCEDetector returns a resource detector that records the project ID, zone,
+// region, instance ID, name and machine type of services running on GCP
+// Compute Engine using the metadata server. It also records the cluster name
+// of services running on GKE. The detector returns an empty resource when the
+// metadata server is not reachable, which may take up to 2 seconds outside of
+// GCP.
+func GCEDetector() resource.Detector {
+	return &gceDetector{endpoint: gceMetadataEndpoint, client: &http.Client{Timeout: metadataTimeout}}
+}
+
+// CloudRunDetector returns a resource detector that records the service name,
+// revision, project ID, region and instance ID of services running on GCP
+// Cloud Run. The detector returns an empty resource outside of Cloud Run.
+func CloudRunDetector() resource.Detector {
+	return &cloudRunDetector{endpoint: gceMetadataEndpoint, client: &http.Client{Timeout: metadataTimeout}}
+}
+
+// Detect returns the Kubernetes resource.
+func (d *kubernetesDetector) Detect(ctx context.Context) (*resource.Resource, error) {
+	if os.Getenv("KUBERNETES_SERVICE_HOST") == "" {
+		return resource.Empty(), nil
+	}
+	podName := os.Getenv("K8S_POD_NAME")
+	if podName == "" {
+		podName, _ = os.Hostname()
+	}
+	namespace := os.Getenv("K8S_NAMESPACE_NAME")
+	if namespace == "" {
+		if b, err := os.ReadFile(d.namespaceFile); err == nil {
+			namespace = strings.TrimSpace(string(b))
+		}
+	}
+	var attrs []attribute.KeyValue
+	attrs = appendAttr(attrs, semconv.K8SPodName, podName)
+	attrs = appendAttr(attrs, semconv.K8SNamespaceName, namespace)
+	attrs = appendAttr(attrs, semconv.K8SPodUID, os.Getenv("K8S_POD_UID"))
+	attrs = appendAttr(attrs, semconv.K8SNodeName, os.Getenv("K8S_NODE_NAME"))
+	attrs = appendAttr(attrs, semconv.K8SClusterName, os.Getenv("K8S_CLUSTER_NAME"))
+	return resource.NewWithAttributes(semconv.SchemaURL, attrs...), nil
+}
+
+// Detect returns the EC2 resource.
+func (d *ec2Detector) Detect(ctx context.Context) (*resource.Resource, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, d.endpoint+"/latest/api/token", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-aws-ec2-metadata-token-ttl-seconds", "60")
+	token, err := fetchMetadata(d.client, req)
+	if err != nil {
+		// Not running on EC2.
+		return resource.Empty(), nil
+	}
+	req, err = http.NewRequestWithContext(ctx, http.MethodGet, d.endpoint+"/latest/dynamic/instance-identity/document", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-aws-ec2-metadata-token", token)
+	body, err := fetchMetadata(d.client, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve EC2 instance identity: %w", err)
+	}
+	var doc struct {
+		AccountID        string `json:"accountId"`
+		Region           string `json:"region"`
+		AvailabilityZone string `json:"availabilityZone"`
+		InstanceID       string `json:"instanceId"`
+		InstanceType     string `json:"instanceType"`
+		ImageID          string `json:"imageId"`
+	}
+	if err := json.Unmarshal([]byte(body), &doc); err != nil {
+		return nil, fmt.Errorf("failed to decode EC2 instance identity: %w", err)
+	}
+	attrs := []attribute.KeyValue{semconv.CloudProviderAWS, semconv.CloudPlatformAWSEC2}
+	attrs = appendAttr(attrs, semconv.CloudAccountID, doc.AccountID)
+	attrs = appendAttr(attrs, semconv.CloudRegion, doc.Region)
+	attrs = appendAttr(attrs, semconv.CloudAvailabilityZone, doc.AvailabilityZone)
+	attrs = appendAttr(attrs, semconv.HostID, doc.InstanceID)
+	attrs = appendAttr(attrs, semconv.HostType, doc.InstanceType)
+	attrs = appendAttr(attrs, semconv.HostImageID, doc.ImageID)
+	return resource.NewWithAttributes(semconv.SchemaURL, attrs...), nil
+}
+
+// Detect returns the ECS resource.
+func (d *ecsDetector) Detect(ctx context.Context) (*resource.Resource, error) {
+	endpoint := os.Getenv("ECS_CONTAINER_METADATA_URI_V4")
+	if endpoint == "" {
+		return resource.Empty(), nil
+	}
+	var container struct {
+		DockerID string `json:"DockerId"`
+		Name     string `json:"Name"`
+		ARN      string `json:"ContainerARN"`
+	}
+	if err := d.get(ctx, endpoint, &container); err != nil {
+		return nil, err
+	}
+	var task struct {
+		Cluster          string `json:"Cluster"`
+		TaskARN          string `json:"TaskARN"`
+		Family           string `json:"Family"`
+		Revision         string `json:"Revision"`
+		AvailabilityZone string `json:"AvailabilityZone"`
+		LaunchType       string `json:"LaunchType"`
+	}
+	if err := d.get(ctx, endpoint+"/task", &task); err != nil {
+		return nil, err
+	}
+	attrs := []attribute.KeyValue{semconv.CloudProviderAWS, semconv.CloudPlatformAWSECS}
+	attrs = appendAttr(attrs, semconv.AWSECSClusterARN, task.Cluster)
+	attrs = appendAttr(attrs, semconv.AWSECSTaskARN, task.TaskARN)
+	attrs = appendAttr(attrs, semconv.AWSECSTaskFamily, task.Family)
+	attrs = appendAttr(attrs, semconv.AWSECSTaskRevision, task.Revision)
+	attrs = appendAttr(attrs, semconv.CloudAvailabilityZone, task.AvailabilityZone)
+	attrs = appendAttr(attrs, semconv.AWSECSLaunchtypeKey.String, strings.ToLower(task.LaunchType))
+	attrs = appendAttr(attrs, semconv.AWSECSContainerARN, container.ARN)
+	attrs = appendAttr(attrs, semconv.ContainerName, container.Name)
+	attrs = appendAttr(attrs, semconv.ContainerID, container.DockerID)
+	return resource.NewWithAttributes(semconv.SchemaURL, attrs...), nil
+}
+
+// get retrieves the ECS metadata at the given URL and decodes it into v.
+func (d *ecsDetector) get(ctx context.Context, url string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	body, err := fetchMetadata(d.client, req)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve ECS metadata: %w", err)
+	}
+	if err := json.Unmarshal([]byte(body), v); err != nil {
+		return fmt.Errorf("failed to decode ECS metadata: %w", err)
+	}
+	return nil
+}
+
+// Detect returns the GCE resource.
+func (d *gceDetector) Detect(ctx context.Context) (*resource.Resource, error) {
+	project, err := gcpMetadata(ctx, d.client, d.endpoint, "project/project-id")
+	if err != nil {
+		// Not running on GCP.
+		return resource.Empty(), nil
+	}
+	zone, err := gcpMetadata(ctx, d.client, d.endpoint, "instance/zone")
+	if err != nil {
+		return nil, err
+	}
+	zone = path.Base(zone)
+	var region string
+	if i := strings.LastIndexByte(zone, '-'); i > 0 {
+		region = zone[:i]
+	}
+	attrs := []attribute.KeyValue{semconv.CloudProviderGCP, semconv.CloudPlatformGCPComputeEngine}
+	if cluster, err := gcpMetadata(ctx, d.client, d.endpoint, "instance/attributes/cluster-name"); err == nil {
+		attrs = []attribute.KeyValue{semconv.CloudProviderGCP, semconv.CloudPlatformGCPKubernetesEngine}
+		attrs = appendAttr(attrs, semconv.K8SClusterName, cluster)
+	}
+	attrs = appendAttr(attrs, semconv.CloudAccountID, project)
+	attrs = appendAttr(attrs, semconv.CloudAvailabilityZone, zone)
+	attrs = appendAttr(attrs, semconv.CloudRegion, region)
+	for _, m := range []struct {
+		path string
+		attr func(string) attribute.KeyValue
+	}{
+		{"instance/id", semconv.HostID},
+		{"instance/name", semconv.HostName},
+		{"instance/machine-type", semconv.HostType},
+	} {
+		v, err := gcpMetadata(ctx, d.client, d.endpoint, m.path)
+		if err != nil {
+			return nil, err
+		}
+		attrs = appendAttr(attrs, m.attr, path.Base(v))
+	}
+	return resource.NewWithAttributes(semconv.SchemaURL, attrs...), nil
+}
+
+// Detect returns the Cloud Run resource.
+func (d *cloudRunDetector) Detect(ctx context.Context) (*resource.Resource, error) {
+	svc := os.Getenv("K_SERVICE")
+	if svc == "" {
+		return resource.Empty(), nil
+	}
+	attrs := []attribute.KeyValue{semconv.CloudProviderGCP, semconv.CloudPlatformGCPCloudRun}
+	attrs = appendAttr(attrs, semconv.FaaSName, svc)
+	attrs = appendAttr(attrs, semconv.FaaSVersion, os.Getenv("K_REVISION"))
+	for _, m := range []struct {
+		path string
+		attr func(string) attribute.KeyValue
+	}{
+		{"project/project-id", semconv.CloudAccountID},
+		{"instance/region", semconv.CloudRegion},
+		{"instance/id", semconv.FaaSInstance},
+	} {
+		v, err := gcpMetadata(ctx, d.client, d.endpoint, m.path)
+		if err != nil {
+			return nil, err
+		}
+		attrs = appendAttr(attrs, m.attr, path.Base(v))
+	}
+	return resource.NewWithAttributes(semconv.SchemaURL, attrs...), nil
+}
+
+// gcpMetadata retrieves the GCP metadata at the given path.
+func gcpMetadata(ctx context.Context, client *http.Client, endpoint, path string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint+"/computeMetadata/v1/"+path, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+	v, err := fetchMetadata(client, req)
+	if err != nil {
+		return "", fmt.Errorf("failed to retrieve GCP metadata %q: %w", path, err)
+	}
+	return v, nil
+}
+
+// fetchMetadata makes the given request and returns the response body.
+func fetchMetadata(client *http.Client, req *http.Request) (string, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(b)), nil
+}
+
+// appendAttr appends the attribute created with the given value to attrs if
+// the value is not empty.
+func appendAttr(attrs []attribute.KeyValue, attr func(string) attribute.KeyValue, v string) []attribute.KeyValue {
+	if v == "" {
+		return attrs
+	}
+	return append(attrs, attr(v))
+}
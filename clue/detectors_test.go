@@ -0,0 +1,248 @@
+package clue
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+
+	cluetrace "goa.design/clue/trace"
+)
+
+func TestKubernetesDetector(t *testing.T) {
+	nsFile := filepath.Join(t.TempDir(), "namespace")
+	require.NoError(t, os.WriteFile(nsFile, []byte("prod\n"), 0600))
+	d := &kubernetesDetector{namespaceFile: nsFile}
+
+	t.Setenv("KUBERNETES_SERVICE_HOST", "")
+	res, err := d.Detect(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, res.Len())
+
+	t.Setenv("KUBERNETES_SERVICE_HOST", "10.0.0.1")
+	t.Setenv("K8S_POD_NAME", "svc-abc")
+	t.Setenv("K8S_NAMESPACE_NAME", "")
+	t.Setenv("K8S_POD_UID", "uid")
+	t.Setenv("K8S_NODE_NAME", "node-1")
+	t.Setenv("K8S_CLUSTER_NAME", "")
+	res, err = d.Detect(context.Background())
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []attribute.KeyValue{
+		semconv.K8SPodName("svc-abc"),
+		semconv.K8SNamespaceName("prod"),
+		semconv.K8SPodUID("uid"),
+		semconv.K8SNodeName("node-1"),
+	}, res.Attributes())
+}
+
+func TestEC2Detector(t *testing.T) {
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPut && r.URL.Path == "/latest/api/token":
+			_, _ = w.Write([]byte("token"))
+		case r.URL.Path == "/latest/dynamic/instance-identity/document" && r.Header.Get("X-aws-ec2-metadata-token") == "token":
+			_, _ = w.Write([]byte(`{"accountId":"123","region":"us-east-1","availabilityZone":"us-east-1a","instanceId":"i-1","instanceType":"t3.micro","imageId":"ami-1"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer svr.Close()
+	d := &ec2Detector{endpoint: svr.URL, client: svr.Client()}
+
+	res, err := d.Detect(context.Background())
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []attribute.KeyValue{
+		semconv.CloudProviderAWS,
+		semconv.CloudPlatformAWSEC2,
+		semconv.CloudAccountID("123"),
+		semconv.CloudRegion("us-east-1"),
+		semconv.CloudAvailabilityZone("us-east-1a"),
+		semconv.HostID("i-1"),
+		semconv.HostType("t3.micro"),
+		semconv.HostImageID("ami-1"),
+	}, res.Attributes())
+
+	svr.Close()
+	res, err = d.Detect(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, res.Len())
+}
+
+func TestECSDetector(t *testing.T) {
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v4":
+			_, _ = w.Write([]byte(`{"DockerId":"abc","Name":"svc","ContainerARN":"arn:container"}`))
+		case "/v4/task":
+			_, _ = w.Write([]byte(`{"Cluster":"arn:cluster","TaskARN":"arn:task","Family":"svc","Revision":"3","AvailabilityZone":"us-east-1b","LaunchType":"FARGATE"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer svr.Close()
+	d := &ecsDetector{client: svr.Client()}
+
+	t.Setenv("ECS_CONTAINER_METADATA_URI_V4", "")
+	res, err := d.Detect(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, res.Len())
+
+	t.Setenv("ECS_CONTAINER_METADATA_URI_V4", svr.URL+"/v4")
+	res, err = d.Detect(context.Background())
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []attribute.KeyValue{
+		semconv.CloudProviderAWS,
+		semconv.CloudPlatformAWSECS,
+		semconv.AWSECSClusterARN("arn:cluster"),
+		semconv.AWSECSTaskARN("arn:task"),
+		semconv.AWSECSTaskFamily("svc"),
+		semconv.AWSECSTaskRevision("3"),
+		semconv.CloudAvailabilityZone("us-east-1b"),
+		semconv.AWSECSLaunchtypeFargate,
+		semconv.AWSECSContainerARN("arn:container"),
+		semconv.ContainerName("svc"),
+		semconv.ContainerID("abc"),
+	}, res.Attributes())
+
+	t.Setenv("ECS_CONTAINER_METADATA_URI_V4", svr.URL+"/unknown")
+	_, err = d.Detect(context.Background())
+	assert.Error(t, err)
+}
+
+func TestGCEDetector(t *testing.T) {
+	cases := []struct {
+		name     string
+		cluster  string
+		expected []attribute.KeyValue
+	}{
+		{"gce", "", []attribute.KeyValue{
+			semconv.CloudProviderGCP,
+			semconv.CloudPlatformGCPComputeEngine,
+			semconv.CloudAccountID("project"),
+			semconv.CloudAvailabilityZone("us-central1-a"),
+			semconv.CloudRegion("us-central1"),
+			semconv.HostID("42"),
+			semconv.HostName("vm"),
+			semconv.HostType("e2-medium"),
+		}},
+		{"gke", "cluster", []attribute.KeyValue{
+			semconv.CloudProviderGCP,
+			semconv.CloudPlatformGCPKubernetesEngine,
+			semconv.K8SClusterName("cluster"),
+			semconv.CloudAccountID("project"),
+			semconv.CloudAvailabilityZone("us-central1-a"),
+			semconv.CloudRegion("us-central1"),
+			semconv.HostID("42"),
+			semconv.HostName("vm"),
+			semconv.HostType("e2-medium"),
+		}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			metadata := map[string]string{
+				"project/project-id":    "project",
+				"instance/zone":         "projects/123/zones/us-central1-a",
+				"instance/id":           "42",
+				"instance/name":         "vm",
+				"instance/machine-type": "projects/123/machineTypes/e2-medium",
+			}
+			if c.cluster != "" {
+				metadata["instance/attributes/cluster-name"] = c.cluster
+			}
+			svr := newGCPMetadataServer(metadata)
+			defer svr.Close()
+			d := &gceDetector{endpoint: svr.URL, client: svr.Client()}
+
+			res, err := d.Detect(context.Background())
+
+			require.NoError(t, err)
+			assert.ElementsMatch(t, c.expected, res.Attributes())
+		})
+	}
+}
+
+func TestCloudRunDetector(t *testing.T) {
+	svr := newGCPMetadataServer(map[string]string{
+		"project/project-id": "project",
+		"instance/region":    "projects/123/regions/us-central1",
+		"instance/id":        "instance",
+	})
+	defer svr.Close()
+	d := &cloudRunDetector{endpoint: svr.URL, client: svr.Client()}
+
+	t.Setenv("K_SERVICE", "")
+	res, err := d.Detect(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, res.Len())
+
+	t.Setenv("K_SERVICE", "svc")
+	t.Setenv("K_REVISION", "svc-00001")
+	res, err = d.Detect(context.Background())
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []attribute.KeyValue{
+		semconv.CloudProviderGCP,
+		semconv.CloudPlatformGCPCloudRun,
+		semconv.FaaSName("svc"),
+		semconv.FaaSVersion("svc-00001"),
+		semconv.CloudAccountID("project"),
+		semconv.CloudRegion("us-central1"),
+		semconv.FaaSInstance("instance"),
+	}, res.Attributes())
+}
+
+func TestNewConfigResourceDetectors(t *testing.T) {
+	var handled []error
+	exporter := tracetest.NewInMemoryExporter()
+	detected := resource.NewWithAttributes(semconv.SchemaURL, semconv.K8SPodName("pod"), semconv.K8SNodeName("node"))
+	cfg, err := NewConfig(context.Background(), "svc", "1.0", nil, exporter,
+		WithSampler(cluetrace.ParentRatio(1)),
+		WithResourceDetectors(
+			resource.StringDetector(semconv.SchemaURL, semconv.K8SPodNameKey, func() (string, error) { return "", errors.New("failed") }),
+			detectorFunc(func(context.Context) (*resource.Resource, error) { return detected, nil })),
+		WithResource(resource.NewSchemaless(semconv.K8SNodeName("override"))),
+		WithErrorHandler(errorHandlerFunc(func(err error) { handled = append(handled, err) })))
+	require.NoError(t, err)
+	_, span := cfg.TracerProvider.Tracer("test").Start(context.Background(), "span")
+	span.End()
+	require.NoError(t, cfg.TracerProvider.(*sdktrace.TracerProvider).ForceFlush(context.Background()))
+
+	assert.Len(t, handled, 1)
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+	attrs := spans[0].Resource.Attributes()
+	assert.Contains(t, attrs, semconv.ServiceName("svc"))
+	assert.Contains(t, attrs, semconv.K8SPodName("pod"))
+	assert.Contains(t, attrs, semconv.K8SNodeName("override"))
+}
+
+type (
+	detectorFunc     func(context.Context) (*resource.Resource, error)
+	errorHandlerFunc func(error)
+)
+
+func (f detectorFunc) Detect(ctx context.Context) (*resource.Resource, error) { return f(ctx) }
+func (f errorHandlerFunc) Handle(err error)                                   { f(err) }
+
+// newGCPMetadataServer returns a test GCP metadata server that serves the
+// given metadata.
+func newGCPMetadataServer(metadata map[string]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		v, ok := metadata[r.URL.Path[len("/computeMetadata/v1/"):]]
+		if !ok || r.Header.Get("Metadata-Flavor") != "Google" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(v))
+	}))
+}
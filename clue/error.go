@@ -0,0 +1,159 @@
+package clue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+	"goa.design/goa/v3/middleware"
+	goa "goa.design/goa/v3/pkg"
+
+	"goa.design/clue/log"
+)
+
+const (
+	// ErrorCounterName is the name of the counter incremented by
+	// RecordError.
+	ErrorCounterName = "errors"
+
+	// instrumentationName is the name of the instrumentation library used
+	// to create the metrics recorded by this package.
+	instrumentationName = "goa.design/clue/clue"
+)
+
+// TracedError is an error that records the identity of the trace, span and
+// request that created it so that errors surfaced later, for example by an
+// asynchronous worker or in an API response, can be traced back to the
+// originating request. Use errors.As to retrieve it from a chain of wrapped
+// errors.
+type TracedError struct {
+	// TraceID is the ID of the trace in hexadecimal form, empty if the
+	// error was created outside of a trace.
+	TraceID string
+	// SpanID is the ID of the span in hexadecimal form, empty if the error
+	// was created outside of a trace.
+	SpanID string
+	// RequestID is the ID of the request set by the Goa RequestID
+	// middleware, empty if not set.
+	RequestID string
+
+	// err is the wrapped error.
+	err error
+}
+
+var (
+	// errorCounterOnce protects the initialization of errorCounter.
+	errorCounterOnce sync.Once
+	// errorCounter counts the errors recorded with RecordError.
+	errorCounter metric.Int64Counter
+)
+
+// RecordError records err in all the telemetry signals in one call:
+//
+//   - it sets the status of the current span to error and adds an exception
+//     event with the given key/value pairs as attributes,
+//   - it writes an error log entry with the given key/value pairs,
+//   - it increments the "errors" counter created with the global meter
+//     provider (see ConfigureOpenTelemetry). The counter records the Goa
+//     service and method names if available in ctx (see log.Endpoint).
+//
+// RecordError does nothing if err is nil.
+//
+// Usage:
+//
+//	if err := svc.db.Save(ctx, order); err != nil {
+//	        clue.RecordError(ctx, err, log.KV{K: "order", V: order.ID})
+//	        return err
+//	}
+func RecordError(ctx context.Context, err error, keyvals ...log.Fielder) {
+	if err == nil {
+		return
+	}
+	span := trace.SpanFromContext(ctx)
+	span.RecordError(err, trace.WithAttributes(log.Attributes(keyvals...)...))
+	span.SetStatus(codes.Error, err.Error())
+	log.Error(ctx, err, keyvals...)
+
+	var attrs []attribute.KeyValue
+	if s, ok := ctx.Value(goa.ServiceKey).(string); ok {
+		attrs = append(attrs, attribute.String(log.GoaServiceKey, s))
+	}
+	if m, ok := ctx.Value(goa.MethodKey).(string); ok {
+		attrs = append(attrs, attribute.String(log.GoaMethodKey, m))
+	}
+	errorCounterOnce.Do(func() {
+		var cerr error
+		errorCounter, cerr = otel.Meter(instrumentationName).Int64Counter(ErrorCounterName,
+			metric.WithDescription("Number of errors recorded with clue.RecordError"))
+		if cerr != nil {
+			otel.Handle(cerr)
+		}
+	})
+	if errorCounter != nil {
+		errorCounter.Add(ctx, 1, metric.WithAttributes(attrs...))
+	}
+}
+
+// Errorf formats an error like fmt.Errorf and annotates it with the trace ID,
+// span ID and request ID stored in ctx, see TracedError. The error message ends
+// with the identifiers (e.g. "boom (trace-id=..., span-id=..., request-id=...)")
+// unless it wraps another TracedError whose message already includes them.
+//
+// Usage:
+//
+//	if err := svc.db.Save(ctx, order); err != nil {
+//	        return clue.Errorf(ctx, "failed to save order %s: %w", order.ID, err)
+//	}
+//
+// and later, possibly in a different goroutine:
+//
+//	var terr *clue.TracedError
+//	if errors.As(err, &terr) {
+//	        resp.TraceID = terr.TraceID
+//	}
+func Errorf(ctx context.Context, format string, args ...interface{}) error {
+	terr := &TracedError{err: fmt.Errorf(format, args...)}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		terr.TraceID = sc.TraceID().String()
+		terr.SpanID = sc.SpanID().String()
+	}
+	if reqID, ok := ctx.Value(middleware.RequestIDKey).(string); ok {
+		terr.RequestID = reqID
+	}
+	return terr
+}
+
+// Error returns the error message followed by the trace, span and request IDs.
+func (e *TracedError) Error() string {
+	msg := e.err.Error()
+	var inner *TracedError
+	if errors.As(e.err, &inner) {
+		return msg
+	}
+	var ids []string
+	for _, id := range []struct{ key, val string }{
+		{log.TraceIDKey, e.TraceID},
+		{log.SpanIDKey, e.SpanID},
+		{log.RequestIDKey, e.RequestID},
+	} {
+		if id.val != "" {
+			ids = append(ids, id.key+"="+id.val)
+		}
+	}
+	if len(ids) == 0 {
+		return msg
+	}
+	return msg + " (" + strings.Join(ids, ", ") + ")"
+}
+
+// Unwrap returns the wrapped error if any.
+func (e *TracedError) Unwrap() error {
+	return errors.Unwrap(e.err)
+}
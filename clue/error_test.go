@@ -0,0 +1,111 @@
+package clue
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"goa.design/goa/v3/middleware"
+	goa "goa.design/goa/v3/pkg"
+
+	"goa.design/clue/log"
+)
+
+func TestRecordError(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	restore := otel.GetMeterProvider()
+	otel.SetMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)))
+	defer otel.SetMeterProvider(restore)
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	var buf bytes.Buffer
+	ctx := log.Context(context.Background(), log.WithOutput(&buf), log.WithFormat(log.FormatJSON))
+	ctx = context.WithValue(ctx, goa.ServiceKey, "Service")
+	ctx = context.WithValue(ctx, goa.MethodKey, "Method")
+	ctx, span := provider.Tracer("test").Start(ctx, "span")
+
+	RecordError(ctx, nil)
+	RecordError(ctx, errors.New("boom"), log.KV{K: "order", V: 42})
+	span.End()
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, codes.Error, spans[0].Status.Code)
+	assert.Equal(t, "boom", spans[0].Status.Description)
+	require.Len(t, spans[0].Events, 1)
+	assert.Equal(t, "exception", spans[0].Events[0].Name)
+	assert.Contains(t, spans[0].Events[0].Attributes, log.Attributes(log.KV{K: "order", V: 42})[0])
+
+	assert.Contains(t, buf.String(), `"err":"boom"`)
+	assert.Contains(t, buf.String(), `"order":42`)
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+	require.Len(t, rm.ScopeMetrics, 1)
+	require.Len(t, rm.ScopeMetrics[0].Metrics, 1)
+	m := rm.ScopeMetrics[0].Metrics[0]
+	assert.Equal(t, ErrorCounterName, m.Name)
+	sum := m.Data.(metricdata.Sum[int64])
+	require.Len(t, sum.DataPoints, 1)
+	assert.Equal(t, int64(1), sum.DataPoints[0].Value)
+	svc, _ := sum.DataPoints[0].Attributes.Value(attribute.Key(log.GoaServiceKey))
+	assert.Equal(t, "Service", svc.AsString())
+	meth, _ := sum.DataPoints[0].Attributes.Value(attribute.Key(log.GoaMethodKey))
+	assert.Equal(t, "Method", meth.AsString())
+}
+
+func TestErrorf(t *testing.T) {
+	provider := sdktrace.NewTracerProvider()
+	traced, span := provider.Tracer("test").Start(context.Background(), "span")
+	defer span.End()
+	traceID := span.SpanContext().TraceID().String()
+	spanID := span.SpanContext().SpanID().String()
+	base := errors.New("boom")
+
+	cases := []struct {
+		name        string
+		ctx         context.Context
+		err         error
+		expectedMsg string
+	}{
+		{"no identity", context.Background(), Errorf(context.Background(), "failed: %w", base), "failed: boom"},
+		{"request id", context.WithValue(context.Background(), middleware.RequestIDKey, "req"), nil, "failed: boom (request-id=req)"},
+		{"trace", traced, nil, "failed: boom (trace-id=" + traceID + ", span-id=" + spanID + ")"},
+		{"trace and request id", context.WithValue(traced, middleware.RequestIDKey, "req"), nil, "failed: boom (trace-id=" + traceID + ", span-id=" + spanID + ", request-id=req)"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := c.err
+			if err == nil {
+				err = Errorf(c.ctx, "failed: %w", base)
+			}
+			assert.Equal(t, c.expectedMsg, err.Error())
+			assert.ErrorIs(t, err, base)
+			var terr *TracedError
+			require.ErrorAs(t, err, &terr)
+		})
+	}
+}
+
+func TestErrorfWrapped(t *testing.T) {
+	ctx := context.WithValue(context.Background(), middleware.RequestIDKey, "req")
+	inner := Errorf(ctx, "boom")
+
+	err := Errorf(context.Background(), "failed: %w", inner)
+
+	assert.Equal(t, "failed: boom (request-id=req)", err.Error())
+	var terr *TracedError
+	require.ErrorAs(t, err, &terr)
+	assert.Empty(t, terr.RequestID, "outermost error")
+	assert.ErrorIs(t, err, inner)
+}
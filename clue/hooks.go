@@ -0,0 +1,26 @@
+package clue
+
+import (
+	"context"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// spanStartHooks is a span processor that calls the span start hooks.
+type spanStartHooks []SpanStartHook
+
+// OnStart calls the hooks in order.
+func (h spanStartHooks) OnStart(ctx context.Context, span sdktrace.ReadWriteSpan) {
+	for _, hook := range h {
+		hook(ctx, span)
+	}
+}
+
+// OnEnd does nothing.
+func (spanStartHooks) OnEnd(sdktrace.ReadOnlySpan) {}
+
+// Shutdown does nothing.
+func (spanStartHooks) Shutdown(context.Context) error { return nil }
+
+// ForceFlush does nothing.
+func (spanStartHooks) ForceFlush(context.Context) error { return nil }
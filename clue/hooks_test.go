@@ -0,0 +1,49 @@
+package clue
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+type tenantKey struct{}
+
+func TestSpanProcessorAndStartHook(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	var calls []string
+	cfg, err := NewConfig(context.Background(), "svc", "1.0", nil, nil,
+		WithSpanProcessor(sdktrace.NewSimpleSpanProcessor(exporter)),
+		WithSpanStartHook(func(ctx context.Context, span sdktrace.ReadWriteSpan) {
+			calls = append(calls, "first")
+			if tenant, ok := ctx.Value(tenantKey{}).(string); ok {
+				span.SetAttributes(attribute.String("tenant", tenant))
+			}
+		}),
+		WithSpanStartHook(func(context.Context, sdktrace.ReadWriteSpan) {
+			calls = append(calls, "second")
+		}))
+	require.NoError(t, err)
+	require.IsType(t, &sdktrace.TracerProvider{}, cfg.TracerProvider)
+
+	ctx := context.WithValue(context.Background(), tenantKey{}, "acme")
+	_, span := cfg.TracerProvider.Tracer("test").Start(ctx, "test-span")
+	span.End()
+
+	assert.Equal(t, []string{"first", "second"}, calls)
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+	assert.Contains(t, spans[0].Attributes, attribute.String("tenant", "acme"))
+}
+
+func TestSpanStartHookWithoutProcessor(t *testing.T) {
+	cfg, err := NewConfig(context.Background(), "svc", "1.0", nil, nil,
+		WithSpanStartHook(func(context.Context, sdktrace.ReadWriteSpan) {}))
+	require.NoError(t, err)
+	_, ok := cfg.TracerProvider.(*sdktrace.TracerProvider)
+	assert.False(t, ok)
+}
@@ -0,0 +1,91 @@
+package clue
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+
+	"go.opentelemetry.io/otel/exporters/jaeger"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+type (
+	// JaegerOption is a function that configures the Jaeger exporter.
+	JaegerOption func(*jaegerOptions)
+
+	jaegerOptions struct {
+		username      string
+		password      string
+		client        *http.Client
+		maxPacketSize int
+	}
+)
+
+// WithJaegerExporter configures tracing to export spans to Jaeger. If endpoint
+// is a URL (e.g. "http://localhost:14268/api/traces") then spans are sent to
+// the Jaeger collector over HTTP. Otherwise endpoint must be the "host:port"
+// address of a Jaeger agent (e.g. "localhost:6831") and spans are sent over
+// UDP. The exporter is used in addition to the span exporter given to
+// NewConfig if any.
+func WithJaegerExporter(endpoint string, opts ...JaegerOption) Option {
+	return func(o *options) {
+		o.spanExporters = append(o.spanExporters, spanExporter{
+			newExporter: func(context.Context) (sdktrace.SpanExporter, error) {
+				return newJaegerExporter(endpoint, opts...)
+			},
+		})
+	}
+}
+
+// WithJaegerBasicAuth sets the username and password used to authenticate
+// with the Jaeger collector. It has no effect in agent mode.
+func WithJaegerBasicAuth(username, password string) JaegerOption {
+	return func(o *jaegerOptions) {
+		o.username = username
+		o.password = password
+	}
+}
+
+// WithJaegerHTTPClient sets the HTTP client used to send spans to the Jaeger
+// collector. It has no effect in agent mode.
+func WithJaegerHTTPClient(client *http.Client) JaegerOption {
+	return func(o *jaegerOptions) {
+		o.client = client
+	}
+}
+
+// WithJaegerMaxPacketSize sets the maximum size of the UDP packets sent to the
+// Jaeger agent. It has no effect in collector mode.
+func WithJaegerMaxPacketSize(size int) JaegerOption {
+	return func(o *jaegerOptions) {
+		o.maxPacketSize = size
+	}
+}
+
+// newJaegerExporter returns a Jaeger exporter for the given endpoint.
+func newJaegerExporter(endpoint string, opts ...JaegerOption) (sdktrace.SpanExporter, error) {
+	var o jaegerOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if strings.HasPrefix(endpoint, "http://") || strings.HasPrefix(endpoint, "https://") {
+		copts := []jaeger.CollectorEndpointOption{jaeger.WithEndpoint(endpoint)}
+		if o.username != "" {
+			copts = append(copts, jaeger.WithUsername(o.username), jaeger.WithPassword(o.password))
+		}
+		if o.client != nil {
+			copts = append(copts, jaeger.WithHTTPClient(o.client))
+		}
+		return jaeger.New(jaeger.WithCollectorEndpoint(copts...))
+	}
+	host, port, err := net.SplitHostPort(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	aopts := []jaeger.AgentEndpointOption{jaeger.WithAgentHost(host), jaeger.WithAgentPort(port)}
+	if o.maxPacketSize > 0 {
+		aopts = append(aopts, jaeger.WithMaxPacketSize(o.maxPacketSize))
+	}
+	return jaeger.New(jaeger.WithAgentEndpoint(aopts...))
+}
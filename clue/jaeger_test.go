@@ -0,0 +1,44 @@
+package clue
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+func TestJaegerCollector(t *testing.T) {
+	var user, pass string
+	var called bool
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		user, pass, _ = r.BasicAuth()
+	}))
+	defer svr.Close()
+
+	cfg, err := NewConfig(context.Background(), "svc", "1.0", nil, nil,
+		WithJaegerExporter(svr.URL, WithJaegerBasicAuth("user", "pass"), WithJaegerHTTPClient(svr.Client())))
+	require.NoError(t, err)
+	require.IsType(t, &sdktrace.TracerProvider{}, cfg.TracerProvider)
+	_, span := cfg.TracerProvider.Tracer("test").Start(context.Background(), "span")
+	span.End()
+	require.NoError(t, cfg.TracerProvider.(*sdktrace.TracerProvider).Shutdown(context.Background()))
+	assert.True(t, called)
+	assert.Equal(t, "user", user)
+	assert.Equal(t, "pass", pass)
+}
+
+func TestJaegerAgent(t *testing.T) {
+	exporter, err := newJaegerExporter("localhost:6831", WithJaegerMaxPacketSize(1000))
+	require.NoError(t, err)
+	assert.NoError(t, exporter.Shutdown(context.Background()))
+}
+
+func TestJaegerInvalidEndpoint(t *testing.T) {
+	_, err := NewConfig(context.Background(), "svc", "1.0", nil, nil, WithJaegerExporter("invalid"))
+	assert.Error(t, err)
+}
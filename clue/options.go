@@ -0,0 +1,301 @@
+package clue
+
+import (
+	"context"
+	"io"
+	"os"
+	"time"
+
+	"go.opentelemetry.io/contrib/propagators/b3"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+
+	cluetrace "goa.design/clue/trace"
+)
+
+type (
+	// Option is a function that initializes the OpenTelemetry configuration.
+	Option func(*options)
+
+	// spanExporter creates a span exporter and the options of the
+	// corresponding batch span processor.
+	spanExporter struct {
+		newExporter  func(context.Context) (sdktrace.SpanExporter, error)
+		batchOptions []sdktrace.BatchSpanProcessorOption
+	}
+
+	// SpanStartHook is called when a span starts with the context used to
+	// create the span. Hooks may set span attributes, for example the
+	// tenant or user recorded in the context.
+	SpanStartHook func(ctx context.Context, span sdktrace.ReadWriteSpan)
+
+	// options contains the configuration options for OpenTelemetry.
+	options struct {
+		// maxSamplingRate is the maximum sampling rate for the trace exporter.
+		maxSamplingRate int
+		// sampleSize is the number of requests between two adjustments of
+		// the sampling rate.
+		sampleSize int
+		// samplerOptions configure the sampler, e.g. per route ratios.
+		samplerOptions []cluetrace.SamplerOption
+		// tailSampling enables tail sampling.
+		tailSampling bool
+		// tailOptions configure the tail sampler.
+		tailOptions []cluetrace.TailOption
+		// b3 enables B3 propagation.
+		b3 bool
+		// b3Options configure the B3 propagator.
+		b3Options []b3.Option
+		// xray enables AWS X-Ray propagation and trace IDs.
+		xray bool
+		// spanLimits limits the number and size of span attributes,
+		// events and links.
+		spanLimits sdktrace.SpanLimits
+		// readerInterval is the interval at which the metrics reader is
+		// invoked.
+		readerInterval time.Duration
+		// resource is merged with the resource created from the service
+		// name and version.
+		resource *resource.Resource
+		// resourceDetectors detect additional resource attributes, e.g.
+		// cloud or Kubernetes attributes.
+		resourceDetectors []resource.Detector
+		// propagators is the trace propagator.
+		propagators propagation.TextMapPropagator
+		// errorHandler is the error handler used by OpenTelemetry.
+		errorHandler otel.ErrorHandler
+		// metricExporters create additional metric exporters.
+		metricExporters []func(context.Context) (sdkmetric.Exporter, error)
+		// spanExporters create additional span exporters.
+		spanExporters []spanExporter
+		// spanProcessors are user provided span processors.
+		spanProcessors []sdktrace.SpanProcessor
+		// spanStartHooks are called when spans start.
+		spanStartHooks []SpanStartHook
+		// flushers are flushed on shutdown.
+		flushers []Flusher
+		// profiler configures continuous profiling if not nil.
+		profiler *profilerConfig
+		// debugExporters enables the console span and metric exporters.
+		debugExporters bool
+		// debugOutput is the writer used by the console exporters.
+		debugOutput io.Writer
+	}
+)
+
+const (
+	// DefaultMaxSamplingRate is the default maximum sampling rate in
+	// requests per second.
+	DefaultMaxSamplingRate = 2
+	// DefaultSampleSize is the default number of requests between two
+	// adjustments of the sampling rate.
+	DefaultSampleSize = 10
+	// DefaultReaderInterval is the default interval at which metrics are
+	// exported.
+	DefaultReaderInterval = time.Minute
+	// DefaultAttributeValueLengthLimit is the default maximum length of
+	// string span attribute values, longer values are truncated.
+	DefaultAttributeValueLengthLimit = 4096
+)
+
+// defaultOptions returns a new options struct with default values. The logger
+// in ctx is used to log OpenTelemetry errors.
+func defaultOptions(ctx context.Context) *options {
+	return &options{
+		maxSamplingRate: DefaultMaxSamplingRate,
+		sampleSize:      DefaultSampleSize,
+		readerInterval:  DefaultReaderInterval,
+		propagators:     propagation.TraceContext{},
+		errorHandler:    NewErrorHandler(ctx),
+		debugOutput:     os.Stdout,
+		spanLimits:      defaultSpanLimits(),
+	}
+}
+
+// defaultSpanLimits returns the OpenTelemetry span limits configured via the
+// OTEL_SPAN_* and OTEL_*_LIMIT environment variables if any, the
+// OpenTelemetry defaults otherwise. The length of attribute values is capped
+// to DefaultAttributeValueLengthLimit unless set via environment variables.
+func defaultSpanLimits() sdktrace.SpanLimits {
+	limits := sdktrace.NewSpanLimits()
+	if limits.AttributeValueLengthLimit < 0 {
+		limits.AttributeValueLengthLimit = DefaultAttributeValueLengthLimit
+	}
+	return limits
+}
+
+// WithMaxSamplingRate sets the maximum sampling rate in requests per second.
+func WithMaxSamplingRate(rate int) Option {
+	return func(opts *options) {
+		opts.maxSamplingRate = rate
+	}
+}
+
+// WithSampleSize sets the number of requests between two adjustments of the
+// sampling rate.
+func WithSampleSize(size int) Option {
+	return func(opts *options) {
+		opts.sampleSize = size
+	}
+}
+
+// WithSampler configures the sampler of the tracer provider created by
+// NewConfig using the trace package sampler options. This makes it possible
+// to declare per route sampling ratios once for both the HTTP and gRPC
+// servers, for example:
+//
+//	clue.WithSampler(
+//		trace.RouteRatio(1, "/payments/*"),
+//		trace.RouteRatio(0.01, "/search"),
+//		trace.NeverRoute("grpc.health.v1.Health/*"),
+//	)
+//
+// HTTP routes are matched against the request path, gRPC routes against the
+// full method name (see trace.AlwaysRoute).
+func WithSampler(samplerOptions ...cluetrace.SamplerOption) Option {
+	return func(opts *options) {
+		opts.samplerOptions = append(opts.samplerOptions, samplerOptions...)
+	}
+}
+
+// WithTailSampling enables tail sampling of the spans exported by the span
+// exporters, see trace.NewTailSampler. The root sampler samples all traces
+// when tail sampling is enabled so that all traces are candidates, use
+// WithSampler to override. The span processors configured via
+// WithSpanProcessor receive all the sampled spans.
+func WithTailSampling(tailOptions ...cluetrace.TailOption) Option {
+	return func(opts *options) {
+		opts.tailSampling = true
+		opts.tailOptions = append(opts.tailOptions, tailOptions...)
+	}
+}
+
+// WithB3Propagation adds B3 propagation alongside the propagators set with
+// WithPropagators, see trace.WithB3Propagation.
+func WithB3Propagation(b3Options ...b3.Option) Option {
+	return func(opts *options) {
+		opts.b3 = true
+		opts.b3Options = append(opts.b3Options, b3Options...)
+	}
+}
+
+// WithXRay adds AWS X-Ray propagation alongside the propagators set with
+// WithPropagators and makes the tracer provider generate X-Ray compatible
+// trace IDs, see trace.WithXRay.
+func WithXRay() Option {
+	return func(opts *options) {
+		opts.xray = true
+	}
+}
+
+// WithReaderInterval sets the interval at which metrics are exported.
+func WithReaderInterval(interval time.Duration) Option {
+	return func(opts *options) {
+		opts.readerInterval = interval
+	}
+}
+
+// WithResource sets a resource merged with the resource created from the
+// service name and version. Attributes of res take precedence.
+func WithResource(res *resource.Resource) Option {
+	return func(opts *options) {
+		opts.resource = res
+	}
+}
+
+// WithResourceDetectors adds resource detectors whose attributes are merged
+// with the resource created from the service name and version, for example:
+//
+//	clue.WithResourceDetectors(clue.KubernetesDetector(), clue.GCEDetector())
+//
+// Detection errors are reported to the error handler and the attributes
+// detected successfully are still recorded. Attributes set via WithResource
+// take precedence over detected attributes.
+func WithResourceDetectors(detectors ...resource.Detector) Option {
+	return func(opts *options) {
+		opts.resourceDetectors = append(opts.resourceDetectors, detectors...)
+	}
+}
+
+// WithPropagators sets the propagators used to propagate trace context.
+func WithPropagators(propagator propagation.TextMapPropagator) Option {
+	return func(opts *options) {
+		opts.propagators = propagator
+	}
+}
+
+// WithErrorHandler sets the error handler used by OpenTelemetry. The default
+// logs errors using the logger in the context given to NewConfig.
+func WithErrorHandler(errorHandler otel.ErrorHandler) Option {
+	return func(opts *options) {
+		opts.errorHandler = errorHandler
+	}
+}
+
+// WithSpanProcessor appends the given span processor to the processors of the
+// tracer provider created by NewConfig. The processors are invoked in order
+// after the processors of the configured exporters.
+func WithSpanProcessor(processor sdktrace.SpanProcessor) Option {
+	return func(opts *options) {
+		opts.spanProcessors = append(opts.spanProcessors, processor)
+	}
+}
+
+// WithSpanStartHook registers a function called when spans start, before any
+// span processor. Hooks are typically used to add attributes stored in the
+// context (e.g. tenant or user IDs) to all spans.
+func WithSpanStartHook(hook SpanStartHook) Option {
+	return func(opts *options) {
+		opts.spanStartHooks = append(opts.spanStartHooks, hook)
+	}
+}
+
+// WithFlushers adds asynchronous telemetry writers flushed by Shutdown after
+// the spans and metrics, for example a log.KafkaSink used as log output:
+//
+//	sink := log.NewKafkaSink(producer, "logs")
+//	ctx := log.Context(ctx, log.WithOutput(sink))
+//	cfg, err := clue.NewConfig(ctx, "svc", "1.0.0", metricExporter, spanExporter,
+//	        clue.WithFlushers(sink))
+func WithFlushers(flushers ...Flusher) Option {
+	return func(opts *options) {
+		opts.flushers = append(opts.flushers, flushers...)
+	}
+}
+
+// WithAttributeCountLimit sets the maximum number of attributes of a span,
+// additional attributes are dropped. The default is 128. A negative value
+// means no limit.
+func WithAttributeCountLimit(limit int) Option {
+	return func(opts *options) {
+		opts.spanLimits.AttributeCountLimit = limit
+	}
+}
+
+// WithAttributeValueLengthLimit sets the maximum length of string span
+// attribute values, longer values are truncated. The default is
+// DefaultAttributeValueLengthLimit. A negative value means no limit.
+func WithAttributeValueLengthLimit(limit int) Option {
+	return func(opts *options) {
+		opts.spanLimits.AttributeValueLengthLimit = limit
+	}
+}
+
+// WithEventCountLimit sets the maximum number of events of a span, additional
+// events are dropped. The default is 128. A negative value means no limit.
+func WithEventCountLimit(limit int) Option {
+	return func(opts *options) {
+		opts.spanLimits.EventCountLimit = limit
+	}
+}
+
+// WithLinkCountLimit sets the maximum number of links of a span, additional
+// links are dropped. The default is 128. A negative value means no limit.
+func WithLinkCountLimit(limit int) Option {
+	return func(opts *options) {
+		opts.spanLimits.LinkCountLimit = limit
+	}
+}
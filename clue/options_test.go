@@ -0,0 +1,60 @@
+package clue
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+
+	cluetrace "goa.design/clue/trace"
+)
+
+func TestOptions(t *testing.T) {
+	ctx := context.Background()
+	opts := defaultOptions(ctx)
+	assert.Equal(t, DefaultMaxSamplingRate, opts.maxSamplingRate)
+	assert.Equal(t, DefaultSampleSize, opts.sampleSize)
+	assert.Equal(t, DefaultReaderInterval, opts.readerInterval)
+	assert.Equal(t, propagation.TraceContext{}, opts.propagators)
+	assert.Equal(t, NewErrorHandler(ctx), opts.errorHandler)
+	assert.Equal(t, os.Stdout, opts.debugOutput)
+	assert.Equal(t, DefaultAttributeValueLengthLimit, opts.spanLimits.AttributeValueLengthLimit)
+	assert.Equal(t, sdktrace.DefaultAttributeCountLimit, opts.spanLimits.AttributeCountLimit)
+
+	res := resource.Empty()
+	handler := NewErrorHandler(context.Background())
+	WithMaxSamplingRate(3)(opts)
+	WithSampleSize(20)(opts)
+	WithReaderInterval(time.Second)(opts)
+	WithResource(res)(opts)
+	WithPropagators(propagation.Baggage{})(opts)
+	WithErrorHandler(handler)(opts)
+	WithDebugExporters()(opts)
+	WithSampler(cluetrace.RouteRatio(1, "/payments/*"), cluetrace.NeverRoute("/livez"))(opts)
+	WithAttributeCountLimit(10)(opts)
+	WithAttributeValueLengthLimit(100)(opts)
+	WithEventCountLimit(20)(opts)
+	WithLinkCountLimit(-1)(opts)
+	assert.Equal(t, 3, opts.maxSamplingRate)
+	assert.Equal(t, 20, opts.sampleSize)
+	assert.Equal(t, time.Second, opts.readerInterval)
+	assert.Equal(t, res, opts.resource)
+	assert.Equal(t, propagation.Baggage{}, opts.propagators)
+	assert.Equal(t, handler, opts.errorHandler)
+	assert.True(t, opts.debugExporters)
+	assert.Len(t, opts.samplerOptions, 2)
+	assert.Equal(t, 10, opts.spanLimits.AttributeCountLimit)
+	assert.Equal(t, 100, opts.spanLimits.AttributeValueLengthLimit)
+	assert.Equal(t, 20, opts.spanLimits.EventCountLimit)
+	assert.Equal(t, -1, opts.spanLimits.LinkCountLimit)
+}
+
+func TestDefaultSpanLimitsEnv(t *testing.T) {
+	t.Setenv("OTEL_SPAN_ATTRIBUTE_VALUE_LENGTH_LIMIT", "42")
+	assert.Equal(t, 42, defaultSpanLimits().AttributeValueLengthLimit)
+}
@@ -0,0 +1,297 @@
+package clue
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"time"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"google.golang.org/grpc/credentials"
+)
+
+type (
+	// OTLPOption is a function that configures the OTLP span and metric
+	// exporters.
+	OTLPOption func(*otlpOptions)
+
+	otlpOptions struct {
+		http        bool
+		insecure    bool
+		tlsConfig   *tls.Config
+		certFile    string
+		keyFile     string
+		caFile      string
+		headers     map[string]string
+		compression string
+		timeout     time.Duration
+		retry       *otlptracegrpc.RetryConfig
+	}
+)
+
+// WithOTLPExporter configures tracing to export spans to the OTLP endpoint at
+// the given "host:port" address. Spans are sent over gRPC unless WithOTLPHTTP
+// is used. Connections use TLS with the system root certificates unless
+// configured otherwise. The exporter is used in addition to the span exporter
+// given to NewConfig if any.
+//
+// Example:
+//
+//	cfg, err := clue.NewConfig(ctx, "svc", "1.0.0", nil, nil,
+//		clue.WithOTLPExporter("otlp.vendor.com:4317",
+//			clue.WithOTLPBearerToken(token),
+//			clue.WithOTLPCompression("gzip")))
+func WithOTLPExporter(endpoint string, opts ...OTLPOption) Option {
+	return func(o *options) {
+		o.spanExporters = append(o.spanExporters, spanExporter{
+			newExporter: func(ctx context.Context) (sdktrace.SpanExporter, error) {
+				return newOTLPSpanExporter(ctx, endpoint, opts...)
+			},
+		})
+	}
+}
+
+// WithOTLPMetricExporter configures metrics to be exported to the OTLP
+// endpoint at the given "host:port" address. It accepts the same options as
+// WithOTLPExporter. The exporter is used in addition to the metric exporter
+// given to NewConfig if any.
+func WithOTLPMetricExporter(endpoint string, opts ...OTLPOption) Option {
+	return func(o *options) {
+		o.metricExporters = append(o.metricExporters, func(ctx context.Context) (sdkmetric.Exporter, error) {
+			return newOTLPMetricExporter(ctx, endpoint, opts...)
+		})
+	}
+}
+
+// WithOTLPHTTP sends telemetry using OTLP over HTTP with protobuf payloads
+// instead of gRPC. Spans and metrics are posted to the default "/v1/traces"
+// and "/v1/metrics" paths of the endpoint.
+func WithOTLPHTTP() OTLPOption {
+	return func(o *otlpOptions) {
+		o.http = true
+	}
+}
+
+// WithOTLPInsecure disables TLS, not for use in production.
+func WithOTLPInsecure() OTLPOption {
+	return func(o *otlpOptions) {
+		o.insecure = true
+	}
+}
+
+// WithOTLPTLSConfig sets the TLS configuration used to connect to the
+// endpoint. The client certificate and root CAs set via
+// WithOTLPClientCertificate and WithOTLPRootCAs are added to a copy of cfg.
+func WithOTLPTLSConfig(cfg *tls.Config) OTLPOption {
+	return func(o *otlpOptions) {
+		o.tlsConfig = cfg
+	}
+}
+
+// WithOTLPClientCertificate sets the PEM encoded client certificate and key
+// files used to authenticate with the endpoint (mTLS).
+func WithOTLPClientCertificate(certFile, keyFile string) OTLPOption {
+	return func(o *otlpOptions) {
+		o.certFile = certFile
+		o.keyFile = keyFile
+	}
+}
+
+// WithOTLPRootCAs sets the PEM encoded certificate authorities file used to
+// verify the endpoint certificate.
+func WithOTLPRootCAs(caFile string) OTLPOption {
+	return func(o *otlpOptions) {
+		o.caFile = caFile
+	}
+}
+
+// WithOTLPHeaders sets headers sent with each export request.
+func WithOTLPHeaders(headers map[string]string) OTLPOption {
+	return func(o *otlpOptions) {
+		if o.headers == nil {
+			o.headers = make(map[string]string, len(headers))
+		}
+		for k, v := range headers {
+			o.headers[k] = v
+		}
+	}
+}
+
+// WithOTLPBearerToken sets the bearer token sent in the authorization header
+// of each export request.
+func WithOTLPBearerToken(token string) OTLPOption {
+	return WithOTLPHeaders(map[string]string{"authorization": "Bearer " + token})
+}
+
+// WithOTLPCompression sets the compression used to send telemetry, e.g.
+// "gzip". Only "gzip" is supported with WithOTLPHTTP.
+func WithOTLPCompression(compression string) OTLPOption {
+	return func(o *otlpOptions) {
+		o.compression = compression
+	}
+}
+
+// WithOTLPTimeout sets the maximum duration of each export request.
+func WithOTLPTimeout(timeout time.Duration) OTLPOption {
+	return func(o *otlpOptions) {
+		o.timeout = timeout
+	}
+}
+
+// WithOTLPRetry sets the retry policy used when export requests fail.
+func WithOTLPRetry(retry otlptracegrpc.RetryConfig) OTLPOption {
+	return func(o *otlpOptions) {
+		o.retry = &retry
+	}
+}
+
+// newOTLPSpanExporter returns an OTLP span exporter for the given endpoint.
+func newOTLPSpanExporter(ctx context.Context, endpoint string, opts ...OTLPOption) (sdktrace.SpanExporter, error) {
+	o, err := newOTLPOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	if o.http {
+		eopts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(endpoint)}
+		if o.insecure {
+			eopts = append(eopts, otlptracehttp.WithInsecure())
+		} else {
+			eopts = append(eopts, otlptracehttp.WithTLSClientConfig(o.tlsConfig))
+		}
+		if len(o.headers) > 0 {
+			eopts = append(eopts, otlptracehttp.WithHeaders(o.headers))
+		}
+		if o.compression != "" {
+			eopts = append(eopts, otlptracehttp.WithCompression(otlptracehttp.GzipCompression))
+		}
+		if o.timeout > 0 {
+			eopts = append(eopts, otlptracehttp.WithTimeout(o.timeout))
+		}
+		if o.retry != nil {
+			eopts = append(eopts, otlptracehttp.WithRetry(otlptracehttp.RetryConfig(*o.retry)))
+		}
+		return otlptracehttp.New(ctx, eopts...)
+	}
+	eopts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(endpoint)}
+	if o.insecure {
+		eopts = append(eopts, otlptracegrpc.WithInsecure())
+	} else {
+		eopts = append(eopts, otlptracegrpc.WithTLSCredentials(credentials.NewTLS(o.tlsConfig)))
+	}
+	if len(o.headers) > 0 {
+		eopts = append(eopts, otlptracegrpc.WithHeaders(o.headers))
+	}
+	if o.compression != "" {
+		eopts = append(eopts, otlptracegrpc.WithCompressor(o.compression))
+	}
+	if o.timeout > 0 {
+		eopts = append(eopts, otlptracegrpc.WithTimeout(o.timeout))
+	}
+	if o.retry != nil {
+		eopts = append(eopts, otlptracegrpc.WithRetry(*o.retry))
+	}
+	return otlptracegrpc.New(ctx, eopts...)
+}
+
+// newOTLPMetricExporter returns an OTLP metric exporter for the given
+// endpoint.
+func newOTLPMetricExporter(ctx context.Context, endpoint string, opts ...OTLPOption) (sdkmetric.Exporter, error) {
+	o, err := newOTLPOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	if o.http {
+		eopts := []otlpmetrichttp.Option{otlpmetrichttp.WithEndpoint(endpoint)}
+		if o.insecure {
+			eopts = append(eopts, otlpmetrichttp.WithInsecure())
+		} else {
+			eopts = append(eopts, otlpmetrichttp.WithTLSClientConfig(o.tlsConfig))
+		}
+		if len(o.headers) > 0 {
+			eopts = append(eopts, otlpmetrichttp.WithHeaders(o.headers))
+		}
+		if o.compression != "" {
+			eopts = append(eopts, otlpmetrichttp.WithCompression(otlpmetrichttp.GzipCompression))
+		}
+		if o.timeout > 0 {
+			eopts = append(eopts, otlpmetrichttp.WithTimeout(o.timeout))
+		}
+		if o.retry != nil {
+			eopts = append(eopts, otlpmetrichttp.WithRetry(otlpmetrichttp.RetryConfig(*o.retry)))
+		}
+		return otlpmetrichttp.New(ctx, eopts...)
+	}
+	eopts := []otlpmetricgrpc.Option{otlpmetricgrpc.WithEndpoint(endpoint)}
+	if o.insecure {
+		eopts = append(eopts, otlpmetricgrpc.WithInsecure())
+	} else {
+		eopts = append(eopts, otlpmetricgrpc.WithTLSCredentials(credentials.NewTLS(o.tlsConfig)))
+	}
+	if len(o.headers) > 0 {
+		eopts = append(eopts, otlpmetricgrpc.WithHeaders(o.headers))
+	}
+	if o.compression != "" {
+		eopts = append(eopts, otlpmetricgrpc.WithCompressor(o.compression))
+	}
+	if o.timeout > 0 {
+		eopts = append(eopts, otlpmetricgrpc.WithTimeout(o.timeout))
+	}
+	if o.retry != nil {
+		eopts = append(eopts, otlpmetricgrpc.WithRetry(otlpmetricgrpc.RetryConfig(*o.retry)))
+	}
+	return otlpmetricgrpc.New(ctx, eopts...)
+}
+
+// newOTLPOptions applies opts and validates the result, the TLS configuration
+// is resolved unless the connection is insecure.
+func newOTLPOptions(opts []OTLPOption) (*otlpOptions, error) {
+	var o otlpOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.http && o.compression != "" && o.compression != "gzip" {
+		return nil, fmt.Errorf("unsupported OTLP HTTP compression %q, must be gzip", o.compression)
+	}
+	if !o.insecure {
+		cfg, err := o.tls()
+		if err != nil {
+			return nil, err
+		}
+		o.tlsConfig = cfg
+	}
+	return &o, nil
+}
+
+// tls returns the TLS configuration built from the options.
+func (o *otlpOptions) tls() (*tls.Config, error) {
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+	if o.tlsConfig != nil {
+		cfg = o.tlsConfig.Clone()
+	}
+	if o.certFile != "" {
+		cert, err := tls.LoadX509KeyPair(o.certFile, o.keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load OTLP client certificate: %w", err)
+		}
+		cfg.Certificates = append(cfg.Certificates, cert)
+	}
+	if o.caFile != "" {
+		pem, err := os.ReadFile(o.caFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read OTLP root CAs: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("failed to parse OTLP root CAs in %s", o.caFile)
+		}
+		cfg.RootCAs = pool
+	}
+	return cfg, nil
+}
@@ -0,0 +1,225 @@
+package clue
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	collectormetrics "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	collectortrace "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestOTLPExporter(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	collector := &testCollector{}
+	svr := grpc.NewServer()
+	collectortrace.RegisterTraceServiceServer(svr, collector)
+	go svr.Serve(l)
+	defer svr.Stop()
+
+	cfg, err := NewConfig(context.Background(), "svc", "1.0", nil, nil,
+		WithOTLPExporter(l.Addr().String(),
+			WithOTLPInsecure(),
+			WithOTLPBearerToken("token"),
+			WithOTLPHeaders(map[string]string{"x-tenant": "acme"}),
+			WithOTLPCompression("gzip"),
+			WithOTLPTimeout(time.Second),
+			WithOTLPRetry(otlptracegrpc.RetryConfig{Enabled: false})))
+	require.NoError(t, err)
+	_, span := cfg.TracerProvider.Tracer("test").Start(context.Background(), "span")
+	span.End()
+	require.NoError(t, cfg.TracerProvider.(*sdktrace.TracerProvider).Shutdown(context.Background()))
+
+	require.NotNil(t, collector.md)
+	assert.Equal(t, []string{"Bearer token"}, collector.md.Get("authorization"))
+	assert.Equal(t, []string{"acme"}, collector.md.Get("x-tenant"))
+	assert.Equal(t, 1, collector.spans)
+}
+
+func TestOTLPMetricExporter(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	collector := &testMetricCollector{}
+	svr := grpc.NewServer()
+	collectormetrics.RegisterMetricsServiceServer(svr, collector)
+	go svr.Serve(l)
+	defer svr.Stop()
+
+	cfg, err := NewConfig(context.Background(), "svc", "1.0", nil, nil,
+		WithOTLPMetricExporter(l.Addr().String(),
+			WithOTLPInsecure(),
+			WithOTLPBearerToken("token"),
+			WithOTLPCompression("gzip"),
+			WithOTLPRetry(otlptracegrpc.RetryConfig{Enabled: false})))
+	require.NoError(t, err)
+	counter, err := cfg.MeterProvider.Meter("test").Int64Counter("counter")
+	require.NoError(t, err)
+	counter.Add(context.Background(), 1)
+	require.NoError(t, cfg.MeterProvider.(*sdkmetric.MeterProvider).Shutdown(context.Background()))
+
+	require.NotNil(t, collector.md)
+	assert.Equal(t, []string{"Bearer token"}, collector.md.Get("authorization"))
+	assert.Equal(t, 1, collector.metrics)
+}
+
+func TestOTLPHTTP(t *testing.T) {
+	var paths []string
+	var auth []string
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body) // nolint: errcheck
+		paths = append(paths, r.URL.Path)
+		auth = append(auth, r.Header.Get("Authorization"))
+	}))
+	defer svr.Close()
+	endpoint := svr.Listener.Addr().String()
+	opts := []OTLPOption{WithOTLPHTTP(), WithOTLPInsecure(), WithOTLPBearerToken("token"), WithOTLPCompression("gzip")}
+
+	cfg, err := NewConfig(context.Background(), "svc", "1.0", nil, nil,
+		WithOTLPExporter(endpoint, opts...),
+		WithOTLPMetricExporter(endpoint, opts...))
+	require.NoError(t, err)
+	_, span := cfg.TracerProvider.Tracer("test").Start(context.Background(), "span")
+	span.End()
+	counter, err := cfg.MeterProvider.Meter("test").Int64Counter("counter")
+	require.NoError(t, err)
+	counter.Add(context.Background(), 1)
+	require.NoError(t, cfg.TracerProvider.(*sdktrace.TracerProvider).Shutdown(context.Background()))
+	require.NoError(t, cfg.MeterProvider.(*sdkmetric.MeterProvider).Shutdown(context.Background()))
+
+	assert.Equal(t, []string{"/v1/traces", "/v1/metrics"}, paths)
+	assert.Equal(t, []string{"Bearer token", "Bearer token"}, auth)
+}
+
+func TestOTLPHTTPCompression(t *testing.T) {
+	_, err := NewConfig(context.Background(), "svc", "1.0", nil, nil,
+		WithOTLPExporter("localhost:4318", WithOTLPHTTP(), WithOTLPCompression("zstd")))
+	assert.Error(t, err)
+	_, err = NewConfig(context.Background(), "svc", "1.0", nil, nil,
+		WithOTLPMetricExporter("localhost:4318", WithOTLPHTTP(), WithOTLPCompression("zstd")))
+	assert.Error(t, err)
+}
+
+func TestOTLPTLS(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeTestCertificate(t, dir)
+
+	o := &otlpOptions{}
+	WithOTLPClientCertificate(certFile, keyFile)(o)
+	WithOTLPRootCAs(certFile)(o)
+	cfg, err := o.tls()
+	require.NoError(t, err)
+	assert.Len(t, cfg.Certificates, 1)
+	assert.NotNil(t, cfg.RootCAs)
+
+	o = &otlpOptions{}
+	WithOTLPTLSConfig(cfg)(o)
+	cfg2, err := o.tls()
+	require.NoError(t, err)
+	assert.Len(t, cfg2.Certificates, 1)
+
+	_, err = NewConfig(context.Background(), "svc", "1.0", nil, nil,
+		WithOTLPExporter("localhost:4317", WithOTLPClientCertificate(certFile, keyFile), WithOTLPRootCAs(certFile)))
+	assert.NoError(t, err)
+}
+
+func TestOTLPTLSErrors(t *testing.T) {
+	dir := t.TempDir()
+	invalid := filepath.Join(dir, "invalid.pem")
+	require.NoError(t, os.WriteFile(invalid, []byte("invalid"), 0600))
+	cases := []struct {
+		name string
+		opt  OTLPOption
+	}{
+		{"missing certificate", WithOTLPClientCertificate(filepath.Join(dir, "missing.pem"), invalid)},
+		{"missing root CAs", WithOTLPRootCAs(filepath.Join(dir, "missing.pem"))},
+		{"invalid root CAs", WithOTLPRootCAs(invalid)},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := NewConfig(context.Background(), "svc", "1.0", nil, nil,
+				WithOTLPExporter("localhost:4317", c.opt))
+			assert.Error(t, err)
+			_, err = NewConfig(context.Background(), "svc", "1.0", nil, nil,
+				WithOTLPMetricExporter("localhost:4317", c.opt))
+			assert.Error(t, err)
+		})
+	}
+}
+
+type testCollector struct {
+	collectortrace.UnimplementedTraceServiceServer
+	md    metadata.MD
+	spans int
+}
+
+func (c *testCollector) Export(ctx context.Context, req *collectortrace.ExportTraceServiceRequest) (*collectortrace.ExportTraceServiceResponse, error) {
+	c.md, _ = metadata.FromIncomingContext(ctx)
+	for _, rs := range req.ResourceSpans {
+		for _, ss := range rs.ScopeSpans {
+			c.spans += len(ss.Spans)
+		}
+	}
+	return &collectortrace.ExportTraceServiceResponse{}, nil
+}
+
+type testMetricCollector struct {
+	collectormetrics.UnimplementedMetricsServiceServer
+	md      metadata.MD
+	metrics int
+}
+
+func (c *testMetricCollector) Export(ctx context.Context, req *collectormetrics.ExportMetricsServiceRequest) (*collectormetrics.ExportMetricsServiceResponse, error) {
+	c.md, _ = metadata.FromIncomingContext(ctx)
+	for _, rm := range req.ResourceMetrics {
+		for _, sm := range rm.ScopeMetrics {
+			c.metrics += len(sm.Metrics)
+		}
+	}
+	return &collectormetrics.ExportMetricsServiceResponse{}, nil
+}
+
+// writeTestCertificate writes a self-signed certificate and its key to dir.
+func writeTestCertificate(t *testing.T, dir string) (string, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "localhost"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600))
+	return certFile, keyFile
+}
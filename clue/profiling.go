@@ -0,0 +1,290 @@
+package clue
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+
+	rpprof "runtime/pprof"
+)
+
+type (
+	// ProfileUploader pushes profiles to a continuous profiling backend.
+	// PyroscopeUploader returns an uploader for Pyroscope, other backends
+	// such as Parca or Cloud Profiler can be supported by implementing the
+	// interface with their client libraries.
+	ProfileUploader interface {
+		// Upload pushes the given profile.
+		Upload(ctx context.Context, p *Profile) error
+	}
+
+	// Profile is a profile captured by a Profiler.
+	Profile struct {
+		// Type is the profile type, e.g. ProfileCPU or ProfileHeap.
+		Type string
+		// Start is the time the capture started.
+		Start time.Time
+		// End is the time the capture ended.
+		End time.Time
+		// Labels contains the service name and version under the
+		// "service" and "version" keys and the labels configured with
+		// WithProfileLabels.
+		Labels map[string]string
+		// Data is the gzip compressed protobuf pprof profile.
+		Data []byte
+	}
+
+	// Profiler periodically captures profiles and pushes them to a
+	// continuous profiling backend.
+	Profiler struct {
+		uploader     ProfileUploader
+		types        []string
+		interval     time.Duration
+		cpuDuration  time.Duration
+		labels       map[string]string
+		errorHandler otel.ErrorHandler
+		lock         sync.Mutex
+		stop         chan struct{}
+		done         chan struct{}
+	}
+
+	// profilerConfig is the configuration of the profiler created by
+	// NewConfig.
+	profilerConfig struct {
+		uploader ProfileUploader
+		opts     []ProfilingOption
+	}
+
+	// ProfilingOption is a function that configures a Profiler.
+	ProfilingOption func(*profilingOptions)
+
+	profilingOptions struct {
+		types        []string
+		interval     time.Duration
+		cpuDuration  time.Duration
+		labels       map[string]string
+		errorHandler otel.ErrorHandler
+	}
+)
+
+const (
+	// ProfileCPU is the type of CPU profiles.
+	ProfileCPU = "cpu"
+	// ProfileHeap is the type of heap profiles.
+	ProfileHeap = "heap"
+	// ProfileGoroutine is the type of goroutine profiles.
+	ProfileGoroutine = "goroutine"
+
+	// DefaultProfilingInterval is the default interval between two
+	// captures.
+	DefaultProfilingInterval = time.Minute
+	// DefaultCPUProfileDuration is the default duration of CPU profiles.
+	DefaultCPUProfileDuration = 10 * time.Second
+)
+
+// WithProfiling configures a Profiler that periodically captures profiles and
+// pushes them to uploader labeled with the service name and version given to
+// NewConfig. The profiler is started by ConfigureOpenTelemetry and stopped by
+// Shutdown.
+//
+// Example:
+//
+//	cfg, err := clue.NewConfig(ctx, "svc", "1.0.0", metricExporter, spanExporter,
+//	        clue.WithProfiling(clue.PyroscopeUploader("http://pyroscope:4040"),
+//	                clue.WithProfilingInterval(30*time.Second)))
+func WithProfiling(uploader ProfileUploader, opts ...ProfilingOption) Option {
+	return func(o *options) {
+		o.profiler = &profilerConfig{uploader: uploader, opts: opts}
+	}
+}
+
+// WithProfileTypes sets the types of profiles captured by the profiler. The
+// types are ProfileCPU and the names of the runtime/pprof profiles, e.g.
+// ProfileHeap, ProfileGoroutine, "mutex" or "block". The default is
+// ProfileCPU, ProfileHeap and ProfileGoroutine.
+func WithProfileTypes(types ...string) ProfilingOption {
+	return func(o *profilingOptions) {
+		o.types = types
+	}
+}
+
+// WithProfilingInterval sets the interval between two captures. The default
+// is DefaultProfilingInterval.
+func WithProfilingInterval(interval time.Duration) ProfilingOption {
+	return func(o *profilingOptions) {
+		o.interval = interval
+	}
+}
+
+// WithCPUProfileDuration sets the duration of CPU profiles. It must be
+// shorter than the profiling interval. The default is
+// DefaultCPUProfileDuration.
+func WithCPUProfileDuration(d time.Duration) ProfilingOption {
+	return func(o *profilingOptions) {
+		o.cpuDuration = d
+	}
+}
+
+// WithProfileLabels adds labels to the profiles, e.g. the environment or the
+// region.
+func WithProfileLabels(labels map[string]string) ProfilingOption {
+	return func(o *profilingOptions) {
+		for k, v := range labels {
+			o.labels[k] = v
+		}
+	}
+}
+
+// WithProfilingErrorHandler sets the handler of capture and upload errors.
+// The default is the OpenTelemetry global error handler, profilers created
+// via WithProfiling use the error handler of the configuration.
+func WithProfilingErrorHandler(h otel.ErrorHandler) ProfilingOption {
+	return func(o *profilingOptions) {
+		o.errorHandler = h
+	}
+}
+
+// NewProfiler returns a profiler that pushes the profiles of the service with
+// the given name and version to uploader once started. Use WithProfiling to
+// create a profiler managed by the clue configuration.
+func NewProfiler(svcName, svcVersion string, uploader ProfileUploader, opts ...ProfilingOption) *Profiler {
+	o := &profilingOptions{
+		types:       []string{ProfileCPU, ProfileHeap, ProfileGoroutine},
+		interval:    DefaultProfilingInterval,
+		cpuDuration: DefaultCPUProfileDuration,
+		labels:      make(map[string]string),
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	labels := map[string]string{"service": svcName, "version": svcVersion}
+	for k, v := range o.labels {
+		labels[k] = v
+	}
+	if o.cpuDuration > o.interval {
+		o.cpuDuration = o.interval
+	}
+	return &Profiler{
+		uploader:     uploader,
+		types:        o.types,
+		interval:     o.interval,
+		cpuDuration:  o.cpuDuration,
+		labels:       labels,
+		errorHandler: o.errorHandler,
+	}
+}
+
+// Start starts capturing and pushing profiles in the background until Stop is
+// called or ctx is done. The first profiles are captured right away. Start
+// does nothing if the profiler is already running.
+func (p *Profiler) Start(ctx context.Context) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.stop != nil {
+		return
+	}
+	p.stop = make(chan struct{})
+	p.done = make(chan struct{})
+	go p.run(ctx, p.stop, p.done)
+}
+
+// Stop stops the profiler and waits for the profiles being pushed to be
+// uploaded or for ctx to be done, whichever comes first. A CPU profile being
+// captured is cut short and pushed.
+func (p *Profiler) Stop(ctx context.Context) error {
+	p.lock.Lock()
+	stop, done := p.stop, p.done
+	p.stop, p.done = nil, nil
+	p.lock.Unlock()
+	if stop == nil {
+		return nil
+	}
+	close(stop)
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// run captures and pushes profiles until stop is closed or ctx is done.
+func (p *Profiler) run(ctx context.Context, stop, done chan struct{}) {
+	defer close(done)
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		for _, typ := range p.types {
+			p.push(ctx, typ, stop)
+		}
+		select {
+		case <-ticker.C:
+		case <-stop:
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// push captures a profile of the given type and uploads it.
+func (p *Profiler) push(ctx context.Context, typ string, stop chan struct{}) {
+	prof := &Profile{Type: typ, Start: time.Now(), Labels: p.labels}
+	var err error
+	if typ == ProfileCPU {
+		prof.Data, err = p.captureCPU(ctx, stop)
+	} else {
+		prof.Data, err = capture(typ)
+	}
+	prof.End = time.Now()
+	if err == nil {
+		err = p.uploader.Upload(ctx, prof)
+	}
+	if err != nil {
+		p.handle(fmt.Errorf("failed to push %s profile: %w", typ, err))
+	}
+}
+
+// captureCPU returns a CPU profile captured for the configured duration or
+// until stop is closed or ctx is done.
+func (p *Profiler) captureCPU(ctx context.Context, stop chan struct{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := rpprof.StartCPUProfile(&buf); err != nil {
+		return nil, err
+	}
+	timer := time.NewTimer(p.cpuDuration)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-stop:
+	case <-ctx.Done():
+	}
+	rpprof.StopCPUProfile()
+	return buf.Bytes(), nil
+}
+
+// capture returns the runtime/pprof profile with the given name.
+func capture(name string) ([]byte, error) {
+	prof := rpprof.Lookup(name)
+	if prof == nil {
+		return nil, fmt.Errorf("unknown profile %q", name)
+	}
+	var buf bytes.Buffer
+	if err := prof.WriteTo(&buf, 0); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// handle reports err to the configured error handler.
+func (p *Profiler) handle(err error) {
+	if p.errorHandler != nil {
+		p.errorHandler.Handle(err)
+		return
+	}
+	otel.Handle(err)
+}
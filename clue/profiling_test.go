@@ -0,0 +1,96 @@
+package clue
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProfiler(t *testing.T) {
+	uploader := &recordingUploader{}
+	p := NewProfiler("svc", "1.0", uploader,
+		WithProfileTypes(ProfileCPU, ProfileHeap, ProfileGoroutine),
+		WithProfilingInterval(time.Hour),
+		WithCPUProfileDuration(10*time.Millisecond),
+		WithProfileLabels(map[string]string{"env": "test"}))
+	p.Start(context.Background())
+	require.Eventually(t, func() bool { return len(uploader.Profiles()) == 3 }, time.Second, time.Millisecond)
+	require.NoError(t, p.Stop(context.Background()))
+
+	profiles := uploader.Profiles()
+	for i, typ := range []string{ProfileCPU, ProfileHeap, ProfileGoroutine} {
+		assert.Equal(t, typ, profiles[i].Type)
+		assert.NotEmpty(t, profiles[i].Data, typ)
+		assert.Equal(t, map[string]string{"service": "svc", "version": "1.0", "env": "test"}, profiles[i].Labels)
+	}
+	assert.GreaterOrEqual(t, profiles[0].End.Sub(profiles[0].Start), 10*time.Millisecond)
+	assert.NoError(t, p.Stop(context.Background()), "stopping twice")
+}
+
+func TestProfilerStop(t *testing.T) {
+	uploader := &recordingUploader{}
+	p := NewProfiler("svc", "1.0", uploader, WithProfileTypes(ProfileCPU))
+	p.Start(context.Background())
+	time.Sleep(10 * time.Millisecond)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, p.Stop(ctx))
+	require.Len(t, uploader.Profiles(), 1, "CPU profile cut short")
+}
+
+func TestProfilerErrors(t *testing.T) {
+	var errs []error
+	handler := errorHandlerFunc(func(err error) { errs = append(errs, err) })
+	uploader := &recordingUploader{err: errors.New("upload failed")}
+	p := NewProfiler("svc", "1.0", uploader,
+		WithProfileTypes("unknown", ProfileHeap),
+		WithProfilingErrorHandler(handler))
+	p.Start(context.Background())
+	require.NoError(t, p.Stop(context.Background()))
+
+	require.Len(t, errs, 2)
+	assert.EqualError(t, errs[0], `failed to push unknown profile: unknown profile "unknown"`)
+	assert.EqualError(t, errs[1], "failed to push heap profile: upload failed")
+}
+
+func TestWithProfiling(t *testing.T) {
+	uploader := &recordingUploader{}
+	cfg, err := NewConfig(context.Background(), "svc", "1.0", nil, nil,
+		WithProfiling(uploader, WithProfileTypes(ProfileGoroutine)))
+	require.NoError(t, err)
+	ConfigureOpenTelemetry(context.Background(), cfg)
+	t.Cleanup(func() { configured.Store(nil) })
+	require.NoError(t, Shutdown(context.Background()))
+
+	profiles := uploader.Profiles()
+	require.Len(t, profiles, 1)
+	assert.Equal(t, "svc", profiles[0].Labels["service"])
+	assert.Equal(t, "1.0", profiles[0].Labels["version"])
+}
+
+type recordingUploader struct {
+	lock     sync.Mutex
+	profiles []*Profile
+	err      error
+}
+
+func (u *recordingUploader) Upload(_ context.Context, p *Profile) error {
+	u.lock.Lock()
+	defer u.lock.Unlock()
+	if u.err != nil {
+		return u.err
+	}
+	u.profiles = append(u.profiles, p)
+	return nil
+}
+
+func (u *recordingUploader) Profiles() []*Profile {
+	u.lock.Lock()
+	defer u.lock.Unlock()
+	return append([]*Profile(nil), u.profiles...)
+}
@@ -0,0 +1,128 @@
+package clue
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type (
+	// PyroscopeOption is a function that configures the Pyroscope uploader.
+	PyroscopeOption func(*pyroscopeUploader)
+
+	// pyroscopeUploader pushes profiles to the Pyroscope ingestion API.
+	pyroscopeUploader struct {
+		url      string
+		username string
+		password string
+		headers  map[string]string
+		client   *http.Client
+	}
+)
+
+// PyroscopeUploader returns an uploader that pushes profiles to the Pyroscope
+// server at the given URL (e.g. "http://pyroscope:4040") using the ingestion
+// API. The application name is the service name followed by the profile type
+// (e.g. "svc.cpu") and the other profile labels are added as Pyroscope tags.
+func PyroscopeUploader(url string, opts ...PyroscopeOption) ProfileUploader {
+	u := &pyroscopeUploader{
+		url:    strings.TrimSuffix(url, "/") + "/ingest",
+		client: http.DefaultClient,
+	}
+	for _, opt := range opts {
+		opt(u)
+	}
+	return u
+}
+
+// WithPyroscopeBasicAuth sets the username and password used to authenticate
+// with the Pyroscope server.
+func WithPyroscopeBasicAuth(username, password string) PyroscopeOption {
+	return func(u *pyroscopeUploader) {
+		u.username = username
+		u.password = password
+	}
+}
+
+// WithPyroscopeHeaders adds headers to the upload requests, for example the
+// "X-Scope-OrgID" tenant header of multi-tenant deployments.
+func WithPyroscopeHeaders(headers map[string]string) PyroscopeOption {
+	return func(u *pyroscopeUploader) {
+		u.headers = headers
+	}
+}
+
+// WithPyroscopeHTTPClient sets the HTTP client used to push profiles.
+func WithPyroscopeHTTPClient(client *http.Client) PyroscopeOption {
+	return func(u *pyroscopeUploader) {
+		u.client = client
+	}
+}
+
+// Upload pushes p to Pyroscope.
+func (u *pyroscopeUploader) Upload(ctx context.Context, p *Profile) error {
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	fw, err := w.CreateFormFile("profile", "profile.pprof")
+	if err != nil {
+		return err
+	}
+	if _, err := fw.Write(p.Data); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	from, until := p.Start.Unix(), p.End.Unix()
+	if until <= from {
+		until = from + 1
+	}
+	q := url.Values{}
+	q.Set("name", pyroscopeName(p))
+	q.Set("from", strconv.FormatInt(from, 10))
+	q.Set("until", strconv.FormatInt(until, 10))
+	q.Set("spyName", "gospy")
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u.url+"?"+q.Encode(), &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	for k, v := range u.headers {
+		req.Header.Set(k, v)
+	}
+	if u.username != "" {
+		req.SetBasicAuth(u.username, u.password)
+	}
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("pyroscope: failed to upload profile: %s", resp.Status)
+	}
+	return nil
+}
+
+// pyroscopeName returns the Pyroscope application name of p including its
+// tags, e.g. "svc.cpu{version=1.0.0}".
+func pyroscopeName(p *Profile) string {
+	keys := make([]string, 0, len(p.Labels))
+	for k := range p.Labels {
+		if k != "service" {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	tags := make([]string, len(keys))
+	for i, k := range keys {
+		tags[i] = k + "=" + p.Labels[k]
+	}
+	return p.Labels["service"] + "." + p.Type + "{" + strings.Join(tags, ",") + "}"
+}
@@ -0,0 +1,62 @@
+package clue
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPyroscopeUploader(t *testing.T) {
+	var req *http.Request
+	var data []byte
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req = r
+		f, _, err := r.FormFile("profile")
+		require.NoError(t, err)
+		data, err = io.ReadAll(f)
+		require.NoError(t, err)
+	}))
+	defer svr.Close()
+
+	start := time.Unix(1000, 0)
+	u := PyroscopeUploader(svr.URL+"/",
+		WithPyroscopeBasicAuth("user", "password"),
+		WithPyroscopeHeaders(map[string]string{"X-Scope-OrgID": "tenant"}))
+	err := u.Upload(context.Background(), &Profile{
+		Type:   ProfileCPU,
+		Start:  start,
+		End:    start.Add(10 * time.Second),
+		Labels: map[string]string{"service": "svc", "version": "1.0", "env": "test"},
+		Data:   []byte("pprof"),
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "/ingest", req.URL.Path)
+	q := req.URL.Query()
+	assert.Equal(t, "svc.cpu{env=test,version=1.0}", q.Get("name"))
+	assert.Equal(t, "1000", q.Get("from"))
+	assert.Equal(t, "1010", q.Get("until"))
+	assert.Equal(t, "pprof", string(data))
+	username, password, ok := req.BasicAuth()
+	assert.True(t, ok)
+	assert.Equal(t, "user", username)
+	assert.Equal(t, "password", password)
+	assert.Equal(t, "tenant", req.Header.Get("X-Scope-OrgID"))
+}
+
+func TestPyroscopeUploaderError(t *testing.T) {
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer svr.Close()
+
+	now := time.Now()
+	err := PyroscopeUploader(svr.URL).Upload(context.Background(), &Profile{Type: ProfileHeap, Start: now, End: now})
+	assert.EqualError(t, err, "pyroscope: failed to upload profile: 401 Unauthorized")
+}
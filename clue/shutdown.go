@@ -0,0 +1,92 @@
+package clue
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	cluetrace "goa.design/clue/trace"
+)
+
+// Flusher is implemented by asynchronous telemetry writers that must be
+// flushed before the process exits, for example log.KafkaSink.
+type Flusher interface {
+	// Flush writes all pending entries.
+	Flush(ctx context.Context)
+}
+
+// DefaultShutdownTimeout is the maximum duration of Shutdown when the context
+// has no deadline.
+const DefaultShutdownTimeout = 5 * time.Second
+
+// configured is the configuration set by ConfigureOpenTelemetry.
+var configured atomic.Pointer[Config]
+
+// Shutdown flushes the telemetry recorded with the configuration set by
+// ConfigureOpenTelemetry. It stops the profiler configured via WithProfiling if
+// any, exports the pending span batches of the tracer providers created by
+// trace.Context (see trace.Shutdown) and of the configured tracer provider,
+// pushes the final metric values and flushes the flushers configured via
+// WithFlushers, in that order so that errors reported while exporting spans
+// and metrics are also flushed. The providers cannot be used once Shutdown
+// returns. Shutdown returns when all the
+// telemetry has been flushed or when ctx is done, whichever comes first.
+// DefaultShutdownTimeout is used if ctx has no deadline. Shutdown does nothing
+// if ConfigureOpenTelemetry has not been called.
+//
+// Shutdown is meant to be called by the graceful shutdown logic of the
+// service once the servers have stopped accepting requests so that telemetry
+// recorded by the last requests is not dropped. The servers should themselves
+// be stopped once load balancers have stopped sending requests, see
+// health.Drain:
+//
+//	<-ctx.Done()
+//	if err := health.Drain(ctx, chk, 10*time.Second); err != nil {
+//	        log.Error(ctx, err)
+//	}
+//	if err := httpsvr.Shutdown(ctx); err != nil {
+//	        log.Error(ctx, err)
+//	}
+//	if err := clue.Shutdown(ctx); err != nil {
+//	        log.Error(ctx, err)
+//	}
+func Shutdown(ctx context.Context) error {
+	cfg := configured.Load()
+	if cfg == nil {
+		return nil
+	}
+	return cfg.Shutdown(ctx)
+}
+
+// Shutdown flushes the telemetry recorded with cfg, see Shutdown. It returns
+// the first error encountered, the remaining telemetry is still flushed.
+func (cfg *Config) Shutdown(ctx context.Context) error {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, DefaultShutdownTimeout)
+		defer cancel()
+	}
+	var firstErr error
+	if cfg.profiler != nil {
+		firstErr = cfg.profiler.Stop(ctx)
+	}
+	if err := cluetrace.Shutdown(ctx); err != nil && firstErr == nil {
+		firstErr = err
+	}
+	for _, p := range []interface{}{cfg.TracerProvider, cfg.MeterProvider} {
+		s, ok := p.(interface{ Shutdown(context.Context) error })
+		if !ok {
+			continue
+		}
+		if err := s.Shutdown(ctx); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	for _, f := range cfg.flushers {
+		f.Flush(ctx)
+	}
+	if firstErr == nil {
+		firstErr = ctx.Err()
+	}
+	return firstErr
+}
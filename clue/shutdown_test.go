@@ -0,0 +1,110 @@
+package clue
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/aggregation"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	cluetrace "goa.design/clue/trace"
+)
+
+func TestShutdown(t *testing.T) {
+	spanExporter := &recordingSpanExporter{tracetest.NewInMemoryExporter()}
+	metricExporter := &recordingMetricExporter{}
+	flusher := &recordingFlusher{}
+	cfg, err := NewConfig(context.Background(), "svc", "1.0", metricExporter, spanExporter,
+		WithSampler(cluetrace.ParentRatio(1)),
+		WithFlushers(flusher))
+	require.NoError(t, err)
+	_, span := cfg.TracerProvider.Tracer("test").Start(context.Background(), "span")
+	span.End()
+	counter, err := cfg.MeterProvider.Meter("test").Int64Counter("counter")
+	require.NoError(t, err)
+	counter.Add(context.Background(), 1)
+
+	ConfigureOpenTelemetry(context.Background(), cfg)
+	t.Cleanup(func() { configured.Store(nil) })
+	require.NoError(t, Shutdown(context.Background()))
+
+	assert.Len(t, spanExporter.GetSpans(), 1)
+	assert.Equal(t, 1, metricExporter.exports)
+	assert.Equal(t, 1, flusher.flushes)
+	assert.True(t, flusher.hasDeadline, "default deadline")
+}
+
+func TestShutdownTraceContext(t *testing.T) {
+	spanExporter := &recordingSpanExporter{tracetest.NewInMemoryExporter()}
+	ctx, err := cluetrace.Context(context.Background(), "svc",
+		cluetrace.WithExporter(spanExporter),
+		cluetrace.WithSampler(cluetrace.ParentRatio(1)))
+	require.NoError(t, err)
+	_, span := cluetrace.TraceProvider(ctx).Tracer("test").Start(ctx, "span")
+	span.End()
+	cfg, err := NewConfig(context.Background(), "svc", "1.0", nil, nil)
+	require.NoError(t, err)
+
+	require.NoError(t, cfg.Shutdown(context.Background()))
+
+	assert.Len(t, spanExporter.GetSpans(), 1)
+}
+
+func TestShutdownNotConfigured(t *testing.T) {
+	configured.Store(nil)
+	assert.NoError(t, Shutdown(context.Background()))
+}
+
+func TestShutdownDeadline(t *testing.T) {
+	flusher := &recordingFlusher{}
+	cfg, err := NewConfig(context.Background(), "svc", "1.0", nil, nil, WithFlushers(flusher))
+	require.NoError(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Nanosecond)
+	defer cancel()
+	<-ctx.Done()
+
+	err = cfg.Shutdown(ctx)
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, 1, flusher.flushes, "flushers are called even if the deadline is exceeded")
+}
+
+type (
+	// recordingSpanExporter keeps the exported spans on shutdown.
+	recordingSpanExporter struct {
+		*tracetest.InMemoryExporter
+	}
+
+	recordingMetricExporter struct {
+		dummyMetricExporter
+		exports int
+	}
+
+	recordingFlusher struct {
+		flushes     int
+		hasDeadline bool
+	}
+)
+
+func (*recordingSpanExporter) Shutdown(context.Context) error { return nil }
+
+func (*recordingMetricExporter) Aggregation(kind sdkmetric.InstrumentKind) aggregation.Aggregation {
+	return sdkmetric.DefaultAggregationSelector(kind)
+}
+
+func (e *recordingMetricExporter) Export(_ context.Context, rm *metricdata.ResourceMetrics) error {
+	if len(rm.ScopeMetrics) > 0 {
+		e.exports++
+	}
+	return nil
+}
+
+func (f *recordingFlusher) Flush(ctx context.Context) {
+	f.flushes++
+	_, f.hasDeadline = ctx.Deadline()
+}
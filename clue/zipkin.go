@@ -0,0 +1,115 @@
+package clue
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"go.opentelemetry.io/otel/exporters/zipkin"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+type (
+	// ZipkinOption is a function that configures the Zipkin exporter.
+	ZipkinOption func(*zipkinOptions)
+
+	zipkinOptions struct {
+		headers      map[string]string
+		client       *http.Client
+		maxBatchSize int
+		batchTimeout time.Duration
+	}
+
+	// headerTransport adds headers to the requests made by the Zipkin
+	// exporter.
+	headerTransport struct {
+		headers map[string]string
+		next    http.RoundTripper
+	}
+)
+
+// WithZipkinExporter configures tracing to export spans to the Zipkin
+// collector at the given URL (e.g. "http://localhost:9411/api/v2/spans")
+// using the OpenTelemetry Zipkin exporter. The exporter is used in addition to
+// the span exporter given to NewConfig if any.
+func WithZipkinExporter(url string, opts ...ZipkinOption) Option {
+	var o zipkinOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	var batchOptions []sdktrace.BatchSpanProcessorOption
+	if o.maxBatchSize > 0 {
+		batchOptions = append(batchOptions, sdktrace.WithMaxExportBatchSize(o.maxBatchSize))
+	}
+	if o.batchTimeout > 0 {
+		batchOptions = append(batchOptions, sdktrace.WithBatchTimeout(o.batchTimeout))
+	}
+	return func(opts *options) {
+		opts.spanExporters = append(opts.spanExporters, spanExporter{
+			newExporter: func(context.Context) (sdktrace.SpanExporter, error) {
+				return zipkin.New(url, zipkin.WithClient(o.httpClient()))
+			},
+			batchOptions: batchOptions,
+		})
+	}
+}
+
+// WithZipkinHeaders sets HTTP headers added to the requests made to the Zipkin
+// collector, for example to set an authorization header.
+func WithZipkinHeaders(headers map[string]string) ZipkinOption {
+	return func(o *zipkinOptions) {
+		o.headers = headers
+	}
+}
+
+// WithZipkinHTTPClient sets the HTTP client used to send spans to the Zipkin
+// collector. Defaults to http.DefaultClient.
+func WithZipkinHTTPClient(client *http.Client) ZipkinOption {
+	return func(o *zipkinOptions) {
+		o.client = client
+	}
+}
+
+// WithZipkinMaxBatchSize sets the maximum number of spans sent in a single
+// request to the Zipkin collector.
+func WithZipkinMaxBatchSize(size int) ZipkinOption {
+	return func(o *zipkinOptions) {
+		o.maxBatchSize = size
+	}
+}
+
+// WithZipkinBatchTimeout sets the maximum delay before buffered spans are sent
+// to the Zipkin collector.
+func WithZipkinBatchTimeout(timeout time.Duration) ZipkinOption {
+	return func(o *zipkinOptions) {
+		o.batchTimeout = timeout
+	}
+}
+
+// httpClient returns the HTTP client used by the exporter, it adds the headers
+// configured via WithZipkinHeaders to the requests.
+func (o *zipkinOptions) httpClient() *http.Client {
+	client := o.client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	if len(o.headers) == 0 {
+		return client
+	}
+	next := client.Transport
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	c := *client
+	c.Transport = &headerTransport{headers: o.headers, next: next}
+	return &c
+}
+
+// RoundTrip adds the headers to a copy of req and sends it.
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	for k, v := range t.headers {
+		req.Header.Set(k, v)
+	}
+	return t.next.RoundTrip(req)
+}
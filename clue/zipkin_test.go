@@ -0,0 +1,62 @@
+package clue
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestZipkinExporter(t *testing.T) {
+	var spans []map[string]interface{}
+	var auth string
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&spans))
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer svr.Close()
+
+	cfg, err := NewConfig(context.Background(), "svc", "1.0", nil, nil,
+		WithZipkinExporter(svr.URL,
+			WithZipkinHeaders(map[string]string{"Authorization": "Bearer token"}),
+			WithZipkinHTTPClient(svr.Client()),
+			WithZipkinMaxBatchSize(10),
+			WithZipkinBatchTimeout(time.Second)))
+	require.NoError(t, err)
+	tracer := cfg.TracerProvider.Tracer("test")
+	ctx, parent := tracer.Start(context.Background(), "parent", trace.WithSpanKind(trace.SpanKindServer))
+	_, child := tracer.Start(ctx, "child", trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("key", "value")))
+	child.AddEvent("event")
+	child.SetStatus(codes.Error, "boom")
+	child.End()
+	parent.End()
+	require.NoError(t, cfg.TracerProvider.(*sdktrace.TracerProvider).Shutdown(context.Background()))
+
+	assert.Equal(t, "Bearer token", auth)
+	require.Len(t, spans, 2)
+	c, p := spans[0], spans[1]
+	assert.Equal(t, "child", c["name"])
+	assert.Equal(t, "CLIENT", c["kind"])
+	assert.Equal(t, p["id"], c["parentId"])
+	assert.Equal(t, p["traceId"], c["traceId"])
+	assert.Equal(t, map[string]interface{}{"serviceName": "svc"}, c["localEndpoint"])
+	assert.Equal(t, "event", c["annotations"].([]interface{})[0].(map[string]interface{})["value"])
+	tags := c["tags"].(map[string]interface{})
+	assert.Equal(t, "value", tags["key"])
+	assert.Equal(t, "test", tags["otel.library.name"])
+	assert.Equal(t, "ERROR", tags["otel.status_code"])
+	assert.Equal(t, "boom", tags["error"])
+	assert.Equal(t, "SERVER", p["kind"])
+	assert.NotContains(t, p, "parentId")
+}
@@ -0,0 +1,126 @@
+package debug
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+type (
+	// Authorizer returns a non-nil error if the request is not authorized.
+	Authorizer func(*http.Request) error
+
+	// guardedMux is a Muxer that requires authorization for the handlers
+	// mounted through it.
+	guardedMux struct {
+		Muxer
+		authorize func(http.Handler) http.Handler
+	}
+)
+
+// Guard returns a Muxer that mounts handlers on mux behind the authorization
+// middleware returned by Authorize. All the handlers mounted with the debug
+// package Mount functions via the returned muxer are protected, handlers
+// mounted directly on mux are not. The debug endpoints reveal the internals of
+// the service and many let callers slow it down or change its behavior, mount
+// them on a muxer protected with Guard and never expose them publicly:
+//
+//	guarded := debug.Guard(mux, debug.WithBearerTokens(os.Getenv("DEBUG_TOKEN")))
+//	debug.MountPprofHandlers(guarded)
+//	debug.MountDebugLogEnabler(guarded)
+func Guard(mux Muxer, opts ...AuthOption) Muxer {
+	return &guardedMux{Muxer: mux, authorize: Authorize(opts...)}
+}
+
+// Authorize returns a HTTP middleware that only lets authorized requests go
+// through and responds with 401 otherwise. A request is authorized if it
+// satisfies any of the methods configured with WithBearerTokens,
+// WithClientCertificates and WithAuthorizer. No request is authorized if no
+// method is configured: debug endpoints expose sensitive information and
+// controls and should not be left open even on internal networks.
+func Authorize(opts ...AuthOption) func(http.Handler) http.Handler {
+	o := &authOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !o.authorized(r) {
+				if len(o.tokens) > 0 {
+					w.Header().Set("WWW-Authenticate", `Bearer realm="debug"`)
+				}
+				http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Unwrap returns the guarded muxer.
+func (m *guardedMux) Unwrap() Muxer {
+	return m.Muxer
+}
+
+func (m *guardedMux) Handle(pattern string, handler http.Handler) {
+	m.Muxer.Handle(pattern, m.authorize(handler))
+}
+
+func (m *guardedMux) HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request)) {
+	m.Muxer.Handle(pattern, m.authorize(http.HandlerFunc(handler)))
+}
+
+// authorized returns true if r satisfies any of the configured methods.
+func (o *authOptions) authorized(r *http.Request) bool {
+	if len(o.tokens) > 0 {
+		if token, ok := bearerToken(r); ok {
+			for _, t := range o.tokens {
+				if subtle.ConstantTimeCompare([]byte(token), []byte(t)) == 1 {
+					return true
+				}
+			}
+		}
+	}
+	if o.certs && o.verifyClientCertificate(r) == nil {
+		return true
+	}
+	for _, fn := range o.authorizers {
+		if fn(r) == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// verifyClientCertificate returns an error if r was not made with a verified
+// client certificate matching the configured names.
+func (o *authOptions) verifyClientCertificate(r *http.Request) error {
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+		return errors.New("no verified client certificate")
+	}
+	if len(o.certNames) == 0 {
+		return nil
+	}
+	leaf := r.TLS.VerifiedChains[0][0]
+	for _, name := range o.certNames {
+		if leaf.Subject.CommonName == name {
+			return nil
+		}
+		for _, dns := range leaf.DNSNames {
+			if dns == name {
+				return nil
+			}
+		}
+	}
+	return errors.New("client certificate not allowed")
+}
+
+// bearerToken returns the bearer token of r if any.
+func bearerToken(r *http.Request) (string, bool) {
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "Bearer ") {
+		return "", false
+	}
+	return strings.TrimPrefix(auth, "Bearer "), true
+}
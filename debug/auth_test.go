@@ -0,0 +1,100 @@
+package debug
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAuthorize(t *testing.T) {
+	verified := func(cn string, dns ...string) *tls.ConnectionState {
+		cert := &x509.Certificate{Subject: pkix.Name{CommonName: cn}, DNSNames: dns}
+		return &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}
+	}
+	fromOps := WithAuthorizer(func(r *http.Request) error {
+		if r.Header.Get("X-Team") != "ops" {
+			return errors.New("not ops")
+		}
+		return nil
+	})
+	cases := []struct {
+		name           string
+		opts           []AuthOption
+		header         string
+		value          string
+		tls            *tls.ConnectionState
+		expectedStatus int
+	}{
+		{"no method", nil, "", "", nil, http.StatusUnauthorized},
+		{"empty token ignored", []AuthOption{WithBearerTokens("")}, "Authorization", "Bearer ", nil, http.StatusUnauthorized},
+		{"token", []AuthOption{WithBearerTokens("t1", "t2")}, "Authorization", "Bearer t2", nil, http.StatusOK},
+		{"invalid token", []AuthOption{WithBearerTokens("t1")}, "Authorization", "Bearer t2", nil, http.StatusUnauthorized},
+		{"missing token", []AuthOption{WithBearerTokens("t1")}, "", "", nil, http.StatusUnauthorized},
+		{"client certificate", []AuthOption{WithClientCertificates()}, "", "", verified("ops"), http.StatusOK},
+		{"no client certificate", []AuthOption{WithClientCertificates()}, "", "", &tls.ConnectionState{}, http.StatusUnauthorized},
+		{"client certificate name", []AuthOption{WithClientCertificates("ops")}, "", "", verified("ops"), http.StatusOK},
+		{"client certificate dns name", []AuthOption{WithClientCertificates("ops.internal")}, "", "", verified("x", "ops.internal"), http.StatusOK},
+		{"client certificate not allowed", []AuthOption{WithClientCertificates("ops")}, "", "", verified("dev"), http.StatusUnauthorized},
+		{"authorizer", []AuthOption{fromOps}, "X-Team", "ops", nil, http.StatusOK},
+		{"authorizer denied", []AuthOption{fromOps}, "X-Team", "dev", nil, http.StatusUnauthorized},
+		{"any method", []AuthOption{WithBearerTokens("t1"), fromOps}, "X-Team", "ops", nil, http.StatusOK},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			handler := Authorize(c.opts...)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte("OK"))
+			}))
+			req := httptest.NewRequest("GET", "/debug", nil)
+			if c.header != "" {
+				req.Header.Set(c.header, c.value)
+			}
+			req.TLS = c.tls
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+			if w.Code != c.expectedStatus {
+				t.Errorf("got status %d, expected %d", w.Code, c.expectedStatus)
+			}
+		})
+	}
+}
+
+func TestGuard(t *testing.T) {
+	mux := http.NewServeMux()
+	guarded := Guard(mux, WithBearerTokens("secret"))
+	MountDebugLogEnabler(guarded)
+	MountPprofHandlers(guarded)
+	mux.Handle("/public", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.Write([]byte("OK")) }))
+
+	cases := []struct {
+		name           string
+		path           string
+		token          string
+		expectedStatus int
+	}{
+		{"debug logs", "/debug", "", http.StatusUnauthorized},
+		{"debug logs authorized", "/debug", "secret", http.StatusOK},
+		{"pprof", "/debug/pprof/cmdline", "", http.StatusUnauthorized},
+		{"pprof authorized", "/debug/pprof/cmdline", "secret", http.StatusOK},
+		{"public", "/public", "", http.StatusOK},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", c.path, nil)
+			if c.token != "" {
+				req.Header.Set("Authorization", "Bearer "+c.token)
+			}
+			w := httptest.NewRecorder()
+			guarded.ServeHTTP(w, req)
+			if w.Code != c.expectedStatus {
+				t.Errorf("got status %d, expected %d", w.Code, c.expectedStatus)
+			}
+			if w.Code == http.StatusUnauthorized && w.Header().Get("WWW-Authenticate") == "" {
+				t.Errorf("expected WWW-Authenticate header")
+			}
+		})
+	}
+}
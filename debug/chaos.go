@@ -0,0 +1,615 @@
+package debug
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"goa.design/clue/clock"
+)
+
+type (
+	// ChaosConfig configures the faults injected by the chaos middlewares
+	// and interceptors, see ChaosHTTP. The zero value injects no fault.
+	// Rates are fractions of requests between 0 and 1.
+	ChaosConfig struct {
+		// Latency is the delay added to the affected requests.
+		Latency time.Duration
+		// LatencyRate is the fraction of requests delayed by Latency.
+		LatencyRate float64
+		// ErrorRate is the fraction of requests that fail with
+		// ErrorStatus or ErrorCode.
+		ErrorRate float64
+		// ErrorStatus is the HTTP status of the injected errors,
+		// http.StatusServiceUnavailable by default.
+		ErrorStatus int
+		// ErrorCode is the gRPC status code of the injected errors,
+		// codes.Unavailable by default.
+		ErrorCode codes.Code
+		// DropRate is the fraction of requests whose connection is
+		// dropped without response.
+		DropRate float64
+		// TruncateRate is the fraction of HTTP responses whose body is
+		// truncated and of gRPC streams closed after the first message.
+		TruncateRate float64
+		// Match restricts the faults to the requests whose path or gRPC
+		// full method starts with Match if not empty.
+		Match string
+	}
+
+	// chaosFaults lists the faults injected in a request.
+	chaosFaults struct {
+		latency  time.Duration
+		drop     bool
+		err      bool
+		truncate bool
+	}
+
+	// chaosClient is the round tripper returned by ChaosClient.
+	chaosClient struct {
+		http.RoundTripper
+	}
+
+	// truncateWriter buffers the response of a handler so that it can be
+	// truncated.
+	truncateWriter struct {
+		http.ResponseWriter
+		status int
+		body   bytes.Buffer
+	}
+
+	// truncateBody returns the first half of a response body followed by
+	// io.ErrUnexpectedEOF.
+	truncateBody struct {
+		io.Reader
+		closer io.Closer
+	}
+
+	// truncateServerStream closes a server stream after the first message.
+	truncateServerStream struct {
+		grpc.ServerStream
+		lock sync.Mutex
+		sent int
+	}
+
+	// truncateClientStream closes a client stream after the first message.
+	truncateClientStream struct {
+		grpc.ClientStream
+		lock sync.Mutex
+		recv int
+	}
+)
+
+var (
+	// chaosConfig is the current chaos configuration.
+	chaosConfig ChaosConfig
+	// chaosLock protects chaosConfig and chaosTimer.
+	chaosLock sync.Mutex
+	// chaosTimer reverts the chaos configuration changed with an
+	// expiration.
+	chaosTimer *time.Timer
+
+	// errChaosDropped is the error returned by ChaosClient when it drops a
+	// request.
+	errChaosDropped = errors.New("chaos: connection dropped")
+)
+
+// ChaosHTTP returns a HTTP middleware that injects faults in the requests it
+// handles for resilience testing. The faults are configured with SetChaos or
+// at runtime with the endpoint mounted by MountChaos, no fault is injected by
+// default. Affected requests are:
+//
+//   - delayed by the configured latency,
+//   - dropped: the connection is closed without response,
+//   - failed: the handler is not called and the response has the configured
+//     error status,
+//   - truncated: the connection is closed after half of the response body is
+//     written.
+//
+// The middleware should be added last so that the faults are observed by the
+// other middlewares:
+//
+//	handler = debug.ChaosHTTP()(handler)
+//	handler = log.HTTP(ctx)(handler)
+//	debug.MountChaos(mux)
+func ChaosHTTP() func(http.Handler) http.Handler {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			cfg, f := chaosFor(r.URL.Path)
+			if f.latency > 0 {
+				if err := chaosSleep(r.Context(), f.latency); err != nil {
+					return
+				}
+			}
+			switch {
+			case f.drop:
+				dropConn(w)
+			case f.err:
+				http.Error(w, "chaos: injected error", cfg.httpStatus())
+			case f.truncate:
+				tw := &truncateWriter{ResponseWriter: w, status: http.StatusOK}
+				h.ServeHTTP(tw, r)
+				body := tw.body.Bytes()
+				w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+				w.WriteHeader(tw.status)
+				w.Write(body[:len(body)/2])
+				if f, ok := w.(http.Flusher); ok {
+					f.Flush()
+				}
+				dropConn(w)
+			default:
+				h.ServeHTTP(w, r)
+			}
+		})
+	}
+}
+
+// ChaosClient returns a HTTP round tripper that injects faults in the requests
+// it sends, see ChaosHTTP. Dropped requests return an error, failed requests
+// return a response with the configured error status without being sent and
+// the body of truncated responses returns io.ErrUnexpectedEOF after half of its
+// content.
+//
+//	c := &http.Client{Transport: trace.Client(ctx, debug.ChaosClient(http.DefaultTransport))}
+func ChaosClient(rt http.RoundTripper) http.RoundTripper {
+	return &chaosClient{RoundTripper: rt}
+}
+
+// ChaosUnaryServerInterceptor returns a unary server interceptor that injects
+// faults in the requests it handles, see ChaosHTTP. Dropped requests fail with
+// codes.Unavailable and failed requests with the configured error code, the
+// handler is not called in both cases. Unary responses are never truncated.
+func ChaosUnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		cfg, f := chaosFor(info.FullMethod)
+		if err := chaosGRPC(ctx, cfg, f); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// ChaosStreamServerInterceptor returns a stream server interceptor that injects
+// faults in the streams it handles, see ChaosUnaryServerInterceptor. Truncated
+// streams fail with codes.Unavailable when the handler sends its second
+// message.
+func ChaosStreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(
+		srv interface{},
+		stream grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		cfg, f := chaosFor(info.FullMethod)
+		if err := chaosGRPC(stream.Context(), cfg, f); err != nil {
+			return err
+		}
+		if f.truncate {
+			stream = &truncateServerStream{ServerStream: stream}
+		}
+		return handler(srv, stream)
+	}
+}
+
+// ChaosUnaryClientInterceptor returns a unary client interceptor that injects
+// faults in the requests it sends, see ChaosUnaryServerInterceptor. Dropped
+// and failed requests are not sent.
+func ChaosUnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(
+		ctx context.Context,
+		method string,
+		req, reply interface{},
+		cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker,
+		opts ...grpc.CallOption,
+	) error {
+		cfg, f := chaosFor(method)
+		if err := chaosGRPC(ctx, cfg, f); err != nil {
+			return err
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// ChaosStreamClientInterceptor returns a stream client interceptor that
+// injects faults in the streams it creates, see ChaosUnaryClientInterceptor.
+// Truncated streams fail with codes.Unavailable when the client receives its
+// second message.
+func ChaosStreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(
+		ctx context.Context,
+		desc *grpc.StreamDesc,
+		cc *grpc.ClientConn,
+		method string,
+		streamer grpc.Streamer,
+		opts ...grpc.CallOption,
+	) (grpc.ClientStream, error) {
+		cfg, f := chaosFor(method)
+		if err := chaosGRPC(ctx, cfg, f); err != nil {
+			return nil, err
+		}
+		stream, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil || !f.truncate {
+			return stream, err
+		}
+		return &truncateClientStream{ClientStream: stream}, nil
+	}
+}
+
+// MountChaos mounts an endpoint under "/debug/chaos" that controls the faults
+// injected by the chaos middlewares and interceptors, see ChaosHTTP. GET
+// requests return the current configuration. POST requests change it using
+// the following query parameters, only the given settings are changed:
+//
+//   - "latency": the delay added to requests (e.g. "200ms").
+//   - "latency-rate", "error-rate", "drop-rate", "truncate-rate": the fraction
+//     of requests affected by each fault, between 0 and 1.
+//   - "error-status": the HTTP status of injected errors.
+//   - "error-code": the gRPC code of injected errors (e.g. "unavailable" or
+//     "14").
+//   - "match": the prefix of the paths and gRPC methods affected.
+//   - "chaos": "off" resets all the settings.
+//   - "revert": a duration (e.g. "10m") after which the changes made by the
+//     request are reverted.
+//
+// The endpoint returns the current configuration, for example:
+//
+//	{"chaos":"on","drop-rate":0,"error-code":"Unavailable","error-rate":0.1,"error-status":503,"latency":"200ms","latency-rate":0.5,"match":"","revert-in":"10m0s","truncate-rate":0}
+//
+// The endpoint lets anyone who can reach it degrade the service, see Guard. The
+// path can be changed using WithChaosPath.
+func MountChaos(mux Muxer, opts ...ChaosOption) {
+	o := defaultChaosOptions()
+	for _, opt := range opts {
+		opt(o)
+	}
+	if !strings.HasPrefix(o.path, "/") {
+		o.path = "/" + o.path
+	}
+	register(mux, "chaos", o.path, "Fault injection for resilience testing", func() interface{} {
+		return onOff(Chaos().enabled())
+	})
+	mux.Handle(o.path, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		var revert time.Duration
+		if v := q.Get("revert"); v != "" {
+			d, err := time.ParseDuration(v)
+			if err != nil || d <= 0 {
+				http.Error(w, fmt.Sprintf("invalid revert value %q, must be a positive duration", v), http.StatusBadRequest)
+				return
+			}
+			revert = d
+		}
+		cfg := Chaos()
+		changed := false
+		switch v := q.Get("chaos"); v {
+		case "":
+		case "off":
+			cfg = ChaosConfig{}
+			changed = true
+		default:
+			http.Error(w, fmt.Sprintf("invalid chaos value %q, must be off", v), http.StatusBadRequest)
+			return
+		}
+		if v := q.Get("latency"); v != "" {
+			d, err := time.ParseDuration(v)
+			if err != nil || d < 0 {
+				http.Error(w, fmt.Sprintf("invalid latency value %q, must be a duration", v), http.StatusBadRequest)
+				return
+			}
+			cfg.Latency = d
+			changed = true
+		}
+		for _, p := range []struct {
+			name string
+			rate *float64
+		}{
+			{"latency-rate", &cfg.LatencyRate},
+			{"error-rate", &cfg.ErrorRate},
+			{"drop-rate", &cfg.DropRate},
+			{"truncate-rate", &cfg.TruncateRate},
+		} {
+			v := q.Get(p.name)
+			if v == "" {
+				continue
+			}
+			rate, err := strconv.ParseFloat(v, 64)
+			if err != nil || rate < 0 || rate > 1 {
+				http.Error(w, fmt.Sprintf("invalid %s value %q, must be a number between 0 and 1", p.name, v), http.StatusBadRequest)
+				return
+			}
+			*p.rate = rate
+			changed = true
+		}
+		if v := q.Get("error-status"); v != "" {
+			s, err := strconv.Atoi(v)
+			if err != nil || s < 100 || s > 599 {
+				http.Error(w, fmt.Sprintf("invalid error-status value %q, must be a HTTP status code", v), http.StatusBadRequest)
+				return
+			}
+			cfg.ErrorStatus = s
+			changed = true
+		}
+		if v := q.Get("error-code"); v != "" {
+			c, ok := parseCode(v)
+			if !ok {
+				http.Error(w, fmt.Sprintf("invalid error-code value %q, must be a gRPC status code", v), http.StatusBadRequest)
+				return
+			}
+			cfg.ErrorCode = c
+			changed = true
+		}
+		if q.Has("match") {
+			cfg.Match = q.Get("match")
+			changed = true
+		}
+		if changed {
+			if r.Method != http.MethodPost {
+				w.Header().Set("Allow", http.MethodPost)
+				http.Error(w, "changing the chaos configuration requires a POST request", http.StatusMethodNotAllowed)
+				return
+			}
+			setChaos(cfg, revert)
+		}
+		state := chaosState(Chaos())
+		if changed && revert > 0 {
+			state["revert-in"] = revert.String()
+		}
+		js, _ := json.Marshal(state)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(js)
+	}))
+}
+
+// SetChaos sets the faults injected by the chaos middlewares and interceptors,
+// see ChaosHTTP. It cancels any pending revert made by the endpoint mounted by
+// MountChaos. Use SetChaos(ChaosConfig{}) to stop injecting faults.
+func SetChaos(cfg ChaosConfig) {
+	setChaos(cfg, 0)
+}
+
+// Chaos returns the current chaos configuration.
+func Chaos() ChaosConfig {
+	chaosLock.Lock()
+	defer chaosLock.Unlock()
+	return chaosConfig
+}
+
+// setChaos sets the chaos configuration and reverts it after revert if revert
+// is greater than 0.
+func setChaos(cfg ChaosConfig, revert time.Duration) {
+	chaosLock.Lock()
+	defer chaosLock.Unlock()
+	if chaosTimer != nil {
+		chaosTimer.Stop()
+		chaosTimer = nil
+	}
+	old := chaosConfig
+	chaosConfig = cfg
+	if revert > 0 {
+		var timer *time.Timer
+		timer = time.AfterFunc(revert, func() {
+			chaosLock.Lock()
+			defer chaosLock.Unlock()
+			// Note: a timer stopped while its callback waits for the
+			// lock still runs it, only revert if no other change
+			// happened since.
+			if chaosTimer != timer {
+				return
+			}
+			chaosConfig = old
+			chaosTimer = nil
+		})
+		chaosTimer = timer
+	}
+}
+
+// chaosFor returns the current configuration and the faults to inject in the
+// request with the given path or gRPC full method.
+func chaosFor(path string) (ChaosConfig, chaosFaults) {
+	cfg := Chaos()
+	var f chaosFaults
+	if !cfg.enabled() || !strings.HasPrefix(path, cfg.Match) {
+		return cfg, f
+	}
+	if cfg.Latency > 0 && sample(cfg.LatencyRate) {
+		f.latency = cfg.Latency
+	}
+	f.drop = sample(cfg.DropRate)
+	f.err = !f.drop && sample(cfg.ErrorRate)
+	f.truncate = !f.drop && !f.err && sample(cfg.TruncateRate)
+	return cfg, f
+}
+
+// chaosGRPC waits for the injected latency and returns the error of the
+// dropped or failed gRPC requests.
+func chaosGRPC(ctx context.Context, cfg ChaosConfig, f chaosFaults) error {
+	if f.latency > 0 {
+		if err := chaosSleep(ctx, f.latency); err != nil {
+			return status.FromContextError(err).Err()
+		}
+	}
+	switch {
+	case f.drop:
+		return status.Error(codes.Unavailable, errChaosDropped.Error())
+	case f.err:
+		return status.Error(cfg.grpcCode(), "chaos: injected error")
+	}
+	return nil
+}
+
+// chaosSleep waits for d or until ctx is done, it uses the clock of ctx.
+func chaosSleep(ctx context.Context, d time.Duration) error {
+	timer := clock.FromContext(ctx).NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C():
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// chaosState returns the JSON representation of cfg.
+func chaosState(cfg ChaosConfig) map[string]interface{} {
+	return map[string]interface{}{
+		"chaos":         onOff(cfg.enabled()),
+		"latency":       cfg.Latency.String(),
+		"latency-rate":  cfg.LatencyRate,
+		"error-rate":    cfg.ErrorRate,
+		"error-status":  cfg.httpStatus(),
+		"error-code":    cfg.grpcCode().String(),
+		"drop-rate":     cfg.DropRate,
+		"truncate-rate": cfg.TruncateRate,
+		"match":         cfg.Match,
+	}
+}
+
+// dropConn closes the connection of the request being served by w without
+// completing the response.
+func dropConn(w http.ResponseWriter) {
+	if hj, ok := w.(http.Hijacker); ok {
+		if conn, _, err := hj.Hijack(); err == nil {
+			conn.Close()
+			return
+		}
+	}
+	panic(http.ErrAbortHandler)
+}
+
+// parseCode parses a gRPC status code given by name (case insensitive) or
+// number.
+func parseCode(v string) (codes.Code, bool) {
+	if n, err := strconv.ParseUint(v, 10, 32); err == nil {
+		return codes.Code(n), n <= uint64(codes.Unauthenticated)
+	}
+	var c codes.Code
+	if err := c.UnmarshalJSON([]byte(strconv.Quote(strings.ToUpper(v)))); err != nil {
+		return 0, false
+	}
+	return c, true
+}
+
+// sample returns true with the probability rate.
+func sample(rate float64) bool {
+	return rate >= 1 || rate > 0 && rand.Float64() < rate
+}
+
+// enabled returns true if cfg injects faults.
+func (cfg ChaosConfig) enabled() bool {
+	return cfg.Latency > 0 && cfg.LatencyRate > 0 || cfg.ErrorRate > 0 || cfg.DropRate > 0 || cfg.TruncateRate > 0
+}
+
+// httpStatus returns the HTTP status of injected errors.
+func (cfg ChaosConfig) httpStatus() int {
+	if cfg.ErrorStatus == 0 {
+		return http.StatusServiceUnavailable
+	}
+	return cfg.ErrorStatus
+}
+
+// grpcCode returns the gRPC code of injected errors.
+func (cfg ChaosConfig) grpcCode() codes.Code {
+	if cfg.ErrorCode == codes.OK {
+		return codes.Unavailable
+	}
+	return cfg.ErrorCode
+}
+
+// RoundTrip injects the configured faults in req.
+func (c *chaosClient) RoundTrip(req *http.Request) (*http.Response, error) {
+	cfg, f := chaosFor(req.URL.Path)
+	if f.latency > 0 {
+		if err := chaosSleep(req.Context(), f.latency); err != nil {
+			return nil, err
+		}
+	}
+	switch {
+	case f.drop:
+		return nil, errChaosDropped
+	case f.err:
+		body := "chaos: injected error"
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", cfg.httpStatus(), http.StatusText(cfg.httpStatus())),
+			StatusCode:    cfg.httpStatus(),
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        http.Header{"Content-Type": {"text/plain; charset=utf-8"}},
+			Body:          io.NopCloser(strings.NewReader(body)),
+			ContentLength: int64(len(body)),
+			Request:       req,
+		}, nil
+	}
+	resp, err := c.RoundTripper.RoundTrip(req)
+	if err != nil || !f.truncate {
+		return resp, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	resp.Body = &truncateBody{Reader: bytes.NewReader(body[:len(body)/2]), closer: resp.Body}
+	return resp, nil
+}
+
+func (w *truncateWriter) WriteHeader(status int) {
+	w.status = status
+}
+
+func (w *truncateWriter) Write(b []byte) (int, error) {
+	return w.body.Write(b)
+}
+
+func (b *truncateBody) Read(p []byte) (int, error) {
+	n, err := b.Reader.Read(p)
+	if errors.Is(err, io.EOF) {
+		err = io.ErrUnexpectedEOF
+	}
+	return n, err
+}
+
+func (b *truncateBody) Close() error {
+	return b.closer.Close()
+}
+
+func (s *truncateServerStream) SendMsg(m interface{}) error {
+	s.lock.Lock()
+	s.sent++
+	sent := s.sent
+	s.lock.Unlock()
+	if sent > 1 {
+		return status.Error(codes.Unavailable, "chaos: stream truncated")
+	}
+	return s.ServerStream.SendMsg(m)
+}
+
+func (s *truncateClientStream) RecvMsg(m interface{}) error {
+	s.lock.Lock()
+	s.recv++
+	recv := s.recv
+	s.lock.Unlock()
+	if recv > 1 {
+		return status.Error(codes.Unavailable, "chaos: stream truncated")
+	}
+	return s.ClientStream.RecvMsg(m)
+}
@@ -0,0 +1,291 @@
+package debug
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"goa.design/clue/clock"
+	"goa.design/clue/testing/testsvc"
+)
+
+func TestChaosHTTP(t *testing.T) {
+	defer SetChaos(ChaosConfig{})
+	ts := httptest.NewServer(ChaosHTTP()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("hello world!"))
+	})))
+	defer ts.Close()
+	cases := []struct {
+		name           string
+		cfg            ChaosConfig
+		expectedStatus int
+		expectedBody   string
+		expectedErr    bool
+	}{
+		{"none", ChaosConfig{}, http.StatusOK, "hello world!", false},
+		{"error", ChaosConfig{ErrorRate: 1}, http.StatusServiceUnavailable, "chaos: injected error\n", false},
+		{"error status", ChaosConfig{ErrorRate: 1, ErrorStatus: http.StatusInternalServerError}, http.StatusInternalServerError, "chaos: injected error\n", false},
+		{"drop", ChaosConfig{DropRate: 1}, 0, "", true},
+		{"truncate", ChaosConfig{TruncateRate: 1}, http.StatusOK, "hello ", true},
+		{"no match", ChaosConfig{ErrorRate: 1, Match: "/other"}, http.StatusOK, "hello world!", false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			SetChaos(c.cfg)
+			status, body, err := get(ts.URL + "/path")
+			if c.expectedErr != (err != nil) {
+				t.Errorf("got error %v, expected error: %v", err, c.expectedErr)
+			}
+			if status != c.expectedStatus {
+				t.Errorf("got status %d, expected %d", status, c.expectedStatus)
+			}
+			if body != c.expectedBody {
+				t.Errorf("got body %q, expected %q", body, c.expectedBody)
+			}
+		})
+	}
+}
+
+func TestChaosClient(t *testing.T) {
+	defer SetChaos(ChaosConfig{})
+	var calls int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Write([]byte("hello world!"))
+	}))
+	defer ts.Close()
+	c := &http.Client{Transport: ChaosClient(http.DefaultTransport)}
+	cases := []struct {
+		name           string
+		cfg            ChaosConfig
+		expectedStatus int
+		expectedBody   string
+		expectedErr    error
+		expectedCalls  int
+	}{
+		{"none", ChaosConfig{}, http.StatusOK, "hello world!", nil, 1},
+		{"error", ChaosConfig{ErrorRate: 1, ErrorStatus: http.StatusBadGateway}, http.StatusBadGateway, "chaos: injected error", nil, 0},
+		{"drop", ChaosConfig{DropRate: 1}, 0, "", errChaosDropped, 0},
+		{"truncate", ChaosConfig{TruncateRate: 1}, http.StatusOK, "hello ", io.ErrUnexpectedEOF, 1},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			calls = 0
+			SetChaos(tc.cfg)
+			var (
+				status int
+				body   []byte
+			)
+			resp, err := c.Get(ts.URL)
+			if err == nil {
+				status = resp.StatusCode
+				body, err = io.ReadAll(resp.Body)
+				resp.Body.Close()
+			}
+			if !errors.Is(err, tc.expectedErr) {
+				t.Errorf("got error %v, expected %v", err, tc.expectedErr)
+			}
+			if status != tc.expectedStatus {
+				t.Errorf("got status %d, expected %d", status, tc.expectedStatus)
+			}
+			if string(body) != tc.expectedBody {
+				t.Errorf("got body %q, expected %q", string(body), tc.expectedBody)
+			}
+			if calls != tc.expectedCalls {
+				t.Errorf("got %d calls, expected %d", calls, tc.expectedCalls)
+			}
+		})
+	}
+}
+
+func TestChaosGRPC(t *testing.T) {
+	defer SetChaos(ChaosConfig{})
+	s1, s2 := "a", "b"
+	cases := []struct {
+		name    string
+		options []testsvc.GRPCOption
+	}{
+		{"server", []testsvc.GRPCOption{testsvc.WithServerOptions(
+			grpc.ChainUnaryInterceptor(ChaosUnaryServerInterceptor()),
+			grpc.ChainStreamInterceptor(ChaosStreamServerInterceptor()))}},
+		{"client", []testsvc.GRPCOption{testsvc.WithDialOptions(
+			grpc.WithChainUnaryInterceptor(ChaosUnaryClientInterceptor()),
+			grpc.WithChainStreamInterceptor(ChaosStreamClientInterceptor()))}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var calls int
+			opts := append(c.options,
+				testsvc.WithUnaryFunc(func(context.Context, *testsvc.Fields) (*testsvc.Fields, error) {
+					calls++
+					return &testsvc.Fields{}, nil
+				}),
+				testsvc.WithStreamFunc(testsvc.Script(
+					testsvc.ScriptSend(&testsvc.Fields{S: &s1}),
+					testsvc.ScriptSend(&testsvc.Fields{S: &s2}))))
+			cli, stop := testsvc.SetupGRPC(t, opts...)
+			defer stop()
+
+			SetChaos(ChaosConfig{})
+			if _, err := cli.GRPCMethod(context.Background(), &testsvc.Fields{}); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			SetChaos(ChaosConfig{DropRate: 1})
+			// The generated client does not preserve the status of
+			// unary errors.
+			if _, err := cli.GRPCMethod(context.Background(), &testsvc.Fields{}); err == nil || !strings.Contains(err.Error(), "code = Unavailable") {
+				t.Errorf("got error %v, expected unavailable", err)
+			}
+			SetChaos(ChaosConfig{ErrorRate: 1, ErrorCode: codes.ResourceExhausted})
+			if _, err := cli.GRPCMethod(context.Background(), &testsvc.Fields{}); err == nil || !strings.Contains(err.Error(), "code = ResourceExhausted") {
+				t.Errorf("got error %v, expected resource exhausted", err)
+			}
+			if calls != 1 {
+				t.Errorf("got %d calls, expected 1", calls)
+			}
+
+			SetChaos(ChaosConfig{TruncateRate: 1})
+			stream, err := cli.GRPCStream(context.Background())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if f, err := stream.Recv(); err != nil || *f.S != s1 {
+				t.Errorf("got message %v and error %v, expected %q", f, err, s1)
+			}
+			if _, err := stream.Recv(); status.Code(err) != codes.Unavailable {
+				t.Errorf("got error %v, expected unavailable", err)
+			}
+		})
+	}
+}
+
+func TestChaosLatency(t *testing.T) {
+	defer SetChaos(ChaosConfig{})
+	SetChaos(ChaosConfig{Latency: time.Second, LatencyRate: 1})
+	clk := clock.NewFake(time.Now())
+	ctx := clock.Context(context.Background(), clk)
+	invoker := func(context.Context, string, interface{}, interface{}, *grpc.ClientConn, ...grpc.CallOption) error {
+		return nil
+	}
+	done := make(chan error)
+	go func() {
+		done <- ChaosUnaryClientInterceptor()(ctx, "/test.Test/GrpcMethod", nil, nil, nil, invoker)
+	}()
+	clk.BlockUntil(1)
+	select {
+	case <-done:
+		t.Fatal("expected request to be delayed")
+	default:
+	}
+	clk.Advance(time.Second)
+	if err := <-done; err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestMountChaos(t *testing.T) {
+	defer SetChaos(ChaosConfig{})
+	mux := http.NewServeMux()
+	MountChaos(mux)
+	cases := []struct {
+		name           string
+		method         string
+		url            string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{"get", "GET", "/debug/chaos", http.StatusOK, `"chaos":"off"`},
+		{"get change", "GET", "/debug/chaos?drop-rate=1", http.StatusMethodNotAllowed, "requires a POST request"},
+		{"latency", "POST", "/debug/chaos?latency=200ms&latency-rate=0.5", http.StatusOK, `"latency":"200ms","latency-rate":0.5`},
+		{"error", "POST", "/debug/chaos?error-rate=0.1&error-status=500&error-code=not_found", http.StatusOK, `"error-code":"NotFound","error-rate":0.1,"error-status":500`},
+		{"keep", "POST", "/debug/chaos?drop-rate=1", http.StatusOK, `"chaos":"on","drop-rate":1,"error-code":"NotFound"`},
+		{"match", "POST", "/debug/chaos?match=/orders", http.StatusOK, `"match":"/orders"`},
+		{"code number", "POST", "/debug/chaos?error-code=8", http.StatusOK, `"error-code":"ResourceExhausted"`},
+		{"revert", "POST", "/debug/chaos?truncate-rate=0.2&revert=1h", http.StatusOK, `"revert-in":"1h0m0s","truncate-rate":0.2`},
+		{"off", "POST", "/debug/chaos?chaos=off", http.StatusOK, `"chaos":"off","drop-rate":0`},
+		{"invalid rate", "POST", "/debug/chaos?error-rate=2", http.StatusBadRequest, `invalid error-rate value "2"`},
+		{"invalid latency", "POST", "/debug/chaos?latency=slow", http.StatusBadRequest, `invalid latency value "slow"`},
+		{"invalid status", "POST", "/debug/chaos?error-status=42", http.StatusBadRequest, `invalid error-status value "42"`},
+		{"invalid code", "POST", "/debug/chaos?error-code=oops", http.StatusBadRequest, `invalid error-code value "oops"`},
+		{"invalid chaos", "POST", "/debug/chaos?chaos=on", http.StatusBadRequest, `invalid chaos value "on"`},
+		{"invalid revert", "POST", "/debug/chaos?drop-rate=1&revert=soon", http.StatusBadRequest, `invalid revert value "soon"`},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, httptest.NewRequest(c.method, c.url, nil))
+			if w.Code != c.expectedStatus {
+				t.Errorf("got status %d, expected %d", w.Code, c.expectedStatus)
+			}
+			if !strings.Contains(w.Body.String(), c.expectedBody) {
+				t.Errorf("got body %q, expected it to contain %q", w.Body.String(), c.expectedBody)
+			}
+		})
+	}
+}
+
+func TestSetChaosRevertOverridden(t *testing.T) {
+	defer SetChaos(ChaosConfig{})
+	setChaos(ChaosConfig{ErrorRate: 1}, 20*time.Millisecond)
+	// Hold the lock until the revert callback fired and change the
+	// configuration like SetChaos does so that the callback runs after the
+	// change.
+	chaosLock.Lock()
+	time.Sleep(50 * time.Millisecond)
+	chaosTimer.Stop()
+	chaosTimer = nil
+	chaosConfig = ChaosConfig{DropRate: 1}
+	chaosLock.Unlock()
+	time.Sleep(50 * time.Millisecond)
+	if Chaos().DropRate != 1 {
+		t.Errorf("expected stale revert to be ignored, got %+v", Chaos())
+	}
+}
+
+func TestChaosHTTPCanceled(t *testing.T) {
+	defer SetChaos(ChaosConfig{})
+	SetChaos(ChaosConfig{Latency: time.Hour, LatencyRate: 1})
+	var called bool
+	handler := ChaosHTTP()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { called = true }))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil).WithContext(ctx))
+	if called {
+		t.Error("expected handler not to be called once the request is canceled")
+	}
+}
+
+func TestMountChaosRevert(t *testing.T) {
+	defer SetChaos(ChaosConfig{})
+	mux := http.NewServeMux()
+	MountChaos(mux)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("POST", "/debug/chaos?error-rate=1&revert=50ms", nil))
+	if Chaos().ErrorRate != 1 {
+		t.Fatal("expected chaos to be enabled")
+	}
+	time.Sleep(200 * time.Millisecond)
+	if Chaos().ErrorRate != 0 {
+		t.Error("expected chaos to be reverted")
+	}
+}
+
+// get sends a GET request to url and returns the response status and body.
+func get(url string) (int, string, error) {
+	resp, err := http.Get(url)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(body), err
+}
@@ -0,0 +1,163 @@
+package debug
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Redacted is the value that replaces secrets in the configuration returned by
+// the endpoint mounted by MountConfig.
+const Redacted = "[REDACTED]"
+
+var (
+	// DefaultRedactedKeys is the default list of key patterns used by
+	// MountConfig to redact secrets, see WithRedactedKeys.
+	DefaultRedactedKeys = []string{"password", "passwd", "secret", "token", "apikey", "api_key", "private", "credential", "auth"}
+
+	// keyNormalizer removes the separators ignored when matching keys.
+	keyNormalizer = strings.NewReplacer("-", "", "_", "")
+
+	// configsLock protects configs.
+	configsLock sync.Mutex
+	// configs contains the configurations registered with RegisterConfig
+	// indexed by name.
+	configs = make(map[string]interface{})
+)
+
+// RegisterConfig registers a configuration rendered by the endpoint mounted by
+// MountConfig under the given name. cfg must be JSON serializable, it is
+// serialized on each request so that a pointer reflects the current values.
+// Registering a configuration with the same name replaces the previous one.
+//
+// Example:
+//
+//	debug.RegisterConfig("service", &cfg)
+func RegisterConfig(name string, cfg interface{}) {
+	configsLock.Lock()
+	defer configsLock.Unlock()
+	configs[name] = cfg
+}
+
+// MountConfig mounts an endpoint under "/debug/config" that returns the
+// configurations registered with RegisterConfig indexed by name as JSON, so
+// that the configuration a service is actually running with can be inspected.
+// Secrets are redacted: values whose key matches one of the patterns in
+// DefaultRedactedKeys or given with WithRedactedKeys are replaced with
+// Redacted, so are the passwords of URLs. The path can be changed using
+// WithConfigPath.
+func MountConfig(mux Muxer, opts ...ConfigOption) {
+	o := defaultConfigOptions()
+	for _, opt := range opts {
+		opt(o)
+	}
+	if !strings.HasPrefix(o.path, "/") {
+		o.path = "/" + o.path
+	}
+	patterns := make([]string, len(o.patterns))
+	for i, p := range o.patterns {
+		patterns[i] = normalizeKey(p)
+	}
+	register(mux, "config", o.path, "Configuration dump", func() interface{} {
+		configsLock.Lock()
+		defer configsLock.Unlock()
+		names := make([]string, 0, len(configs))
+		for name := range configs {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return names
+	})
+	mux.Handle(o.path, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		configsLock.Lock()
+		snapshot := make(map[string]interface{}, len(configs))
+		for name, cfg := range configs {
+			snapshot[name] = cfg
+		}
+		configsLock.Unlock()
+		res := make(map[string]interface{}, len(snapshot))
+		for name, cfg := range snapshot {
+			v, err := redactConfig(cfg, patterns)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("failed to render configuration %q: %s", name, err), http.StatusInternalServerError)
+				return
+			}
+			res[name] = v
+		}
+		js, _ := json.Marshal(res)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(js)
+	}))
+}
+
+// redactConfig returns the generic JSON representation of cfg with secrets
+// redacted.
+func redactConfig(cfg interface{}, patterns []string) (interface{}, error) {
+	js, err := json.Marshal(cfg)
+	if err != nil {
+		return nil, err
+	}
+	var v interface{}
+	if err := json.Unmarshal(js, &v); err != nil {
+		return nil, err
+	}
+	return redact(v, patterns), nil
+}
+
+// redact redacts the secrets contained in v recursively.
+func redact(v interface{}, patterns []string) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for k, e := range val {
+			if isSecret(k, patterns) && e != nil {
+				val[k] = Redacted
+				continue
+			}
+			val[k] = redact(e, patterns)
+		}
+	case []interface{}:
+		for i, e := range val {
+			val[i] = redact(e, patterns)
+		}
+	case string:
+		return redactURL(val)
+	}
+	return v
+}
+
+// isSecret returns true if key matches one of the normalized patterns.
+func isSecret(key string, patterns []string) bool {
+	key = normalizeKey(key)
+	for _, p := range patterns {
+		if strings.Contains(key, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeKey returns key lowercased and without dashes and underscores so
+// that e.g. "X-Api-Key" matches the "apikey" pattern.
+func normalizeKey(key string) string {
+	return keyNormalizer.Replace(strings.ToLower(key))
+}
+
+// redactURL redacts the password of s if s is a URL with a password.
+func redactURL(s string) string {
+	if !strings.Contains(s, "://") {
+		return s
+	}
+	u, err := url.Parse(s)
+	if err != nil || u.User == nil {
+		return s
+	}
+	if _, ok := u.User.Password(); !ok {
+		return s
+	}
+	u.User = url.UserPassword(u.User.Username(), "xxxxx")
+	return u.String()
+}
@@ -0,0 +1,77 @@
+package debug
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMountConfig(t *testing.T) {
+	type dbConfig struct {
+		URL      string `json:"url"`
+		Password string `json:"password"`
+		PoolSize int    `json:"pool_size"`
+	}
+	cfg := struct {
+		Name    string            `json:"name"`
+		APIKey  string            `json:"apiKey"`
+		DB      dbConfig          `json:"db"`
+		Labels  map[string]string `json:"labels"`
+		Tenants []dbConfig        `json:"tenants"`
+	}{
+		Name:    "svc",
+		APIKey:  "abc",
+		DB:      dbConfig{URL: "postgres://user:pass@db:5432/svc", Password: "secret", PoolSize: 10},
+		Labels:  map[string]string{"team": "core", "SigningSecretName": "s", "x-api-key": "k"},
+		Tenants: []dbConfig{{URL: "postgres://db2/svc", Password: ""}},
+	}
+	defer func() { configs = make(map[string]interface{}) }()
+	RegisterConfig("service", &cfg)
+	RegisterConfig("flags", map[string]bool{"new-pricing": true})
+
+	cases := []struct {
+		name     string
+		opts     []ConfigOption
+		url      string
+		expected string
+	}{
+		{
+			"default",
+			nil,
+			"/debug/config",
+			`{"flags":{"new-pricing":true},"service":{"apiKey":"[REDACTED]","db":{"password":"[REDACTED]","pool_size":10,"url":"postgres://user:xxxxx@db:5432/svc"},"labels":{"SigningSecretName":"[REDACTED]","team":"core","x-api-key":"[REDACTED]"},"name":"svc","tenants":[{"password":"[REDACTED]","pool_size":0,"url":"postgres://db2/svc"}]}}`,
+		},
+		{
+			"custom keys",
+			[]ConfigOption{WithConfigPath("test"), WithRedactedKeys("TEAM", "pricing")},
+			"/test",
+			`{"flags":{"new-pricing":"[REDACTED]"},"service":{"apiKey":"[REDACTED]","db":{"password":"[REDACTED]","pool_size":10,"url":"postgres://user:xxxxx@db:5432/svc"},"labels":{"SigningSecretName":"[REDACTED]","team":"[REDACTED]","x-api-key":"[REDACTED]"},"name":"svc","tenants":[{"password":"[REDACTED]","pool_size":0,"url":"postgres://db2/svc"}]}}`,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mux := http.NewServeMux()
+			MountConfig(mux, c.opts...)
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, httptest.NewRequest("GET", c.url, nil))
+			if w.Code != http.StatusOK {
+				t.Fatalf("got status %d, expected %d", w.Code, http.StatusOK)
+			}
+			if w.Body.String() != c.expected {
+				t.Errorf("got body:\n%s\nexpected:\n%s", w.Body.String(), c.expected)
+			}
+		})
+	}
+
+	t.Run("live values", func(t *testing.T) {
+		cfg.Name = "updated"
+		mux := http.NewServeMux()
+		MountConfig(mux)
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest("GET", "/debug/config", nil))
+		if !strings.Contains(w.Body.String(), `"name":"updated"`) {
+			t.Errorf("expected updated value, got %s", w.Body.String())
+		}
+	})
+}
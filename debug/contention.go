@@ -0,0 +1,161 @@
+package debug
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type (
+	// contentionRates are the block profile rate and mutex profile
+	// fraction.
+	contentionRates struct {
+		BlockRate     int `json:"block-rate"`
+		MutexFraction int `json:"mutex-fraction"`
+	}
+)
+
+var (
+	// currentBlockRate is the block profile rate last set by this
+	// package, the runtime does not expose it.
+	currentBlockRate int
+	// contention protects currentBlockRate and revertTimer.
+	contention sync.Mutex
+	// revertTimer reverts the rates set with an expiration.
+	revertTimer *time.Timer
+)
+
+// MountContentionProfiling mounts an endpoint under "/debug/contention" that
+// sets the block profile rate (see runtime.SetBlockProfileRate) and the mutex
+// profile fraction (see runtime.SetMutexProfileFraction) at runtime so that
+// the block and mutex profiles served by the pprof handlers can be enabled
+// temporarily without paying their overhead permanently. The endpoint accepts
+// the following query parameters:
+//
+//   - "block-rate": the block profile rate, one blocking event is sampled
+//     per rate nanoseconds spent blocked, 1 samples all events and 0 disables
+//     the profile.
+//   - "mutex-fraction": the mutex profile fraction, on average 1/fraction
+//     contention events are sampled, 0 disables the profile.
+//   - "reset": "true" disables both profiles.
+//   - "expire": a duration (e.g. "10m") after which the rates set by the
+//     request are reverted to their previous values.
+//
+// The endpoint returns the current rates, for example:
+//
+//	{"block-rate":10000,"mutex-fraction":100,"expires-in":"10m0s"}
+//
+// The path can be changed using WithContentionProfilingPath. See also
+// SetContentionProfiling to set the rates programmatically.
+func MountContentionProfiling(mux Muxer, opts ...ContentionProfilingOption) {
+	o := defaultContentionProfilingOptions()
+	for _, opt := range opts {
+		opt(o)
+	}
+	if !strings.HasPrefix(o.path, "/") {
+		o.path = "/" + o.path
+	}
+	register(mux, "contention", o.path, "Block and mutex profiling rates", func() interface{} {
+		return readContentionRates()
+	})
+	mux.Handle(o.path, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		rates := readContentionRates()
+		prev := *rates
+		switch v := q.Get("reset"); v {
+		case "", "false":
+		case "true":
+			rates.BlockRate, rates.MutexFraction = 0, 0
+		default:
+			http.Error(w, fmt.Sprintf("invalid reset value %q, must be true or false", v), http.StatusBadRequest)
+			return
+		}
+		for _, p := range []struct {
+			name string
+			val  *int
+		}{{"block-rate", &rates.BlockRate}, {"mutex-fraction", &rates.MutexFraction}} {
+			v := q.Get(p.name)
+			if v == "" {
+				continue
+			}
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 0 {
+				http.Error(w, fmt.Sprintf("invalid %s value %q, must be a positive integer", p.name, v), http.StatusBadRequest)
+				return
+			}
+			*p.val = n
+		}
+		var expire time.Duration
+		if v := q.Get("expire"); v != "" {
+			d, err := time.ParseDuration(v)
+			if err != nil || d <= 0 {
+				http.Error(w, fmt.Sprintf("invalid expire value %q, must be a positive duration", v), http.StatusBadRequest)
+				return
+			}
+			expire = d
+		}
+		state := map[string]interface{}{}
+		if *rates != prev {
+			SetContentionProfiling(rates.BlockRate, rates.MutexFraction, expire)
+			if expire > 0 {
+				state["expires-in"] = expire.String()
+			}
+		}
+		rates = readContentionRates()
+		state["block-rate"] = rates.BlockRate
+		state["mutex-fraction"] = rates.MutexFraction
+		js, _ := json.Marshal(state)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(js)
+	}))
+}
+
+// SetContentionProfiling sets the block profile rate (see
+// runtime.SetBlockProfileRate) and the mutex profile fraction (see
+// runtime.SetMutexProfileFraction). If expire is greater than 0 then the
+// previous values are restored once it elapses. Setting the rates cancels any
+// pending restore. Use ResetContentionProfiling to disable both profiles.
+func SetContentionProfiling(blockRate, mutexFraction int, expire time.Duration) {
+	contention.Lock()
+	defer contention.Unlock()
+	if revertTimer != nil {
+		revertTimer.Stop()
+		revertTimer = nil
+	}
+	prev := contentionRates{BlockRate: currentBlockRate, MutexFraction: runtime.SetMutexProfileFraction(-1)}
+	applyContentionRates(blockRate, mutexFraction)
+	if expire > 0 {
+		revertTimer = time.AfterFunc(expire, func() {
+			contention.Lock()
+			defer contention.Unlock()
+			applyContentionRates(prev.BlockRate, prev.MutexFraction)
+			revertTimer = nil
+		})
+	}
+}
+
+// ResetContentionProfiling disables the block and mutex profiles and cancels
+// any pending restore.
+func ResetContentionProfiling() {
+	SetContentionProfiling(0, 0, 0)
+}
+
+// readContentionRates returns the current block profile rate and mutex profile
+// fraction.
+func readContentionRates() *contentionRates {
+	contention.Lock()
+	defer contention.Unlock()
+	return &contentionRates{BlockRate: currentBlockRate, MutexFraction: runtime.SetMutexProfileFraction(-1)}
+}
+
+// applyContentionRates sets the runtime rates, contention must be locked.
+func applyContentionRates(blockRate, mutexFraction int) {
+	runtime.SetBlockProfileRate(blockRate)
+	runtime.SetMutexProfileFraction(mutexFraction)
+	currentBlockRate = blockRate
+}
@@ -0,0 +1,58 @@
+package debug
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMountContentionProfiling(t *testing.T) {
+	defer ResetContentionProfiling()
+	mux := http.NewServeMux()
+	MountContentionProfiling(mux)
+	cases := []struct {
+		name           string
+		url            string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{"get", "/debug/contention", http.StatusOK, `{"block-rate":0,"mutex-fraction":0}`},
+		{"block", "/debug/contention?block-rate=1000", http.StatusOK, `{"block-rate":1000,"mutex-fraction":0}`},
+		{"mutex", "/debug/contention?mutex-fraction=10", http.StatusOK, `{"block-rate":1000,"mutex-fraction":10}`},
+		{"reset", "/debug/contention?reset=true", http.StatusOK, `{"block-rate":0,"mutex-fraction":0}`},
+		{"expire", "/debug/contention?mutex-fraction=5&expire=1h", http.StatusOK, `{"block-rate":0,"expires-in":"1h0m0s","mutex-fraction":5}`},
+		{"invalid block rate", "/debug/contention?block-rate=-1", http.StatusBadRequest, `invalid block-rate value "-1"`},
+		{"invalid mutex fraction", "/debug/contention?mutex-fraction=abc", http.StatusBadRequest, `invalid mutex-fraction value "abc"`},
+		{"invalid reset", "/debug/contention?reset=maybe", http.StatusBadRequest, `invalid reset value "maybe"`},
+		{"invalid expire", "/debug/contention?block-rate=1&expire=soon", http.StatusBadRequest, `invalid expire value "soon"`},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, httptest.NewRequest("GET", c.url, nil))
+			if w.Code != c.expectedStatus {
+				t.Errorf("got status %d, expected %d", w.Code, c.expectedStatus)
+			}
+			if !strings.Contains(w.Body.String(), c.expectedBody) {
+				t.Errorf("got body %q, expected it to contain %q", w.Body.String(), c.expectedBody)
+			}
+		})
+	}
+}
+
+func TestSetContentionProfilingExpire(t *testing.T) {
+	defer ResetContentionProfiling()
+	SetContentionProfiling(100, 2, 0)
+	SetContentionProfiling(1000, 20, 50*time.Millisecond)
+	if got := runtime.SetMutexProfileFraction(-1); got != 20 {
+		t.Errorf("got mutex fraction %d, expected 20", got)
+	}
+	time.Sleep(200 * time.Millisecond)
+	rates := readContentionRates()
+	if rates.BlockRate != 100 || rates.MutexFraction != 2 {
+		t.Errorf("got rates %+v, expected {BlockRate:100 MutexFraction:2}", *rates)
+	}
+}
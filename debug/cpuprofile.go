@@ -0,0 +1,168 @@
+package debug
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	rpprof "runtime/pprof"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"goa.design/clue/log"
+)
+
+const (
+	// DefaultCPUProfileDuration is the duration of the profiles captured by
+	// MountCPUProfile when the request does not specify one.
+	DefaultCPUProfileDuration = 30 * time.Second
+	// DefaultMaxCPUProfileDuration is the default maximum duration of the
+	// profiles captured by MountCPUProfile.
+	DefaultMaxCPUProfileDuration = time.Minute
+	// DefaultCPUProfileInterval is the default minimum interval between two
+	// profiles captured by MountCPUProfile.
+	DefaultCPUProfileInterval = 5 * time.Minute
+)
+
+// MountCPUProfile mounts an endpoint under "/debug/cpuprofile" that returns a
+// CPU profile captured for the duration given by the "seconds" query parameter
+// (30 by default, capped to the maximum duration) for use with "go tool pprof".
+// Unlike the profile handler mounted by MountPprofHandlers the endpoint is
+// guarded so that it is safe to expose on production servers (behind
+// authentication, see Guard):
+//
+//   - only one capture may run at a time, concurrent requests get a 409
+//     response.
+//   - captures are rate limited to one per interval (5 minutes by default),
+//     requests made too early get a 429 response with a Retry-After header
+//     unless the "force" query parameter is "true".
+//   - every request that starts or is denied a capture results in an audit
+//     log entry written with log.Print that records the outcome, the requested
+//     duration, whether the capture was forced and the author given by the
+//     "who" query parameter (defaults to the remote address).
+//
+// The capture stops early if the client goes away. The path can be changed
+// using WithCPUProfilePath, the maximum duration of a capture (1 minute by
+// default) using WithMaxCPUProfileDuration, the interval using
+// WithCPUProfileInterval and the context used to write the audit log entries
+// using WithCPUProfileLogContext. Note that the write timeout of the HTTP server
+// must be longer than the capture duration.
+//
+// The limits bound the overhead of the captures but not what they reveal: the
+// profile lists the function names and source files of the service, see Guard.
+func MountCPUProfile(mux Muxer, opts ...CPUProfileOption) {
+	o := defaultCPUProfileOptions()
+	for _, opt := range opts {
+		opt(o)
+	}
+	if !strings.HasPrefix(o.path, "/") {
+		o.path = "/" + o.path
+	}
+	var (
+		lock    sync.Mutex
+		running bool
+		last    time.Time
+	)
+	register(mux, "cpu profile", o.path, "Guarded CPU profile capture", func() interface{} {
+		lock.Lock()
+		defer lock.Unlock()
+		state := map[string]interface{}{"status": "idle"}
+		if running {
+			state["status"] = "capturing"
+		}
+		if !last.IsZero() {
+			state["last"] = last.UTC().Format(time.RFC3339)
+		}
+		return state
+	})
+	mux.Handle(o.path, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		d := DefaultCPUProfileDuration
+		if d > o.maxDuration {
+			d = o.maxDuration
+		}
+		if v := q.Get("seconds"); v != "" {
+			secs, err := strconv.ParseFloat(v, 64)
+			if err != nil || secs <= 0 {
+				http.Error(w, fmt.Sprintf("invalid seconds value %q, must be a positive number", v), http.StatusBadRequest)
+				return
+			}
+			d = time.Duration(secs * float64(time.Second))
+		}
+		if d > o.maxDuration {
+			http.Error(w, fmt.Sprintf("duration %s exceeds maximum of %s", d, o.maxDuration), http.StatusBadRequest)
+			return
+		}
+		var force bool
+		switch v := q.Get("force"); v {
+		case "", "false":
+		case "true":
+			force = true
+		default:
+			http.Error(w, fmt.Sprintf("invalid force value %q, must be true or false", v), http.StatusBadRequest)
+			return
+		}
+		who := q.Get("who")
+		if who == "" {
+			who = r.RemoteAddr
+		}
+		ctx := r.Context()
+		if o.logCtx != nil {
+			ctx = o.logCtx
+		}
+		audit := func(status string) {
+			log.Print(ctx,
+				log.KV{K: log.MessageKey, V: "cpu profile"},
+				log.KV{K: "status", V: status},
+				log.KV{K: "who", V: who},
+				log.KV{K: "duration", V: d.String()},
+				log.KV{K: "forced", V: force},
+			)
+		}
+
+		lock.Lock()
+		if running {
+			lock.Unlock()
+			audit("conflict")
+			http.Error(w, "a CPU profile capture is already in progress", http.StatusConflict)
+			return
+		}
+		if wait := o.interval - time.Since(last); !force && !last.IsZero() && wait > 0 {
+			lock.Unlock()
+			audit("rate-limited")
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			http.Error(w, fmt.Sprintf("a CPU profile was captured less than %s ago, retry in %s or force the capture", o.interval, wait.Round(time.Second)), http.StatusTooManyRequests)
+			return
+		}
+		running = true
+		lock.Unlock()
+		defer func() {
+			lock.Lock()
+			running = false
+			lock.Unlock()
+		}()
+
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Header().Set("Content-Disposition", `attachment; filename="profile"`)
+		if err := rpprof.StartCPUProfile(w); err != nil {
+			// Profiling may have been started by other means, e.g. the
+			// pprof profile handler.
+			audit("failed")
+			w.Header().Del("Content-Disposition")
+			http.Error(w, fmt.Sprintf("failed to start CPU profile: %s", err), http.StatusConflict)
+			return
+		}
+		lock.Lock()
+		last = time.Now()
+		lock.Unlock()
+		audit("started")
+		timer := time.NewTimer(d)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-r.Context().Done():
+		}
+		rpprof.StopCPUProfile()
+	}))
+}
@@ -0,0 +1,75 @@
+package debug
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"goa.design/clue/log"
+)
+
+func TestMountCPUProfile(t *testing.T) {
+	var buf bytes.Buffer
+	ctx := log.Context(context.Background(), log.WithOutput(&buf), log.WithFormat(logKeyValsOnly))
+	mux := http.NewServeMux()
+	MountCPUProfile(mux, WithMaxCPUProfileDuration(time.Second), WithCPUProfileInterval(time.Hour), WithCPUProfileLogContext(ctx))
+	cases := []struct {
+		name           string
+		url            string
+		expectedStatus int
+		expectedBody   string
+		expectedLog    string
+	}{
+		{"capture", "/debug/cpuprofile?seconds=0.05&who=alice", http.StatusOK, "\x1f\x8b", "msg=cpu profile status=started who=alice duration=50ms forced=false "},
+		{"rate limited", "/debug/cpuprofile?seconds=0.05&who=bob", http.StatusTooManyRequests, "a CPU profile was captured less than 1h0m0s ago", "msg=cpu profile status=rate-limited who=bob duration=50ms forced=false "},
+		{"forced", "/debug/cpuprofile?seconds=0.05&force=true&who=bob", http.StatusOK, "\x1f\x8b", "msg=cpu profile status=started who=bob duration=50ms forced=true "},
+		{"invalid seconds", "/debug/cpuprofile?seconds=abc", http.StatusBadRequest, `invalid seconds value "abc"`, ""},
+		{"invalid force", "/debug/cpuprofile?force=yes", http.StatusBadRequest, `invalid force value "yes"`, ""},
+		{"too long", "/debug/cpuprofile?seconds=2", http.StatusBadRequest, "duration 2s exceeds maximum of 1s", ""},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			buf.Reset()
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, httptest.NewRequest("GET", c.url, nil))
+			if w.Code != c.expectedStatus {
+				t.Errorf("got status %d, expected %d", w.Code, c.expectedStatus)
+			}
+			if !strings.Contains(w.Body.String(), c.expectedBody) {
+				t.Errorf("got body %.40q, expected it to contain %q", w.Body.String(), c.expectedBody)
+			}
+			if got := buf.String(); got != c.expectedLog {
+				t.Errorf("got log %q, expected %q", got, c.expectedLog)
+			}
+			if c.expectedStatus == http.StatusTooManyRequests && w.Header().Get("Retry-After") == "" {
+				t.Error("expected Retry-After header")
+			}
+		})
+	}
+}
+
+func TestMountCPUProfileConcurrent(t *testing.T) {
+	mux := http.NewServeMux()
+	MountCPUProfile(mux, WithCPUProfileInterval(0))
+	var wg sync.WaitGroup
+	codes := make([]int, 2)
+	for i := range codes {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, httptest.NewRequest("GET", "/debug/cpuprofile?seconds=0.2", nil))
+			codes[i] = w.Code
+		}(i)
+		time.Sleep(50 * time.Millisecond)
+	}
+	wg.Wait()
+	if codes[0] != http.StatusOK || codes[1] != http.StatusConflict {
+		t.Errorf("got status codes %v, expected [200 409]", codes)
+	}
+}
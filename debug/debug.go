@@ -2,14 +2,22 @@ package debug
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/pprof"
+	"runtime"
+	rpprof "runtime/pprof"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	goa "goa.design/goa/v3/pkg"
 
+	"goa.design/clue/health"
 	"goa.design/clue/log"
+	"goa.design/clue/trace"
 )
 
 // Muxer is the HTTP mux interface used by the debug package.
@@ -39,6 +47,22 @@ var (
 // actually configure the current logger. The logger is configured by the
 // middleware returned by the HTTP function or by the gRPC interceptors returned
 // by the UnaryServerInterceptor and StreamServerInterceptor functions.
+//
+// If a log control is given with WithLogControl the changes are also applied to
+// the control (see log.Control) and the endpoint accepts the following
+// additional query parameters:
+//
+//   - "module" and "level": set the minimum level ("debug", "info", "error" or
+//     "default" to remove it) of the entries written for the given module.
+//   - "revert": a duration (e.g. "10m") after which the changes made by the
+//     request are reverted.
+//   - "who": identifies the author of the changes in the control history,
+//     defaults to the remote address.
+//
+// The endpoint then also returns the per-module levels and the previous value
+// of the settings changed by the request, for example:
+//
+//	{"debug-logs":"on","modules":{"db":"debug"},"previous":{"module:db":"default"},"revert-in":"10m0s"}
 func MountDebugLogEnabler(mux Muxer, opts ...DebugLogEnablerOption) {
 	o := defaultDebugLogEnablerOptions()
 	for _, opt := range opts {
@@ -47,6 +71,14 @@ func MountDebugLogEnabler(mux Muxer, opts ...DebugLogEnablerOption) {
 	if !strings.HasPrefix(o.path, "/") {
 		o.path = "/" + o.path
 	}
+	if o.control != nil {
+		register(mux, "logs", o.path, "Debug logs and per-module log levels", func() interface{} {
+			return map[string]interface{}{"debug-logs": onOff(o.control.Debug()), "modules": len(o.control.ModuleLevels())}
+		})
+		mux.Handle(o.path, logControlHandler(o))
+		return
+	}
+	register(mux, "logs", o.path, "Debug logs", func() interface{} { return onOff(debugLogs) })
 	mux.Handle(o.path, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if q := r.URL.Query().Get(o.query); q == o.onval {
 			debugLogs = true
@@ -61,6 +93,436 @@ func MountDebugLogEnabler(mux Muxer, opts ...DebugLogEnablerOption) {
 	}))
 }
 
+// logControlHandler returns the handler mounted by MountDebugLogEnabler when a
+// log control is configured.
+func logControlHandler(o *dleOptions) http.Handler {
+	reverts := newPendingReverts()
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		who := q.Get("who")
+		if who == "" {
+			who = r.RemoteAddr
+		}
+		var revert time.Duration
+		if v := q.Get("revert"); v != "" {
+			d, err := time.ParseDuration(v)
+			if err != nil || d <= 0 {
+				http.Error(w, fmt.Sprintf("invalid revert value %q, must be a positive duration", v), http.StatusBadRequest)
+				return
+			}
+			revert = d
+		}
+		module, level := q.Get("module"), q.Get("level")
+		var sev log.Severity
+		if module != "" {
+			var ok bool
+			if sev, ok = parseSeverity(level); !ok {
+				http.Error(w, fmt.Sprintf("invalid level value %q, must be debug, info, error or default", level), http.StatusBadRequest)
+				return
+			}
+		}
+		previous := make(map[string]string)
+		if v := q.Get(o.query); v == o.onval || v == o.offval {
+			on := v == o.onval
+			old := o.control.SetDebug(who, on)
+			debugLogs = on
+			previous[o.query] = o.offval
+			if old {
+				previous[o.query] = o.onval
+			}
+			reverts.schedule(o.query, revert, func() {
+				if o.control.Debug() != on {
+					return
+				}
+				// Note: debugLogs is set first so that readers of the log
+				// control see it.
+				debugLogs = old
+				o.control.SetDebug(who+" (revert)", old)
+			})
+		}
+		if module != "" {
+			old := o.control.SetModuleLevel(who, module, sev)
+			previous["module:"+module] = severityName(old)
+			reverts.schedule("module:"+module, revert, func() {
+				if cur, _ := o.control.ModuleLevel(module); cur != sev {
+					return
+				}
+				o.control.SetModuleLevel(who+" (revert)", module, old)
+			})
+		}
+		state := map[string]interface{}{o.query: o.offval}
+		if o.control.Debug() {
+			state[o.query] = o.onval
+		}
+		if levels := o.control.ModuleLevels(); len(levels) > 0 {
+			modules := make(map[string]string, len(levels))
+			for m, sev := range levels {
+				modules[m] = severityName(sev)
+			}
+			state["modules"] = modules
+		}
+		if len(previous) > 0 {
+			state["previous"] = previous
+		}
+		if revert > 0 && len(previous) > 0 {
+			state["revert-in"] = revert.String()
+		}
+		js, _ := json.Marshal(state)
+		w.Write(js)
+	})
+}
+
+// MountPayloadLogToggle mounts an endpoint under "/debug/payloads" that enables
+// or disables the logging of request and response payloads done by LogPayloads
+// using the log control ctl (see log.Control). The endpoint accepts the
+// following query parameters:
+//
+//   - "payloads": "on" enables payload logging, "off" disables it and, if
+//     "route" is set, "default" makes the route use the global setting.
+//   - "route": applies the change to the given Goa route only, identified by
+//     the service and method names separated with a dot (e.g.
+//     "front.forecast").
+//   - "expire": a duration (e.g. "10m") after which the change is reverted.
+//   - "who": identifies the author of the change in the control history,
+//     defaults to the remote address.
+//
+// The endpoint returns the current settings and the previous value of the
+// setting changed by the request, for example:
+//
+//	{"expires-in":"10m0s","payloads":"off","previous":"off","routes":{"front.forecast":"on"}}
+//
+// The path can be changed using WithPayloadLogTogglePath.
+func MountPayloadLogToggle(mux Muxer, ctl *log.Control, opts ...PayloadLogToggleOption) {
+	o := defaultPayloadLogToggleOptions()
+	for _, opt := range opts {
+		opt(o)
+	}
+	if !strings.HasPrefix(o.path, "/") {
+		o.path = "/" + o.path
+	}
+	register(mux, "payloads", o.path, "Request and result payload logging", func() interface{} { return onOff(ctl.Payloads()) })
+	reverts := newPendingReverts()
+	mux.Handle(o.path, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		who := q.Get("who")
+		if who == "" {
+			who = r.RemoteAddr
+		}
+		var expire time.Duration
+		if v := q.Get("expire"); v != "" {
+			d, err := time.ParseDuration(v)
+			if err != nil || d <= 0 {
+				http.Error(w, fmt.Sprintf("invalid expire value %q, must be a positive duration", v), http.StatusBadRequest)
+				return
+			}
+			expire = d
+		}
+		route := q.Get("route")
+		key := "payloads"
+		if route != "" {
+			key = "route:" + route
+		}
+		state := make(map[string]interface{})
+		var undo func()
+		changed := true
+		switch v := q.Get("payloads"); v {
+		case "":
+			changed = false
+		case "on", "off":
+			on := v == "on"
+			if route == "" {
+				old := ctl.SetPayloads(who, on)
+				state["previous"] = onOff(old)
+				undo = func() {
+					if ctl.Payloads() == on {
+						ctl.SetPayloads(who+" (expired)", old)
+					}
+				}
+			} else {
+				old, overridden := ctl.RoutesPayloads()[route]
+				prev := ctl.SetRoutePayloads(who, route, on)
+				state["previous"] = onOff(prev)
+				undo = func() {
+					if cur, ok := ctl.RoutesPayloads()[route]; !ok || cur != on {
+						return
+					}
+					if overridden {
+						ctl.SetRoutePayloads(who+" (expired)", route, old)
+					} else {
+						ctl.ResetRoutePayloads(who+" (expired)", route)
+					}
+				}
+			}
+		case "default":
+			if route == "" {
+				http.Error(w, `payloads value "default" requires a route`, http.StatusBadRequest)
+				return
+			}
+			old, overridden := ctl.RoutesPayloads()[route]
+			ctl.ResetRoutePayloads(who, route)
+			if overridden {
+				state["previous"] = onOff(old)
+				undo = func() {
+					if _, ok := ctl.RoutesPayloads()[route]; !ok {
+						ctl.SetRoutePayloads(who+" (expired)", route, old)
+					}
+				}
+			}
+		default:
+			http.Error(w, fmt.Sprintf("invalid payloads value %q, must be on, off or default", v), http.StatusBadRequest)
+			return
+		}
+		if changed {
+			reverts.schedule(key, expire, undo)
+		}
+		if expire > 0 && undo != nil {
+			state["expires-in"] = expire.String()
+		}
+		state["payloads"] = onOff(ctl.Payloads())
+		if routes := ctl.RoutesPayloads(); len(routes) > 0 {
+			rs := make(map[string]string, len(routes))
+			for r, on := range routes {
+				rs[r] = onOff(on)
+			}
+			state["routes"] = rs
+		}
+		js, _ := json.Marshal(state)
+		w.Write(js)
+	}))
+}
+
+// onOff returns "on" if on is true, "off" otherwise.
+func onOff(on bool) string {
+	if on {
+		return "on"
+	}
+	return "off"
+}
+
+// pendingReverts tracks the timers that revert the settings changed by a debug
+// endpoint so that a new change of a setting cancels its pending revert.
+type pendingReverts struct {
+	lock   sync.Mutex
+	timers map[string]*time.Timer
+}
+
+// newPendingReverts returns an empty set of pending reverts.
+func newPendingReverts() *pendingReverts {
+	return &pendingReverts{timers: make(map[string]*time.Timer)}
+}
+
+// schedule cancels the pending revert of the setting with the given key if
+// any and calls undo after d. undo is not called if d is not positive, if undo
+// is nil or if the setting changes again before d elapses. undo should only
+// revert the setting if it still has the value set by the change.
+func (p *pendingReverts) schedule(key string, d time.Duration, undo func()) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if t, ok := p.timers[key]; ok {
+		t.Stop()
+		delete(p.timers, key)
+	}
+	if d <= 0 || undo == nil {
+		return
+	}
+	var timer *time.Timer
+	timer = time.AfterFunc(d, func() {
+		p.lock.Lock()
+		defer p.lock.Unlock()
+		// Note: a timer stopped while its callback waits for the lock
+		// still runs it.
+		if p.timers[key] != timer {
+			return
+		}
+		delete(p.timers, key)
+		undo()
+	})
+	p.timers[key] = timer
+}
+
+// parseSeverity returns the severity with the given name, 0 for "default".
+func parseSeverity(name string) (log.Severity, bool) {
+	switch name {
+	case "debug":
+		return log.SeverityDebug, true
+	case "info":
+		return log.SeverityInfo, true
+	case "error":
+		return log.SeverityError, true
+	case "default", "":
+		return 0, true
+	}
+	return 0, false
+}
+
+// severityName returns the name of sev, "default" for 0.
+func severityName(sev log.Severity) string {
+	if sev == 0 {
+		return "default"
+	}
+	return sev.String()
+}
+
+// MountTraceControl mounts an endpoint under "/debug/trace" that controls
+// tracing at runtime using the trace.Control of traceCtx. The endpoint accepts
+// the following query parameters:
+//
+//   - "tracing": "on" enables tracing, "off" disables it.
+//   - "sampling-ratio": a number between 0 and 1 overrides the sampling ratio
+//     of requests without a parent, "default" removes the override.
+//   - "force-sample": forces the sampling of the next N requests.
+//
+// The endpoint returns the current tracing state, for example:
+//
+//	{"tracing":"on","sampling-ratio":"0.5","force-sample":10}
+//
+// The path can be changed using WithTraceControlPath. MountTraceControl panics
+// if traceCtx hasn't been initialized with trace.Context.
+func MountTraceControl(mux Muxer, traceCtx context.Context, opts ...TraceControlOption) {
+	o := defaultTraceControlOptions()
+	for _, opt := range opts {
+		opt(o)
+	}
+	if !strings.HasPrefix(o.path, "/") {
+		o.path = "/" + o.path
+	}
+	control := trace.TracingControl(traceCtx)
+	register(mux, "tracing", o.path, "Tracing and sampling control", func() interface{} { return onOff(control.Enabled()) })
+	mux.Handle(o.path, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		switch v := q.Get("tracing"); v {
+		case "":
+		case "on":
+			control.SetEnabled(true)
+		case "off":
+			control.SetEnabled(false)
+		default:
+			http.Error(w, fmt.Sprintf("invalid tracing value %q, must be on or off", v), http.StatusBadRequest)
+			return
+		}
+		if v := q.Get("sampling-ratio"); v == "default" {
+			control.SetSamplingRatio(-1)
+		} else if v != "" {
+			ratio, err := strconv.ParseFloat(v, 64)
+			if err != nil || ratio < 0 || ratio > 1 {
+				http.Error(w, fmt.Sprintf("invalid sampling-ratio value %q, must be between 0 and 1", v), http.StatusBadRequest)
+				return
+			}
+			control.SetSamplingRatio(ratio)
+		}
+		if v := q.Get("force-sample"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 0 {
+				http.Error(w, fmt.Sprintf("invalid force-sample value %q, must be a positive integer", v), http.StatusBadRequest)
+				return
+			}
+			control.ForceSample(n)
+		}
+		state := struct {
+			Tracing       string `json:"tracing"`
+			SamplingRatio string `json:"sampling-ratio"`
+			ForceSample   int    `json:"force-sample"`
+		}{"off", "default", control.ForcedSamples()}
+		if control.Enabled() {
+			state.Tracing = "on"
+		}
+		if ratio, ok := control.SamplingRatio(); ok {
+			state.SamplingRatio = strconv.FormatFloat(ratio, 'f', -1, 64)
+		}
+		js, _ := json.Marshal(state)
+		w.Write(js)
+	}))
+}
+
+// MountReadinessToggle mounts an endpoint under "/debug/ready" that makes it
+// possible to take the service out of rotation without stopping it, e.g. to
+// drain it before maintenance. The endpoint accepts a single query parameter
+// "ready": "off" makes the readiness checks of d fail and "on" restores them.
+// The endpoint returns the current readiness, for example:
+//
+//	{"ready":"off"}
+//
+// The path can be changed using WithReadinessTogglePath.
+func MountReadinessToggle(mux Muxer, d health.Drainer, opts ...ReadinessToggleOption) {
+	o := defaultReadinessToggleOptions()
+	for _, opt := range opts {
+		opt(o)
+	}
+	if !strings.HasPrefix(o.path, "/") {
+		o.path = "/" + o.path
+	}
+	register(mux, "readiness", o.path, "Readiness toggle", func() interface{} { return onOff(d.Ready()) })
+	mux.Handle(o.path, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch v := r.URL.Query().Get("ready"); v {
+		case "":
+		case "on":
+			d.SetReady(true)
+		case "off":
+			d.SetReady(false)
+		default:
+			http.Error(w, fmt.Sprintf("invalid ready value %q, must be on or off", v), http.StatusBadRequest)
+			return
+		}
+		if d.Ready() {
+			w.Write([]byte(`{"ready":"on"}`))
+		} else {
+			w.Write([]byte(`{"ready":"off"}`))
+		}
+	}))
+}
+
+// MountGoroutineDump mounts an endpoint under "/debug/goroutines" that returns
+// the stack traces of all the goroutines so that deadlocks can be diagnosed
+// without access to the container. The endpoint accepts the following query
+// parameters:
+//
+//   - "format": "text" (default) returns the full stack dump in the format
+//     used by Go when a program panics, "pprof" returns the goroutine profile
+//     in the pprof format for use with "go tool pprof".
+//   - "debug": with the text format "1" groups goroutines with identical
+//     stacks instead of listing each one (equivalent to "debug=2").
+//
+// The path can be changed using WithGoroutineDumpPath.
+//
+// The dump includes the function names, source file paths and arguments of
+// every goroutine, which reveals the internals of the service and may contain
+// values such as IDs or keys passed as arguments. Building the dump stops the
+// world, so repeated requests also slow down the service, see Guard.
+func MountGoroutineDump(mux Muxer, opts ...GoroutineDumpOption) {
+	o := defaultGoroutineDumpOptions()
+	for _, opt := range opts {
+		opt(o)
+	}
+	if !strings.HasPrefix(o.path, "/") {
+		o.path = "/" + o.path
+	}
+	register(mux, "goroutines", o.path, "Goroutine stack dump", func() interface{} { return runtime.NumGoroutine() })
+	mux.Handle(o.path, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		debug := 2
+		switch v := q.Get("debug"); v {
+		case "", "2":
+		case "1":
+			debug = 1
+		default:
+			http.Error(w, fmt.Sprintf("invalid debug value %q, must be 1 or 2", v), http.StatusBadRequest)
+			return
+		}
+		switch v := q.Get("format"); v {
+		case "", "text":
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		case "pprof":
+			w.Header().Set("Content-Type", "application/octet-stream")
+			w.Header().Set("Content-Disposition", `attachment; filename="goroutine"`)
+			debug = 0
+		default:
+			http.Error(w, fmt.Sprintf("invalid format value %q, must be text or pprof", v), http.StatusBadRequest)
+			return
+		}
+		rpprof.Lookup("goroutine").WriteTo(w, debug)
+	}))
+}
+
 // MountPprofHandlers mounts pprof handlers under /debug/pprof/. The list of
 // mounted handlers is:
 //
@@ -78,9 +540,16 @@ func MountDebugLogEnabler(mux Muxer, opts ...DebugLogEnablerOption) {
 //
 // See the pprof package documentation for more information.
 //
-// The path prefix ("/debug/pprof/") can be changed using WithPprofPrefix.
-// Note: do not call this function on production servers accessible to the
-// public!  It exposes sensitive information about the server.
+// The path prefix ("/debug/pprof/") can be changed using WithPrefix and the
+// handlers can be wrapped with middlewares (e.g. to authenticate requests)
+// using WithPprofMiddleware. mux may be a http.ServeMux or a Goa muxer adapted
+// with Adapt.
+//
+// The handlers expose the command line of the process (which may contain
+// secrets) and the function names, source files and allocation sites of the
+// service, and the CPU profile and execution trace handlers let any caller slow
+// down the service for the requested duration. Protect them with
+// WithPprofMiddleware or Guard.
 func MountPprofHandlers(mux Muxer, opts ...PprofOption) {
 	o := defaultPprofOptions()
 	for _, opt := range opts {
@@ -92,18 +561,27 @@ func MountPprofHandlers(mux Muxer, opts ...PprofOption) {
 	if !strings.HasSuffix(o.prefix, "/") {
 		o.prefix = o.prefix + "/"
 	}
-	mux.HandleFunc(o.prefix, pprof.Index)
-	mux.HandleFunc(o.prefix+"cmdline", pprof.Cmdline)
-	mux.HandleFunc(o.prefix+"profile", pprof.Profile)
-	mux.HandleFunc(o.prefix+"symbol", pprof.Symbol)
-	mux.HandleFunc(o.prefix+"trace", pprof.Trace)
+	handle := func(path string, h http.HandlerFunc) {
+		var handler http.Handler = h
+		for i := len(o.middlewares) - 1; i >= 0; i-- {
+			handler = o.middlewares[i](handler)
+		}
+		mux.Handle(path, handler)
+	}
+	register(mux, "pprof", o.prefix, "pprof profiles", nil)
+	handle(o.prefix, pprof.Index)
+	handle(o.prefix+"cmdline", pprof.Cmdline)
+	handle(o.prefix+"profile", pprof.Profile)
+	handle(o.prefix+"symbol", pprof.Symbol)
+	handle(o.prefix+"trace", pprof.Trace)
 }
 
 // LogPayloads returns a Goa endpoint middleware that logs request payloads and
 // response results using debug log entries.
 //
 // Note: this middleware marshals the request and response data using the
-// standard JSON marshaller. It only marshals if debug logs are enabled.
+// standard JSON marshaller. It only marshals if debug logs are enabled and
+// payload logging is not disabled by the log control (see log.Control).
 func LogPayloads(opts ...LogPayloadsOption) func(goa.Endpoint) goa.Endpoint {
 	return func(next goa.Endpoint) goa.Endpoint {
 		options := defaultLogPayloadsOptions()
@@ -119,7 +597,7 @@ func LogPayloads(opts ...LogPayloadsOption) func(goa.Endpoint) goa.Endpoint {
 			resKey = "client-" + resKey
 		}
 		return func(ctx context.Context, req interface{}) (interface{}, error) {
-			if !log.DebugEnabled(ctx) {
+			if !log.DebugEnabled(ctx) || !log.PayloadsEnabled(ctx) {
 				return next(ctx, req)
 			}
 			reqs := options.format(ctx, req)
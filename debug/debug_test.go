@@ -7,11 +7,17 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
-	"goa.design/clue/internal/testsvc"
-	"goa.design/clue/internal/testsvc/gen/test"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"goa.design/clue/health"
 	"goa.design/clue/log"
+	"goa.design/clue/testing/testsvc"
+	"goa.design/clue/testing/testsvc/gen/test"
+	"goa.design/clue/trace"
 )
 
 func TestMountDebugLogEnabler(t *testing.T) {
@@ -75,6 +81,115 @@ func TestMountDebugLogEnabler(t *testing.T) {
 	}
 }
 
+func TestMountTraceControl(t *testing.T) {
+	cases := []struct {
+		name           string
+		path           string
+		urls           []string
+		expectedStatus int
+		expectedResp   string
+	}{
+		{"defaults", "", []string{"/debug/trace"}, http.StatusOK,
+			`{"tracing":"on","sampling-ratio":"default","force-sample":0}`},
+		{"disable", "", []string{"/debug/trace?tracing=off"}, http.StatusOK,
+			`{"tracing":"off","sampling-ratio":"default","force-sample":0}`},
+		{"enable", "", []string{"/debug/trace?tracing=off", "/debug/trace?tracing=on"}, http.StatusOK,
+			`{"tracing":"on","sampling-ratio":"default","force-sample":0}`},
+		{"ratio", "", []string{"/debug/trace?sampling-ratio=0.5"}, http.StatusOK,
+			`{"tracing":"on","sampling-ratio":"0.5","force-sample":0}`},
+		{"ratio-default", "", []string{"/debug/trace?sampling-ratio=0.5", "/debug/trace?sampling-ratio=default"}, http.StatusOK,
+			`{"tracing":"on","sampling-ratio":"default","force-sample":0}`},
+		{"force-sample", "", []string{"/debug/trace?force-sample=10"}, http.StatusOK,
+			`{"tracing":"on","sampling-ratio":"default","force-sample":10}`},
+		{"path", "trace", []string{"/trace?tracing=off"}, http.StatusOK,
+			`{"tracing":"off","sampling-ratio":"default","force-sample":0}`},
+		{"invalid-tracing", "", []string{"/debug/trace?tracing=maybe"}, http.StatusBadRequest,
+			"invalid tracing value \"maybe\", must be on or off\n"},
+		{"invalid-ratio", "", []string{"/debug/trace?sampling-ratio=2"}, http.StatusBadRequest,
+			"invalid sampling-ratio value \"2\", must be between 0 and 1\n"},
+		{"invalid-force-sample", "", []string{"/debug/trace?force-sample=-1"}, http.StatusBadRequest,
+			"invalid force-sample value \"-1\", must be a positive integer\n"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			traceCtx, err := trace.Context(context.Background(), "test", trace.WithExporter(tracetest.NewInMemoryExporter()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			mux := http.NewServeMux()
+			var options []TraceControlOption
+			if c.path != "" {
+				options = append(options, WithTraceControlPath(c.path))
+			}
+			MountTraceControl(mux, traceCtx, options...)
+			ts := httptest.NewServer(mux)
+			defer ts.Close()
+
+			var status int
+			var resp string
+			for _, url := range c.urls {
+				status, resp = makeRequest(t, ts.URL+url)
+			}
+
+			if status != c.expectedStatus {
+				t.Errorf("got status %d, expected %d", status, c.expectedStatus)
+			}
+			if resp != c.expectedResp {
+				t.Errorf("got body %q, expected %q", resp, c.expectedResp)
+			}
+		})
+	}
+}
+
+func TestMountReadinessToggle(t *testing.T) {
+	cases := []struct {
+		name           string
+		path           string
+		urls           []string
+		expectedStatus int
+		expectedResp   string
+		expectedReady  bool
+	}{
+		{"defaults", "", []string{"/debug/ready"}, http.StatusOK, `{"ready":"on"}`, true},
+		{"off", "", []string{"/debug/ready?ready=off"}, http.StatusOK, `{"ready":"off"}`, false},
+		{"on", "", []string{"/debug/ready?ready=off", "/debug/ready?ready=on"}, http.StatusOK, `{"ready":"on"}`, true},
+		{"path", "ready", []string{"/ready?ready=off"}, http.StatusOK, `{"ready":"off"}`, false},
+		{"invalid", "", []string{"/debug/ready?ready=maybe"}, http.StatusBadRequest,
+			"invalid ready value \"maybe\", must be on or off\n", true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			chk := health.NewChecker()
+			mux := http.NewServeMux()
+			var options []ReadinessToggleOption
+			if c.path != "" {
+				options = append(options, WithReadinessTogglePath(c.path))
+			}
+			MountReadinessToggle(mux, chk, options...)
+			ts := httptest.NewServer(mux)
+			defer ts.Close()
+
+			var status int
+			var resp string
+			for _, url := range c.urls {
+				status, resp = makeRequest(t, ts.URL+url)
+			}
+
+			if status != c.expectedStatus {
+				t.Errorf("got status %d, expected %d", status, c.expectedStatus)
+			}
+			if resp != c.expectedResp {
+				t.Errorf("got body %q, expected %q", resp, c.expectedResp)
+			}
+			if chk.Ready() != c.expectedReady {
+				t.Errorf("got ready %v, expected %v", chk.Ready(), c.expectedReady)
+			}
+		})
+	}
+}
+
 func TestMountPprofHandlers(t *testing.T) {
 	mux := http.NewServeMux()
 	MountPprofHandlers(mux)
@@ -212,3 +327,239 @@ func makeRequest(t *testing.T, url string) (int, string) {
 	buf.ReadFrom(res.Body)
 	return res.StatusCode, buf.String()
 }
+
+func TestMountPprofHandlersMiddleware(t *testing.T) {
+	var calls []string
+	middleware := func(name string) func(http.Handler) http.Handler {
+		return func(h http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls = append(calls, name)
+				if r.Header.Get("Authorization") == "" {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				h.ServeHTTP(w, r)
+			})
+		}
+	}
+	mux := http.NewServeMux()
+	MountPprofHandlers(mux, WithPprofMiddleware(middleware("outer"), middleware("inner")))
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("GET", "/debug/pprof/cmdline", nil))
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("got status %d, expected %d", w.Code, http.StatusUnauthorized)
+	}
+	req := httptest.NewRequest("GET", "/debug/pprof/cmdline", nil)
+	req.Header.Set("Authorization", "secret")
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("got status %d, expected %d", w.Code, http.StatusOK)
+	}
+	expected := "outer,outer,inner"
+	if got := strings.Join(calls, ","); got != expected {
+		t.Errorf("got middleware calls %q, expected %q", got, expected)
+	}
+}
+
+func TestMountDebugLogEnablerWithLogControl(t *testing.T) {
+	defer func() { debugLogs = false }()
+	ctl := log.NewControl()
+	mux := http.NewServeMux()
+	MountDebugLogEnabler(mux, WithLogControl(ctl))
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	cases := []struct {
+		name           string
+		url            string
+		expectedStatus int
+		expectedResp   string
+	}{
+		{"state", "/debug", http.StatusOK, `{"debug-logs":"off"}`},
+		{"enable", "/debug?debug-logs=on&who=alice", http.StatusOK, `{"debug-logs":"on","previous":{"debug-logs":"off"}}`},
+		{"module", "/debug?module=db&level=error", http.StatusOK, `{"debug-logs":"on","modules":{"db":"error"},"previous":{"module:db":"default"}}`},
+		{"module default", "/debug?module=db&level=default", http.StatusOK, `{"debug-logs":"on","previous":{"module:db":"error"}}`},
+		{"invalid level", "/debug?module=db&level=trace", http.StatusBadRequest, "invalid level value \"trace\", must be debug, info, error or default\n"},
+		{"invalid revert", "/debug?debug-logs=off&revert=soon", http.StatusBadRequest, "invalid revert value \"soon\", must be a positive duration\n"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			status, resp := makeRequest(t, ts.URL+c.url)
+			if status != c.expectedStatus {
+				t.Errorf("got status %d, expected %d", status, c.expectedStatus)
+			}
+			if resp != c.expectedResp {
+				t.Errorf("got body %q, expected %q", resp, c.expectedResp)
+			}
+		})
+	}
+	if !ctl.Debug() || !debugLogs {
+		t.Errorf("expected debug logs to be enabled")
+	}
+	if h := ctl.History(); len(h) != 3 || h[0].Who != "alice" {
+		t.Errorf("unexpected history %v", h)
+	}
+}
+
+func TestMountDebugLogEnablerRevert(t *testing.T) {
+	defer func() { debugLogs = false }()
+	ctl := log.NewControl()
+	mux := http.NewServeMux()
+	MountDebugLogEnabler(mux, WithLogControl(ctl))
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	_, resp := makeRequest(t, ts.URL+"/debug?debug-logs=on&module=db&level=debug&revert=20ms")
+	expected := `{"debug-logs":"on","modules":{"db":"debug"},"previous":{"debug-logs":"off","module:db":"default"},"revert-in":"20ms"}`
+	if resp != expected {
+		t.Errorf("got body %q, expected %q", resp, expected)
+	}
+	deadline := time.Now().Add(time.Second)
+	for ctl.Debug() && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	time.Sleep(5 * time.Millisecond)
+	if ctl.Debug() {
+		t.Errorf("expected debug logs to be reverted")
+	}
+	if _, ok := ctl.ModuleLevel("db"); ok {
+		t.Errorf("expected module level to be reverted")
+	}
+}
+
+func TestMountDebugLogEnablerRevertSuperseded(t *testing.T) {
+	defer func() { debugLogs = false }()
+	ctl := log.NewControl()
+	mux := http.NewServeMux()
+	MountDebugLogEnabler(mux, WithLogControl(ctl))
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	makeRequest(t, ts.URL+"/debug?debug-logs=on&module=db&level=debug&revert=20ms")
+	// A new change cancels the pending revert of the same setting.
+	makeRequest(t, ts.URL+"/debug?debug-logs=on")
+	// A change made outside of the endpoint prevents the revert.
+	ctl.SetModuleLevel("test", "db", log.SeverityError)
+	time.Sleep(100 * time.Millisecond)
+	if !ctl.Debug() {
+		t.Errorf("expected debug logs to stay on")
+	}
+	if sev, _ := ctl.ModuleLevel("db"); sev != log.SeverityError {
+		t.Errorf("got module level %v, expected %v", sev, log.SeverityError)
+	}
+}
+
+func TestMountGoroutineDump(t *testing.T) {
+	mux := http.NewServeMux()
+	MountGoroutineDump(mux)
+	MountGoroutineDump(mux, WithGoroutineDumpPath("test"))
+	cases := []struct {
+		name           string
+		url            string
+		expectedStatus int
+		expectedType   string
+		expectedPrefix string
+	}{
+		{"default", "/debug/goroutines", http.StatusOK, "text/plain; charset=utf-8", "goroutine "},
+		{"path", "/test", http.StatusOK, "text/plain; charset=utf-8", "goroutine "},
+		{"grouped", "/debug/goroutines?debug=1", http.StatusOK, "text/plain; charset=utf-8", "goroutine profile: total "},
+		{"pprof", "/debug/goroutines?format=pprof", http.StatusOK, "application/octet-stream", "\x1f\x8b"},
+		{"invalid format", "/debug/goroutines?format=json", http.StatusBadRequest, "text/plain; charset=utf-8", "invalid format value"},
+		{"invalid debug", "/debug/goroutines?debug=3", http.StatusBadRequest, "text/plain; charset=utf-8", "invalid debug value"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, httptest.NewRequest("GET", c.url, nil))
+			if w.Code != c.expectedStatus {
+				t.Errorf("got status %d, expected %d", w.Code, c.expectedStatus)
+			}
+			if ct := w.Header().Get("Content-Type"); ct != c.expectedType {
+				t.Errorf("got content type %q, expected %q", ct, c.expectedType)
+			}
+			if !strings.HasPrefix(w.Body.String(), c.expectedPrefix) {
+				t.Errorf("got body %.40q, expected prefix %q", w.Body.String(), c.expectedPrefix)
+			}
+		})
+	}
+}
+
+func TestMountPayloadLogToggle(t *testing.T) {
+	ctl := log.NewControl()
+	mux := http.NewServeMux()
+	MountPayloadLogToggle(mux, ctl)
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	cases := []struct {
+		name           string
+		url            string
+		expectedStatus int
+		expectedResp   string
+	}{
+		{"state", "/debug/payloads", http.StatusOK, `{"payloads":"on"}`},
+		{"disable", "/debug/payloads?payloads=off&who=alice", http.StatusOK, `{"payloads":"off","previous":"on"}`},
+		{"route", "/debug/payloads?payloads=on&route=front.forecast", http.StatusOK, `{"payloads":"off","previous":"off","routes":{"front.forecast":"on"}}`},
+		{"route default", "/debug/payloads?payloads=default&route=front.forecast", http.StatusOK, `{"payloads":"off","previous":"on"}`},
+		{"default without route", "/debug/payloads?payloads=default", http.StatusBadRequest, "payloads value \"default\" requires a route\n"},
+		{"invalid payloads", "/debug/payloads?payloads=maybe", http.StatusBadRequest, "invalid payloads value \"maybe\", must be on, off or default\n"},
+		{"invalid expire", "/debug/payloads?payloads=on&expire=-1s", http.StatusBadRequest, "invalid expire value \"-1s\", must be a positive duration\n"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			status, resp := makeRequest(t, ts.URL+c.url)
+			if status != c.expectedStatus {
+				t.Errorf("got status %d, expected %d", status, c.expectedStatus)
+			}
+			if resp != c.expectedResp {
+				t.Errorf("got body %q, expected %q", resp, c.expectedResp)
+			}
+		})
+	}
+	if h := ctl.History(); len(h) != 3 || h[0].Who != "alice" {
+		t.Errorf("unexpected history %v", h)
+	}
+}
+
+func TestMountPayloadLogToggleExpire(t *testing.T) {
+	ctl := log.NewControl()
+	mux := http.NewServeMux()
+	MountPayloadLogToggle(mux, ctl)
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	_, resp := makeRequest(t, ts.URL+"/debug/payloads?payloads=off&route=front.forecast&expire=20ms")
+	expected := `{"expires-in":"20ms","payloads":"on","previous":"on","routes":{"front.forecast":"off"}}`
+	if resp != expected {
+		t.Errorf("got body %q, expected %q", resp, expected)
+	}
+	deadline := time.Now().Add(time.Second)
+	for len(ctl.RoutesPayloads()) > 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if routes := ctl.RoutesPayloads(); len(routes) > 0 {
+		t.Errorf("expected route setting to expire, got %v", routes)
+	}
+}
+
+func TestMountPayloadLogToggleExpireSuperseded(t *testing.T) {
+	ctl := log.NewControl()
+	mux := http.NewServeMux()
+	MountPayloadLogToggle(mux, ctl)
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	makeRequest(t, ts.URL+"/debug/payloads?payloads=off&expire=20ms")
+	makeRequest(t, ts.URL+"/debug/payloads?payloads=off")
+	makeRequest(t, ts.URL+"/debug/payloads?payloads=on&route=front.forecast&expire=20ms")
+	ctl.SetRoutePayloads("test", "front.forecast", false)
+	time.Sleep(100 * time.Millisecond)
+	if ctl.Payloads() {
+		t.Errorf("expected payloads to stay off")
+	}
+	if on, ok := ctl.RoutesPayloads()["front.forecast"]; !ok || on {
+		t.Errorf("expected route setting to be kept, got %v", ctl.RoutesPayloads())
+	}
+}
@@ -0,0 +1,89 @@
+package debug
+
+import (
+	"fmt"
+	"net/http"
+	"runtime/trace"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// DefaultExecutionTraceDuration is the duration of the captures made by
+	// MountExecutionTrace when the request does not specify one.
+	DefaultExecutionTraceDuration = 5 * time.Second
+	// DefaultMaxExecutionTraceDuration is the default maximum duration of
+	// the captures made by MountExecutionTrace.
+	DefaultMaxExecutionTraceDuration = 30 * time.Second
+)
+
+// MountExecutionTrace mounts an endpoint under "/debug/trace/execution" that
+// streams a runtime execution trace (see runtime/trace) captured for the
+// duration given by the "seconds" query parameter (5 by default) for use with
+// "go tool trace". Execution traces show scheduling latencies, blocking and GC
+// events that are impossible to diagnose otherwise. Only one capture may run at
+// a time, concurrent requests get a 409 response. The capture stops early if
+// the client goes away.
+//
+// The path can be changed using WithExecutionTracePath and the maximum
+// duration of a capture (30 seconds by default) using
+// WithMaxExecutionTraceDuration. Note that the write timeout of the HTTP server
+// must be longer than the capture duration.
+//
+// Execution traces record the goroutines, function names and source locations
+// of the service and tracing slows it down noticeably while a capture runs, so
+// that anyone who can reach the endpoint can degrade the service, see Guard.
+func MountExecutionTrace(mux Muxer, opts ...ExecutionTraceOption) {
+	o := defaultExecutionTraceOptions()
+	for _, opt := range opts {
+		opt(o)
+	}
+	if !strings.HasPrefix(o.path, "/") {
+		o.path = "/" + o.path
+	}
+	var running atomic.Bool
+	register(mux, "execution trace", o.path, "Runtime execution trace capture", func() interface{} {
+		if running.Load() {
+			return "capturing"
+		}
+		return "idle"
+	})
+	mux.Handle(o.path, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		d := DefaultExecutionTraceDuration
+		if v := r.URL.Query().Get("seconds"); v != "" {
+			secs, err := strconv.ParseFloat(v, 64)
+			if err != nil || secs <= 0 {
+				http.Error(w, fmt.Sprintf("invalid seconds value %q, must be a positive number", v), http.StatusBadRequest)
+				return
+			}
+			d = time.Duration(secs * float64(time.Second))
+		}
+		if d > o.maxDuration {
+			http.Error(w, fmt.Sprintf("duration %s exceeds maximum of %s", d, o.maxDuration), http.StatusBadRequest)
+			return
+		}
+		if !running.CompareAndSwap(false, true) {
+			http.Error(w, "an execution trace capture is already in progress", http.StatusConflict)
+			return
+		}
+		defer running.Store(false)
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Header().Set("Content-Disposition", `attachment; filename="trace"`)
+		if err := trace.Start(w); err != nil {
+			// Tracing may have been started by other means, e.g.
+			// the pprof trace handler.
+			w.Header().Del("Content-Disposition")
+			http.Error(w, fmt.Sprintf("failed to start execution trace: %s", err), http.StatusConflict)
+			return
+		}
+		timer := time.NewTimer(d)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-r.Context().Done():
+		}
+		trace.Stop()
+	}))
+}
@@ -0,0 +1,61 @@
+package debug
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMountExecutionTrace(t *testing.T) {
+	mux := http.NewServeMux()
+	MountExecutionTrace(mux, WithMaxExecutionTraceDuration(time.Second))
+	MountExecutionTrace(mux, WithExecutionTracePath("test"))
+	cases := []struct {
+		name           string
+		url            string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{"capture", "/debug/trace/execution?seconds=0.05", http.StatusOK, "go 1."},
+		{"path", "/test?seconds=0.05", http.StatusOK, "go 1."},
+		{"invalid seconds", "/debug/trace/execution?seconds=abc", http.StatusBadRequest, `invalid seconds value "abc"`},
+		{"negative seconds", "/debug/trace/execution?seconds=-1", http.StatusBadRequest, `invalid seconds value "-1"`},
+		{"too long", "/debug/trace/execution?seconds=2", http.StatusBadRequest, "duration 2s exceeds maximum of 1s"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, httptest.NewRequest("GET", c.url, nil))
+			if w.Code != c.expectedStatus {
+				t.Errorf("got status %d, expected %d", w.Code, c.expectedStatus)
+			}
+			if !strings.Contains(w.Body.String(), c.expectedBody) {
+				t.Errorf("got body %.40q, expected it to contain %q", w.Body.String(), c.expectedBody)
+			}
+		})
+	}
+}
+
+func TestMountExecutionTraceConcurrent(t *testing.T) {
+	mux := http.NewServeMux()
+	MountExecutionTrace(mux)
+	var wg sync.WaitGroup
+	codes := make([]int, 2)
+	for i := range codes {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, httptest.NewRequest("GET", "/debug/trace/execution?seconds=0.2", nil))
+			codes[i] = w.Code
+		}(i)
+		time.Sleep(50 * time.Millisecond)
+	}
+	wg.Wait()
+	if codes[0] != http.StatusOK || codes[1] != http.StatusConflict {
+		t.Errorf("got status codes %v, expected [200 409]", codes)
+	}
+}
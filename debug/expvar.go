@@ -0,0 +1,63 @@
+package debug
+
+import (
+	"expvar"
+	"strings"
+	"sync"
+)
+
+var (
+	// varsLock protects vars.
+	varsLock sync.Mutex
+	// vars contains the functions registered with PublishVar indexed by
+	// name.
+	vars = make(map[string]func() interface{})
+)
+
+// MountExpvar mounts an endpoint under "/debug/vars" that serves the variables
+// published with the expvar package as JSON, for tools that already scrape
+// expvar. This includes the "cmdline" and "memstats" variables published by
+// the expvar package, a "buildinfo" variable with the build information of the
+// service (see ReadBuildInfo) and the variables registered with PublishVar.
+// The path can be changed using WithExpvarPath.
+//
+// The "cmdline" variable contains the command line of the process, which may
+// include secrets, and the build information lists the exact dependency
+// versions of the service, which helps attackers find known vulnerabilities,
+// see Guard.
+func MountExpvar(mux Muxer, opts ...ExpvarOption) {
+	o := defaultExpvarOptions()
+	for _, opt := range opts {
+		opt(o)
+	}
+	if !strings.HasPrefix(o.path, "/") {
+		o.path = "/" + o.path
+	}
+	PublishVar("buildinfo", func() interface{} { return ReadBuildInfo() })
+	register(mux, "expvar", o.path, "Published variables", nil)
+	mux.Handle(o.path, expvar.Handler())
+}
+
+// PublishVar publishes a variable whose value is computed by calling fn each
+// time the variables are served, e.g. a snapshot of the configuration or the
+// values of feature flags. The value must be JSON serializable. Unlike
+// expvar.Publish, PublishVar may be called multiple times with the same name:
+// the last function replaces the previous ones. PublishVar panics if name is
+// already used by a variable published with the expvar package directly.
+//
+// Example:
+//
+//	debug.PublishVar("flags", func() interface{} { return flags.Snapshot() })
+func PublishVar(name string, fn func() interface{}) {
+	varsLock.Lock()
+	defer varsLock.Unlock()
+	if _, ok := vars[name]; !ok {
+		expvar.Publish(name, expvar.Func(func() interface{} {
+			varsLock.Lock()
+			fn := vars[name]
+			varsLock.Unlock()
+			return fn()
+		}))
+	}
+	vars[name] = fn
+}
@@ -0,0 +1,36 @@
+package debug
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMountExpvar(t *testing.T) {
+	mux := http.NewServeMux()
+	MountExpvar(mux)
+	MountExpvar(mux, WithExpvarPath("test"))
+	PublishVar("testvar", func() interface{} { return "old" })
+	PublishVar("testvar", func() interface{} { return map[string]int{"answer": 42} })
+
+	for _, path := range []string{"/debug/vars", "/test"} {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: got status %d, expected %d", path, w.Code, http.StatusOK)
+		}
+		var vars map[string]json.RawMessage
+		if err := json.Unmarshal(w.Body.Bytes(), &vars); err != nil {
+			t.Fatal(err)
+		}
+		for _, name := range []string{"cmdline", "memstats", "buildinfo"} {
+			if _, ok := vars[name]; !ok {
+				t.Errorf("%s: missing %q variable", path, name)
+			}
+		}
+		if got := string(vars["testvar"]); got != `{"answer":42}` {
+			t.Errorf("%s: got testvar %s, expected %s", path, got, `{"answer":42}`)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package debug
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/felixge/fgprof"
+)
+
+const (
+	// DefaultWallClockProfileDuration is the duration of the profiles
+	// captured by MountWallClockProfile when the request does not specify
+	// one.
+	DefaultWallClockProfileDuration = 30 * time.Second
+	// DefaultMaxWallClockProfileDuration is the default maximum duration of
+	// the profiles captured by MountWallClockProfile.
+	DefaultMaxWallClockProfileDuration = time.Minute
+)
+
+// MountWallClockProfile mounts an endpoint under "/debug/fgprof" that returns
+// a wall-clock profile captured with fgprof
+// (https://github.com/felixge/fgprof) for the duration given by the "seconds"
+// query parameter (30 by default, capped to the maximum duration). Unlike the
+// CPU profile served by the pprof handlers, wall-clock profiles sample all the
+// goroutines whether they are running or blocked (on I/O, locks, channels
+// etc.) so that they show where requests actually spend their time. The
+// "format" query parameter selects the output format: "pprof" (default) for
+// use with "go tool pprof" or "folded" for use with flame graph tools. Only one
+// capture may run at a time, concurrent requests get a 409 response. The
+// capture stops early if the client goes away.
+//
+// Sampling all the goroutines is expensive for processes with many goroutines
+// so the overhead of the capture grows with the number of goroutines. The path
+// can be changed using WithWallClockProfilePath and the maximum duration of a
+// capture (1 minute by default) using WithMaxWallClockProfileDuration. Note
+// that the write timeout of the HTTP server must be longer than the capture
+// duration.
+//
+// The profile reveals the call stacks of all the goroutines and a capture slows
+// down the service for its whole duration, see Guard.
+func MountWallClockProfile(mux Muxer, opts ...WallClockProfileOption) {
+	o := defaultWallClockProfileOptions()
+	for _, opt := range opts {
+		opt(o)
+	}
+	if !strings.HasPrefix(o.path, "/") {
+		o.path = "/" + o.path
+	}
+	var running atomic.Bool
+	register(mux, "fgprof", o.path, "Wall-clock (on and off CPU) profile capture", func() interface{} {
+		if running.Load() {
+			return "capturing"
+		}
+		return "idle"
+	})
+	mux.Handle(o.path, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		d := DefaultWallClockProfileDuration
+		if d > o.maxDuration {
+			d = o.maxDuration
+		}
+		if v := q.Get("seconds"); v != "" {
+			secs, err := strconv.ParseFloat(v, 64)
+			if err != nil || secs <= 0 {
+				http.Error(w, fmt.Sprintf("invalid seconds value %q, must be a positive number", v), http.StatusBadRequest)
+				return
+			}
+			d = time.Duration(secs * float64(time.Second))
+		}
+		if d > o.maxDuration {
+			http.Error(w, fmt.Sprintf("duration %s exceeds maximum of %s", d, o.maxDuration), http.StatusBadRequest)
+			return
+		}
+		format := fgprof.FormatPprof
+		switch v := q.Get("format"); v {
+		case "", "pprof":
+		case "folded":
+			format = fgprof.FormatFolded
+		default:
+			http.Error(w, fmt.Sprintf("invalid format value %q, must be pprof or folded", v), http.StatusBadRequest)
+			return
+		}
+		if !running.CompareAndSwap(false, true) {
+			http.Error(w, "a wall-clock profile capture is already in progress", http.StatusConflict)
+			return
+		}
+		defer running.Store(false)
+		if format == fgprof.FormatPprof {
+			w.Header().Set("Content-Type", "application/octet-stream")
+			w.Header().Set("Content-Disposition", `attachment; filename="fgprof"`)
+		} else {
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		}
+		stop := fgprof.Start(w, format)
+		timer := time.NewTimer(d)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-r.Context().Done():
+		}
+		stop()
+	}))
+}
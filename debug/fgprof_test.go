@@ -0,0 +1,61 @@
+package debug
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMountWallClockProfile(t *testing.T) {
+	mux := http.NewServeMux()
+	MountWallClockProfile(mux, WithMaxWallClockProfileDuration(time.Second))
+	MountWallClockProfile(mux, WithWallClockProfilePath("test"))
+	cases := []struct {
+		name           string
+		url            string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{"pprof", "/debug/fgprof?seconds=0.05", http.StatusOK, "\x1f\x8b"},
+		{"folded", "/test?seconds=0.05&format=folded", http.StatusOK, "testing.tRunner"},
+		{"invalid seconds", "/debug/fgprof?seconds=abc", http.StatusBadRequest, `invalid seconds value "abc"`},
+		{"invalid format", "/debug/fgprof?format=svg", http.StatusBadRequest, `invalid format value "svg"`},
+		{"too long", "/debug/fgprof?seconds=2", http.StatusBadRequest, "duration 2s exceeds maximum of 1s"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, httptest.NewRequest("GET", c.url, nil))
+			if w.Code != c.expectedStatus {
+				t.Errorf("got status %d, expected %d", w.Code, c.expectedStatus)
+			}
+			if !strings.Contains(w.Body.String(), c.expectedBody) {
+				t.Errorf("got body %.40q, expected it to contain %q", w.Body.String(), c.expectedBody)
+			}
+		})
+	}
+}
+
+func TestMountWallClockProfileConcurrent(t *testing.T) {
+	mux := http.NewServeMux()
+	MountWallClockProfile(mux)
+	var wg sync.WaitGroup
+	codes := make([]int, 2)
+	for i := range codes {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, httptest.NewRequest("GET", "/debug/fgprof?seconds=0.2", nil))
+			codes[i] = w.Code
+		}(i)
+		time.Sleep(50 * time.Millisecond)
+	}
+	wg.Wait()
+	if codes[0] != http.StatusOK || codes[1] != http.StatusConflict {
+		t.Errorf("got status codes %v, expected [200 409]", codes)
+	}
+}
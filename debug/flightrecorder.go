@@ -0,0 +1,303 @@
+package debug
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"runtime"
+	rdebug "runtime/debug"
+	"runtime/metrics"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type (
+	// FlightRecorder continuously samples runtime statistics (not execution
+	// traces, see MountExecutionTrace) into a ring buffer so that the state
+	// of the process in the moments leading to an incident can be inspected
+	// after the fact. Create flight recorders with NewFlightRecorder.
+	FlightRecorder struct {
+		interval  time.Duration
+		window    time.Duration
+		lock      sync.Mutex
+		samples   []*FlightSample
+		next      int // index of the next sample in samples
+		full      bool
+		events    []*FlightEvent
+		cancel    context.CancelFunc
+		done      chan struct{}
+		metrics   []metrics.Sample
+		gcStats   rdebug.GCStats
+		lastGC    int64
+		lastPause time.Duration
+		lastCgo   int64
+	}
+
+	// FlightSample is a sample of runtime statistics recorded by a
+	// FlightRecorder.
+	FlightSample struct {
+		// Time is the time the sample was taken.
+		Time time.Time `json:"time"`
+		// Goroutines is the number of goroutines.
+		Goroutines int `json:"goroutines"`
+		// HeapAlloc is the number of bytes of allocated heap objects.
+		HeapAlloc uint64 `json:"heap_alloc"`
+		// HeapObjects is the number of allocated heap objects.
+		HeapObjects uint64 `json:"heap_objects"`
+		// Sys is the total number of bytes obtained from the OS.
+		Sys uint64 `json:"sys"`
+		// GCs is the number of GC cycles completed since the previous
+		// sample.
+		GCs uint32 `json:"gcs"`
+		// GCPauseMS is the GC pause duration since the previous sample
+		// in milliseconds.
+		GCPauseMS float64 `json:"gc_pause_ms"`
+		// CgoCalls is the number of cgo calls made since the previous
+		// sample.
+		CgoCalls int64 `json:"cgo_calls"`
+	}
+
+	// FlightEvent is an event recorded with FlightRecorder.Record, for
+	// example a configuration reload or a circuit breaker opening.
+	FlightEvent struct {
+		// Time is the time the event was recorded.
+		Time time.Time `json:"time"`
+		// Message describes the event.
+		Message string `json:"message"`
+	}
+
+	// flightDump is the response of the flight recorder endpoint.
+	flightDump struct {
+		Interval string          `json:"interval"`
+		Samples  []*FlightSample `json:"samples"`
+		Events   []*FlightEvent  `json:"events"`
+	}
+)
+
+const (
+	// DefaultFlightRecorderInterval is the default interval between two
+	// samples of a FlightRecorder. Taking a sample reads a few runtime
+	// metrics without stopping the world so that sampling every second has
+	// a negligible overhead.
+	DefaultFlightRecorderInterval = time.Second
+	// DefaultFlightRecorderWindow is the default duration covered by the
+	// samples retained by a FlightRecorder.
+	DefaultFlightRecorderWindow = 5 * time.Minute
+)
+
+// NewFlightRecorder returns a flight recorder that samples the runtime
+// statistics every DefaultFlightRecorderInterval and retains the samples and
+// events of the last DefaultFlightRecorderWindow once started. Use
+// WithFlightRecorderInterval and WithFlightRecorderWindow to change the
+// defaults.
+//
+// The recorder is a statistics sampler: each sample holds the number of
+// goroutines, the heap size and number of objects, the memory obtained from
+// the OS and the GC cycles, GC pauses and cgo calls since the previous sample.
+// The statistics are read with the runtime/metrics package and
+// runtime/debug.ReadGCStats which, unlike runtime.ReadMemStats, do not stop
+// the world, so sampling does not add latency to the requests being served.
+// The memory used by the recorder is proportional to the window divided by the
+// interval.
+//
+// Example:
+//
+//	fr := debug.NewFlightRecorder()
+//	fr.Start(ctx)
+//	defer fr.Stop()
+//	debug.MountFlightRecorder(mux, fr)
+func NewFlightRecorder(opts ...FlightRecorderOption) *FlightRecorder {
+	o := defaultFlightRecorderOptions()
+	for _, opt := range opts {
+		opt(o)
+	}
+	size := int(o.window / o.interval)
+	if size < 1 {
+		size = 1
+	}
+	return &FlightRecorder{
+		interval: o.interval,
+		window:   o.window,
+		samples:  make([]*FlightSample, size),
+		metrics: []metrics.Sample{
+			{Name: "/memory/classes/heap/objects:bytes"},
+			{Name: "/gc/heap/objects:objects"},
+			{Name: "/memory/classes/total:bytes"},
+		},
+	}
+}
+
+// Start starts sampling in the background until Stop is called or ctx is
+// done. Start does nothing if the recorder is already running.
+func (fr *FlightRecorder) Start(ctx context.Context) {
+	fr.lock.Lock()
+	defer fr.lock.Unlock()
+	if fr.cancel != nil {
+		return
+	}
+	ctx, fr.cancel = context.WithCancel(ctx)
+	fr.done = make(chan struct{})
+	fr.sample()
+	go fr.run(ctx, fr.done)
+}
+
+// Stop stops sampling. The recorded samples and events are kept.
+func (fr *FlightRecorder) Stop() {
+	fr.lock.Lock()
+	cancel, done := fr.cancel, fr.done
+	fr.cancel, fr.done = nil, nil
+	fr.lock.Unlock()
+	if cancel == nil {
+		return
+	}
+	cancel()
+	<-done
+}
+
+// Record records an event with the given message, the events recorded during
+// the last window are returned with the samples.
+func (fr *FlightRecorder) Record(msg string) {
+	fr.lock.Lock()
+	defer fr.lock.Unlock()
+	now := time.Now()
+	fr.events = append(fr.events, &FlightEvent{Time: now, Message: msg})
+	fr.prune(now)
+}
+
+// Samples returns the samples and events recorded during the last d, oldest
+// first. All the retained samples and events are returned if d is 0.
+func (fr *FlightRecorder) Samples(d time.Duration) ([]*FlightSample, []*FlightEvent) {
+	fr.lock.Lock()
+	defer fr.lock.Unlock()
+	var since time.Time
+	if d > 0 {
+		since = time.Now().Add(-d)
+	}
+	samples := make([]*FlightSample, 0, len(fr.samples))
+	start := 0
+	if fr.full {
+		start = fr.next
+	}
+	for i := 0; i < len(fr.samples); i++ {
+		s := fr.samples[(start+i)%len(fr.samples)]
+		if s == nil || s.Time.Before(since) {
+			continue
+		}
+		samples = append(samples, s)
+	}
+	fr.prune(time.Now())
+	events := make([]*FlightEvent, 0, len(fr.events))
+	for _, e := range fr.events {
+		if !e.Time.Before(since) {
+			events = append(events, e)
+		}
+	}
+	return samples, events
+}
+
+// MountFlightRecorder mounts an endpoint under "/debug/flightrecorder" that
+// returns the JSON encoded samples and events recorded by fr. The "seconds"
+// query parameter limits the response to the last given number of seconds,
+// all the retained samples are returned by default. The path can be changed
+// using WithFlightRecorderPath.
+//
+// The samples reveal the load and memory usage of the process and the events
+// contain whatever messages the service passes to Record, e.g. configuration
+// changes or dependency failures, see Guard.
+func MountFlightRecorder(mux Muxer, fr *FlightRecorder, opts ...FlightRecorderHandlerOption) {
+	o := defaultFlightRecorderHandlerOptions()
+	for _, opt := range opts {
+		opt(o)
+	}
+	if !strings.HasPrefix(o.path, "/") {
+		o.path = "/" + o.path
+	}
+	register(mux, "flight recorder", o.path, "Recent runtime statistics and events", func() interface{} {
+		fr.lock.Lock()
+		defer fr.lock.Unlock()
+		if fr.cancel != nil {
+			return "recording"
+		}
+		return "stopped"
+	})
+	mux.Handle(o.path, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var d time.Duration
+		if v := r.URL.Query().Get("seconds"); v != "" {
+			secs, err := strconv.ParseFloat(v, 64)
+			if err != nil || secs <= 0 {
+				http.Error(w, fmt.Sprintf("invalid seconds value %q, must be a positive number", v), http.StatusBadRequest)
+				return
+			}
+			d = time.Duration(secs * float64(time.Second))
+		}
+		samples, events := fr.Samples(d)
+		js, _ := json.Marshal(&flightDump{Interval: fr.interval.String(), Samples: samples, Events: events})
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(js)
+	}))
+}
+
+// run samples the runtime statistics until ctx is done.
+func (fr *FlightRecorder) run(ctx context.Context, done chan struct{}) {
+	defer close(done)
+	ticker := time.NewTicker(fr.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			fr.lock.Lock()
+			fr.sample()
+			fr.lock.Unlock()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// sample records a sample in the ring buffer. fr.lock must be held.
+func (fr *FlightRecorder) sample() {
+	metrics.Read(fr.metrics)
+	rdebug.ReadGCStats(&fr.gcStats)
+	cgo := runtime.NumCgoCall()
+	s := &FlightSample{
+		Time:        time.Now(),
+		Goroutines:  runtime.NumGoroutine(),
+		HeapAlloc:   metricValue(fr.metrics[0]),
+		HeapObjects: metricValue(fr.metrics[1]),
+		Sys:         metricValue(fr.metrics[2]),
+	}
+	if fr.next > 0 || fr.full {
+		s.GCs = uint32(fr.gcStats.NumGC - fr.lastGC)
+		s.GCPauseMS = toMS(uint64(fr.gcStats.PauseTotal - fr.lastPause))
+		s.CgoCalls = cgo - fr.lastCgo
+	}
+	fr.lastGC, fr.lastPause, fr.lastCgo = fr.gcStats.NumGC, fr.gcStats.PauseTotal, cgo
+	fr.samples[fr.next] = s
+	fr.next = (fr.next + 1) % len(fr.samples)
+	if fr.next == 0 {
+		fr.full = true
+	}
+	fr.prune(s.Time)
+}
+
+// metricValue returns the value of s, 0 if the metric is not supported by the
+// Go runtime.
+func metricValue(s metrics.Sample) uint64 {
+	if s.Value.Kind() != metrics.KindUint64 {
+		return 0
+	}
+	return s.Value.Uint64()
+}
+
+// prune removes the events older than the window. fr.lock must be held.
+func (fr *FlightRecorder) prune(now time.Time) {
+	cutoff := now.Add(-fr.window)
+	i := 0
+	for i < len(fr.events) && fr.events[i].Time.Before(cutoff) {
+		i++
+	}
+	fr.events = fr.events[i:]
+}
@@ -0,0 +1,93 @@
+package debug
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestFlightRecorder(t *testing.T) {
+	fr := NewFlightRecorder(WithFlightRecorderInterval(time.Millisecond), WithFlightRecorderWindow(5*time.Millisecond))
+	fr.Start(context.Background())
+	fr.Start(context.Background())
+	time.Sleep(20 * time.Millisecond)
+	runtime.GC()
+	time.Sleep(5 * time.Millisecond)
+	fr.Record("event")
+	fr.Stop()
+	fr.Stop()
+
+	samples, events := fr.Samples(0)
+	if len(samples) != 5 {
+		t.Fatalf("got %d samples, expected ring buffer size 5", len(samples))
+	}
+	for i := 1; i < len(samples); i++ {
+		if samples[i].Time.Before(samples[i-1].Time) {
+			t.Errorf("samples not sorted: %v before %v", samples[i].Time, samples[i-1].Time)
+		}
+	}
+	if samples[0].Goroutines == 0 || samples[0].HeapAlloc == 0 || samples[0].HeapObjects == 0 || samples[0].Sys == 0 {
+		t.Errorf("unexpected sample %+v", samples[0])
+	}
+	if len(events) != 1 || events[0].Message != "event" {
+		t.Errorf("got events %+v, expected one event", events)
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	fr.Record("other")
+	_, events = fr.Samples(0)
+	if len(events) != 1 || events[0].Message != "other" {
+		t.Errorf("got events %+v, expected old event to be pruned", events)
+	}
+}
+
+func TestMountFlightRecorder(t *testing.T) {
+	fr := NewFlightRecorder(WithFlightRecorderInterval(time.Hour))
+	fr.Start(context.Background())
+	defer fr.Stop()
+	fr.Record("deploy")
+	mux := http.NewServeMux()
+	MountFlightRecorder(mux, fr)
+	MountFlightRecorder(mux, fr, WithFlightRecorderPath("test"))
+
+	cases := []struct {
+		name          string
+		path          string
+		expectedCode  int
+		expectedCount int
+	}{
+		{"all", "/debug/flightrecorder", http.StatusOK, 1},
+		{"custom path", "/test?seconds=60", http.StatusOK, 1},
+		{"too old", "/test?seconds=0.000001", http.StatusOK, 0},
+		{"invalid seconds", "/test?seconds=foo", http.StatusBadRequest, 0},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if c.name == "too old" {
+				time.Sleep(time.Millisecond)
+			}
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, httptest.NewRequest("GET", c.path, nil))
+			if w.Code != c.expectedCode {
+				t.Fatalf("got status %d, expected %d", w.Code, c.expectedCode)
+			}
+			if c.expectedCode != http.StatusOK {
+				return
+			}
+			var dump flightDump
+			if err := json.Unmarshal(w.Body.Bytes(), &dump); err != nil {
+				t.Fatal(err)
+			}
+			if dump.Interval != "1h0m0s" {
+				t.Errorf("got interval %q, expected 1h0m0s", dump.Interval)
+			}
+			if len(dump.Samples) != c.expectedCount || len(dump.Events) != c.expectedCount {
+				t.Errorf("got %d samples and %d events, expected %d", len(dump.Samples), len(dump.Events), c.expectedCount)
+			}
+		})
+	}
+}
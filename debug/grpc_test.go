@@ -9,8 +9,8 @@ import (
 
 	"google.golang.org/grpc"
 
-	"goa.design/clue/internal/testsvc"
 	"goa.design/clue/log"
+	"goa.design/clue/testing/testsvc"
 )
 
 func TestUnaryServerInterceptor(t *testing.T) {
@@ -0,0 +1,182 @@
+package debug
+
+import (
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+type (
+	// Capability describes a debug endpoint listed by the index mounted by
+	// MountIndex.
+	Capability struct {
+		// Name is the name of the capability, e.g. "pprof".
+		Name string `json:"name"`
+		// Path is the URL path of the endpoint.
+		Path string `json:"path"`
+		// Description describes the capability.
+		Description string `json:"description"`
+		// State is the current state of the capability if any, e.g.
+		// "on" for debug logs.
+		State interface{} `json:"state,omitempty"`
+	}
+
+	// capability is a capability registered by a Mount function.
+	capability struct {
+		name        string
+		path        string
+		description string
+		state       func() interface{}
+	}
+
+	// indexMux is a Muxer that records the capabilities mounted through
+	// it so that they can be listed by the index.
+	indexMux struct {
+		Muxer
+		lock sync.Mutex
+		caps []*capability
+	}
+
+	// unwrapper is implemented by muxers that wrap another muxer.
+	unwrapper interface {
+		Unwrap() Muxer
+	}
+)
+
+// indexTemplate renders the HTML index.
+var indexTemplate = template.Must(template.New("index").Parse(`<!DOCTYPE html>
+<html>
+<head><title>Debug</title></head>
+<body>
+<h1>Debug</h1>
+<table>
+<tr><th>Name</th><th>Description</th><th>State</th></tr>
+{{- range .}}
+<tr><td><a href="{{.Path}}">{{.Name}}</a></td><td>{{.Description}}</td><td>{{.State}}</td></tr>
+{{- end}}
+</table>
+</body>
+</html>
+`))
+
+// MountIndex mounts an endpoint under "/debug/" that lists the debug
+// capabilities mounted by the Mount functions of this package on the returned
+// muxer, directly or via Guard, with links to the endpoints and their current
+// state (e.g. whether debug logs are enabled) so that operators can discover
+// what is available on a given service. The endpoint returns a HTML page if
+// the request accepts "text/html" (e.g. browsers) and the JSON encoded list of
+// capabilities (see Capability) otherwise. The path can be changed using
+// WithIndexPath.
+//
+// The returned muxer mounts handlers on mux and owns the list of capabilities,
+// capabilities mounted directly on mux are not listed. Calling MountIndex with
+// the returned muxer mounts another endpoint that lists the same capabilities:
+//
+//	index := debug.MountIndex(mux)
+//	debug.MountDebugLogEnabler(index)
+//	debug.MountPprofHandlers(debug.Guard(index, debug.WithBearerTokens(token)))
+func MountIndex(mux Muxer, opts ...IndexOption) Muxer {
+	o := defaultIndexOptions()
+	for _, opt := range opts {
+		opt(o)
+	}
+	if !strings.HasPrefix(o.path, "/") {
+		o.path = "/" + o.path
+	}
+	idx := indexOf(mux)
+	if idx == nil {
+		idx = &indexMux{Muxer: mux}
+	}
+	mux.Handle(o.path, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != o.path {
+			http.NotFound(w, r)
+			return
+		}
+		caps := idx.capabilities()
+		if strings.Contains(r.Header.Get("Accept"), "text/html") {
+			for _, c := range caps {
+				if c.State != nil {
+					js, _ := json.Marshal(c.State)
+					c.State = strings.Trim(string(js), `"`)
+				}
+			}
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			indexTemplate.Execute(w, caps)
+			return
+		}
+		js, _ := json.Marshal(caps)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(js)
+	}))
+	return idx
+}
+
+// Capabilities returns the debug capabilities mounted on mux by the Mount
+// functions of this package in the order they were mounted. mux must be the
+// muxer returned by MountIndex or a muxer that wraps it such as the one
+// returned by Guard, Capabilities returns nil otherwise.
+func Capabilities(mux Muxer) []*Capability {
+	idx := indexOf(mux)
+	if idx == nil {
+		return nil
+	}
+	return idx.capabilities()
+}
+
+// Unwrap returns the muxer wrapped by the index.
+func (m *indexMux) Unwrap() Muxer {
+	return m.Muxer
+}
+
+// capabilities returns the capabilities recorded by the index.
+func (m *indexMux) capabilities() []*Capability {
+	m.lock.Lock()
+	caps := append([]*capability(nil), m.caps...)
+	m.lock.Unlock()
+	res := make([]*Capability, len(caps))
+	for i, c := range caps {
+		res[i] = &Capability{Name: c.name, Path: c.path, Description: c.description}
+		if c.state != nil {
+			res[i].State = c.state()
+		}
+	}
+	return res
+}
+
+// register records a capability mounted on mux if mux was returned by
+// MountIndex or wraps such a muxer, state may be nil. Mounting a capability
+// again under the same path replaces it.
+func register(mux Muxer, name, path, description string, state func() interface{}) {
+	idx := indexOf(mux)
+	if idx == nil {
+		return
+	}
+	idx.lock.Lock()
+	defer idx.lock.Unlock()
+	c := &capability{name: name, path: path, description: description, state: state}
+	for i, existing := range idx.caps {
+		if existing.path == path {
+			idx.caps[i] = c
+			return
+		}
+	}
+	idx.caps = append(idx.caps, c)
+}
+
+// indexOf returns the index muxer returned by MountIndex that mux is or wraps,
+// nil if there is none.
+func indexOf(mux Muxer) *indexMux {
+	for mux != nil {
+		if idx, ok := mux.(*indexMux); ok {
+			return idx
+		}
+		u, ok := mux.(unwrapper)
+		if !ok {
+			return nil
+		}
+		mux = u.Unwrap()
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package debug
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"goa.design/clue/health"
+)
+
+func TestMountIndex(t *testing.T) {
+	mux := http.NewServeMux()
+	index := MountIndex(mux)
+	if MountIndex(index, WithIndexPath("index")) != index {
+		t.Errorf("expected mounting the index again to return the same index")
+	}
+	MountPprofHandlers(Guard(index, WithBearerTokens("token")))
+	MountGoroutineDump(index)
+	drainer := health.NewChecker()
+	MountReadinessToggle(index, drainer)
+	MountMemoryStats(mux)
+	MountMemoryStats(MountIndex(http.NewServeMux()))
+
+	get := func(path, accept string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", path, nil)
+		req.Header.Set("Accept", accept)
+		mux.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("json", func(t *testing.T) {
+		for _, path := range []string{"/debug/", "/index"} {
+			w := get(path, "*/*")
+			if w.Code != http.StatusOK {
+				t.Fatalf("%s: got status %d, expected %d", path, w.Code, http.StatusOK)
+			}
+			if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+				t.Errorf("%s: got content type %q, expected application/json", path, ct)
+			}
+			var caps []*Capability
+			if err := json.Unmarshal(w.Body.Bytes(), &caps); err != nil {
+				t.Fatal(err)
+			}
+			var names []string
+			for _, c := range caps {
+				names = append(names, c.Name)
+			}
+			if strings.Join(names, ",") != "pprof,goroutines,readiness" {
+				t.Fatalf("%s: got capabilities %v, expected pprof, goroutines and readiness", path, names)
+			}
+			if caps[0].Path != "/debug/pprof/" || caps[0].State != nil {
+				t.Errorf("%s: unexpected pprof capability %+v", path, caps[0])
+			}
+			if caps[2].Path != "/debug/ready" || caps[2].State != "on" {
+				t.Errorf("%s: unexpected readiness capability %+v", path, caps[2])
+			}
+		}
+	})
+
+	t.Run("state", func(t *testing.T) {
+		drainer.SetReady(false)
+		defer drainer.SetReady(true)
+		caps := Capabilities(index)
+		if caps[2].State != "off" {
+			t.Errorf("got readiness state %v, expected off", caps[2].State)
+		}
+	})
+
+	t.Run("not indexed", func(t *testing.T) {
+		if caps := Capabilities(mux); caps != nil {
+			t.Errorf("got capabilities %+v for a muxer without index, expected none", caps)
+		}
+	})
+
+	t.Run("remount", func(t *testing.T) {
+		other := MountIndex(http.NewServeMux())
+		register(other, "test", "/test", "first", nil)
+		register(other, "test", "/test", "second", nil)
+		caps := Capabilities(other)
+		if len(caps) != 1 || caps[0].Description != "second" {
+			t.Errorf("got capabilities %+v, expected the second one only", caps)
+		}
+	})
+
+	t.Run("html", func(t *testing.T) {
+		w := get("/debug/", "text/html,application/xhtml+xml")
+		if ct := w.Header().Get("Content-Type"); ct != "text/html; charset=utf-8" {
+			t.Errorf("got content type %q, expected text/html", ct)
+		}
+		body := w.Body.String()
+		for _, s := range []string{`<a href="/debug/pprof/">pprof</a>`, `<a href="/debug/ready">readiness</a>`, "<td>on</td>"} {
+			if !strings.Contains(body, s) {
+				t.Errorf("expected body to contain %q, got %s", s, body)
+			}
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		if w := get("/debug/unknown", "*/*"); w.Code != http.StatusNotFound {
+			t.Errorf("got status %d, expected %d", w.Code, http.StatusNotFound)
+		}
+	})
+}
@@ -0,0 +1,179 @@
+package debug
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"runtime"
+	"runtime/pprof"
+	"sort"
+	"strings"
+	"time"
+)
+
+type (
+	// MemoryStats is the summary of the memory usage of the process
+	// returned by the endpoint mounted by MountMemoryStats.
+	MemoryStats struct {
+		// HeapAlloc is the number of bytes of allocated heap objects.
+		HeapAlloc uint64 `json:"heap_alloc"`
+		// HeapInuse is the number of bytes in in-use heap spans.
+		HeapInuse uint64 `json:"heap_inuse"`
+		// HeapIdle is the number of bytes in idle heap spans.
+		HeapIdle uint64 `json:"heap_idle"`
+		// HeapReleased is the number of bytes returned to the OS.
+		HeapReleased uint64 `json:"heap_released"`
+		// HeapObjects is the number of allocated heap objects.
+		HeapObjects uint64 `json:"heap_objects"`
+		// StackInuse is the number of bytes in stack spans.
+		StackInuse uint64 `json:"stack_inuse"`
+		// Sys is the total number of bytes obtained from the OS.
+		Sys uint64 `json:"sys"`
+		// TotalAlloc is the cumulative number of bytes allocated for
+		// heap objects.
+		TotalAlloc uint64 `json:"total_alloc"`
+		// Mallocs is the cumulative count of heap objects allocated.
+		Mallocs uint64 `json:"mallocs"`
+		// Frees is the cumulative count of heap objects freed.
+		Frees uint64 `json:"frees"`
+		// Goroutines is the number of goroutines.
+		Goroutines int `json:"goroutines"`
+		// GC summarizes the garbage collections.
+		GC GCStats `json:"gc"`
+	}
+
+	// GCStats summarizes the garbage collections of the process.
+	GCStats struct {
+		// NumGC is the number of completed GC cycles.
+		NumGC uint32 `json:"num_gc"`
+		// NumForcedGC is the number of GC cycles forced by the
+		// application.
+		NumForcedGC uint32 `json:"num_forced_gc"`
+		// NextGC is the target heap size of the next GC cycle.
+		NextGC uint64 `json:"next_gc"`
+		// LastGC is the time the last GC cycle finished, nil if there
+		// wasn't any.
+		LastGC *time.Time `json:"last_gc,omitempty"`
+		// CPUFraction is the fraction of the available CPU time used
+		// by the GC since the program started.
+		CPUFraction float64 `json:"cpu_fraction"`
+		// PauseTotalMS is the cumulative GC pause duration in
+		// milliseconds.
+		PauseTotalMS float64 `json:"pause_total_ms"`
+		// PauseP50MS is the median of the recent GC pauses in
+		// milliseconds.
+		PauseP50MS float64 `json:"pause_p50_ms"`
+		// PauseP99MS is the 99th percentile of the recent GC pauses in
+		// milliseconds.
+		PauseP99MS float64 `json:"pause_p99_ms"`
+		// PauseMaxMS is the longest of the recent GC pauses in
+		// milliseconds.
+		PauseMaxMS float64 `json:"pause_max_ms"`
+	}
+)
+
+// MountMemoryStats mounts two endpoints that help investigate memory leaks:
+//
+//   - "/debug/memory" returns the JSON encoded memory statistics of the
+//     process (see MemoryStats) including a summary of the recent GC pauses.
+//   - "/debug/memory/heap" returns the heap profile in the pprof format for
+//     use with "go tool pprof".
+//
+// Both endpoints run a garbage collection first if the "gc" query parameter is
+// set to "true" or "1" so that the results only reflect live objects. The path
+// can be changed using WithMemoryStatsPath.
+//
+// The heap profile lists the allocation sites of the service and so reveals its
+// internals, and the "gc" query parameter lets any caller force garbage
+// collections that pause the service, see Guard.
+func MountMemoryStats(mux Muxer, opts ...MemoryStatsOption) {
+	o := defaultMemoryStatsOptions()
+	for _, opt := range opts {
+		opt(o)
+	}
+	if !strings.HasPrefix(o.path, "/") {
+		o.path = "/" + o.path
+	}
+	o.path = strings.TrimSuffix(o.path, "/")
+	register(mux, "memory", o.path, "Memory statistics", nil)
+	register(mux, "heap", o.path+"/heap", "Heap profile", nil)
+	mux.Handle(o.path, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !maybeGC(w, r) {
+			return
+		}
+		js, _ := json.Marshal(readMemoryStats())
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(js)
+	}))
+	mux.Handle(o.path+"/heap", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !maybeGC(w, r) {
+			return
+		}
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Header().Set("Content-Disposition", `attachment; filename="heap"`)
+		pprof.Lookup("heap").WriteTo(w, 0)
+	}))
+}
+
+// maybeGC runs a garbage collection if the request asks for it. It returns
+// false and writes an error response if the "gc" query parameter is invalid.
+func maybeGC(w http.ResponseWriter, r *http.Request) bool {
+	switch v := r.URL.Query().Get("gc"); v {
+	case "", "false", "0":
+	case "true", "1":
+		runtime.GC()
+	default:
+		http.Error(w, fmt.Sprintf("invalid gc value %q, must be true or false", v), http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+// readMemoryStats returns the current memory statistics.
+func readMemoryStats() *MemoryStats {
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	res := &MemoryStats{
+		HeapAlloc:    ms.HeapAlloc,
+		HeapInuse:    ms.HeapInuse,
+		HeapIdle:     ms.HeapIdle,
+		HeapReleased: ms.HeapReleased,
+		HeapObjects:  ms.HeapObjects,
+		StackInuse:   ms.StackInuse,
+		Sys:          ms.Sys,
+		TotalAlloc:   ms.TotalAlloc,
+		Mallocs:      ms.Mallocs,
+		Frees:        ms.Frees,
+		Goroutines:   runtime.NumGoroutine(),
+		GC: GCStats{
+			NumGC:        ms.NumGC,
+			NumForcedGC:  ms.NumForcedGC,
+			NextGC:       ms.NextGC,
+			CPUFraction:  ms.GCCPUFraction,
+			PauseTotalMS: toMS(ms.PauseTotalNs),
+		},
+	}
+	if ms.LastGC > 0 {
+		last := time.Unix(0, int64(ms.LastGC)).UTC()
+		res.GC.LastGC = &last
+	}
+	// PauseNs is a circular buffer of the most recent pauses.
+	n := int(ms.NumGC)
+	if n > len(ms.PauseNs) {
+		n = len(ms.PauseNs)
+	}
+	if n > 0 {
+		pauses := make([]uint64, n)
+		copy(pauses, ms.PauseNs[:n])
+		sort.Slice(pauses, func(i, j int) bool { return pauses[i] < pauses[j] })
+		res.GC.PauseP50MS = toMS(pauses[(n-1)/2])
+		res.GC.PauseP99MS = toMS(pauses[(n-1)*99/100])
+		res.GC.PauseMaxMS = toMS(pauses[n-1])
+	}
+	return res
+}
+
+// toMS converts a duration in nanoseconds to milliseconds.
+func toMS(ns uint64) float64 {
+	return float64(ns) / float64(time.Millisecond)
+}
@@ -0,0 +1,69 @@
+package debug
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMountMemoryStats(t *testing.T) {
+	mux := http.NewServeMux()
+	MountMemoryStats(mux)
+	MountMemoryStats(mux, WithMemoryStatsPath("test/"))
+
+	t.Run("stats", func(t *testing.T) {
+		for _, path := range []string{"/debug/memory?gc=true", "/test"} {
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+			if w.Code != http.StatusOK {
+				t.Fatalf("%s: got status %d, expected %d", path, w.Code, http.StatusOK)
+			}
+			if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+				t.Errorf("%s: got content type %q, expected application/json", path, ct)
+			}
+			var stats MemoryStats
+			if err := json.Unmarshal(w.Body.Bytes(), &stats); err != nil {
+				t.Fatal(err)
+			}
+			if stats.HeapAlloc == 0 || stats.Sys == 0 || stats.Goroutines == 0 {
+				t.Errorf("%s: unexpected stats %+v", path, stats)
+			}
+		}
+	})
+
+	t.Run("gc", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest("GET", "/debug/memory?gc=1", nil))
+		var stats MemoryStats
+		if err := json.Unmarshal(w.Body.Bytes(), &stats); err != nil {
+			t.Fatal(err)
+		}
+		if stats.GC.NumForcedGC == 0 || stats.GC.LastGC == nil {
+			t.Errorf("expected a forced GC, got %+v", stats.GC)
+		}
+		if stats.GC.PauseMaxMS < stats.GC.PauseP99MS || stats.GC.PauseP99MS < stats.GC.PauseP50MS {
+			t.Errorf("inconsistent pause summary %+v", stats.GC)
+		}
+	})
+
+	t.Run("heap", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest("GET", "/test/heap?gc=true", nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("got status %d, expected %d", w.Code, http.StatusOK)
+		}
+		if !strings.HasPrefix(w.Body.String(), "\x1f\x8b") {
+			t.Errorf("expected gzipped pprof profile")
+		}
+	})
+
+	t.Run("invalid gc", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest("GET", "/debug/memory/heap?gc=maybe", nil))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("got status %d, expected %d", w.Code, http.StatusBadRequest)
+		}
+	})
+}
@@ -4,6 +4,12 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"goa.design/clue/log"
+	cluetrace "goa.design/clue/trace"
 )
 
 type (
@@ -15,10 +21,98 @@ type (
 	// to MountDebugLogEnabler.
 	DebugLogEnablerOption func(*dleOptions)
 
+	// TraceControlOption is a function that applies a configuration option
+	// to MountTraceControl.
+	TraceControlOption func(*tcOptions)
+
+	// ReadinessToggleOption is a function that applies a configuration
+	// option to MountReadinessToggle.
+	ReadinessToggleOption func(*rtOptions)
+
+	// GoroutineDumpOption is a function that applies a configuration
+	// option to MountGoroutineDump.
+	GoroutineDumpOption func(*gdOptions)
+
+	// MemoryStatsOption is a function that applies a configuration option
+	// to MountMemoryStats.
+	MemoryStatsOption func(*msOptions)
+
+	// ExecutionTraceOption is a function that applies a configuration
+	// option to MountExecutionTrace.
+	ExecutionTraceOption func(*etOptions)
+
+	// WallClockProfileOption is a function that applies a configuration
+	// option to MountWallClockProfile.
+	WallClockProfileOption func(*wcOptions)
+
+	// ContentionProfilingOption is a function that applies a configuration
+	// option to MountContentionProfiling.
+	ContentionProfilingOption func(*cpOptions)
+
+	// CPUProfileOption is a function that applies a configuration option
+	// to MountCPUProfile.
+	CPUProfileOption func(*cpuOptions)
+
+	// RequestRecorderOption is a function that applies a configuration
+	// option to NewRequestRecorder.
+	RequestRecorderOption func(*rrOptions)
+
+	// RequestRecorderHandlerOption is a function that applies a
+	// configuration option to MountRequestRecorder.
+	RequestRecorderHandlerOption func(*rrhOptions)
+
+	// ReflectionEnablerOption is a function that applies a configuration
+	// option to MountReflectionEnabler.
+	ReflectionEnablerOption func(*reOptions)
+
+	// ExpvarOption is a function that applies a configuration option to
+	// MountExpvar.
+	ExpvarOption func(*evOptions)
+
+	// PayloadLogToggleOption is a function that applies a configuration
+	// option to MountPayloadLogToggle.
+	PayloadLogToggleOption func(*ptOptions)
+
+	// VersionOption is a function that applies a configuration option to
+	// MountVersion.
+	VersionOption func(*vOptions)
+
+	// ConfigOption is a function that applies a configuration option to
+	// MountConfig.
+	ConfigOption func(*cfgOptions)
+
+	// FlightRecorderOption is a function that applies a configuration
+	// option to NewFlightRecorder.
+	FlightRecorderOption func(*frOptions)
+
+	// FlightRecorderHandlerOption is a function that applies a
+	// configuration option to MountFlightRecorder.
+	FlightRecorderHandlerOption func(*frhOptions)
+
+	// StackDumpOption is a function that applies a configuration option to
+	// NotifyStackDump.
+	StackDumpOption func(*sdOptions)
+
+	// IndexOption is a function that applies a configuration option to
+	// MountIndex.
+	IndexOption func(*idxOptions)
+
+	// AuthOption is a function that applies a configuration option to
+	// Guard and Authorize.
+	AuthOption func(*authOptions)
+
+	// PprofLabelsOption is a function that applies a configuration option
+	// to PprofLabelsHTTP.
+	PprofLabelsOption func(*plOptions)
+
 	// PprofOption is a function that applies a configuration option to
 	// MountPprofHandlers.
 	PprofOption func(*pprofOptions)
 
+	// ChaosOption is a function that applies a configuration option to
+	// MountChaos.
+	ChaosOption func(*chaosOptions)
+
 	// FormatFunc is used to format the logged value for payloads and
 	// results.
 	FormatFunc func(context.Context, interface{}) string
@@ -31,14 +125,118 @@ type (
 	}
 
 	dleOptions struct {
-		path   string
-		query  string
-		onval  string
-		offval string
+		path    string
+		query   string
+		onval   string
+		offval  string
+		control *log.Control
+	}
+
+	tcOptions struct {
+		path string
+	}
+
+	rtOptions struct {
+		path string
+	}
+
+	gdOptions struct {
+		path string
+	}
+
+	msOptions struct {
+		path string
+	}
+
+	etOptions struct {
+		path        string
+		maxDuration time.Duration
+	}
+
+	wcOptions struct {
+		path        string
+		maxDuration time.Duration
+	}
+
+	cpOptions struct {
+		path string
+	}
+
+	cpuOptions struct {
+		path        string
+		maxDuration time.Duration
+		interval    time.Duration
+		logCtx      context.Context
+	}
+
+	rrOptions struct {
+		size     int
+		rate     float64
+		maxBody  int
+		patterns []string
+		raw      []string
+	}
+
+	rrhOptions struct {
+		path string
+	}
+
+	reOptions struct {
+		path string
+	}
+
+	evOptions struct {
+		path string
+	}
+
+	ptOptions struct {
+		path string
+	}
+
+	vOptions struct {
+		path string
+	}
+
+	cfgOptions struct {
+		path     string
+		patterns []string
+	}
+
+	frOptions struct {
+		interval time.Duration
+		window   time.Duration
+	}
+
+	frhOptions struct {
+		path string
+	}
+
+	sdOptions struct {
+		signals []os.Signal
+	}
+
+	idxOptions struct {
+		path string
+	}
+
+	authOptions struct {
+		tokens      []string
+		certs       bool
+		certNames   []string
+		authorizers []Authorizer
+	}
+
+	plOptions struct {
+		resolver cluetrace.RouteResolver
+	}
+
+	chaosOptions struct {
+		path string
 	}
 
 	pprofOptions struct {
-		prefix string
+		prefix      string
+		middlewares []func(http.Handler) http.Handler
 	}
 )
 
@@ -76,13 +274,315 @@ func WithPath(path string) DebugLogEnablerOption {
 	}
 }
 
-// WIthPrefix sets the path prefix used by MountPprofHandlers.
+// WithLogControl makes MountDebugLogEnabler apply the changes to the given log
+// control and enables the per-module levels and auto-revert timers, see
+// MountDebugLogEnabler.
+func WithLogControl(c *log.Control) DebugLogEnablerOption {
+	return func(o *dleOptions) {
+		o.control = c
+	}
+}
+
+// WithTraceControlPath sets the URL path used by MountTraceControl.
+func WithTraceControlPath(path string) TraceControlOption {
+	return func(o *tcOptions) {
+		o.path = path
+	}
+}
+
+// WithReadinessTogglePath sets the URL path used by MountReadinessToggle.
+func WithReadinessTogglePath(path string) ReadinessToggleOption {
+	return func(o *rtOptions) {
+		o.path = path
+	}
+}
+
+// WithGoroutineDumpPath sets the URL path used by MountGoroutineDump.
+func WithGoroutineDumpPath(path string) GoroutineDumpOption {
+	return func(o *gdOptions) {
+		o.path = path
+	}
+}
+
+// WithMemoryStatsPath sets the URL path used by MountMemoryStats.
+func WithMemoryStatsPath(path string) MemoryStatsOption {
+	return func(o *msOptions) {
+		o.path = path
+	}
+}
+
+// WithExecutionTracePath sets the URL path used by MountExecutionTrace.
+func WithExecutionTracePath(path string) ExecutionTraceOption {
+	return func(o *etOptions) {
+		o.path = path
+	}
+}
+
+// WithMaxExecutionTraceDuration sets the maximum duration of a capture made
+// by MountExecutionTrace, DefaultMaxExecutionTraceDuration by default.
+func WithMaxExecutionTraceDuration(d time.Duration) ExecutionTraceOption {
+	return func(o *etOptions) {
+		o.maxDuration = d
+	}
+}
+
+// WithWallClockProfilePath sets the URL path used by MountWallClockProfile.
+func WithWallClockProfilePath(path string) WallClockProfileOption {
+	return func(o *wcOptions) {
+		o.path = path
+	}
+}
+
+// WithMaxWallClockProfileDuration sets the maximum duration of a capture made
+// by MountWallClockProfile, DefaultMaxWallClockProfileDuration by default.
+func WithMaxWallClockProfileDuration(d time.Duration) WallClockProfileOption {
+	return func(o *wcOptions) {
+		o.maxDuration = d
+	}
+}
+
+// WithContentionProfilingPath sets the URL path used by
+// MountContentionProfiling.
+func WithContentionProfilingPath(path string) ContentionProfilingOption {
+	return func(o *cpOptions) {
+		o.path = path
+	}
+}
+
+// WithCPUProfilePath sets the URL path used by MountCPUProfile.
+func WithCPUProfilePath(path string) CPUProfileOption {
+	return func(o *cpuOptions) {
+		o.path = path
+	}
+}
+
+// WithMaxCPUProfileDuration sets the maximum duration of a capture made by
+// MountCPUProfile, DefaultMaxCPUProfileDuration by default.
+func WithMaxCPUProfileDuration(d time.Duration) CPUProfileOption {
+	return func(o *cpuOptions) {
+		o.maxDuration = d
+	}
+}
+
+// WithCPUProfileInterval sets the minimum interval between two captures made
+// by MountCPUProfile unless forced, DefaultCPUProfileInterval by default. An
+// interval of 0 disables rate limiting.
+func WithCPUProfileInterval(d time.Duration) CPUProfileOption {
+	return func(o *cpuOptions) {
+		o.interval = d
+	}
+}
+
+// WithCPUProfileLogContext sets the context used to write the audit log
+// entries of MountCPUProfile. By default the entries are written with the
+// request context which must then be initialized with log.Context (e.g. via
+// the log.HTTP middleware).
+func WithCPUProfileLogContext(ctx context.Context) CPUProfileOption {
+	return func(o *cpuOptions) {
+		o.logCtx = ctx
+	}
+}
+
+// WithRecordedRequests sets the maximum number of requests retained by a
+// RequestRecorder, DefaultRecordedRequests by default. The oldest requests are
+// evicted first.
+func WithRecordedRequests(n int) RequestRecorderOption {
+	return func(o *rrOptions) {
+		o.size = n
+	}
+}
+
+// WithRequestSampleRate sets the fraction of requests recorded by a
+// RequestRecorder between 0 and 1, DefaultRequestSampleRate by default.
+func WithRequestSampleRate(rate float64) RequestRecorderOption {
+	return func(o *rrOptions) {
+		o.rate = rate
+	}
+}
+
+// WithMaxRecordedBodySize sets the maximum size in bytes of the request bodies
+// recorded by a RequestRecorder, DefaultMaxRecordedBodySize by default. It
+// also limits the size of the response bodies returned when replaying
+// requests.
+func WithMaxRecordedBodySize(n int) RequestRecorderOption {
+	return func(o *rrOptions) {
+		o.maxBody = n
+	}
+}
+
+// WithRequestRedactedKeys sets the patterns used by a RequestRecorder to
+// redact secrets. A header, query parameter or body field is redacted if its
+// name contains one of the patterns, ignoring case, dashes and underscores.
+func WithRequestRedactedKeys(patterns ...string) RequestRecorderOption {
+	return func(o *rrOptions) {
+		o.patterns = patterns
+	}
+}
+
+// WithRawRecordedContentTypes sets the content types of the request bodies that
+// a RequestRecorder records as is. By default only JSON and form encoded
+// bodies, whose secret fields can be redacted, are recorded. A body is
+// recorded as is if its content type starts with one of types, e.g.
+// "text/plain". The content of these bodies is not redacted.
+func WithRawRecordedContentTypes(types ...string) RequestRecorderOption {
+	return func(o *rrOptions) {
+		o.raw = types
+	}
+}
+
+// WithRequestRecorderPath sets the URL path used by MountRequestRecorder.
+func WithRequestRecorderPath(path string) RequestRecorderHandlerOption {
+	return func(o *rrhOptions) {
+		o.path = path
+	}
+}
+
+// WithReflectionEnablerPath sets the URL path used by MountReflectionEnabler.
+func WithReflectionEnablerPath(path string) ReflectionEnablerOption {
+	return func(o *reOptions) {
+		o.path = path
+	}
+}
+
+// WithExpvarPath sets the URL path used by MountExpvar.
+func WithExpvarPath(path string) ExpvarOption {
+	return func(o *evOptions) {
+		o.path = path
+	}
+}
+
+// WithPayloadLogTogglePath sets the URL path used by MountPayloadLogToggle.
+func WithPayloadLogTogglePath(path string) PayloadLogToggleOption {
+	return func(o *ptOptions) {
+		o.path = path
+	}
+}
+
+// WithVersionPath sets the URL path used by MountVersion.
+func WithVersionPath(path string) VersionOption {
+	return func(o *vOptions) {
+		o.path = path
+	}
+}
+
+// WithConfigPath sets the URL path used by MountConfig.
+func WithConfigPath(path string) ConfigOption {
+	return func(o *cfgOptions) {
+		o.path = path
+	}
+}
+
+// WithRedactedKeys adds key patterns to the ones used by MountConfig to redact
+// secrets, see DefaultRedactedKeys. A value is redacted if its key contains one
+// of the patterns, ignoring case, dashes and underscores.
+func WithRedactedKeys(patterns ...string) ConfigOption {
+	return func(o *cfgOptions) {
+		o.patterns = append(o.patterns, patterns...)
+	}
+}
+
+// WithFlightRecorderInterval sets the interval between two samples of the
+// flight recorder, DefaultFlightRecorderInterval by default.
+func WithFlightRecorderInterval(interval time.Duration) FlightRecorderOption {
+	return func(o *frOptions) {
+		o.interval = interval
+	}
+}
+
+// WithFlightRecorderWindow sets the duration covered by the samples and events
+// retained by the flight recorder, DefaultFlightRecorderWindow by default.
+func WithFlightRecorderWindow(window time.Duration) FlightRecorderOption {
+	return func(o *frOptions) {
+		o.window = window
+	}
+}
+
+// WithFlightRecorderPath sets the URL path used by MountFlightRecorder.
+func WithFlightRecorderPath(path string) FlightRecorderHandlerOption {
+	return func(o *frhOptions) {
+		o.path = path
+	}
+}
+
+// WithStackDumpSignals sets the signals that trigger a stack dump, SIGUSR1 by
+// default on Unix systems.
+func WithStackDumpSignals(signals ...os.Signal) StackDumpOption {
+	return func(o *sdOptions) {
+		o.signals = signals
+	}
+}
+
+// WithIndexPath sets the URL path used by MountIndex.
+func WithIndexPath(path string) IndexOption {
+	return func(o *idxOptions) {
+		o.path = path
+	}
+}
+
+// WithBearerTokens authorizes requests whose "Authorization" header is
+// "Bearer " followed by one of the given tokens. Empty tokens are ignored.
+func WithBearerTokens(tokens ...string) AuthOption {
+	return func(o *authOptions) {
+		for _, t := range tokens {
+			if t != "" {
+				o.tokens = append(o.tokens, t)
+			}
+		}
+	}
+}
+
+// WithClientCertificates authorizes requests made over TLS connections whose
+// client certificate was verified by the server, i.e. the server TLS
+// configuration must set ClientCAs and a ClientAuth value that verifies client
+// certificates. If names are given the common name or one of the DNS names of
+// the client certificate must also be one of them.
+func WithClientCertificates(names ...string) AuthOption {
+	return func(o *authOptions) {
+		o.certs = true
+		o.certNames = append(o.certNames, names...)
+	}
+}
+
+// WithAuthorizer authorizes requests for which fn returns nil, e.g. to
+// validate a JWT or check the source IP of requests.
+func WithAuthorizer(fn Authorizer) AuthOption {
+	return func(o *authOptions) {
+		o.authorizers = append(o.authorizers, fn)
+	}
+}
+
+// WithRouteResolver sets the function used by PprofLabelsHTTP to resolve the
+// route of a request. The request path is used when the resolver returns an
+// empty string.
+func WithRouteResolver(resolver cluetrace.RouteResolver) PprofLabelsOption {
+	return func(o *plOptions) {
+		o.resolver = resolver
+	}
+}
+
+// WithChaosPath sets the URL path used by MountChaos.
+func WithChaosPath(path string) ChaosOption {
+	return func(o *chaosOptions) {
+		o.path = path
+	}
+}
+
+// WithPrefix sets the path prefix used by MountPprofHandlers.
 func WithPrefix(prefix string) PprofOption {
 	return func(o *pprofOptions) {
 		o.prefix = prefix
 	}
 }
 
+// WithPprofMiddleware adds middlewares that wrap the handlers mounted by
+// MountPprofHandlers, e.g. to authenticate or log requests. The first
+// middleware is the outermost one.
+func WithPprofMiddleware(middlewares ...func(http.Handler) http.Handler) PprofOption {
+	return func(o *pprofOptions) {
+		o.middlewares = append(o.middlewares, middlewares...)
+	}
+}
+
 // WithQuery sets the query string parameter name used by MountDebugLogEnabler
 // to enable or disable debug logs.
 func WithQuery(query string) DebugLogEnablerOption {
@@ -134,6 +634,166 @@ func defaultDebugLogEnablerOptions() *dleOptions {
 	}
 }
 
+// defaultTraceControlOptions returns a new tcOptions struct with default values.
+func defaultTraceControlOptions() *tcOptions {
+	return &tcOptions{
+		path: "/debug/trace",
+	}
+}
+
+// defaultReadinessToggleOptions returns a new rtOptions struct with default
+// values.
+func defaultReadinessToggleOptions() *rtOptions {
+	return &rtOptions{
+		path: "/debug/ready",
+	}
+}
+
+// defaultGoroutineDumpOptions returns a new gdOptions struct with default
+// values.
+func defaultGoroutineDumpOptions() *gdOptions {
+	return &gdOptions{
+		path: "/debug/goroutines",
+	}
+}
+
+// defaultMemoryStatsOptions returns a new msOptions struct with default values.
+func defaultMemoryStatsOptions() *msOptions {
+	return &msOptions{
+		path: "/debug/memory",
+	}
+}
+
+// defaultExecutionTraceOptions returns a new etOptions struct with default
+// values.
+func defaultExecutionTraceOptions() *etOptions {
+	return &etOptions{
+		path:        "/debug/trace/execution",
+		maxDuration: DefaultMaxExecutionTraceDuration,
+	}
+}
+
+// defaultWallClockProfileOptions returns a new wcOptions struct with default
+// values.
+func defaultWallClockProfileOptions() *wcOptions {
+	return &wcOptions{
+		path:        "/debug/fgprof",
+		maxDuration: DefaultMaxWallClockProfileDuration,
+	}
+}
+
+// defaultContentionProfilingOptions returns a new cpOptions struct with
+// default values.
+func defaultContentionProfilingOptions() *cpOptions {
+	return &cpOptions{path: "/debug/contention"}
+}
+
+// defaultCPUProfileOptions returns a new cpuOptions struct with default
+// values.
+func defaultCPUProfileOptions() *cpuOptions {
+	return &cpuOptions{
+		path:        "/debug/cpuprofile",
+		maxDuration: DefaultMaxCPUProfileDuration,
+		interval:    DefaultCPUProfileInterval,
+	}
+}
+
+// defaultRequestRecorderOptions returns a new rrOptions struct with default
+// values.
+func defaultRequestRecorderOptions() *rrOptions {
+	return &rrOptions{
+		size:     DefaultRecordedRequests,
+		rate:     DefaultRequestSampleRate,
+		maxBody:  DefaultMaxRecordedBodySize,
+		patterns: append(append([]string{}, DefaultRedactedKeys...), "cookie", "session"),
+	}
+}
+
+// defaultRequestRecorderHandlerOptions returns a new rrhOptions struct with
+// default values.
+func defaultRequestRecorderHandlerOptions() *rrhOptions {
+	return &rrhOptions{path: "/debug/requests"}
+}
+
+// defaultReflectionEnablerOptions returns a new reOptions struct with default
+// values.
+func defaultReflectionEnablerOptions() *reOptions {
+	return &reOptions{path: "/debug/grpc-reflection"}
+}
+
+// defaultExpvarOptions returns a new evOptions struct with default values.
+func defaultExpvarOptions() *evOptions {
+	return &evOptions{
+		path: "/debug/vars",
+	}
+}
+
+// defaultPayloadLogToggleOptions returns a new ptOptions struct with default
+// values.
+func defaultPayloadLogToggleOptions() *ptOptions {
+	return &ptOptions{
+		path: "/debug/payloads",
+	}
+}
+
+// defaultVersionOptions returns a new vOptions struct with default values.
+func defaultVersionOptions() *vOptions {
+	return &vOptions{
+		path: "/debug/version",
+	}
+}
+
+// defaultConfigOptions returns a new cfgOptions struct with default values.
+func defaultConfigOptions() *cfgOptions {
+	return &cfgOptions{
+		path:     "/debug/config",
+		patterns: append([]string(nil), DefaultRedactedKeys...),
+	}
+}
+
+// defaultFlightRecorderOptions returns a new frOptions struct with default
+// values.
+func defaultFlightRecorderOptions() *frOptions {
+	return &frOptions{
+		interval: DefaultFlightRecorderInterval,
+		window:   DefaultFlightRecorderWindow,
+	}
+}
+
+// defaultFlightRecorderHandlerOptions returns a new frhOptions struct with
+// default values.
+func defaultFlightRecorderHandlerOptions() *frhOptions {
+	return &frhOptions{
+		path: "/debug/flightrecorder",
+	}
+}
+
+// defaultStackDumpOptions returns a new sdOptions struct with default values.
+func defaultStackDumpOptions() *sdOptions {
+	return &sdOptions{
+		signals: defaultStackDumpSignals(),
+	}
+}
+
+// defaultIndexOptions returns a new idxOptions struct with default values.
+func defaultIndexOptions() *idxOptions {
+	return &idxOptions{
+		path: "/debug/",
+	}
+}
+
+// defaultPprofLabelsOptions returns a new plOptions struct with default values.
+func defaultPprofLabelsOptions() *plOptions {
+	return &plOptions{}
+}
+
+// defaultChaosOptions returns a new chaosOptions struct with default values.
+func defaultChaosOptions() *chaosOptions {
+	return &chaosOptions{
+		path: "/debug/chaos",
+	}
+}
+
 // defaultPprofOptions returns a new pprofOptions struct with default values.
 func defaultPprofOptions() *pprofOptions {
 	return &pprofOptions{
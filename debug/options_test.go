@@ -32,6 +32,13 @@ func TestDefaultDebugLogEnablerOptions(t *testing.T) {
 	}
 }
 
+func TestDefaultTraceControlOptions(t *testing.T) {
+	opts := defaultTraceControlOptions()
+	if opts.path != "/debug/trace" {
+		t.Errorf("got path %q, expected %q", opts.path, "/debug/trace")
+	}
+}
+
 func TestDefaultPprofOptions(t *testing.T) {
 	opts := defaultPprofOptions()
 	if opts.prefix != "/debug/pprof/" {
@@ -0,0 +1,96 @@
+package debug
+
+import (
+	"context"
+	"net/http"
+	"runtime/pprof"
+
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+)
+
+const (
+	// LabelRoute is the name of the pprof label that contains the request
+	// route.
+	LabelRoute = "route"
+	// LabelTraceID is the name of the pprof label that contains the request
+	// trace ID.
+	LabelTraceID = "trace_id"
+)
+
+// PprofLabelsHTTP returns a middleware that sets pprof labels on the goroutine
+// handling each request so that CPU profiles captured via the pprof handlers
+// (see MountPprofHandlers) can be sliced by endpoint and correlated with
+// traces. The "route" label contains the request route and the "trace_id"
+// label contains the request trace ID when the request is traced. The route
+// defaults to the request path, use WithRouteResolver to use the route pattern
+// instead. The middleware must be mounted after the trace middleware for the
+// trace ID to be available.
+//
+// Example:
+//
+//	handler = debug.PprofLabelsHTTP(debug.WithRouteResolver(resolver))(handler)
+//	handler = trace.HTTP(ctx)(handler)
+func PprofLabelsHTTP(opts ...PprofLabelsOption) func(http.Handler) http.Handler {
+	o := defaultPprofLabelsOptions()
+	for _, opt := range opts {
+		opt(o)
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var route string
+			if o.resolver != nil {
+				route = o.resolver(r)
+			}
+			if route == "" {
+				route = r.URL.Path
+			}
+			pprof.Do(r.Context(), requestLabels(r.Context(), route), func(ctx context.Context) {
+				next.ServeHTTP(w, r.WithContext(ctx))
+			})
+		})
+	}
+}
+
+// PprofLabelsUnaryServerInterceptor returns an interceptor that sets pprof
+// labels on the goroutine handling each request, see PprofLabelsHTTP. The
+// "route" label contains the full gRPC method name.
+func PprofLabelsUnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (res interface{}, err error) {
+		pprof.Do(ctx, requestLabels(ctx, info.FullMethod), func(ctx context.Context) {
+			res, err = handler(ctx, req)
+		})
+		return
+	}
+}
+
+// PprofLabelsStreamServerInterceptor returns a stream interceptor that sets
+// pprof labels on the goroutine handling each stream, see PprofLabelsHTTP.
+// The "route" label contains the full gRPC method name.
+func PprofLabelsStreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(
+		srv interface{},
+		stream grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) (err error) {
+		pprof.Do(stream.Context(), requestLabels(stream.Context(), info.FullMethod), func(ctx context.Context) {
+			err = handler(srv, &streamWithContext{stream, ctx})
+		})
+		return
+	}
+}
+
+// requestLabels returns the pprof labels for the request with the given
+// context and route.
+func requestLabels(ctx context.Context, route string) pprof.LabelSet {
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		return pprof.Labels(LabelRoute, route, LabelTraceID, sc.TraceID().String())
+	}
+	return pprof.Labels(LabelRoute, route)
+}
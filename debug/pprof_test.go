@@ -0,0 +1,91 @@
+package debug
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"runtime/pprof"
+	"testing"
+
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+
+	"goa.design/clue/testing/testsvc"
+)
+
+func TestPprofLabelsHTTP(t *testing.T) {
+	traceID := trace.TraceID{1, 2, 3}
+	spanCtx := trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: trace.SpanID{1}})
+	cases := []struct {
+		name            string
+		opts            []PprofLabelsOption
+		traced          bool
+		expectedRoute   string
+		expectedTraceID string
+	}{
+		{"path", nil, false, "/users/42", ""},
+		{"resolver", []PprofLabelsOption{WithRouteResolver(func(*http.Request) string { return "/users/{id}" })}, false, "/users/{id}", ""},
+		{"empty resolver", []PprofLabelsOption{WithRouteResolver(func(*http.Request) string { return "" })}, false, "/users/42", ""},
+		{"traced", nil, true, "/users/42", traceID.String()},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var route, tid string
+			var routeOK, tidOK bool
+			handler := PprofLabelsHTTP(c.opts...)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				route, routeOK = pprof.Label(r.Context(), LabelRoute)
+				tid, tidOK = pprof.Label(r.Context(), LabelTraceID)
+			}))
+			req := httptest.NewRequest("GET", "/users/42", nil)
+			if c.traced {
+				req = req.WithContext(trace.ContextWithSpanContext(req.Context(), spanCtx))
+			}
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+			if !routeOK || route != c.expectedRoute {
+				t.Errorf("got route label %q, expected %q", route, c.expectedRoute)
+			}
+			if tidOK != (c.expectedTraceID != "") || tid != c.expectedTraceID {
+				t.Errorf("got trace ID label %q, expected %q", tid, c.expectedTraceID)
+			}
+		})
+	}
+}
+
+func TestPprofLabelsUnaryServerInterceptor(t *testing.T) {
+	var route string
+	cli, stop := testsvc.SetupGRPC(t,
+		testsvc.WithServerOptions(grpc.UnaryInterceptor(PprofLabelsUnaryServerInterceptor())),
+		testsvc.WithUnaryFunc(func(ctx context.Context, _ *testsvc.Fields) (*testsvc.Fields, error) {
+			route, _ = pprof.Label(ctx, LabelRoute)
+			return &testsvc.Fields{}, nil
+		}))
+	defer stop()
+	if _, err := cli.GRPCMethod(context.Background(), nil); err != nil {
+		t.Fatal(err)
+	}
+	if route != "/test.Test/GrpcMethod" {
+		t.Errorf("got route label %q, expected %q", route, "/test.Test/GrpcMethod")
+	}
+}
+
+func TestPprofLabelsStreamServerInterceptor(t *testing.T) {
+	var route string
+	cli, stop := testsvc.SetupGRPC(t,
+		testsvc.WithServerOptions(grpc.StreamInterceptor(PprofLabelsStreamServerInterceptor())),
+		testsvc.WithStreamFunc(func(ctx context.Context, stream testsvc.Stream) error {
+			route, _ = pprof.Label(ctx, LabelRoute)
+			return stream.Send(&testsvc.Fields{})
+		}))
+	defer stop()
+	stream, err := cli.GRPCStream(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stream.Close()
+	if _, err := stream.Recv(); err != nil {
+		t.Fatal(err)
+	}
+	if route != "/test.Test/GrpcStream" {
+		t.Errorf("got route label %q, expected %q", route, "/test.Test/GrpcStream")
+	}
+}
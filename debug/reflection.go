@@ -0,0 +1,141 @@
+package debug
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// reflectionPrefix is the prefix of the gRPC server reflection methods.
+const reflectionPrefix = "/grpc.reflection."
+
+var (
+	// reflectionEnabled is true if gRPC server reflection is enabled.
+	reflectionEnabled bool
+	// reflectionLock protects reflectionEnabled and reflectionTimer.
+	reflectionLock sync.Mutex
+	// reflectionTimer reverts the reflection setting changed with an
+	// expiration.
+	reflectionTimer *time.Timer
+)
+
+// ReflectionStreamServerInterceptor returns a stream interceptor that rejects
+// calls to the gRPC server reflection service with codes.Unimplemented unless
+// reflection is enabled, see MountReflectionEnabler and EnableReflection. The
+// reflection service must be registered with the server (see
+// google.golang.org/grpc/reflection) so that it can be enabled at runtime, for
+// example to debug the server with grpcurl, while being disabled by default.
+//
+// Example:
+//
+//	srv := grpc.NewServer(grpc.ChainStreamInterceptor(debug.ReflectionStreamServerInterceptor()))
+//	reflection.Register(srv)
+//	debug.MountReflectionEnabler(mux)
+func ReflectionStreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(
+		srv interface{},
+		stream grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		if strings.HasPrefix(info.FullMethod, reflectionPrefix) && !ReflectionEnabled() {
+			return status.Error(codes.Unimplemented, "gRPC server reflection is disabled")
+		}
+		return handler(srv, stream)
+	}
+}
+
+// MountReflectionEnabler mounts an endpoint under "/debug/grpc-reflection"
+// that enables or disables gRPC server reflection, see
+// ReflectionStreamServerInterceptor. The endpoint accepts the following query
+// parameters:
+//
+//   - "reflection": "on" enables reflection, "off" disables it.
+//   - "revert": a duration (e.g. "10m") after which the change made by the
+//     request is reverted.
+//
+// The endpoint returns the current reflection status, for example:
+//
+//	{"reflection":"on","revert-in":"10m0s"}
+//
+// The path can be changed using WithReflectionEnablerPath.
+func MountReflectionEnabler(mux Muxer, opts ...ReflectionEnablerOption) {
+	o := defaultReflectionEnablerOptions()
+	for _, opt := range opts {
+		opt(o)
+	}
+	if !strings.HasPrefix(o.path, "/") {
+		o.path = "/" + o.path
+	}
+	register(mux, "grpc reflection", o.path, "gRPC server reflection", func() interface{} { return onOff(ReflectionEnabled()) })
+	mux.Handle(o.path, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		var revert time.Duration
+		if v := q.Get("revert"); v != "" {
+			d, err := time.ParseDuration(v)
+			if err != nil || d <= 0 {
+				http.Error(w, fmt.Sprintf("invalid revert value %q, must be a positive duration", v), http.StatusBadRequest)
+				return
+			}
+			revert = d
+		}
+		state := make(map[string]interface{})
+		switch v := q.Get("reflection"); v {
+		case "":
+		case "on", "off":
+			setReflection(v == "on", revert)
+			if revert > 0 {
+				state["revert-in"] = revert.String()
+			}
+		default:
+			http.Error(w, fmt.Sprintf("invalid reflection value %q, must be on or off", v), http.StatusBadRequest)
+			return
+		}
+		state["reflection"] = onOff(ReflectionEnabled())
+		js, _ := json.Marshal(state)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(js)
+	}))
+}
+
+// EnableReflection enables or disables gRPC server reflection, see
+// ReflectionStreamServerInterceptor. It cancels any pending revert made by the
+// endpoint mounted by MountReflectionEnabler.
+func EnableReflection(on bool) {
+	setReflection(on, 0)
+}
+
+// ReflectionEnabled returns true if gRPC server reflection is enabled.
+func ReflectionEnabled() bool {
+	reflectionLock.Lock()
+	defer reflectionLock.Unlock()
+	return reflectionEnabled
+}
+
+// setReflection sets the reflection status and reverts it after revert if
+// revert is greater than 0.
+func setReflection(on bool, revert time.Duration) {
+	reflectionLock.Lock()
+	defer reflectionLock.Unlock()
+	if reflectionTimer != nil {
+		reflectionTimer.Stop()
+		reflectionTimer = nil
+	}
+	old := reflectionEnabled
+	reflectionEnabled = on
+	if revert > 0 {
+		reflectionTimer = time.AfterFunc(revert, func() {
+			reflectionLock.Lock()
+			defer reflectionLock.Unlock()
+			reflectionEnabled = old
+			reflectionTimer = nil
+		})
+	}
+}
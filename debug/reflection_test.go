@@ -0,0 +1,85 @@
+package debug
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestReflectionStreamServerInterceptor(t *testing.T) {
+	defer EnableReflection(false)
+	interceptor := ReflectionStreamServerInterceptor()
+	handler := func(interface{}, grpc.ServerStream) error { return nil }
+	cases := []struct {
+		name         string
+		enabled      bool
+		method       string
+		expectedCode codes.Code
+	}{
+		{"disabled v1", false, "/grpc.reflection.v1.ServerReflection/ServerReflectionInfo", codes.Unimplemented},
+		{"disabled v1alpha", false, "/grpc.reflection.v1alpha.ServerReflection/ServerReflectionInfo", codes.Unimplemented},
+		{"disabled other", false, "/test.Test/Stream", codes.OK},
+		{"enabled", true, "/grpc.reflection.v1.ServerReflection/ServerReflectionInfo", codes.OK},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			EnableReflection(c.enabled)
+			err := interceptor(nil, nil, &grpc.StreamServerInfo{FullMethod: c.method}, handler)
+			if code := status.Code(err); code != c.expectedCode {
+				t.Errorf("got code %s, expected %s", code, c.expectedCode)
+			}
+		})
+	}
+}
+
+func TestMountReflectionEnabler(t *testing.T) {
+	defer EnableReflection(false)
+	mux := http.NewServeMux()
+	MountReflectionEnabler(mux)
+	cases := []struct {
+		name           string
+		url            string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{"get", "/debug/grpc-reflection", http.StatusOK, `{"reflection":"off"}`},
+		{"on", "/debug/grpc-reflection?reflection=on", http.StatusOK, `{"reflection":"on"}`},
+		{"off", "/debug/grpc-reflection?reflection=off", http.StatusOK, `{"reflection":"off"}`},
+		{"revert", "/debug/grpc-reflection?reflection=on&revert=1h", http.StatusOK, `{"reflection":"on","revert-in":"1h0m0s"}`},
+		{"invalid reflection", "/debug/grpc-reflection?reflection=yes", http.StatusBadRequest, `invalid reflection value "yes"`},
+		{"invalid revert", "/debug/grpc-reflection?reflection=on&revert=soon", http.StatusBadRequest, `invalid revert value "soon"`},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, httptest.NewRequest("GET", c.url, nil))
+			if w.Code != c.expectedStatus {
+				t.Errorf("got status %d, expected %d", w.Code, c.expectedStatus)
+			}
+			if !strings.Contains(w.Body.String(), c.expectedBody) {
+				t.Errorf("got body %q, expected it to contain %q", w.Body.String(), c.expectedBody)
+			}
+		})
+	}
+}
+
+func TestMountReflectionEnablerRevert(t *testing.T) {
+	defer EnableReflection(false)
+	mux := http.NewServeMux()
+	MountReflectionEnabler(mux)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("GET", "/debug/grpc-reflection?reflection=on&revert=50ms", nil))
+	if !ReflectionEnabled() {
+		t.Fatal("expected reflection to be enabled")
+	}
+	time.Sleep(200 * time.Millisecond)
+	if ReflectionEnabled() {
+		t.Error("expected reflection to be reverted")
+	}
+}
@@ -0,0 +1,502 @@
+package debug
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type (
+	// RequestRecorder records a sample of the HTTP requests handled by a
+	// service into a bounded in-memory store so that they can be inspected
+	// and replayed via the endpoint mounted by MountRequestRecorder. Secrets
+	// are redacted before the requests are stored. Create request recorders
+	// with NewRequestRecorder and record requests with the middleware
+	// returned by RecordRequests.
+	RequestRecorder struct {
+		rate     float64
+		maxBody  int
+		patterns []string
+		raw      []string
+		lock     sync.Mutex
+		enabled  bool
+		requests []*RecordedRequest
+		next     int // index of the next request in requests
+		full     bool
+		lastID   uint64
+	}
+
+	// RecordedRequest is a HTTP request recorded by a RequestRecorder.
+	RecordedRequest struct {
+		// ID is the unique identifier of the request in the recorder.
+		ID uint64 `json:"id"`
+		// Time is the time the request was received.
+		Time time.Time `json:"time"`
+		// Method is the request HTTP method.
+		Method string `json:"method"`
+		// URL is the request URL with secret query parameters redacted.
+		URL string `json:"url"`
+		// Header contains the request headers with secrets redacted.
+		Header http.Header `json:"header"`
+		// Body is the request body with secrets redacted. Only JSON and
+		// form encoded bodies and the bodies whose content type was given
+		// to WithRawRecordedContentTypes are recorded.
+		Body string `json:"body,omitempty"`
+		// Truncated is true if the body exceeded the maximum size and
+		// was truncated, truncated requests cannot be replayed.
+		Truncated bool `json:"truncated,omitempty"`
+		// BodyOmitted is true if the body could not be redacted and was
+		// not recorded, such requests cannot be replayed.
+		BodyOmitted bool `json:"body_omitted,omitempty"`
+		// Status is the response status code.
+		Status int `json:"status"`
+		// DurationMS is the time it took to handle the request in
+		// milliseconds.
+		DurationMS float64 `json:"duration_ms"`
+	}
+
+	// replayResult is the response of the replay endpoint.
+	replayResult struct {
+		Request *RecordedRequest `json:"request"`
+		Status  int              `json:"status"`
+		Header  http.Header      `json:"header"`
+		Body    string           `json:"body"`
+	}
+
+	// replayWriter is the response writer used to replay requests.
+	replayWriter struct {
+		header  http.Header
+		status  int
+		body    bytes.Buffer
+		maxBody int
+	}
+
+	// statusWriter captures the status code written by a handler.
+	statusWriter struct {
+		http.ResponseWriter
+		status int
+	}
+
+	// ctxKey is a private type used to mark replayed requests.
+	ctxKey int
+)
+
+const (
+	// DefaultRecordedRequests is the default maximum number of requests
+	// retained by a RequestRecorder.
+	DefaultRecordedRequests = 100
+	// DefaultRequestSampleRate is the default fraction of requests recorded
+	// by a RequestRecorder.
+	DefaultRequestSampleRate = 0.01
+	// DefaultMaxRecordedBodySize is the default maximum size in bytes of the
+	// request bodies recorded by a RequestRecorder.
+	DefaultMaxRecordedBodySize = 64 * 1024
+)
+
+// ctxReplay is the context key used to mark replayed requests.
+const ctxReplay ctxKey = iota + 1
+
+// NewRequestRecorder returns a request recorder that retains the last
+// DefaultRecordedRequests requests sampled at DefaultRequestSampleRate with
+// bodies truncated to DefaultMaxRecordedBodySize. Use WithRecordedRequests,
+// WithRequestSampleRate and WithMaxRecordedBodySize to change the defaults.
+// Headers, query parameters and JSON or form encoded body fields whose name
+// matches one of the patterns in DefaultRedactedKeys, "cookie" or "session"
+// are replaced with Redacted, use WithRequestRedactedKeys to change the
+// patterns. Bodies that cannot be redacted, i.e. bodies that are not JSON or
+// form encoded or that cannot be parsed, are not recorded unless their content
+// type is given to WithRawRecordedContentTypes.
+//
+// Recording is disabled until Enable is called or the "record" query parameter
+// of the endpoint mounted by MountRequestRecorder is set to "on".
+//
+// Example:
+//
+//	rr := debug.NewRequestRecorder(debug.WithRequestSampleRate(0.1))
+//	handler = debug.RecordRequests(rr)(handler)
+//	debug.MountRequestRecorder(mux, rr, handler)
+func NewRequestRecorder(opts ...RequestRecorderOption) *RequestRecorder {
+	o := defaultRequestRecorderOptions()
+	for _, opt := range opts {
+		opt(o)
+	}
+	size := o.size
+	if size < 1 {
+		size = 1
+	}
+	patterns := make([]string, len(o.patterns))
+	for i, p := range o.patterns {
+		patterns[i] = normalizeKey(p)
+	}
+	return &RequestRecorder{
+		rate:     o.rate,
+		maxBody:  o.maxBody,
+		patterns: patterns,
+		raw:      o.raw,
+		requests: make([]*RecordedRequest, size),
+	}
+}
+
+// Enable starts recording requests.
+func (rr *RequestRecorder) Enable() {
+	rr.lock.Lock()
+	defer rr.lock.Unlock()
+	rr.enabled = true
+}
+
+// Disable stops recording requests, the recorded requests are kept.
+func (rr *RequestRecorder) Disable() {
+	rr.lock.Lock()
+	defer rr.lock.Unlock()
+	rr.enabled = false
+}
+
+// Enabled returns true if the recorder is recording requests.
+func (rr *RequestRecorder) Enabled() bool {
+	rr.lock.Lock()
+	defer rr.lock.Unlock()
+	return rr.enabled
+}
+
+// Requests returns the recorded requests, oldest first.
+func (rr *RequestRecorder) Requests() []*RecordedRequest {
+	rr.lock.Lock()
+	defer rr.lock.Unlock()
+	var res []*RecordedRequest
+	if rr.full {
+		res = append(res, rr.requests[rr.next:]...)
+	}
+	return append(res, rr.requests[:rr.next]...)
+}
+
+// Request returns the recorded request with the given ID, nil if there is no
+// such request.
+func (rr *RequestRecorder) Request(id uint64) *RecordedRequest {
+	for _, req := range rr.Requests() {
+		if req.ID == id {
+			return req
+		}
+	}
+	return nil
+}
+
+// RecordRequests returns a HTTP middleware that records a sample of the
+// requests handled by the next handler in rr when rr is enabled. Requests
+// replayed via the endpoint mounted by MountRequestRecorder are not recorded.
+func RecordRequests(rr *RequestRecorder) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !rr.sample(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			start := time.Now()
+			req := &RecordedRequest{
+				Time:   start,
+				Method: r.Method,
+				URL:    rr.redactURL(r.URL),
+				Header: rr.redactHeader(r.Header),
+			}
+			if r.Body != nil && r.Body != http.NoBody {
+				body, err := io.ReadAll(io.LimitReader(r.Body, int64(rr.maxBody)+1))
+				r.Body = struct {
+					io.Reader
+					io.Closer
+				}{io.MultiReader(bytes.NewReader(body), r.Body), r.Body}
+				if err == nil {
+					if len(body) > rr.maxBody {
+						body, req.Truncated = body[:rr.maxBody], true
+					}
+					req.Body, req.BodyOmitted = rr.redactBody(body, r.Header.Get("Content-Type"), req.Truncated)
+				}
+			}
+			sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(sw, r)
+			req.Status = sw.status
+			req.DurationMS = float64(time.Since(start)) / float64(time.Millisecond)
+			rr.add(req)
+		})
+	}
+}
+
+// MountRequestRecorder mounts an endpoint under "/debug/requests" that returns
+// the requests recorded by rr and replays them against handler. The endpoint
+// accepts the following query parameters:
+//
+//   - "record": "on" enables recording, "off" disables it.
+//   - "id": returns the recorded request with the given ID.
+//   - "replay": "true" replays the request given by "id" against handler and
+//     returns the recorded request together with the response status,
+//     headers and body. Replaying requires a POST request.
+//
+// Without "id" the endpoint returns whether recording is enabled and the
+// recorded requests, for example:
+//
+//	{"recording":"on","requests":[{"id":1,"method":"POST","url":"/orders",...}]}
+//
+// Replayed requests use the recorded method, URL, headers and body, redacted
+// headers are omitted and redacted body fields are sent as Redacted so
+// handler should typically bypass authentication. Requests whose body was
+// truncated or not recorded cannot be replayed. The path can be changed using
+// WithRequestRecorderPath.
+//
+// The recorded requests contain the URLs, headers and bodies sent by clients,
+// minus the redacted values, and replaying lets the caller resend them to
+// handler, typically without authentication, see Guard.
+func MountRequestRecorder(mux Muxer, rr *RequestRecorder, handler http.Handler, opts ...RequestRecorderHandlerOption) {
+	o := defaultRequestRecorderHandlerOptions()
+	for _, opt := range opts {
+		opt(o)
+	}
+	if !strings.HasPrefix(o.path, "/") {
+		o.path = "/" + o.path
+	}
+	register(mux, "requests", o.path, "Request capture and replay", func() interface{} {
+		return map[string]interface{}{"recording": onOff(rr.Enabled()), "requests": len(rr.Requests())}
+	})
+	mux.Handle(o.path, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		switch v := q.Get("record"); v {
+		case "":
+		case "on":
+			rr.Enable()
+		case "off":
+			rr.Disable()
+		default:
+			http.Error(w, fmt.Sprintf("invalid record value %q, must be on or off", v), http.StatusBadRequest)
+			return
+		}
+		v := q.Get("id")
+		if v == "" {
+			js, _ := json.Marshal(map[string]interface{}{"recording": onOff(rr.Enabled()), "requests": rr.Requests()})
+			w.Header().Set("Content-Type", "application/json")
+			w.Write(js)
+			return
+		}
+		id, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid id value %q, must be a positive integer", v), http.StatusBadRequest)
+			return
+		}
+		req := rr.Request(id)
+		if req == nil {
+			http.Error(w, fmt.Sprintf("request %d not found", id), http.StatusNotFound)
+			return
+		}
+		var res interface{} = req
+		switch v := q.Get("replay"); v {
+		case "", "false":
+		case "true":
+			if r.Method != http.MethodPost {
+				w.Header().Set("Allow", http.MethodPost)
+				http.Error(w, "replaying a request requires a POST request", http.StatusMethodNotAllowed)
+				return
+			}
+			if req.Truncated {
+				http.Error(w, fmt.Sprintf("request %d body was truncated, it cannot be replayed", id), http.StatusConflict)
+				return
+			}
+			if req.BodyOmitted {
+				http.Error(w, fmt.Sprintf("request %d body was not recorded, it cannot be replayed", id), http.StatusConflict)
+				return
+			}
+			res, err = rr.replay(r.Context(), req, handler)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("failed to replay request %d: %s", id, err), http.StatusInternalServerError)
+				return
+			}
+		default:
+			http.Error(w, fmt.Sprintf("invalid replay value %q, must be true or false", v), http.StatusBadRequest)
+			return
+		}
+		js, _ := json.Marshal(res)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(js)
+	}))
+}
+
+// sample returns true if r should be recorded.
+func (rr *RequestRecorder) sample(r *http.Request) bool {
+	if r.Context().Value(ctxReplay) != nil || !rr.Enabled() {
+		return false
+	}
+	return rr.rate >= 1 || rand.Float64() < rr.rate
+}
+
+// add adds req to the recorded requests, evicting the oldest request if the
+// store is full.
+func (rr *RequestRecorder) add(req *RecordedRequest) {
+	rr.lock.Lock()
+	defer rr.lock.Unlock()
+	rr.lastID++
+	req.ID = rr.lastID
+	rr.requests[rr.next] = req
+	rr.next = (rr.next + 1) % len(rr.requests)
+	if rr.next == 0 {
+		rr.full = true
+	}
+}
+
+// replay replays req against handler.
+func (rr *RequestRecorder) replay(ctx context.Context, req *RecordedRequest, handler http.Handler) (*replayResult, error) {
+	r, err := http.NewRequestWithContext(context.WithValue(ctx, ctxReplay, true), req.Method, req.URL, strings.NewReader(req.Body))
+	if err != nil {
+		return nil, err
+	}
+	for k, vals := range req.Header {
+		for _, v := range vals {
+			if v != Redacted {
+				r.Header.Add(k, v)
+			}
+		}
+	}
+	r.RequestURI = req.URL
+	w := &replayWriter{header: make(http.Header), maxBody: rr.maxBody}
+	handler.ServeHTTP(w, r)
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return &replayResult{Request: req, Status: w.status, Header: w.header, Body: w.body.String()}, nil
+}
+
+// redactURL returns the path and query of u with secret query parameters
+// redacted.
+func (rr *RequestRecorder) redactURL(u *url.URL) string {
+	res := *u
+	res.Scheme, res.Host, res.User = "", "", nil
+	if res.RawQuery != "" {
+		q := res.Query()
+		for k, vals := range q {
+			if isSecret(k, rr.patterns) {
+				for i := range vals {
+					vals[i] = Redacted
+				}
+			}
+		}
+		res.RawQuery = q.Encode()
+	}
+	return res.String()
+}
+
+// redactHeader returns a copy of h with secret headers redacted.
+func (rr *RequestRecorder) redactHeader(h http.Header) http.Header {
+	res := make(http.Header, len(h))
+	for k, vals := range h {
+		cp := make([]string, len(vals))
+		for i, v := range vals {
+			if isSecret(k, rr.patterns) {
+				v = Redacted
+			}
+			cp[i] = v
+		}
+		res[k] = cp
+	}
+	return res
+}
+
+// redactBody returns body with secret fields redacted if body is JSON or form
+// encoded, body as is if its content type was given to
+// WithRawRecordedContentTypes. It returns true if body cannot be redacted, for
+// example because it is truncated JSON, the body must not be recorded then.
+func (rr *RequestRecorder) redactBody(body []byte, contentType string, truncated bool) (string, bool) {
+	switch {
+	case len(body) == 0:
+		return "", false
+	case strings.Contains(contentType, "json"):
+		var v interface{}
+		if err := json.Unmarshal(body, &v); err != nil {
+			return "", true
+		}
+		js, _ := json.Marshal(redact(v, rr.patterns))
+		return string(js), false
+	case strings.HasPrefix(contentType, "application/x-www-form-urlencoded"):
+		if truncated {
+			return "", true
+		}
+		form, err := url.ParseQuery(string(body))
+		if err != nil {
+			return "", true
+		}
+		for k, vals := range form {
+			if isSecret(k, rr.patterns) {
+				for i := range vals {
+					vals[i] = Redacted
+				}
+			}
+		}
+		return form.Encode(), false
+	}
+	for _, t := range rr.raw {
+		if strings.HasPrefix(contentType, t) {
+			return string(body), false
+		}
+	}
+	return "", true
+}
+
+// Header implements http.ResponseWriter.
+func (w *replayWriter) Header() http.Header { return w.header }
+
+// WriteHeader implements http.ResponseWriter.
+func (w *replayWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+// Write implements http.ResponseWriter, the body is truncated to maxBody
+// bytes.
+func (w *replayWriter) Write(b []byte) (int, error) {
+	w.WriteHeader(http.StatusOK)
+	if n := w.maxBody - w.body.Len(); n > 0 {
+		if len(b) > n {
+			w.body.Write(b[:n])
+		} else {
+			w.body.Write(b)
+		}
+	}
+	return len(b), nil
+}
+
+// WriteHeader records the status code.
+func (w *statusWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Flush implements http.Flusher, it flushes the underlying response writer if
+// it supports it so that streaming responses work while recording.
+func (w *statusWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack implements http.Hijacker, it hijacks the underlying connection if the
+// underlying response writer supports it so that protocol upgrades such as
+// WebSocket work while recording.
+func (w *statusWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, http.ErrNotSupported
+	}
+	conn, rw, err := h.Hijack()
+	if err == nil {
+		w.status = http.StatusSwitchingProtocols
+	}
+	return conn, rw, err
+}
+
+// Unwrap returns the underlying response writer.
+func (w *statusWriter) Unwrap() http.ResponseWriter { return w.ResponseWriter }
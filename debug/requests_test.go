@@ -0,0 +1,269 @@
+package debug
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRecordRequests(t *testing.T) {
+	cases := []struct {
+		name           string
+		enabled        bool
+		url            string
+		contentType    string
+		body           string
+		header         http.Header
+		expectedURL    string
+		expectedBody   string
+		expectedHeader http.Header
+		truncated      bool
+		omitted        bool
+	}{
+		{name: "disabled", url: "/orders"},
+		{name: "get", enabled: true, url: "/orders?id=1&token=abc", expectedURL: "/orders?id=1&token=%5BREDACTED%5D", expectedHeader: http.Header{}},
+		{
+			name:           "json",
+			enabled:        true,
+			url:            "/orders",
+			contentType:    "application/json",
+			body:           `{"item":"book","password":"secret"}`,
+			header:         http.Header{"Authorization": {"Bearer abc"}, "Cookie": {"session=abc"}, "X-Api-Key": {"abc"}, "X-Session-Id": {"abc"}, "X-Request-Id": {"123"}},
+			expectedURL:    "/orders",
+			expectedBody:   `{"item":"book","password":"[REDACTED]"}`,
+			expectedHeader: http.Header{"Authorization": {Redacted}, "Cookie": {Redacted}, "X-Api-Key": {Redacted}, "X-Session-Id": {Redacted}, "X-Request-Id": {"123"}},
+		},
+		{
+			name:         "form",
+			enabled:      true,
+			url:          "/login",
+			contentType:  "application/x-www-form-urlencoded",
+			body:         "passwd=secret&user=alice",
+			expectedURL:  "/login",
+			expectedBody: "passwd=%5BREDACTED%5D&user=alice",
+		},
+		{name: "raw", enabled: true, url: "/notes", contentType: "text/plain; charset=utf-8", body: "note", expectedURL: "/notes", expectedBody: "note"},
+		{name: "truncated", enabled: true, url: "/upload", contentType: "text/plain", body: strings.Repeat("0123456789", 5), expectedURL: "/upload", expectedBody: strings.Repeat("0123456789", 4), truncated: true},
+		{name: "not redactable", enabled: true, url: "/upload", contentType: "application/octet-stream", body: "password=secret", expectedURL: "/upload", omitted: true},
+		{name: "no content type", enabled: true, url: "/upload", body: "password=secret", expectedURL: "/upload", omitted: true},
+		{name: "invalid json", enabled: true, url: "/orders", contentType: "application/json", body: `{"password":"secret"`, expectedURL: "/orders", omitted: true},
+		{name: "truncated form", enabled: true, url: "/login", contentType: "application/x-www-form-urlencoded", body: "user=alice&" + strings.Repeat("x", 30) + "password=secret", expectedURL: "/login", truncated: true, omitted: true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			rr := NewRequestRecorder(WithRequestSampleRate(1), WithMaxRecordedBodySize(40), WithRecordedRequests(2), WithRawRecordedContentTypes("text/plain"))
+			if c.enabled {
+				rr.Enable()
+			}
+			var received string
+			handler := RecordRequests(rr)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				b, _ := io.ReadAll(r.Body)
+				received = string(b)
+				w.WriteHeader(http.StatusCreated)
+			}))
+			req := httptest.NewRequest("POST", c.url, strings.NewReader(c.body))
+			if c.contentType != "" {
+				req.Header.Set("Content-Type", c.contentType)
+			}
+			for k, v := range c.header {
+				req.Header[k] = v
+			}
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+			if received != c.body {
+				t.Errorf("got handler body %q, expected %q", received, c.body)
+			}
+			reqs := rr.Requests()
+			if !c.enabled {
+				if len(reqs) != 0 {
+					t.Errorf("got %d requests, expected none", len(reqs))
+				}
+				return
+			}
+			if len(reqs) != 1 {
+				t.Fatalf("got %d requests, expected 1", len(reqs))
+			}
+			got := reqs[0]
+			if got.ID != 1 || got.Method != "POST" || got.Status != http.StatusCreated {
+				t.Errorf("got request %+v, expected ID 1, method POST and status 201", got)
+			}
+			if got.URL != c.expectedURL {
+				t.Errorf("got URL %q, expected %q", got.URL, c.expectedURL)
+			}
+			if got.Body != c.expectedBody {
+				t.Errorf("got body %q, expected %q", got.Body, c.expectedBody)
+			}
+			if got.Truncated != c.truncated {
+				t.Errorf("got truncated %v, expected %v", got.Truncated, c.truncated)
+			}
+			if got.BodyOmitted != c.omitted {
+				t.Errorf("got body omitted %v, expected %v", got.BodyOmitted, c.omitted)
+			}
+			for k, v := range c.expectedHeader {
+				if got.Header.Get(k) != v[0] {
+					t.Errorf("got header %s %q, expected %q", k, got.Header.Get(k), v[0])
+				}
+			}
+		})
+	}
+}
+
+func TestRecordRequestsStreaming(t *testing.T) {
+	rr := NewRequestRecorder(WithRequestSampleRate(1))
+	rr.Enable()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/events", func(w http.ResponseWriter, r *http.Request) {
+		f, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, "streaming not supported", http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte("data: event\n\n"))
+		f.Flush()
+	})
+	mux.HandleFunc("/upgrade", func(w http.ResponseWriter, r *http.Request) {
+		h, ok := w.(http.Hijacker)
+		if !ok {
+			http.Error(w, "hijacking not supported", http.StatusInternalServerError)
+			return
+		}
+		conn, buf, err := h.Hijack()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer conn.Close()
+		buf.WriteString("HTTP/1.1 101 Switching Protocols\r\nConnection: Upgrade\r\nUpgrade: test\r\n\r\n")
+		buf.Flush()
+	})
+	svr := httptest.NewServer(RecordRequests(rr)(mux))
+	defer svr.Close()
+
+	resp, err := http.Get(svr.URL + "/events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("got streaming status %d, expected %d", resp.StatusCode, http.StatusOK)
+	}
+
+	req, _ := http.NewRequest("GET", svr.URL+"/upgrade", nil)
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "test")
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Errorf("got upgrade status %d, expected %d", resp.StatusCode, http.StatusSwitchingProtocols)
+	}
+
+	if reqs := rr.Requests(); len(reqs) != 2 || reqs[1].Status != http.StatusSwitchingProtocols {
+		t.Errorf("got requests %+v, expected the upgrade to be recorded with status 101", reqs)
+	}
+}
+
+func TestRequestRecorderEviction(t *testing.T) {
+	rr := NewRequestRecorder(WithRequestSampleRate(1), WithRecordedRequests(2))
+	rr.Enable()
+	handler := RecordRequests(rr)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	for i := 0; i < 3; i++ {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", fmt.Sprintf("/%d", i), nil))
+	}
+	reqs := rr.Requests()
+	if len(reqs) != 2 || reqs[0].URL != "/1" || reqs[1].URL != "/2" {
+		t.Fatalf("got requests %+v, expected /1 and /2", reqs)
+	}
+	if rr.Request(1) != nil || rr.Request(3) == nil {
+		t.Error("expected request 1 to be evicted and request 3 to be retained")
+	}
+}
+
+func TestMountRequestRecorder(t *testing.T) {
+	rr := NewRequestRecorder(WithRequestSampleRate(1), WithMaxRecordedBodySize(20), WithRawRecordedContentTypes("text/plain"))
+	svc := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		w.Header().Set("X-Auth", r.Header.Get("Authorization"))
+		w.WriteHeader(http.StatusAccepted)
+		fmt.Fprintf(w, "%s %s %s", r.Method, r.URL.Path, b)
+	})
+	handler := RecordRequests(rr)(svc)
+	mux := http.NewServeMux()
+	MountRequestRecorder(mux, rr, handler)
+	serve := func(method, url string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(method, url, nil))
+		return w
+	}
+
+	if w := serve("GET", "/debug/requests?record=on"); w.Body.String() != `{"recording":"on","requests":null}` {
+		t.Errorf("got body %q", w.Body.String())
+	}
+	req := httptest.NewRequest("PUT", "/items/1", strings.NewReader("hello"))
+	req.Header.Set("Authorization", "Bearer abc")
+	req.Header.Set("Content-Type", "text/plain")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	req = httptest.NewRequest("PUT", "/items/2", strings.NewReader("this body is way too long"))
+	req.Header.Set("Content-Type", "text/plain")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	req = httptest.NewRequest("PUT", "/items/3", strings.NewReader("binary"))
+	req.Header.Set("Content-Type", "application/octet-stream")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	serve("GET", "/debug/requests?record=off")
+
+	var list struct {
+		Recording string             `json:"recording"`
+		Requests  []*RecordedRequest `json:"requests"`
+	}
+	if err := json.Unmarshal(serve("GET", "/debug/requests").Body.Bytes(), &list); err != nil {
+		t.Fatal(err)
+	}
+	if list.Recording != "off" || len(list.Requests) != 3 {
+		t.Fatalf("got %+v, expected recording off and 3 requests", list)
+	}
+
+	var res replayResult
+	w := serve("POST", "/debug/requests?id=1&replay=true")
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatalf("%s: %s", err, w.Body.String())
+	}
+	if res.Status != http.StatusAccepted || res.Body != "PUT /items/1 hello" || res.Header.Get("X-Auth") != "" {
+		t.Errorf("got replay result %+v", res)
+	}
+	if len(rr.Requests()) != 3 {
+		t.Errorf("got %d requests, expected replayed request not to be recorded", len(rr.Requests()))
+	}
+
+	cases := []struct {
+		name           string
+		method         string
+		url            string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{"get", "GET", "/debug/requests?id=1", http.StatusOK, `"url":"/items/1"`},
+		{"not found", "GET", "/debug/requests?id=42", http.StatusNotFound, "request 42 not found"},
+		{"replay get", "GET", "/debug/requests?id=1&replay=true", http.StatusMethodNotAllowed, "replaying a request requires a POST request"},
+		{"replay truncated", "POST", "/debug/requests?id=2&replay=true", http.StatusConflict, "request 2 body was truncated"},
+		{"replay omitted", "POST", "/debug/requests?id=3&replay=true", http.StatusConflict, "request 3 body was not recorded"},
+		{"invalid id", "GET", "/debug/requests?id=abc", http.StatusBadRequest, `invalid id value "abc"`},
+		{"invalid record", "GET", "/debug/requests?record=yes", http.StatusBadRequest, `invalid record value "yes"`},
+		{"invalid replay", "POST", "/debug/requests?id=1&replay=yes", http.StatusBadRequest, `invalid replay value "yes"`},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			w := serve(c.method, c.url)
+			if w.Code != c.expectedStatus {
+				t.Errorf("got status %d, expected %d", w.Code, c.expectedStatus)
+			}
+			if !strings.Contains(w.Body.String(), c.expectedBody) {
+				t.Errorf("got body %q, expected it to contain %q", w.Body.String(), c.expectedBody)
+			}
+		})
+	}
+}
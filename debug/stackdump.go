@@ -0,0 +1,160 @@
+package debug
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	"goa.design/clue/log"
+)
+
+var (
+	// goroutineHeader matches the first line of a goroutine stack, e.g.
+	// "goroutine 12 [chan receive, 5 minutes]:".
+	goroutineHeader = regexp.MustCompile(`^goroutine \d+ \[([^,\]]+)`)
+
+	// callArgs matches the arguments of a call in a goroutine stack.
+	callArgs = regexp.MustCompile(`\([^()]*\)$`)
+
+	// createdIn matches the ID of the parent goroutine in a goroutine
+	// stack.
+	createdIn = regexp.MustCompile(` in goroutine \d+$`)
+)
+
+// NotifyStackDump logs the stacks of all the goroutines and key runtime
+// statistics with the logger in ctx each time the process receives one of the
+// stack dump signals, see LogStackDump. This makes it possible to inspect
+// services whose debug HTTP endpoints are not reachable:
+//
+//	kill -USR1 <pid>
+//
+// The default signal is SIGUSR1 on Unix systems, WithStackDumpSignals sets
+// other signals (e.g. SIGQUIT, note that handling SIGQUIT prevents the Go
+// runtime from dumping the stacks and exiting). NotifyStackDump does nothing if
+// there is no signal to listen to. It returns a function that stops listening
+// to the signals, listening also stops when ctx is done.
+func NotifyStackDump(ctx context.Context, opts ...StackDumpOption) (stop func()) {
+	o := defaultStackDumpOptions()
+	for _, opt := range opts {
+		opt(o)
+	}
+	if len(o.signals) == 0 {
+		return func() {}
+	}
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, o.signals...)
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		for {
+			select {
+			case sig := <-ch:
+				LogStackDump(log.With(ctx, log.KV{K: "signal", V: sig.String()}))
+			case <-done:
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(ch)
+			close(done)
+			<-stopped
+		})
+	}
+}
+
+// LogStackDump logs key runtime statistics (goroutines, memory and GC) in one
+// entry followed by one entry per unique goroutine stack with the number of
+// goroutines that share the stack, most common first. Goroutines are grouped
+// by state (e.g. "chan receive") and stack ignoring call arguments so that
+// thousands of identical goroutines result in a single entry. The entries are
+// logged with log.Print so they are written even if the log context buffers
+// its entries.
+func LogStackDump(ctx context.Context) {
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	log.Print(ctx,
+		log.KV{K: log.MessageKey, V: "stack dump"},
+		log.KV{K: "goroutines", V: runtime.NumGoroutine()},
+		log.KV{K: "gomaxprocs", V: runtime.GOMAXPROCS(0)},
+		log.KV{K: "num-cpu", V: runtime.NumCPU()},
+		log.KV{K: "cgo-calls", V: runtime.NumCgoCall()},
+		log.KV{K: "heap-alloc", V: ms.HeapAlloc},
+		log.KV{K: "heap-inuse", V: ms.HeapInuse},
+		log.KV{K: "heap-objects", V: ms.HeapObjects},
+		log.KV{K: "sys", V: ms.Sys},
+		log.KV{K: "num-gc", V: ms.NumGC},
+		log.KV{K: "gc-pause-total-ms", V: toMS(ms.PauseTotalNs)},
+		log.KV{K: "gc-cpu-fraction", V: ms.GCCPUFraction},
+	)
+	for _, g := range groupStacks(allStacks()) {
+		log.Print(ctx,
+			log.KV{K: log.MessageKey, V: "goroutine stack"},
+			log.KV{K: "count", V: g.count},
+			log.KV{K: "state", V: g.state},
+			log.KV{K: "stack", V: g.stack},
+		)
+	}
+}
+
+// stackGroup is a set of goroutines that share the same state and stack.
+type stackGroup struct {
+	state string
+	stack string
+	count int
+}
+
+// allStacks returns the stacks of all goroutines as formatted by
+// runtime.Stack.
+func allStacks() string {
+	buf := make([]byte, 64*1024)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			return string(buf[:n])
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+}
+
+// groupStacks groups the goroutines of dump by state and stack, the groups
+// are sorted by decreasing count.
+func groupStacks(dump string) []*stackGroup {
+	groups := make(map[string]*stackGroup)
+	var res []*stackGroup
+	for _, block := range strings.Split(strings.TrimSpace(dump), "\n\n") {
+		lines := strings.Split(block, "\n")
+		m := goroutineHeader.FindStringSubmatch(lines[0])
+		if m == nil {
+			continue
+		}
+		frames := lines[1:]
+		for i, l := range frames {
+			if !strings.HasPrefix(l, "\t") {
+				l = callArgs.ReplaceAllString(l, "(...)")
+				frames[i] = createdIn.ReplaceAllString(l, "")
+			}
+		}
+		stack := strings.Join(frames, "\n")
+		key := m[1] + "\n" + stack
+		g, ok := groups[key]
+		if !ok {
+			g = &stackGroup{state: m[1], stack: stack}
+			groups[key] = g
+			res = append(res, g)
+		}
+		g.count++
+	}
+	sort.SliceStable(res, func(i, j int) bool { return res[i].count > res[j].count })
+	return res
+}
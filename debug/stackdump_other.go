@@ -0,0 +1,11 @@
+//go:build !unix
+
+package debug
+
+import "os"
+
+// defaultStackDumpSignals returns nil as there is no signal dedicated to user
+// actions on this platform, use WithStackDumpSignals.
+func defaultStackDumpSignals() []os.Signal {
+	return nil
+}
@@ -0,0 +1,102 @@
+package debug
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"goa.design/clue/log"
+)
+
+func TestLogStackDump(t *testing.T) {
+	var buf bytes.Buffer
+	ctx := log.Context(context.Background(), log.WithOutput(&buf), log.WithFormat(log.FormatJSON))
+	block := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() { defer wg.Done(); <-block }()
+	}
+	defer func() { close(block); wg.Wait() }()
+	for blocked(allStacks()) < 3 {
+		time.Sleep(time.Millisecond)
+	}
+
+	LogStackDump(ctx)
+
+	entries := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	var stats map[string]interface{}
+	if err := json.Unmarshal([]byte(entries[0]), &stats); err != nil {
+		t.Fatal(err)
+	}
+	if stats["msg"] != "stack dump" || stats["goroutines"].(float64) < 4 || stats["heap-alloc"].(float64) == 0 {
+		t.Errorf("unexpected stats entry %s", entries[0])
+	}
+	var found bool
+	for _, e := range entries[1:] {
+		var stack map[string]interface{}
+		if err := json.Unmarshal([]byte(e), &stack); err != nil {
+			t.Fatal(err)
+		}
+		if stack["msg"] != "goroutine stack" {
+			t.Errorf("unexpected entry %s", e)
+		}
+		if strings.Contains(stack["stack"].(string), "TestLogStackDump.func") && stack["state"] == "chan receive" {
+			found = true
+			if stack["count"].(float64) != 3 {
+				t.Errorf("got count %v, expected 3", stack["count"])
+			}
+		}
+	}
+	if !found {
+		t.Errorf("blocked goroutines not found in %s", buf.String())
+	}
+}
+
+// blocked returns the number of goroutines created by TestLogStackDump that
+// are blocked in dump.
+func blocked(dump string) int {
+	for _, g := range groupStacks(dump) {
+		if g.state == "chan receive" && strings.Contains(g.stack, "TestLogStackDump.func") {
+			return g.count
+		}
+	}
+	return 0
+}
+
+func TestGroupStacks(t *testing.T) {
+	dump := `goroutine 1 [running]:
+main.main()
+	/app/main.go:10 +0x1d
+
+goroutine 7 [chan receive, 5 minutes]:
+main.worker(0xc000010000, 0x1)
+	/app/worker.go:20 +0x2a
+created by main.main in goroutine 1
+	/app/main.go:8 +0x3b
+
+goroutine 8 [chan receive]:
+main.worker(0xc000020000, 0x2)
+	/app/worker.go:20 +0x2a
+created by main.main in goroutine 1
+	/app/main.go:8 +0x3b
+`
+	groups := groupStacks(dump)
+	if len(groups) != 2 {
+		t.Fatalf("got %d groups, expected 2", len(groups))
+	}
+	if groups[0].count != 2 || groups[0].state != "chan receive" {
+		t.Errorf("got group %+v, expected 2 goroutines in chan receive", groups[0])
+	}
+	expected := "main.worker(...)\n\t/app/worker.go:20 +0x2a\ncreated by main.main\n\t/app/main.go:8 +0x3b"
+	if groups[0].stack != expected {
+		t.Errorf("got stack %q, expected %q", groups[0].stack, expected)
+	}
+	if groups[1].count != 1 || groups[1].state != "running" {
+		t.Errorf("got group %+v, expected 1 running goroutine", groups[1])
+	}
+}
@@ -0,0 +1,14 @@
+//go:build unix
+
+package debug
+
+import (
+	"os"
+	"syscall"
+)
+
+// defaultStackDumpSignals returns the signals that trigger a stack dump by
+// default.
+func defaultStackDumpSignals() []os.Signal {
+	return []os.Signal{syscall.SIGUSR1}
+}
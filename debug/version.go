@@ -0,0 +1,104 @@
+package debug
+
+import (
+	"encoding/json"
+	"net/http"
+	"runtime"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// BuildInfo describes the build of the service.
+type BuildInfo struct {
+	// Version of the service.
+	Version string `json:"version,omitempty"`
+	// Commit is the VCS revision the service was built from.
+	Commit string `json:"commit,omitempty"`
+	// BuildDate is the date the service was built, e.g. in RFC 3339
+	// format.
+	BuildDate string `json:"build_date,omitempty"`
+	// GoVersion is the version of Go used to build the service.
+	GoVersion string `json:"go_version,omitempty"`
+	// Features lists the enabled feature flags.
+	Features []string `json:"features,omitempty"`
+}
+
+var (
+	// buildInfoLock protects buildInfo.
+	buildInfoLock sync.Mutex
+	// buildInfo is the build information set with SetBuildInfo.
+	buildInfo BuildInfo
+)
+
+// SetBuildInfo sets the build information returned by ReadBuildInfo, typically
+// with values injected at link time:
+//
+//	var version, commit, date string // set with -ldflags "-X main.version=..."
+//
+//	debug.SetBuildInfo(debug.BuildInfo{Version: version, Commit: commit, BuildDate: date})
+//
+// Empty fields are populated from the build information embedded in the binary
+// by the Go toolchain.
+func SetBuildInfo(bi BuildInfo) {
+	buildInfoLock.Lock()
+	defer buildInfoLock.Unlock()
+	buildInfo = bi
+	buildInfo.Features = append([]string(nil), bi.Features...)
+}
+
+// ReadBuildInfo returns the build information set with SetBuildInfo completed
+// with the information embedded in the binary by the Go toolchain (see
+// runtime/debug.ReadBuildInfo): the main module version, the VCS revision and
+// time and the Go version.
+func ReadBuildInfo() *BuildInfo {
+	buildInfoLock.Lock()
+	res := buildInfo
+	res.Features = append([]string(nil), buildInfo.Features...)
+	buildInfoLock.Unlock()
+	sort.Strings(res.Features)
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		if res.Version == "" && bi.Main.Version != "(devel)" {
+			res.Version = bi.Main.Version
+		}
+		for _, s := range bi.Settings {
+			switch s.Key {
+			case "vcs.revision":
+				if res.Commit == "" {
+					res.Commit = s.Value
+				}
+			case "vcs.time":
+				if res.BuildDate == "" {
+					res.BuildDate = s.Value
+				}
+			}
+		}
+	}
+	if res.GoVersion == "" {
+		res.GoVersion = runtime.Version()
+	}
+	return &res
+}
+
+// MountVersion mounts an endpoint under "/debug/version" that returns the JSON
+// encoded build information of the service (see ReadBuildInfo), for example:
+//
+//	{"version":"v1.2.0","commit":"4f2a9c1","build_date":"2023-05-01T10:00:00Z","go_version":"go1.20.4","features":["new-pricing"]}
+//
+// The path can be changed using WithVersionPath.
+func MountVersion(mux Muxer, opts ...VersionOption) {
+	o := defaultVersionOptions()
+	for _, opt := range opts {
+		opt(o)
+	}
+	if !strings.HasPrefix(o.path, "/") {
+		o.path = "/" + o.path
+	}
+	register(mux, "version", o.path, "Build information", func() interface{} { return ReadBuildInfo().Version })
+	mux.Handle(o.path, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		js, _ := json.Marshal(ReadBuildInfo())
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(js)
+	}))
+}
@@ -0,0 +1,49 @@
+package debug
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+)
+
+func TestMountVersion(t *testing.T) {
+	defer SetBuildInfo(BuildInfo{})
+	mux := http.NewServeMux()
+	MountVersion(mux)
+	MountVersion(mux, WithVersionPath("test"))
+
+	cases := []struct {
+		name     string
+		info     BuildInfo
+		expected BuildInfo
+	}{
+		{"defaults", BuildInfo{}, BuildInfo{GoVersion: runtime.Version()}},
+		{"set", BuildInfo{Version: "v1.2.0", Commit: "4f2a9c1", BuildDate: "2023-05-01", Features: []string{"b", "a"}},
+			BuildInfo{Version: "v1.2.0", Commit: "4f2a9c1", BuildDate: "2023-05-01", GoVersion: runtime.Version(), Features: []string{"a", "b"}}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			SetBuildInfo(c.info)
+			for _, path := range []string{"/debug/version", "/test"} {
+				w := httptest.NewRecorder()
+				mux.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+				if w.Code != http.StatusOK {
+					t.Fatalf("%s: got status %d, expected %d", path, w.Code, http.StatusOK)
+				}
+				var bi BuildInfo
+				if err := json.Unmarshal(w.Body.Bytes(), &bi); err != nil {
+					t.Fatal(err)
+				}
+				// Test binaries have no VCS information.
+				if bi.Version != c.expected.Version || bi.Commit != c.expected.Commit || bi.BuildDate != c.expected.BuildDate || bi.GoVersion != c.expected.GoVersion {
+					t.Errorf("%s: got build info %+v, expected %+v", path, bi, c.expected)
+				}
+				if len(bi.Features) != len(c.expected.Features) || (len(bi.Features) > 0 && bi.Features[0] != c.expected.Features[0]) {
+					t.Errorf("%s: got features %v, expected %v", path, bi.Features, c.expected.Features)
+				}
+			}
+		})
+	}
+}
@@ -79,6 +79,7 @@ func main() {
 	endpoints := genforecaster.NewEndpoints(svc)
 	endpoints.Use(debug.LogPayloads())
 	endpoints.Use(log.Endpoint)
+	endpoints.Use(trace.Endpoint)
 
 	// 6. Create transport
 	server := gengrpc.New(endpoints, nil)
@@ -104,6 +104,7 @@ func main() {
 	endpoints := genfront.NewEndpoints(svc)
 	endpoints.Use(debug.LogPayloads())
 	endpoints.Use(log.Endpoint)
+	endpoints.Use(trace.Endpoint)
 
 	// 5. Create transport
 	mux := goahttp.NewMuxer()
@@ -0,0 +1,195 @@
+package health
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"goa.design/clue/clock"
+)
+
+type (
+	// CertObserver is notified of the certificates inspected by a
+	// certificate Pinger, see metrics.CertObserver.
+	CertObserver interface {
+		// ObserveCertificate is called with the name of the pinger, the
+		// source of the certificate (file path or "memory") and the
+		// certificate.
+		ObserveCertificate(name, source string, cert *x509.Certificate)
+	}
+
+	// CertOption configures a certificate Pinger, see Certificates.
+	CertOption func(o *certOptions)
+
+	// certPinger is a non-critical Pinger that checks the expiry of
+	// certificates.
+	certPinger struct {
+		name    string
+		options *certOptions
+	}
+
+	certOptions struct {
+		files     []string
+		certs     []*x509.Certificate
+		window    time.Duration
+		observers []CertObserver
+		clock     clock.Clock
+	}
+)
+
+// DefaultExpiryWindow is the default duration before the expiry of a
+// certificate during which a certificate Pinger fails.
+const DefaultExpiryWindow = 30 * 24 * time.Hour
+
+// Certificates returns a Pinger that fails when one of the configured
+// certificates (server certificate, client certificates, trust bundles etc.)
+// expires within the expiry window or is not valid yet. The certificates are
+// configured with WithCertFiles and WithX509Certificates. Files are read on each
+// ping so that rotated certificates are taken into account. The pinger is
+// non-critical so that an upcoming expiry reports the service as degraded, use
+// WithCriticality to change this.
+//
+// Example:
+//
+//	chk := health.NewChecker(health.Certificates("certificates",
+//		health.WithCertFiles("/etc/tls/tls.crt", "/etc/tls/ca.crt"),
+//		health.WithExpiryWindow(14*24*time.Hour)))
+func Certificates(name string, opts ...CertOption) Pinger {
+	options := &certOptions{window: DefaultExpiryWindow}
+	for _, o := range opts {
+		o(options)
+	}
+	return &certPinger{name: name, options: options}
+}
+
+// WithCertFiles adds the certificates contained in the given PEM encoded
+// files. A file may contain multiple certificates, e.g. a chain or a trust
+// bundle.
+func WithCertFiles(paths ...string) CertOption {
+	return func(o *certOptions) {
+		o.files = append(o.files, paths...)
+	}
+}
+
+// WithX509Certificates adds the given certificates, e.g. the leaf of a
+// tls.Certificate.
+func WithX509Certificates(certs ...*x509.Certificate) CertOption {
+	return func(o *certOptions) {
+		o.certs = append(o.certs, certs...)
+	}
+}
+
+// WithExpiryWindow sets the duration before the expiry of a certificate during
+// which the pinger fails, DefaultExpiryWindow by default.
+func WithExpiryWindow(d time.Duration) CertOption {
+	return func(o *certOptions) {
+		o.window = d
+	}
+}
+
+// WithCertObserver adds an observer notified of each certificate inspected by
+// the pinger.
+func WithCertObserver(obs CertObserver) CertOption {
+	return func(o *certOptions) {
+		o.observers = append(o.observers, obs)
+	}
+}
+
+// WithCertClock sets the clock used to compute the time left until the
+// certificates expire. The default is the clock stored in the context given to
+// Ping if any, clock.System otherwise.
+func WithCertClock(c clock.Clock) CertOption {
+	return func(o *certOptions) {
+		o.clock = c
+	}
+}
+
+func (p *certPinger) Name() string {
+	return p.name
+}
+
+func (p *certPinger) Ping(ctx context.Context) error {
+	clk := p.options.clock
+	if clk == nil {
+		clk = clock.FromContext(ctx)
+	}
+	now := clk.Now()
+	var errs []string
+	check := func(source string, cert *x509.Certificate) {
+		for _, o := range p.options.observers {
+			o.ObserveCertificate(p.name, source, cert)
+		}
+		if err := p.check(now, cert); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %s", source, err))
+		}
+	}
+	for _, path := range p.options.files {
+		certs, err := readCertificates(path)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		for _, cert := range certs {
+			check(path, cert)
+		}
+	}
+	for _, cert := range p.options.certs {
+		check("memory", cert)
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// Criticality returns CriticalityNonCritical.
+func (p *certPinger) Criticality() Criticality {
+	return CriticalityNonCritical
+}
+
+// check checks the validity period of a single certificate at the given time.
+func (p *certPinger) check(now time.Time, cert *x509.Certificate) error {
+	subject := cert.Subject.String()
+	if now.Before(cert.NotBefore) {
+		return fmt.Errorf("certificate %q is not valid before %s", subject, cert.NotBefore.Format(time.RFC3339))
+	}
+	if left := cert.NotAfter.Sub(now); left < p.options.window {
+		if left <= 0 {
+			return fmt.Errorf("certificate %q expired on %s", subject, cert.NotAfter.Format(time.RFC3339))
+		}
+		return fmt.Errorf("certificate %q expires on %s, in less than %s", subject, cert.NotAfter.Format(time.RFC3339), p.options.window)
+	}
+	return nil
+}
+
+// readCertificates reads the PEM encoded certificates contained in path.
+func readCertificates(path string) ([]*x509.Certificate, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read certificates: %w", err)
+	}
+	var certs []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, b = pem.Decode(b)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse certificate in %s: %w", path, err)
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("no certificate found in %s", path)
+	}
+	return certs, nil
+}
@@ -0,0 +1,169 @@
+package health
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"goa.design/clue/clock"
+)
+
+func TestCertificates(t *testing.T) {
+	dir := t.TempDir()
+	valid := testCertificate(t, "valid", time.Now().Add(-time.Hour), time.Now().Add(60*24*time.Hour))
+	expiring := testCertificate(t, "expiring", time.Now().Add(-time.Hour), time.Now().Add(10*24*time.Hour))
+	expired := testCertificate(t, "expired", time.Now().Add(-2*time.Hour), time.Now().Add(-time.Hour))
+	future := testCertificate(t, "future", time.Now().Add(time.Hour), time.Now().Add(60*24*time.Hour))
+	bundle := writeCertificates(t, filepath.Join(dir, "bundle.crt"), valid, expiring)
+	validFile := writeCertificates(t, filepath.Join(dir, "valid.crt"), valid)
+	empty := filepath.Join(dir, "empty.crt")
+	if err := os.WriteFile(empty, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		name        string
+		opts        []CertOption
+		expectedErr string
+	}{
+		{"valid file", []CertOption{WithCertFiles(validFile)}, ""},
+		{"expiring in bundle", []CertOption{WithCertFiles(bundle)}, `bundle.crt: certificate "CN=expiring" expires on`},
+		{"expiring outside window", []CertOption{WithCertFiles(bundle), WithExpiryWindow(24 * time.Hour)}, ""},
+		{"expired", []CertOption{WithX509Certificates(expired)}, `memory: certificate "CN=expired" expired on`},
+		{"not valid yet", []CertOption{WithX509Certificates(future)}, `certificate "CN=future" is not valid before`},
+		{"missing file", []CertOption{WithCertFiles(filepath.Join(dir, "missing.crt"))}, "failed to read certificates"},
+		{"empty file", []CertOption{WithCertFiles(empty)}, "no certificate found"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			p := Certificates("certificates", c.opts...)
+			if p.Name() != "certificates" {
+				t.Errorf("got name %q, expected %q", p.Name(), "certificates")
+			}
+			if CriticalityOf(p) != CriticalityNonCritical {
+				t.Errorf("got criticality %q, expected %q", CriticalityOf(p), CriticalityNonCritical)
+			}
+			err := p.Ping(context.Background())
+			if c.expectedErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), c.expectedErr) {
+				t.Errorf("got error %v, expected %q", err, c.expectedErr)
+			}
+		})
+	}
+}
+
+func TestCertificatesExpiryWindow(t *testing.T) {
+	notBefore := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	notAfter := notBefore.Add(60 * 24 * time.Hour)
+	window := 30 * 24 * time.Hour
+	cert := testCertificate(t, "cert", notBefore, notAfter)
+	cases := []struct {
+		name        string
+		now         time.Time
+		expectedErr string
+	}{
+		{"before not before", notBefore.Add(-time.Second), "is not valid before"},
+		{"at not before", notBefore, ""},
+		{"at window start", notAfter.Add(-window), ""},
+		{"in window", notAfter.Add(-window).Add(time.Second), "expires on"},
+		{"at not after", notAfter, "expired on"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			clk := clock.NewFake(c.now)
+			p := Certificates("certificates", WithX509Certificates(cert), WithExpiryWindow(window), WithCertClock(clk))
+			err := p.Ping(context.Background())
+			if c.expectedErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), c.expectedErr) {
+				t.Errorf("got error %v, expected %q", err, c.expectedErr)
+			}
+		})
+	}
+
+	t.Run("clock from context", func(t *testing.T) {
+		ctx := clock.Context(context.Background(), clock.NewFake(notAfter))
+		err := Certificates("certificates", WithX509Certificates(cert)).Ping(ctx)
+		if err == nil || !strings.Contains(err.Error(), "expired on") {
+			t.Errorf("got error %v, expected certificate to be expired", err)
+		}
+	})
+}
+
+func TestCertObserver(t *testing.T) {
+	cert := testCertificate(t, "valid", time.Now().Add(-time.Hour), time.Now().Add(60*24*time.Hour))
+	path := writeCertificates(t, filepath.Join(t.TempDir(), "tls.crt"), cert)
+	obs := &recordingCertObserver{}
+	p := Certificates("certificates", WithCertFiles(path), WithX509Certificates(cert), WithCertObserver(obs))
+	if err := p.Ping(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"certificates " + path + " CN=valid", "certificates memory CN=valid"}
+	if strings.Join(obs.observed, ",") != strings.Join(expected, ",") {
+		t.Errorf("got observed certificates %v, expected %v", obs.observed, expected)
+	}
+}
+
+type recordingCertObserver struct {
+	observed []string
+}
+
+func (o *recordingCertObserver) ObserveCertificate(name, source string, cert *x509.Certificate) {
+	o.observed = append(o.observed, name+" "+source+" "+cert.Subject.String())
+}
+
+// testCertificate returns a self-signed certificate with the given common
+// name and validity period.
+func testCertificate(t *testing.T, cn string, notBefore, notAfter time.Time) *x509.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    notBefore,
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+// writeCertificates writes the PEM encoded certificates to path.
+func writeCertificates(t *testing.T, path string, certs ...*x509.Certificate) string {
+	t.Helper()
+	var b []byte
+	for _, cert := range certs {
+		b = append(b, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})...)
+	}
+	if err := os.WriteFile(path, b, 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
@@ -2,8 +2,12 @@ package health
 
 import (
 	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"goa.design/clue/clock"
 	"goa.design/clue/log"
 )
 
@@ -11,27 +15,190 @@ type (
 	// Checker exposes a health check.
 	Checker interface {
 		// Check that all dependencies are healthy. Check returns true
-		// if the service is healthy. The returned Health struct
-		// contains the health status of each dependency.
+		// if the service is healthy or degraded, that is if only
+		// non-critical dependencies are unhealthy (see Criticality).
+		// The returned Health struct contains the health status of each
+		// dependency.
 		Check(context.Context) (*Health, bool)
 	}
 
+	// Registry is a Checker whose dependencies can be registered after it
+	// is created, e.g. when the clients of the service are initialized
+	// lazily.
+	Registry interface {
+		Checker
+		// Register adds the given dependencies to the checks. A
+		// dependency replaces any previously registered dependency with
+		// the same name.
+		Register(deps ...Pinger)
+		// Deregister removes the dependencies with the given names
+		// from the checks together with their recorded results.
+		Deregister(names ...string)
+		// AddObserver adds an observer notified of the result of each
+		// dependency check, see metrics.HealthObserver.
+		AddObserver(o Observer)
+		// OnTransition adds a function called when the status of a
+		// dependency or the state of the service changes.
+		OnTransition(hook func(Transition))
+		// RegisterWarmup adds a function that must complete
+		// successfully before the service is ready, see Start.
+		RegisterWarmup(name string, warmup func(context.Context) error)
+		// Start runs the registered warmup functions, see Starter.
+		Start(context.Context) error
+		Starter
+		Drainer
+	}
+
+	// Drainer makes it possible to take a service out of rotation.
+	Drainer interface {
+		// SetReady sets the readiness of the service. Readiness checks
+		// fail while ready is false regardless of the health of the
+		// dependencies, see Drain.
+		SetReady(ready bool)
+		// Ready returns the value last set with SetReady, true by
+		// default.
+		Ready() bool
+	}
+
+	// Starter exposes the startup state of a service.
+	Starter interface {
+		// Started returns true once all the warmup functions
+		// completed successfully.
+		Started() bool
+	}
+
+	// Observer is notified of the result of each dependency check.
+	Observer interface {
+		// ObserveCheck is called after each check of the dependency
+		// with the given name with the check duration and error.
+		ObserveCheck(name string, latency time.Duration, err error)
+	}
+
 	// Health status of a service.
 	Health struct {
 		// Uptime of service in seconds.
 		Uptime int64 `json:"uptime"`
 		// Version of service.
 		Version string `json:"version"`
+		// State of service: StateHealthy, StateDegraded or
+		// StateUnhealthy.
+		State string `json:"state,omitempty"`
 		// Status of each dependency indexed by service name.
 		// "OK" if dependency is healthy, "NOT OK" otherwise.
 		Status map[string]string `json:"status,omitempty"`
+		// Checks contains the details of the last check of each
+		// dependency indexed by service name. Handlers only include it
+		// in responses to verbose requests.
+		Checks map[string]*CheckResult `json:"checks,omitempty"`
+		// Groups contains the health of the members of each group
+		// dependency indexed by group name, see Group.
+		Groups map[string]*GroupHealth `json:"groups,omitempty"`
+	}
+
+	// CheckResult is the result of the last check of a dependency.
+	CheckResult struct {
+		// Criticality of the dependency.
+		Criticality Criticality `json:"criticality"`
+		// LatencyMS is the duration of the last check in milliseconds.
+		LatencyMS float64 `json:"latency_ms"`
+		// Error is the error returned by the last check if it failed.
+		Error string `json:"error,omitempty"`
+		// ConsecutiveFailures is the number of consecutive failed
+		// checks.
+		ConsecutiveFailures int `json:"consecutive_failures"`
+		// LastChecked is the time the dependency was last checked.
+		LastChecked time.Time `json:"last_checked"`
+		// LastError is the error returned by the last failed check if
+		// any.
+		LastError string `json:"last_error,omitempty"`
+		// LastFailure is the time of the last failed check if any.
+		LastFailure *time.Time `json:"last_failure,omitempty"`
 	}
 
 	// checker is a Checker that checks the health of the given
 	// dependencies.
 	checker struct {
+		lock sync.RWMutex
 		deps []Pinger
+
+		resultsLock sync.Mutex
+		results     map[string]*CheckResult
+		observers   []Observer
+		hooks       []func(Transition)
+		state       string
+
+		warmupLock sync.Mutex
+		warmups    []*warmup
+
+		notReady atomic.Bool
+
+		// clock is used to time and timestamp the checks.
+		clock clock.Clock
+		// maxAge returns the age after which the last result of a
+		// dependency is reported as unhealthy, zero means no limit.
+		maxAge func(Pinger) time.Duration
+	}
+
+	// Criticality describes the impact of the failure of a dependency on
+	// the health of the service. Pingers may implement a
+	// "Criticality() Criticality" method to declare their criticality,
+	// pingers that do not are critical. See also WithCriticality.
+	Criticality string
+
+	// leveledPinger is a Pinger with an explicit criticality.
+	leveledPinger struct {
+		Pinger
+		criticality Criticality
+	}
+
+	// timeoutPinger is a Pinger whose pings are canceled after a timeout.
+	timeoutPinger struct {
+		Pinger
+		timeout time.Duration
 	}
+
+	// pingResult is the result of a dependency ping.
+	pingResult struct {
+		name    string
+		err     error
+		latency time.Duration
+	}
+
+	// pingFunc is a Pinger that calls a function.
+	pingFunc struct {
+		name string
+		ping func(context.Context) error
+	}
+)
+
+const (
+	// CriticalityCritical is the criticality of dependencies the service
+	// cannot operate without: their failure makes the service unhealthy.
+	CriticalityCritical Criticality = "critical"
+	// CriticalityNonCritical is the criticality of dependencies the
+	// service can operate without, e.g. a cache: their failure puts the
+	// service in the degraded state.
+	CriticalityNonCritical Criticality = "non-critical"
+	// CriticalityInformational is the criticality of dependencies whose
+	// failure does not affect the state of the service. Failures are only
+	// reported in the check details and metrics.
+	CriticalityInformational Criticality = "informational"
+
+	// StateHealthy is the state of a service whose dependencies are all
+	// healthy.
+	StateHealthy = "healthy"
+	// StateDegraded is the state of a service whose critical dependencies
+	// are healthy but some non-critical dependencies are not.
+	StateDegraded = "degraded"
+	// StateUnhealthy is the state of a service with an unhealthy critical
+	// dependency.
+	StateUnhealthy = "unhealthy"
+	// StateStarting is the state of a service whose warmup functions have
+	// not all completed successfully yet.
+	StateStarting = "starting"
+	// StateDraining is the state of a service taken out of rotation with
+	// SetReady(false).
+	StateDraining = "draining"
 )
 
 // Version of service, initialized at compiled time.
@@ -41,29 +208,339 @@ var Version string
 var StartedAt = time.Now()
 
 // Create a Checker that checks the health of the given dependencies.
-func NewChecker(deps ...Pinger) Checker {
-	return &checker{
-		deps: deps,
+// Additional dependencies may be added with Register.
+func NewChecker(deps ...Pinger) Registry {
+	c := newChecker(clock.System)
+	c.Register(deps...)
+	return c
+}
+
+// newChecker returns a checker with no dependency that uses clk.
+func newChecker(clk clock.Clock) *checker {
+	return &checker{results: make(map[string]*CheckResult), clock: clk}
+}
+
+// PingFunc returns a Pinger with the given name that calls ping to check the
+// health of the dependency. It makes it possible to register dependencies that
+// do not implement Pinger.
+//
+// Example:
+//
+//	chk.Register(health.PingFunc("queue", func(ctx context.Context) error {
+//		return queue.Ping(ctx)
+//	}))
+func PingFunc(name string, ping func(context.Context) error) Pinger {
+	return &pingFunc{name: name, ping: ping}
+}
+
+func (c *checker) Register(deps ...Pinger) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	// Copy the dependencies so that concurrent checks are not affected.
+	c.deps = append([]Pinger(nil), c.deps...)
+	for _, dep := range deps {
+		replaced := false
+		for i, d := range c.deps {
+			if d.Name() == dep.Name() {
+				c.deps[i] = dep
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			c.deps = append(c.deps, dep)
+		}
 	}
 }
 
+// Deregister removes the dependencies with the given names from the checks,
+// e.g. when a tenant-specific database connection is closed. Checks in
+// progress use a snapshot of the dependencies taken when they started but do
+// not report removed dependencies.
+func (c *checker) Deregister(names ...string) {
+	removed := make(map[string]bool, len(names))
+	for _, name := range names {
+		removed[name] = true
+	}
+	c.lock.Lock()
+	deps := make([]Pinger, 0, len(c.deps))
+	for _, dep := range c.deps {
+		if !removed[dep.Name()] {
+			deps = append(deps, dep)
+		}
+	}
+	c.deps = deps
+	c.lock.Unlock()
+
+	c.resultsLock.Lock()
+	defer c.resultsLock.Unlock()
+	for name := range removed {
+		delete(c.results, name)
+	}
+}
+
+// registered returns the names of the registered dependencies.
+func (c *checker) registered() map[string]bool {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	names := make(map[string]bool, len(c.deps))
+	for _, dep := range c.deps {
+		names[dep.Name()] = true
+	}
+	return names
+}
+
+func (c *checker) AddObserver(o Observer) {
+	c.resultsLock.Lock()
+	defer c.resultsLock.Unlock()
+	c.observers = append(c.observers, o)
+}
+
+// Check pings the dependencies concurrently. Each ping is canceled after
+// DefaultPingTimeout unless a timeout is set with PingTimeout. Dependencies that
+// have not responded when their ping times out or when ctx is done are
+// reported as unhealthy.
 func (c *checker) Check(ctx context.Context) (*Health, bool) {
+	c.lock.RLock()
+	deps := c.deps
+	c.lock.RUnlock()
+	// Note: the pings use a context that is not derived from ctx so that
+	// one dependency canceling its context does not affect the other
+	// checks. The context is canceled once all the results are in or ctx
+	// is done.
+	pingCtx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	start := c.clock.Now()
+	results := make(chan pingResult, len(deps))
+	for _, dep := range deps {
+		go func(dep Pinger) {
+			timeout := timeoutOf(dep)
+			if timeout <= 0 {
+				timeout = DefaultPingTimeout
+			}
+			ctx, cancel := context.WithTimeout(pingCtx, timeout)
+			defer cancel()
+			results <- c.ping(ctx, dep)
+		}(dep)
+	}
+	pending := make(map[string]bool, len(deps))
+	for _, dep := range deps {
+		pending[dep.Name()] = true
+	}
+loop:
+	for range deps {
+		select {
+		case r := <-results:
+			delete(pending, r.name)
+			c.record(r)
+			if r.err != nil {
+				log.Error(ctx, r.err, log.KV{K: "msg", V: "ping failed"}, log.KV{K: "dep", V: r.name})
+			}
+		case <-ctx.Done():
+			log.Error(ctx, ctx.Err(), log.KV{K: "msg", V: "health check timed out"})
+			break loop
+		}
+	}
+	for name := range pending {
+		c.record(pingResult{name: name, err: fmt.Errorf("health check timed out: %w", ctx.Err()), latency: c.clock.Since(start)})
+	}
+	return c.health(deps)
+}
+
+// ping pings dep and returns the result.
+func (c *checker) ping(ctx context.Context, dep Pinger) pingResult {
+	logCtx := log.With(ctx, log.KV{K: "dep", V: dep.Name()})
+	start := c.clock.Now()
+	err := dep.Ping(logCtx)
+	return pingResult{name: dep.Name(), err: err, latency: c.clock.Since(start)}
+}
+
+// record records the result of a dependency check. Results of dependencies
+// that have been deregistered while being checked are discarded.
+func (c *checker) record(r pingResult) {
+	now := c.clock.Now()
+	if !c.registered()[r.name] {
+		return
+	}
+	c.resultsLock.Lock()
+	for _, o := range c.observers {
+		o.ObserveCheck(r.name, r.latency, r.err)
+	}
+	res, ok := c.results[r.name]
+	if !ok {
+		res = &CheckResult{}
+		c.results[r.name] = res
+	}
+	wasHealthy := res.Error == ""
+	res.LatencyMS = float64(r.latency.Microseconds()) / 1000
+	res.LastChecked = now
+	if r.err == nil {
+		res.Error = ""
+		res.ConsecutiveFailures = 0
+	} else {
+		res.Error = r.err.Error()
+		res.ConsecutiveFailures++
+		res.LastError = res.Error
+		res.LastFailure = &now
+	}
+	hooks := c.hooks
+	c.resultsLock.Unlock()
+	if ok && wasHealthy != (r.err == nil) {
+		notify(hooks, Transition{Dependency: r.name, From: depStatus(wasHealthy), To: depStatus(r.err == nil), Err: r.err})
+	}
+}
+
+// health returns the health of the service computed from the last recorded
+// results of the given dependencies. Dependencies that have not been checked
+// yet are reported as unhealthy, dependencies that have been deregistered
+// since deps was retrieved are ignored.
+func (c *checker) health(deps []Pinger) (*Health, bool) {
 	res := &Health{
 		Uptime:  int64(time.Since(StartedAt).Seconds()),
 		Version: Version,
 		Status:  make(map[string]string),
+		Checks:  make(map[string]*CheckResult),
 	}
-	healthy := true
-	for _, dep := range c.deps {
-		res.Status[dep.Name()] = "OK"
-		// Note: need to create a new context for each dependency So that one
-		// dependency canceling the context will not affect the other checks.
-		logCtx := log.With(context.Background(), log.KV{K: "dep", V: dep.Name()})
-		if err := dep.Ping(logCtx); err != nil {
-			res.Status[dep.Name()] = "NOT OK"
-			healthy = false
-			log.Error(ctx, err, log.KV{K: "msg", V: "ping failed"})
+	registered := c.registered()
+	current := make([]Pinger, 0, len(deps))
+	for _, dep := range deps {
+		if registered[dep.Name()] {
+			current = append(current, dep)
+		}
+	}
+	deps = current
+	c.resultsLock.Lock()
+	for _, dep := range deps {
+		name := dep.Name()
+		res.Status[name] = "NOT OK"
+		r, ok := c.results[name]
+		if !ok {
+			continue
+		}
+		cr := *r
+		cr.Criticality = CriticalityOf(dep)
+		if c.maxAge != nil {
+			if max := c.maxAge(dep); max > 0 && c.clock.Since(r.LastChecked) > max {
+				cr.Error = fmt.Sprintf("stale health check result, last checked %s ago", c.clock.Since(r.LastChecked).Round(time.Millisecond))
+			}
+		}
+		res.Checks[name] = &cr
+		if g := groupOf(dep); g != nil {
+			if res.Groups == nil {
+				res.Groups = make(map[string]*GroupHealth)
+			}
+			res.Groups[name] = g
+		}
+		if cr.Error == "" {
+			res.Status[name] = "OK"
+		}
+	}
+	c.resultsLock.Unlock()
+	res.State = healthState(deps, res.Status)
+	if !c.Started() {
+		res.State = StateStarting
+	}
+	if !c.Ready() {
+		res.State = StateDraining
+	}
+	c.resultsLock.Lock()
+	prev, hooks := c.state, c.hooks
+	c.state = res.State
+	c.resultsLock.Unlock()
+	if prev != "" && prev != res.State {
+		notify(hooks, Transition{From: prev, To: res.State})
+	}
+	return res, res.State == StateHealthy || res.State == StateDegraded
+}
+
+// NonCritical returns a Pinger for a dependency that the service can operate
+// without, e.g. a cache. The failure of a non-critical dependency puts the
+// service in the degraded state: health checks still succeed (status 200) but
+// the response state is StateDegraded. It is equivalent to
+// WithCriticality(p, CriticalityNonCritical).
+func NonCritical(p Pinger) Pinger {
+	return WithCriticality(p, CriticalityNonCritical)
+}
+
+// Informational returns a Pinger for a dependency whose failure does not
+// affect the health of the service. Failures only appear in the check details
+// and metrics. It is equivalent to WithCriticality(p, CriticalityInformational).
+func Informational(p Pinger) Pinger {
+	return WithCriticality(p, CriticalityInformational)
+}
+
+// WithCriticality returns a Pinger for a dependency with the given
+// criticality.
+func WithCriticality(p Pinger, c Criticality) Pinger {
+	if lp, ok := p.(*leveledPinger); ok {
+		p = lp.Pinger
+	}
+	if c == CriticalityCritical {
+		return p
+	}
+	return &leveledPinger{Pinger: p, criticality: c}
+}
+
+// CriticalityOf returns the criticality of p, see Criticality.
+func CriticalityOf(p Pinger) Criticality {
+	if c, ok := p.(interface{ Criticality() Criticality }); ok {
+		return c.Criticality()
+	}
+	return CriticalityCritical
+}
+
+// PingTimeout returns a Pinger that cancels the ping of p after timeout so
+// that a slow dependency is reported as unhealthy instead of delaying the
+// health check. The timeout overrides the default timeout of the checker:
+// DefaultPingTimeout for checkers created with NewChecker and the polling
+// interval for checkers created with NewPollingChecker.
+func PingTimeout(p Pinger, timeout time.Duration) Pinger {
+	return &timeoutPinger{Pinger: p, timeout: timeout}
+}
+
+func (p *pingFunc) Name() string                   { return p.name }
+func (p *pingFunc) Ping(ctx context.Context) error { return p.ping(ctx) }
+
+func (p *leveledPinger) Criticality() Criticality   { return p.criticality }
+func (p *leveledPinger) TTL() time.Duration         { return TTLOf(p.Pinger) }
+func (p *leveledPinger) PingTimeout() time.Duration { return timeoutOf(p.Pinger) }
+func (p *leveledPinger) groupHealth() *GroupHealth  { return groupOf(p.Pinger) }
+
+func (p *timeoutPinger) Ping(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+	return p.Pinger.Ping(ctx)
+}
+
+func (p *timeoutPinger) Criticality() Criticality   { return CriticalityOf(p.Pinger) }
+func (p *timeoutPinger) TTL() time.Duration         { return TTLOf(p.Pinger) }
+func (p *timeoutPinger) PingTimeout() time.Duration { return p.timeout }
+func (p *timeoutPinger) groupHealth() *GroupHealth  { return groupOf(p.Pinger) }
+
+// timeoutOf returns the timeout set with PingTimeout on p, zero if there is
+// none.
+func timeoutOf(p Pinger) time.Duration {
+	if t, ok := p.(interface{ PingTimeout() time.Duration }); ok {
+		return t.PingTimeout()
+	}
+	return 0
+}
+
+// healthState returns the state of a service given the status of its
+// dependencies.
+func healthState(deps []Pinger, status map[string]string) string {
+	state := StateHealthy
+	for _, dep := range deps {
+		if status[dep.Name()] == "OK" {
+			continue
+		}
+		switch CriticalityOf(dep) {
+		case CriticalityInformational:
+		case CriticalityNonCritical:
+			state = StateDegraded
+		default:
+			return StateUnhealthy
 		}
 	}
-	return res, healthy
+	return state
 }
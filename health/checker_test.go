@@ -2,7 +2,9 @@ package health
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sync"
 	"testing"
 	"time"
 )
@@ -129,3 +131,276 @@ func multipleUnhealthyDeps(err error, names ...string) []Pinger {
 	}
 	return deps
 }
+
+func TestRegister(t *testing.T) {
+	chk := NewChecker(singleHealthyDep("dependency1")...)
+	chk.Register(PingFunc("dependency2", func(context.Context) error { return fmt.Errorf("dependency2 is not ok") }))
+
+	res, healthy := chk.Check(context.Background())
+	if healthy {
+		t.Errorf("expected unhealthy")
+	}
+	expected := map[string]string{"dependency1": "OK", "dependency2": "NOT OK"}
+	if len(res.Status) != len(expected) {
+		t.Errorf("unexpected status: %v", res.Status)
+	}
+	for k, v := range expected {
+		if res.Status[k] != v {
+			t.Errorf("unexpected status for %s: %s", k, res.Status[k])
+		}
+	}
+
+	chk.Register(singleHealthyDep("dependency2")...)
+	res, healthy = chk.Check(context.Background())
+	if !healthy {
+		t.Errorf("expected healthy, got %v", res.Status)
+	}
+	if len(res.Status) != 2 {
+		t.Errorf("expected registered dependency to be replaced, got %v", res.Status)
+	}
+}
+
+func TestCheckConcurrent(t *testing.T) {
+	var deps []Pinger
+	for _, name := range []string{"dependency1", "dependency2", "dependency3"} {
+		deps = append(deps, PingFunc(name, func(context.Context) error {
+			time.Sleep(50 * time.Millisecond)
+			return nil
+		}))
+	}
+	start := time.Now()
+	_, healthy := NewChecker(deps...).Check(context.Background())
+	if !healthy {
+		t.Errorf("expected healthy")
+	}
+	if d := time.Since(start); d >= 150*time.Millisecond {
+		t.Errorf("check took %s, expected dependencies to be checked concurrently", d)
+	}
+}
+
+func TestPingTimeoutWrapper(t *testing.T) {
+	slow := PingFunc("slow", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	chk := NewChecker(PingTimeout(slow, 10*time.Millisecond))
+	res, healthy := chk.Check(context.Background())
+	if healthy {
+		t.Errorf("expected unhealthy")
+	}
+	if res.Status["slow"] != "NOT OK" {
+		t.Errorf("unexpected status for slow: %s", res.Status["slow"])
+	}
+}
+
+func TestCheckDefaultPingTimeout(t *testing.T) {
+	var deadlines []time.Duration
+	var lock sync.Mutex
+	record := func(name string) Pinger {
+		return PingFunc(name, func(ctx context.Context) error {
+			deadline, ok := ctx.Deadline()
+			if !ok {
+				return errors.New("no deadline")
+			}
+			lock.Lock()
+			defer lock.Unlock()
+			deadlines = append(deadlines, time.Until(deadline))
+			return nil
+		})
+	}
+	chk := NewChecker(record("default"))
+	if _, healthy := chk.Check(context.Background()); !healthy {
+		t.Fatal("expected ping context to have a deadline")
+	}
+	chk = NewChecker(NonCritical(PingTimeout(record("override"), time.Hour)))
+	if _, healthy := chk.Check(context.Background()); !healthy {
+		t.Fatal("expected ping context to have a deadline")
+	}
+	if len(deadlines) != 2 {
+		t.Fatalf("got %d pings, expected 2", len(deadlines))
+	}
+	if deadlines[0] > DefaultPingTimeout {
+		t.Errorf("got timeout %s, expected at most %s", deadlines[0], DefaultPingTimeout)
+	}
+	if deadlines[1] <= DefaultPingTimeout {
+		t.Errorf("got timeout %s, expected PingTimeout to override the default", deadlines[1])
+	}
+}
+
+func TestCheckDetails(t *testing.T) {
+	var fail bool
+	chk := NewChecker(PingFunc("dependency", func(context.Context) error {
+		time.Sleep(time.Millisecond)
+		if fail {
+			return fmt.Errorf("dependency is not ok")
+		}
+		return nil
+	}))
+	expect := func(failures int, lastErr string, hasErr bool) {
+		t.Helper()
+		res, _ := chk.Check(context.Background())
+		r := res.Checks["dependency"]
+		if r == nil {
+			t.Fatal("missing check details")
+		}
+		if r.ConsecutiveFailures != failures {
+			t.Errorf("got consecutive failures: %d, expected %d", r.ConsecutiveFailures, failures)
+		}
+		if r.LastError != lastErr {
+			t.Errorf("got last error: %q, expected %q", r.LastError, lastErr)
+		}
+		if (r.Error != "") != hasErr {
+			t.Errorf("got error: %q", r.Error)
+		}
+		if r.LatencyMS < 1 {
+			t.Errorf("got latency: %fms, expected at least 1ms", r.LatencyMS)
+		}
+	}
+	expect(0, "", false)
+	fail = true
+	expect(1, "dependency is not ok", true)
+	expect(2, "dependency is not ok", true)
+	fail = false
+	expect(0, "dependency is not ok", false)
+}
+
+func TestAddObserver(t *testing.T) {
+	chk := NewChecker(append(singleHealthyDep("dependency1"), singleUnhealthyDep("dependency2", fmt.Errorf("dependency2 is not ok"))...)...)
+	obs := &recordingObserver{errs: make(map[string]error)}
+	chk.AddObserver(obs)
+	chk.Check(context.Background())
+	if len(obs.errs) != 2 {
+		t.Fatalf("got %d observed checks, expected 2", len(obs.errs))
+	}
+	if obs.errs["dependency1"] != nil {
+		t.Errorf("unexpected error for dependency1: %v", obs.errs["dependency1"])
+	}
+	if obs.errs["dependency2"] == nil {
+		t.Errorf("expected error for dependency2")
+	}
+}
+
+type recordingObserver struct {
+	errs map[string]error
+}
+
+func (o *recordingObserver) ObserveCheck(name string, _ time.Duration, err error) {
+	o.errs[name] = err
+}
+
+func TestCriticality(t *testing.T) {
+	dep := singleHealthyDep("dependency")[0]
+	cases := []struct {
+		name     string
+		pinger   Pinger
+		expected Criticality
+	}{
+		{"default", dep, CriticalityCritical},
+		{"non-critical", NonCritical(dep), CriticalityNonCritical},
+		{"informational", Informational(dep), CriticalityInformational},
+		{"override", WithCriticality(NonCritical(dep), CriticalityCritical), CriticalityCritical},
+		{"timeout", PingTimeout(Informational(dep), time.Second), CriticalityInformational},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := CriticalityOf(c.pinger); got != c.expected {
+				t.Errorf("got criticality %q, expected %q", got, c.expected)
+			}
+			if c.pinger.Name() != "dependency" {
+				t.Errorf("got name %q, expected dependency", c.pinger.Name())
+			}
+			res, _ := NewChecker(c.pinger).Check(context.Background())
+			if got := res.Checks["dependency"].Criticality; got != c.expected {
+				t.Errorf("got check criticality %q, expected %q", got, c.expected)
+			}
+		})
+	}
+}
+
+func TestOnTransition(t *testing.T) {
+	var fail bool
+	dep := PingFunc("dependency", func(context.Context) error {
+		if fail {
+			return fmt.Errorf("dependency is not ok")
+		}
+		return nil
+	})
+	chk := NewChecker(dep)
+	var transitions []Transition
+	chk.OnTransition(func(tr Transition) { transitions = append(transitions, tr) })
+
+	chk.Check(context.Background())
+	if len(transitions) != 0 {
+		t.Fatalf("got %d transitions on first check, expected 0", len(transitions))
+	}
+	fail = true
+	chk.Check(context.Background())
+	chk.Check(context.Background())
+	fail = false
+	chk.Check(context.Background())
+
+	expected := []Transition{
+		{Dependency: "dependency", From: "OK", To: "NOT OK"},
+		{From: StateHealthy, To: StateUnhealthy},
+		{Dependency: "dependency", From: "NOT OK", To: "OK"},
+		{From: StateUnhealthy, To: StateHealthy},
+	}
+	if len(transitions) != len(expected) {
+		t.Fatalf("got %d transitions, expected %d: %v", len(transitions), len(expected), transitions)
+	}
+	for i, tr := range transitions {
+		e := expected[i]
+		if tr.Dependency != e.Dependency || tr.From != e.From || tr.To != e.To {
+			t.Errorf("got transition %d %+v, expected %+v", i, tr, e)
+		}
+		if (tr.Err != nil) != (e.Dependency != "" && e.To == "NOT OK") {
+			t.Errorf("got transition %d error %v", i, tr.Err)
+		}
+	}
+}
+
+func TestDeregister(t *testing.T) {
+	chk := NewChecker(append(singleHealthyDep("dependency1"), singleUnhealthyDep("dependency2", fmt.Errorf("dependency2 is not ok"))...)...)
+	if _, healthy := chk.Check(context.Background()); healthy {
+		t.Fatal("expected unhealthy")
+	}
+
+	chk.Deregister("dependency2", "unknown")
+	res, healthy := chk.Check(context.Background())
+	if !healthy {
+		t.Errorf("expected healthy, got %v", res.Status)
+	}
+	if len(res.Status) != 1 || res.Status["dependency1"] != "OK" {
+		t.Errorf("unexpected status: %v", res.Status)
+	}
+
+	chk.Register(singleUnhealthyDep("dependency2", fmt.Errorf("dependency2 is not ok"))...)
+	res, _ = chk.Check(context.Background())
+	if n := res.Checks["dependency2"].ConsecutiveFailures; n != 1 {
+		t.Errorf("got %d consecutive failures, expected results to be reset", n)
+	}
+}
+
+func TestDeregisterDuringCheck(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	chk := NewChecker(append(singleHealthyDep("dependency1"), PingFunc("dependency2", func(context.Context) error {
+		close(started)
+		<-release
+		return fmt.Errorf("dependency2 is not ok")
+	}))...)
+	done := make(chan *Health)
+	go func() {
+		res, _ := chk.Check(context.Background())
+		done <- res
+	}()
+	<-started
+	chk.Deregister("dependency2")
+	close(release)
+	res := <-done
+	if _, ok := res.Status["dependency2"]; ok {
+		t.Errorf("expected deregistered dependency to be ignored, got %v", res.Status)
+	}
+	if res.State != StateHealthy {
+		t.Errorf("got state %q, expected %q", res.State, StateHealthy)
+	}
+}
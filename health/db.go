@@ -0,0 +1,124 @@
+package health
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+)
+
+type (
+	// DBOption configures a database Pinger, see DB.
+	DBOption func(o *dbOptions)
+
+	// dbPinger is a Pinger that checks the health of a SQL database.
+	dbPinger struct {
+		name    string
+		db      *sql.DB
+		options *dbOptions
+
+		lock      sync.Mutex
+		waitCount int64
+	}
+
+	dbOptions struct {
+		timeout time.Duration
+		query   string
+	}
+)
+
+// DefaultPingTimeout is the default maximum duration of a dependency ping.
+const DefaultPingTimeout = 5 * time.Second
+
+// DB returns a Pinger that checks the health of the given database. Ping fails
+// if the database does not respond to PingContext or to the validation query
+// (see WithDBValidationQuery) within the timeout (see WithDBTimeout). Ping
+// also fails without querying the database when the connection pool is
+// saturated, that is when all the connections allowed by SetMaxOpenConns are
+// in use and requests have been waiting for a connection since the last ping.
+// This makes readiness flip quickly when the database is wedged instead of
+// waiting for the timeout.
+//
+// Example:
+//
+//	db, err := sql.Open("postgres", dsn)
+//	if err != nil {
+//		return err
+//	}
+//	chk := health.NewChecker(health.DB("PostgreSQL", db, health.WithDBValidationQuery("SELECT 1")))
+func DB(name string, db *sql.DB, opts ...DBOption) Pinger {
+	options := &dbOptions{timeout: DefaultPingTimeout}
+	for _, o := range opts {
+		o(options)
+	}
+	return &dbPinger{
+		name:      name,
+		db:        db,
+		options:   options,
+		waitCount: db.Stats().WaitCount,
+	}
+}
+
+// WithDBTimeout sets the maximum duration of the database ping including the
+// validation query. Default timeout is DefaultPingTimeout.
+func WithDBTimeout(timeout time.Duration) DBOption {
+	return func(o *dbOptions) {
+		o.timeout = timeout
+	}
+}
+
+// WithDBValidationQuery sets a query run after pinging the database, e.g.
+// "SELECT 1". Running a query makes sure the database is able to serve
+// requests and not just accept connections. No query is run by default.
+func WithDBValidationQuery(query string) DBOption {
+	return func(o *dbOptions) {
+		o.query = query
+	}
+}
+
+func (p *dbPinger) Name() string {
+	return p.name
+}
+
+func (p *dbPinger) Ping(ctx context.Context) error {
+	if err := p.checkSaturation(); err != nil {
+		return err
+	}
+	if p.options.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.options.timeout)
+		defer cancel()
+	}
+	if err := p.db.PingContext(ctx); err != nil {
+		return fmt.Errorf("failed to ping database %q: %w", p.name, err)
+	}
+	if p.options.query == "" {
+		return nil
+	}
+	rows, err := p.db.QueryContext(ctx, p.options.query)
+	if err != nil {
+		return fmt.Errorf("validation query failed for database %q: %w", p.name, err)
+	}
+	if err := rows.Close(); err != nil {
+		return fmt.Errorf("validation query failed for database %q: %w", p.name, err)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("validation query failed for database %q: %w", p.name, err)
+	}
+	return nil
+}
+
+// checkSaturation returns an error if all the connections of the pool are in
+// use and requests waited for a connection since the last check.
+func (p *dbPinger) checkSaturation() error {
+	stats := p.db.Stats()
+	p.lock.Lock()
+	waited := stats.WaitCount > p.waitCount
+	p.waitCount = stats.WaitCount
+	p.lock.Unlock()
+	if stats.MaxOpenConnections > 0 && stats.InUse >= stats.MaxOpenConnections && waited {
+		return fmt.Errorf("connection pool of database %q is saturated: %d connections in use", p.name, stats.InUse)
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package health
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDB(t *testing.T) {
+	cases := []struct {
+		name        string
+		pingErr     error
+		queryErr    error
+		opts        []DBOption
+		expectedErr string
+	}{
+		{"ok", nil, nil, nil, ""},
+		{"ping fails", errors.New("boom"), nil, nil, `failed to ping database "db": boom`},
+		{"query ok", nil, nil, []DBOption{WithDBValidationQuery("SELECT 1")}, ""},
+		{"query fails", nil, errors.New("boom"), []DBOption{WithDBValidationQuery("SELECT 1")}, `validation query failed for database "db": boom`},
+		{"query not run", nil, errors.New("boom"), nil, ""},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			db := sql.OpenDB(&testConnector{conn: &testConn{pingErr: c.pingErr, queryErr: c.queryErr}})
+			defer func() { _ = db.Close() }()
+			p := DB("db", db, c.opts...)
+			if p.Name() != "db" {
+				t.Errorf("got name %q, expected %q", p.Name(), "db")
+			}
+			err := p.Ping(context.Background())
+			if c.expectedErr == "" && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if c.expectedErr != "" && (err == nil || err.Error() != c.expectedErr) {
+				t.Errorf("got error %v, expected %q", err, c.expectedErr)
+			}
+		})
+	}
+}
+
+func TestDBTimeout(t *testing.T) {
+	db := sql.OpenDB(&testConnector{conn: &testConn{block: true}})
+	defer func() { _ = db.Close() }()
+	p := DB("db", db, WithDBTimeout(10*time.Millisecond))
+	err := p.Ping(context.Background())
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got error %v, expected deadline exceeded", err)
+	}
+}
+
+func TestDBSaturation(t *testing.T) {
+	db := sql.OpenDB(&testConnector{conn: &testConn{}})
+	defer func() { _ = db.Close() }()
+	db.SetMaxOpenConns(1)
+	p := DB("db", db, WithDBTimeout(10*time.Millisecond))
+
+	// Hold the only connection and make a request wait for it.
+	conn, err := db.Conn(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = conn.Close() }()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_ = db.PingContext(ctx)
+
+	err = p.Ping(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "saturated") {
+		t.Errorf("got error %v, expected saturation error", err)
+	}
+}
+
+type (
+	testConnector struct {
+		conn *testConn
+	}
+
+	testConn struct {
+		pingErr  error
+		queryErr error
+		block    bool
+	}
+
+	testRows struct {
+		done bool
+	}
+)
+
+func (c *testConnector) Connect(context.Context) (driver.Conn, error) { return c.conn, nil }
+func (c *testConnector) Driver() driver.Driver                        { return nil }
+
+func (c *testConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not implemented") }
+func (c *testConn) Close() error                        { return nil }
+func (c *testConn) Begin() (driver.Tx, error)           { return nil, errors.New("not implemented") }
+
+func (c *testConn) Ping(ctx context.Context) error {
+	if c.block {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	return c.pingErr
+}
+
+func (c *testConn) QueryContext(context.Context, string, []driver.NamedValue) (driver.Rows, error) {
+	if c.queryErr != nil {
+		return nil, c.queryErr
+	}
+	return &testRows{}, nil
+}
+
+func (r *testRows) Columns() []string { return []string{"1"} }
+func (r *testRows) Close() error      { return nil }
+
+func (r *testRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	dest[0] = int64(1)
+	return nil
+}
@@ -0,0 +1,139 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+)
+
+type (
+	// DiskOption configures a disk Pinger, see Disk.
+	DiskOption func(o *diskOptions)
+
+	// diskPinger is a Pinger that checks the free space and writability of
+	// paths.
+	diskPinger struct {
+		name    string
+		paths   []string
+		options *diskOptions
+	}
+
+	diskOptions struct {
+		minFreeBytes uint64
+		minFreeRatio float64
+		writable     bool
+	}
+
+	// diskUsage is the space usage of a file system.
+	diskUsage struct {
+		// Total is the size of the file system in bytes.
+		Total uint64
+		// Free is the number of bytes available to unprivileged users.
+		Free uint64
+	}
+)
+
+// Disk returns a Pinger that checks the file systems containing the given
+// paths, e.g. data and temporary directories. Ping fails if a file system has
+// less free space than the thresholds set with WithMinFreeBytes and
+// WithMinFreeRatio or, if WithWritableCheck is used, if a file cannot be
+// created in one of the paths. Free space is only checked on platforms that
+// support statfs (Linux, macOS, BSD).
+//
+// Example:
+//
+//	chk := health.NewChecker(health.Disk("disk", []string{"/data", os.TempDir()},
+//		health.WithMinFreeRatio(0.1),
+//		health.WithWritableCheck()))
+func Disk(name string, paths []string, opts ...DiskOption) Pinger {
+	options := &diskOptions{}
+	for _, o := range opts {
+		o(options)
+	}
+	return &diskPinger{name: name, paths: paths, options: options}
+}
+
+// WithMinFreeBytes makes the pinger fail if the file system of any of the
+// paths has less than n bytes available.
+func WithMinFreeBytes(n uint64) DiskOption {
+	return func(o *diskOptions) {
+		o.minFreeBytes = n
+	}
+}
+
+// WithMinFreeRatio makes the pinger fail if the file system of any of the
+// paths has less than the given ratio (between 0 and 1) of its space
+// available.
+func WithMinFreeRatio(ratio float64) DiskOption {
+	return func(o *diskOptions) {
+		o.minFreeRatio = ratio
+	}
+}
+
+// WithWritableCheck makes the pinger fail if a file cannot be created, written
+// and removed in any of the paths.
+func WithWritableCheck() DiskOption {
+	return func(o *diskOptions) {
+		o.writable = true
+	}
+}
+
+func (p *diskPinger) Name() string {
+	return p.name
+}
+
+func (p *diskPinger) Ping(ctx context.Context) error {
+	var errs []string
+	for _, path := range p.paths {
+		if err := p.check(path); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// check checks a single path.
+func (p *diskPinger) check(path string) error {
+	if p.options.minFreeBytes > 0 || p.options.minFreeRatio > 0 {
+		u, err := statDisk(path)
+		if err != nil {
+			return fmt.Errorf("failed to retrieve disk usage of %q: %w", path, err)
+		}
+		if u.Free < p.options.minFreeBytes {
+			return fmt.Errorf("%q has %d bytes available, less than %d", path, u.Free, p.options.minFreeBytes)
+		}
+		if u.Total > 0 {
+			if ratio := float64(u.Free) / float64(u.Total); ratio < p.options.minFreeRatio {
+				return fmt.Errorf("%q has %.1f%% of space available, less than %.1f%%", path, ratio*100, p.options.minFreeRatio*100)
+			}
+		}
+	}
+	if p.options.writable {
+		if err := checkWritable(path); err != nil {
+			return fmt.Errorf("%q is not writable: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// checkWritable creates, writes and removes a temporary file in dir.
+func checkWritable(dir string) error {
+	f, err := os.CreateTemp(dir, ".health-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write([]byte("ok")); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// statDisk is the function used to retrieve the usage of the file system
+// containing path, overridden in tests.
+var statDisk = diskStat
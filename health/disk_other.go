@@ -0,0 +1,10 @@
+//go:build !unix
+
+package health
+
+import "errors"
+
+// diskStat returns an error as statfs is not supported on this platform.
+func diskStat(string) (*diskUsage, error) {
+	return nil, errors.New("disk usage not supported on this platform")
+}
@@ -0,0 +1,66 @@
+package health
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDisk(t *testing.T) {
+	dir := t.TempDir()
+	missing := filepath.Join(dir, "missing")
+	cases := []struct {
+		name        string
+		paths       []string
+		usage       *diskUsage
+		opts        []DiskOption
+		expectedErr string
+	}{
+		{"no check", []string{dir}, nil, nil, ""},
+		{"enough bytes", []string{dir}, &diskUsage{Total: 100, Free: 50}, []DiskOption{WithMinFreeBytes(50)}, ""},
+		{"not enough bytes", []string{dir}, &diskUsage{Total: 100, Free: 49}, []DiskOption{WithMinFreeBytes(50)}, "bytes available, less than 50"},
+		{"enough ratio", []string{dir}, &diskUsage{Total: 100, Free: 10}, []DiskOption{WithMinFreeRatio(0.1)}, ""},
+		{"not enough ratio", []string{dir}, &diskUsage{Total: 100, Free: 9}, []DiskOption{WithMinFreeRatio(0.1)}, "9.0% of space available, less than 10.0%"},
+		{"writable", []string{dir}, nil, []DiskOption{WithWritableCheck()}, ""},
+		{"not writable", []string{dir, missing}, nil, []DiskOption{WithWritableCheck()}, "missing\" is not writable"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if c.usage != nil {
+				restore := statDisk
+				defer func() { statDisk = restore }()
+				statDisk = func(string) (*diskUsage, error) { return c.usage, nil }
+			}
+			p := Disk("disk", c.paths, c.opts...)
+			if p.Name() != "disk" {
+				t.Errorf("got name %q, expected %q", p.Name(), "disk")
+			}
+			err := p.Ping(context.Background())
+			if c.expectedErr == "" && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if c.expectedErr != "" && (err == nil || !strings.Contains(err.Error(), c.expectedErr)) {
+				t.Errorf("got error %v, expected it to contain %q", err, c.expectedErr)
+			}
+		})
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("expected writable check to remove its files, got %d entries", len(entries))
+	}
+}
+
+func TestDiskStat(t *testing.T) {
+	u, err := diskStat(t.TempDir())
+	if err != nil {
+		t.Skipf("disk usage not supported: %v", err)
+	}
+	if u.Total == 0 || u.Free > u.Total {
+		t.Errorf("unexpected disk usage: %+v", u)
+	}
+}
@@ -0,0 +1,15 @@
+//go:build unix
+
+package health
+
+import "syscall"
+
+// diskStat returns the usage of the file system containing path.
+func diskStat(path string) (*diskUsage, error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return nil, err
+	}
+	bsize := uint64(st.Bsize)
+	return &diskUsage{Total: uint64(st.Blocks) * bsize, Free: uint64(st.Bavail) * bsize}, nil
+}
@@ -0,0 +1,121 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+type (
+	// DNSResolver resolves host names, it is implemented by net.Resolver.
+	DNSResolver interface {
+		LookupHost(ctx context.Context, host string) ([]string, error)
+	}
+
+	// DNSOption configures a DNS Pinger, see DNS.
+	DNSOption func(o *dnsOptions)
+
+	// dnsPinger is a Pinger that checks the resolution of host names.
+	dnsPinger struct {
+		name    string
+		hosts   []string
+		options *dnsOptions
+	}
+
+	dnsOptions struct {
+		resolver   DNSResolver
+		timeout    time.Duration
+		maxLatency time.Duration
+	}
+)
+
+// DNS returns a Pinger that resolves the given host names, e.g. the names of
+// critical downstream services. Ping fails if a name cannot be resolved, does
+// not resolve to any address or, if WithMaxDNSLatency is used, takes too long
+// to resolve. This makes cluster DNS outages explicit instead of presenting as
+// downstream timeouts. The names are resolved concurrently using
+// net.DefaultResolver unless WithDNSResolver is used.
+//
+// Example:
+//
+//	chk := health.NewChecker(health.DNS("dns", []string{"postgres.db.svc.cluster.local"},
+//		health.WithMaxDNSLatency(200*time.Millisecond)))
+func DNS(name string, hosts []string, opts ...DNSOption) Pinger {
+	options := &dnsOptions{resolver: net.DefaultResolver, timeout: DefaultPingTimeout}
+	for _, o := range opts {
+		o(options)
+	}
+	return &dnsPinger{name: name, hosts: hosts, options: options}
+}
+
+// WithDNSResolver sets the resolver used by the pinger.
+func WithDNSResolver(r DNSResolver) DNSOption {
+	return func(o *dnsOptions) {
+		o.resolver = r
+	}
+}
+
+// WithDNSTimeout sets the maximum duration of a ping, DefaultPingTimeout by
+// default.
+func WithDNSTimeout(timeout time.Duration) DNSOption {
+	return func(o *dnsOptions) {
+		o.timeout = timeout
+	}
+}
+
+// WithMaxDNSLatency makes the pinger fail if resolving a name takes longer
+// than d.
+func WithMaxDNSLatency(d time.Duration) DNSOption {
+	return func(o *dnsOptions) {
+		o.maxLatency = d
+	}
+}
+
+func (p *dnsPinger) Name() string {
+	return p.name
+}
+
+func (p *dnsPinger) Ping(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, p.options.timeout)
+	defer cancel()
+	errs := make([]error, len(p.hosts))
+	var wg sync.WaitGroup
+	for i, host := range p.hosts {
+		wg.Add(1)
+		go func(i int, host string) {
+			defer wg.Done()
+			errs[i] = p.resolve(ctx, host)
+		}(i, host)
+	}
+	wg.Wait()
+	var msgs []string
+	for _, err := range errs {
+		if err != nil {
+			msgs = append(msgs, err.Error())
+		}
+	}
+	if len(msgs) > 0 {
+		return fmt.Errorf("%s", strings.Join(msgs, "; "))
+	}
+	return nil
+}
+
+// resolve resolves a single host name.
+func (p *dnsPinger) resolve(ctx context.Context, host string) error {
+	start := time.Now()
+	addrs, err := p.options.resolver.LookupHost(ctx, host)
+	latency := time.Since(start)
+	if err != nil {
+		return fmt.Errorf("failed to resolve %q: %w", host, err)
+	}
+	if len(addrs) == 0 {
+		return fmt.Errorf("%q does not resolve to any address", host)
+	}
+	if p.options.maxLatency > 0 && latency > p.options.maxLatency {
+		return fmt.Errorf("resolving %q took %s, more than %s", host, latency.Round(time.Millisecond), p.options.maxLatency)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDNS(t *testing.T) {
+	resolver := &fakeResolver{
+		addrs: map[string][]string{
+			"db.local":    {"10.0.0.1"},
+			"empty.local": {},
+			"slow.local":  {"10.0.0.2"},
+		},
+		delays: map[string]time.Duration{"slow.local": 50 * time.Millisecond},
+	}
+	cases := []struct {
+		name        string
+		hosts       []string
+		opts        []DNSOption
+		expectedErr string
+	}{
+		{"ok", []string{"db.local"}, nil, ""},
+		{"not found", []string{"db.local", "missing.local"}, nil, `failed to resolve "missing.local"`},
+		{"no address", []string{"empty.local"}, nil, `"empty.local" does not resolve to any address`},
+		{"slow", []string{"slow.local"}, []DNSOption{WithMaxDNSLatency(10 * time.Millisecond)}, `resolving "slow.local" took`},
+		{"slow without threshold", []string{"slow.local"}, nil, ""},
+		{"timeout", []string{"slow.local"}, []DNSOption{WithDNSTimeout(10 * time.Millisecond)}, "context deadline exceeded"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			p := DNS("dns", c.hosts, append([]DNSOption{WithDNSResolver(resolver)}, c.opts...)...)
+			if p.Name() != "dns" {
+				t.Errorf("got name %q, expected %q", p.Name(), "dns")
+			}
+			err := p.Ping(context.Background())
+			if c.expectedErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), c.expectedErr) {
+				t.Errorf("got error %v, expected %q", err, c.expectedErr)
+			}
+		})
+	}
+}
+
+type fakeResolver struct {
+	addrs  map[string][]string
+	delays map[string]time.Duration
+}
+
+func (r *fakeResolver) LookupHost(ctx context.Context, host string) ([]string, error) {
+	select {
+	case <-time.After(r.delays[host]):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	addrs, ok := r.addrs[host]
+	if !ok {
+		return nil, errors.New("no such host")
+	}
+	return addrs, nil
+}
@@ -0,0 +1,45 @@
+package health
+
+import (
+	"context"
+	"time"
+)
+
+// SetReady sets the readiness of the service, see Drainer.
+func (c *checker) SetReady(ready bool) {
+	c.notReady.Store(!ready)
+}
+
+// Ready returns the value last set with SetReady.
+func (c *checker) Ready() bool {
+	return !c.notReady.Load()
+}
+
+// Drain fails the readiness checks of d and waits for delay so that load
+// balancers stop sending new requests before the servers shut down. It
+// returns early with the context error if ctx is done first. delay should be
+// greater than the readiness probe period multiplied by its failure threshold.
+//
+// Example:
+//
+//	<-ctx.Done()
+//	if err := health.Drain(ctx, chk, 10*time.Second); err != nil {
+//		log.Error(ctx, err)
+//	}
+//	if err := httpsvr.Shutdown(ctx); err != nil {
+//		log.Error(ctx, err)
+//	}
+//	if err := clue.Shutdown(ctx); err != nil {
+//		log.Error(ctx, err)
+//	}
+func Drain(ctx context.Context, d Drainer, delay time.Duration) error {
+	d.SetReady(false)
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
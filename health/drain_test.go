@@ -0,0 +1,47 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSetReady(t *testing.T) {
+	chk := NewChecker(singleHealthyDep("dependency")...)
+	if !chk.Ready() {
+		t.Errorf("expected checker to be ready by default")
+	}
+	chk.SetReady(false)
+	res, healthy := chk.Check(context.Background())
+	if healthy || res.State != StateDraining {
+		t.Errorf("got healthy %v and state %q, expected false and %q", healthy, res.State, StateDraining)
+	}
+	if res.Status["dependency"] != "OK" {
+		t.Errorf("got status %q for dependency, expected OK", res.Status["dependency"])
+	}
+	chk.SetReady(true)
+	if _, healthy := chk.Check(context.Background()); !healthy {
+		t.Errorf("expected checker to be healthy once ready")
+	}
+}
+
+func TestDrain(t *testing.T) {
+	chk := NewChecker()
+	start := time.Now()
+	if err := Drain(context.Background(), chk, 20*time.Millisecond); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if time.Since(start) < 20*time.Millisecond {
+		t.Errorf("expected Drain to wait for the delay")
+	}
+	if chk.Ready() {
+		t.Errorf("expected checker not to be ready after Drain")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := Drain(ctx, chk, time.Hour); !errors.Is(err, context.Canceled) {
+		t.Errorf("got error %v, expected context canceled", err)
+	}
+}
@@ -0,0 +1,118 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+type (
+	// GroupHealth is the health of the members of a group dependency, see
+	// Group.
+	GroupHealth struct {
+		// State of the group: StateHealthy, StateDegraded or
+		// StateUnhealthy.
+		State string `json:"state,omitempty"`
+		// Status of each member indexed by name.
+		Status map[string]string `json:"status,omitempty"`
+		// Checks contains the details of the last check of each member
+		// indexed by name, see Health.
+		Checks map[string]*CheckResult `json:"checks,omitempty"`
+		// Groups contains the health of the members of nested groups.
+		Groups map[string]*GroupHealth `json:"groups,omitempty"`
+	}
+
+	// groupPinger is a Pinger that checks the dependencies of a checker.
+	groupPinger struct {
+		name string
+		chk  Checker
+
+		lock sync.Mutex
+		last *GroupHealth
+	}
+)
+
+// Group returns a Pinger that checks the dependencies of chk as a single
+// dependency named name, e.g. a "storage" group made of a database, a cache
+// and an object store. Ping fails if chk reports the group as unhealthy, a
+// degraded group is healthy. The health of the members of the group is
+// included in the "groups" field of the responses so that large services
+// present an organized dependency tree. Groups may be nested.
+//
+// Example:
+//
+//	storage := health.NewChecker(db, cache, health.NonCritical(s3))
+//	chk := health.NewChecker(health.Group("storage", storage), queue)
+func Group(name string, chk Checker) Pinger {
+	return &groupPinger{name: name, chk: chk}
+}
+
+func (p *groupPinger) Name() string {
+	return p.name
+}
+
+func (p *groupPinger) Ping(ctx context.Context) error {
+	h, healthy := p.chk.Check(ctx)
+	g := &GroupHealth{State: h.State, Status: h.Status, Checks: h.Checks, Groups: h.Groups}
+	p.lock.Lock()
+	p.last = g
+	p.lock.Unlock()
+	if healthy {
+		return nil
+	}
+	var failed []string
+	for name, status := range h.Status {
+		if status != "OK" {
+			failed = append(failed, name)
+		}
+	}
+	sort.Strings(failed)
+	return fmt.Errorf("%s is %s: %s not ok", p.name, h.State, strings.Join(failed, ", "))
+}
+
+// groupHealth returns a copy of the health of the members of the group
+// recorded by the last ping, nil if the group hasn't been checked yet.
+func (p *groupPinger) groupHealth() *GroupHealth {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.last.clone()
+}
+
+// groupOf returns the health of the members of p if p is a group, nil
+// otherwise.
+func groupOf(p Pinger) *GroupHealth {
+	if g, ok := p.(interface{ groupHealth() *GroupHealth }); ok {
+		return g.groupHealth()
+	}
+	return nil
+}
+
+// clone returns a deep copy of g so that handlers may modify it.
+func (g *GroupHealth) clone() *GroupHealth {
+	if g == nil {
+		return nil
+	}
+	c := &GroupHealth{State: g.State}
+	if g.Status != nil {
+		c.Status = make(map[string]string, len(g.Status))
+		for k, v := range g.Status {
+			c.Status[k] = v
+		}
+	}
+	if g.Checks != nil {
+		c.Checks = make(map[string]*CheckResult, len(g.Checks))
+		for k, v := range g.Checks {
+			cr := *v
+			c.Checks[k] = &cr
+		}
+	}
+	if g.Groups != nil {
+		c.Groups = make(map[string]*GroupHealth, len(g.Groups))
+		for k, v := range g.Groups {
+			c.Groups[k] = v.clone()
+		}
+	}
+	return c
+}
@@ -0,0 +1,80 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGroup(t *testing.T) {
+	cases := []struct {
+		name            string
+		members         []Pinger
+		expectedErr     string
+		expectedState   string
+		expectedOverall string
+	}{
+		{"healthy", multipleHealthyDeps("db", "cache"), "", StateHealthy, StateHealthy},
+		{"degraded", append(singleHealthyDep("db"), NonCritical(singleUnhealthyDep("cache", fmt.Errorf("cache is not ok"))[0])), "", StateDegraded, StateHealthy},
+		{"unhealthy", multipleUnhealthyDeps(fmt.Errorf("cache is not ok"), "db", "cache"), "storage is unhealthy: cache not ok", StateUnhealthy, StateUnhealthy},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			g := Group("storage", NewChecker(c.members...))
+			if g.Name() != "storage" {
+				t.Errorf("got name %q, expected storage", g.Name())
+			}
+			chk := NewChecker(append(singleHealthyDep("queue"), g)...)
+			res, _ := chk.Check(context.Background())
+			if c.expectedErr == "" && res.Checks["storage"].Error != "" {
+				t.Errorf("unexpected error: %s", res.Checks["storage"].Error)
+			}
+			if c.expectedErr != "" && res.Checks["storage"].Error != c.expectedErr {
+				t.Errorf("got error %q, expected %q", res.Checks["storage"].Error, c.expectedErr)
+			}
+			if res.State != c.expectedOverall {
+				t.Errorf("got state %q, expected %q", res.State, c.expectedOverall)
+			}
+			storage := res.Groups["storage"]
+			if storage == nil {
+				t.Fatalf("expected storage group, got %v", res.Groups)
+			}
+			if storage.State != c.expectedState {
+				t.Errorf("got group state %q, expected %q", storage.State, c.expectedState)
+			}
+			if len(storage.Status) != 2 || len(storage.Checks) != 2 {
+				t.Errorf("unexpected group status %v", storage.Status)
+			}
+		})
+	}
+}
+
+func TestGroupHandler(t *testing.T) {
+	cache := NewChecker(singleUnhealthyDep("redis", fmt.Errorf("redis is not ok"))...)
+	storage := NewChecker(append(singleHealthyDep("db"), NonCritical(Group("cache", cache)))...)
+	chk := NewChecker(PingTimeout(Group("storage", storage), DefaultPingTimeout))
+
+	w := httptest.NewRecorder()
+	Handler(chk)(w, httptest.NewRequest("GET", "/readyz", nil))
+	expected := `{"uptime":0,"version":"","state":"healthy","status":{"storage":"OK"},"groups":{"storage":{"state":"degraded","status":{"cache":"NOT OK","db":"OK"},"groups":{"cache":{"state":"unhealthy","status":{"redis":"NOT OK"}}}}}}`
+	if w.Body.String() != expected {
+		t.Errorf("got body: %s, expected %s", w.Body.String(), expected)
+	}
+
+	w = httptest.NewRecorder()
+	Handler(chk, WithRedactedErrors())(w, httptest.NewRequest("GET", "/readyz?verbose=1", nil))
+	var h Health
+	if err := json.Unmarshal(w.Body.Bytes(), &h); err != nil {
+		t.Fatal(err)
+	}
+	redis := h.Groups["storage"].Groups["cache"].Checks["redis"]
+	if redis == nil || redis.Error != "" || redis.LastError != "" || redis.ConsecutiveFailures != 2 {
+		t.Errorf("expected redacted nested check details, got %+v", redis)
+	}
+	res, _ := chk.Check(context.Background())
+	if res.Groups["storage"].Groups["cache"].Checks["redis"].Error == "" {
+		t.Errorf("expected redaction not to modify recorded results")
+	}
+}
@@ -0,0 +1,89 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+type (
+	// GRPCOption configures a gRPC Pinger, see GRPC.
+	GRPCOption func(o *grpcOptions)
+
+	// grpcPinger is a Pinger that uses the gRPC health checking protocol.
+	grpcPinger struct {
+		name    string
+		client  grpc_health_v1.HealthClient
+		options *grpcOptions
+	}
+
+	grpcOptions struct {
+		service string
+		timeout time.Duration
+	}
+)
+
+// GRPC returns a Pinger that checks the health of the service at the other end
+// of conn using the gRPC health checking protocol
+// (grpc.health.v1.Health/Check). Ping fails if the service does not respond
+// within the timeout (see WithGRPCTimeout) or if the reported status is not
+// SERVING. The overall server health is checked by default, use WithGRPCService
+// to check a specific service.
+//
+// Example:
+//
+//	conn, err := grpc.DialContext(ctx, addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+//	if err != nil {
+//		return err
+//	}
+//	chk := health.NewChecker(health.GRPC("locator", conn))
+func GRPC(name string, conn *grpc.ClientConn, opts ...GRPCOption) Pinger {
+	options := &grpcOptions{timeout: DefaultPingTimeout}
+	for _, o := range opts {
+		o(options)
+	}
+	return &grpcPinger{
+		name:    name,
+		client:  grpc_health_v1.NewHealthClient(conn),
+		options: options,
+	}
+}
+
+// WithGRPCService sets the name of the service whose health is checked. The
+// default is the empty string which corresponds to the overall server health.
+func WithGRPCService(service string) GRPCOption {
+	return func(o *grpcOptions) {
+		o.service = service
+	}
+}
+
+// WithGRPCTimeout sets the maximum duration of the health check request.
+// Default timeout is DefaultPingTimeout.
+func WithGRPCTimeout(timeout time.Duration) GRPCOption {
+	return func(o *grpcOptions) {
+		o.timeout = timeout
+	}
+}
+
+func (p *grpcPinger) Name() string {
+	return p.name
+}
+
+func (p *grpcPinger) Ping(ctx context.Context) error {
+	if p.options.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.options.timeout)
+		defer cancel()
+	}
+	resp, err := p.client.Check(ctx, &grpc_health_v1.HealthCheckRequest{Service: p.options.service})
+	if err != nil {
+		return fmt.Errorf("failed to make health check request to %q: %w", p.name, err)
+	}
+	if s := resp.GetStatus(); s != grpc_health_v1.HealthCheckResponse_SERVING {
+		return fmt.Errorf("health-check for %q returned status %s", p.name, s)
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package health
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+type (
+	// GRPCServerOption configures a gRPC health server, see NewGRPCServer.
+	GRPCServerOption func(o *grpcServerOptions)
+
+	// grpcServer implements the gRPC health checking protocol using a
+	// Checker.
+	grpcServer struct {
+		grpc_health_v1.UnimplementedHealthServer
+		chk     Checker
+		options *grpcServerOptions
+	}
+
+	grpcServerOptions struct {
+		services map[string]bool
+		interval time.Duration
+	}
+)
+
+// DefaultWatchInterval is the default interval at which the health of the
+// service is checked for the Watch streams of the gRPC health server.
+const DefaultWatchInterval = 5 * time.Second
+
+// NewGRPCServer returns a server implementation of the gRPC health checking
+// protocol (grpc.health.v1.Health) driven by chk so that Kubernetes gRPC probes
+// and service meshes can check the health of the service without an HTTP
+// port. The status is SERVING if all the dependencies of chk are healthy and
+// NOT_SERVING otherwise. The overall server health (empty service name) is
+// always available, other service names must be listed with
+// WithGRPCServerServices.
+//
+// Example:
+//
+//	srv := grpc.NewServer()
+//	grpc_health_v1.RegisterHealthServer(srv, health.NewGRPCServer(chk))
+func NewGRPCServer(chk Checker, opts ...GRPCServerOption) grpc_health_v1.HealthServer {
+	options := &grpcServerOptions{
+		services: map[string]bool{"": true},
+		interval: DefaultWatchInterval,
+	}
+	for _, o := range opts {
+		o(options)
+	}
+	return &grpcServer{chk: chk, options: options}
+}
+
+// WithGRPCServerServices sets the names of the services whose health may be
+// requested in addition to the overall server health, typically the fully
+// qualified names of the gRPC services exposed by the server. All services
+// report the health of the checker. Requests for other services fail with
+// NOT_FOUND as required by the protocol.
+func WithGRPCServerServices(services ...string) GRPCServerOption {
+	return func(o *grpcServerOptions) {
+		for _, s := range services {
+			o.services[s] = true
+		}
+	}
+}
+
+// WithGRPCServerWatchInterval sets the interval at which the health is checked
+// for Watch streams. Default interval is DefaultWatchInterval.
+func WithGRPCServerWatchInterval(interval time.Duration) GRPCServerOption {
+	return func(o *grpcServerOptions) {
+		o.interval = interval
+	}
+}
+
+// Check returns the health of the requested service.
+func (s *grpcServer) Check(ctx context.Context, req *grpc_health_v1.HealthCheckRequest) (*grpc_health_v1.HealthCheckResponse, error) {
+	if !s.options.services[req.GetService()] {
+		return nil, status.Errorf(codes.NotFound, "unknown service %q", req.GetService())
+	}
+	return &grpc_health_v1.HealthCheckResponse{Status: s.status(ctx)}, nil
+}
+
+// Watch streams the health of the requested service, a message is sent
+// initially and then each time the health changes.
+func (s *grpcServer) Watch(req *grpc_health_v1.HealthCheckRequest, stream grpc_health_v1.Health_WatchServer) error {
+	ctx := stream.Context()
+	if !s.options.services[req.GetService()] {
+		// The protocol requires sending SERVICE_UNKNOWN and keeping the
+		// stream open in case the service gets registered.
+		if err := stream.Send(&grpc_health_v1.HealthCheckResponse{Status: grpc_health_v1.HealthCheckResponse_SERVICE_UNKNOWN}); err != nil {
+			return err
+		}
+		<-ctx.Done()
+		return status.FromContextError(ctx.Err()).Err()
+	}
+	ticker := time.NewTicker(s.options.interval)
+	defer ticker.Stop()
+	last := grpc_health_v1.HealthCheckResponse_UNKNOWN
+	for {
+		if st := s.status(ctx); st != last {
+			if err := stream.Send(&grpc_health_v1.HealthCheckResponse{Status: st}); err != nil {
+				return err
+			}
+			last = st
+		}
+		select {
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// status returns the gRPC serving status corresponding to the checker health.
+func (s *grpcServer) status(ctx context.Context) grpc_health_v1.HealthCheckResponse_ServingStatus {
+	if _, healthy := s.chk.Check(ctx); healthy {
+		return grpc_health_v1.HealthCheckResponse_SERVING
+	}
+	return grpc_health_v1.HealthCheckResponse_NOT_SERVING
+}
@@ -0,0 +1,92 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+func TestGRPCServerCheck(t *testing.T) {
+	cases := []struct {
+		name           string
+		deps           []Pinger
+		service        string
+		expectedStatus grpc_health_v1.HealthCheckResponse_ServingStatus
+		expectedCode   codes.Code
+	}{
+		{"serving", singleHealthyDep("dependency"), "", grpc_health_v1.HealthCheckResponse_SERVING, codes.OK},
+		{"not serving", singleUnhealthyDep("dependency", errors.New("dependency is not ok")), "", grpc_health_v1.HealthCheckResponse_NOT_SERVING, codes.OK},
+		{"service", singleHealthyDep("dependency"), "svc.Service", grpc_health_v1.HealthCheckResponse_SERVING, codes.OK},
+		{"unknown service", singleHealthyDep("dependency"), "unknown", grpc_health_v1.HealthCheckResponse_UNKNOWN, codes.NotFound},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			srv := NewGRPCServer(NewChecker(c.deps...), WithGRPCServerServices("svc.Service"))
+			client := grpc_health_v1.NewHealthClient(testGRPCConn(t, srv))
+			resp, err := client.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{Service: c.service})
+			if code := status.Code(err); code != c.expectedCode {
+				t.Fatalf("got code: %s, expected %s", code, c.expectedCode)
+			}
+			if resp.GetStatus() != c.expectedStatus {
+				t.Errorf("got status: %s, expected %s", resp.GetStatus(), c.expectedStatus)
+			}
+		})
+	}
+}
+
+func TestGRPCServerWatch(t *testing.T) {
+	var healthy atomic.Bool
+	healthy.Store(true)
+	chk := NewChecker(PingFunc("dependency", func(context.Context) error {
+		if healthy.Load() {
+			return nil
+		}
+		return errors.New("dependency is not ok")
+	}))
+	srv := NewGRPCServer(chk, WithGRPCServerWatchInterval(10*time.Millisecond))
+	client := grpc_health_v1.NewHealthClient(testGRPCConn(t, srv))
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	stream, err := client.Watch(ctx, &grpc_health_v1.HealthCheckRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.GetStatus() != grpc_health_v1.HealthCheckResponse_SERVING {
+		t.Errorf("got status: %s, expected SERVING", resp.GetStatus())
+	}
+	healthy.Store(false)
+	resp, err = stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.GetStatus() != grpc_health_v1.HealthCheckResponse_NOT_SERVING {
+		t.Errorf("got status: %s, expected NOT_SERVING", resp.GetStatus())
+	}
+}
+
+func TestGRPCServerWatchUnknownService(t *testing.T) {
+	client := grpc_health_v1.NewHealthClient(testGRPCConn(t, NewGRPCServer(NewChecker())))
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	stream, err := client.Watch(ctx, &grpc_health_v1.HealthCheckRequest{Service: "unknown"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.GetStatus() != grpc_health_v1.HealthCheckResponse_SERVICE_UNKNOWN {
+		t.Errorf("got status: %s, expected SERVICE_UNKNOWN", resp.GetStatus())
+	}
+}
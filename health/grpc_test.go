@@ -0,0 +1,71 @@
+package health
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	grpchealth "google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestGRPC(t *testing.T) {
+	cases := []struct {
+		name        string
+		service     string
+		status      grpc_health_v1.HealthCheckResponse_ServingStatus
+		expectedErr string
+	}{
+		{"serving", "", grpc_health_v1.HealthCheckResponse_SERVING, ""},
+		{"not serving", "", grpc_health_v1.HealthCheckResponse_NOT_SERVING, `health-check for "dependency" returned status NOT_SERVING`},
+		{"service serving", "svc", grpc_health_v1.HealthCheckResponse_SERVING, ""},
+		{"service not serving", "svc", grpc_health_v1.HealthCheckResponse_NOT_SERVING, `health-check for "dependency" returned status NOT_SERVING`},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			hs := grpchealth.NewServer()
+			hs.SetServingStatus(c.service, c.status)
+			conn := testGRPCConn(t, hs)
+			pinger := GRPC("dependency", conn, WithGRPCService(c.service))
+			if pinger.Name() != "dependency" {
+				t.Errorf("got name: %s, expected dependency", pinger.Name())
+			}
+			err := pinger.Ping(context.Background())
+			if c.expectedErr == "" && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if c.expectedErr != "" && (err == nil || err.Error() != c.expectedErr) {
+				t.Errorf("got error: %v, expected: %s", err, c.expectedErr)
+			}
+		})
+	}
+}
+
+func TestGRPCUnknownService(t *testing.T) {
+	conn := testGRPCConn(t, grpchealth.NewServer())
+	if err := GRPC("dependency", conn, WithGRPCService("unknown")).Ping(context.Background()); err == nil {
+		t.Errorf("expected error for unknown service")
+	}
+}
+
+// testGRPCConn returns a client connection to an in-memory gRPC server that
+// serves the given health server.
+func testGRPCConn(t *testing.T, hs grpc_health_v1.HealthServer) *grpc.ClientConn {
+	t.Helper()
+	lis := bufconn.Listen(1024 * 1024)
+	srv := grpc.NewServer()
+	grpc_health_v1.RegisterHealthServer(srv, hs)
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+	conn, err := grpc.DialContext(context.Background(), "bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn
+}
@@ -1,17 +1,78 @@
 package health
 
 import (
+	"context"
+	"crypto/subtle"
 	"encoding/json"
+	"net"
 	"net/http"
+	"net/netip"
+	"strings"
+	"time"
+)
+
+type (
+	// Muxer is the HTTP mux interface used by Mount. It is implemented by
+	// http.ServeMux and by the adapter returned by debug.Adapt for Goa
+	// muxers.
+	Muxer interface {
+		Handle(pattern string, handler http.Handler)
+	}
+
+	// HandlerOption configures a health check handler.
+	HandlerOption func(o *handlerOptions)
+
+	handlerOptions struct {
+		timeout  time.Duration
+		redact   bool
+		token    string
+		prefixes []netip.Prefix
+	}
+)
+
+const (
+	// LivenessPath is the path of the liveness endpoint mounted by Mount.
+	LivenessPath = "/livez"
+	// ReadinessPath is the path of the readiness endpoint mounted by Mount.
+	ReadinessPath = "/readyz"
 )
 
 // Handler returns a HTTP handler that serves health check requests. The
 // response body is the JSON encoded health status returned by chk.Check(). The
-// response status is 200 if chk.Check() returns a nil error, 503 otherwise.
-func Handler(chk Checker) http.HandlerFunc {
+// response status is 200 if chk.Check() returns true, 503 otherwise. The
+// details of each check (latency, error, consecutive failures etc.) are only
+// included if the request has the "verbose" query string parameter set to "1"
+// or "true". Error messages can be removed from the details with
+// WithRedactedErrors. The status of each dependency and the check details can
+// be restricted to authorized clients with WithDetailsToken and
+// WithDetailsAllowedPrefixes, other clients only get the state of the service
+// and the response status.
+// Dependencies that do not respond before the deadline set with
+// WithHandlerTimeout are reported as unhealthy.
+func Handler(chk Checker, opts ...HandlerOption) http.HandlerFunc {
+	options := &handlerOptions{}
+	for _, o := range opts {
+		o(options)
+	}
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
-		h, healthy := chk.Check(r.Context())
+		ctx := r.Context()
+		if options.timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, options.timeout)
+			defer cancel()
+		}
+		h, healthy := chk.Check(ctx)
+		switch {
+		case !options.authorized(r):
+			h.Status, h.Checks, h.Groups = nil, nil, nil
+		case !isVerbose(r):
+			h.Checks = nil
+			walkGroups(h.Groups, func(g *GroupHealth) { g.Checks = nil })
+		case options.redact:
+			redact(h.Checks)
+			walkGroups(h.Groups, func(g *GroupHealth) { redact(g.Checks) })
+		}
 		b, _ := json.Marshal(h)
 		if healthy {
 			w.WriteHeader(http.StatusOK)
@@ -21,3 +82,150 @@ func Handler(chk Checker) http.HandlerFunc {
 		w.Write(b)
 	})
 }
+
+// Mount mounts the liveness handler under LivenessPath and the readiness
+// handler for chk configured with opts under ReadinessPath. If chk implements
+// Starter (e.g. checkers created with NewChecker) Mount also mounts the
+// startup handler under StartupPath.
+//
+// Example:
+//
+//	mux := http.NewServeMux()
+//	health.Mount(mux, health.NewChecker(db, cache))
+func Mount(mux Muxer, chk Checker, opts ...HandlerOption) {
+	mux.Handle(LivenessPath, LivenessHandler())
+	mux.Handle(ReadinessPath, ReadinessHandler(chk, opts...))
+	if s, ok := chk.(Starter); ok {
+		mux.Handle(StartupPath, StartupHandler(s))
+	}
+}
+
+// LivenessHandler returns a HTTP handler that serves liveness probe requests.
+// The handler always responds with status 200 and the JSON encoded uptime and
+// version of the service: liveness only reflects that the process is able to
+// serve requests so that orchestrators do not restart services whose
+// dependencies are unavailable.
+func LivenessHandler() http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		b, _ := json.Marshal(&Health{
+			Uptime:  int64(time.Since(StartedAt).Seconds()),
+			Version: Version,
+		})
+		w.WriteHeader(http.StatusOK)
+		w.Write(b)
+	})
+}
+
+// ReadinessHandler returns a HTTP handler that serves readiness probe
+// requests. The handler checks the dependencies of chk and responds with status
+// 200 if they are all healthy, 503 otherwise, see Handler.
+func ReadinessHandler(chk Checker, opts ...HandlerOption) http.HandlerFunc {
+	return Handler(chk, opts...)
+}
+
+// WithRedactedErrors removes the error messages from the check details
+// included in verbose responses so that the messages of failed checks, which
+// may contain sensitive information such as host names, are not exposed to
+// clients.
+func WithRedactedErrors() HandlerOption {
+	return func(o *handlerOptions) {
+		o.redact = true
+	}
+}
+
+// WithHandlerTimeout sets the overall deadline of the health checks made by the
+// handler. Set it below the probe timeout (1 second by default in Kubernetes)
+// so that a slow dependency results in a 503 response rather than in the probe
+// itself timing out. There is no deadline by default other than the request
+// context.
+func WithHandlerTimeout(timeout time.Duration) HandlerOption {
+	return func(o *handlerOptions) {
+		o.timeout = timeout
+	}
+}
+
+// WithDetailsToken restricts the status of each dependency and the check
+// details to requests that have an "Authorization" header set to "Bearer "
+// followed by token. The details expose the internal topology of the service
+// so should not be served to arbitrary clients. Requests that are not
+// authorized still get the response status and the state of the service so
+// that probes keep working. If WithDetailsAllowedPrefixes is also used then
+// requests that satisfy either condition are authorized.
+func WithDetailsToken(token string) HandlerOption {
+	return func(o *handlerOptions) {
+		o.token = token
+	}
+}
+
+// WithDetailsAllowedPrefixes restricts the status of each dependency and the
+// check details to requests whose remote address belongs to one of the given
+// network prefixes, see WithDetailsToken. The remote address is the address
+// of the connection, headers such as X-Forwarded-For are ignored.
+//
+// Example:
+//
+//	health.Handler(chk, health.WithDetailsAllowedPrefixes(
+//		netip.MustParsePrefix("10.0.0.0/8"),
+//		netip.MustParsePrefix("127.0.0.1/32"),
+//	))
+func WithDetailsAllowedPrefixes(prefixes ...netip.Prefix) HandlerOption {
+	return func(o *handlerOptions) {
+		o.prefixes = append(o.prefixes, prefixes...)
+	}
+}
+
+// authorized returns true if r may get the details of the health checks.
+func (o *handlerOptions) authorized(r *http.Request) bool {
+	if o.token == "" && len(o.prefixes) == 0 {
+		return true
+	}
+	if o.token != "" {
+		auth := r.Header.Get("Authorization")
+		if strings.HasPrefix(auth, "Bearer ") {
+			token := strings.TrimPrefix(auth, "Bearer ")
+			if subtle.ConstantTimeCompare([]byte(token), []byte(o.token)) == 1 {
+				return true
+			}
+		}
+	}
+	if len(o.prefixes) == 0 {
+		return false
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, p := range o.prefixes {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// walkGroups calls fn with each group of groups and of their nested groups.
+func walkGroups(groups map[string]*GroupHealth, fn func(*GroupHealth)) {
+	for _, g := range groups {
+		fn(g)
+		walkGroups(g.Groups, fn)
+	}
+}
+
+// redact removes the error messages from checks.
+func redact(checks map[string]*CheckResult) {
+	for _, c := range checks {
+		c.Error, c.LastError = "", ""
+	}
+}
+
+// isVerbose returns true if the request asks for the check details.
+func isVerbose(r *http.Request) bool {
+	v := r.URL.Query().Get("verbose")
+	return v == "1" || v == "true"
+}
@@ -1,10 +1,14 @@
 package health
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/netip"
 	"testing"
+	"time"
 )
 
 func TestHandler(t *testing.T) {
@@ -17,31 +21,49 @@ func TestHandler(t *testing.T) {
 		{
 			name:           "empty",
 			expectedStatus: http.StatusOK,
-			expectedJSON:   `{"uptime":0,"version":""}`,
+			expectedJSON:   `{"uptime":0,"version":"","state":"healthy"}`,
 		},
 		{
 			name:           "ok",
 			deps:           singleHealthyDep("dependency"),
 			expectedStatus: http.StatusOK,
-			expectedJSON:   `{"uptime":0,"version":"","status":{"dependency":"OK"}}`,
+			expectedJSON:   `{"uptime":0,"version":"","state":"healthy","status":{"dependency":"OK"}}`,
 		},
 		{
 			name:           "not ok",
 			deps:           singleUnhealthyDep("dependency", fmt.Errorf("dependency is not ok")),
 			expectedStatus: http.StatusServiceUnavailable,
-			expectedJSON:   `{"uptime":0,"version":"","status":{"dependency":"NOT OK"}}`,
+			expectedJSON:   `{"uptime":0,"version":"","state":"unhealthy","status":{"dependency":"NOT OK"}}`,
 		},
 		{
 			name:           "multiple dependencies",
 			deps:           multipleHealthyDeps("dependency1", "dependency2"),
 			expectedStatus: http.StatusOK,
-			expectedJSON:   `{"uptime":0,"version":"","status":{"dependency1":"OK","dependency2":"OK"}}`,
+			expectedJSON:   `{"uptime":0,"version":"","state":"healthy","status":{"dependency1":"OK","dependency2":"OK"}}`,
 		},
 		{
 			name:           "multiple dependencies not ok",
 			deps:           multipleUnhealthyDeps(fmt.Errorf("dependency2 is not ok"), "dependency1", "dependency2"),
 			expectedStatus: http.StatusServiceUnavailable,
-			expectedJSON:   `{"uptime":0,"version":"","status":{"dependency1":"OK","dependency2":"NOT OK"}}`,
+			expectedJSON:   `{"uptime":0,"version":"","state":"unhealthy","status":{"dependency1":"OK","dependency2":"NOT OK"}}`,
+		},
+		{
+			name:           "non-critical dependency not ok",
+			deps:           append(singleHealthyDep("dependency1"), NonCritical(singleUnhealthyDep("dependency2", fmt.Errorf("dependency2 is not ok"))[0])),
+			expectedStatus: http.StatusOK,
+			expectedJSON:   `{"uptime":0,"version":"","state":"degraded","status":{"dependency1":"OK","dependency2":"NOT OK"}}`,
+		},
+		{
+			name:           "informational dependency not ok",
+			deps:           append(singleHealthyDep("dependency1"), Informational(singleUnhealthyDep("dependency2", fmt.Errorf("dependency2 is not ok"))[0])),
+			expectedStatus: http.StatusOK,
+			expectedJSON:   `{"uptime":0,"version":"","state":"healthy","status":{"dependency1":"OK","dependency2":"NOT OK"}}`,
+		},
+		{
+			name:           "critical and non-critical dependencies not ok",
+			deps:           append(singleUnhealthyDep("dependency1", fmt.Errorf("dependency1 is not ok")), NonCritical(singleUnhealthyDep("dependency2", fmt.Errorf("dependency2 is not ok"))[0])),
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedJSON:   `{"uptime":0,"version":"","state":"unhealthy","status":{"dependency1":"NOT OK","dependency2":"NOT OK"}}`,
 		},
 	}
 	for _, c := range cases {
@@ -60,3 +82,146 @@ func TestHandler(t *testing.T) {
 		})
 	}
 }
+
+func TestMount(t *testing.T) {
+	mux := http.NewServeMux()
+	Mount(mux, NewChecker(singleUnhealthyDep("dependency", fmt.Errorf("dependency is not ok"))...))
+	cases := []struct {
+		name           string
+		path           string
+		expectedStatus int
+		expectedJSON   string
+	}{
+		{"liveness", LivenessPath, http.StatusOK, `{"uptime":0,"version":""}`},
+		{"readiness", ReadinessPath, http.StatusServiceUnavailable, `{"uptime":0,"version":"","state":"unhealthy","status":{"dependency":"NOT OK"}}`},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", c.path, nil)
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, req)
+			if w.Code != c.expectedStatus {
+				t.Errorf("got status: %d, expected %d", w.Code, c.expectedStatus)
+			}
+			if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+				t.Errorf("got content type: %s, expected application/json", ct)
+			}
+			if w.Body.String() != c.expectedJSON {
+				t.Errorf("got body: %s, expected %s", w.Body.String(), c.expectedJSON)
+			}
+		})
+	}
+}
+
+func TestHandlerTimeout(t *testing.T) {
+	block := make(chan struct{})
+	defer close(block)
+	slow := PingFunc("slow", func(ctx context.Context) error {
+		select {
+		case <-block:
+		case <-ctx.Done():
+		}
+		return ctx.Err()
+	})
+	chk := NewChecker(append(singleHealthyDep("fast"), slow)...)
+	handler := Handler(chk, WithHandlerTimeout(20*time.Millisecond))
+	w := httptest.NewRecorder()
+	start := time.Now()
+	handler(w, httptest.NewRequest("GET", "/readyz", nil))
+	if d := time.Since(start); d > time.Second {
+		t.Errorf("handler took %s, expected it to time out", d)
+	}
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("got status: %d, expected %d", w.Code, http.StatusServiceUnavailable)
+	}
+	expected := `{"uptime":0,"version":"","state":"unhealthy","status":{"fast":"OK","slow":"NOT OK"}}`
+	if w.Body.String() != expected {
+		t.Errorf("got body: %s, expected %s", w.Body.String(), expected)
+	}
+}
+
+func TestHandlerVerbose(t *testing.T) {
+	cases := []struct {
+		name          string
+		query         string
+		opts          []HandlerOption
+		expectedCheck bool
+		expectedError string
+	}{
+		{"compact", "", nil, false, ""},
+		{"verbose", "?verbose=1", nil, true, "dependency is not ok"},
+		{"verbose true", "?verbose=true", nil, true, "dependency is not ok"},
+		{"redacted", "?verbose=1", []HandlerOption{WithRedactedErrors()}, true, ""},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			chk := NewChecker(singleUnhealthyDep("dependency", fmt.Errorf("dependency is not ok"))...)
+			w := httptest.NewRecorder()
+			Handler(chk, c.opts...)(w, httptest.NewRequest("GET", "/readyz"+c.query, nil))
+			var h Health
+			if err := json.Unmarshal(w.Body.Bytes(), &h); err != nil {
+				t.Fatal(err)
+			}
+			check, ok := h.Checks["dependency"]
+			if ok != c.expectedCheck {
+				t.Fatalf("got check details: %v, expected %v", ok, c.expectedCheck)
+			}
+			if !ok {
+				return
+			}
+			if check.Error != c.expectedError || check.LastError != c.expectedError {
+				t.Errorf("got errors: %q, %q, expected %q", check.Error, check.LastError, c.expectedError)
+			}
+			if check.ConsecutiveFailures != 1 {
+				t.Errorf("got consecutive failures: %d, expected 1", check.ConsecutiveFailures)
+			}
+		})
+	}
+}
+
+func TestHandlerAccessControl(t *testing.T) {
+	const (
+		compact = `{"uptime":0,"version":"","state":"unhealthy"}`
+		details = `{"uptime":0,"version":"","state":"unhealthy","status":{"dependency":"NOT OK"}}`
+	)
+	cases := []struct {
+		name         string
+		opts         []HandlerOption
+		remoteAddr   string
+		auth         string
+		expectedJSON string
+	}{
+		{"no restriction", nil, "192.0.2.1:1234", "", details},
+		{"token missing", []HandlerOption{WithDetailsToken("secret")}, "192.0.2.1:1234", "", compact},
+		{"token invalid", []HandlerOption{WithDetailsToken("secret")}, "192.0.2.1:1234", "Bearer wrong", compact},
+		{"token valid", []HandlerOption{WithDetailsToken("secret")}, "192.0.2.1:1234", "Bearer secret", details},
+		{"prefix denied", []HandlerOption{WithDetailsAllowedPrefixes(netip.MustParsePrefix("10.0.0.0/8"))}, "192.0.2.1:1234", "", compact},
+		{"prefix allowed", []HandlerOption{WithDetailsAllowedPrefixes(netip.MustParsePrefix("10.0.0.0/8"))}, "10.1.2.3:1234", "", details},
+		{"prefix ipv6", []HandlerOption{WithDetailsAllowedPrefixes(netip.MustParsePrefix("::1/128"))}, "[::1]:1234", "", details},
+		{"either", []HandlerOption{WithDetailsToken("secret"), WithDetailsAllowedPrefixes(netip.MustParsePrefix("10.0.0.0/8"))}, "192.0.2.1:1234", "Bearer secret", details},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			chk := NewChecker(singleUnhealthyDep("dependency", fmt.Errorf("dependency is not ok"))...)
+			req := httptest.NewRequest("GET", "/readyz?verbose=1", nil)
+			req.RemoteAddr = c.remoteAddr
+			if c.auth != "" {
+				req.Header.Set("Authorization", c.auth)
+			}
+			w := httptest.NewRecorder()
+			Handler(chk, c.opts...)(w, req)
+			if w.Code != http.StatusServiceUnavailable {
+				t.Errorf("got status: %d, expected %d", w.Code, http.StatusServiceUnavailable)
+			}
+			var h Health
+			if err := json.Unmarshal(w.Body.Bytes(), &h); err != nil {
+				t.Fatal(err)
+			}
+			h.Checks = nil
+			b, _ := json.Marshal(h)
+			if string(b) != c.expectedJSON {
+				t.Errorf("got body: %s, expected %s", b, c.expectedJSON)
+			}
+		})
+	}
+}
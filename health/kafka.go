@@ -0,0 +1,139 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+type (
+	// KafkaClient retrieves the information used to check the health of a
+	// Kafka cluster. Implementations typically wrap the admin client of a
+	// Kafka client library (e.g. sarama or kafka-go).
+	KafkaClient interface {
+		// Metadata retrieves the cluster metadata for the given topics.
+		// It returns an error if no broker can be reached.
+		Metadata(ctx context.Context, topics ...string) (*KafkaMetadata, error)
+		// ConsumerLag returns the total lag of the consumer group on
+		// the given topic, that is the sum over all partitions of the
+		// difference between the latest offset and the committed
+		// offset. It is only called if WithKafkaConsumerLag is used.
+		ConsumerLag(ctx context.Context, group, topic string) (int64, error)
+	}
+
+	// KafkaMetadata is the Kafka cluster metadata used by the Kafka
+	// pinger.
+	KafkaMetadata struct {
+		// Brokers is the list of brokers of the cluster.
+		Brokers []string
+		// Partitions is the number of partitions with a leader of each
+		// requested topic indexed by topic name. Topics that do not
+		// exist must be omitted.
+		Partitions map[string]int
+	}
+
+	// KafkaOption configures a Kafka Pinger, see Kafka.
+	KafkaOption func(o *kafkaOptions)
+
+	// kafkaPinger is a Pinger that checks the health of a Kafka cluster.
+	kafkaPinger struct {
+		name    string
+		client  KafkaClient
+		options *kafkaOptions
+	}
+
+	kafkaOptions struct {
+		timeout time.Duration
+		topics  []string
+		lags    []*kafkaLag
+	}
+
+	// kafkaLag is a consumer group lag threshold.
+	kafkaLag struct {
+		group  string
+		topic  string
+		maxLag int64
+	}
+)
+
+// Kafka returns a Pinger that checks the health of a Kafka cluster. Ping fails
+// if the cluster metadata cannot be retrieved within the timeout (see
+// WithKafkaTimeout) or if the cluster has no broker. Options make it possible
+// to also require that topics are available (see WithKafkaTopics) and that
+// consumer groups keep up (see WithKafkaConsumerLag).
+//
+// Example:
+//
+//	chk := health.NewChecker(health.Kafka("Kafka", client,
+//		health.WithKafkaTopics("orders"),
+//		health.WithKafkaConsumerLag("order-processor", "orders", 10000)))
+func Kafka(name string, client KafkaClient, opts ...KafkaOption) Pinger {
+	options := &kafkaOptions{timeout: DefaultPingTimeout}
+	for _, o := range opts {
+		o(options)
+	}
+	return &kafkaPinger{name: name, client: client, options: options}
+}
+
+// WithKafkaTimeout sets the maximum duration of the Kafka checks. Default
+// timeout is DefaultPingTimeout.
+func WithKafkaTimeout(timeout time.Duration) KafkaOption {
+	return func(o *kafkaOptions) {
+		o.timeout = timeout
+	}
+}
+
+// WithKafkaTopics makes the pinger fail if any of the given topics does not
+// exist or has no partition with a leader.
+func WithKafkaTopics(topics ...string) KafkaOption {
+	return func(o *kafkaOptions) {
+		o.topics = append(o.topics, topics...)
+	}
+}
+
+// WithKafkaConsumerLag makes the pinger fail if the lag of the given consumer
+// group on the given topic exceeds maxLag messages. It may be used multiple
+// times to check multiple groups or topics.
+func WithKafkaConsumerLag(group, topic string, maxLag int64) KafkaOption {
+	return func(o *kafkaOptions) {
+		o.lags = append(o.lags, &kafkaLag{group: group, topic: topic, maxLag: maxLag})
+	}
+}
+
+func (p *kafkaPinger) Name() string {
+	return p.name
+}
+
+func (p *kafkaPinger) Ping(ctx context.Context) error {
+	if p.options.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.options.timeout)
+		defer cancel()
+	}
+	md, err := p.client.Metadata(ctx, p.options.topics...)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve metadata of Kafka %q: %w", p.name, err)
+	}
+	if len(md.Brokers) == 0 {
+		return fmt.Errorf("no broker available for Kafka %q", p.name)
+	}
+	for _, topic := range p.options.topics {
+		n, ok := md.Partitions[topic]
+		if !ok {
+			return fmt.Errorf("topic %q not found in Kafka %q", topic, p.name)
+		}
+		if n == 0 {
+			return fmt.Errorf("topic %q of Kafka %q has no available partition", topic, p.name)
+		}
+	}
+	for _, l := range p.options.lags {
+		lag, err := p.client.ConsumerLag(ctx, l.group, l.topic)
+		if err != nil {
+			return fmt.Errorf("failed to retrieve lag of consumer group %q on topic %q of Kafka %q: %w", l.group, l.topic, p.name, err)
+		}
+		if lag > l.maxLag {
+			return fmt.Errorf("lag of consumer group %q on topic %q of Kafka %q is %d, exceeds %d", l.group, l.topic, p.name, lag, l.maxLag)
+		}
+	}
+	return nil
+}
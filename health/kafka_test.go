@@ -0,0 +1,57 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestKafka(t *testing.T) {
+	md := &KafkaMetadata{Brokers: []string{"broker1:9092"}, Partitions: map[string]int{"orders": 3, "empty": 0}}
+	cases := []struct {
+		name        string
+		client      *mockKafkaClient
+		opts        []KafkaOption
+		expectedErr string
+	}{
+		{"ok", &mockKafkaClient{md: md}, nil, ""},
+		{"metadata error", &mockKafkaClient{err: errors.New("boom")}, nil, `failed to retrieve metadata of Kafka "kafka": boom`},
+		{"no broker", &mockKafkaClient{md: &KafkaMetadata{}}, nil, `no broker available for Kafka "kafka"`},
+		{"topic", &mockKafkaClient{md: md}, []KafkaOption{WithKafkaTopics("orders")}, ""},
+		{"missing topic", &mockKafkaClient{md: md}, []KafkaOption{WithKafkaTopics("orders", "payments")}, `topic "payments" not found in Kafka "kafka"`},
+		{"no partition", &mockKafkaClient{md: md}, []KafkaOption{WithKafkaTopics("empty")}, `topic "empty" of Kafka "kafka" has no available partition`},
+		{"lag", &mockKafkaClient{md: md, lag: 10}, []KafkaOption{WithKafkaConsumerLag("group", "orders", 10)}, ""},
+		{"lag exceeded", &mockKafkaClient{md: md, lag: 11}, []KafkaOption{WithKafkaConsumerLag("group", "orders", 10)}, `lag of consumer group "group" on topic "orders" of Kafka "kafka" is 11, exceeds 10`},
+		{"lag error", &mockKafkaClient{md: md, lagErr: errors.New("boom")}, []KafkaOption{WithKafkaConsumerLag("group", "orders", 10)}, `failed to retrieve lag of consumer group "group" on topic "orders" of Kafka "kafka": boom`},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			p := Kafka("kafka", c.client, c.opts...)
+			if p.Name() != "kafka" {
+				t.Errorf("got name %q, expected %q", p.Name(), "kafka")
+			}
+			err := p.Ping(context.Background())
+			if c.expectedErr == "" && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if c.expectedErr != "" && (err == nil || err.Error() != c.expectedErr) {
+				t.Errorf("got error %v, expected %q", err, c.expectedErr)
+			}
+		})
+	}
+}
+
+type mockKafkaClient struct {
+	md     *KafkaMetadata
+	err    error
+	lag    int64
+	lagErr error
+}
+
+func (c *mockKafkaClient) Metadata(context.Context, ...string) (*KafkaMetadata, error) {
+	return c.md, c.err
+}
+
+func (c *mockKafkaClient) ConsumerLag(context.Context, string, string) (int64, error) {
+	return c.lag, c.lagErr
+}
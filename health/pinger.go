@@ -3,8 +3,11 @@ package health
 import (
 	"context"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
+	"sync"
+	"time"
 )
 
 type (
@@ -21,21 +24,46 @@ type (
 	Option func(o *options)
 
 	client struct {
-		name string
-		req  *http.Request
+		name    string
+		req     *http.Request
+		options *options
+
+		lock     sync.Mutex
+		failures int       // number of consecutive failures
+		openedAt time.Time // time the circuit was opened
+		lastErr  error     // error returned while the circuit is open
 	}
 
 	options struct {
-		scheme string
-		path   string
+		scheme      string
+		path        string
+		httpClient  *http.Client
+		timeout     time.Duration
+		statuses    []int
+		validate    func([]byte) error
+		maxFailures int
+		cooldown    time.Duration
 	}
 )
 
+// maxBodySize is the maximum number of bytes of the response body read for
+// validation.
+const maxBodySize = 1 << 20
+
 // NewPinger returns a new health-check client for the given service. It panics
 // if the given host address is malformed.  The default scheme is "http" and the
-// default path is "/livez". Both can be overridden via options.
+// default path is "/livez". Both can be overridden via options. Ping fails if
+// the service does not respond within DefaultPingTimeout or if the response
+// status is not 2xx. Options make it possible to change the timeout and
+// expected status, to validate the response body and to stop pinging a
+// flapping service for a while, see WithCircuitBreaker.
 func NewPinger(name, addr string, opts ...Option) Pinger {
-	options := &options{scheme: "http", path: "/livez"}
+	options := &options{
+		scheme:     "http",
+		path:       "/livez",
+		httpClient: http.DefaultClient,
+		timeout:    DefaultPingTimeout,
+	}
 	for _, o := range opts {
 		o(options)
 	}
@@ -45,8 +73,9 @@ func NewPinger(name, addr string, opts ...Option) Pinger {
 		panic(err)
 	}
 	return &client{
-		name: name,
-		req:  req,
+		name:    name,
+		req:     req,
+		options: options,
 	}
 }
 
@@ -55,17 +84,88 @@ func (c *client) Name() string {
 }
 
 func (c *client) Ping(ctx context.Context) error {
-	resp, err := http.DefaultClient.Do(c.req.WithContext(ctx))
+	if err := c.open(); err != nil {
+		return err
+	}
+	err := c.ping(ctx)
+	c.record(err)
+	return err
+}
+
+// ping makes the health check request.
+func (c *client) ping(ctx context.Context) error {
+	if c.options.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.options.timeout)
+		defer cancel()
+	}
+	resp, err := c.options.httpClient.Do(c.req.WithContext(ctx))
 	if err != nil {
 		return fmt.Errorf("failed to make health check request to %q: %v", c.name, err)
 	}
 	defer resp.Body.Close()
-	if resp.StatusCode < 200 || resp.StatusCode > 300 {
+	if !c.expectedStatus(resp.StatusCode) {
 		return fmt.Errorf("health-check for %q returned status %d", c.name, resp.StatusCode)
 	}
+	if c.options.validate == nil {
+		return nil
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxBodySize))
+	if err != nil {
+		return fmt.Errorf("failed to read health-check response from %q: %v", c.name, err)
+	}
+	if err := c.options.validate(body); err != nil {
+		return fmt.Errorf("health-check for %q returned invalid response: %w", c.name, err)
+	}
 	return nil
 }
 
+// expectedStatus returns true if status is one of the expected statuses.
+func (c *client) expectedStatus(status int) bool {
+	if len(c.options.statuses) == 0 {
+		return status >= 200 && status < 300
+	}
+	for _, s := range c.options.statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// open returns the last error if the circuit is open, nil otherwise.
+func (c *client) open() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.options.maxFailures == 0 || c.failures < c.options.maxFailures {
+		return nil
+	}
+	if time.Since(c.openedAt) >= c.options.cooldown {
+		// Let one request through, the circuit opens again on failure.
+		c.openedAt = time.Now()
+		return nil
+	}
+	return fmt.Errorf("circuit open for %q: %w", c.name, c.lastErr)
+}
+
+// record updates the circuit state with the result of a request.
+func (c *client) record(err error) {
+	if c.options.maxFailures == 0 {
+		return
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if err == nil {
+		c.failures = 0
+		return
+	}
+	c.failures++
+	c.lastErr = err
+	if c.failures >= c.options.maxFailures {
+		c.openedAt = time.Now()
+	}
+}
+
 // WithScheme sets the scheme used to ping the service.
 // Default scheme is "http".
 func WithScheme(scheme string) Option {
@@ -81,3 +181,62 @@ func WithPath(path string) Option {
 		o.path = path
 	}
 }
+
+// WithHTTPClient sets the HTTP client used to ping the service.
+// Default client is http.DefaultClient.
+func WithHTTPClient(c *http.Client) Option {
+	return func(o *options) {
+		o.httpClient = c
+	}
+}
+
+// WithTimeout sets the maximum duration of the health check request including
+// reading the response body. Default timeout is DefaultPingTimeout.
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.timeout = timeout
+	}
+}
+
+// WithExpectedStatus sets the response statuses that indicate that the service
+// is healthy. Default is any 2xx status.
+func WithExpectedStatus(statuses ...int) Option {
+	return func(o *options) {
+		o.statuses = statuses
+	}
+}
+
+// WithBodyValidation sets a function that validates the response body of
+// healthy responses, the service is reported as unhealthy if it returns an
+// error. Only the first megabyte of the body is validated.
+//
+// Example:
+//
+//	health.NewPinger("svc", addr, health.WithBodyValidation(func(body []byte) error {
+//		var h health.Health
+//		if err := json.Unmarshal(body, &h); err != nil {
+//			return err
+//		}
+//		if h.Version != expectedVersion {
+//			return fmt.Errorf("unexpected version %q", h.Version)
+//		}
+//		return nil
+//	}))
+func WithBodyValidation(validate func(body []byte) error) Option {
+	return func(o *options) {
+		o.validate = validate
+	}
+}
+
+// WithCircuitBreaker stops pinging the service for the duration of cooldown
+// after maxFailures consecutive failures. Pings made while the circuit is open
+// fail with the last error without making a request so that a flapping
+// service does not get hammered with health checks. A single request is made
+// once the cooldown has elapsed, the circuit closes if it succeeds and opens
+// again otherwise. The circuit breaker is disabled by default.
+func WithCircuitBreaker(maxFailures int, cooldown time.Duration) Option {
+	return func(o *options) {
+		o.maxFailures = maxFailures
+		o.cooldown = cooldown
+	}
+}
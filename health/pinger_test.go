@@ -7,6 +7,7 @@ import (
 	"net/http/httptest"
 	"net/url"
 	"testing"
+	"time"
 )
 
 func TestPing(t *testing.T) {
@@ -91,3 +92,92 @@ func TestOptions(t *testing.T) {
 		})
 	}
 }
+
+func TestPingValidation(t *testing.T) {
+	cases := []struct {
+		name        string
+		status      int
+		body        string
+		opts        []Option
+		expectedErr string
+	}{
+		{"expected status", http.StatusNoContent, "", []Option{WithExpectedStatus(http.StatusOK, http.StatusNoContent)}, ""},
+		{"unexpected status", http.StatusOK, "", []Option{WithExpectedStatus(http.StatusNoContent)}, `health-check for "dependency" returned status 200`},
+		{"valid body", http.StatusOK, "ok", []Option{WithBodyValidation(expectBody("ok"))}, ""},
+		{"invalid body", http.StatusOK, "ko", []Option{WithBodyValidation(expectBody("ok"))}, `health-check for "dependency" returned invalid response: unexpected body "ko"`},
+		{"body not validated on error", http.StatusServiceUnavailable, "ok", []Option{WithBodyValidation(expectBody("ok"))}, `health-check for "dependency" returned status 503`},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(c.status)
+				_, _ = w.Write([]byte(c.body))
+			}))
+			defer svr.Close()
+			u, _ := url.Parse(svr.URL)
+			err := NewPinger("dependency", u.Host, c.opts...).Ping(context.Background())
+			if c.expectedErr == "" && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if c.expectedErr != "" && (err == nil || err.Error() != c.expectedErr) {
+				t.Errorf("got error: %v, expected: %s", err, c.expectedErr)
+			}
+		})
+	}
+}
+
+func TestPingTimeout(t *testing.T) {
+	done := make(chan struct{})
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-done
+	}))
+	defer svr.Close()
+	defer close(done)
+	u, _ := url.Parse(svr.URL)
+	err := NewPinger("dependency", u.Host, WithTimeout(10*time.Millisecond)).Ping(context.Background())
+	if err == nil {
+		t.Errorf("expected timeout error")
+	}
+}
+
+func TestPingCircuitBreaker(t *testing.T) {
+	var requests int
+	status := http.StatusServiceUnavailable
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(status)
+	}))
+	defer svr.Close()
+	u, _ := url.Parse(svr.URL)
+	pinger := NewPinger("dependency", u.Host, WithCircuitBreaker(2, 50*time.Millisecond))
+
+	for i := 0; i < 4; i++ {
+		if err := pinger.Ping(context.Background()); err == nil {
+			t.Fatalf("ping %d: expected error", i)
+		}
+	}
+	if requests != 2 {
+		t.Errorf("got %d requests while circuit is open, expected 2", requests)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	status = http.StatusOK
+	if err := pinger.Ping(context.Background()); err != nil {
+		t.Errorf("unexpected error after cooldown: %v", err)
+	}
+	if err := pinger.Ping(context.Background()); err != nil {
+		t.Errorf("unexpected error after circuit closed: %v", err)
+	}
+	if requests != 4 {
+		t.Errorf("got %d requests after circuit closed, expected 4", requests)
+	}
+}
+
+func expectBody(expected string) func([]byte) error {
+	return func(body []byte) error {
+		if string(body) != expected {
+			return fmt.Errorf("unexpected body %q", body)
+		}
+		return nil
+	}
+}
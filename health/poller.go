@@ -0,0 +1,217 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"goa.design/clue/clock"
+	"goa.design/clue/log"
+)
+
+type (
+	// pollingChecker is a Checker that checks the health of its dependencies in
+	// the background and serves the cached results.
+	pollingChecker struct {
+		*checker
+		interval time.Duration
+
+		inflightLock sync.Mutex
+		inflight     map[string]bool
+	}
+
+	// ttlPinger is a Pinger whose results are reused for a given duration.
+	ttlPinger struct {
+		Pinger
+		ttl time.Duration
+	}
+)
+
+// NewPollingChecker creates a Checker that checks the health of the given
+// dependencies every interval in the background instead of on each call to
+// Check. Check returns the cached results. Dependencies that have not been
+// checked yet are reported as unhealthy. The first check starts immediately
+// and polling stops when ctx is canceled. Dependencies wrapped with CacheTTL
+// are checked at most once per TTL.
+//
+// Pings are canceled after interval unless a timeout is set with PingTimeout.
+// Dependencies that have not responded by then are reported as unhealthy and
+// are not pinged again until the pending ping returns. Results older than
+// twice the interval (or twice the TTL of dependencies wrapped with CacheTTL)
+// are reported as unhealthy so that a stuck polling loop does not keep serving
+// stale results. The polling interval and TTLs are measured
+// with the clock stored in ctx if any (see clock.Context) so that tests can
+// drive polling with a fake clock.
+//
+// Example:
+//
+//	chk := health.NewPollingChecker(ctx, 10*time.Second, db, cache)
+//	health.Mount(mux, chk)
+func NewPollingChecker(ctx context.Context, interval time.Duration, deps ...Pinger) Registry {
+	c := &pollingChecker{
+		checker:  newChecker(clock.FromContext(ctx)),
+		interval: interval,
+		inflight: make(map[string]bool),
+	}
+	c.maxAge = c.staleAfter
+	c.Register(deps...)
+	go c.poll(ctx)
+	return c
+}
+
+func (c *pollingChecker) Check(ctx context.Context) (*Health, bool) {
+	c.lock.RLock()
+	deps := c.deps
+	c.lock.RUnlock()
+	return c.health(deps)
+}
+
+// poll checks the dependencies every interval until ctx is canceled.
+func (c *pollingChecker) poll(ctx context.Context) {
+	ticker := c.clock.NewTicker(c.interval)
+	defer ticker.Stop()
+	for {
+		c.checkAll(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C():
+		}
+	}
+}
+
+// checkAll checks all the dependencies concurrently and records the results.
+// Dependencies that do not respond before their ping timeout are recorded as
+// unhealthy. It then computes the state of the service so that transition
+// hooks get called even when the service is not probed.
+func (c *pollingChecker) checkAll(ctx context.Context) {
+	c.lock.RLock()
+	deps := c.deps
+	c.lock.RUnlock()
+	results := make(chan pingResult, len(deps))
+	pending := make(map[string]bool)
+	var wait time.Duration
+	for _, dep := range deps {
+		if c.fresh(dep) || !c.startPing(dep.Name()) {
+			continue
+		}
+		timeout := c.timeout(dep)
+		if timeout > wait {
+			wait = timeout
+		}
+		pending[dep.Name()] = true
+		go func(dep Pinger) {
+			defer c.endPing(dep.Name())
+			// Note: use a new context for each dependency so that
+			// one dependency canceling the context does not affect
+			// the other checks.
+			pingCtx, cancel := context.WithTimeout(ctx, c.timeout(dep))
+			defer cancel()
+			results <- c.ping(pingCtx, dep)
+		}(dep)
+	}
+	waitCtx, cancel := context.WithTimeout(ctx, wait)
+	defer cancel()
+loop:
+	for len(pending) > 0 {
+		select {
+		case r := <-results:
+			delete(pending, r.name)
+			if r.err != nil {
+				log.Error(ctx, r.err, log.KV{K: "msg", V: "ping failed"}, log.KV{K: "dep", V: r.name})
+			}
+			c.record(r)
+		case <-waitCtx.Done():
+			break loop
+		}
+	}
+	if ctx.Err() != nil {
+		return
+	}
+	for name := range pending {
+		err := fmt.Errorf("health check timed out after %s", wait)
+		log.Error(ctx, err, log.KV{K: "msg", V: "ping failed"}, log.KV{K: "dep", V: name})
+		c.record(pingResult{name: name, err: err, latency: wait})
+	}
+	c.health(deps)
+}
+
+// timeout returns the ping timeout of dep: the timeout set with PingTimeout
+// if any, the polling interval otherwise.
+func (c *pollingChecker) timeout(dep Pinger) time.Duration {
+	if t := timeoutOf(dep); t > 0 {
+		return t
+	}
+	return c.interval
+}
+
+// staleAfter returns the age after which the last result of dep is reported
+// as unhealthy: twice the polling interval or twice the TTL of dep if longer.
+func (c *pollingChecker) staleAfter(dep Pinger) time.Duration {
+	if ttl := TTLOf(dep); ttl > c.interval {
+		return 2 * ttl
+	}
+	return 2 * c.interval
+}
+
+// startPing marks the ping of the dependency with the given name as in
+// flight, it returns false if it already was.
+func (c *pollingChecker) startPing(name string) bool {
+	c.inflightLock.Lock()
+	defer c.inflightLock.Unlock()
+	if c.inflight[name] {
+		return false
+	}
+	c.inflight[name] = true
+	return true
+}
+
+// endPing marks the ping of the dependency with the given name as done.
+func (c *pollingChecker) endPing(name string) {
+	c.inflightLock.Lock()
+	defer c.inflightLock.Unlock()
+	delete(c.inflight, name)
+}
+
+// fresh returns true if the last result of dep is more recent than its TTL.
+func (c *pollingChecker) fresh(dep Pinger) bool {
+	ttl := TTLOf(dep)
+	if ttl <= 0 {
+		return false
+	}
+	c.resultsLock.Lock()
+	defer c.resultsLock.Unlock()
+	r, ok := c.results[dep.Name()]
+	return ok && c.clock.Since(r.LastChecked) < ttl
+}
+
+// CacheTTL returns a Pinger that a polling checker checks at most once per
+// ttl, reusing the last result in between. This makes it possible for
+// expensive checks (e.g. full downstream round trips) to run less often than
+// cheap ones within the same polling loop. Checkers created with NewChecker
+// ignore the TTL.
+//
+// Example:
+//
+//	chk := health.NewPollingChecker(ctx, 5*time.Second, db, health.CacheTTL(svc, time.Minute))
+func CacheTTL(p Pinger, ttl time.Duration) Pinger {
+	if tp, ok := p.(*ttlPinger); ok {
+		p = tp.Pinger
+	}
+	return &ttlPinger{Pinger: p, ttl: ttl}
+}
+
+// TTLOf returns the TTL of p, see CacheTTL. Pingers may also implement a
+// "TTL() time.Duration" method to declare their TTL.
+func TTLOf(p Pinger) time.Duration {
+	if t, ok := p.(interface{ TTL() time.Duration }); ok {
+		return t.TTL()
+	}
+	return 0
+}
+
+func (p *ttlPinger) TTL() time.Duration         { return p.ttl }
+func (p *ttlPinger) Criticality() Criticality   { return CriticalityOf(p.Pinger) }
+func (p *ttlPinger) PingTimeout() time.Duration { return timeoutOf(p.Pinger) }
+func (p *ttlPinger) groupHealth() *GroupHealth  { return groupOf(p.Pinger) }
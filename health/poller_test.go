@@ -0,0 +1,208 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"goa.design/clue/clock"
+)
+
+func TestPollingChecker(t *testing.T) {
+	var healthy atomic.Bool
+	var pings atomic.Int32
+	dep := PingFunc("dependency", func(context.Context) error {
+		pings.Add(1)
+		if healthy.Load() {
+			return nil
+		}
+		return errors.New("dependency is not ok")
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	chk := NewPollingChecker(ctx, 10*time.Millisecond, dep)
+
+	waitFor(t, func() bool { res, _ := chk.Check(ctx); return res.Checks["dependency"] != nil })
+	res, _ := chk.Check(ctx)
+	r := res.Checks["dependency"]
+	if r.LastError != "dependency is not ok" {
+		t.Errorf("got last error: %q, expected %q", r.LastError, "dependency is not ok")
+	}
+	if r.LastFailure == nil || r.LastChecked.IsZero() {
+		t.Errorf("expected last checked and last failure to be set, got %+v", r)
+	}
+	if res.Status["dependency"] != "NOT OK" {
+		t.Errorf("got status: %s, expected NOT OK", res.Status["dependency"])
+	}
+
+	healthy.Store(true)
+	waitFor(t, func() bool { _, ok := chk.Check(ctx); return ok })
+	res, _ = chk.Check(ctx)
+	r = res.Checks["dependency"]
+	if res.Status["dependency"] != "OK" {
+		t.Errorf("got status: %s, expected OK", res.Status["dependency"])
+	}
+	if r.LastError != "dependency is not ok" || r.LastFailure == nil {
+		t.Errorf("expected last failure to be kept, got %+v", r)
+	}
+	if !r.LastChecked.After(*r.LastFailure) {
+		t.Errorf("expected last checked %v to be after last failure %v", r.LastChecked, *r.LastFailure)
+	}
+
+	cancel()
+	time.Sleep(20 * time.Millisecond)
+	n := pings.Load()
+	time.Sleep(50 * time.Millisecond)
+	if pings.Load() != n {
+		t.Errorf("expected polling to stop when the context is canceled")
+	}
+}
+
+func TestPollingCheckerNotChecked(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	block := make(chan struct{})
+	defer close(block)
+	dep := PingFunc("dependency", func(context.Context) error { <-block; return nil })
+	chk := NewPollingChecker(ctx, time.Hour, dep)
+	res, healthy := chk.Check(ctx)
+	if healthy {
+		t.Errorf("expected dependencies that have not been checked to be unhealthy")
+	}
+	if res.Status["dependency"] != "NOT OK" {
+		t.Errorf("got status: %s, expected NOT OK", res.Status["dependency"])
+	}
+}
+
+// waitFor waits for cond to be true and fails the test after one second.
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("timeout waiting for condition")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestCacheTTL(t *testing.T) {
+	var cheap, expensive atomic.Int32
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	chk := NewPollingChecker(ctx, 5*time.Millisecond,
+		PingFunc("cheap", func(context.Context) error { cheap.Add(1); return nil }),
+		NonCritical(CacheTTL(PingFunc("expensive", func(context.Context) error { expensive.Add(1); return nil }), time.Hour)),
+	)
+	waitFor(t, func() bool { return cheap.Load() >= 5 })
+	if n := expensive.Load(); n != 1 {
+		t.Errorf("got %d expensive pings, expected 1", n)
+	}
+	res, ok := chk.Check(ctx)
+	if !ok || res.Status["expensive"] != "OK" {
+		t.Errorf("expected cached result to be reported, got %v", res.Status)
+	}
+}
+
+func TestPollingCheckerClock(t *testing.T) {
+	var cheap, expensive atomic.Int32
+	clk := clock.NewFake(time.Date(2022, time.January, 9, 20, 29, 45, 0, time.UTC))
+	ctx, cancel := context.WithCancel(clock.Context(context.Background(), clk))
+	defer cancel()
+	// Note: the expensive dependency is registered first so that its TTL is
+	// evaluated before the cheap dependency is pinged.
+	chk := NewPollingChecker(ctx, time.Minute,
+		CacheTTL(PingFunc("expensive", func(context.Context) error { expensive.Add(1); return nil }), 2*time.Minute),
+		PingFunc("cheap", func(context.Context) error { cheap.Add(1); return nil }),
+	)
+	waitFor(t, func() bool { return cheap.Load() == 1 })
+	clk.BlockUntil(1)
+
+	clk.Advance(time.Minute)
+	waitFor(t, func() bool { return cheap.Load() == 2 })
+	if n := expensive.Load(); n != 1 {
+		t.Errorf("got %d expensive pings, expected 1", n)
+	}
+
+	clk.Advance(time.Minute)
+	waitFor(t, func() bool { return cheap.Load() == 3 })
+	waitFor(t, func() bool { return expensive.Load() == 2 })
+	res, _ := chk.Check(ctx)
+	if got := res.Checks["expensive"].LastChecked; !got.Equal(clk.Now()) {
+		t.Errorf("got last checked %v, expected %v", got, clk.Now())
+	}
+}
+
+func TestPollingCheckerTimeout(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	block := make(chan struct{})
+	defer close(block)
+	var pings atomic.Int32
+	chk := NewPollingChecker(ctx, 10*time.Millisecond,
+		PingFunc("hung", func(context.Context) error { <-block; return nil }),
+		PingFunc("healthy", func(context.Context) error { pings.Add(1); return nil }),
+	)
+	waitFor(t, func() bool { res, _ := chk.Check(ctx); return res.Checks["hung"] != nil })
+	res, ok := chk.Check(ctx)
+	if ok {
+		t.Errorf("expected hung dependency to be unhealthy")
+	}
+	if got := res.Checks["hung"].Error; !strings.Contains(got, "timed out") {
+		t.Errorf("got error %q, expected timeout", got)
+	}
+	waitFor(t, func() bool { return pings.Load() >= 3 })
+}
+
+func TestPollingCheckerStale(t *testing.T) {
+	clk := clock.NewFake(time.Date(2022, time.January, 9, 20, 29, 45, 0, time.UTC))
+	ctx, cancel := context.WithCancel(clock.Context(context.Background(), clk))
+	defer cancel()
+	block := make(chan struct{})
+	defer close(block)
+	var pings atomic.Int32
+	chk := NewPollingChecker(ctx, time.Minute, PingFunc("dependency", func(context.Context) error {
+		if pings.Add(1) > 1 {
+			<-block
+		}
+		return nil
+	}))
+	waitFor(t, func() bool { _, ok := chk.Check(ctx); return ok })
+	clk.BlockUntil(1)
+
+	clk.Advance(time.Minute)
+	waitFor(t, func() bool { return pings.Load() == 2 })
+	clk.Advance(2 * time.Minute)
+	res, ok := chk.Check(ctx)
+	if ok {
+		t.Errorf("expected stale result to be unhealthy")
+	}
+	if got := res.Checks["dependency"].Error; !strings.Contains(got, "stale") {
+		t.Errorf("got error %q, expected stale result", got)
+	}
+}
+
+func TestTTLOf(t *testing.T) {
+	dep := singleHealthyDep("dependency")[0]
+	cases := []struct {
+		name     string
+		pinger   Pinger
+		expected time.Duration
+	}{
+		{"default", dep, 0},
+		{"ttl", CacheTTL(dep, time.Minute), time.Minute},
+		{"override", CacheTTL(CacheTTL(dep, time.Minute), time.Second), time.Second},
+		{"criticality", NonCritical(CacheTTL(dep, time.Minute)), time.Minute},
+		{"timeout", PingTimeout(CacheTTL(dep, time.Minute), time.Second), time.Minute},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := TTLOf(c.pinger); got != c.expected {
+				t.Errorf("got TTL %s, expected %s", got, c.expected)
+			}
+		})
+	}
+}
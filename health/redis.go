@@ -0,0 +1,119 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+type (
+	// RedisOption configures a Redis Pinger, see Redis.
+	RedisOption func(o *redisOptions)
+
+	// redisPinger is a Pinger that checks the health of a Redis server.
+	redisPinger struct {
+		name    string
+		client  redis.UniversalClient
+		options *redisOptions
+	}
+
+	redisOptions struct {
+		timeout     time.Duration
+		replication bool
+		cluster     bool
+	}
+)
+
+// Redis returns a Pinger that checks the health of the Redis server the given
+// client is connected to by sending a PING command. Ping fails if the server
+// does not respond within the timeout (see WithRedisTimeout). The replication
+// and cluster status can also be validated, see WithRedisReplicationCheck and
+// WithRedisClusterCheck.
+//
+// Example:
+//
+//	rdb := redis.NewClient(&redis.Options{Addr: addr})
+//	chk := health.NewChecker(health.Redis("Redis", rdb, health.WithRedisReplicationCheck()))
+func Redis(name string, client redis.UniversalClient, opts ...RedisOption) Pinger {
+	options := &redisOptions{timeout: DefaultPingTimeout}
+	for _, o := range opts {
+		o(options)
+	}
+	return &redisPinger{name: name, client: client, options: options}
+}
+
+// WithRedisTimeout sets the maximum duration of the Redis ping including the
+// replication and cluster checks. Default timeout is DefaultPingTimeout.
+func WithRedisTimeout(timeout time.Duration) RedisOption {
+	return func(o *redisOptions) {
+		o.timeout = timeout
+	}
+}
+
+// WithRedisReplicationCheck makes the pinger fail if the server is a replica
+// whose link to its master is down as reported by "INFO replication".
+func WithRedisReplicationCheck() RedisOption {
+	return func(o *redisOptions) {
+		o.replication = true
+	}
+}
+
+// WithRedisClusterCheck makes the pinger fail if the cluster state reported by
+// "CLUSTER INFO" is not "ok".
+func WithRedisClusterCheck() RedisOption {
+	return func(o *redisOptions) {
+		o.cluster = true
+	}
+}
+
+func (p *redisPinger) Name() string {
+	return p.name
+}
+
+func (p *redisPinger) Ping(ctx context.Context) error {
+	if p.options.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.options.timeout)
+		defer cancel()
+	}
+	if err := p.client.Ping(ctx).Err(); err != nil {
+		return fmt.Errorf("failed to ping Redis %q: %w", p.name, err)
+	}
+	if p.options.replication {
+		info, err := p.client.Info(ctx, "replication").Result()
+		if err != nil {
+			return fmt.Errorf("failed to retrieve replication info of Redis %q: %w", p.name, err)
+		}
+		fields := redisInfoFields(info)
+		if fields["role"] == "slave" && fields["master_link_status"] != "up" {
+			return fmt.Errorf("replica link to master of Redis %q is %q", p.name, fields["master_link_status"])
+		}
+	}
+	if p.options.cluster {
+		info, err := p.client.ClusterInfo(ctx).Result()
+		if err != nil {
+			return fmt.Errorf("failed to retrieve cluster info of Redis %q: %w", p.name, err)
+		}
+		if state := redisInfoFields(info)["cluster_state"]; state != "ok" {
+			return fmt.Errorf("cluster state of Redis %q is %q", p.name, state)
+		}
+	}
+	return nil
+}
+
+// redisInfoFields parses the "field:value" lines returned by the INFO and
+// CLUSTER INFO commands.
+func redisInfoFields(info string) map[string]string {
+	fields := make(map[string]string)
+	for _, line := range strings.Split(info, "\n") {
+		k, v, ok := strings.Cut(strings.TrimSpace(line), ":")
+		if !ok || strings.HasPrefix(k, "#") {
+			continue
+		}
+		fields[k] = v
+	}
+	return fields
+}
@@ -0,0 +1,104 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+func TestRedis(t *testing.T) {
+	cases := []struct {
+		name        string
+		pingErr     error
+		replication string
+		cluster     string
+		opts        []RedisOption
+		expectedErr string
+	}{
+		{"ok", nil, "", "", nil, ""},
+		{"ping fails", errors.New("boom"), "", "", nil, `failed to ping Redis "redis": boom`},
+		{"master", nil, "# Replication\r\nrole:master\r\n", "", []RedisOption{WithRedisReplicationCheck()}, ""},
+		{"replica up", nil, "# Replication\r\nrole:slave\r\nmaster_link_status:up\r\n", "", []RedisOption{WithRedisReplicationCheck()}, ""},
+		{"replica down", nil, "# Replication\r\nrole:slave\r\nmaster_link_status:down\r\n", "", []RedisOption{WithRedisReplicationCheck()}, `replica link to master of Redis "redis" is "down"`},
+		{"replication not checked", nil, "role:slave\r\nmaster_link_status:down\r\n", "", nil, ""},
+		{"cluster ok", nil, "", "cluster_state:ok\r\ncluster_slots_assigned:16384\r\n", []RedisOption{WithRedisClusterCheck()}, ""},
+		{"cluster fail", nil, "", "cluster_state:fail\r\n", []RedisOption{WithRedisClusterCheck()}, `cluster state of Redis "redis" is "fail"`},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			rdb := redis.NewClient(&redis.Options{Addr: "localhost:0"})
+			defer func() { _ = rdb.Close() }()
+			rdb.AddHook(&fakeRedis{pingErr: c.pingErr, replication: c.replication, cluster: c.cluster})
+			p := Redis("redis", rdb, c.opts...)
+			if p.Name() != "redis" {
+				t.Errorf("got name %q, expected %q", p.Name(), "redis")
+			}
+			err := p.Ping(context.Background())
+			if c.expectedErr == "" && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if c.expectedErr != "" && (err == nil || err.Error() != c.expectedErr) {
+				t.Errorf("got error %v, expected %q", err, c.expectedErr)
+			}
+		})
+	}
+}
+
+func TestRedisTimeout(t *testing.T) {
+	rdb := redis.NewClient(&redis.Options{Addr: "localhost:0"})
+	defer func() { _ = rdb.Close() }()
+	rdb.AddHook(&fakeRedis{block: true})
+	p := Redis("redis", rdb, WithRedisTimeout(10*time.Millisecond))
+	if err := p.Ping(context.Background()); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got error %v, expected deadline exceeded", err)
+	}
+}
+
+// fakeRedis is a go-redis hook that answers commands without calling the
+// server.
+type fakeRedis struct {
+	pingErr     error
+	replication string
+	cluster     string
+	block       bool
+}
+
+func (f *fakeRedis) DialHook(next redis.DialHook) redis.DialHook {
+	return func(context.Context, string, string) (net.Conn, error) {
+		return nil, errors.New("unexpected dial")
+	}
+}
+
+func (f *fakeRedis) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		if f.block {
+			<-ctx.Done()
+			cmd.SetErr(ctx.Err())
+			return ctx.Err()
+		}
+		switch c := cmd.(type) {
+		case *redis.StatusCmd:
+			if f.pingErr != nil {
+				c.SetErr(f.pingErr)
+				return f.pingErr
+			}
+			c.SetVal("PONG")
+		case *redis.StringCmd:
+			if strings.EqualFold(c.Name(), "info") {
+				c.SetVal(f.replication)
+			} else {
+				c.SetVal(f.cluster)
+			}
+		}
+		return nil
+	}
+}
+
+func (f *fakeRedis) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return next
+}
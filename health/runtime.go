@@ -0,0 +1,86 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+)
+
+// runtimePinger is a non-critical Pinger that checks a runtime resource
+// against a threshold.
+type runtimePinger struct {
+	name  string
+	check func() error
+}
+
+// Goroutines returns a Pinger named "goroutines" that fails when the number of
+// goroutines exceeds max. Like the other runtime pingers it is non-critical so
+// that it reports the service as degraded before it actually falls over, use
+// WithCriticality to change this.
+func Goroutines(max int) Pinger {
+	return &runtimePinger{
+		name: "goroutines",
+		check: func() error {
+			if n := runtime.NumGoroutine(); n > max {
+				return fmt.Errorf("%d goroutines, exceeds %d", n, max)
+			}
+			return nil
+		},
+	}
+}
+
+// HeapUsage returns a non-critical Pinger named "heap" that fails when the
+// number of bytes allocated on the heap exceeds max, see Goroutines.
+func HeapUsage(max uint64) Pinger {
+	return &runtimePinger{
+		name: "heap",
+		check: func() error {
+			var ms runtime.MemStats
+			runtime.ReadMemStats(&ms)
+			if ms.HeapAlloc > max {
+				return fmt.Errorf("%d heap bytes allocated, exceeds %d", ms.HeapAlloc, max)
+			}
+			return nil
+		},
+	}
+}
+
+// FileDescriptors returns a non-critical Pinger named "file_descriptors" that
+// fails when the ratio of open file descriptors to the process limit exceeds
+// maxRatio (between 0 and 1), see Goroutines. The pinger always fails on
+// platforms that do not support counting file descriptors (e.g. Windows).
+func FileDescriptors(maxRatio float64) Pinger {
+	return &runtimePinger{
+		name: "file_descriptors",
+		check: func() error {
+			open, limit, err := fdUsage()
+			if err != nil {
+				return fmt.Errorf("failed to retrieve file descriptor usage: %w", err)
+			}
+			if limit == 0 {
+				return nil
+			}
+			if ratio := float64(open) / float64(limit); ratio > maxRatio {
+				return fmt.Errorf("%d file descriptors open out of %d, exceeds %.1f%%", open, limit, maxRatio*100)
+			}
+			return nil
+		},
+	}
+}
+
+func (p *runtimePinger) Name() string {
+	return p.name
+}
+
+func (p *runtimePinger) Ping(context.Context) error {
+	return p.check()
+}
+
+// Criticality returns CriticalityNonCritical.
+func (p *runtimePinger) Criticality() Criticality {
+	return CriticalityNonCritical
+}
+
+// fdUsage is the function used to retrieve the number of open file descriptors
+// and the process limit, overridden in tests.
+var fdUsage = fileDescriptorUsage
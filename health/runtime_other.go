@@ -0,0 +1,11 @@
+//go:build !unix
+
+package health
+
+import "errors"
+
+// fileDescriptorUsage returns an error as counting file descriptors is not
+// supported on this platform.
+func fileDescriptorUsage() (open, limit uint64, err error) {
+	return 0, 0, errors.New("file descriptor usage not supported on this platform")
+}
@@ -0,0 +1,77 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRuntimePingers(t *testing.T) {
+	cases := []struct {
+		name         string
+		pinger       Pinger
+		expectedName string
+		expectedErr  string
+	}{
+		{"goroutines", Goroutines(1 << 20), "goroutines", ""},
+		{"too many goroutines", Goroutines(0), "goroutines", "goroutines, exceeds 0"},
+		{"heap", HeapUsage(1 << 40), "heap", ""},
+		{"heap exceeded", HeapUsage(1), "heap", "heap bytes allocated, exceeds 1"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if c.pinger.Name() != c.expectedName {
+				t.Errorf("got name %q, expected %q", c.pinger.Name(), c.expectedName)
+			}
+			if got := CriticalityOf(c.pinger); got != CriticalityNonCritical {
+				t.Errorf("got criticality %q, expected %q", got, CriticalityNonCritical)
+			}
+			err := c.pinger.Ping(context.Background())
+			if c.expectedErr == "" && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if c.expectedErr != "" && (err == nil || !strings.Contains(err.Error(), c.expectedErr)) {
+				t.Errorf("got error %v, expected it to contain %q", err, c.expectedErr)
+			}
+		})
+	}
+}
+
+func TestFileDescriptors(t *testing.T) {
+	cases := []struct {
+		name        string
+		open, limit uint64
+		err         error
+		expectedErr string
+	}{
+		{"ok", 50, 100, nil, ""},
+		{"exceeded", 81, 100, nil, "81 file descriptors open out of 100, exceeds 80.0%"},
+		{"unlimited", 81, 0, nil, ""},
+		{"error", 0, 0, errors.New("boom"), "failed to retrieve file descriptor usage: boom"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			restore := fdUsage
+			defer func() { fdUsage = restore }()
+			fdUsage = func() (uint64, uint64, error) { return c.open, c.limit, c.err }
+			err := FileDescriptors(0.8).Ping(context.Background())
+			if c.expectedErr == "" && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if c.expectedErr != "" && (err == nil || err.Error() != c.expectedErr) {
+				t.Errorf("got error %v, expected %q", err, c.expectedErr)
+			}
+		})
+	}
+}
+
+func TestFileDescriptorUsage(t *testing.T) {
+	open, limit, err := fileDescriptorUsage()
+	if err != nil {
+		t.Skipf("file descriptor usage not supported: %v", err)
+	}
+	if open == 0 || limit == 0 {
+		t.Errorf("unexpected file descriptor usage: %d open, %d limit", open, limit)
+	}
+}
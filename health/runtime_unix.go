@@ -0,0 +1,22 @@
+//go:build unix
+
+package health
+
+import (
+	"os"
+	"syscall"
+)
+
+// fileDescriptorUsage returns the number of open file descriptors and the
+// process soft limit.
+func fileDescriptorUsage() (open, limit uint64, err error) {
+	var rl syscall.Rlimit
+	if err := syscall.Getrlimit(syscall.RLIMIT_NOFILE, &rl); err != nil {
+		return 0, 0, err
+	}
+	entries, err := os.ReadDir("/dev/fd")
+	if err != nil {
+		return 0, 0, err
+	}
+	return uint64(len(entries)), uint64(rl.Cur), nil
+}
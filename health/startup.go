@@ -0,0 +1,117 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"goa.design/clue/log"
+)
+
+// warmup is a function that must complete before the service is ready.
+type warmup struct {
+	name string
+	fn   func(context.Context) error
+	done bool
+}
+
+// StartupPath is the path of the startup endpoint mounted by Mount.
+const StartupPath = "/startupz"
+
+// RegisterWarmup adds a function that must complete successfully before the
+// service is ready, e.g. to prime a cache or fetch configuration. The checker
+// reports the service as starting (StateStarting) and readiness checks fail
+// until Start runs all the registered functions successfully.
+func (c *checker) RegisterWarmup(name string, fn func(context.Context) error) {
+	c.warmupLock.Lock()
+	defer c.warmupLock.Unlock()
+	c.warmups = append(c.warmups, &warmup{name: name, fn: fn})
+}
+
+// Start runs the registered warmup functions that have not completed yet
+// concurrently and waits for them to return. It returns the first error if
+// any, Start may then be called again to retry the failed functions.
+//
+// Example:
+//
+//	chk := health.NewChecker(db)
+//	chk.RegisterWarmup("cache", primeCache)
+//	health.Mount(mux, chk)
+//	go serve(mux)
+//	if err := chk.Start(ctx); err != nil {
+//		log.Fatal(ctx, err)
+//	}
+func (c *checker) Start(ctx context.Context) error {
+	c.warmupLock.Lock()
+	var pending []*warmup
+	for _, w := range c.warmups {
+		if !w.done {
+			pending = append(pending, w)
+		}
+	}
+	c.warmupLock.Unlock()
+
+	errs := make([]error, len(pending))
+	var wg sync.WaitGroup
+	for i, w := range pending {
+		wg.Add(1)
+		go func(i int, w *warmup) {
+			defer wg.Done()
+			start := c.clock.Now()
+			if err := w.fn(ctx); err != nil {
+				errs[i] = fmt.Errorf("warmup %q failed: %w", w.name, err)
+				return
+			}
+			c.warmupLock.Lock()
+			w.done = true
+			c.warmupLock.Unlock()
+			log.Info(ctx, log.KV{K: "msg", V: "warmup complete"}, log.KV{K: "warmup", V: w.name}, log.KV{K: "duration", V: c.clock.Since(start).String()})
+		}(i, w)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Started returns true once all the registered warmup functions completed
+// successfully. It returns true if no warmup function is registered.
+func (c *checker) Started() bool {
+	c.warmupLock.Lock()
+	defer c.warmupLock.Unlock()
+	for _, w := range c.warmups {
+		if !w.done {
+			return false
+		}
+	}
+	return true
+}
+
+// StartupHandler returns a HTTP handler that serves startup probe requests.
+// The handler responds with status 200 once all the warmup functions of s
+// completed and 503 before that. Unlike readiness the startup state does not
+// depend on the health of the dependencies.
+func StartupHandler(s Starter) http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		h := &Health{
+			Uptime:  int64(time.Since(StartedAt).Seconds()),
+			Version: Version,
+			State:   StateStarting,
+		}
+		status := http.StatusServiceUnavailable
+		if s.Started() {
+			h.State = StateHealthy
+			status = http.StatusOK
+		}
+		b, _ := json.Marshal(h)
+		w.WriteHeader(status)
+		w.Write(b)
+	})
+}
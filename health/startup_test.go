@@ -0,0 +1,107 @@
+package health
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"goa.design/clue/clock"
+	"goa.design/clue/log"
+)
+
+func TestStart(t *testing.T) {
+	chk := NewChecker(singleHealthyDep("dependency")...)
+	if !chk.Started() {
+		t.Errorf("expected checker without warmup to be started")
+	}
+	fail := true
+	var calls int
+	chk.RegisterWarmup("config", func(context.Context) error { calls++; return nil })
+	chk.RegisterWarmup("cache", func(context.Context) error {
+		if fail {
+			return errors.New("cache is not ok")
+		}
+		return nil
+	})
+	if chk.Started() {
+		t.Errorf("expected checker with pending warmups not to be started")
+	}
+	res, healthy := chk.Check(context.Background())
+	if healthy || res.State != StateStarting {
+		t.Errorf("got healthy %v and state %q, expected false and %q", healthy, res.State, StateStarting)
+	}
+
+	err := chk.Start(context.Background())
+	if err == nil || err.Error() != `warmup "cache" failed: cache is not ok` {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if chk.Started() {
+		t.Errorf("expected checker with failed warmup not to be started")
+	}
+
+	fail = false
+	if err := chk.Start(context.Background()); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if !chk.Started() {
+		t.Errorf("expected checker to be started")
+	}
+	if calls != 1 {
+		t.Errorf("got %d calls to completed warmup, expected 1", calls)
+	}
+	res, healthy = chk.Check(context.Background())
+	if !healthy || res.State != StateHealthy {
+		t.Errorf("got healthy %v and state %q, expected true and %q", healthy, res.State, StateHealthy)
+	}
+}
+
+func TestStartClock(t *testing.T) {
+	clk := clock.NewFake(time.Date(2022, time.January, 9, 20, 29, 45, 0, time.UTC))
+	chk := newChecker(clk)
+	chk.RegisterWarmup("cache", func(context.Context) error { clk.Advance(3 * time.Second); return nil })
+	var buf bytes.Buffer
+	ctx := log.Context(context.Background(), log.WithOutput(&buf), log.WithFormat(log.FormatText),
+		log.WithDisableBuffering(func(context.Context) bool { return true }))
+	if err := chk.Start(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(buf.String(), "duration=3s") {
+		t.Errorf("expected warmup duration to be measured with the checker clock, got %q", buf.String())
+	}
+}
+
+func TestStartupHandler(t *testing.T) {
+	chk := NewChecker(singleUnhealthyDep("dependency", errors.New("dependency is not ok"))...)
+	chk.RegisterWarmup("cache", func(context.Context) error { return nil })
+	mux := http.NewServeMux()
+	Mount(mux, chk)
+	probe := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest("GET", StartupPath, nil))
+		return w
+	}
+
+	w := probe()
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("got status: %d, expected %d", w.Code, http.StatusServiceUnavailable)
+	}
+	if expected := `{"uptime":0,"version":"","state":"starting"}`; w.Body.String() != expected {
+		t.Errorf("got body: %s, expected %s", w.Body.String(), expected)
+	}
+
+	if err := chk.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	w = probe()
+	if w.Code != http.StatusOK {
+		t.Errorf("got status: %d, expected %d", w.Code, http.StatusOK)
+	}
+	if expected := `{"uptime":0,"version":"","state":"healthy"}`; w.Body.String() != expected {
+		t.Errorf("got body: %s, expected %s", w.Body.String(), expected)
+	}
+}
@@ -0,0 +1,53 @@
+package health
+
+type (
+	// Transition describes a change of the status of a dependency or of
+	// the state of the service.
+	Transition struct {
+		// Dependency is the name of the dependency whose status changed,
+		// empty if the state of the service changed.
+		Dependency string
+		// From is the previous status ("OK" or "NOT OK") or state
+		// (StateHealthy, StateDegraded etc.).
+		From string
+		// To is the new status or state.
+		To string
+		// Err is the error returned by the check that made the
+		// dependency unhealthy, nil otherwise.
+		Err error
+	}
+)
+
+// OnTransition adds a function called when the status of a dependency or the
+// state of the service changes, e.g. to log, emit events or trip client-side
+// circuit breakers. Hooks are called synchronously by the goroutine running the
+// checks and must not block. The first check of a dependency and the first
+// computed state of the service are not transitions.
+//
+// Example:
+//
+//	chk.OnTransition(func(t health.Transition) {
+//		if t.Dependency == "" {
+//			log.Print(ctx, log.KV{K: "msg", V: "state changed"}, log.KV{K: "from", V: t.From}, log.KV{K: "to", V: t.To})
+//		}
+//	})
+func (c *checker) OnTransition(hook func(Transition)) {
+	c.resultsLock.Lock()
+	defer c.resultsLock.Unlock()
+	c.hooks = append(c.hooks, hook)
+}
+
+// notify calls the given hooks with t.
+func notify(hooks []func(Transition), t Transition) {
+	for _, h := range hooks {
+		h(t)
+	}
+}
+
+// depStatus returns the dependency status corresponding to healthy.
+func depStatus(healthy bool) string {
+	if healthy {
+		return "OK"
+	}
+	return "NOT OK"
+}
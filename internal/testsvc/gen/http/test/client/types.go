@@ -1,48 +0,0 @@
-// Code generated by goa v3.9.1, DO NOT EDIT.
-//
-// test HTTP client types
-//
-// Command:
-// $ goa gen goa.design/clue/internal/testsvc/design
-
-package client
-
-import (
-	test "goa.design/clue/internal/testsvc/gen/test"
-)
-
-// HTTPMethodRequestBody is the type of the "test" service "http_method"
-// endpoint HTTP request body.
-type HTTPMethodRequestBody struct {
-	// String operand
-	S *string `form:"s,omitempty" json:"s,omitempty" xml:"s,omitempty"`
-}
-
-// HTTPMethodResponseBody is the type of the "test" service "http_method"
-// endpoint HTTP response body.
-type HTTPMethodResponseBody struct {
-	// String operand
-	S *string `form:"s,omitempty" json:"s,omitempty" xml:"s,omitempty"`
-	// Int operand
-	I *int `form:"i,omitempty" json:"i,omitempty" xml:"i,omitempty"`
-}
-
-// NewHTTPMethodRequestBody builds the HTTP request body from the payload of
-// the "http_method" endpoint of the "test" service.
-func NewHTTPMethodRequestBody(p *test.Fields) *HTTPMethodRequestBody {
-	body := &HTTPMethodRequestBody{
-		S: p.S,
-	}
-	return body
-}
-
-// NewHTTPMethodFieldsOK builds a "test" service "http_method" endpoint result
-// from a HTTP "OK" response.
-func NewHTTPMethodFieldsOK(body *HTTPMethodResponseBody) *test.Fields {
-	v := &test.Fields{
-		S: body.S,
-		I: body.I,
-	}
-
-	return v
-}
@@ -3,6 +3,8 @@ package log
 import (
 	"context"
 	"fmt"
+	stdlog "log"
+	"strings"
 
 	"github.com/aws/smithy-go/logging"
 	"goa.design/goa/v3/middleware"
@@ -23,6 +25,13 @@ type (
 	goaLogger struct {
 		context.Context
 	}
+
+	// stdlibWriter is the io.Writer used by loggers created with
+	// AsStdlibLogger.
+	stdlibWriter struct {
+		ctx context.Context
+		sev Severity
+	}
 )
 
 // Make Goa use clue's request ID context key.
@@ -59,6 +68,18 @@ func AsStdLogger(ctx context.Context) *StdLogger {
 	return &StdLogger{ctx}
 }
 
+// AsStdlibLogger returns a standard library logger that writes its output to
+// the logger contained in ctx with the given severity. This makes it possible
+// to use the logger with libraries that only accept the standard library
+// type, for example:
+//
+//	srv := &http.Server{ErrorLog: log.AsStdlibLogger(ctx, log.SeverityError)}
+//
+// Entries written with SeverityError flush the log buffer, see Error.
+func AsStdlibLogger(ctx context.Context, sev Severity) *stdlog.Logger {
+	return stdlog.New(&stdlibWriter{ctx, sev}, "", 0)
+}
+
 // AsAWSLogger returns an AWS SDK compatible logger.
 //
 // Usage:
@@ -127,6 +148,21 @@ func (l *StdLogger) Println(v ...interface{}) {
 	Printf(l.ctx, "%s", fmt.Sprintln(v...))
 }
 
+// Write writes a single message to the logger. The standard library logger
+// calls Write once per message.
+func (w *stdlibWriter) Write(b []byte) (int, error) {
+	msg := KV{K: MessageKey, V: strings.TrimSuffix(string(b), "\n")}
+	switch w.sev {
+	case SeverityDebug:
+		Debug(w.ctx, msg)
+	case SeverityError:
+		Error(w.ctx, nil, msg)
+	default:
+		Info(w.ctx, msg)
+	}
+	return len(b), nil
+}
+
 func (l *AWSLogger) Logf(classification logging.Classification, format string, v ...any) {
 	fn := Infof
 	if classification == logging.Debug {
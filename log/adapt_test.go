@@ -4,32 +4,37 @@ import (
 	"bytes"
 	"context"
 	"testing"
-	"time"
 
 	"github.com/aws/smithy-go/logging"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestAsGoaMiddlwareLogger(t *testing.T) {
-	restore := timeNow
-	timeNow = func() time.Time { return time.Date(2022, time.January, 9, 20, 29, 45, 0, time.UTC) }
-	defer func() { timeNow = restore }()
-
 	var buf bytes.Buffer
-	ctx := Context(context.Background(), WithOutput(&buf))
+	ctx := Context(context.Background(), WithOutput(&buf), WithClock(testClock()))
 	logger := AsGoaMiddlewareLogger(ctx)
 	logger.Log("msg", "hello world")
 	want := "time=2022-01-09T20:29:45Z level=info msg=\"hello world\"\n"
 	assert.Equal(t, buf.String(), want)
 }
 
-func TestAsStdLogger(t *testing.T) {
-	restore := timeNow
-	timeNow = func() time.Time { return time.Date(2022, time.January, 9, 20, 29, 45, 0, time.UTC) }
-	defer func() { timeNow = restore }()
+func TestAsStdlibLogger(t *testing.T) {
+	var buf bytes.Buffer
+	ctx := Context(context.Background(), WithOutput(&buf), WithClock(testClock()))
+
+	AsStdlibLogger(ctx, SeverityDebug).Println("ignored")
+	AsStdlibLogger(ctx, SeverityInfo).Printf("hello %s", "world")
+	assert.Empty(t, buf.String())
 
+	AsStdlibLogger(ctx, SeverityError).Print("failed")
+	want := "time=2022-01-09T20:29:45Z level=info msg=\"hello world\"\n" +
+		"time=2022-01-09T20:29:45Z level=error msg=failed\n"
+	assert.Equal(t, want, buf.String())
+}
+
+func TestAsStdLogger(t *testing.T) {
 	var buf bytes.Buffer
-	ctx := Context(context.Background(), WithOutput(&buf))
+	ctx := Context(context.Background(), WithOutput(&buf), WithClock(testClock()))
 	logger := AsStdLogger(ctx)
 
 	logger.Print("hello world")
@@ -107,11 +112,8 @@ func TestAsStdLogger(t *testing.T) {
 type ctxkey string
 
 func TestAsAWSLogger(t *testing.T) {
-	restore := timeNow
-	timeNow = func() time.Time { return time.Date(2022, time.January, 9, 20, 29, 45, 0, time.UTC) }
-	defer func() { timeNow = restore }()
 	var buf bytes.Buffer
-	ctx := Context(context.Background(), WithOutput(&buf), WithDebug())
+	ctx := Context(context.Background(), WithOutput(&buf), WithDebug(), WithClock(testClock()))
 	var logger logging.Logger = AsAWSLogger(ctx)
 
 	logger.Logf(logging.Classification("INFO"), "hello %s", "world")
@@ -1,6 +1,10 @@
 package log
 
-import "context"
+import (
+	"context"
+
+	"goa.design/clue/clock"
+)
 
 const (
 	ctxLogger ctxKey = iota + 1
@@ -17,6 +21,9 @@ func Context(ctx context.Context, opts ...LogOption) context.Context {
 	for _, opt := range opts {
 		opt(l.options)
 	}
+	if l.options.clock == nil {
+		l.options.clock = clock.FromContext(ctx)
+	}
 	if l.options.disableBuffering != nil && l.options.disableBuffering(ctx) {
 		l.flush()
 	}
@@ -45,6 +52,17 @@ func MustContainLogger(logCtx context.Context) {
 	}
 }
 
+// clockFrom returns the clock of the logger stored in ctx, the clock stored in
+// ctx via clock.Context if there is no logger.
+func clockFrom(ctx context.Context) clock.Clock {
+	if l, ok := ctx.Value(ctxLogger).(*logger); ok {
+		l.lock.Lock()
+		defer l.lock.Unlock()
+		return l.options.clock
+	}
+	return clock.FromContext(ctx)
+}
+
 // DebugEnabled returns true if the given context has debug logging enabled.
 func DebugEnabled(ctx context.Context) bool {
 	v := ctx.Value(ctxLogger)
@@ -54,5 +72,5 @@ func DebugEnabled(ctx context.Context) bool {
 	l := v.(*logger)
 	l.lock.Lock()
 	defer l.lock.Unlock()
-	return l.options.debug
+	return l.debug || l.options.debug || (l.options.control != nil && l.options.control.Debug())
 }
@@ -0,0 +1,334 @@
+package log
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	goa "goa.design/goa/v3/pkg"
+
+	"goa.design/clue/clock"
+)
+
+type (
+	// Control exposes the logging settings that can be changed while the
+	// process is running, typically via debug endpoints. A Control is
+	// attached to a logger with WithControl and is shared by all the
+	// contexts derived from it. All changes are recorded and can be
+	// retrieved with History for auditing.
+	Control struct {
+		lock     sync.RWMutex
+		debug    bool
+		payloads bool
+		rate     float64
+		modules  map[string]Severity
+		routes   map[string]bool
+		history  []*Change
+		audit    func(*Change)
+		clock    clock.Clock
+	}
+
+	// Change records a single change made to a Control.
+	Change struct {
+		// Time is the time the change was made.
+		Time time.Time
+		// Who identifies the author of the change.
+		Who string
+		// Setting is the name of the setting that changed, one of
+		// "debug", "payloads", "sampling-rate", "module:<name>" or
+		// "payloads:<route>".
+		Setting string
+		// Old is the previous value of the setting.
+		Old interface{}
+		// New is the new value of the setting.
+		New interface{}
+	}
+
+	// ControlOption is a function that applies a configuration option to a
+	// Control.
+	ControlOption func(*Control)
+)
+
+// ModuleKey is the key used to identify the module that emits log entries.
+// Per-module levels set via Control.SetModuleLevel apply to the contexts
+// created with log.With(ctx, log.KV{log.ModuleKey, "name"}).
+var ModuleKey = "module"
+
+// maxHistory is the maximum number of changes kept by a Control.
+const maxHistory = 100
+
+// Be kind to tests
+var randFloat64 = rand.Float64
+
+// NewControl returns a Control with payload logging enabled, debug logs
+// disabled and a sampling rate of 1 (all entries are written).
+func NewControl(opts ...ControlOption) *Control {
+	c := &Control{clock: clock.System, payloads: true, rate: 1, modules: make(map[string]Severity), routes: make(map[string]bool)}
+	for _, o := range opts {
+		o(c)
+	}
+	return c
+}
+
+// WithControlClock sets the clock used to timestamp changes. The default is
+// clock.System.
+func WithControlClock(c clock.Clock) ControlOption {
+	return func(ctl *Control) {
+		ctl.clock = c
+	}
+}
+
+// WithAudit sets a function called with each change made to the control,
+// for example to log it.
+func WithAudit(fn func(*Change)) ControlOption {
+	return func(c *Control) {
+		c.audit = fn
+	}
+}
+
+// WithControl attaches the given control to the logger. The control settings
+// take precedence over WithDebug and WithNoDebug when debug logs are enabled.
+func WithControl(c *Control) LogOption {
+	return func(o *options) {
+		o.control = c
+	}
+}
+
+// ControlFromContext returns the control attached to the logger in ctx if any,
+// nil otherwise.
+func ControlFromContext(ctx context.Context) *Control {
+	v := ctx.Value(ctxLogger)
+	if v == nil {
+		return nil
+	}
+	l := v.(*logger)
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return l.options.control
+}
+
+// PayloadsEnabled returns false if the control attached to the logger in ctx
+// disables payload logging, true otherwise. Per-route settings (see
+// Control.SetRoutePayloads) apply to the contexts of the Goa endpoints of the
+// route.
+func PayloadsEnabled(ctx context.Context) bool {
+	c := ControlFromContext(ctx)
+	if c == nil {
+		return true
+	}
+	if route := goaRoute(ctx); route != "" {
+		return c.RoutePayloads(route)
+	}
+	return c.Payloads()
+}
+
+// Debug returns true if debug logs are enabled.
+func (c *Control) Debug() bool {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.debug
+}
+
+// SetDebug enables or disables debug logs and returns the previous value.
+func (c *Control) SetDebug(who string, on bool) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	old := c.debug
+	c.debug = on
+	c.record(who, "debug", old, on)
+	return old
+}
+
+// Payloads returns true if payload logging is enabled.
+func (c *Control) Payloads() bool {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.payloads
+}
+
+// SetPayloads enables or disables payload logging and returns the previous
+// value.
+func (c *Control) SetPayloads(who string, on bool) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	old := c.payloads
+	c.payloads = on
+	c.record(who, "payloads", old, on)
+	return old
+}
+
+// RoutePayloads returns true if payload logging is enabled for the given route.
+// Routes are identified by the Goa service and method names separated with a
+// dot, e.g. "front.forecast". Routes without a specific setting use the global
+// setting, see Payloads.
+func (c *Control) RoutePayloads(route string) bool {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	if on, ok := c.routes[route]; ok {
+		return on
+	}
+	return c.payloads
+}
+
+// RoutesPayloads returns a copy of the route specific payload logging
+// settings indexed by route.
+func (c *Control) RoutesPayloads() map[string]bool {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	res := make(map[string]bool, len(c.routes))
+	for r, on := range c.routes {
+		res[r] = on
+	}
+	return res
+}
+
+// SetRoutePayloads enables or disables payload logging for the given route,
+// see RoutePayloads, and returns the previous value.
+func (c *Control) SetRoutePayloads(who, route string, on bool) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	old, ok := c.routes[route]
+	if !ok {
+		old = c.payloads
+	}
+	c.routes[route] = on
+	c.record(who, "payloads:"+route, old, on)
+	return old
+}
+
+// ResetRoutePayloads removes the payload logging setting specific to the given
+// route so that it uses the global setting again.
+func (c *Control) ResetRoutePayloads(who, route string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	old, ok := c.routes[route]
+	if !ok {
+		return
+	}
+	delete(c.routes, route)
+	c.record(who, "payloads:"+route, old, "default")
+}
+
+// SamplingRate returns the fraction of debug and info entries that are
+// written.
+func (c *Control) SamplingRate() float64 {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.rate
+}
+
+// SetSamplingRate sets the fraction of debug and info entries that are written
+// and returns the previous value. rate is capped to the [0, 1] interval. Error
+// entries are never sampled out.
+func (c *Control) SetSamplingRate(who string, rate float64) float64 {
+	if rate < 0 {
+		rate = 0
+	} else if rate > 1 {
+		rate = 1
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	old := c.rate
+	c.rate = rate
+	c.record(who, "sampling-rate", old, rate)
+	return old
+}
+
+// ModuleLevel returns the minimum severity of the entries written for the
+// given module and true if one is set, false otherwise.
+func (c *Control) ModuleLevel(module string) (Severity, bool) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	sev, ok := c.modules[module]
+	return sev, ok
+}
+
+// ModuleLevels returns a copy of the minimum severities indexed by module.
+func (c *Control) ModuleLevels() map[string]Severity {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	res := make(map[string]Severity, len(c.modules))
+	for m, sev := range c.modules {
+		res[m] = sev
+	}
+	return res
+}
+
+// SetModuleLevel sets the minimum severity of the entries written for the
+// given module and returns the previous value (0 if none was set). A severity
+// of 0 removes the module specific level.
+func (c *Control) SetModuleLevel(who, module string, sev Severity) Severity {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	old := c.modules[module]
+	if sev == 0 {
+		delete(c.modules, module)
+	} else {
+		c.modules[module] = sev
+	}
+	c.record(who, "module:"+module, old, sev)
+	return old
+}
+
+// History returns the most recent changes made to the control, oldest first.
+func (c *Control) History() []*Change {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	res := make([]*Change, len(c.history))
+	copy(res, c.history)
+	return res
+}
+
+// enabled returns true if an entry with the given severity emitted by the
+// given module should be written. debug is the logger debug setting.
+func (c *Control) enabled(sev Severity, module string, debug bool) bool {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	min := SeverityInfo
+	if debug || c.debug {
+		min = SeverityDebug
+	}
+	if module != "" {
+		if s, ok := c.modules[module]; ok {
+			min = s
+		}
+	}
+	if sev < min {
+		return false
+	}
+	return sev == SeverityError || c.rate >= 1 || randFloat64() < c.rate
+}
+
+// record adds a change to the history, c.lock must be held.
+func (c *Control) record(who, setting string, old, new interface{}) {
+	ch := &Change{Time: c.clock.Now().UTC(), Who: who, Setting: setting, Old: old, New: new}
+	c.history = append(c.history, ch)
+	if len(c.history) > maxHistory {
+		c.history = c.history[len(c.history)-maxHistory:]
+	}
+	if c.audit != nil {
+		c.audit(ch)
+	}
+}
+
+// goaRoute returns the Goa route of the request handled with ctx if any.
+func goaRoute(ctx context.Context) string {
+	s, _ := ctx.Value(goa.ServiceKey).(string)
+	m, _ := ctx.Value(goa.MethodKey).(string)
+	if s == "" || m == "" {
+		return ""
+	}
+	return s + "." + m
+}
+
+// LogFields returns the key/value pairs describing the change.
+func (ch *Change) LogFields() []KV {
+	return []KV{
+		{K: "who", V: ch.Who},
+		{K: "setting", V: ch.Setting},
+		{K: "old", V: fmt.Sprint(ch.Old)},
+		{K: "new", V: fmt.Sprint(ch.New)},
+	}
+}
@@ -0,0 +1,123 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	goa "goa.design/goa/v3/pkg"
+)
+
+func TestControlDebug(t *testing.T) {
+	var buf bytes.Buffer
+	c := NewControl()
+	ctx := Context(context.Background(), WithOutput(&buf), WithFormat(testFormat), WithControl(c))
+
+	Debugf(ctx, ignored)
+	assert.False(t, DebugEnabled(ctx))
+	assert.Empty(t, buf.String())
+
+	assert.False(t, c.SetDebug("alice", true))
+	assert.True(t, DebugEnabled(ctx))
+	Debugf(ctx, printed)
+	assert.Equal(t, printed, buf.String())
+}
+
+func TestControlModuleLevels(t *testing.T) {
+	var buf bytes.Buffer
+	c := NewControl()
+	ctx := Context(context.Background(), WithOutput(&buf), WithFormat(testFormat), WithControl(c))
+	db := With(ctx, KV{ModuleKey, "db"})
+
+	c.SetModuleLevel("alice", "db", SeverityDebug)
+	Debugf(ctx, ignored)
+	Debugf(db, printed)
+	assert.Equal(t, "db"+printed, buf.String())
+
+	buf.Reset()
+	c.SetModuleLevel("alice", "db", SeverityError)
+	Print(db, KV{"msg", ignored})
+	Print(ctx, KV{"msg", printed})
+	assert.Equal(t, printed, buf.String())
+
+	assert.Equal(t, SeverityError, c.SetModuleLevel("alice", "db", 0))
+	assert.Empty(t, c.ModuleLevels())
+}
+
+func TestControlSamplingRate(t *testing.T) {
+	restore := randFloat64
+	defer func() { randFloat64 = restore }()
+	randFloat64 = func() float64 { return 0.5 }
+
+	var buf bytes.Buffer
+	c := NewControl()
+	ctx := Context(context.Background(), WithOutput(&buf), WithFormat(testFormat), WithControl(c))
+
+	assert.Equal(t, 1.0, c.SetSamplingRate("alice", 0.4))
+	Print(ctx, KV{"msg", ignored})
+	Error(ctx, nil, KV{"msg", printed})
+	assert.Equal(t, printed, buf.String())
+
+	c.SetSamplingRate("alice", 0.6)
+	Print(ctx, KV{"msg", printed})
+	assert.Equal(t, printed+printed, buf.String())
+
+	c.SetSamplingRate("alice", 2)
+	assert.Equal(t, 1.0, c.SamplingRate())
+}
+
+func TestControlPayloads(t *testing.T) {
+	assert.True(t, PayloadsEnabled(context.Background()))
+	c := NewControl()
+	ctx := Context(context.Background(), WithControl(c))
+	assert.True(t, PayloadsEnabled(ctx))
+	c.SetPayloads("alice", false)
+	assert.False(t, PayloadsEnabled(ctx))
+	assert.Equal(t, c, ControlFromContext(ctx))
+}
+
+func TestControlHistory(t *testing.T) {
+	var audited []*Change
+	clock := testClock()
+	c := NewControl(WithControlClock(clock), WithAudit(func(ch *Change) { audited = append(audited, ch) }))
+	c.SetDebug("alice", true)
+	c.SetPayloads("bob", false)
+	for i := 0; i < maxHistory; i++ {
+		c.SetSamplingRate("carol", 0.5)
+	}
+
+	h := c.History()
+	require.Len(t, h, maxHistory)
+	assert.Len(t, audited, maxHistory+2)
+	assert.Equal(t, &Change{Time: clock.Now(), Who: "alice", Setting: "debug", Old: false, New: true}, audited[0])
+	assert.Equal(t, []KV{{"who", "bob"}, {"setting", "payloads"}, {"old", "true"}, {"new", "false"}}, audited[1].LogFields())
+	assert.Equal(t, "carol", h[0].Who)
+}
+
+func TestControlRoutePayloads(t *testing.T) {
+	c := NewControl()
+	ctx := Context(context.Background(), WithControl(c))
+	forecast := context.WithValue(context.WithValue(ctx, goa.ServiceKey, "front"), goa.MethodKey, "forecast")
+	other := context.WithValue(context.WithValue(ctx, goa.ServiceKey, "front"), goa.MethodKey, "other")
+
+	assert.True(t, c.SetRoutePayloads("alice", "front.forecast", false))
+	assert.False(t, PayloadsEnabled(forecast))
+	assert.True(t, PayloadsEnabled(other))
+	assert.True(t, PayloadsEnabled(ctx))
+
+	c.SetPayloads("alice", false)
+	c.SetRoutePayloads("alice", "front.other", true)
+	assert.True(t, PayloadsEnabled(other))
+	assert.False(t, PayloadsEnabled(ctx))
+	assert.Equal(t, map[string]bool{"front.forecast": false, "front.other": true}, c.RoutesPayloads())
+
+	c.ResetRoutePayloads("alice", "front.other")
+	c.ResetRoutePayloads("alice", "unknown")
+	assert.False(t, PayloadsEnabled(other))
+	h := c.History()
+	require.Len(t, h, 4)
+	assert.Equal(t, "payloads:front.other", h[3].Setting)
+	assert.Equal(t, "default", h[3].New)
+}
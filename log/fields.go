@@ -1,5 +1,12 @@
 package log
 
+import (
+	"fmt"
+	"sort"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
 type (
 	// KV represents a key/value pair. Values must be strings, numbers,
 	// booleans, nil or a slice of these types.
@@ -17,6 +24,14 @@ type (
 	// non-deterministic order of the fields
 	Fields map[string]interface{}
 
+	// Lazy is a value computed only when the log entry that contains it is
+	// written. This makes it possible to log values that are expensive to
+	// compute without paying the cost for entries that are never written
+	// (e.g. debug entries when debug logs are disabled or buffered entries
+	// that are never flushed). The function must return a string, number,
+	// boolean, nil or a slice of these types.
+	Lazy func() interface{}
+
 	kvList []KV
 )
 
@@ -48,3 +63,106 @@ func (kvs kvList) merge(fielders []Fielder) kvList {
 func (kvs kvList) LogFields() []KV {
 	return kvs
 }
+
+// resolve returns a copy of the list of key/value pairs where lazy values are
+// replaced with their results. The second return value is false if kvs does
+// not contain any lazy value in which case kvs is returned as-is.
+func (kvs kvList) resolve() (kvList, bool) {
+	var res kvList
+	for i, kv := range kvs {
+		fn, ok := kv.V.(Lazy)
+		if !ok {
+			if res != nil {
+				res = append(res, kv)
+			}
+			continue
+		}
+		if res == nil {
+			res = make(kvList, i, len(kvs))
+			copy(res, kvs[:i])
+		}
+		res = append(res, KV{K: kv.K, V: fn()})
+	}
+	if res == nil {
+		return kvs, false
+	}
+	return res, true
+}
+
+// canonical returns a copy of the list of key/value pairs sorted in canonical
+// order (see WithCanonicalOrder) and without reserved keys.
+func (kvs kvList) canonical() kvList {
+	res := make(kvList, 0, len(kvs))
+	for _, kv := range kvs {
+		if !isReserved(kv.K) {
+			res = append(res, kv)
+		}
+	}
+	rank := func(k string) int {
+		switch k {
+		case MessageKey:
+			return 0
+		case TraceIDKey:
+			return 1
+		default:
+			return 2
+		}
+	}
+	sort.SliceStable(res, func(i, j int) bool {
+		ri, rj := rank(res[i].K), rank(res[j].K)
+		if ri != rj {
+			return ri < rj
+		}
+		return ri == 2 && res[i].K < res[j].K
+	})
+	return res
+}
+
+// validate panics if kvs contains a reserved key.
+func (kvs kvList) validate() {
+	for _, kv := range kvs {
+		if isReserved(kv.K) {
+			panic(fmt.Sprintf("log: key %q is reserved", kv.K))
+		}
+	}
+}
+
+// isReserved returns true if k is written by the log formatters.
+func isReserved(k string) bool {
+	return k == TimestampKey || k == SeverityKey
+}
+
+// module returns the value of the last ModuleKey key if any, empty string
+// otherwise.
+func (kvs kvList) module() string {
+	for i := len(kvs) - 1; i >= 0; i-- {
+		if kvs[i].K == ModuleKey {
+			if m, ok := kvs[i].V.(string); ok {
+				return m
+			}
+		}
+	}
+	return ""
+}
+
+// attributes returns the OpenTelemetry attributes corresponding to kvs.
+func (kvs kvList) attributes() []attribute.KeyValue {
+	attrs := make([]attribute.KeyValue, len(kvs))
+	for i, kv := range kvs {
+		switch v := kv.V.(type) {
+		case string:
+			attrs[i] = attribute.String(kv.K, v)
+		case bool:
+			attrs[i] = attribute.Bool(kv.K, v)
+		case int:
+			attrs[i] = attribute.Int(kv.K, v)
+		case int64:
+			attrs[i] = attribute.Int64(kv.K, v)
+		case float64:
+			attrs[i] = attribute.Float64(kv.K, v)
+		default:
+			attrs[i] = attribute.String(kv.K, fmt.Sprint(v))
+		}
+	}
+	return attrs
+}
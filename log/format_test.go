@@ -8,13 +8,15 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+
+	"goa.design/clue/clock"
 )
 
 func TestFormat(t *testing.T) {
-	now, epoc := timeNow, epoch
-	timeNow = func() time.Time { return time.Date(2022, time.January, 9, 20, 29, 45, 123, time.UTC) }
-	epoch = timeNow()
-	defer func() { timeNow = now; epoch = epoc }()
+	clock := clock.NewFake(time.Date(2022, time.January, 9, 20, 29, 45, 123, time.UTC))
+	epoc := epoch
+	epoch = clock.Now()
+	defer func() { epoch = epoc }()
 
 	keyVals := []KV{
 		{"string", "val"},
@@ -202,7 +204,7 @@ func TestFormat(t *testing.T) {
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
 			var buf bytes.Buffer
-			ctx := Context(context.Background(), WithOutput(&buf), WithFormat(tc.format), WithDebug())
+			ctx := Context(context.Background(), WithOutput(&buf), WithFormat(tc.format), WithDebug(), WithClock(clock))
 			tc.logfn(ctx, kvList(tc.keyVals))
 			assert.Equal(t, tc.want, buf.String())
 		})
@@ -240,7 +242,7 @@ func TestFormat(t *testing.T) {
 	for _, tc := range errorCases {
 		t.Run(tc.name, func(t *testing.T) {
 			var buf bytes.Buffer
-			ctx := Context(context.Background(), WithOutput(&buf), WithFormat(tc.format), WithDebug())
+			ctx := Context(context.Background(), WithOutput(&buf), WithFormat(tc.format), WithDebug(), WithClock(clock))
 			tc.logfn(ctx, errors.New("error"), kvList(tc.keyVals))
 			assert.Equal(t, tc.want, buf.String())
 		})
@@ -284,7 +286,7 @@ func TestFormat(t *testing.T) {
 				SeverityKey = severityKey
 			}()
 			var buf bytes.Buffer
-			ctx := Context(context.Background(), WithOutput(&buf), WithFormat(tc.format), WithDebug())
+			ctx := Context(context.Background(), WithOutput(&buf), WithFormat(tc.format), WithDebug(), WithClock(clock))
 			tc.logfn(ctx, kvList(tc.keyVals))
 			assert.Equal(t, tc.want, buf.String())
 		})
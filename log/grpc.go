@@ -3,7 +3,6 @@ package log
 import (
 	"context"
 	"path"
-	"time"
 
 	goamiddleware "goa.design/goa/v3/middleware"
 	"google.golang.org/grpc"
@@ -74,12 +73,13 @@ func UnaryClientInterceptor(opts ...GRPCClientLogOption) grpc.UnaryClientInterce
 		invoker grpc.UnaryInvoker,
 		opts ...grpc.CallOption,
 	) error {
-		then := time.Now()
+		clock := clockFrom(ctx)
+		then := clock.Now()
 		service := path.Dir(fullmethod)[1:]
 		method := path.Base(fullmethod)
 		err := invoker(ctx, fullmethod, req, reply, cc, opts...)
 		stat, _ := status.FromError(err)
-		ms := timeSince(then).Milliseconds()
+		ms := clock.Since(then).Milliseconds()
 		msgKV := KV{K: MessageKey, V: "finished client unary call"}
 		svcKV := KV{K: GRPCServiceKey, V: service}
 		methKV := KV{K: GRPCMethodKey, V: method}
@@ -110,12 +110,13 @@ func StreamClientInterceptor(opts ...GRPCClientLogOption) grpc.StreamClientInter
 		streamer grpc.Streamer,
 		opts ...grpc.CallOption,
 	) (grpc.ClientStream, error) {
-		then := time.Now()
+		clock := clockFrom(ctx)
+		then := clock.Now()
 		service := path.Dir(fullmethod)[1:]
 		method := path.Base(fullmethod)
 		stream, err := streamer(ctx, desc, cc, fullmethod, opts...)
 		stat, _ := status.FromError(err)
-		ms := timeSince(then).Milliseconds()
+		ms := clock.Since(then).Milliseconds()
 		msgKV := KV{K: MessageKey, V: "finished client streaming call"}
 		svcKV := KV{K: GRPCServiceKey, V: service}
 		methKV := KV{K: GRPCMethodKey, V: method}
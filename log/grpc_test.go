@@ -9,7 +9,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
-	"goa.design/clue/internal/testsvc"
+	"goa.design/clue/testing/testsvc"
 	grpcmiddleware "goa.design/goa/v3/grpc/middleware"
 	"goa.design/goa/v3/middleware"
 	"google.golang.org/grpc"
@@ -17,12 +17,8 @@ import (
 )
 
 func TestUnaryServerInterceptor(t *testing.T) {
-	now := timeNow
-	timeNow = func() time.Time { return time.Date(2022, time.January, 9, 20, 29, 45, 0, time.UTC) }
-	defer func() { timeNow = now }()
-
 	var buf bytes.Buffer
-	ctx := Context(context.Background(), WithOutput(&buf), WithFormat(FormatJSON))
+	ctx := Context(context.Background(), WithOutput(&buf), WithFormat(FormatJSON), WithClock(testClock()))
 	logInterceptor := UnaryServerInterceptor(ctx)
 	requestIDInterceptor := grpcmiddleware.UnaryRequestID()
 	cli, stop := testsvc.SetupGRPC(t,
@@ -46,12 +42,8 @@ func TestUnaryServerInterceptor(t *testing.T) {
 }
 
 func TestStreamServerTrace(t *testing.T) {
-	now := timeNow
-	timeNow = func() time.Time { return time.Date(2022, time.January, 9, 20, 29, 45, 0, time.UTC) }
-	defer func() { timeNow = now }()
-
 	var buf bytes.Buffer
-	ctx := Context(context.Background(), WithOutput(&buf), WithFormat(FormatJSON))
+	ctx := Context(context.Background(), WithOutput(&buf), WithFormat(FormatJSON), WithClock(testClock()))
 	traceInterceptor := StreamServerInterceptor(ctx)
 	requestIDInterceptor := grpcmiddleware.StreamRequestID()
 	cli, stop := testsvc.SetupGRPC(t,
@@ -98,22 +90,19 @@ func TestUnaryClientInterceptor(t *testing.T) {
 		{"error", false, fmt.Errorf("error"), nil, errorLogs},
 		{"with status", false, fmt.Errorf("error"), WithErrorFunc(func(codes.Code) bool { return true }), statusLogs},
 	}
-	now := timeNow
-	timeNow = func() time.Time { return time.Date(2022, time.January, 9, 20, 29, 45, 0, time.UTC) }
-	defer func() { timeNow = now }()
-	duration := 42 * time.Millisecond
-	since := timeSince
-	timeSince = func(_ time.Time) time.Duration { return duration }
-	defer func() { timeSince = since }()
-
 	for _, c := range cases {
 		t.Run(c.name, func(t *testing.T) {
 			var buf bytes.Buffer
-			ctx := Context(context.Background(), WithOutput(&buf))
+			clock := testClock()
+			ctx := Context(context.Background(), WithOutput(&buf), WithClock(clock))
 			if c.noLog {
 				ctx = context.Background()
 			}
-			opts := []testsvc.GRPCOption{testsvc.WithUnaryFunc(dummyMethod(c.clientErr))}
+			method := func(ctx context.Context, f *testsvc.Fields) (*testsvc.Fields, error) {
+				clock.Advance(42 * time.Millisecond)
+				return dummyMethod(c.clientErr)(ctx, f)
+			}
+			opts := []testsvc.GRPCOption{testsvc.WithUnaryFunc(method)}
 			if c.opt != nil {
 				opts = append(opts, testsvc.WithDialOptions(
 					grpc.WithUnaryInterceptor(UnaryClientInterceptor(c.opt))))
@@ -140,23 +129,20 @@ func TestStreamClientInterceptor(t *testing.T) {
 		{"no logger", true, ""},
 		{"success", false, successLogs},
 	}
-	now := timeNow
-	timeNow = func() time.Time { return time.Date(2022, time.January, 9, 20, 29, 45, 0, time.UTC) }
-	defer func() { timeNow = now }()
-	duration := 42 * time.Millisecond
-	since := timeSince
-	timeSince = func(_ time.Time) time.Duration { return duration }
-	defer func() { timeSince = since }()
-
 	for _, c := range cases {
 		t.Run(c.name, func(t *testing.T) {
 			var buf bytes.Buffer
-			ctx := Context(context.Background(), WithOutput(&buf))
+			clock := testClock()
+			ctx := Context(context.Background(), WithOutput(&buf), WithClock(clock))
 			if c.noLog {
 				ctx = context.Background()
 			}
+			advance := func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+				clock.Advance(42 * time.Millisecond)
+				return streamer(ctx, desc, cc, method, opts...)
+			}
 			cli, stop := testsvc.SetupGRPC(t,
-				testsvc.WithDialOptions(grpc.WithStreamInterceptor(StreamClientInterceptor())),
+				testsvc.WithDialOptions(grpc.WithChainStreamInterceptor(StreamClientInterceptor(), advance)),
 				testsvc.WithStreamFunc(dummyStreamMethod()))
 
 			_, err := cli.GRPCStream(ctx)
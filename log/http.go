@@ -63,7 +63,8 @@ func HTTP(logCtx context.Context, opts ...HTTPLogOption) func(http.Handler) http
 }
 
 // Endpoint is a Goa endpoint middleware that adds the service and method names
-// to the logged key/value pairs.
+// to the logged key/value pairs. Use trace.Endpoint to add the same names to
+// the current span.
 func Endpoint(e goa.Endpoint) goa.Endpoint {
 	return func(ctx context.Context, req interface{}) (interface{}, error) {
 		if s := ctx.Value(goa.ServiceKey); s != nil {
@@ -119,13 +120,14 @@ func (c *client) RoundTrip(req *http.Request) (resp *http.Response, err error) {
 	msgKV := KV{K: MessageKey, V: "finished client HTTP request"}
 	methKV := KV{K: HTTPMethodKey, V: req.Method}
 	urlKV := KV{K: HTTPURLKey, V: req.URL.String()}
-	then := timeNow()
+	clock := clockFrom(req.Context())
+	then := clock.Now()
 	resp, err = c.RoundTripper.RoundTrip(req)
 	if err != nil {
 		Error(req.Context(), err, msgKV, methKV, urlKV)
 		return
 	}
-	ms := timeSince(then).Milliseconds()
+	ms := clock.Since(then).Milliseconds()
 	statusKV := KV{K: HTTPStatusKey, V: resp.Status}
 	durKV := KV{K: HTTPDurationKey, V: ms}
 	if c.options.iserr(resp.StatusCode) {
@@ -17,15 +17,11 @@ import (
 )
 
 func TestHTTP(t *testing.T) {
-	now := timeNow
-	timeNow = func() time.Time { return time.Date(2022, time.January, 9, 20, 29, 45, 0, time.UTC) }
-	defer func() { timeNow = now }()
-
 	var handler http.Handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
 		Print(req.Context(), KV{"key1", "value1"}, KV{"key2", "value2"})
 	})
 	var buf bytes.Buffer
-	ctx := Context(context.Background(), WithOutput(&buf), WithFormat(FormatJSON))
+	ctx := Context(context.Background(), WithOutput(&buf), WithFormat(FormatJSON), WithClock(testClock()))
 
 	handler = HTTP(ctx)(handler)
 
@@ -46,9 +42,6 @@ func TestHTTP(t *testing.T) {
 }
 
 func TestEndpoint(t *testing.T) {
-	now := timeNow
-	timeNow = func() time.Time { return time.Date(2022, time.January, 9, 20, 29, 45, 0, time.UTC) }
-	defer func() { timeNow = now }()
 	endpoint := func(ctx context.Context, req interface{}) (interface{}, error) {
 		Printf(ctx, "test")
 		return nil, nil
@@ -67,7 +60,7 @@ func TestEndpoint(t *testing.T) {
 	for _, c := range cases {
 		t.Run(c.name, func(t *testing.T) {
 			var buf bytes.Buffer
-			ctx := Context(context.Background(), WithOutput(&buf), WithFormat(FormatJSON))
+			ctx := Context(context.Background(), WithOutput(&buf), WithFormat(FormatJSON), WithClock(testClock()))
 			if c.sname != "" {
 				ctx = context.WithValue(ctx, goa.ServiceKey, c.sname)
 			}
@@ -98,21 +91,18 @@ func TestClient(t *testing.T) {
 		{"error", false, fmt.Errorf("error"), nil, errorLogs},
 		{"error with status", false, nil, WithErrorStatus(200), statusLogs},
 	}
-	now := timeNow
-	timeNow = func() time.Time { return time.Date(2022, time.January, 9, 20, 29, 45, 0, time.UTC) }
-	defer func() { timeNow = now }()
-	duration := 42 * time.Millisecond
-	since := timeSince
-	timeSince = func(_ time.Time) time.Duration { return duration }
-	defer func() { timeSince = since }()
 	for _, c := range cases {
 		t.Run(c.name, func(t *testing.T) {
 			var buf bytes.Buffer
-			ctx := Context(context.Background(), WithOutput(&buf))
+			clock := testClock()
+			ctx := Context(context.Background(), WithOutput(&buf), WithClock(clock))
 			if c.noLog {
 				ctx = context.Background()
 			}
-			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) { rw.Write([]byte(`OK`)) }))
+			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+				clock.Advance(42 * time.Millisecond)
+				rw.Write([]byte(`OK`))
+			}))
 			defer server.Close()
 			client := server.Client()
 			if c.clientErr != nil {
@@ -0,0 +1,395 @@
+package log
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+type (
+	// KafkaProducer publishes batches of messages to Kafka. Implementations
+	// typically wrap the producer of a Kafka client library (e.g. sarama or
+	// kafka-go) and map the batch compression and acknowledgment settings to
+	// the corresponding client configuration.
+	KafkaProducer interface {
+		// Produce publishes the given batch and returns once the
+		// messages have been acknowledged as specified by batch.Acks.
+		Produce(ctx context.Context, batch *KafkaBatch) error
+	}
+
+	// KafkaBatch is a batch of messages published to a single topic.
+	KafkaBatch struct {
+		// Topic is the name of the Kafka topic.
+		Topic string
+		// Messages is the list of messages in the batch.
+		Messages []*KafkaMessage
+		// Compression is the compression codec used for the batch.
+		Compression KafkaCompression
+		// Acks is the acknowledgment level required for the batch.
+		Acks KafkaAcks
+	}
+
+	// KafkaMessage is a single log entry published to Kafka.
+	KafkaMessage struct {
+		// Key is the message key, nil if the entry has no key.
+		Key []byte
+		// Value is the encoded log entry.
+		Value []byte
+	}
+
+	// KafkaCompression is the compression codec used to publish batches.
+	KafkaCompression int
+
+	// KafkaAcks is the acknowledgment level required to consider a batch
+	// published.
+	KafkaAcks int
+
+	// KafkaSinkOption is a function that applies a configuration option to
+	// a Kafka sink.
+	KafkaSinkOption func(*kafkaOptions)
+
+	// KafkaSink is a log output that batches log entries and publishes them
+	// to a Kafka topic. It implements io.Writer and is meant to be used with
+	// WithOutput. Each call to Write must contain a single log entry which
+	// is the case for the log package functions.
+	KafkaSink struct {
+		producer KafkaProducer
+		topic    string
+		options  *kafkaOptions
+
+		lock     sync.Mutex
+		messages []*KafkaMessage
+		dead     []*KafkaMessage
+		dropped  uint64
+		flush    chan struct{}
+		done     chan struct{}
+		wg       sync.WaitGroup
+	}
+
+	kafkaOptions struct {
+		key         func([]byte) []byte
+		encode      func([]byte) ([]byte, error)
+		compression KafkaCompression
+		acks        KafkaAcks
+		batchSize   int
+		maxBuffered int
+		interval    time.Duration
+		timeout     time.Duration
+		dlqTopic    string
+		onError     func(error)
+	}
+)
+
+const (
+	// KafkaCompressionNone disables compression.
+	KafkaCompressionNone KafkaCompression = iota
+	// KafkaCompressionGzip uses gzip compression.
+	KafkaCompressionGzip
+	// KafkaCompressionSnappy uses snappy compression.
+	KafkaCompressionSnappy
+	// KafkaCompressionLZ4 uses lz4 compression.
+	KafkaCompressionLZ4
+	// KafkaCompressionZstd uses zstd compression.
+	KafkaCompressionZstd
+)
+
+const (
+	// KafkaAcksNone does not wait for any acknowledgment (at most once).
+	KafkaAcksNone KafkaAcks = iota
+	// KafkaAcksLeader waits for the partition leader to acknowledge.
+	KafkaAcksLeader
+	// KafkaAcksAll waits for all in-sync replicas to acknowledge (at least
+	// once).
+	KafkaAcksAll
+)
+
+const (
+	// DefaultKafkaBatchSize is the default maximum number of entries
+	// published in a single batch.
+	DefaultKafkaBatchSize = 100
+
+	// DefaultKafkaFlushInterval is the default maximum duration entries are
+	// kept in memory before being published.
+	DefaultKafkaFlushInterval = time.Second
+
+	// DefaultKafkaMaxBuffered is the default maximum number of entries kept
+	// in memory while waiting to be published.
+	DefaultKafkaMaxBuffered = 10000
+
+	// DefaultKafkaPublishTimeout is the default maximum duration of the
+	// flushes made by the background goroutine and by Close.
+	DefaultKafkaPublishTimeout = 10 * time.Second
+)
+
+// errKafkaSinkClosed is returned by Write once the sink is closed.
+var errKafkaSinkClosed = errors.New("kafka sink closed")
+
+// NewKafkaSink returns a log output that publishes log entries to the given
+// Kafka topic. The sink publishes entries in batches whenever the batch is
+// full or the flush interval elapses. Batches are published by a background
+// goroutine so that logging never blocks on the producer. Entries written while
+// the buffer is full are dropped, see Dropped. Close must be called to publish
+// any pending entries and release resources.
+//
+// Usage:
+//
+//	sink := log.NewKafkaSink(producer, "logs", log.WithKafkaAcks(log.KafkaAcksAll))
+//	defer sink.Close()
+//	ctx := log.Context(context.Background(), log.WithOutput(sink), log.WithFormat(log.FormatJSON))
+func NewKafkaSink(producer KafkaProducer, topic string, opts ...KafkaSinkOption) *KafkaSink {
+	options := &kafkaOptions{
+		acks:        KafkaAcksLeader,
+		batchSize:   DefaultKafkaBatchSize,
+		maxBuffered: DefaultKafkaMaxBuffered,
+		interval:    DefaultKafkaFlushInterval,
+		timeout:     DefaultKafkaPublishTimeout,
+		onError:     func(error) {},
+	}
+	for _, o := range opts {
+		o(options)
+	}
+	if options.batchSize <= 0 {
+		options.batchSize = DefaultKafkaBatchSize
+	}
+	if options.maxBuffered <= 0 {
+		options.maxBuffered = DefaultKafkaMaxBuffered
+	}
+	if options.interval <= 0 {
+		options.interval = DefaultKafkaFlushInterval
+	}
+	if options.timeout <= 0 {
+		options.timeout = DefaultKafkaPublishTimeout
+	}
+	s := &KafkaSink{
+		producer: producer,
+		topic:    topic,
+		options:  options,
+		flush:    make(chan struct{}, 1),
+		done:     make(chan struct{}),
+	}
+	s.wg.Add(1)
+	go s.run()
+	return s
+}
+
+// WithKafkaKey sets the function used to compute the message key from the
+// formatted log entry. By default messages have no key.
+func WithKafkaKey(fn func(entry []byte) []byte) KafkaSinkOption {
+	return func(o *kafkaOptions) {
+		o.key = fn
+	}
+}
+
+// WithKafkaEncoder sets the function used to encode the formatted log entry
+// into the message value, for example to wrap it in an envelope. Entries that
+// fail to encode are published unmodified to the dead letter topic if one is
+// configured via WithKafkaDLQ and dropped otherwise.
+func WithKafkaEncoder(fn func(entry []byte) ([]byte, error)) KafkaSinkOption {
+	return func(o *kafkaOptions) {
+		o.encode = fn
+	}
+}
+
+// WithKafkaCompression sets the compression codec used to publish batches.
+// The default is KafkaCompressionNone.
+func WithKafkaCompression(c KafkaCompression) KafkaSinkOption {
+	return func(o *kafkaOptions) {
+		o.compression = c
+	}
+}
+
+// WithKafkaAcks sets the acknowledgment level required to publish batches.
+// The default is KafkaAcksLeader.
+func WithKafkaAcks(acks KafkaAcks) KafkaSinkOption {
+	return func(o *kafkaOptions) {
+		o.acks = acks
+	}
+}
+
+// WithKafkaBatchSize sets the maximum number of entries published in a single
+// batch. The default is DefaultKafkaBatchSize, which is also used if n is not
+// positive.
+func WithKafkaBatchSize(n int) KafkaSinkOption {
+	return func(o *kafkaOptions) {
+		o.batchSize = n
+	}
+}
+
+// WithKafkaMaxBuffered sets the maximum number of entries kept in memory while
+// waiting to be published, e.g. when the producer is slow or unavailable.
+// Entries written once the limit is reached are dropped and counted, see
+// Dropped. The limit applies separately to the entries waiting to be published
+// to the dead letter topic. The default is DefaultKafkaMaxBuffered, which is
+// also used if n is not positive.
+func WithKafkaMaxBuffered(n int) KafkaSinkOption {
+	return func(o *kafkaOptions) {
+		o.maxBuffered = n
+	}
+}
+
+// WithKafkaFlushInterval sets the maximum duration entries are kept in memory
+// before being published. The default is DefaultKafkaFlushInterval, which is
+// also used if d is not positive.
+func WithKafkaFlushInterval(d time.Duration) KafkaSinkOption {
+	return func(o *kafkaOptions) {
+		o.interval = d
+	}
+}
+
+// WithKafkaPublishTimeout sets the maximum duration of the flushes made by the
+// background goroutine and by Close. The default is
+// DefaultKafkaPublishTimeout, which is also used if d is not positive.
+func WithKafkaPublishTimeout(d time.Duration) KafkaSinkOption {
+	return func(o *kafkaOptions) {
+		o.timeout = d
+	}
+}
+
+// WithKafkaDLQ sets the dead letter topic that receives the entries that fail
+// to encode.
+func WithKafkaDLQ(topic string) KafkaSinkOption {
+	return func(o *kafkaOptions) {
+		o.dlqTopic = topic
+	}
+}
+
+// WithKafkaErrorHandler sets the function called when publishing a batch
+// fails. The sink does not retry failed batches, retries are the
+// responsibility of the producer.
+func WithKafkaErrorHandler(fn func(error)) KafkaSinkOption {
+	return func(o *kafkaOptions) {
+		o.onError = fn
+	}
+}
+
+// Write adds the given log entry to the current batch. It never blocks on
+// the producer: full batches are published by the background goroutine and
+// entries are dropped if the buffer is full.
+func (s *KafkaSink) Write(b []byte) (int, error) {
+	entry := make([]byte, len(b))
+	copy(entry, b)
+	msg := &KafkaMessage{Value: entry}
+	if s.options.key != nil {
+		msg.Key = s.options.key(entry)
+	}
+
+	s.lock.Lock()
+	select {
+	case <-s.done:
+		s.lock.Unlock()
+		return 0, errKafkaSinkClosed
+	default:
+	}
+	if s.options.encode != nil {
+		v, err := s.options.encode(entry)
+		if err != nil {
+			if s.options.dlqTopic != "" {
+				if len(s.dead) >= s.options.maxBuffered {
+					s.dropped++
+				} else {
+					s.dead = append(s.dead, msg)
+				}
+			}
+			s.lock.Unlock()
+			return len(b), nil
+		}
+		msg.Value = v
+	}
+	if len(s.messages) >= s.options.maxBuffered {
+		s.dropped++
+		s.lock.Unlock()
+		return len(b), nil
+	}
+	s.messages = append(s.messages, msg)
+	full := len(s.messages) >= s.options.batchSize
+	s.lock.Unlock()
+
+	if full {
+		select {
+		case s.flush <- struct{}{}:
+		default:
+		}
+	}
+	return len(b), nil
+}
+
+// Dropped returns the number of entries dropped because the buffer was full.
+func (s *KafkaSink) Dropped() uint64 {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.dropped
+}
+
+// Flush publishes all pending entries.
+func (s *KafkaSink) Flush(ctx context.Context) {
+	s.lock.Lock()
+	msgs, dead := s.messages, s.dead
+	s.messages, s.dead = nil, nil
+	s.lock.Unlock()
+
+	s.publish(ctx, s.topic, msgs)
+	s.publish(ctx, s.options.dlqTopic, dead)
+}
+
+// Close publishes all pending entries and stops the background flush loop.
+// Subsequent calls to Write return an error.
+func (s *KafkaSink) Close() error {
+	s.lock.Lock()
+	select {
+	case <-s.done:
+		s.lock.Unlock()
+		return nil
+	default:
+	}
+	close(s.done)
+	s.lock.Unlock()
+	s.wg.Wait()
+	s.flushWithTimeout()
+	return nil
+}
+
+// run flushes the pending entries periodically until the sink is closed.
+func (s *KafkaSink) run() {
+	defer s.wg.Done()
+	ticker := time.NewTicker(s.options.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.flushWithTimeout()
+		case <-s.flush:
+			s.flushWithTimeout()
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// flushWithTimeout publishes all pending entries, giving up after the publish
+// timeout.
+func (s *KafkaSink) flushWithTimeout() {
+	ctx, cancel := context.WithTimeout(context.Background(), s.options.timeout)
+	defer cancel()
+	s.Flush(ctx)
+}
+
+// publish sends msgs to topic in batches of at most batchSize messages.
+func (s *KafkaSink) publish(ctx context.Context, topic string, msgs []*KafkaMessage) {
+	for len(msgs) > 0 {
+		n := len(msgs)
+		if s.options.batchSize > 0 && n > s.options.batchSize {
+			n = s.options.batchSize
+		}
+		batch := &KafkaBatch{
+			Topic:       topic,
+			Messages:    msgs[:n],
+			Compression: s.options.compression,
+			Acks:        s.options.acks,
+		}
+		if err := s.producer.Produce(ctx, batch); err != nil {
+			s.options.onError(err)
+		}
+		msgs = msgs[n:]
+	}
+}
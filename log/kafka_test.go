@@ -0,0 +1,157 @@
+package log
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockProducer struct {
+	lock    sync.Mutex
+	batches []*KafkaBatch
+	err     error
+}
+
+func (p *mockProducer) Produce(_ context.Context, batch *KafkaBatch) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.batches = append(p.batches, batch)
+	return p.err
+}
+
+func (p *mockProducer) Batches() []*KafkaBatch {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return append([]*KafkaBatch(nil), p.batches...)
+}
+
+func TestKafkaSink(t *testing.T) {
+	producer := &mockProducer{}
+	sink := NewKafkaSink(producer, "logs",
+		WithKafkaBatchSize(2),
+		WithKafkaFlushInterval(time.Hour),
+		WithKafkaKey(func(b []byte) []byte { return b[:1] }),
+		WithKafkaCompression(KafkaCompressionZstd),
+		WithKafkaAcks(KafkaAcksAll))
+	ctx := Context(context.Background(), WithOutput(sink), WithFormat(testFormat))
+
+	Printf(ctx, "a1")
+	assert.Empty(t, producer.Batches())
+	Printf(ctx, "b2")
+	require.Eventually(t, func() bool { return len(producer.Batches()) == 1 }, time.Second, time.Millisecond)
+	Printf(ctx, "c3")
+	batch := producer.Batches()[0]
+	assert.Equal(t, "logs", batch.Topic)
+	assert.Equal(t, KafkaCompressionZstd, batch.Compression)
+	assert.Equal(t, KafkaAcksAll, batch.Acks)
+	require.Len(t, batch.Messages, 2)
+	assert.Equal(t, &KafkaMessage{Key: []byte("a"), Value: []byte("a1")}, batch.Messages[0])
+	assert.Equal(t, &KafkaMessage{Key: []byte("b"), Value: []byte("b2")}, batch.Messages[1])
+
+	require.NoError(t, sink.Close())
+	require.Len(t, producer.batches, 2)
+	assert.Equal(t, []*KafkaMessage{{Key: []byte("c"), Value: []byte("c3")}}, producer.batches[1].Messages)
+
+	_, err := sink.Write([]byte("closed"))
+	assert.Error(t, err)
+}
+
+func TestKafkaSinkDLQ(t *testing.T) {
+	producer := &mockProducer{}
+	errEncode := errors.New("encode")
+	sink := NewKafkaSink(producer, "logs",
+		WithKafkaFlushInterval(time.Hour),
+		WithKafkaDLQ("logs-dlq"),
+		WithKafkaEncoder(func(b []byte) ([]byte, error) {
+			if string(b) == "bad" {
+				return nil, errEncode
+			}
+			return append([]byte("env:"), b...), nil
+		}))
+	ctx := Context(context.Background(), WithOutput(sink), WithFormat(testFormat))
+
+	Printf(ctx, "good")
+	Printf(ctx, "bad")
+	require.NoError(t, sink.Close())
+
+	require.Len(t, producer.batches, 2)
+	assert.Equal(t, "logs", producer.batches[0].Topic)
+	assert.Equal(t, []*KafkaMessage{{Value: []byte("env:good")}}, producer.batches[0].Messages)
+	assert.Equal(t, "logs-dlq", producer.batches[1].Topic)
+	assert.Equal(t, []*KafkaMessage{{Value: []byte("bad")}}, producer.batches[1].Messages)
+}
+
+func TestKafkaSinkError(t *testing.T) {
+	errProduce := errors.New("produce")
+	producer := &mockProducer{err: errProduce}
+	var got error
+	sink := NewKafkaSink(producer, "logs",
+		WithKafkaFlushInterval(time.Hour),
+		WithKafkaErrorHandler(func(err error) { got = err }))
+	ctx := Context(context.Background(), WithOutput(sink), WithFormat(testFormat))
+
+	Printf(ctx, "msg")
+	require.NoError(t, sink.Close())
+	assert.Equal(t, errProduce, got)
+}
+
+func TestKafkaSinkMaxBuffered(t *testing.T) {
+	producer := &mockProducer{}
+	sink := NewKafkaSink(producer, "logs",
+		WithKafkaFlushInterval(time.Hour),
+		WithKafkaMaxBuffered(2),
+		WithKafkaDLQ("logs-dlq"),
+		WithKafkaEncoder(func(b []byte) ([]byte, error) {
+			if string(b) == "bad" {
+				return nil, errors.New("encode")
+			}
+			return b, nil
+		}))
+	ctx := Context(context.Background(), WithOutput(sink), WithFormat(testFormat))
+
+	for _, msg := range []string{"a", "b", "c", "bad", "bad", "bad"} {
+		Printf(ctx, msg)
+	}
+	assert.Equal(t, uint64(2), sink.Dropped())
+	require.NoError(t, sink.Close())
+
+	require.Len(t, producer.batches, 2)
+	assert.Equal(t, []*KafkaMessage{{Value: []byte("a")}, {Value: []byte("b")}}, producer.batches[0].Messages)
+	assert.Len(t, producer.batches[1].Messages, 2)
+}
+
+type blockingProducer struct{}
+
+func (blockingProducer) Produce(ctx context.Context, _ *KafkaBatch) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestKafkaSinkPublishTimeout(t *testing.T) {
+	var got error
+	sink := NewKafkaSink(blockingProducer{}, "logs",
+		WithKafkaFlushInterval(time.Hour),
+		WithKafkaPublishTimeout(time.Millisecond),
+		WithKafkaErrorHandler(func(err error) { got = err }))
+	ctx := Context(context.Background(), WithOutput(sink), WithFormat(testFormat))
+
+	Printf(ctx, "msg")
+	require.NoError(t, sink.Close())
+	assert.ErrorIs(t, got, context.DeadlineExceeded)
+}
+
+func TestKafkaSinkInvalidOptions(t *testing.T) {
+	producer := &mockProducer{}
+	sink := NewKafkaSink(producer, "logs", WithKafkaFlushInterval(0), WithKafkaBatchSize(-1),
+		WithKafkaMaxBuffered(0), WithKafkaPublishTimeout(-1))
+	assert.Equal(t, DefaultKafkaFlushInterval, sink.options.interval)
+	assert.Equal(t, DefaultKafkaBatchSize, sink.options.batchSize)
+	assert.Equal(t, DefaultKafkaMaxBuffered, sink.options.maxBuffered)
+	assert.Equal(t, DefaultKafkaPublishTimeout, sink.options.timeout)
+	require.NoError(t, sink.Close())
+}
@@ -20,4 +20,6 @@ var (
 	GRPCDurationKey = "grpc.time_ms"
 	GoaServiceKey   = "goa.service"
 	GoaMethodKey    = "goa.method"
+	SpanNameKey     = "span"
+	SpanDurationKey = "span.time_ms"
 )
@@ -26,6 +26,8 @@ type (
 		keyvals kvList
 		entries []*Entry
 		flushed bool
+		// debug is set by ForceDebug.
+		debug bool
 	}
 
 	// Log severity enum
@@ -42,11 +44,7 @@ const (
 )
 
 // Be kind to tests
-var (
-	timeNow   = time.Now
-	timeSince = time.Since
-	osExit    = os.Exit
-)
+var osExit = os.Exit
 
 // Debug writes the key/value pairs to the log output if the log context is
 // configured to log debug messages (via WithDebug).
@@ -116,7 +114,8 @@ func Fatalf(ctx context.Context, err error, format string, v ...interface{}) {
 
 // With creates a copy of the given log context and appends the given key/value
 // pairs to it. Values must be strings, numbers, booleans, nil or a slice of
-// these types.
+// these types. With panics if the logger is configured with WithCanonicalOrder
+// and one of the keys is reserved.
 func With(ctx context.Context, keyvals ...Fielder) context.Context {
 	v := ctx.Value(ctxLogger)
 	if v == nil {
@@ -125,11 +124,15 @@ func With(ctx context.Context, keyvals ...Fielder) context.Context {
 	l := v.(*logger)
 	l.lock.Lock()
 	defer l.lock.Unlock()
+	if l.options.canonicalOrder {
+		kvList(nil).merge(keyvals).validate()
+	}
 	copy := logger{
 		options: l.options,
 		entries: l.entries,
 		keyvals: l.keyvals.merge(keyvals),
 		flushed: l.flushed,
+		debug:   l.debug,
 	}
 	if l.options.disableBuffering != nil && l.options.disableBuffering(ctx) {
 		l.flush()
@@ -156,13 +159,36 @@ func FlushAndDisableBuffering(ctx context.Context) {
 	l.flush()
 }
 
+// ForceDebug returns a copy of the log context that writes all log entries,
+// including debug entries, and disables buffering regardless of the logger
+// configuration and of the Control attached to it. It only affects the returned
+// context and the contexts derived from it, for example to elevate the log
+// level of a single request.
+func ForceDebug(ctx context.Context) context.Context {
+	v := ctx.Value(ctxLogger)
+	if v == nil {
+		return ctx // do nothing if context isn't initialized
+	}
+	l := v.(*logger)
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.flush()
+	copy := logger{
+		options: l.options,
+		keyvals: l.keyvals,
+		flushed: true,
+		debug:   true,
+	}
+	return context.WithValue(ctx, ctxLogger, &copy)
+}
+
 // logger lock must be held when calling this function.
 func (l *logger) flush() {
 	if l.flushed {
 		return
 	}
 	for _, e := range l.entries {
-		l.options.w.Write(l.options.format(e))
+		l.write(e)
 	}
 	l.entries = nil // free up memory
 	l.flushed = true
@@ -177,10 +203,18 @@ func log(ctx context.Context, sev Severity, buffer bool, fielders []Fielder) {
 	l.lock.Lock()
 	defer l.lock.Unlock()
 
-	if !l.options.debug && sev == SeverityDebug {
+	debug := l.options.debug || l.debug
+	if c := l.options.control; c != nil && !l.debug {
+		debug = debug || c.Debug()
+		if !c.enabled(sev, l.keyvals.module(), debug) {
+			return
+		}
+		// Debug entries are only enabled when debugging the module.
+		debug = debug || sev == SeverityDebug
+	} else if !debug && sev == SeverityDebug {
 		return
 	}
-	if l.options.debug && !l.flushed {
+	if debug && !l.flushed {
 		l.flush()
 	}
 
@@ -191,16 +225,29 @@ func log(ctx context.Context, sev Severity, buffer bool, fielders []Fielder) {
 	for _, fn := range l.options.kvfuncs {
 		keyvals = append(keyvals, fn(ctx)...)
 	}
+	if l.options.canonicalOrder {
+		keyvals = keyvals.canonical()
+	}
 	truncate(keyvals, l.options.maxsize)
 
-	e := &Entry{timeNow().UTC(), sev, keyvals}
+	e := &Entry{l.options.clock.Now().UTC(), sev, keyvals}
 	if l.flushed || !buffer {
-		l.options.w.Write(l.options.format(e))
+		l.write(e)
 		return
 	}
 	l.entries = append(l.entries, e)
 }
 
+// write evaluates the lazy values of e and writes it to the logger output.
+// logger lock must be held when calling this function.
+func (l *logger) write(e *Entry) {
+	if keyvals, ok := e.KeyVals.resolve(); ok {
+		truncate(keyvals, l.options.maxsize)
+		e = &Entry{e.Time, e.Severity, keyvals}
+	}
+	l.options.w.Write(l.options.format(e))
+}
+
 // String returns a string representation of the log severity.
 func (l Severity) String() string {
 	switch l {
@@ -272,6 +319,8 @@ func truncate(keyvals []KV, maxsize int) {
 			continue
 		case nil:
 			continue
+		case Lazy:
+			continue // truncated once evaluated
 		default:
 			var buf bytes.Buffer
 			_, err := fmt.Fprintf(newLimitWriter(&buf, maxsize), "%v", kv.V)
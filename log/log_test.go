@@ -13,6 +13,8 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"goa.design/clue/clock"
 )
 
 const (
@@ -24,10 +26,8 @@ const (
 const ServiceName = "service"
 
 func init() {
-	// Mock time.Now for examples and tests
-	timeNow = func() time.Time {
-		return time.Date(2022, time.February, 22, 17, 0, 0, 0, time.UTC)
-	}
+	// Mock the system clock for examples
+	clock.System = clock.NewFake(time.Date(2022, time.February, 22, 17, 0, 0, 0, time.UTC))
 }
 
 func ExamplePrintf() {
@@ -210,6 +210,30 @@ func TestDebug(t *testing.T) {
 	assert.Equal(t, printed+printed, buf.String())
 }
 
+func TestForceDebug(t *testing.T) {
+	var buf bytes.Buffer
+	ctl := NewControl()
+	ctx := Context(context.Background(), WithOutput(&buf), WithFormat(testFormat), WithControl(ctl))
+	Infof(ctx, buffered)
+	ctl.SetSamplingRate("test", 0)
+
+	debugCtx := ForceDebug(ctx)
+	assert.Equal(t, buffered, buf.String(), "buffered entries are flushed")
+	assert.True(t, DebugEnabled(debugCtx))
+	assert.False(t, DebugEnabled(ctx))
+
+	// Debug entries are written regardless of the control sampling rate.
+	Debugf(debugCtx, printed)
+	assert.Equal(t, buffered+printed, buf.String())
+
+	// The original context is not affected.
+	Debugf(ctx, ignored)
+	Infof(ctx, ignored)
+	assert.Equal(t, buffered+printed, buf.String())
+
+	assert.Equal(t, context.Background(), ForceDebug(context.Background()))
+}
+
 func TestStructuredLogging(t *testing.T) {
 	var buf bytes.Buffer
 	ctx := Context(context.Background(), WithOutput(&buf), WithFormat(testFormat))
@@ -348,6 +372,52 @@ func TestChaining(t *testing.T) {
 	}
 }
 
+func TestLazy(t *testing.T) {
+	var buf bytes.Buffer
+	var calls int
+	lazy := Lazy(func() interface{} { calls++; return printed })
+	ctx := Context(context.Background(), WithOutput(&buf), WithFormat(testFormat))
+
+	// Lazy values are not evaluated for ignored entries.
+	Debug(ctx, KV{"msg", lazy})
+	assert.Equal(t, 0, calls)
+	assert.Empty(t, buf.String())
+
+	// Lazy values are not evaluated while entries are buffered.
+	Info(ctx, KV{"msg", lazy})
+	assert.Equal(t, 0, calls)
+	require.Len(t, entries(ctx), 1)
+
+	// Lazy values are evaluated when entries are written.
+	FlushAndDisableBuffering(ctx)
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, printed, buf.String())
+
+	// Lazy values set in the log context are evaluated for each entry.
+	ctx = With(ctx, KV{"key", lazy})
+	Print(ctx, KV{"msg", printed})
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, printed+printed+printed, buf.String())
+}
+
+func TestLazyMaxSize(t *testing.T) {
+	var buf bytes.Buffer
+	ctx := Context(context.Background(), WithOutput(&buf), WithMaxSize(3), WithFormat(testFormat))
+	Print(ctx, KV{"msg", Lazy(func() interface{} { return "toolong" })})
+	assert.Equal(t, "too"+truncationSuffix, buf.String())
+}
+
+func TestCanonicalOrder(t *testing.T) {
+	var buf bytes.Buffer
+	ctx := Context(context.Background(), WithOutput(&buf), WithFormat(FormatText), WithCanonicalOrder())
+	ctx = With(ctx, KV{"svc", "svc"}, KV{TraceIDKey, "trace"})
+	Print(ctx, KV{"b", 2}, KV{"a", 1}, KV{SeverityKey, "dropped"}, KV{MessageKey, "hello"})
+
+	want := "time=2022-02-22T17:00:00Z level=info msg=hello trace-id=trace a=1 b=2 svc=svc\n"
+	assert.Equal(t, want, buf.String())
+	assert.Panics(t, func() { With(ctx, KV{TimestampKey, "reserved"}) })
+}
+
 func TestNoLogging(t *testing.T) {
 	defer func() {
 		if err := recover(); err != nil {
@@ -424,13 +494,8 @@ func TestMaxSize(t *testing.T) {
 	}
 
 	t.Run("example result", func(t *testing.T) {
-		now, epoc := timeNow, epoch
-		timeNow = func() time.Time { return time.Date(2022, time.January, 9, 20, 29, 45, 0, time.UTC) }
-		epoch = timeNow()
-		defer func() { timeNow = now; epoch = epoc }()
-
 		var buf bytes.Buffer
-		ctx := Context(context.Background(), WithOutput(&buf), WithMaxSize(maxsize), WithFormat(FormatText))
+		ctx := Context(context.Background(), WithOutput(&buf), WithMaxSize(maxsize), WithFormat(FormatText), WithClock(testClock()))
 		Print(ctx, KV{"truncated", "it is too long"})
 
 		want := "time=2022-01-09T20:29:45Z level=info truncated=\"it is ... <clue/log.truncated>\"\n"
@@ -454,3 +519,8 @@ func entries(ctx context.Context) []*Entry {
 	defer l.lock.Unlock()
 	return l.entries
 }
+
+// testClock returns a fake clock set to the time used by the tests.
+func testClock() *clock.Fake {
+	return clock.NewFake(time.Date(2022, time.January, 9, 20, 29, 45, 0, time.UTC))
+}
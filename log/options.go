@@ -9,6 +9,8 @@ import (
 
 	"golang.org/x/term"
 
+	"goa.design/clue/clock"
+
 	"go.opentelemetry.io/otel/trace"
 )
 
@@ -31,9 +33,16 @@ type (
 		keyvals          kvList
 		kvfuncs          []func(context.Context) []KV
 		maxsize          int
+		canonicalOrder   bool
+		control          *Control
+		clock            clock.Clock
 	}
 )
 
+// instrumentationName is the name of the instrumentation library used to
+// create spans.
+const instrumentationName = "goa.design/clue/log"
+
 // DefaultMaxSize is the default maximum size of a single log message or value
 // in bytes. It's also the maximum number of elements in a slice value.
 const DefaultMaxSize = 1024
@@ -89,6 +98,18 @@ func WithMaxSize(n int) LogOption {
 	}
 }
 
+// WithCanonicalOrder enforces a canonical ordering of the key/value pairs of
+// each log entry: the timestamp and severity (written by the formatter) come
+// first followed by the MessageKey and TraceIDKey keys if present and the
+// remaining keys sorted alphabetically. Keys that collide with the reserved
+// TimestampKey and SeverityKey keys cause With to panic and are dropped from
+// individual log entries.
+func WithCanonicalOrder() LogOption {
+	return func(o *options) {
+		o.canonicalOrder = true
+	}
+}
+
 // WithFileLocation adds the "file" key to each log entry with the parent
 // directory, file and line number of the caller: "file=dir/file.go:123".
 func WithFileLocation() LogOption {
@@ -117,6 +138,17 @@ func WithFunc(fn func(context.Context) []KV) LogOption {
 	}
 }
 
+// WithClock sets the clock used to timestamp log entries and to measure the
+// durations logged by the HTTP client, gRPC client interceptors and Span. The
+// default is the clock stored in the context given to Context if any,
+// clock.System otherwise. Tests may use a clock.Fake to control the logged
+// values.
+func WithClock(c clock.Clock) LogOption {
+	return func(o *options) {
+		o.clock = c
+	}
+}
+
 // IsTerminal returns true if the process is running in a terminal.
 func IsTerminal() bool {
 	return term.IsTerminal(int(os.Stdout.Fd()))
@@ -61,6 +61,12 @@ func TestWithMaxSize(t *testing.T) {
 	assert.Equal(t, opts.maxsize, 10)
 }
 
+func TestWithCanonicalOrder(t *testing.T) {
+	opts := defaultOptions()
+	WithCanonicalOrder()(opts)
+	assert.True(t, opts.canonicalOrder)
+}
+
 func TestIsTracing(t *testing.T) {
 	if IsTracing(context.Background()) {
 		t.Errorf("expected IsTracing to return false")
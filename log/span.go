@@ -0,0 +1,44 @@
+package log
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Span starts a span named name and returns the span context together with a
+// function that must be called to end the span. If the request is traced (see
+// IsTracing) Span starts a child OpenTelemetry span using the tracer provider
+// of the current span. Otherwise Span writes an info entry when the span
+// starts and another when it ends including the duration in milliseconds so
+// that services that do not enable tracing still get coarse timing breakdowns.
+//
+// Usage:
+//
+//	ctx, end := log.Span(ctx, "load-config", log.KV{"path", path})
+//	defer end()
+func Span(ctx context.Context, name string, keyvals ...Fielder) (context.Context, func()) {
+	if span := trace.SpanFromContext(ctx); span.SpanContext().IsValid() {
+		attrs := Attributes(keyvals...)
+		ctx, child := span.TracerProvider().Tracer(instrumentationName).Start(ctx, name, trace.WithAttributes(attrs...))
+		return ctx, func() { child.End() }
+	}
+	spanKV := KV{K: SpanNameKey, V: name}
+	fielders := make([]Fielder, 0, len(keyvals)+2)
+	fielders = append(fielders, KV{K: MessageKey, V: "span started"}, spanKV)
+	Info(ctx, append(fielders, keyvals...)...)
+	clock := clockFrom(ctx)
+	then := clock.Now()
+	return ctx, func() {
+		ms := clock.Since(then).Milliseconds()
+		Info(ctx, KV{K: MessageKey, V: "span ended"}, spanKV, KV{K: SpanDurationKey, V: ms})
+	}
+}
+
+// Attributes returns the OpenTelemetry attributes corresponding to the given
+// key/value pairs. Values that are not strings, booleans, integers or floats
+// are formatted with fmt.Sprint.
+func Attributes(keyvals ...Fielder) []attribute.KeyValue {
+	return kvList(nil).merge(keyvals).attributes()
+}
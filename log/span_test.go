@@ -0,0 +1,61 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestSpanFallback(t *testing.T) {
+	var buf bytes.Buffer
+	clock := testClock()
+	ctx := Context(context.Background(), WithOutput(&buf), WithFormat(FormatText), WithClock(clock))
+	FlushAndDisableBuffering(ctx)
+	_, end := Span(ctx, "load", KV{"key", "val"})
+	clock.Advance(42 * time.Millisecond)
+	end()
+
+	want := "time=2022-01-09T20:29:45Z level=info msg=\"span started\" span=load key=val\n" +
+		"time=2022-01-09T20:29:45Z level=info msg=\"span ended\" span=load span.time_ms=42\n"
+	assert.Equal(t, want, buf.String())
+}
+
+func TestSpanTraced(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "parent")
+
+	var buf bytes.Buffer
+	ctx = Context(ctx, WithOutput(&buf))
+	spanCtx, end := Span(ctx, "child", KV{"key", "val"})
+	end()
+	parent.End()
+
+	assert.Empty(t, buf.String())
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "child", spans[0].Name)
+	assert.Equal(t, parent.SpanContext().SpanID(), spans[0].Parent.SpanID())
+	assert.Equal(t, "val", spans[0].Attributes[0].Value.AsString())
+	assert.NotEqual(t, ctx, spanCtx)
+}
+
+func TestAttributes(t *testing.T) {
+	attrs := Attributes(KV{"s", "val"}, KV{"b", true}, KV{"i", 1}, KV{"i64", int64(2)}, KV{"f", 1.5}, KV{"o", []int{1}})
+	want := []attribute.KeyValue{
+		attribute.String("s", "val"),
+		attribute.Bool("b", true),
+		attribute.Int("i", 1),
+		attribute.Int64("i64", 2),
+		attribute.Float64("f", 1.5),
+		attribute.String("o", "[1]"),
+	}
+	assert.Equal(t, want, attrs)
+}
@@ -2,8 +2,11 @@ package metrics
 
 import (
 	"context"
+	"runtime/debug"
 
 	"github.com/prometheus/client_golang/prometheus"
+
+	"goa.design/clue/clock"
 )
 
 type (
@@ -12,10 +15,15 @@ type (
 	// interceptors. This state is only needed during initialization and is
 	// not intended to be kept in request contexts.
 	stateBag struct {
-		options     *options
-		svc         string
-		httpMetrics *httpMetrics
-		grpcMetrics *grpcMetrics
+		options       *options
+		svc           string
+		httpMetrics   *httpMetrics
+		grpcMetrics   *grpcMetrics
+		spanMetrics   *spanMetrics
+		redisMetrics  *redisMetrics
+		healthMetrics *healthMetrics
+		certMetrics   *certMetrics
+		gcMetrics     *gcMetrics
 	}
 
 	// httpMetrics is the set of HTTP Metrics used by this package interceptors.
@@ -46,6 +54,49 @@ type (
 		StreamResultSizes *prometheus.HistogramVec
 	}
 
+	// spanMetrics is the set of metrics derived from server spans.
+	spanMetrics struct {
+		// Requests is a counter of the number of requests.
+		Requests *prometheus.CounterVec
+		// Errors is a counter of the number of requests that failed.
+		Errors *prometheus.CounterVec
+		// Durations is a histogram of the duration of requests.
+		Durations *prometheus.HistogramVec
+	}
+
+	// redisMetrics is the set of Redis client metrics.
+	redisMetrics struct {
+		// Durations is a histogram of the duration of commands.
+		Durations *prometheus.HistogramVec
+	}
+
+	// healthMetrics is the set of health check metrics.
+	healthMetrics struct {
+		// Up is a gauge of the health of each dependency.
+		Up *prometheus.GaugeVec
+		// Durations is a histogram of the duration of health checks.
+		Durations *prometheus.HistogramVec
+	}
+
+	// certMetrics is the set of certificate metrics.
+	certMetrics struct {
+		// ExpiryDays is a gauge of the number of days until the expiry
+		// of each certificate.
+		ExpiryDays *prometheus.GaugeVec
+	}
+
+	// gcMetrics is the set of garbage collector tuning metrics.
+	gcMetrics struct {
+		// MemoryLimit is a gauge of the soft memory limit.
+		MemoryLimit prometheus.GaugeFunc
+		// Percent is a gauge of the GC target percentage.
+		Percent prometheus.GaugeFunc
+		// Ballast is a gauge of the size of the memory ballast.
+		Ballast prometheus.GaugeFunc
+		// CPUFraction is a gauge of the fraction of CPU used by the GC.
+		CPUFraction prometheus.GaugeFunc
+	}
+
 	// Private type used to define context keys.
 	ctxKey int
 )
@@ -71,6 +122,29 @@ const (
 	metricRPCStreamMessageSize = "rpc_server_stream_message_size_bytes"
 	// metricRPCStreamResponseSize is the name of the gRPC stream response size metric.
 	metricRPCStreamResponseSize = "rpc_server_stream_response_size_bytes"
+	// metricSpanRequests is the name of the span derived requests metric.
+	metricSpanRequests = "span_server_requests_total"
+	// metricSpanErrors is the name of the span derived errors metric.
+	metricSpanErrors = "span_server_errors_total"
+	// metricSpanDuration is the name of the span derived duration metric.
+	metricSpanDuration = "span_server_duration_ms"
+	// metricRedisDuration is the name of the Redis command duration metric.
+	metricRedisDuration = "redis_client_duration_ms"
+	// metricDependencyUp is the name of the dependency health metric.
+	metricDependencyUp = "service_dependency_up"
+	// metricHealthCheckDuration is the name of the health check duration
+	// metric.
+	metricHealthCheckDuration = "health_check_duration_ms"
+	// metricCertExpiryDays is the name of the certificate expiry metric.
+	metricCertExpiryDays = "tls_certificate_expiry_days"
+	// metricGCMemoryLimit is the name of the soft memory limit metric.
+	metricGCMemoryLimit = "runtime_gc_memory_limit_bytes"
+	// metricGCPercent is the name of the GC target percentage metric.
+	metricGCPercent = "runtime_gc_percent"
+	// metricGCBallast is the name of the memory ballast size metric.
+	metricGCBallast = "runtime_gc_ballast_bytes"
+	// metricGCCPUFraction is the name of the GC CPU fraction metric.
+	metricGCCPUFraction = "runtime_gc_cpu_fraction"
 	// labelGoaService is the name of the label containing the Goa service name.
 	labelGoaService = "goa_service"
 	// labelHTTPVerb is the name of the label containing the HTTP verb.
@@ -91,6 +165,29 @@ const (
 	labelRPCMethod = "rpc_method"
 	// labelRPCStatusCode is the name of the RPC status code label.
 	labelRPCStatusCode = "rpc_status_code"
+	// labelSynthetic is the name of the label that indicates whether the
+	// request was made by a synthetic source.
+	labelSynthetic = "synthetic"
+	// labelSpanName is the name of the label containing the span name.
+	labelSpanName = "span_name"
+	// labelRedisCommand is the name of the label containing the Redis
+	// command.
+	labelRedisCommand = "redis_command"
+	// labelRedisStatus is the name of the label containing the Redis
+	// command status.
+	labelRedisStatus = "redis_status"
+	// labelDependency is the name of the label containing the name of a
+	// dependency checked by the health checker.
+	labelDependency = "dependency"
+	// labelHealthStatus is the name of the label containing the result of
+	// a health check.
+	labelHealthStatus = "health_status"
+	// labelCertSource is the name of the label containing the source of a
+	// certificate (file path or "memory").
+	labelCertSource = "certificate_source"
+	// labelCertSubject is the name of the label containing the subject of
+	// a certificate.
+	labelCertSubject = "certificate_subject"
 )
 
 const (
@@ -115,6 +212,24 @@ var (
 	// NoCode is the set of dynamic labels used for active gRPC requests
 	// metric and stream message and result size metrics.
 	rpcNoCodeLabels = []string{labelPeerIP, labelPeerPort, labelRPCService, labelRPCMethod}
+
+	// spanLabels is the set of dynamic labels used for span derived metrics.
+	spanLabels = []string{labelSpanName}
+
+	// redisLabels is the set of dynamic labels used for Redis metrics.
+	redisLabels = []string{labelRedisCommand, labelRedisStatus}
+
+	// dependencyLabels is the set of dynamic labels used for the
+	// dependency health metric.
+	dependencyLabels = []string{labelDependency}
+
+	// healthCheckLabels is the set of dynamic labels used for the health
+	// check duration metric.
+	healthCheckLabels = []string{labelDependency, labelHealthStatus}
+
+	// certLabels is the set of dynamic labels used for the certificate
+	// expiry metric.
+	certLabels = []string{labelDependency, labelCertSource, labelCertSubject}
 )
 
 // Context initializes the given context for the HTTP, UnaryInterceptor and
@@ -128,6 +243,9 @@ func Context(ctx context.Context, svc string, opts ...Option) context.Context {
 	for _, o := range opts {
 		o(options)
 	}
+	if options.clock == nil {
+		options.clock = clock.FromContext(ctx)
+	}
 
 	return context.WithValue(ctx, stateBagKey, &stateBag{options: options, svc: svc})
 }
@@ -137,12 +255,18 @@ func (state *stateBag) HTTPMetrics() *httpMetrics {
 		return state.httpMetrics
 	}
 
+	labels, activeLabels := httpLabels, httpActiveRequestsLabels
+	if len(state.options.synthetic) > 0 {
+		labels = append(labels[:len(labels):len(labels)], labelSynthetic)
+		activeLabels = append(activeLabels[:len(activeLabels):len(activeLabels)], labelSynthetic)
+	}
+
 	durations := prometheus.NewHistogramVec(prometheus.HistogramOpts{
 		Name:        metricHTTPDuration,
 		Help:        "Histogram of request durations in milliseconds.",
 		ConstLabels: prometheus.Labels{labelGoaService: state.svc},
 		Buckets:     state.options.durationBuckets,
-	}, httpLabels)
+	}, labels)
 	state.options.registerer.MustRegister(durations)
 
 	reqSizes := prometheus.NewHistogramVec(prometheus.HistogramOpts{
@@ -150,7 +274,7 @@ func (state *stateBag) HTTPMetrics() *httpMetrics {
 		Help:        "Histogram of request sizes in bytes.",
 		ConstLabels: prometheus.Labels{labelGoaService: state.svc},
 		Buckets:     state.options.requestSizeBuckets,
-	}, httpLabels)
+	}, labels)
 	state.options.registerer.MustRegister(reqSizes)
 
 	respSizes := prometheus.NewHistogramVec(prometheus.HistogramOpts{
@@ -158,14 +282,14 @@ func (state *stateBag) HTTPMetrics() *httpMetrics {
 		Help:        "Histogram of response sizes in bytes.",
 		ConstLabels: prometheus.Labels{labelGoaService: state.svc},
 		Buckets:     state.options.responseSizeBuckets,
-	}, httpLabels)
+	}, labels)
 	state.options.registerer.MustRegister(respSizes)
 
 	activeReqs := prometheus.NewGaugeVec(prometheus.GaugeOpts{
 		Name:        metricHTTPActiveRequests,
 		Help:        "Gauge of active requests.",
 		ConstLabels: prometheus.Labels{labelGoaService: state.svc},
-	}, httpActiveRequestsLabels)
+	}, activeLabels)
 	state.options.registerer.MustRegister(activeReqs)
 
 	state.httpMetrics = &httpMetrics{
@@ -241,3 +365,127 @@ func (state *stateBag) GRPCMetrics() *grpcMetrics {
 
 	return state.grpcMetrics
 }
+
+func (state *stateBag) SpanMetrics() *spanMetrics {
+	if state.spanMetrics != nil {
+		return state.spanMetrics
+	}
+
+	requests := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name:        metricSpanRequests,
+		Help:        "Counter of requests derived from server spans.",
+		ConstLabels: prometheus.Labels{labelGoaService: state.svc},
+	}, spanLabels)
+	state.options.registerer.MustRegister(requests)
+
+	errors := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name:        metricSpanErrors,
+		Help:        "Counter of failed requests derived from server spans.",
+		ConstLabels: prometheus.Labels{labelGoaService: state.svc},
+	}, spanLabels)
+	state.options.registerer.MustRegister(errors)
+
+	durations := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:        metricSpanDuration,
+		Help:        "Histogram of request durations in milliseconds derived from server spans.",
+		ConstLabels: prometheus.Labels{labelGoaService: state.svc},
+		Buckets:     state.options.durationBuckets,
+	}, spanLabels)
+	state.options.registerer.MustRegister(durations)
+
+	state.spanMetrics = &spanMetrics{
+		Requests:  requests,
+		Errors:    errors,
+		Durations: durations,
+	}
+
+	return state.spanMetrics
+}
+
+func (state *stateBag) RedisMetrics() *redisMetrics {
+	if state.redisMetrics != nil {
+		return state.redisMetrics
+	}
+
+	durations := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:        metricRedisDuration,
+		Help:        "Histogram of Redis command durations in milliseconds.",
+		ConstLabels: prometheus.Labels{labelGoaService: state.svc},
+		Buckets:     state.options.durationBuckets,
+	}, redisLabels)
+	state.options.registerer.MustRegister(durations)
+
+	state.redisMetrics = &redisMetrics{Durations: durations}
+
+	return state.redisMetrics
+}
+
+func (state *stateBag) HealthMetrics() *healthMetrics {
+	if state.healthMetrics != nil {
+		return state.healthMetrics
+	}
+
+	up := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name:        metricDependencyUp,
+		Help:        "Health of service dependencies, 1 if healthy and 0 otherwise.",
+		ConstLabels: prometheus.Labels{labelGoaService: state.svc},
+	}, dependencyLabels)
+	state.options.registerer.MustRegister(up)
+
+	durations := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:        metricHealthCheckDuration,
+		Help:        "Histogram of dependency health check durations in milliseconds.",
+		ConstLabels: prometheus.Labels{labelGoaService: state.svc},
+		Buckets:     state.options.durationBuckets,
+	}, healthCheckLabels)
+	state.options.registerer.MustRegister(durations)
+
+	state.healthMetrics = &healthMetrics{Up: up, Durations: durations}
+
+	return state.healthMetrics
+}
+
+func (state *stateBag) CertMetrics() *certMetrics {
+	if state.certMetrics != nil {
+		return state.certMetrics
+	}
+
+	expiry := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name:        metricCertExpiryDays,
+		Help:        "Number of days until the expiry of certificates, negative once expired.",
+		ConstLabels: prometheus.Labels{labelGoaService: state.svc},
+	}, certLabels)
+	state.options.registerer.MustRegister(expiry)
+
+	state.certMetrics = &certMetrics{ExpiryDays: expiry}
+
+	return state.certMetrics
+}
+
+func (state *stateBag) GCMetrics() *gcMetrics {
+	if state.gcMetrics != nil {
+		return state.gcMetrics
+	}
+
+	newGauge := func(name, help string, fn func() float64) prometheus.GaugeFunc {
+		g := prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name:        name,
+			Help:        help,
+			ConstLabels: prometheus.Labels{labelGoaService: state.svc},
+		}, fn)
+		state.options.registerer.MustRegister(g)
+		return g
+	}
+	state.gcMetrics = &gcMetrics{
+		MemoryLimit: newGauge(metricGCMemoryLimit, "Soft memory limit of the runtime in bytes.",
+			func() float64 { return float64(debug.SetMemoryLimit(-1)) }),
+		Percent: newGauge(metricGCPercent, "GC target percentage, -1 if the GC is disabled.",
+			func() float64 { return float64(gcPercent.Load()) }),
+		Ballast: newGauge(metricGCBallast, "Size of the memory ballast in bytes.",
+			func() float64 { return float64(ballastSize()) }),
+		CPUFraction: newGauge(metricGCCPUFraction, "Fraction of the available CPU time used by the GC since the program started.",
+			gcCPUFraction),
+	}
+
+	return state.gcMetrics
+}
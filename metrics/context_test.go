@@ -3,6 +3,9 @@ package metrics
 import (
 	"context"
 	"testing"
+	"time"
+
+	"goa.design/clue/clock"
 )
 
 func TestContext(t *testing.T) {
@@ -67,3 +70,20 @@ func sameOptions(a, b *options) bool {
 	}
 	return true
 }
+
+func TestContextClock(t *testing.T) {
+	clk := clock.NewFake(time.Now())
+	ctx := Context(context.Background(), "testsvc")
+	if got := ctx.Value(stateBagKey).(*stateBag).options.clock; got != clock.System {
+		t.Errorf("got clock %v, want system clock", got)
+	}
+	ctx = Context(clock.Context(context.Background(), clk), "testsvc")
+	if got := ctx.Value(stateBagKey).(*stateBag).options.clock; got != clk {
+		t.Errorf("expected the clock stored in the context to be used")
+	}
+	other := clock.NewFake(time.Now())
+	ctx = Context(clock.Context(context.Background(), clk), "testsvc", WithClock(other))
+	if got := ctx.Value(stateBagKey).(*stateBag).options.clock; got != other {
+		t.Errorf("expected WithClock to take precedence over the context clock")
+	}
+}
@@ -0,0 +1,190 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+type (
+	// GCOption is a function that configures the garbage collector tuned by
+	// TuneGC.
+	GCOption func(*gcOptions)
+
+	gcOptions struct {
+		memoryLimit int64
+		limitRatio  float64
+		gcPercent   *int
+		ballast     int
+	}
+)
+
+var (
+	// ballast is the memory ballast allocated by TuneGC, it is never
+	// accessed but must be kept reachable.
+	ballast     []byte
+	ballastLock sync.Mutex
+
+	// gcPercent is the GC percent set by TuneGC, -1 if GC is off and
+	// math.MinInt64 if unknown.
+	gcPercent atomic.Int64
+
+	// cgroupRoot is the mount point of the cgroup file system.
+	cgroupRoot = "/sys/fs/cgroup"
+)
+
+func init() {
+	gcPercent.Store(math.MinInt64)
+}
+
+// TuneGC configures the garbage collector using the given options and
+// registers gauges that report the configured values so that memory tuning
+// changes are visible in dashboards. The context must have been initialized
+// with Context. TuneGC collects the following metrics:
+//
+//   - `runtime_gc_memory_limit_bytes`: Gauge of the soft memory limit of the
+//     runtime (GOMEMLIMIT).
+//   - `runtime_gc_percent`: Gauge of the GC target percentage (GOGC), -1 if
+//     the GC is disabled.
+//   - `runtime_gc_ballast_bytes`: Gauge of the size of the memory ballast.
+//   - `runtime_gc_cpu_fraction`: Gauge of the fraction of the available CPU
+//     time used by the GC since the program started.
+//
+// Values not set via options are left untouched so that the GOMEMLIMIT and
+// GOGC environment variables still apply.
+//
+// Example:
+//
+//	// Leave 10% of the container memory limit for non-heap memory.
+//	if err := metrics.TuneGC(ctx, metrics.WithMemoryLimitRatio(0.9)); err != nil {
+//		log.Error(ctx, err)
+//	}
+func TuneGC(ctx context.Context, opts ...GCOption) error {
+	b := ctx.Value(stateBagKey)
+	if b == nil {
+		panic("initialize context with Context first")
+	}
+	o := &gcOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	b.(*stateBag).GCMetrics()
+	if o.limitRatio > 0 {
+		limit, err := cgroupMemoryLimit()
+		if err != nil {
+			return fmt.Errorf("failed to read container memory limit: %w", err)
+		}
+		o.memoryLimit = int64(float64(limit) * o.limitRatio)
+	}
+	if o.memoryLimit > 0 {
+		debug.SetMemoryLimit(o.memoryLimit)
+	}
+	if o.gcPercent != nil {
+		debug.SetGCPercent(*o.gcPercent)
+		gcPercent.Store(int64(*o.gcPercent))
+	} else if gcPercent.Load() == math.MinInt64 {
+		gcPercent.Store(int64(readGCPercent()))
+	}
+	if o.ballast > 0 {
+		ballastLock.Lock()
+		ballast = make([]byte, o.ballast)
+		ballastLock.Unlock()
+	}
+	return nil
+}
+
+// WithMemoryLimit sets the soft memory limit of the runtime in bytes, see
+// runtime/debug.SetMemoryLimit.
+func WithMemoryLimit(bytes int64) GCOption {
+	return func(o *gcOptions) {
+		o.memoryLimit = bytes
+	}
+}
+
+// WithMemoryLimitRatio sets the soft memory limit of the runtime to the given
+// ratio (e.g. 0.9) of the memory limit of the container the process runs in.
+// The container memory limit is read from the cgroup file system, TuneGC
+// returns an error if there is none. It takes precedence over WithMemoryLimit.
+func WithMemoryLimitRatio(ratio float64) GCOption {
+	return func(o *gcOptions) {
+		o.limitRatio = ratio
+	}
+}
+
+// WithGCPercent sets the GC target percentage, see
+// runtime/debug.SetGCPercent. A negative value disables the GC.
+func WithGCPercent(percent int) GCOption {
+	return func(o *gcOptions) {
+		o.gcPercent = &percent
+	}
+}
+
+// WithBallast allocates a memory ballast of the given size in bytes. The
+// ballast increases the heap size the GC targets without using physical memory
+// so that services with small live heaps are not collected too often. Prefer
+// WithMemoryLimit combined with WithGCPercent when possible.
+func WithBallast(bytes int) GCOption {
+	return func(o *gcOptions) {
+		o.ballast = bytes
+	}
+}
+
+// ballastSize returns the size of the memory ballast.
+func ballastSize() int {
+	ballastLock.Lock()
+	defer ballastLock.Unlock()
+	return len(ballast)
+}
+
+// readGCPercent returns the current GC target percentage. The GC percent
+// cannot be read without setting it so this briefly sets it to its current
+// value.
+func readGCPercent() int {
+	p := debug.SetGCPercent(100)
+	debug.SetGCPercent(p)
+	return p
+}
+
+// gcCPUFraction returns the fraction of the CPU time used by the GC.
+func gcCPUFraction() float64 {
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	return ms.GCCPUFraction
+}
+
+// cgroupMemoryLimit returns the memory limit of the cgroup of the process
+// using the cgroup v2 interface or the cgroup v1 interface.
+func cgroupMemoryLimit() (int64, error) {
+	for _, path := range []string{"memory.max", "memory/memory.limit_in_bytes"} {
+		b, err := os.ReadFile(filepath.Join(cgroupRoot, path))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return 0, err
+		}
+		v := strings.TrimSpace(string(b))
+		if v == "max" {
+			return 0, errors.New("no memory limit")
+		}
+		limit, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return 0, err
+		}
+		// cgroup v1 reports a very large number when there is no limit.
+		if limit >= math.MaxInt64/2 {
+			return 0, errors.New("no memory limit")
+		}
+		return limit, nil
+	}
+	return 0, errors.New("no cgroup memory controller")
+}
@@ -0,0 +1,92 @@
+package metrics
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"testing"
+)
+
+func TestTuneGC(t *testing.T) {
+	limit := debug.SetMemoryLimit(-1)
+	percent := debug.SetGCPercent(100)
+	debug.SetGCPercent(percent)
+	t.Cleanup(func() {
+		debug.SetMemoryLimit(limit)
+		debug.SetGCPercent(percent)
+		ballast = nil
+	})
+
+	reg := NewTestRegistry(t)
+	ctx := Context(context.Background(), "testsvc", WithRegisterer(reg))
+	err := TuneGC(ctx, WithMemoryLimit(1<<30), WithGCPercent(200), WithBallast(1<<20))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := debug.SetMemoryLimit(-1); got != 1<<30 {
+		t.Errorf("got memory limit %d, expected %d", got, 1<<30)
+	}
+	reg.AssertGauge(metricGCMemoryLimit, nil, 1<<30)
+	reg.AssertGauge(metricGCPercent, nil, 200)
+	reg.AssertGauge(metricGCBallast, nil, 1<<20)
+	if m := reg.findMetric(metricGCCPUFraction, nil); m.Gauge == nil {
+		t.Errorf("expected GC CPU fraction gauge")
+	}
+
+	// Calling TuneGC again must not register the metrics twice.
+	if err := TuneGC(ctx, WithGCPercent(-1)); err != nil {
+		t.Fatal(err)
+	}
+	reg.AssertGauge(metricGCPercent, nil, -1)
+}
+
+func TestTuneGCMemoryLimitRatio(t *testing.T) {
+	limit := debug.SetMemoryLimit(-1)
+	t.Cleanup(func() { debug.SetMemoryLimit(limit) })
+	restore := cgroupRoot
+	t.Cleanup(func() { cgroupRoot = restore })
+
+	cases := []struct {
+		name          string
+		file          string
+		content       string
+		expectedLimit int64
+		expectErr     bool
+	}{
+		{"cgroup v2", "memory.max", "1000\n", 900, false},
+		{"cgroup v1", "memory/memory.limit_in_bytes", "2000\n", 1800, false},
+		{"cgroup v2 no limit", "memory.max", "max\n", 0, true},
+		{"cgroup v1 no limit", "memory/memory.limit_in_bytes", "9223372036854771712\n", 0, true},
+		{"no cgroup", "", "", 0, true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cgroupRoot = t.TempDir()
+			if c.file != "" {
+				path := filepath.Join(cgroupRoot, c.file)
+				if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, []byte(c.content), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			ctx := Context(context.Background(), "testsvc", WithRegisterer(NewTestRegistry(t)))
+			err := TuneGC(ctx, WithMemoryLimitRatio(0.9))
+			if c.expectErr {
+				if err == nil {
+					t.Error("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := debug.SetMemoryLimit(-1); got != c.expectedLimit {
+				t.Errorf("got memory limit %d, expected %d", got, c.expectedLimit)
+			}
+		})
+	}
+}
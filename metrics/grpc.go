@@ -49,6 +49,7 @@ func UnaryServerInterceptor(ctx context.Context) grpc.UnaryServerInterceptor {
 		panic("initialize context with Context first")
 	}
 	metrics := b.(*stateBag).GRPCMetrics()
+	clock := b.(*stateBag).options.clock
 
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
 		service, method := parseGRPCFullMethodName(info.FullMethod)
@@ -64,12 +65,12 @@ func UnaryServerInterceptor(ctx context.Context) grpc.UnaryServerInterceptor {
 		metrics.ActiveRequests.With(labels).Add(1)
 		defer metrics.ActiveRequests.With(labels).Sub(1)
 
-		now := time.Now()
+		now := clock.Now()
 		resp, err := handler(ctx, req)
 
 		st, _ := status.FromError(err)
 		labels[labelRPCStatusCode] = strconv.Itoa(int(st.Code()))
-		metrics.Durations.With(labels).Observe(float64(timeSince(now)) / float64(time.Millisecond))
+		metrics.Durations.With(labels).Observe(float64(clock.Since(now)) / float64(time.Millisecond))
 		if msg, ok := req.(proto.Message); ok {
 			metrics.RequestSizes.With(labels).Observe(float64(proto.Size(msg)))
 		}
@@ -107,6 +108,7 @@ func StreamServerInterceptor(ctx context.Context) grpc.StreamServerInterceptor {
 		panic("metrics not found in context, initialize context with Context first")
 	}
 	metrics := b.(*stateBag).GRPCMetrics()
+	clock := b.(*stateBag).options.clock
 
 	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
 		service, method := parseGRPCFullMethodName(info.FullMethod)
@@ -122,14 +124,14 @@ func StreamServerInterceptor(ctx context.Context) grpc.StreamServerInterceptor {
 		metrics.ActiveRequests.With(labels).Add(1)
 		defer metrics.ActiveRequests.With(labels).Sub(1)
 
-		now := time.Now()
+		now := clock.Now()
 		wrapper := streamWrapper{stream, labels, metrics.StreamMessageSizes, metrics.StreamResultSizes}
 		err := handler(srv, &wrapper)
 
 		st, _ := status.FromError(err)
 		labels[labelRPCStatusCode] = strconv.Itoa(int(st.Code()))
 
-		metrics.Durations.With(labels).Observe(float64(timeSince(now)) / float64(time.Millisecond))
+		metrics.Durations.With(labels).Observe(float64(clock.Since(now)) / float64(time.Millisecond))
 
 		return err
 	}
@@ -7,7 +7,8 @@ import (
 	"testing"
 	"time"
 
-	"goa.design/clue/internal/testsvc"
+	"goa.design/clue/clock"
+	"goa.design/clue/testing/testsvc"
 	"google.golang.org/grpc"
 )
 
@@ -24,16 +25,13 @@ func TestUnaryServerInterceptorServerDuration(t *testing.T) {
 	}
 	for _, c := range cases {
 		t.Run(c.name, func(t *testing.T) {
-			restore := timeSince
-			defer func() { timeSince = restore }()
-			timeSince = func(time.Time) time.Duration { return c.d }
-
+			clk := clock.NewFake(time.Now())
 			reg := NewTestRegistry(t)
-			ctx := Context(context.Background(), "testsvc", WithRegisterer(reg), WithDurationBuckets(buckets))
+			ctx := Context(context.Background(), "testsvc", WithRegisterer(reg), WithDurationBuckets(buckets), WithClock(clk))
 			uinter := UnaryServerInterceptor(ctx)
 			cli, stop := testsvc.SetupGRPC(t,
 				testsvc.WithServerOptions(grpc.UnaryInterceptor(uinter)),
-				testsvc.WithUnaryFunc(noopMethod()))
+				testsvc.WithUnaryFunc(advanceMethod(clk, c.d)))
 
 			_, err := cli.GRPCMethod(context.Background(), &testsvc.Fields{})
 			if err != nil {
@@ -156,16 +154,13 @@ func TestStreamServerInterceptorServerDuration(t *testing.T) {
 	}
 	for _, c := range cases {
 		t.Run(c.name, func(t *testing.T) {
-			restore := timeSince
-			defer func() { timeSince = restore }()
-			timeSince = func(time.Time) time.Duration { return c.d }
-
+			clk := clock.NewFake(time.Now())
 			reg := NewTestRegistry(t)
-			ctx := Context(context.Background(), "testsvc", WithRegisterer(reg), WithDurationBuckets(buckets))
+			ctx := Context(context.Background(), "testsvc", WithRegisterer(reg), WithDurationBuckets(buckets), WithClock(clk))
 			sinter := StreamServerInterceptor(ctx)
 			cli, stop := testsvc.SetupGRPC(t,
 				testsvc.WithServerOptions(grpc.StreamInterceptor(sinter)),
-				testsvc.WithStreamFunc(echoMethod()))
+				testsvc.WithStreamFunc(advanceEchoMethod(clk, c.d)))
 
 			stream, err := cli.GRPCStream(context.Background())
 			if err != nil {
@@ -306,6 +301,13 @@ func noopMethod() testsvc.UnaryFunc {
 	}
 }
 
+func advanceMethod(clk *clock.Fake, d time.Duration) testsvc.UnaryFunc {
+	return func(_ context.Context, _ *testsvc.Fields) (*testsvc.Fields, error) {
+		clk.Advance(d)
+		return &testsvc.Fields{}, nil
+	}
+}
+
 func stringMethod(str string) testsvc.UnaryFunc {
 	return func(_ context.Context, _ *testsvc.Fields) (*testsvc.Fields, error) {
 		return &testsvc.Fields{S: &str}, nil
@@ -321,6 +323,14 @@ func waitMethod(running, done *sync.WaitGroup, stop chan struct{}) testsvc.Unary
 	}
 }
 
+func advanceEchoMethod(clk *clock.Fake, d time.Duration) testsvc.StreamFunc {
+	echo := echoMethod()
+	return func(ctx context.Context, stream testsvc.Stream) error {
+		clk.Advance(d)
+		return echo(ctx, stream)
+	}
+}
+
 func echoMethod() testsvc.StreamFunc {
 	return func(_ context.Context, stream testsvc.Stream) (err error) {
 		f, err := stream.Recv()
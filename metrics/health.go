@@ -0,0 +1,102 @@
+package metrics
+
+import (
+	"context"
+	"crypto/x509"
+	"math"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"goa.design/clue/clock"
+	"goa.design/clue/health"
+)
+
+type (
+	// healthObserver is a health check observer that records dependency
+	// health metrics.
+	healthObserver struct {
+		metrics *healthMetrics
+	}
+
+	// certObserver is a certificate observer that records certificate
+	// expiry metrics.
+	certObserver struct {
+		metrics *certMetrics
+		clock   clock.Clock
+	}
+)
+
+// HealthObserver returns a health check observer that records the result of
+// each dependency check so that alerting does not require scraping the health
+// check endpoint. The context must have been initialized with Context.
+// HealthObserver collects the following metrics:
+//
+//   - `service_dependency_up`: Gauge set to 1 if the last check of the
+//     dependency succeeded and 0 otherwise.
+//   - `health_check_duration_ms`: Histogram of dependency check durations in
+//     milliseconds.
+//
+// The metrics have the following labels:
+//
+//   - `goa_service`: The service name given to Context.
+//   - `dependency`: The name of the dependency.
+//   - `health_status`: `ok` or `error` (duration histogram only).
+//
+// Example:
+//
+//	chk := health.NewChecker(db, cache)
+//	chk.AddObserver(metrics.HealthObserver(ctx))
+func HealthObserver(ctx context.Context) health.Observer {
+	b := ctx.Value(stateBagKey)
+	if b == nil {
+		panic("initialize context with Context first")
+	}
+	return &healthObserver{metrics: b.(*stateBag).HealthMetrics()}
+}
+
+// ObserveCheck records the result of a dependency check.
+func (o *healthObserver) ObserveCheck(name string, latency time.Duration, err error) {
+	up, status := 1.0, "ok"
+	if err != nil {
+		up, status = 0, "error"
+	}
+	o.metrics.Up.With(prometheus.Labels{labelDependency: name}).Set(up)
+	labels := prometheus.Labels{labelDependency: name, labelHealthStatus: status}
+	o.metrics.Durations.With(labels).Observe(float64(latency) / float64(time.Millisecond))
+}
+
+// CertObserver returns a certificate observer that records the number of days
+// until the expiry of the certificates inspected by a certificate pinger, see
+// health.Certificates. The context must have been initialized with Context.
+// CertObserver collects the following metric:
+//
+//   - `tls_certificate_expiry_days`: Gauge set to the number of whole days
+//     until the certificate expires, negative once it has expired.
+//
+// The metric has the following labels:
+//
+//   - `goa_service`: The service name given to Context.
+//   - `dependency`: The name of the certificate pinger.
+//   - `certificate_source`: The path of the certificate file or `memory`.
+//   - `certificate_subject`: The subject of the certificate.
+//
+// Example:
+//
+//	chk := health.NewChecker(health.Certificates("certificates",
+//		health.WithCertFiles("/etc/tls/tls.crt"),
+//		health.WithCertObserver(metrics.CertObserver(ctx))))
+func CertObserver(ctx context.Context) health.CertObserver {
+	b := ctx.Value(stateBagKey)
+	if b == nil {
+		panic("initialize context with Context first")
+	}
+	return &certObserver{metrics: b.(*stateBag).CertMetrics(), clock: b.(*stateBag).options.clock}
+}
+
+// ObserveCertificate records the number of days until cert expires.
+func (o *certObserver) ObserveCertificate(name, source string, cert *x509.Certificate) {
+	days := math.Floor(cert.NotAfter.Sub(o.clock.Now()).Hours() / 24)
+	labels := prometheus.Labels{labelDependency: name, labelCertSource: source, labelCertSubject: cert.Subject.String()}
+	o.metrics.ExpiryDays.With(labels).Set(days)
+}
@@ -0,0 +1,73 @@
+package metrics
+
+import (
+	"context"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"testing"
+	"time"
+
+	"goa.design/clue/clock"
+	"goa.design/clue/health"
+)
+
+func TestHealthObserver(t *testing.T) {
+	cases := []struct {
+		name          string
+		err           error
+		expectedUp    int
+		expectedLabel string
+	}{
+		{"healthy", nil, 1, "ok"},
+		{"unhealthy", errors.New("boom"), 0, "error"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			reg := NewTestRegistry(t)
+			ctx := Context(context.Background(), "testsvc", WithRegisterer(reg), WithDurationBuckets([]float64{10, 110}))
+			chk := health.NewChecker(health.PingFunc("dependency", func(context.Context) error { return c.err }))
+			chk.AddObserver(HealthObserver(ctx))
+
+			chk.Check(context.Background())
+
+			reg.AssertGauge(metricDependencyUp, dependencyLabels, c.expectedUp)
+			reg.AssertHistogram(metricHealthCheckDuration, healthCheckLabels, 1, []int{1, 1})
+			m := reg.findMetric(metricHealthCheckDuration, healthCheckLabels)
+			for _, l := range m.Label {
+				if l.GetName() == labelHealthStatus && l.GetValue() != c.expectedLabel {
+					t.Errorf("got status %q, want %q", l.GetValue(), c.expectedLabel)
+				}
+				if l.GetName() == labelDependency && l.GetValue() != "dependency" {
+					t.Errorf("got dependency %q, want dependency", l.GetValue())
+				}
+			}
+		})
+	}
+}
+
+func TestCertObserver(t *testing.T) {
+	reg := NewTestRegistry(t)
+	ctx := Context(context.Background(), "testsvc", WithRegisterer(reg))
+	cert := &x509.Certificate{
+		Subject:  pkix.Name{CommonName: "test"},
+		NotAfter: time.Now().Add(10*24*time.Hour + time.Hour),
+	}
+
+	CertObserver(ctx).ObserveCertificate("certificates", "memory", cert)
+
+	reg.AssertGauge(metricCertExpiryDays, certLabels, 10)
+
+	clk := clock.NewFake(cert.NotAfter.Add(-3*24*time.Hour - time.Minute))
+	reg = NewTestRegistry(t)
+	ctx = Context(context.Background(), "testsvc", WithRegisterer(reg), WithClock(clk))
+	CertObserver(ctx).ObserveCertificate("certificates", "memory", cert)
+	reg.AssertGauge(metricCertExpiryDays, certLabels, 3)
+	m := reg.findMetric(metricCertExpiryDays, certLabels)
+	expected := map[string]string{labelDependency: "certificates", labelCertSource: "memory", labelCertSubject: "CN=test"}
+	for _, l := range m.Label {
+		if v, ok := expected[l.GetName()]; ok && l.GetValue() != v {
+			t.Errorf("got %s %q, want %q", l.GetName(), l.GetValue(), v)
+		}
+	}
+}
@@ -7,10 +7,11 @@ import (
 	"net/http"
 	"regexp"
 	"strconv"
-	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"goa.design/goa/v3/http/middleware"
+
+	"goa.design/clue/trace"
 )
 
 type (
@@ -41,13 +42,10 @@ type InitMetricDetails struct {
 	StatusCodes []string
 }
 
-// Be kind to tests
-var timeSince = time.Since
-
 // initMetrics initializes all metrics that are specified in the init details,
 // for all given status ports. This is important from a metrics standpoint so
 // that the metric is properly reported -> makes computations easier.
-func initMetrics(metrics *httpMetrics, initDetails *InitMetricDetails) {
+func initMetrics(metrics *httpMetrics, initDetails *InitMetricDetails, synthetic bool) {
 	if initDetails == nil || len(initDetails.EndpointDetails) == 0 {
 		return
 	}
@@ -60,6 +58,9 @@ func initMetrics(metrics *httpMetrics, initDetails *InitMetricDetails) {
 				labelHTTPHost:       initDetails.Host,
 				labelHTTPStatusCode: code,
 			}
+			if synthetic {
+				labels[labelSynthetic] = "false"
+			}
 			metrics.Durations.With(labels)
 		}
 	}
@@ -108,6 +109,9 @@ func findMatchingPattern(path string, dtls []*HTTPEndpointDetails) (string, erro
 //   - `http.path`: The HTTP path.
 //   - `http.status_code`: The HTTP status code.
 //
+// The metrics also have a `synthetic` label when the context is initialized
+// with WithSyntheticLabel.
+//
 // Errors collecting or serving metrics are logged to the logger in the context
 // if any.
 func HTTP(ctx context.Context, initDetails *InitMetricDetails) func(http.Handler) http.Handler {
@@ -117,13 +121,15 @@ func HTTP(ctx context.Context, initDetails *InitMetricDetails) func(http.Handler
 	}
 	metrics := b.(*stateBag).HTTPMetrics()
 	resolver := b.(*stateBag).options.resolver
+	synthetic := b.(*stateBag).options.synthetic
+	clock := b.(*stateBag).options.clock
 
 	// Replace all paths with the relevant path pattern regexp string.
 	for _, path := range initDetails.EndpointDetails {
 		path.Path = replacePathWithPattern(path.Path)
 	}
 
-	initMetrics(metrics, initDetails)
+	initMetrics(metrics, initDetails, len(synthetic) > 0)
 
 	return func(h http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
@@ -138,10 +144,13 @@ func HTTP(ctx context.Context, initDetails *InitMetricDetails) func(http.Handler
 				labelHTTPHost: req.Host,
 				labelHTTPPath: route,
 			}
+			if len(synthetic) > 0 {
+				labels[labelSynthetic] = strconv.FormatBool(trace.IsSynthetic(req, synthetic...))
+			}
 			metrics.ActiveRequests.With(labels).Add(1)
 			defer metrics.ActiveRequests.With(labels).Sub(1)
 
-			now := time.Now()
+			now := clock.Now()
 			rw := middleware.CaptureResponse(w)
 			ctx, body := newLengthReader(req.Body, req.Context())
 			req.Body = body
@@ -156,7 +165,7 @@ func HTTP(ctx context.Context, initDetails *InitMetricDetails) func(http.Handler
 			}
 
 			reqLength := req.Context().Value(ctxReqLen).(*int)
-			metrics.Durations.With(labels).Observe(float64(timeSince(now).Milliseconds()))
+			metrics.Durations.With(labels).Observe(float64(clock.Since(now).Milliseconds()))
 			metrics.RequestSizes.With(labels).Observe(float64(*reqLength))
 			metrics.ResponseSizes.With(labels).Observe(float64(rw.ContentLength))
 		})
@@ -3,12 +3,15 @@ package metrics
 import (
 	"context"
 	"io"
+	"net/http"
+	"net/http/httptest"
 	"strings"
 	"sync"
 	"testing"
 	"time"
 
-	"goa.design/clue/internal/testsvc"
+	"goa.design/clue/clock"
+	"goa.design/clue/testing/testsvc"
 )
 
 func TestHTTPServerDuration(t *testing.T) {
@@ -24,16 +27,13 @@ func TestHTTPServerDuration(t *testing.T) {
 	}
 	for _, c := range cases {
 		t.Run(c.name, func(t *testing.T) {
-			restore := timeSince
-			defer func() { timeSince = restore }()
-			timeSince = func(time.Time) time.Duration { return c.d }
-
+			clk := clock.NewFake(time.Now())
 			reg := NewTestRegistry(t)
-			ctx := Context(context.Background(), "testsvc", WithRegisterer(reg), WithDurationBuckets(buckets))
+			ctx := Context(context.Background(), "testsvc", WithRegisterer(reg), WithDurationBuckets(buckets), WithClock(clk))
 			middleware := HTTP(ctx, nil)
 			cli, stop := testsvc.SetupHTTP(t,
 				testsvc.WithHTTPMiddleware(middleware),
-				testsvc.WithHTTPFunc(noopMethod()))
+				testsvc.WithHTTPFunc(advanceMethod(clk, c.d)))
 			_, err := cli.HTTPMethod(context.Background(), &testsvc.Fields{})
 			if err != nil {
 				t.Errorf("unexpected error: %v", err)
@@ -295,3 +295,38 @@ func TestFindMatchingPattern(t *testing.T) {
 		})
 	}
 }
+
+func TestHTTPSyntheticLabel(t *testing.T) {
+	cases := []struct {
+		name      string
+		opts      []Option
+		userAgent string
+		expected  string
+	}{
+		{"no label", nil, "kube-probe/1.27", ""},
+		{"synthetic", []Option{WithSyntheticLabel()}, "kube-probe/1.27", "true"},
+		{"not synthetic", []Option{WithSyntheticLabel()}, "Mozilla/5.0", "false"},
+		{"custom detector", []Option{WithSyntheticLabel(func(r *http.Request) bool { return r.UserAgent() == "canary" })}, "canary", "true"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			reg := NewTestRegistry(t)
+			ctx := Context(context.Background(), "testsvc", append(c.opts, WithRegisterer(reg))...)
+			handler := HTTP(ctx, &InitMetricDetails{})(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+			req := httptest.NewRequest("GET", "/", nil)
+			req.Header.Set("User-Agent", c.userAgent)
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			m := reg.findMetric(metricHTTPDuration, httpLabels)
+			var got string
+			for _, l := range m.Label {
+				if l.GetName() == labelSynthetic {
+					got = l.GetValue()
+				}
+			}
+			if got != c.expected {
+				t.Errorf("got synthetic label %q, want %q", got, c.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,318 @@
+// Package metricstest provides helpers to test the metrics produced by HTTP
+// middlewares. RunHTTP fires a scripted workload where each request takes a
+// fixed simulated duration measured with a fake clock and returns the metric
+// values the workload is expected to produce. The expected values can then be
+// compared with the gathered metrics without hard-coding bucket counts.
+//
+// Example:
+//
+//	func TestMetrics(t *testing.T) {
+//		clk := clock.NewFake(time.Now())
+//		reg := prometheus.NewRegistry()
+//		ctx := metrics.Context(context.Background(), "svc", metrics.WithRegisterer(reg), metrics.WithClock(clk))
+//		exp := metricstest.RunHTTP(t, clk, metrics.HTTP(ctx, &metrics.InitMetricDetails{}),
+//			metricstest.Route{Path: "/orders", Requests: 10, Duration: 20 * time.Millisecond},
+//			metricstest.Route{Path: "/orders", Requests: 5, Duration: 200 * time.Millisecond},
+//			metricstest.Route{Method: "POST", Path: "/orders", Requests: 3, Status: 500})
+//		exp.AssertHTTP(t, reg)
+//	}
+package metricstest
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+
+	"goa.design/clue/clock"
+)
+
+type (
+	// Route describes the requests sent to a route by RunHTTP.
+	Route struct {
+		// Method is the request method, "GET" by default.
+		Method string
+		// Path is the request path.
+		Path string
+		// Requests is the number of requests sent to the route.
+		Requests int
+		// Duration is the simulated duration of each request.
+		Duration time.Duration
+		// Status is the response status code, 200 by default.
+		Status int
+		// RequestSize is the size of the request bodies in bytes.
+		RequestSize int
+		// ResponseSize is the size of the response bodies in bytes.
+		ResponseSize int
+	}
+
+	// Expected contains the metric values a workload is expected to
+	// produce, see RunHTTP.
+	Expected struct {
+		series []*series
+	}
+
+	// Histogram is the list of values expected to be observed by a
+	// histogram series.
+	Histogram []float64
+
+	// series contains the values expected to be observed by the series
+	// with the given labels.
+	series struct {
+		labels        map[string]string
+		durations     Histogram
+		requestSizes  Histogram
+		responseSizes Histogram
+	}
+)
+
+// Host is the host of the requests sent by RunHTTP.
+const Host = "example.com"
+
+// Names of the metrics and labels produced by the metrics package.
+const (
+	metricHTTPDuration     = "http_server_duration_ms"
+	metricHTTPRequestSize  = "http_server_request_size_bytes"
+	metricHTTPResponseSize = "http_server_response_size_bytes"
+	labelHTTPVerb          = "http_verb"
+	labelHTTPHost          = "http_host"
+	labelHTTPPath          = "http_path"
+	labelHTTPStatusCode    = "http_status_code"
+)
+
+// RunHTTP sends the requests described by routes sequentially to a handler
+// wrapped with middleware and returns the expected metric values. The handler
+// advances clk by the route duration, reads the request body and writes a
+// response with the route status and size. The middleware must measure time
+// with clk (see metrics.WithClock) for the durations to match. Routes are sent
+// in order and the requests of a route are all sent before the next route.
+func RunHTTP(t testing.TB, clk *clock.Fake, middleware func(http.Handler) http.Handler, routes ...Route) *Expected {
+	t.Helper()
+	var current Route
+	handler := middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		clk.Advance(current.Duration)
+		w.WriteHeader(current.status())
+		w.Write([]byte(strings.Repeat("x", current.ResponseSize)))
+	}))
+	e := &Expected{}
+	for _, r := range routes {
+		current = r
+		for i := 0; i < r.Requests; i++ {
+			req := httptest.NewRequest(r.method(), r.Path, strings.NewReader(strings.Repeat("x", r.RequestSize)))
+			req.Host = Host
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+			if w.Code != r.status() {
+				t.Errorf("metricstest: %s %s returned status %d, want %d", r.method(), r.Path, w.Code, r.status())
+			}
+		}
+		s := e.find(r.labels())
+		if s == nil {
+			s = &series{labels: r.labels()}
+			e.series = append(e.series, s)
+		}
+		for i := 0; i < r.Requests; i++ {
+			s.durations = append(s.durations, float64(r.Duration.Milliseconds()))
+			s.requestSizes = append(s.requestSizes, float64(r.RequestSize))
+			s.responseSizes = append(s.responseSizes, float64(r.ResponseSize))
+		}
+	}
+	return e
+}
+
+// Labels returns the labels of the series that contain the metrics of the
+// requests sent to r. Routes with the same method, path and status share the
+// same series.
+func (e *Expected) Labels(r Route) map[string]string {
+	return r.labels()
+}
+
+// Durations returns the values expected to be observed by the duration
+// histogram series of r in milliseconds.
+func (e *Expected) Durations(r Route) Histogram {
+	if s := e.find(r.labels()); s != nil {
+		return s.durations
+	}
+	return nil
+}
+
+// RequestSizes returns the values expected to be observed by the request size
+// histogram series of r in bytes.
+func (e *Expected) RequestSizes(r Route) Histogram {
+	if s := e.find(r.labels()); s != nil {
+		return s.requestSizes
+	}
+	return nil
+}
+
+// ResponseSizes returns the values expected to be observed by the response
+// size histogram series of r in bytes.
+func (e *Expected) ResponseSizes(r Route) Histogram {
+	if s := e.find(r.labels()); s != nil {
+		return s.responseSizes
+	}
+	return nil
+}
+
+// AssertHTTP checks that the HTTP duration, request size and response size
+// histograms gathered from g contain the expected sample count, sum and
+// cumulative bucket counts for each series. The expected bucket counts are
+// computed from the bucket bounds of the gathered histograms so that the
+// assertions do not depend on the bucket configuration.
+func (e *Expected) AssertHTTP(t testing.TB, g prometheus.Gatherer) {
+	t.Helper()
+	families, err := g.Gather()
+	if err != nil {
+		t.Errorf("metricstest: failed to gather metrics: %v", err)
+		return
+	}
+	for _, s := range e.series {
+		for _, m := range []struct {
+			name     string
+			expected Histogram
+		}{
+			{metricHTTPDuration, s.durations},
+			{metricHTTPRequestSize, s.requestSizes},
+			{metricHTTPResponseSize, s.responseSizes},
+		} {
+			h := findHistogram(families, m.name, s.labels)
+			if h == nil {
+				t.Errorf("metricstest: histogram %s with labels %v not found", m.name, s.labels)
+				continue
+			}
+			if got, want := h.GetSampleCount(), uint64(m.expected.Count()); got != want {
+				t.Errorf("metricstest: histogram %s with labels %v has sample count %d, want %d", m.name, s.labels, got, want)
+			}
+			if got, want := h.GetSampleSum(), m.expected.Sum(); got != want {
+				t.Errorf("metricstest: histogram %s with labels %v has sum %v, want %v", m.name, s.labels, got, want)
+			}
+			bounds := make([]float64, len(h.Bucket))
+			for i, b := range h.Bucket {
+				bounds[i] = b.GetUpperBound()
+			}
+			want := m.expected.Buckets(bounds)
+			for i, b := range h.Bucket {
+				if got := b.GetCumulativeCount(); got != uint64(want[i]) {
+					t.Errorf("metricstest: histogram %s with labels %v has cumulative count %d for bucket %v, want %d", m.name, s.labels, got, bounds[i], want[i])
+				}
+			}
+		}
+	}
+}
+
+// Count returns the number of values.
+func (h Histogram) Count() int {
+	return len(h)
+}
+
+// Sum returns the sum of the values.
+func (h Histogram) Sum() float64 {
+	var sum float64
+	for _, v := range h {
+		sum += v
+	}
+	return sum
+}
+
+// Buckets returns the cumulative counts of the values for the given bucket
+// upper bounds, i.e. the number of values less than or equal to each bound.
+func (h Histogram) Buckets(bounds []float64) []int {
+	counts := make([]int, len(bounds))
+	for i, b := range bounds {
+		for _, v := range h {
+			if v <= b {
+				counts[i]++
+			}
+		}
+	}
+	return counts
+}
+
+// find returns the series with the given labels, nil if there is none.
+func (e *Expected) find(labels map[string]string) *series {
+	for _, s := range e.series {
+		if equalLabels(s.labels, labels) {
+			return s
+		}
+	}
+	return nil
+}
+
+// method returns the route method.
+func (r Route) method() string {
+	if r.Method == "" {
+		return http.MethodGet
+	}
+	return r.Method
+}
+
+// status returns the route response status.
+func (r Route) status() int {
+	if r.Status == 0 {
+		return http.StatusOK
+	}
+	return r.Status
+}
+
+// labels returns the labels of the series of the route.
+func (r Route) labels() map[string]string {
+	return map[string]string{
+		labelHTTPVerb:       r.method(),
+		labelHTTPHost:       Host,
+		labelHTTPPath:       r.Path,
+		labelHTTPStatusCode: strconv.Itoa(r.status()),
+	}
+}
+
+// findHistogram returns the histogram with the given name whose labels include
+// the given labels, nil if there is none.
+func findHistogram(families []*dto.MetricFamily, name string, labels map[string]string) *dto.Histogram {
+	for _, f := range families {
+		if f.GetName() != name {
+			continue
+		}
+		for _, m := range f.Metric {
+			if hasLabels(m.Label, labels) {
+				return m.Histogram
+			}
+		}
+	}
+	return nil
+}
+
+// hasLabels returns true if pairs contains all the given labels.
+func hasLabels(pairs []*dto.LabelPair, labels map[string]string) bool {
+	for k, v := range labels {
+		found := false
+		for _, p := range pairs {
+			if p.GetName() == k && p.GetValue() == v {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// equalLabels returns true if a and b contain the same labels.
+func equalLabels(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if b[k] != v {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,63 @@
+package metricstest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"goa.design/clue/clock"
+	"goa.design/clue/metrics"
+)
+
+func TestRunHTTP(t *testing.T) {
+	clk := clock.NewFake(time.Now())
+	reg := prometheus.NewRegistry()
+	ctx := metrics.Context(context.Background(), "svc",
+		metrics.WithRegisterer(reg),
+		metrics.WithClock(clk),
+		metrics.WithDurationBuckets([]float64{10, 100, 1000}),
+		metrics.WithRequestSizeBuckets([]float64{10, 100}),
+		metrics.WithResponseSizeBuckets([]float64{10, 100}))
+	fast := Route{Path: "/orders", Requests: 10, Duration: 20 * time.Millisecond, ResponseSize: 50}
+	slow := Route{Path: "/orders", Requests: 5, Duration: 2 * time.Second, ResponseSize: 50}
+	failed := Route{Method: "POST", Path: "/orders", Requests: 3, Duration: 5 * time.Millisecond, Status: http.StatusInternalServerError, RequestSize: 500}
+	exp := RunHTTP(t, clk, metrics.HTTP(ctx, &metrics.InitMetricDetails{}), fast, slow, failed)
+
+	if got := exp.Durations(fast); got.Count() != 15 || got.Sum() != 10200 {
+		t.Errorf("got %d durations with sum %v, want 15 with sum 10200", got.Count(), got.Sum())
+	}
+	if got := exp.Durations(fast).Buckets([]float64{10, 100, 1000}); fmt.Sprint(got) != "[0 10 10]" {
+		t.Errorf("got duration buckets %v, want [0 10 10]", got)
+	}
+	if got := exp.RequestSizes(failed).Buckets([]float64{10, 1000}); fmt.Sprint(got) != "[0 3]" {
+		t.Errorf("got request size buckets %v, want [0 3]", got)
+	}
+	if got := exp.ResponseSizes(Route{Path: "/missing"}); got != nil {
+		t.Errorf("got response sizes %v for unknown route, want nil", got)
+	}
+	if got := exp.Labels(failed)["http_status_code"]; got != "500" {
+		t.Errorf("got status code label %q, want 500", got)
+	}
+	exp.AssertHTTP(t, reg)
+
+	ft := &fakeTB{TB: t}
+	exp = RunHTTP(t, clk, metrics.HTTP(ctx, &metrics.InitMetricDetails{}), Route{Path: "/orders", Requests: 1, Duration: 20 * time.Millisecond, ResponseSize: 50})
+	exp.AssertHTTP(ft, reg)
+	if len(ft.errors) == 0 {
+		t.Errorf("expected assertion to fail")
+	}
+}
+
+// fakeTB records test errors.
+type fakeTB struct {
+	testing.TB
+	errors []string
+}
+
+func (f *fakeTB) Errorf(format string, args ...interface{}) {
+	f.errors = append(f.errors, fmt.Sprintf(format, args...))
+}
@@ -4,6 +4,9 @@ import (
 	"net/http"
 
 	"github.com/prometheus/client_golang/prometheus"
+
+	"goa.design/clue/clock"
+	"goa.design/clue/trace"
 )
 
 type (
@@ -29,6 +32,10 @@ type (
 		registerer prometheus.Registerer
 		// RouteResolver is used to label metrics.
 		resolver RouteResolver
+		// synthetic detects requests made by synthetic sources.
+		synthetic []trace.SyntheticDetector
+		// clock is used to measure durations.
+		clock clock.Clock
 	}
 )
 
@@ -86,3 +93,28 @@ func WithRegisterer(registerer prometheus.Registerer) Option {
 		c.registerer = registerer
 	}
 }
+
+// WithSyntheticLabel returns an option that adds the "synthetic" label to the
+// HTTP metrics. The label is "true" for requests made by synthetic sources
+// such as health checkers, uptime probes and load tests and "false" otherwise
+// so that SLO calculations can exclude them. The request is synthetic if any
+// of the given detectors returns true. trace.DetectSynthetic is used when no
+// detector is given, see also trace.WithSyntheticDetection.
+func WithSyntheticLabel(detectors ...trace.SyntheticDetector) Option {
+	if len(detectors) == 0 {
+		detectors = []trace.SyntheticDetector{trace.DetectSynthetic}
+	}
+	return func(c *options) {
+		c.synthetic = append(c.synthetic, detectors...)
+	}
+}
+
+// WithClock returns an option that sets the clock used to measure request
+// durations and the time left until certificates expire. The default is the
+// clock stored in the context given to Context if any, clock.System otherwise.
+// Tests may use a clock.Fake to control the recorded values.
+func WithClock(c clock.Clock) Option {
+	return func(o *options) {
+		o.clock = c
+	}
+}
@@ -35,6 +35,23 @@ func (r *Registry) AssertGauge(name string, labels []string, value int) {
 	}
 }
 
+// AssertCounter validates that the counter with the given name and labels
+// exists and has the given value.
+func (r *Registry) AssertCounter(name string, labels []string, value int) {
+	metric := r.findMetric(name, labels)
+	if metric.Counter == nil {
+		r.t.Errorf("counter %q with labels %v not found", name, labels)
+		return
+	}
+	var val float64
+	if metric.Counter.Value != nil {
+		val = *metric.Counter.Value
+	}
+	if float64(value) != val {
+		r.t.Errorf("counter %q with labels %v has value %v, want %v", name, labels, val, value)
+	}
+}
+
 // AssertHistogram validates that the histogram with the given name and given
 // labels exists and has the given sample count and cumulative counts for the
 // given buckets.
@@ -0,0 +1,92 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redis/go-redis/v9"
+
+	"goa.design/clue/clock"
+)
+
+// redisHook is a go-redis hook that records command durations.
+type redisHook struct {
+	metrics *redisMetrics
+	clock   clock.Clock
+}
+
+// RedisHook returns a go-redis hook that meters commands and pipelines. The
+// context must have been initialized with Context. RedisHook collects the
+// following metric:
+//
+//   - `redis_client_duration_ms`: Histogram of command durations in
+//     milliseconds.
+//
+// The metric has the following labels:
+//
+//   - `goa_service`: The service name given to Context.
+//   - `redis_command`: The command (e.g. `GET`) or `PIPELINE` for pipelines.
+//   - `redis_status`: `ok` or `error`, redis.Nil is not an error.
+//
+// Example:
+//
+//	rdb := redis.NewClient(&redis.Options{Addr: addr})
+//	rdb.AddHook(metrics.RedisHook(ctx))
+//	rdb.AddHook(trace.RedisHook(traceCtx))
+func RedisHook(ctx context.Context) redis.Hook {
+	b := ctx.Value(stateBagKey)
+	if b == nil {
+		panic("initialize context with Context first")
+	}
+	state := b.(*stateBag)
+	return &redisHook{metrics: state.RedisMetrics(), clock: state.options.clock}
+}
+
+// DialHook does not record any metric.
+func (h *redisHook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return next(ctx, network, addr)
+	}
+}
+
+// ProcessHook records the duration of commands.
+func (h *redisHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		now := h.clock.Now()
+		err := next(ctx, cmd)
+		h.observe(strings.ToUpper(cmd.FullName()), now, err)
+		return err
+	}
+}
+
+// ProcessPipelineHook records the duration of pipelines.
+func (h *redisHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		now := h.clock.Now()
+		err := next(ctx, cmds)
+		if err == nil {
+			for _, cmd := range cmds {
+				if cerr := cmd.Err(); cerr != nil && !errors.Is(cerr, redis.Nil) {
+					err = cerr
+					break
+				}
+			}
+		}
+		h.observe("PIPELINE", now, err)
+		return err
+	}
+}
+
+// observe records the duration of a command or pipeline.
+func (h *redisHook) observe(command string, start time.Time, err error) {
+	status := "ok"
+	if err != nil && !errors.Is(err, redis.Nil) {
+		status = "error"
+	}
+	labels := prometheus.Labels{labelRedisCommand: command, labelRedisStatus: status}
+	h.metrics.Durations.With(labels).Observe(float64(h.clock.Since(start).Milliseconds()))
+}
@@ -0,0 +1,70 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"goa.design/clue/clock"
+)
+
+func TestRedisHook(t *testing.T) {
+	buckets := []float64{10, 110}
+	cases := []struct {
+		name                 string
+		d                    time.Duration
+		err                  error
+		expectedBucketCounts []int
+	}{
+		{"fast", 1 * time.Millisecond, nil, []int{1, 1}},
+		{"slow", 100 * time.Millisecond, nil, []int{0, 1}},
+		{"nil", 1 * time.Millisecond, redis.Nil, []int{1, 1}},
+		{"error", 1 * time.Millisecond, errors.New("boom"), []int{1, 1}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			clk := clock.NewFake(time.Now())
+			reg := NewTestRegistry(t)
+			ctx := Context(context.Background(), "testsvc", WithRegisterer(reg), WithDurationBuckets(buckets), WithClock(clk))
+			hook := RedisHook(ctx)
+			process := hook.ProcessHook(func(context.Context, redis.Cmder) error { clk.Advance(c.d); return c.err })
+			if err := process(ctx, redis.NewStringCmd(ctx, "get", "k")); err != c.err {
+				t.Errorf("got error %v, want %v", err, c.err)
+			}
+
+			reg.AssertHistogram(metricRedisDuration, redisLabels, 1, c.expectedBucketCounts)
+			status := "ok"
+			if c.err != nil && c.err != redis.Nil {
+				status = "error"
+			}
+			m := reg.findMetric(metricRedisDuration, redisLabels)
+			for _, l := range m.Label {
+				if l.GetName() == labelRedisStatus && l.GetValue() != status {
+					t.Errorf("got status %q, want %q", l.GetValue(), status)
+				}
+				if l.GetName() == labelRedisCommand && l.GetValue() != "GET" {
+					t.Errorf("got command %q, want GET", l.GetValue())
+				}
+			}
+		})
+	}
+}
+
+func TestRedisHookPipeline(t *testing.T) {
+	reg := NewTestRegistry(t)
+	ctx := Context(context.Background(), "testsvc", WithRegisterer(reg))
+	hook := RedisHook(ctx)
+	process := hook.ProcessPipelineHook(func(context.Context, []redis.Cmder) error { return nil })
+	if err := process(ctx, []redis.Cmder{redis.NewStringCmd(ctx, "get", "k")}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	m := reg.findMetric(metricRedisDuration, redisLabels)
+	for _, l := range m.Label {
+		if l.GetName() == labelRedisCommand && l.GetValue() != "PIPELINE" {
+			t.Errorf("got command %q, want PIPELINE", l.GetValue())
+		}
+	}
+}
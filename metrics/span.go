@@ -0,0 +1,72 @@
+package metrics
+
+import (
+	"context"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// SpanProcessor is an OpenTelemetry span processor that aggregates finished
+// server spans into request, error and duration metrics. It makes it possible
+// for services that only enable tracing to get RED (rate, errors, duration)
+// dashboards with numbers that match the traces.
+type SpanProcessor struct {
+	metrics *spanMetrics
+}
+
+var _ sdktrace.SpanProcessor = (*SpanProcessor)(nil)
+
+// NewSpanProcessor returns a span processor that records metrics for server
+// spans. The context must have been initialized with Context. The processor
+// collects the following metrics:
+//
+//   - `span_server_requests_total`: Counter of requests.
+//   - `span_server_errors_total`: Counter of requests whose span status is
+//     error.
+//   - `span_server_duration_ms`: Histogram of request durations in
+//     milliseconds.
+//
+// All the metrics have the following labels:
+//
+//   - `goa_service`: The service name given to Context.
+//   - `span_name`: The name of the span.
+//
+// Example:
+//
+//	ctx = metrics.Context(ctx, svcgen.ServiceName)
+//	provider := sdktrace.NewTracerProvider(
+//		sdktrace.WithSpanProcessor(metrics.NewSpanProcessor(ctx)),
+//		sdktrace.WithBatcher(exporter))
+func NewSpanProcessor(ctx context.Context) *SpanProcessor {
+	b := ctx.Value(stateBagKey)
+	if b == nil {
+		panic("initialize context with Context first")
+	}
+	return &SpanProcessor{metrics: b.(*stateBag).SpanMetrics()}
+}
+
+// OnStart does nothing, metrics are recorded when spans end.
+func (p *SpanProcessor) OnStart(context.Context, sdktrace.ReadWriteSpan) {}
+
+// OnEnd records the metrics for the span if it is a server span.
+func (p *SpanProcessor) OnEnd(span sdktrace.ReadOnlySpan) {
+	if span.SpanKind() != trace.SpanKindServer {
+		return
+	}
+	labels := prometheus.Labels{labelSpanName: span.Name()}
+	p.metrics.Requests.With(labels).Inc()
+	if span.Status().Code == codes.Error {
+		p.metrics.Errors.With(labels).Inc()
+	}
+	d := span.EndTime().Sub(span.StartTime())
+	p.metrics.Durations.With(labels).Observe(float64(d.Milliseconds()))
+}
+
+// Shutdown does nothing.
+func (p *SpanProcessor) Shutdown(context.Context) error { return nil }
+
+// ForceFlush does nothing.
+func (p *SpanProcessor) ForceFlush(context.Context) error { return nil }
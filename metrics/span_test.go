@@ -0,0 +1,77 @@
+package metrics
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestSpanProcessor(t *testing.T) {
+	buckets := []float64{10, 110}
+	start := time.Now()
+	cases := []struct {
+		name                 string
+		kind                 trace.SpanKind
+		d                    time.Duration
+		err                  bool
+		expectedErrors       int
+		expectedBucketCounts []int
+	}{
+		{"fast", trace.SpanKindServer, time.Millisecond, false, 0, []int{1, 1}},
+		{"slow", trace.SpanKindServer, 100 * time.Millisecond, false, 0, []int{0, 1}},
+		{"error", trace.SpanKindServer, time.Millisecond, true, 1, []int{1, 1}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			reg := NewTestRegistry(t)
+			ctx := Context(context.Background(), "testsvc", WithRegisterer(reg), WithDurationBuckets(buckets))
+			provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(NewSpanProcessor(ctx)))
+			tracer := provider.Tracer("test")
+
+			_, span := tracer.Start(ctx, "op", trace.WithSpanKind(c.kind), trace.WithTimestamp(start))
+			if c.err {
+				span.SetStatus(codes.Error, "error")
+			}
+			span.End(trace.WithTimestamp(start.Add(c.d)))
+
+			reg.AssertCounter(metricSpanRequests, spanLabels, 1)
+			if c.err {
+				reg.AssertCounter(metricSpanErrors, spanLabels, c.expectedErrors)
+			}
+			reg.AssertHistogram(metricSpanDuration, spanLabels, 1, c.expectedBucketCounts)
+		})
+	}
+}
+
+func TestSpanProcessorIgnoresNonServerSpans(t *testing.T) {
+	reg := NewTestRegistry(t)
+	ctx := Context(context.Background(), "testsvc", WithRegisterer(reg))
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(NewSpanProcessor(ctx)))
+	tracer := provider.Tracer("test")
+
+	_, span := tracer.Start(ctx, "op", trace.WithSpanKind(trace.SpanKindClient))
+	span.End()
+
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("failed to gather metrics: %v", err)
+	}
+	for _, f := range families {
+		if len(f.Metric) > 0 {
+			t.Errorf("unexpected metric %q", f.GetName())
+		}
+	}
+}
+
+func TestNewSpanProcessorPanicsWithoutContext(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("expected panic")
+		}
+	}()
+	NewSpanProcessor(context.Background())
+}
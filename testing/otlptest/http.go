@@ -0,0 +1,96 @@
+package otlptest
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	collectorlogs "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	collectormetrics "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	collectortrace "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+)
+
+// protobufContentType is the content type of OTLP/HTTP protobuf requests and
+// responses.
+const protobufContentType = "application/x-protobuf"
+
+// httpHandler returns the handler serving the OTLP/HTTP endpoints. Only the
+// protobuf encoding is supported, requests may be gzip compressed.
+func (c *Collector) httpHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/traces", func(w http.ResponseWriter, r *http.Request) {
+		var req collectortrace.ExportTraceServiceRequest
+		if md, ok := decode(w, r, &req); ok {
+			c.recordTraces(md, &req)
+			encode(w, &collectortrace.ExportTraceServiceResponse{})
+		}
+	})
+	mux.HandleFunc("/v1/metrics", func(w http.ResponseWriter, r *http.Request) {
+		var req collectormetrics.ExportMetricsServiceRequest
+		if md, ok := decode(w, r, &req); ok {
+			c.recordMetrics(md, &req)
+			encode(w, &collectormetrics.ExportMetricsServiceResponse{})
+		}
+	})
+	mux.HandleFunc("/v1/logs", func(w http.ResponseWriter, r *http.Request) {
+		var req collectorlogs.ExportLogsServiceRequest
+		if md, ok := decode(w, r, &req); ok {
+			c.recordLogs(md, &req)
+			encode(w, &collectorlogs.ExportLogsServiceResponse{})
+		}
+	})
+	return mux
+}
+
+// decode reads the protobuf encoded export request from r into msg and
+// returns the request headers as metadata. It writes the error response and
+// returns false if the request is invalid.
+func decode(w http.ResponseWriter, r *http.Request, msg proto.Message) (metadata.MD, bool) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return nil, false
+	}
+	if ct := r.Header.Get("Content-Type"); ct != protobufContentType {
+		http.Error(w, fmt.Sprintf("unsupported content type %q, must be %s", ct, protobufContentType), http.StatusUnsupportedMediaType)
+		return nil, false
+	}
+	var body io.Reader = r.Body
+	if strings.EqualFold(r.Header.Get("Content-Encoding"), "gzip") {
+		gz, err := gzip.NewReader(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return nil, false
+		}
+		defer gz.Close()
+		body = gz
+	}
+	b, err := io.ReadAll(body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+	if err := proto.Unmarshal(b, msg); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+	md := metadata.MD{}
+	for k, v := range r.Header {
+		md.Append(k, v...)
+	}
+	return md, true
+}
+
+// encode writes the protobuf encoded export response.
+func encode(w http.ResponseWriter, msg proto.Message) {
+	b, err := proto.Marshal(msg)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", protobufContentType)
+	w.Write(b)
+}
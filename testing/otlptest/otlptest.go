@@ -0,0 +1,421 @@
+// Package otlptest provides an in-memory OTLP collector that records the
+// spans, metrics and logs it receives over gRPC and HTTP. It makes it possible
+// to verify end-to-end exporter configuration (endpoint, headers, compression,
+// resource attributes etc.) in tests without running a real collector.
+//
+// Example:
+//
+//	func TestExporter(t *testing.T) {
+//		col := otlptest.StartCollector(t)
+//		cfg, err := clue.NewConfig(ctx, "svc", "1.0", nil, nil,
+//			clue.WithOTLPExporter(col.GRPCAddr(), clue.WithOTLPInsecure()))
+//		...
+//		spans := col.WaitForSpans(1)
+//		if spans[0].ServiceName() != "svc" {
+//			t.Errorf("unexpected service name %q", spans[0].ServiceName())
+//		}
+//	}
+package otlptest
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	collectorlogs "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	collectormetrics "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	collectortrace "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/grpc"
+	_ "google.golang.org/grpc/encoding/gzip" // Accept gzip compressed exports
+	"google.golang.org/grpc/metadata"
+)
+
+type (
+	// Collector is an in-memory OTLP collector. It serves the OTLP gRPC
+	// services and the OTLP/HTTP protobuf endpoints ("/v1/traces",
+	// "/v1/metrics" and "/v1/logs") and records the telemetry it
+	// receives. Collector is safe for concurrent use.
+	Collector struct {
+		t        testing.TB
+		timeout  time.Duration
+		grpcAddr string
+		httpURL  string
+		grpcSrv  *grpc.Server
+		httpSrv  *http.Server
+		stop     sync.Once
+
+		lock    sync.Mutex
+		spans   []*Span
+		metrics []*Metric
+		logs    []*LogRecord
+		headers []metadata.MD
+	}
+
+	// CollectorOption is a function that configures a collector.
+	CollectorOption func(*collectorOptions)
+
+	// Span is a span received by the collector together with its resource
+	// and instrumentation scope.
+	Span struct {
+		*tracepb.Span
+		// Resource is the resource that produced the span.
+		Resource *resourcepb.Resource
+		// Scope is the instrumentation scope that created the span.
+		Scope *commonpb.InstrumentationScope
+	}
+
+	// Metric is a metric received by the collector together with its
+	// resource and instrumentation scope.
+	Metric struct {
+		*metricspb.Metric
+		// Resource is the resource that produced the metric.
+		Resource *resourcepb.Resource
+		// Scope is the instrumentation scope that created the metric.
+		Scope *commonpb.InstrumentationScope
+	}
+
+	// LogRecord is a log record received by the collector together with
+	// its resource and instrumentation scope.
+	LogRecord struct {
+		*logspb.LogRecord
+		// Resource is the resource that produced the log record.
+		Resource *resourcepb.Resource
+		// Scope is the instrumentation scope that created the log record.
+		Scope *commonpb.InstrumentationScope
+	}
+
+	collectorOptions struct {
+		timeout       time.Duration
+		serverOptions []grpc.ServerOption
+	}
+
+	traceService struct {
+		collectortrace.UnimplementedTraceServiceServer
+		c *Collector
+	}
+
+	metricsService struct {
+		collectormetrics.UnimplementedMetricsServiceServer
+		c *Collector
+	}
+
+	logsService struct {
+		collectorlogs.UnimplementedLogsServiceServer
+		c *Collector
+	}
+)
+
+// DefaultWaitTimeout is the default maximum duration the Wait methods wait for
+// telemetry to be received.
+const DefaultWaitTimeout = 5 * time.Second
+
+// StartCollector starts an in-memory OTLP collector listening on random local
+// ports for gRPC and HTTP. The collector is stopped when the test completes.
+// It fails the test if the collector cannot be started.
+func StartCollector(t testing.TB, opts ...CollectorOption) *Collector {
+	t.Helper()
+	o := &collectorOptions{timeout: DefaultWaitTimeout}
+	for _, opt := range opts {
+		opt(o)
+	}
+	c := &Collector{t: t, timeout: o.timeout}
+
+	gl, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen for OTLP gRPC: %v", err)
+	}
+	c.grpcAddr = gl.Addr().String()
+	c.grpcSrv = grpc.NewServer(o.serverOptions...)
+	collectortrace.RegisterTraceServiceServer(c.grpcSrv, &traceService{c: c})
+	collectormetrics.RegisterMetricsServiceServer(c.grpcSrv, &metricsService{c: c})
+	collectorlogs.RegisterLogsServiceServer(c.grpcSrv, &logsService{c: c})
+	go c.grpcSrv.Serve(gl)
+
+	hl, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		c.grpcSrv.Stop()
+		t.Fatalf("failed to listen for OTLP HTTP: %v", err)
+	}
+	c.httpURL = "http://" + hl.Addr().String()
+	c.httpSrv = &http.Server{Handler: c.httpHandler(), ReadHeaderTimeout: time.Second}
+	go c.httpSrv.Serve(hl)
+
+	t.Cleanup(c.Close)
+	return c
+}
+
+// WithWaitTimeout sets the maximum duration the Wait methods wait for
+// telemetry to be received, DefaultWaitTimeout by default.
+func WithWaitTimeout(d time.Duration) CollectorOption {
+	return func(o *collectorOptions) {
+		o.timeout = d
+	}
+}
+
+// WithServerOptions sets the options used to create the gRPC server, e.g. to
+// require TLS.
+func WithServerOptions(opts ...grpc.ServerOption) CollectorOption {
+	return func(o *collectorOptions) {
+		o.serverOptions = append(o.serverOptions, opts...)
+	}
+}
+
+// GRPCAddr returns the "host:port" address of the OTLP gRPC endpoint.
+func (c *Collector) GRPCAddr() string { return c.grpcAddr }
+
+// HTTPURL returns the base URL of the OTLP/HTTP endpoint, e.g.
+// "http://127.0.0.1:4318". Exporters append "/v1/traces", "/v1/metrics" or
+// "/v1/logs".
+func (c *Collector) HTTPURL() string { return c.httpURL }
+
+// Close stops the collector. It is called automatically when the test
+// completes.
+func (c *Collector) Close() {
+	c.stop.Do(func() {
+		c.grpcSrv.Stop()
+		c.httpSrv.Close()
+	})
+}
+
+// Spans returns the spans received so far.
+func (c *Collector) Spans() []*Span {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return append([]*Span(nil), c.spans...)
+}
+
+// Metrics returns the metrics received so far.
+func (c *Collector) Metrics() []*Metric {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return append([]*Metric(nil), c.metrics...)
+}
+
+// Logs returns the log records received so far.
+func (c *Collector) Logs() []*LogRecord {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return append([]*LogRecord(nil), c.logs...)
+}
+
+// Headers returns the gRPC metadata or HTTP headers of the export requests
+// received so far, in order. HTTP header names are lower cased.
+func (c *Collector) Headers() []metadata.MD {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return append([]metadata.MD(nil), c.headers...)
+}
+
+// FindSpan returns the first span with the given name, nil if there is none.
+func (c *Collector) FindSpan(name string) *Span {
+	for _, s := range c.Spans() {
+		if s.Name == name {
+			return s
+		}
+	}
+	return nil
+}
+
+// FindMetric returns the last received metric with the given name, nil if
+// there is none.
+func (c *Collector) FindMetric(name string) *Metric {
+	ms := c.Metrics()
+	for i := len(ms) - 1; i >= 0; i-- {
+		if ms[i].Name == name {
+			return ms[i]
+		}
+	}
+	return nil
+}
+
+// SpanNames returns the names of the spans received so far.
+func (c *Collector) SpanNames() []string {
+	spans := c.Spans()
+	names := make([]string, len(spans))
+	for i, s := range spans {
+		names[i] = s.Name
+	}
+	return names
+}
+
+// WaitForSpans waits until at least n spans have been received and returns
+// them. It fails the test if the spans are not received before the wait
+// timeout, see WithWaitTimeout.
+func (c *Collector) WaitForSpans(n int) []*Span {
+	c.t.Helper()
+	c.wait(fmt.Sprintf("%d spans", n), func() bool { return len(c.Spans()) >= n })
+	return c.Spans()
+}
+
+// WaitForMetric waits until a metric with the given name has been received
+// and returns the last one received. It fails the test if the metric is not
+// received before the wait timeout, see WithWaitTimeout.
+func (c *Collector) WaitForMetric(name string) *Metric {
+	c.t.Helper()
+	c.wait(fmt.Sprintf("metric %q", name), func() bool { return c.FindMetric(name) != nil })
+	return c.FindMetric(name)
+}
+
+// WaitForLogs waits until at least n log records have been received and
+// returns them. It fails the test if the log records are not received before
+// the wait timeout, see WithWaitTimeout.
+func (c *Collector) WaitForLogs(n int) []*LogRecord {
+	c.t.Helper()
+	c.wait(fmt.Sprintf("%d log records", n), func() bool { return len(c.Logs()) >= n })
+	return c.Logs()
+}
+
+// Reset discards the telemetry and headers received so far.
+func (c *Collector) Reset() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.spans, c.metrics, c.logs, c.headers = nil, nil, nil, nil
+}
+
+// Attr returns the value of the span attribute with the given key formatted
+// as a string and true if it exists, false otherwise.
+func (s *Span) Attr(key string) (string, bool) {
+	return attr(s.Attributes, key)
+}
+
+// ServiceName returns the value of the "service.name" resource attribute.
+func (s *Span) ServiceName() string {
+	return serviceName(s.Resource)
+}
+
+// ServiceName returns the value of the "service.name" resource attribute.
+func (m *Metric) ServiceName() string {
+	return serviceName(m.Resource)
+}
+
+// Attr returns the value of the log record attribute with the given key
+// formatted as a string and true if it exists, false otherwise.
+func (l *LogRecord) Attr(key string) (string, bool) {
+	return attr(l.Attributes, key)
+}
+
+// ServiceName returns the value of the "service.name" resource attribute.
+func (l *LogRecord) ServiceName() string {
+	return serviceName(l.Resource)
+}
+
+func (s *traceService) Export(ctx context.Context, req *collectortrace.ExportTraceServiceRequest) (*collectortrace.ExportTraceServiceResponse, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	s.c.recordTraces(md, req)
+	return &collectortrace.ExportTraceServiceResponse{}, nil
+}
+
+func (s *metricsService) Export(ctx context.Context, req *collectormetrics.ExportMetricsServiceRequest) (*collectormetrics.ExportMetricsServiceResponse, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	s.c.recordMetrics(md, req)
+	return &collectormetrics.ExportMetricsServiceResponse{}, nil
+}
+
+func (s *logsService) Export(ctx context.Context, req *collectorlogs.ExportLogsServiceRequest) (*collectorlogs.ExportLogsServiceResponse, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	s.c.recordLogs(md, req)
+	return &collectorlogs.ExportLogsServiceResponse{}, nil
+}
+
+func (c *Collector) recordTraces(md metadata.MD, req *collectortrace.ExportTraceServiceRequest) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.headers = append(c.headers, md)
+	for _, rs := range req.ResourceSpans {
+		for _, ss := range rs.ScopeSpans {
+			for _, s := range ss.Spans {
+				c.spans = append(c.spans, &Span{Span: s, Resource: rs.Resource, Scope: ss.Scope})
+			}
+		}
+	}
+}
+
+func (c *Collector) recordMetrics(md metadata.MD, req *collectormetrics.ExportMetricsServiceRequest) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.headers = append(c.headers, md)
+	for _, rm := range req.ResourceMetrics {
+		for _, sm := range rm.ScopeMetrics {
+			for _, m := range sm.Metrics {
+				c.metrics = append(c.metrics, &Metric{Metric: m, Resource: rm.Resource, Scope: sm.Scope})
+			}
+		}
+	}
+}
+
+func (c *Collector) recordLogs(md metadata.MD, req *collectorlogs.ExportLogsServiceRequest) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.headers = append(c.headers, md)
+	for _, rl := range req.ResourceLogs {
+		for _, sl := range rl.ScopeLogs {
+			for _, l := range sl.LogRecords {
+				c.logs = append(c.logs, &LogRecord{LogRecord: l, Resource: rl.Resource, Scope: sl.Scope})
+			}
+		}
+	}
+}
+
+// wait polls cond until it returns true and fails the test after the wait
+// timeout.
+func (c *Collector) wait(what string, cond func() bool) {
+	c.t.Helper()
+	deadline := time.Now().Add(c.timeout)
+	for !cond() {
+		if time.Now().After(deadline) {
+			c.t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// attr returns the value of the attribute with the given key formatted as a
+// string.
+func attr(kvs []*commonpb.KeyValue, key string) (string, bool) {
+	for _, kv := range kvs {
+		if kv.Key == key {
+			return formatValue(kv.Value), true
+		}
+	}
+	return "", false
+}
+
+// formatValue formats an OTLP attribute value.
+func formatValue(v *commonpb.AnyValue) string {
+	switch val := v.GetValue().(type) {
+	case *commonpb.AnyValue_StringValue:
+		return val.StringValue
+	case *commonpb.AnyValue_BoolValue:
+		return fmt.Sprint(val.BoolValue)
+	case *commonpb.AnyValue_IntValue:
+		return fmt.Sprint(val.IntValue)
+	case *commonpb.AnyValue_DoubleValue:
+		return fmt.Sprint(val.DoubleValue)
+	case *commonpb.AnyValue_ArrayValue:
+		vals := make([]string, len(val.ArrayValue.GetValues()))
+		for i, v := range val.ArrayValue.GetValues() {
+			vals[i] = formatValue(v)
+		}
+		return "[" + strings.Join(vals, " ") + "]"
+	case *commonpb.AnyValue_BytesValue:
+		return fmt.Sprintf("%x", val.BytesValue)
+	default:
+		return ""
+	}
+}
+
+// serviceName returns the value of the "service.name" attribute of r.
+func serviceName(r *resourcepb.Resource) string {
+	v, _ := attr(r.GetAttributes(), "service.name")
+	return v
+}
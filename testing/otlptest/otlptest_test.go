@@ -0,0 +1,211 @@
+package otlptest
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	collectorlogs "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	collectormetrics "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	collectortrace "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/proto"
+
+	"goa.design/clue/clue"
+)
+
+func TestCollectorGRPCTraces(t *testing.T) {
+	col := StartCollector(t)
+	cfg, err := clue.NewConfig(context.Background(), "svc", "1.0", nil, nil,
+		clue.WithOTLPExporter(col.GRPCAddr(),
+			clue.WithOTLPInsecure(),
+			clue.WithOTLPHeaders(map[string]string{"x-tenant": "acme"}),
+			clue.WithOTLPCompression("gzip")))
+	if err != nil {
+		t.Fatalf("failed to create config: %v", err)
+	}
+	_, span := cfg.TracerProvider.Tracer("test").Start(context.Background(), "span")
+	span.End()
+	if err := cfg.TracerProvider.(*sdktrace.TracerProvider).Shutdown(context.Background()); err != nil {
+		t.Fatalf("failed to shutdown tracer provider: %v", err)
+	}
+
+	spans := col.WaitForSpans(1)
+	if len(spans) != 1 {
+		t.Fatalf("got %d spans, want 1", len(spans))
+	}
+	if spans[0].Name != "span" {
+		t.Errorf("got span name %q, want span", spans[0].Name)
+	}
+	if got := spans[0].ServiceName(); got != "svc" {
+		t.Errorf("got service name %q, want svc", got)
+	}
+	if got := spans[0].Scope.GetName(); got != "test" {
+		t.Errorf("got scope %q, want test", got)
+	}
+	if col.FindSpan("span") == nil {
+		t.Errorf("expected to find span")
+	}
+	headers := col.Headers()
+	if len(headers) != 1 || len(headers[0].Get("x-tenant")) != 1 || headers[0].Get("x-tenant")[0] != "acme" {
+		t.Errorf("got headers %v, want x-tenant header", headers)
+	}
+}
+
+func TestCollectorGRPCMetricsAndLogs(t *testing.T) {
+	col := StartCollector(t)
+	conn, err := grpc.Dial(col.GRPCAddr(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	defer conn.Close()
+	ctx := context.Background()
+	if _, err := collectormetrics.NewMetricsServiceClient(conn).Export(ctx, metricsRequest("requests")); err != nil {
+		t.Fatalf("failed to export metrics: %v", err)
+	}
+	if _, err := collectorlogs.NewLogsServiceClient(conn).Export(ctx, logsRequest("hello")); err != nil {
+		t.Fatalf("failed to export logs: %v", err)
+	}
+
+	m := col.WaitForMetric("requests")
+	if got := m.ServiceName(); got != "svc" {
+		t.Errorf("got service name %q, want svc", got)
+	}
+	logs := col.WaitForLogs(1)
+	if got := logs[0].Body.GetStringValue(); got != "hello" {
+		t.Errorf("got log body %q, want hello", got)
+	}
+	if v, ok := logs[0].Attr("count"); !ok || v != "42" {
+		t.Errorf("got count attribute %q, want 42", v)
+	}
+	if got := logs[0].ServiceName(); got != "svc" {
+		t.Errorf("got service name %q, want svc", got)
+	}
+}
+
+func TestCollectorHTTP(t *testing.T) {
+	col := StartCollector(t)
+	cases := []struct {
+		name        string
+		path        string
+		msg         proto.Message
+		contentType string
+		gzip        bool
+		status      int
+	}{
+		{"traces", "/v1/traces", tracesRequest("span"), protobufContentType, false, http.StatusOK},
+		{"metrics", "/v1/metrics", metricsRequest("requests"), protobufContentType, true, http.StatusOK},
+		{"logs", "/v1/logs", logsRequest("hello"), protobufContentType, false, http.StatusOK},
+		{"json", "/v1/traces", tracesRequest("json"), "application/json", false, http.StatusUnsupportedMediaType},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			body, err := proto.Marshal(c.msg)
+			if err != nil {
+				t.Fatalf("failed to marshal request: %v", err)
+			}
+			if c.gzip {
+				var buf bytes.Buffer
+				gz := gzip.NewWriter(&buf)
+				gz.Write(body)
+				gz.Close()
+				body = buf.Bytes()
+			}
+			req, _ := http.NewRequest("POST", col.HTTPURL()+c.path, bytes.NewReader(body))
+			req.Header.Set("Content-Type", c.contentType)
+			req.Header.Set("X-Tenant", "acme")
+			if c.gzip {
+				req.Header.Set("Content-Encoding", "gzip")
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != c.status {
+				t.Errorf("got status %d, want %d", resp.StatusCode, c.status)
+			}
+		})
+	}
+	if got := col.SpanNames(); len(got) != 1 || got[0] != "span" {
+		t.Errorf("got spans %v, want [span]", got)
+	}
+	if col.FindMetric("requests") == nil {
+		t.Errorf("expected to find metric")
+	}
+	if len(col.Logs()) != 1 {
+		t.Errorf("got %d logs, want 1", len(col.Logs()))
+	}
+	if got := col.Headers()[0].Get("x-tenant"); len(got) != 1 || got[0] != "acme" {
+		t.Errorf("got x-tenant header %v, want acme", got)
+	}
+
+	col.Reset()
+	if len(col.Spans())+len(col.Metrics())+len(col.Logs())+len(col.Headers()) != 0 {
+		t.Errorf("expected reset to discard the recorded telemetry")
+	}
+}
+
+func TestCollectorWaitTimeout(t *testing.T) {
+	col := StartCollector(t, WithWaitTimeout(10*time.Millisecond))
+	ft := &fakeTB{TB: t}
+	col.t = ft
+	func() {
+		defer func() { recover() }()
+		col.WaitForSpans(1)
+	}()
+	if !ft.failed {
+		t.Errorf("expected wait to fail the test")
+	}
+}
+
+// fakeTB records test failures.
+type fakeTB struct {
+	testing.TB
+	failed bool
+}
+
+func (f *fakeTB) Fatalf(string, ...interface{}) {
+	f.failed = true
+	panic("fatal")
+}
+
+func resource() *resourcepb.Resource {
+	return &resourcepb.Resource{Attributes: []*commonpb.KeyValue{
+		{Key: "service.name", Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: "svc"}}},
+	}}
+}
+
+func tracesRequest(name string) *collectortrace.ExportTraceServiceRequest {
+	return &collectortrace.ExportTraceServiceRequest{ResourceSpans: []*tracepb.ResourceSpans{{
+		Resource:   resource(),
+		ScopeSpans: []*tracepb.ScopeSpans{{Spans: []*tracepb.Span{{Name: name}}}},
+	}}}
+}
+
+func metricsRequest(name string) *collectormetrics.ExportMetricsServiceRequest {
+	return &collectormetrics.ExportMetricsServiceRequest{ResourceMetrics: []*metricspb.ResourceMetrics{{
+		Resource:     resource(),
+		ScopeMetrics: []*metricspb.ScopeMetrics{{Metrics: []*metricspb.Metric{{Name: name}}}},
+	}}}
+}
+
+func logsRequest(body string) *collectorlogs.ExportLogsServiceRequest {
+	return &collectorlogs.ExportLogsServiceRequest{ResourceLogs: []*logspb.ResourceLogs{{
+		Resource: resource(),
+		ScopeLogs: []*logspb.ScopeLogs{{LogRecords: []*logspb.LogRecord{{
+			Body:       &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: body}},
+			Attributes: []*commonpb.KeyValue{{Key: "count", Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: 42}}}},
+		}}}},
+	}}}
+}
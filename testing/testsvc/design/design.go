@@ -5,7 +5,7 @@ import (
 )
 
 var _ = API("itest", func() {
-	Description("metrics test service")
+	Description("test service")
 })
 
 var _ = Service("test", func() {
@@ -17,6 +17,14 @@ var _ = Service("test", func() {
 		})
 	})
 
+	Method("http_stream", func() {
+		StreamingPayload(Fields)
+		StreamingResult(Fields)
+		HTTP(func() {
+			GET("/stream")
+		})
+	})
+
 	Method("grpc_method", func() {
 		Payload(Fields)
 		Result(Fields)
@@ -28,6 +36,18 @@ var _ = Service("test", func() {
 		StreamingResult(Fields)
 		GRPC(func() {})
 	})
+
+	Method("grpc_server_stream", func() {
+		Payload(Fields)
+		StreamingResult(Fields)
+		GRPC(func() {})
+	})
+
+	Method("grpc_client_stream", func() {
+		StreamingPayload(Fields)
+		Result(Fields)
+		GRPC(func() {})
+	})
 })
 
 var Fields = Type("Fields", func() {
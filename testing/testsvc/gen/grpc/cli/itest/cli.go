@@ -1,9 +1,9 @@
-// Code generated by goa v3.9.1, DO NOT EDIT.
+// Code generated by goa v3.12.3, DO NOT EDIT.
 //
 // itest gRPC client CLI support package
 //
 // Command:
-// $ goa gen goa.design/clue/internal/testsvc/design
+// $ goa gen goa.design/clue/testing/testsvc/design
 
 package cli
 
@@ -12,7 +12,7 @@ import (
 	"fmt"
 	"os"
 
-	testc "goa.design/clue/internal/testsvc/gen/grpc/test/client"
+	testc "goa.design/clue/testing/testsvc/gen/grpc/test/client"
 	goa "goa.design/goa/v3/pkg"
 	grpc "google.golang.org/grpc"
 )
@@ -21,22 +21,22 @@ import (
 //
 //	command (subcommand1|subcommand2|...)
 func UsageCommands() string {
-	return `test (grpc-method|grpc-stream)
+	return `test (grpc-method|grpc-stream|grpc-server-stream|grpc-client-stream)
 `
 }
 
 // UsageExamples produces an example of a valid invocation of the CLI tool.
 func UsageExamples() string {
 	return os.Args[0] + ` test grpc-method --message '{
-      "i": 4434351785751264939,
-      "s": "Corrupti repellat autem sit architecto ut."
+      "i": 4485950027360197502,
+      "s": "Saepe ex."
    }'` + "\n" +
 		""
 }
 
 // ParseEndpoint returns the endpoint and payload as specified on the command
 // line.
-func ParseEndpoint(cc *grpc.ClientConn, opts ...grpc.CallOption) (goa.Endpoint, interface{}, error) {
+func ParseEndpoint(cc *grpc.ClientConn, opts ...grpc.CallOption) (goa.Endpoint, any, error) {
 	var (
 		testFlags = flag.NewFlagSet("test", flag.ContinueOnError)
 
@@ -44,10 +44,17 @@ func ParseEndpoint(cc *grpc.ClientConn, opts ...grpc.CallOption) (goa.Endpoint,
 		testGrpcMethodMessageFlag = testGrpcMethodFlags.String("message", "", "")
 
 		testGrpcStreamFlags = flag.NewFlagSet("grpc-stream", flag.ExitOnError)
+
+		testGrpcServerStreamFlags       = flag.NewFlagSet("grpc-server-stream", flag.ExitOnError)
+		testGrpcServerStreamMessageFlag = testGrpcServerStreamFlags.String("message", "", "")
+
+		testGrpcClientStreamFlags = flag.NewFlagSet("grpc-client-stream", flag.ExitOnError)
 	)
 	testFlags.Usage = testUsage
 	testGrpcMethodFlags.Usage = testGrpcMethodUsage
 	testGrpcStreamFlags.Usage = testGrpcStreamUsage
+	testGrpcServerStreamFlags.Usage = testGrpcServerStreamUsage
+	testGrpcClientStreamFlags.Usage = testGrpcClientStreamUsage
 
 	if err := flag.CommandLine.Parse(os.Args[1:]); err != nil {
 		return nil, nil, err
@@ -89,6 +96,12 @@ func ParseEndpoint(cc *grpc.ClientConn, opts ...grpc.CallOption) (goa.Endpoint,
 			case "grpc-stream":
 				epf = testGrpcStreamFlags
 
+			case "grpc-server-stream":
+				epf = testGrpcServerStreamFlags
+
+			case "grpc-client-stream":
+				epf = testGrpcClientStreamFlags
+
 			}
 
 		}
@@ -105,7 +118,7 @@ func ParseEndpoint(cc *grpc.ClientConn, opts ...grpc.CallOption) (goa.Endpoint,
 	}
 
 	var (
-		data     interface{}
+		data     any
 		endpoint goa.Endpoint
 		err      error
 	)
@@ -120,6 +133,12 @@ func ParseEndpoint(cc *grpc.ClientConn, opts ...grpc.CallOption) (goa.Endpoint,
 			case "grpc-stream":
 				endpoint = c.GrpcStream()
 				data = nil
+			case "grpc-server-stream":
+				endpoint = c.GrpcServerStream()
+				data, err = testc.BuildGrpcServerStreamPayload(*testGrpcServerStreamMessageFlag)
+			case "grpc-client-stream":
+				endpoint = c.GrpcClientStream()
+				data = nil
 			}
 		}
 	}
@@ -139,6 +158,8 @@ Usage:
 COMMAND:
     grpc-method: GrpcMethod implements grpc_method.
     grpc-stream: GrpcStream implements grpc_stream.
+    grpc-server-stream: GrpcServerStream implements grpc_server_stream.
+    grpc-client-stream: GrpcClientStream implements grpc_client_stream.
 
 Additional help:
     %[1]s test COMMAND --help
@@ -152,8 +173,8 @@ GrpcMethod implements grpc_method.
 
 Example:
     %[1]s test grpc-method --message '{
-      "i": 4434351785751264939,
-      "s": "Corrupti repellat autem sit architecto ut."
+      "i": 4485950027360197502,
+      "s": "Saepe ex."
    }'
 `, os.Args[0])
 }
@@ -167,3 +188,27 @@ Example:
     %[1]s test grpc-stream
 `, os.Args[0])
 }
+
+func testGrpcServerStreamUsage() {
+	fmt.Fprintf(os.Stderr, `%[1]s [flags] test grpc-server-stream -message JSON
+
+GrpcServerStream implements grpc_server_stream.
+    -message JSON: 
+
+Example:
+    %[1]s test grpc-server-stream --message '{
+      "i": 506856245359991540,
+      "s": "Eos nulla ut doloremque."
+   }'
+`, os.Args[0])
+}
+
+func testGrpcClientStreamUsage() {
+	fmt.Fprintf(os.Stderr, `%[1]s [flags] test grpc-client-stream
+
+GrpcClientStream implements grpc_client_stream.
+
+Example:
+    %[1]s test grpc-client-stream
+`, os.Args[0])
+}
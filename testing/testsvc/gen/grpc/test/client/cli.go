@@ -0,0 +1,64 @@
+// Code generated by goa v3.12.3, DO NOT EDIT.
+//
+// test gRPC client CLI support package
+//
+// Command:
+// $ goa gen goa.design/clue/testing/testsvc/design
+
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+
+	testpb "goa.design/clue/testing/testsvc/gen/grpc/test/pb"
+	test "goa.design/clue/testing/testsvc/gen/test"
+)
+
+// BuildGrpcMethodPayload builds the payload for the test grpc_method endpoint
+// from CLI flags.
+func BuildGrpcMethodPayload(testGrpcMethodMessage string) (*test.Fields, error) {
+	var err error
+	var message testpb.GrpcMethodRequest
+	{
+		if testGrpcMethodMessage != "" {
+			err = json.Unmarshal([]byte(testGrpcMethodMessage), &message)
+			if err != nil {
+				return nil, fmt.Errorf("invalid JSON for message, \nerror: %s, \nexample of valid JSON:\n%s", err, "'{\n      \"i\": 4485950027360197502,\n      \"s\": \"Saepe ex.\"\n   }'")
+			}
+		}
+	}
+	v := &test.Fields{
+		S: message.S,
+	}
+	if message.I != nil {
+		i := int(*message.I)
+		v.I = &i
+	}
+
+	return v, nil
+}
+
+// BuildGrpcServerStreamPayload builds the payload for the test
+// grpc_server_stream endpoint from CLI flags.
+func BuildGrpcServerStreamPayload(testGrpcServerStreamMessage string) (*test.Fields, error) {
+	var err error
+	var message testpb.GrpcServerStreamRequest
+	{
+		if testGrpcServerStreamMessage != "" {
+			err = json.Unmarshal([]byte(testGrpcServerStreamMessage), &message)
+			if err != nil {
+				return nil, fmt.Errorf("invalid JSON for message, \nerror: %s, \nexample of valid JSON:\n%s", err, "'{\n      \"i\": 506856245359991540,\n      \"s\": \"Eos nulla ut doloremque.\"\n   }'")
+			}
+		}
+	}
+	v := &test.Fields{
+		S: message.S,
+	}
+	if message.I != nil {
+		i := int(*message.I)
+		v.I = &i
+	}
+
+	return v, nil
+}
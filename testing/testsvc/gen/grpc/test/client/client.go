@@ -0,0 +1,163 @@
+// Code generated by goa v3.12.3, DO NOT EDIT.
+//
+// test gRPC client
+//
+// Command:
+// $ goa gen goa.design/clue/testing/testsvc/design
+
+package client
+
+import (
+	"context"
+
+	testpb "goa.design/clue/testing/testsvc/gen/grpc/test/pb"
+	test "goa.design/clue/testing/testsvc/gen/test"
+	goagrpc "goa.design/goa/v3/grpc"
+	goa "goa.design/goa/v3/pkg"
+	"google.golang.org/grpc"
+)
+
+// Client lists the service endpoint gRPC clients.
+type Client struct {
+	grpccli testpb.TestClient
+	opts    []grpc.CallOption
+}
+
+// GrpcStreamClientStream implements the test.GrpcStreamClientStream interface.
+type GrpcStreamClientStream struct {
+	stream testpb.Test_GrpcStreamClient
+}
+
+// GrpcServerStreamClientStream implements the
+// test.GrpcServerStreamClientStream interface.
+type GrpcServerStreamClientStream struct {
+	stream testpb.Test_GrpcServerStreamClient
+}
+
+// GrpcClientStreamClientStream implements the
+// test.GrpcClientStreamClientStream interface.
+type GrpcClientStreamClientStream struct {
+	stream testpb.Test_GrpcClientStreamClient
+}
+
+// NewClient instantiates gRPC client for all the test service servers.
+func NewClient(cc *grpc.ClientConn, opts ...grpc.CallOption) *Client {
+	return &Client{
+		grpccli: testpb.NewTestClient(cc),
+		opts:    opts,
+	}
+}
+
+// GrpcMethod calls the "GrpcMethod" function in testpb.TestClient interface.
+func (c *Client) GrpcMethod() goa.Endpoint {
+	return func(ctx context.Context, v any) (any, error) {
+		inv := goagrpc.NewInvoker(
+			BuildGrpcMethodFunc(c.grpccli, c.opts...),
+			EncodeGrpcMethodRequest,
+			DecodeGrpcMethodResponse)
+		res, err := inv.Invoke(ctx, v)
+		if err != nil {
+			return nil, goa.Fault(err.Error())
+		}
+		return res, nil
+	}
+}
+
+// GrpcStream calls the "GrpcStream" function in testpb.TestClient interface.
+func (c *Client) GrpcStream() goa.Endpoint {
+	return func(ctx context.Context, v any) (any, error) {
+		inv := goagrpc.NewInvoker(
+			BuildGrpcStreamFunc(c.grpccli, c.opts...),
+			nil,
+			DecodeGrpcStreamResponse)
+		res, err := inv.Invoke(ctx, v)
+		if err != nil {
+			return nil, goa.Fault(err.Error())
+		}
+		return res, nil
+	}
+}
+
+// GrpcServerStream calls the "GrpcServerStream" function in testpb.TestClient
+// interface.
+func (c *Client) GrpcServerStream() goa.Endpoint {
+	return func(ctx context.Context, v any) (any, error) {
+		inv := goagrpc.NewInvoker(
+			BuildGrpcServerStreamFunc(c.grpccli, c.opts...),
+			EncodeGrpcServerStreamRequest,
+			DecodeGrpcServerStreamResponse)
+		res, err := inv.Invoke(ctx, v)
+		if err != nil {
+			return nil, goa.Fault(err.Error())
+		}
+		return res, nil
+	}
+}
+
+// GrpcClientStream calls the "GrpcClientStream" function in testpb.TestClient
+// interface.
+func (c *Client) GrpcClientStream() goa.Endpoint {
+	return func(ctx context.Context, v any) (any, error) {
+		inv := goagrpc.NewInvoker(
+			BuildGrpcClientStreamFunc(c.grpccli, c.opts...),
+			nil,
+			DecodeGrpcClientStreamResponse)
+		res, err := inv.Invoke(ctx, v)
+		if err != nil {
+			return nil, goa.Fault(err.Error())
+		}
+		return res, nil
+	}
+}
+
+// Recv reads instances of "testpb.GrpcStreamResponse" from the "grpc_stream"
+// endpoint gRPC stream.
+func (s *GrpcStreamClientStream) Recv() (*test.Fields, error) {
+	var res *test.Fields
+	v, err := s.stream.Recv()
+	if err != nil {
+		return res, err
+	}
+	return NewGrpcStreamResponseFields(v), nil
+}
+
+// Send streams instances of "testpb.GrpcStreamStreamingRequest" to the
+// "grpc_stream" endpoint gRPC stream.
+func (s *GrpcStreamClientStream) Send(res *test.Fields) error {
+	v := NewProtoFieldsGrpcStreamStreamingRequest(res)
+	return s.stream.Send(v)
+}
+
+func (s *GrpcStreamClientStream) Close() error {
+	// Close the send direction of the stream
+	return s.stream.CloseSend()
+}
+
+// Recv reads instances of "testpb.GrpcServerStreamResponse" from the
+// "grpc_server_stream" endpoint gRPC stream.
+func (s *GrpcServerStreamClientStream) Recv() (*test.Fields, error) {
+	var res *test.Fields
+	v, err := s.stream.Recv()
+	if err != nil {
+		return res, err
+	}
+	return NewGrpcServerStreamResponseFields(v), nil
+}
+
+// CloseAndRecv reads instances of "testpb.GrpcClientStreamResponse" from the
+// "grpc_client_stream" endpoint gRPC stream.
+func (s *GrpcClientStreamClientStream) CloseAndRecv() (*test.Fields, error) {
+	var res *test.Fields
+	v, err := s.stream.CloseAndRecv()
+	if err != nil {
+		return res, err
+	}
+	return NewGrpcClientStreamResponseFields(v), nil
+}
+
+// Send streams instances of "testpb.GrpcClientStreamStreamingRequest" to the
+// "grpc_client_stream" endpoint gRPC stream.
+func (s *GrpcClientStreamClientStream) Send(res *test.Fields) error {
+	v := NewProtoFieldsGrpcClientStreamStreamingRequest(res)
+	return s.stream.Send(v)
+}
@@ -0,0 +1,107 @@
+// Code generated by goa v3.12.3, DO NOT EDIT.
+//
+// test gRPC client types
+//
+// Command:
+// $ goa gen goa.design/clue/testing/testsvc/design
+
+package client
+
+import (
+	testpb "goa.design/clue/testing/testsvc/gen/grpc/test/pb"
+	test "goa.design/clue/testing/testsvc/gen/test"
+)
+
+// NewProtoGrpcMethodRequest builds the gRPC request type from the payload of
+// the "grpc_method" endpoint of the "test" service.
+func NewProtoGrpcMethodRequest(payload *test.Fields) *testpb.GrpcMethodRequest {
+	message := &testpb.GrpcMethodRequest{
+		S: payload.S,
+	}
+	if payload.I != nil {
+		i := int32(*payload.I)
+		message.I = &i
+	}
+	return message
+}
+
+// NewGrpcMethodResult builds the result type of the "grpc_method" endpoint of
+// the "test" service from the gRPC response type.
+func NewGrpcMethodResult(message *testpb.GrpcMethodResponse) *test.Fields {
+	result := &test.Fields{
+		S: message.S,
+	}
+	if message.I != nil {
+		i := int(*message.I)
+		result.I = &i
+	}
+	return result
+}
+
+func NewGrpcStreamResponseFields(v *testpb.GrpcStreamResponse) *test.Fields {
+	result := &test.Fields{
+		S: v.S,
+	}
+	if v.I != nil {
+		i := int(*v.I)
+		result.I = &i
+	}
+	return result
+}
+
+func NewProtoFieldsGrpcStreamStreamingRequest(spayload *test.Fields) *testpb.GrpcStreamStreamingRequest {
+	v := &testpb.GrpcStreamStreamingRequest{
+		S: spayload.S,
+	}
+	if spayload.I != nil {
+		i := int32(*spayload.I)
+		v.I = &i
+	}
+	return v
+}
+
+// NewProtoGrpcServerStreamRequest builds the gRPC request type from the
+// payload of the "grpc_server_stream" endpoint of the "test" service.
+func NewProtoGrpcServerStreamRequest(payload *test.Fields) *testpb.GrpcServerStreamRequest {
+	message := &testpb.GrpcServerStreamRequest{
+		S: payload.S,
+	}
+	if payload.I != nil {
+		i := int32(*payload.I)
+		message.I = &i
+	}
+	return message
+}
+
+func NewGrpcServerStreamResponseFields(v *testpb.GrpcServerStreamResponse) *test.Fields {
+	result := &test.Fields{
+		S: v.S,
+	}
+	if v.I != nil {
+		i := int(*v.I)
+		result.I = &i
+	}
+	return result
+}
+
+func NewGrpcClientStreamResponseFields(v *testpb.GrpcClientStreamResponse) *test.Fields {
+	result := &test.Fields{
+		S: v.S,
+	}
+	if v.I != nil {
+		i := int(*v.I)
+		result.I = &i
+	}
+	return result
+}
+
+func NewProtoFieldsGrpcClientStreamStreamingRequest(spayload *test.Fields) *testpb.GrpcClientStreamStreamingRequest {
+	v := &testpb.GrpcClientStreamStreamingRequest{
+		S: spayload.S,
+	}
+	if spayload.I != nil {
+		i := int32(*spayload.I)
+		v.I = &i
+	}
+	return v
+}
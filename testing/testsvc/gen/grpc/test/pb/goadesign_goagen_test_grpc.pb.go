@@ -26,6 +26,10 @@ type TestClient interface {
 	GrpcMethod(ctx context.Context, in *GrpcMethodRequest, opts ...grpc.CallOption) (*GrpcMethodResponse, error)
 	// GrpcStream implements grpc_stream.
 	GrpcStream(ctx context.Context, opts ...grpc.CallOption) (Test_GrpcStreamClient, error)
+	// GrpcServerStream implements grpc_server_stream.
+	GrpcServerStream(ctx context.Context, in *GrpcServerStreamRequest, opts ...grpc.CallOption) (Test_GrpcServerStreamClient, error)
+	// GrpcClientStream implements grpc_client_stream.
+	GrpcClientStream(ctx context.Context, opts ...grpc.CallOption) (Test_GrpcClientStreamClient, error)
 }
 
 type testClient struct {
@@ -76,6 +80,72 @@ func (x *testGrpcStreamClient) Recv() (*GrpcStreamResponse, error) {
 	return m, nil
 }
 
+func (c *testClient) GrpcServerStream(ctx context.Context, in *GrpcServerStreamRequest, opts ...grpc.CallOption) (Test_GrpcServerStreamClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Test_ServiceDesc.Streams[1], "/test.Test/GrpcServerStream", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &testGrpcServerStreamClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Test_GrpcServerStreamClient interface {
+	Recv() (*GrpcServerStreamResponse, error)
+	grpc.ClientStream
+}
+
+type testGrpcServerStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *testGrpcServerStreamClient) Recv() (*GrpcServerStreamResponse, error) {
+	m := new(GrpcServerStreamResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *testClient) GrpcClientStream(ctx context.Context, opts ...grpc.CallOption) (Test_GrpcClientStreamClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Test_ServiceDesc.Streams[2], "/test.Test/GrpcClientStream", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &testGrpcClientStreamClient{stream}
+	return x, nil
+}
+
+type Test_GrpcClientStreamClient interface {
+	Send(*GrpcClientStreamStreamingRequest) error
+	CloseAndRecv() (*GrpcClientStreamResponse, error)
+	grpc.ClientStream
+}
+
+type testGrpcClientStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *testGrpcClientStreamClient) Send(m *GrpcClientStreamStreamingRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *testGrpcClientStreamClient) CloseAndRecv() (*GrpcClientStreamResponse, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(GrpcClientStreamResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // TestServer is the server API for Test service.
 // All implementations must embed UnimplementedTestServer
 // for forward compatibility
@@ -84,6 +154,10 @@ type TestServer interface {
 	GrpcMethod(context.Context, *GrpcMethodRequest) (*GrpcMethodResponse, error)
 	// GrpcStream implements grpc_stream.
 	GrpcStream(Test_GrpcStreamServer) error
+	// GrpcServerStream implements grpc_server_stream.
+	GrpcServerStream(*GrpcServerStreamRequest, Test_GrpcServerStreamServer) error
+	// GrpcClientStream implements grpc_client_stream.
+	GrpcClientStream(Test_GrpcClientStreamServer) error
 	mustEmbedUnimplementedTestServer()
 }
 
@@ -97,6 +171,12 @@ func (UnimplementedTestServer) GrpcMethod(context.Context, *GrpcMethodRequest) (
 func (UnimplementedTestServer) GrpcStream(Test_GrpcStreamServer) error {
 	return status.Errorf(codes.Unimplemented, "method GrpcStream not implemented")
 }
+func (UnimplementedTestServer) GrpcServerStream(*GrpcServerStreamRequest, Test_GrpcServerStreamServer) error {
+	return status.Errorf(codes.Unimplemented, "method GrpcServerStream not implemented")
+}
+func (UnimplementedTestServer) GrpcClientStream(Test_GrpcClientStreamServer) error {
+	return status.Errorf(codes.Unimplemented, "method GrpcClientStream not implemented")
+}
 func (UnimplementedTestServer) mustEmbedUnimplementedTestServer() {}
 
 // UnsafeTestServer may be embedded to opt out of forward compatibility for this service.
@@ -154,6 +234,53 @@ func (x *testGrpcStreamServer) Recv() (*GrpcStreamStreamingRequest, error) {
 	return m, nil
 }
 
+func _Test_GrpcServerStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(GrpcServerStreamRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TestServer).GrpcServerStream(m, &testGrpcServerStreamServer{stream})
+}
+
+type Test_GrpcServerStreamServer interface {
+	Send(*GrpcServerStreamResponse) error
+	grpc.ServerStream
+}
+
+type testGrpcServerStreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *testGrpcServerStreamServer) Send(m *GrpcServerStreamResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Test_GrpcClientStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(TestServer).GrpcClientStream(&testGrpcClientStreamServer{stream})
+}
+
+type Test_GrpcClientStreamServer interface {
+	SendAndClose(*GrpcClientStreamResponse) error
+	Recv() (*GrpcClientStreamStreamingRequest, error)
+	grpc.ServerStream
+}
+
+type testGrpcClientStreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *testGrpcClientStreamServer) SendAndClose(m *GrpcClientStreamResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *testGrpcClientStreamServer) Recv() (*GrpcClientStreamStreamingRequest, error) {
+	m := new(GrpcClientStreamStreamingRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // Test_ServiceDesc is the grpc.ServiceDesc for Test service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -173,6 +300,16 @@ var Test_ServiceDesc = grpc.ServiceDesc{
 			ServerStreams: true,
 			ClientStreams: true,
 		},
+		{
+			StreamName:    "GrpcServerStream",
+			Handler:       _Test_GrpcServerStream_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "GrpcClientStream",
+			Handler:       _Test_GrpcClientStream_Handler,
+			ClientStreams: true,
+		},
 	},
 	Metadata: "goadesign_goagen_test.proto",
 }
@@ -0,0 +1,99 @@
+// Code generated by goa v3.12.3, DO NOT EDIT.
+//
+// test gRPC server encoders and decoders
+//
+// Command:
+// $ goa gen goa.design/clue/testing/testsvc/design
+
+package server
+
+import (
+	"context"
+
+	testpb "goa.design/clue/testing/testsvc/gen/grpc/test/pb"
+	test "goa.design/clue/testing/testsvc/gen/test"
+	goagrpc "goa.design/goa/v3/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// EncodeGrpcMethodResponse encodes responses from the "test" service
+// "grpc_method" endpoint.
+func EncodeGrpcMethodResponse(ctx context.Context, v any, hdr, trlr *metadata.MD) (any, error) {
+	result, ok := v.(*test.Fields)
+	if !ok {
+		return nil, goagrpc.ErrInvalidType("test", "grpc_method", "*test.Fields", v)
+	}
+	resp := NewProtoGrpcMethodResponse(result)
+	return resp, nil
+}
+
+// DecodeGrpcMethodRequest decodes requests sent to "test" service
+// "grpc_method" endpoint.
+func DecodeGrpcMethodRequest(ctx context.Context, v any, md metadata.MD) (any, error) {
+	var (
+		message *testpb.GrpcMethodRequest
+		ok      bool
+	)
+	{
+		if message, ok = v.(*testpb.GrpcMethodRequest); !ok {
+			return nil, goagrpc.ErrInvalidType("test", "grpc_method", "*testpb.GrpcMethodRequest", v)
+		}
+	}
+	var payload *test.Fields
+	{
+		payload = NewGrpcMethodPayload(message)
+	}
+	return payload, nil
+}
+
+// EncodeGrpcStreamResponse encodes responses from the "test" service
+// "grpc_stream" endpoint.
+func EncodeGrpcStreamResponse(ctx context.Context, v any, hdr, trlr *metadata.MD) (any, error) {
+	result, ok := v.(*test.Fields)
+	if !ok {
+		return nil, goagrpc.ErrInvalidType("test", "grpc_stream", "*test.Fields", v)
+	}
+	resp := NewProtoGrpcStreamResponse(result)
+	return resp, nil
+}
+
+// EncodeGrpcServerStreamResponse encodes responses from the "test" service
+// "grpc_server_stream" endpoint.
+func EncodeGrpcServerStreamResponse(ctx context.Context, v any, hdr, trlr *metadata.MD) (any, error) {
+	result, ok := v.(*test.Fields)
+	if !ok {
+		return nil, goagrpc.ErrInvalidType("test", "grpc_server_stream", "*test.Fields", v)
+	}
+	resp := NewProtoGrpcServerStreamResponse(result)
+	return resp, nil
+}
+
+// DecodeGrpcServerStreamRequest decodes requests sent to "test" service
+// "grpc_server_stream" endpoint.
+func DecodeGrpcServerStreamRequest(ctx context.Context, v any, md metadata.MD) (any, error) {
+	var (
+		message *testpb.GrpcServerStreamRequest
+		ok      bool
+	)
+	{
+		if message, ok = v.(*testpb.GrpcServerStreamRequest); !ok {
+			return nil, goagrpc.ErrInvalidType("test", "grpc_server_stream", "*testpb.GrpcServerStreamRequest", v)
+		}
+	}
+	var payload *test.Fields
+	{
+		payload = NewGrpcServerStreamPayload(message)
+	}
+	return payload, nil
+}
+
+// EncodeGrpcClientStreamResponse encodes responses from the "test" service
+// "grpc_client_stream" endpoint.
+func EncodeGrpcClientStreamResponse(ctx context.Context, v any, hdr, trlr *metadata.MD) (any, error) {
+	result, ok := v.(*test.Fields)
+	if !ok {
+		return nil, goagrpc.ErrInvalidType("test", "grpc_client_stream", "*test.Fields", v)
+	}
+	resp := NewProtoGrpcClientStreamResponse(result)
+	return resp, nil
+}
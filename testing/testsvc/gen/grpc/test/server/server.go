@@ -0,0 +1,213 @@
+// Code generated by goa v3.12.3, DO NOT EDIT.
+//
+// test gRPC server
+//
+// Command:
+// $ goa gen goa.design/clue/testing/testsvc/design
+
+package server
+
+import (
+	"context"
+
+	testpb "goa.design/clue/testing/testsvc/gen/grpc/test/pb"
+	test "goa.design/clue/testing/testsvc/gen/test"
+	goagrpc "goa.design/goa/v3/grpc"
+	goa "goa.design/goa/v3/pkg"
+)
+
+// Server implements the testpb.TestServer interface.
+type Server struct {
+	GrpcMethodH       goagrpc.UnaryHandler
+	GrpcStreamH       goagrpc.StreamHandler
+	GrpcServerStreamH goagrpc.StreamHandler
+	GrpcClientStreamH goagrpc.StreamHandler
+	testpb.UnimplementedTestServer
+}
+
+// GrpcStreamServerStream implements the test.GrpcStreamServerStream interface.
+type GrpcStreamServerStream struct {
+	stream testpb.Test_GrpcStreamServer
+}
+
+// GrpcServerStreamServerStream implements the
+// test.GrpcServerStreamServerStream interface.
+type GrpcServerStreamServerStream struct {
+	stream testpb.Test_GrpcServerStreamServer
+}
+
+// GrpcClientStreamServerStream implements the
+// test.GrpcClientStreamServerStream interface.
+type GrpcClientStreamServerStream struct {
+	stream testpb.Test_GrpcClientStreamServer
+}
+
+// New instantiates the server struct with the test service endpoints.
+func New(e *test.Endpoints, uh goagrpc.UnaryHandler, sh goagrpc.StreamHandler) *Server {
+	return &Server{
+		GrpcMethodH:       NewGrpcMethodHandler(e.GrpcMethod, uh),
+		GrpcStreamH:       NewGrpcStreamHandler(e.GrpcStream, sh),
+		GrpcServerStreamH: NewGrpcServerStreamHandler(e.GrpcServerStream, sh),
+		GrpcClientStreamH: NewGrpcClientStreamHandler(e.GrpcClientStream, sh),
+	}
+}
+
+// NewGrpcMethodHandler creates a gRPC handler which serves the "test" service
+// "grpc_method" endpoint.
+func NewGrpcMethodHandler(endpoint goa.Endpoint, h goagrpc.UnaryHandler) goagrpc.UnaryHandler {
+	if h == nil {
+		h = goagrpc.NewUnaryHandler(endpoint, DecodeGrpcMethodRequest, EncodeGrpcMethodResponse)
+	}
+	return h
+}
+
+// GrpcMethod implements the "GrpcMethod" method in testpb.TestServer interface.
+func (s *Server) GrpcMethod(ctx context.Context, message *testpb.GrpcMethodRequest) (*testpb.GrpcMethodResponse, error) {
+	ctx = context.WithValue(ctx, goa.MethodKey, "grpc_method")
+	ctx = context.WithValue(ctx, goa.ServiceKey, "test")
+	resp, err := s.GrpcMethodH.Handle(ctx, message)
+	if err != nil {
+		return nil, goagrpc.EncodeError(err)
+	}
+	return resp.(*testpb.GrpcMethodResponse), nil
+}
+
+// NewGrpcStreamHandler creates a gRPC handler which serves the "test" service
+// "grpc_stream" endpoint.
+func NewGrpcStreamHandler(endpoint goa.Endpoint, h goagrpc.StreamHandler) goagrpc.StreamHandler {
+	if h == nil {
+		h = goagrpc.NewStreamHandler(endpoint, nil)
+	}
+	return h
+}
+
+// GrpcStream implements the "GrpcStream" method in testpb.TestServer interface.
+func (s *Server) GrpcStream(stream testpb.Test_GrpcStreamServer) error {
+	ctx := stream.Context()
+	ctx = context.WithValue(ctx, goa.MethodKey, "grpc_stream")
+	ctx = context.WithValue(ctx, goa.ServiceKey, "test")
+	_, err := s.GrpcStreamH.Decode(ctx, nil)
+	if err != nil {
+		return goagrpc.EncodeError(err)
+	}
+	ep := &test.GrpcStreamEndpointInput{
+		Stream: &GrpcStreamServerStream{stream: stream},
+	}
+	err = s.GrpcStreamH.Handle(ctx, ep)
+	if err != nil {
+		return goagrpc.EncodeError(err)
+	}
+	return nil
+}
+
+// NewGrpcServerStreamHandler creates a gRPC handler which serves the "test"
+// service "grpc_server_stream" endpoint.
+func NewGrpcServerStreamHandler(endpoint goa.Endpoint, h goagrpc.StreamHandler) goagrpc.StreamHandler {
+	if h == nil {
+		h = goagrpc.NewStreamHandler(endpoint, DecodeGrpcServerStreamRequest)
+	}
+	return h
+}
+
+// GrpcServerStream implements the "GrpcServerStream" method in
+// testpb.TestServer interface.
+func (s *Server) GrpcServerStream(message *testpb.GrpcServerStreamRequest, stream testpb.Test_GrpcServerStreamServer) error {
+	ctx := stream.Context()
+	ctx = context.WithValue(ctx, goa.MethodKey, "grpc_server_stream")
+	ctx = context.WithValue(ctx, goa.ServiceKey, "test")
+	p, err := s.GrpcServerStreamH.Decode(ctx, message)
+	if err != nil {
+		return goagrpc.EncodeError(err)
+	}
+	ep := &test.GrpcServerStreamEndpointInput{
+		Stream:  &GrpcServerStreamServerStream{stream: stream},
+		Payload: p.(*test.Fields),
+	}
+	err = s.GrpcServerStreamH.Handle(ctx, ep)
+	if err != nil {
+		return goagrpc.EncodeError(err)
+	}
+	return nil
+}
+
+// NewGrpcClientStreamHandler creates a gRPC handler which serves the "test"
+// service "grpc_client_stream" endpoint.
+func NewGrpcClientStreamHandler(endpoint goa.Endpoint, h goagrpc.StreamHandler) goagrpc.StreamHandler {
+	if h == nil {
+		h = goagrpc.NewStreamHandler(endpoint, nil)
+	}
+	return h
+}
+
+// GrpcClientStream implements the "GrpcClientStream" method in
+// testpb.TestServer interface.
+func (s *Server) GrpcClientStream(stream testpb.Test_GrpcClientStreamServer) error {
+	ctx := stream.Context()
+	ctx = context.WithValue(ctx, goa.MethodKey, "grpc_client_stream")
+	ctx = context.WithValue(ctx, goa.ServiceKey, "test")
+	_, err := s.GrpcClientStreamH.Decode(ctx, nil)
+	if err != nil {
+		return goagrpc.EncodeError(err)
+	}
+	ep := &test.GrpcClientStreamEndpointInput{
+		Stream: &GrpcClientStreamServerStream{stream: stream},
+	}
+	err = s.GrpcClientStreamH.Handle(ctx, ep)
+	if err != nil {
+		return goagrpc.EncodeError(err)
+	}
+	return nil
+}
+
+// Send streams instances of "testpb.GrpcStreamResponse" to the "grpc_stream"
+// endpoint gRPC stream.
+func (s *GrpcStreamServerStream) Send(res *test.Fields) error {
+	v := NewProtoFieldsGrpcStreamResponse(res)
+	return s.stream.Send(v)
+}
+
+// Recv reads instances of "testpb.GrpcStreamStreamingRequest" from the
+// "grpc_stream" endpoint gRPC stream.
+func (s *GrpcStreamServerStream) Recv() (*test.Fields, error) {
+	var res *test.Fields
+	v, err := s.stream.Recv()
+	if err != nil {
+		return res, err
+	}
+	return NewGrpcStreamStreamingRequestFields(v), nil
+}
+
+func (s *GrpcStreamServerStream) Close() error {
+	// nothing to do here
+	return nil
+}
+
+// Send streams instances of "testpb.GrpcServerStreamResponse" to the
+// "grpc_server_stream" endpoint gRPC stream.
+func (s *GrpcServerStreamServerStream) Send(res *test.Fields) error {
+	v := NewProtoFieldsGrpcServerStreamResponse(res)
+	return s.stream.Send(v)
+}
+
+func (s *GrpcServerStreamServerStream) Close() error {
+	// nothing to do here
+	return nil
+}
+
+// SendAndClose streams instances of "testpb.GrpcClientStreamResponse" to the
+// "grpc_client_stream" endpoint gRPC stream.
+func (s *GrpcClientStreamServerStream) SendAndClose(res *test.Fields) error {
+	v := NewProtoFieldsGrpcClientStreamResponse(res)
+	return s.stream.SendAndClose(v)
+}
+
+// Recv reads instances of "testpb.GrpcClientStreamStreamingRequest" from the
+// "grpc_client_stream" endpoint gRPC stream.
+func (s *GrpcClientStreamServerStream) Recv() (*test.Fields, error) {
+	var res *test.Fields
+	v, err := s.stream.Recv()
+	if err != nil {
+		return res, err
+	}
+	return NewGrpcClientStreamStreamingRequestFields(v), nil
+}
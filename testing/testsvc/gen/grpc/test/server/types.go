@@ -0,0 +1,146 @@
+// Code generated by goa v3.12.3, DO NOT EDIT.
+//
+// test gRPC server types
+//
+// Command:
+// $ goa gen goa.design/clue/testing/testsvc/design
+
+package server
+
+import (
+	testpb "goa.design/clue/testing/testsvc/gen/grpc/test/pb"
+	test "goa.design/clue/testing/testsvc/gen/test"
+)
+
+// NewGrpcMethodPayload builds the payload of the "grpc_method" endpoint of the
+// "test" service from the gRPC request type.
+func NewGrpcMethodPayload(message *testpb.GrpcMethodRequest) *test.Fields {
+	v := &test.Fields{
+		S: message.S,
+	}
+	if message.I != nil {
+		i := int(*message.I)
+		v.I = &i
+	}
+	return v
+}
+
+// NewProtoGrpcMethodResponse builds the gRPC response type from the result of
+// the "grpc_method" endpoint of the "test" service.
+func NewProtoGrpcMethodResponse(result *test.Fields) *testpb.GrpcMethodResponse {
+	message := &testpb.GrpcMethodResponse{
+		S: result.S,
+	}
+	if result.I != nil {
+		i := int32(*result.I)
+		message.I = &i
+	}
+	return message
+}
+
+// NewProtoGrpcStreamResponse builds the gRPC response type from the result of
+// the "grpc_stream" endpoint of the "test" service.
+func NewProtoGrpcStreamResponse(result *test.Fields) *testpb.GrpcStreamResponse {
+	message := &testpb.GrpcStreamResponse{
+		S: result.S,
+	}
+	if result.I != nil {
+		i := int32(*result.I)
+		message.I = &i
+	}
+	return message
+}
+
+func NewProtoFieldsGrpcStreamResponse(result *test.Fields) *testpb.GrpcStreamResponse {
+	v := &testpb.GrpcStreamResponse{
+		S: result.S,
+	}
+	if result.I != nil {
+		i := int32(*result.I)
+		v.I = &i
+	}
+	return v
+}
+
+func NewGrpcStreamStreamingRequestFields(v *testpb.GrpcStreamStreamingRequest) *test.Fields {
+	spayload := &test.Fields{
+		S: v.S,
+	}
+	if v.I != nil {
+		i := int(*v.I)
+		spayload.I = &i
+	}
+	return spayload
+}
+
+// NewGrpcServerStreamPayload builds the payload of the "grpc_server_stream"
+// endpoint of the "test" service from the gRPC request type.
+func NewGrpcServerStreamPayload(message *testpb.GrpcServerStreamRequest) *test.Fields {
+	v := &test.Fields{
+		S: message.S,
+	}
+	if message.I != nil {
+		i := int(*message.I)
+		v.I = &i
+	}
+	return v
+}
+
+// NewProtoGrpcServerStreamResponse builds the gRPC response type from the
+// result of the "grpc_server_stream" endpoint of the "test" service.
+func NewProtoGrpcServerStreamResponse(result *test.Fields) *testpb.GrpcServerStreamResponse {
+	message := &testpb.GrpcServerStreamResponse{
+		S: result.S,
+	}
+	if result.I != nil {
+		i := int32(*result.I)
+		message.I = &i
+	}
+	return message
+}
+
+func NewProtoFieldsGrpcServerStreamResponse(result *test.Fields) *testpb.GrpcServerStreamResponse {
+	v := &testpb.GrpcServerStreamResponse{
+		S: result.S,
+	}
+	if result.I != nil {
+		i := int32(*result.I)
+		v.I = &i
+	}
+	return v
+}
+
+// NewProtoGrpcClientStreamResponse builds the gRPC response type from the
+// result of the "grpc_client_stream" endpoint of the "test" service.
+func NewProtoGrpcClientStreamResponse(result *test.Fields) *testpb.GrpcClientStreamResponse {
+	message := &testpb.GrpcClientStreamResponse{
+		S: result.S,
+	}
+	if result.I != nil {
+		i := int32(*result.I)
+		message.I = &i
+	}
+	return message
+}
+
+func NewProtoFieldsGrpcClientStreamResponse(result *test.Fields) *testpb.GrpcClientStreamResponse {
+	v := &testpb.GrpcClientStreamResponse{
+		S: result.S,
+	}
+	if result.I != nil {
+		i := int32(*result.I)
+		v.I = &i
+	}
+	return v
+}
+
+func NewGrpcClientStreamStreamingRequestFields(v *testpb.GrpcClientStreamStreamingRequest) *test.Fields {
+	spayload := &test.Fields{
+		S: v.S,
+	}
+	if v.I != nil {
+		i := int(*v.I)
+		spayload.I = &i
+	}
+	return spayload
+}
@@ -1,9 +1,9 @@
-// Code generated by goa v3.9.1, DO NOT EDIT.
+// Code generated by goa v3.12.3, DO NOT EDIT.
 //
 // itest HTTP client CLI support package
 //
 // Command:
-// $ goa gen goa.design/clue/internal/testsvc/design
+// $ goa gen goa.design/clue/testing/testsvc/design
 
 package cli
 
@@ -13,7 +13,7 @@ import (
 	"net/http"
 	"os"
 
-	testc "goa.design/clue/internal/testsvc/gen/http/test/client"
+	testc "goa.design/clue/testing/testsvc/gen/http/test/client"
 	goahttp "goa.design/goa/v3/http"
 	goa "goa.design/goa/v3/pkg"
 )
@@ -22,7 +22,7 @@ import (
 //
 //	command (subcommand1|subcommand2|...)
 func UsageCommands() string {
-	return `test http-method
+	return `test (http-method|http-stream)
 `
 }
 
@@ -42,16 +42,21 @@ func ParseEndpoint(
 	enc func(*http.Request) goahttp.Encoder,
 	dec func(*http.Response) goahttp.Decoder,
 	restore bool,
-) (goa.Endpoint, interface{}, error) {
+	dialer goahttp.Dialer,
+	testConfigurer *testc.ConnConfigurer,
+) (goa.Endpoint, any, error) {
 	var (
 		testFlags = flag.NewFlagSet("test", flag.ContinueOnError)
 
 		testHTTPMethodFlags    = flag.NewFlagSet("http-method", flag.ExitOnError)
 		testHTTPMethodBodyFlag = testHTTPMethodFlags.String("body", "REQUIRED", "")
 		testHTTPMethodIFlag    = testHTTPMethodFlags.String("i", "REQUIRED", "Int operand")
+
+		testHTTPStreamFlags = flag.NewFlagSet("http-stream", flag.ExitOnError)
 	)
 	testFlags.Usage = testUsage
 	testHTTPMethodFlags.Usage = testHTTPMethodUsage
+	testHTTPStreamFlags.Usage = testHTTPStreamUsage
 
 	if err := flag.CommandLine.Parse(os.Args[1:]); err != nil {
 		return nil, nil, err
@@ -90,6 +95,9 @@ func ParseEndpoint(
 			case "http-method":
 				epf = testHTTPMethodFlags
 
+			case "http-stream":
+				epf = testHTTPStreamFlags
+
 			}
 
 		}
@@ -106,18 +114,21 @@ func ParseEndpoint(
 	}
 
 	var (
-		data     interface{}
+		data     any
 		endpoint goa.Endpoint
 		err      error
 	)
 	{
 		switch svcn {
 		case "test":
-			c := testc.NewClient(scheme, host, doer, enc, dec, restore)
+			c := testc.NewClient(scheme, host, doer, enc, dec, restore, dialer, testConfigurer)
 			switch epn {
 			case "http-method":
 				endpoint = c.HTTPMethod()
 				data, err = testc.BuildHTTPMethodPayload(*testHTTPMethodBodyFlag, *testHTTPMethodIFlag)
+			case "http-stream":
+				endpoint = c.HTTPStream()
+				data = nil
 			}
 		}
 	}
@@ -136,6 +147,7 @@ Usage:
 
 COMMAND:
     http-method: HTTPMethod implements http_method.
+    http-stream: HTTPStream implements http_stream.
 
 Additional help:
     %[1]s test COMMAND --help
@@ -154,3 +166,13 @@ Example:
    }' --i 4433161771810333809
 `, os.Args[0])
 }
+
+func testHTTPStreamUsage() {
+	fmt.Fprintf(os.Stderr, `%[1]s [flags] test http-stream
+
+HTTPStream implements http_stream.
+
+Example:
+    %[1]s test http-stream
+`, os.Args[0])
+}
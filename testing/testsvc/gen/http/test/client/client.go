@@ -1,9 +1,9 @@
-// Code generated by goa v3.9.1, DO NOT EDIT.
+// Code generated by goa v3.12.3, DO NOT EDIT.
 //
 // test client HTTP transport
 //
 // Command:
-// $ goa gen goa.design/clue/internal/testsvc/design
+// $ goa gen goa.design/clue/testing/testsvc/design
 
 package client
 
@@ -21,14 +21,20 @@ type Client struct {
 	// endpoint.
 	HTTPMethodDoer goahttp.Doer
 
+	// HTTPStream Doer is the HTTP client used to make requests to the http_stream
+	// endpoint.
+	HTTPStreamDoer goahttp.Doer
+
 	// RestoreResponseBody controls whether the response bodies are reset after
 	// decoding so they can be read again.
 	RestoreResponseBody bool
 
-	scheme  string
-	host    string
-	encoder func(*http.Request) goahttp.Encoder
-	decoder func(*http.Response) goahttp.Decoder
+	scheme     string
+	host       string
+	encoder    func(*http.Request) goahttp.Encoder
+	decoder    func(*http.Response) goahttp.Decoder
+	dialer     goahttp.Dialer
+	configurer *ConnConfigurer
 }
 
 // NewClient instantiates HTTP clients for all the test service servers.
@@ -39,14 +45,22 @@ func NewClient(
 	enc func(*http.Request) goahttp.Encoder,
 	dec func(*http.Response) goahttp.Decoder,
 	restoreBody bool,
+	dialer goahttp.Dialer,
+	cfn *ConnConfigurer,
 ) *Client {
+	if cfn == nil {
+		cfn = &ConnConfigurer{}
+	}
 	return &Client{
 		HTTPMethodDoer:      doer,
+		HTTPStreamDoer:      doer,
 		RestoreResponseBody: restoreBody,
 		scheme:              scheme,
 		host:                host,
 		decoder:             dec,
 		encoder:             enc,
+		dialer:              dialer,
+		configurer:          cfn,
 	}
 }
 
@@ -57,7 +71,7 @@ func (c *Client) HTTPMethod() goa.Endpoint {
 		encodeRequest  = EncodeHTTPMethodRequest(c.encoder)
 		decodeResponse = DecodeHTTPMethodResponse(c.decoder, c.RestoreResponseBody)
 	)
-	return func(ctx context.Context, v interface{}) (interface{}, error) {
+	return func(ctx context.Context, v any) (any, error) {
 		req, err := c.BuildHTTPMethodRequest(ctx, v)
 		if err != nil {
 			return nil, err
@@ -73,3 +87,29 @@ func (c *Client) HTTPMethod() goa.Endpoint {
 		return decodeResponse(resp)
 	}
 }
+
+// HTTPStream returns an endpoint that makes HTTP requests to the test service
+// http_stream server.
+func (c *Client) HTTPStream() goa.Endpoint {
+	var (
+		decodeResponse = DecodeHTTPStreamResponse(c.decoder, c.RestoreResponseBody)
+	)
+	return func(ctx context.Context, v any) (any, error) {
+		req, err := c.BuildHTTPStreamRequest(ctx, v)
+		if err != nil {
+			return nil, err
+		}
+		conn, resp, err := c.dialer.DialContext(ctx, req.URL.String(), req.Header)
+		if err != nil {
+			if resp != nil {
+				return decodeResponse(resp)
+			}
+			return nil, goahttp.ErrRequestError("test", "http_stream", err)
+		}
+		if c.configurer.HTTPStreamFn != nil {
+			conn = c.configurer.HTTPStreamFn(conn, nil)
+		}
+		stream := &HTTPStreamClientStream{conn: conn}
+		return stream, nil
+	}
+}
@@ -1,9 +1,9 @@
-// Code generated by goa v3.9.1, DO NOT EDIT.
+// Code generated by goa v3.12.3, DO NOT EDIT.
 //
 // test HTTP client encoders and decoders
 //
 // Command:
-// $ goa gen goa.design/clue/internal/testsvc/design
+// $ goa gen goa.design/clue/testing/testsvc/design
 
 package client
 
@@ -14,13 +14,13 @@ import (
 	"net/http"
 	"net/url"
 
-	test "goa.design/clue/internal/testsvc/gen/test"
+	test "goa.design/clue/testing/testsvc/gen/test"
 	goahttp "goa.design/goa/v3/http"
 )
 
 // BuildHTTPMethodRequest instantiates a HTTP request object with method and
 // path set to call the "test" service "http_method" endpoint
-func (c *Client) BuildHTTPMethodRequest(ctx context.Context, v interface{}) (*http.Request, error) {
+func (c *Client) BuildHTTPMethodRequest(ctx context.Context, v any) (*http.Request, error) {
 	var (
 		i int
 	)
@@ -47,8 +47,8 @@ func (c *Client) BuildHTTPMethodRequest(ctx context.Context, v interface{}) (*ht
 
 // EncodeHTTPMethodRequest returns an encoder for requests sent to the test
 // http_method server.
-func EncodeHTTPMethodRequest(encoder func(*http.Request) goahttp.Encoder) func(*http.Request, interface{}) error {
-	return func(req *http.Request, v interface{}) error {
+func EncodeHTTPMethodRequest(encoder func(*http.Request) goahttp.Encoder) func(*http.Request, any) error {
+	return func(req *http.Request, v any) error {
 		p, ok := v.(*test.Fields)
 		if !ok {
 			return goahttp.ErrInvalidType("test", "http_method", "*test.Fields", v)
@@ -64,8 +64,8 @@ func EncodeHTTPMethodRequest(encoder func(*http.Request) goahttp.Encoder) func(*
 // DecodeHTTPMethodResponse returns a decoder for responses returned by the
 // test http_method endpoint. restoreBody controls whether the response body
 // should be restored after having been read.
-func DecodeHTTPMethodResponse(decoder func(*http.Response) goahttp.Decoder, restoreBody bool) func(*http.Response) (interface{}, error) {
-	return func(resp *http.Response) (interface{}, error) {
+func DecodeHTTPMethodResponse(decoder func(*http.Response) goahttp.Decoder, restoreBody bool) func(*http.Response) (any, error) {
+	return func(resp *http.Response) (any, error) {
 		if restoreBody {
 			b, err := io.ReadAll(resp.Body)
 			if err != nil {
@@ -96,3 +96,61 @@ func DecodeHTTPMethodResponse(decoder func(*http.Response) goahttp.Decoder, rest
 		}
 	}
 }
+
+// BuildHTTPStreamRequest instantiates a HTTP request object with method and
+// path set to call the "test" service "http_stream" endpoint
+func (c *Client) BuildHTTPStreamRequest(ctx context.Context, v any) (*http.Request, error) {
+	scheme := c.scheme
+	switch c.scheme {
+	case "http":
+		scheme = "ws"
+	case "https":
+		scheme = "wss"
+	}
+	u := &url.URL{Scheme: scheme, Host: c.host, Path: HTTPStreamTestPath()}
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return nil, goahttp.ErrInvalidURL("test", "http_stream", u.String(), err)
+	}
+	if ctx != nil {
+		req = req.WithContext(ctx)
+	}
+
+	return req, nil
+}
+
+// DecodeHTTPStreamResponse returns a decoder for responses returned by the
+// test http_stream endpoint. restoreBody controls whether the response body
+// should be restored after having been read.
+func DecodeHTTPStreamResponse(decoder func(*http.Response) goahttp.Decoder, restoreBody bool) func(*http.Response) (any, error) {
+	return func(resp *http.Response) (any, error) {
+		if restoreBody {
+			b, err := io.ReadAll(resp.Body)
+			if err != nil {
+				return nil, err
+			}
+			resp.Body = io.NopCloser(bytes.NewBuffer(b))
+			defer func() {
+				resp.Body = io.NopCloser(bytes.NewBuffer(b))
+			}()
+		} else {
+			defer resp.Body.Close()
+		}
+		switch resp.StatusCode {
+		case http.StatusOK:
+			var (
+				body HTTPStreamResponseBody
+				err  error
+			)
+			err = decoder(resp).Decode(&body)
+			if err != nil {
+				return nil, goahttp.ErrDecodingError("test", "http_stream", err)
+			}
+			res := NewHTTPStreamFieldsOK(&body)
+			return res, nil
+		default:
+			body, _ := io.ReadAll(resp.Body)
+			return nil, goahttp.ErrInvalidResponse("test", "http_stream", resp.StatusCode, string(body))
+		}
+	}
+}
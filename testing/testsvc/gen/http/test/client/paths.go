@@ -1,9 +1,9 @@
-// Code generated by goa v3.9.1, DO NOT EDIT.
+// Code generated by goa v3.12.3, DO NOT EDIT.
 //
 // HTTP request path constructors for the test service.
 //
 // Command:
-// $ goa gen goa.design/clue/internal/testsvc/design
+// $ goa gen goa.design/clue/testing/testsvc/design
 
 package client
 
@@ -15,3 +15,8 @@ import (
 func HTTPMethodTestPath(i int) string {
 	return fmt.Sprintf("/%v", i)
 }
+
+// HTTPStreamTestPath returns the URL path to the test service http_stream HTTP endpoint.
+func HTTPStreamTestPath() string {
+	return "/stream"
+}
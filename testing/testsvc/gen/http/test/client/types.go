@@ -0,0 +1,90 @@
+// Code generated by goa v3.12.3, DO NOT EDIT.
+//
+// test HTTP client types
+//
+// Command:
+// $ goa gen goa.design/clue/testing/testsvc/design
+
+package client
+
+import (
+	test "goa.design/clue/testing/testsvc/gen/test"
+)
+
+// HTTPMethodRequestBody is the type of the "test" service "http_method"
+// endpoint HTTP request body.
+type HTTPMethodRequestBody struct {
+	// String operand
+	S *string `form:"s,omitempty" json:"s,omitempty" xml:"s,omitempty"`
+}
+
+// HTTPStreamStreamingBody is the type of the "test" service "http_stream"
+// endpoint HTTP request body.
+type HTTPStreamStreamingBody FieldsStreamingBody
+
+// HTTPMethodResponseBody is the type of the "test" service "http_method"
+// endpoint HTTP response body.
+type HTTPMethodResponseBody struct {
+	// String operand
+	S *string `form:"s,omitempty" json:"s,omitempty" xml:"s,omitempty"`
+	// Int operand
+	I *int `form:"i,omitempty" json:"i,omitempty" xml:"i,omitempty"`
+}
+
+// HTTPStreamResponseBody is the type of the "test" service "http_stream"
+// endpoint HTTP response body.
+type HTTPStreamResponseBody struct {
+	// String operand
+	S *string `form:"s,omitempty" json:"s,omitempty" xml:"s,omitempty"`
+	// Int operand
+	I *int `form:"i,omitempty" json:"i,omitempty" xml:"i,omitempty"`
+}
+
+// FieldsStreamingBody is used to define fields on request body types.
+type FieldsStreamingBody struct {
+	// String operand
+	S *string `form:"s,omitempty" json:"s,omitempty" xml:"s,omitempty"`
+	// Int operand
+	I *int `form:"i,omitempty" json:"i,omitempty" xml:"i,omitempty"`
+}
+
+// NewHTTPMethodRequestBody builds the HTTP request body from the payload of
+// the "http_method" endpoint of the "test" service.
+func NewHTTPMethodRequestBody(p *test.Fields) *HTTPMethodRequestBody {
+	body := &HTTPMethodRequestBody{
+		S: p.S,
+	}
+	return body
+}
+
+// NewHTTPStreamStreamingBody builds the HTTP request body from the payload of
+// the "http_stream" endpoint of the "test" service.
+func NewHTTPStreamStreamingBody(p *test.Fields) *HTTPStreamStreamingBody {
+	body := &HTTPStreamStreamingBody{
+		S: p.S,
+		I: p.I,
+	}
+	return body
+}
+
+// NewHTTPMethodFieldsOK builds a "test" service "http_method" endpoint result
+// from a HTTP "OK" response.
+func NewHTTPMethodFieldsOK(body *HTTPMethodResponseBody) *test.Fields {
+	v := &test.Fields{
+		S: body.S,
+		I: body.I,
+	}
+
+	return v
+}
+
+// NewHTTPStreamFieldsOK builds a "test" service "http_stream" endpoint result
+// from a HTTP "OK" response.
+func NewHTTPStreamFieldsOK(body *HTTPStreamResponseBody) *test.Fields {
+	v := &test.Fields{
+		S: body.S,
+		I: body.I,
+	}
+
+	return v
+}
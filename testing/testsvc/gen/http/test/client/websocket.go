@@ -0,0 +1,72 @@
+// Code generated by goa v3.12.3, DO NOT EDIT.
+//
+// test WebSocket client streaming
+//
+// Command:
+// $ goa gen goa.design/clue/testing/testsvc/design
+
+package client
+
+import (
+	"io"
+
+	"github.com/gorilla/websocket"
+	test "goa.design/clue/testing/testsvc/gen/test"
+	goahttp "goa.design/goa/v3/http"
+)
+
+// ConnConfigurer holds the websocket connection configurer functions for the
+// streaming endpoints in "test" service.
+type ConnConfigurer struct {
+	HTTPStreamFn goahttp.ConnConfigureFunc
+}
+
+// HTTPStreamClientStream implements the test.HTTPStreamClientStream interface.
+type HTTPStreamClientStream struct {
+	// conn is the underlying websocket connection.
+	conn *websocket.Conn
+}
+
+// NewConnConfigurer initializes the websocket connection configurer function
+// with fn for all the streaming endpoints in "test" service.
+func NewConnConfigurer(fn goahttp.ConnConfigureFunc) *ConnConfigurer {
+	return &ConnConfigurer{
+		HTTPStreamFn: fn,
+	}
+}
+
+// Recv reads instances of "test.Fields" from the "http_stream" endpoint
+// websocket connection.
+func (s *HTTPStreamClientStream) Recv() (*test.Fields, error) {
+	var (
+		rv   *test.Fields
+		body HTTPStreamResponseBody
+		err  error
+	)
+	err = s.conn.ReadJSON(&body)
+	if websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+		return rv, io.EOF
+	}
+	if err != nil {
+		return rv, err
+	}
+	res := NewHTTPStreamFieldsOK(&body)
+	return res, nil
+}
+
+// Send streams instances of "test.Fields" to the "http_stream" endpoint
+// websocket connection.
+func (s *HTTPStreamClientStream) Send(v *test.Fields) error {
+	body := NewHTTPStreamStreamingBody(v)
+	return s.conn.WriteJSON(body)
+}
+
+// Close closes the "http_stream" endpoint websocket connection.
+func (s *HTTPStreamClientStream) Close() error {
+	var err error
+	// Send a nil payload to the server implying client closing connection.
+	if err = s.conn.WriteJSON(nil); err != nil {
+		return err
+	}
+	return s.conn.Close()
+}
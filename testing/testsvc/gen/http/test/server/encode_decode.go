@@ -1,9 +1,9 @@
-// Code generated by goa v3.9.1, DO NOT EDIT.
+// Code generated by goa v3.12.3, DO NOT EDIT.
 //
 // test HTTP server encoders and decoders
 //
 // Command:
-// $ goa gen goa.design/clue/internal/testsvc/design
+// $ goa gen goa.design/clue/testing/testsvc/design
 
 package server
 
@@ -13,15 +13,15 @@ import (
 	"net/http"
 	"strconv"
 
-	test "goa.design/clue/internal/testsvc/gen/test"
+	test "goa.design/clue/testing/testsvc/gen/test"
 	goahttp "goa.design/goa/v3/http"
 	goa "goa.design/goa/v3/pkg"
 )
 
 // EncodeHTTPMethodResponse returns an encoder for responses returned by the
 // test http_method endpoint.
-func EncodeHTTPMethodResponse(encoder func(context.Context, http.ResponseWriter) goahttp.Encoder) func(context.Context, http.ResponseWriter, interface{}) error {
-	return func(ctx context.Context, w http.ResponseWriter, v interface{}) error {
+func EncodeHTTPMethodResponse(encoder func(context.Context, http.ResponseWriter) goahttp.Encoder) func(context.Context, http.ResponseWriter, any) error {
+	return func(ctx context.Context, w http.ResponseWriter, v any) error {
 		res, _ := v.(*test.Fields)
 		enc := encoder(ctx, w)
 		body := NewHTTPMethodResponseBody(res)
@@ -32,8 +32,8 @@ func EncodeHTTPMethodResponse(encoder func(context.Context, http.ResponseWriter)
 
 // DecodeHTTPMethodRequest returns a decoder for requests sent to the test
 // http_method endpoint.
-func DecodeHTTPMethodRequest(mux goahttp.Muxer, decoder func(*http.Request) goahttp.Decoder) func(*http.Request) (interface{}, error) {
-	return func(r *http.Request) (interface{}, error) {
+func DecodeHTTPMethodRequest(mux goahttp.Muxer, decoder func(*http.Request) goahttp.Decoder) func(*http.Request) (any, error) {
+	return func(r *http.Request) (any, error) {
 		var (
 			body HTTPMethodRequestBody
 			err  error
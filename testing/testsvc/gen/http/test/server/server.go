@@ -1,9 +1,9 @@
-// Code generated by goa v3.9.1, DO NOT EDIT.
+// Code generated by goa v3.12.3, DO NOT EDIT.
 //
 // test HTTP server
 //
 // Command:
-// $ goa gen goa.design/clue/internal/testsvc/design
+// $ goa gen goa.design/clue/testing/testsvc/design
 
 package server
 
@@ -11,7 +11,7 @@ import (
 	"context"
 	"net/http"
 
-	test "goa.design/clue/internal/testsvc/gen/test"
+	test "goa.design/clue/testing/testsvc/gen/test"
 	goahttp "goa.design/goa/v3/http"
 	goa "goa.design/goa/v3/pkg"
 )
@@ -20,6 +20,7 @@ import (
 type Server struct {
 	Mounts     []*MountPoint
 	HTTPMethod http.Handler
+	HTTPStream http.Handler
 }
 
 // MountPoint holds information about the mounted endpoints.
@@ -46,12 +47,19 @@ func New(
 	encoder func(context.Context, http.ResponseWriter) goahttp.Encoder,
 	errhandler func(context.Context, http.ResponseWriter, error),
 	formatter func(ctx context.Context, err error) goahttp.Statuser,
+	upgrader goahttp.Upgrader,
+	configurer *ConnConfigurer,
 ) *Server {
+	if configurer == nil {
+		configurer = &ConnConfigurer{}
+	}
 	return &Server{
 		Mounts: []*MountPoint{
 			{"HTTPMethod", "POST", "/{i}"},
+			{"HTTPStream", "GET", "/stream"},
 		},
 		HTTPMethod: NewHTTPMethodHandler(e.HTTPMethod, mux, decoder, encoder, errhandler, formatter),
+		HTTPStream: NewHTTPStreamHandler(e.HTTPStream, mux, decoder, encoder, errhandler, formatter, upgrader, configurer.HTTPStreamFn),
 	}
 }
 
@@ -61,6 +69,7 @@ func (s *Server) Service() string { return "test" }
 // Use wraps the server handlers with the given middleware.
 func (s *Server) Use(m func(http.Handler) http.Handler) {
 	s.HTTPMethod = m(s.HTTPMethod)
+	s.HTTPStream = m(s.HTTPStream)
 }
 
 // MethodNames returns the methods served.
@@ -69,6 +78,7 @@ func (s *Server) MethodNames() []string { return test.MethodNames[:] }
 // Mount configures the mux to serve the test endpoints.
 func Mount(mux goahttp.Muxer, h *Server) {
 	MountHTTPMethodHandler(mux, h.HTTPMethod)
+	MountHTTPStreamHandler(mux, h.HTTPStream)
 }
 
 // Mount configures the mux to serve the test endpoints.
@@ -126,3 +136,61 @@ func NewHTTPMethodHandler(
 		}
 	})
 }
+
+// MountHTTPStreamHandler configures the mux to serve the "test" service
+// "http_stream" endpoint.
+func MountHTTPStreamHandler(mux goahttp.Muxer, h http.Handler) {
+	f, ok := h.(http.HandlerFunc)
+	if !ok {
+		f = func(w http.ResponseWriter, r *http.Request) {
+			h.ServeHTTP(w, r)
+		}
+	}
+	mux.Handle("GET", "/stream", f)
+}
+
+// NewHTTPStreamHandler creates a HTTP handler which loads the HTTP request and
+// calls the "test" service "http_stream" endpoint.
+func NewHTTPStreamHandler(
+	endpoint goa.Endpoint,
+	mux goahttp.Muxer,
+	decoder func(*http.Request) goahttp.Decoder,
+	encoder func(context.Context, http.ResponseWriter) goahttp.Encoder,
+	errhandler func(context.Context, http.ResponseWriter, error),
+	formatter func(ctx context.Context, err error) goahttp.Statuser,
+	upgrader goahttp.Upgrader,
+	configurer goahttp.ConnConfigureFunc,
+) http.Handler {
+	var (
+		encodeError = goahttp.ErrorEncoder(encoder, formatter)
+	)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), goahttp.AcceptTypeKey, r.Header.Get("Accept"))
+		ctx = context.WithValue(ctx, goa.MethodKey, "http_stream")
+		ctx = context.WithValue(ctx, goa.ServiceKey, "test")
+		var err error
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithCancel(ctx)
+		v := &test.HTTPStreamEndpointInput{
+			Stream: &HTTPStreamServerStream{
+				upgrader:   upgrader,
+				configurer: configurer,
+				cancel:     cancel,
+				w:          w,
+				r:          r,
+			},
+		}
+		_, err = endpoint(ctx, v)
+		if err != nil {
+			if _, werr := w.Write(nil); werr == http.ErrHijacked {
+				// Response writer has been hijacked, do not encode the error
+				errhandler(ctx, w, err)
+				return
+			}
+			if err := encodeError(ctx, w, err); err != nil {
+				errhandler(ctx, w, err)
+			}
+			return
+		}
+	})
+}
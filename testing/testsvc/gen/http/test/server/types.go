@@ -0,0 +1,90 @@
+// Code generated by goa v3.12.3, DO NOT EDIT.
+//
+// test HTTP server types
+//
+// Command:
+// $ goa gen goa.design/clue/testing/testsvc/design
+
+package server
+
+import (
+	test "goa.design/clue/testing/testsvc/gen/test"
+)
+
+// HTTPMethodRequestBody is the type of the "test" service "http_method"
+// endpoint HTTP request body.
+type HTTPMethodRequestBody struct {
+	// String operand
+	S *string `form:"s,omitempty" json:"s,omitempty" xml:"s,omitempty"`
+}
+
+// HTTPStreamStreamingBody is the type of the "test" service "http_stream"
+// endpoint HTTP request body.
+type HTTPStreamStreamingBody FieldsStreamingBody
+
+// HTTPMethodResponseBody is the type of the "test" service "http_method"
+// endpoint HTTP response body.
+type HTTPMethodResponseBody struct {
+	// String operand
+	S *string `form:"s,omitempty" json:"s,omitempty" xml:"s,omitempty"`
+	// Int operand
+	I *int `form:"i,omitempty" json:"i,omitempty" xml:"i,omitempty"`
+}
+
+// HTTPStreamResponseBody is the type of the "test" service "http_stream"
+// endpoint HTTP response body.
+type HTTPStreamResponseBody struct {
+	// String operand
+	S *string `form:"s,omitempty" json:"s,omitempty" xml:"s,omitempty"`
+	// Int operand
+	I *int `form:"i,omitempty" json:"i,omitempty" xml:"i,omitempty"`
+}
+
+// FieldsStreamingBody is used to define fields on request body types.
+type FieldsStreamingBody struct {
+	// String operand
+	S *string `form:"s,omitempty" json:"s,omitempty" xml:"s,omitempty"`
+	// Int operand
+	I *int `form:"i,omitempty" json:"i,omitempty" xml:"i,omitempty"`
+}
+
+// NewHTTPMethodResponseBody builds the HTTP response body from the result of
+// the "http_method" endpoint of the "test" service.
+func NewHTTPMethodResponseBody(res *test.Fields) *HTTPMethodResponseBody {
+	body := &HTTPMethodResponseBody{
+		S: res.S,
+		I: res.I,
+	}
+	return body
+}
+
+// NewHTTPStreamResponseBody builds the HTTP response body from the result of
+// the "http_stream" endpoint of the "test" service.
+func NewHTTPStreamResponseBody(res *test.Fields) *HTTPStreamResponseBody {
+	body := &HTTPStreamResponseBody{
+		S: res.S,
+		I: res.I,
+	}
+	return body
+}
+
+// NewHTTPMethodFields builds a test service http_method endpoint payload.
+func NewHTTPMethodFields(body *HTTPMethodRequestBody, i int) *test.Fields {
+	v := &test.Fields{
+		S: body.S,
+	}
+	v.I = &i
+
+	return v
+}
+
+// NewHTTPStreamStreamingBody builds a test service http_stream endpoint
+// payload.
+func NewHTTPStreamStreamingBody(body *HTTPStreamStreamingBody) *test.Fields {
+	v := &test.Fields{
+		S: body.S,
+		I: body.I,
+	}
+
+	return v
+}
@@ -0,0 +1,128 @@
+// Code generated by goa v3.12.3, DO NOT EDIT.
+//
+// test WebSocket server streaming
+//
+// Command:
+// $ goa gen goa.design/clue/testing/testsvc/design
+
+package server
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	test "goa.design/clue/testing/testsvc/gen/test"
+	goahttp "goa.design/goa/v3/http"
+)
+
+// ConnConfigurer holds the websocket connection configurer functions for the
+// streaming endpoints in "test" service.
+type ConnConfigurer struct {
+	HTTPStreamFn goahttp.ConnConfigureFunc
+}
+
+// HTTPStreamServerStream implements the test.HTTPStreamServerStream interface.
+type HTTPStreamServerStream struct {
+	once sync.Once
+	// upgrader is the websocket connection upgrader.
+	upgrader goahttp.Upgrader
+	// configurer is the websocket connection configurer.
+	configurer goahttp.ConnConfigureFunc
+	// cancel is the context cancellation function which cancels the request
+	// context when invoked.
+	cancel context.CancelFunc
+	// w is the HTTP response writer used in upgrading the connection.
+	w http.ResponseWriter
+	// r is the HTTP request.
+	r *http.Request
+	// conn is the underlying websocket connection.
+	conn *websocket.Conn
+}
+
+// NewConnConfigurer initializes the websocket connection configurer function
+// with fn for all the streaming endpoints in "test" service.
+func NewConnConfigurer(fn goahttp.ConnConfigureFunc) *ConnConfigurer {
+	return &ConnConfigurer{
+		HTTPStreamFn: fn,
+	}
+}
+
+// Send streams instances of "test.Fields" to the "http_stream" endpoint
+// websocket connection.
+func (s *HTTPStreamServerStream) Send(v *test.Fields) error {
+	var err error
+	// Upgrade the HTTP connection to a websocket connection only once. Connection
+	// upgrade is done here so that authorization logic in the endpoint is executed
+	// before calling the actual service method which may call Send().
+	s.once.Do(func() {
+		var conn *websocket.Conn
+		conn, err = s.upgrader.Upgrade(s.w, s.r, nil)
+		if err != nil {
+			return
+		}
+		if s.configurer != nil {
+			conn = s.configurer(conn, s.cancel)
+		}
+		s.conn = conn
+	})
+	if err != nil {
+		return err
+	}
+	res := v
+	body := NewHTTPStreamResponseBody(res)
+	return s.conn.WriteJSON(body)
+}
+
+// Recv reads instances of "test.Fields" from the "http_stream" endpoint
+// websocket connection.
+func (s *HTTPStreamServerStream) Recv() (*test.Fields, error) {
+	var (
+		rv  *test.Fields
+		msg *HTTPStreamStreamingBody
+		err error
+	)
+	// Upgrade the HTTP connection to a websocket connection only once. Connection
+	// upgrade is done here so that authorization logic in the endpoint is executed
+	// before calling the actual service method which may call Recv().
+	s.once.Do(func() {
+		var conn *websocket.Conn
+		conn, err = s.upgrader.Upgrade(s.w, s.r, nil)
+		if err != nil {
+			return
+		}
+		if s.configurer != nil {
+			conn = s.configurer(conn, s.cancel)
+		}
+		s.conn = conn
+	})
+	if err != nil {
+		return rv, err
+	}
+	if err = s.conn.ReadJSON(&msg); err != nil {
+		return rv, err
+	}
+	if msg == nil {
+		return rv, io.EOF
+	}
+	return NewHTTPStreamStreamingBody(msg), nil
+}
+
+// Close closes the "http_stream" endpoint websocket connection.
+func (s *HTTPStreamServerStream) Close() error {
+	var err error
+	if s.conn == nil {
+		return nil
+	}
+	if err = s.conn.WriteControl(
+		websocket.CloseMessage,
+		websocket.FormatCloseMessage(websocket.CloseNormalClosure, "server closing connection"),
+		time.Now().Add(time.Second),
+	); err != nil {
+		return err
+	}
+	return s.conn.Close()
+}
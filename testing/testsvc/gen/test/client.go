@@ -0,0 +1,98 @@
+// Code generated by goa v3.12.3, DO NOT EDIT.
+//
+// test client
+//
+// Command:
+// $ goa gen goa.design/clue/testing/testsvc/design
+
+package test
+
+import (
+	"context"
+
+	goa "goa.design/goa/v3/pkg"
+)
+
+// Client is the "test" service client.
+type Client struct {
+	HTTPMethodEndpoint       goa.Endpoint
+	HTTPStreamEndpoint       goa.Endpoint
+	GrpcMethodEndpoint       goa.Endpoint
+	GrpcStreamEndpoint       goa.Endpoint
+	GrpcServerStreamEndpoint goa.Endpoint
+	GrpcClientStreamEndpoint goa.Endpoint
+}
+
+// NewClient initializes a "test" service client given the endpoints.
+func NewClient(hTTPMethod, hTTPStream, grpcMethod, grpcStream, grpcServerStream, grpcClientStream goa.Endpoint) *Client {
+	return &Client{
+		HTTPMethodEndpoint:       hTTPMethod,
+		HTTPStreamEndpoint:       hTTPStream,
+		GrpcMethodEndpoint:       grpcMethod,
+		GrpcStreamEndpoint:       grpcStream,
+		GrpcServerStreamEndpoint: grpcServerStream,
+		GrpcClientStreamEndpoint: grpcClientStream,
+	}
+}
+
+// HTTPMethod calls the "http_method" endpoint of the "test" service.
+func (c *Client) HTTPMethod(ctx context.Context, p *Fields) (res *Fields, err error) {
+	var ires any
+	ires, err = c.HTTPMethodEndpoint(ctx, p)
+	if err != nil {
+		return
+	}
+	return ires.(*Fields), nil
+}
+
+// HTTPStream calls the "http_stream" endpoint of the "test" service.
+func (c *Client) HTTPStream(ctx context.Context) (res HTTPStreamClientStream, err error) {
+	var ires any
+	ires, err = c.HTTPStreamEndpoint(ctx, nil)
+	if err != nil {
+		return
+	}
+	return ires.(HTTPStreamClientStream), nil
+}
+
+// GrpcMethod calls the "grpc_method" endpoint of the "test" service.
+func (c *Client) GrpcMethod(ctx context.Context, p *Fields) (res *Fields, err error) {
+	var ires any
+	ires, err = c.GrpcMethodEndpoint(ctx, p)
+	if err != nil {
+		return
+	}
+	return ires.(*Fields), nil
+}
+
+// GrpcStream calls the "grpc_stream" endpoint of the "test" service.
+func (c *Client) GrpcStream(ctx context.Context) (res GrpcStreamClientStream, err error) {
+	var ires any
+	ires, err = c.GrpcStreamEndpoint(ctx, nil)
+	if err != nil {
+		return
+	}
+	return ires.(GrpcStreamClientStream), nil
+}
+
+// GrpcServerStream calls the "grpc_server_stream" endpoint of the "test"
+// service.
+func (c *Client) GrpcServerStream(ctx context.Context, p *Fields) (res GrpcServerStreamClientStream, err error) {
+	var ires any
+	ires, err = c.GrpcServerStreamEndpoint(ctx, p)
+	if err != nil {
+		return
+	}
+	return ires.(GrpcServerStreamClientStream), nil
+}
+
+// GrpcClientStream calls the "grpc_client_stream" endpoint of the "test"
+// service.
+func (c *Client) GrpcClientStream(ctx context.Context) (res GrpcClientStreamClientStream, err error) {
+	var ires any
+	ires, err = c.GrpcClientStreamEndpoint(ctx, nil)
+	if err != nil {
+		return
+	}
+	return ires.(GrpcClientStreamClientStream), nil
+}
@@ -0,0 +1,132 @@
+// Code generated by goa v3.12.3, DO NOT EDIT.
+//
+// test endpoints
+//
+// Command:
+// $ goa gen goa.design/clue/testing/testsvc/design
+
+package test
+
+import (
+	"context"
+
+	goa "goa.design/goa/v3/pkg"
+)
+
+// Endpoints wraps the "test" service endpoints.
+type Endpoints struct {
+	HTTPMethod       goa.Endpoint
+	HTTPStream       goa.Endpoint
+	GrpcMethod       goa.Endpoint
+	GrpcStream       goa.Endpoint
+	GrpcServerStream goa.Endpoint
+	GrpcClientStream goa.Endpoint
+}
+
+// HTTPStreamEndpointInput holds both the payload and the server stream of the
+// "http_stream" method.
+type HTTPStreamEndpointInput struct {
+	// Stream is the server stream used by the "http_stream" method to send data.
+	Stream HTTPStreamServerStream
+}
+
+// GrpcStreamEndpointInput holds both the payload and the server stream of the
+// "grpc_stream" method.
+type GrpcStreamEndpointInput struct {
+	// Stream is the server stream used by the "grpc_stream" method to send data.
+	Stream GrpcStreamServerStream
+}
+
+// GrpcServerStreamEndpointInput holds both the payload and the server stream
+// of the "grpc_server_stream" method.
+type GrpcServerStreamEndpointInput struct {
+	// Payload is the method payload.
+	Payload *Fields
+	// Stream is the server stream used by the "grpc_server_stream" method to send
+	// data.
+	Stream GrpcServerStreamServerStream
+}
+
+// GrpcClientStreamEndpointInput holds both the payload and the server stream
+// of the "grpc_client_stream" method.
+type GrpcClientStreamEndpointInput struct {
+	// Stream is the server stream used by the "grpc_client_stream" method to send
+	// data.
+	Stream GrpcClientStreamServerStream
+}
+
+// NewEndpoints wraps the methods of the "test" service with endpoints.
+func NewEndpoints(s Service) *Endpoints {
+	return &Endpoints{
+		HTTPMethod:       NewHTTPMethodEndpoint(s),
+		HTTPStream:       NewHTTPStreamEndpoint(s),
+		GrpcMethod:       NewGrpcMethodEndpoint(s),
+		GrpcStream:       NewGrpcStreamEndpoint(s),
+		GrpcServerStream: NewGrpcServerStreamEndpoint(s),
+		GrpcClientStream: NewGrpcClientStreamEndpoint(s),
+	}
+}
+
+// Use applies the given middleware to all the "test" service endpoints.
+func (e *Endpoints) Use(m func(goa.Endpoint) goa.Endpoint) {
+	e.HTTPMethod = m(e.HTTPMethod)
+	e.HTTPStream = m(e.HTTPStream)
+	e.GrpcMethod = m(e.GrpcMethod)
+	e.GrpcStream = m(e.GrpcStream)
+	e.GrpcServerStream = m(e.GrpcServerStream)
+	e.GrpcClientStream = m(e.GrpcClientStream)
+}
+
+// NewHTTPMethodEndpoint returns an endpoint function that calls the method
+// "http_method" of service "test".
+func NewHTTPMethodEndpoint(s Service) goa.Endpoint {
+	return func(ctx context.Context, req any) (any, error) {
+		p := req.(*Fields)
+		return s.HTTPMethod(ctx, p)
+	}
+}
+
+// NewHTTPStreamEndpoint returns an endpoint function that calls the method
+// "http_stream" of service "test".
+func NewHTTPStreamEndpoint(s Service) goa.Endpoint {
+	return func(ctx context.Context, req any) (any, error) {
+		ep := req.(*HTTPStreamEndpointInput)
+		return nil, s.HTTPStream(ctx, ep.Stream)
+	}
+}
+
+// NewGrpcMethodEndpoint returns an endpoint function that calls the method
+// "grpc_method" of service "test".
+func NewGrpcMethodEndpoint(s Service) goa.Endpoint {
+	return func(ctx context.Context, req any) (any, error) {
+		p := req.(*Fields)
+		return s.GrpcMethod(ctx, p)
+	}
+}
+
+// NewGrpcStreamEndpoint returns an endpoint function that calls the method
+// "grpc_stream" of service "test".
+func NewGrpcStreamEndpoint(s Service) goa.Endpoint {
+	return func(ctx context.Context, req any) (any, error) {
+		ep := req.(*GrpcStreamEndpointInput)
+		return nil, s.GrpcStream(ctx, ep.Stream)
+	}
+}
+
+// NewGrpcServerStreamEndpoint returns an endpoint function that calls the
+// method "grpc_server_stream" of service "test".
+func NewGrpcServerStreamEndpoint(s Service) goa.Endpoint {
+	return func(ctx context.Context, req any) (any, error) {
+		ep := req.(*GrpcServerStreamEndpointInput)
+		return nil, s.GrpcServerStream(ctx, ep.Payload, ep.Stream)
+	}
+}
+
+// NewGrpcClientStreamEndpoint returns an endpoint function that calls the
+// method "grpc_client_stream" of service "test".
+func NewGrpcClientStreamEndpoint(s Service) goa.Endpoint {
+	return func(ctx context.Context, req any) (any, error) {
+		ep := req.(*GrpcClientStreamEndpointInput)
+		return nil, s.GrpcClientStream(ctx, ep.Stream)
+	}
+}
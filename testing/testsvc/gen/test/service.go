@@ -0,0 +1,125 @@
+// Code generated by goa v3.12.3, DO NOT EDIT.
+//
+// test service
+//
+// Command:
+// $ goa gen goa.design/clue/testing/testsvc/design
+
+package test
+
+import (
+	"context"
+)
+
+// Service is the test service interface.
+type Service interface {
+	// HTTPMethod implements http_method.
+	HTTPMethod(context.Context, *Fields) (res *Fields, err error)
+	// HTTPStream implements http_stream.
+	HTTPStream(context.Context, HTTPStreamServerStream) (err error)
+	// GrpcMethod implements grpc_method.
+	GrpcMethod(context.Context, *Fields) (res *Fields, err error)
+	// GrpcStream implements grpc_stream.
+	GrpcStream(context.Context, GrpcStreamServerStream) (err error)
+	// GrpcServerStream implements grpc_server_stream.
+	GrpcServerStream(context.Context, *Fields, GrpcServerStreamServerStream) (err error)
+	// GrpcClientStream implements grpc_client_stream.
+	GrpcClientStream(context.Context, GrpcClientStreamServerStream) (err error)
+}
+
+// ServiceName is the name of the service as defined in the design. This is the
+// same value that is set in the endpoint request contexts under the ServiceKey
+// key.
+const ServiceName = "test"
+
+// MethodNames lists the service method names as defined in the design. These
+// are the same values that are set in the endpoint request contexts under the
+// MethodKey key.
+var MethodNames = [6]string{"http_method", "http_stream", "grpc_method", "grpc_stream", "grpc_server_stream", "grpc_client_stream"}
+
+// HTTPStreamServerStream is the interface a "http_stream" endpoint server
+// stream must satisfy.
+type HTTPStreamServerStream interface {
+	// Send streams instances of "Fields".
+	Send(*Fields) error
+	// Recv reads instances of "Fields" from the stream.
+	Recv() (*Fields, error)
+	// Close closes the stream.
+	Close() error
+}
+
+// HTTPStreamClientStream is the interface a "http_stream" endpoint client
+// stream must satisfy.
+type HTTPStreamClientStream interface {
+	// Send streams instances of "Fields".
+	Send(*Fields) error
+	// Recv reads instances of "Fields" from the stream.
+	Recv() (*Fields, error)
+	// Close closes the stream.
+	Close() error
+}
+
+// GrpcStreamServerStream is the interface a "grpc_stream" endpoint server
+// stream must satisfy.
+type GrpcStreamServerStream interface {
+	// Send streams instances of "Fields".
+	Send(*Fields) error
+	// Recv reads instances of "Fields" from the stream.
+	Recv() (*Fields, error)
+	// Close closes the stream.
+	Close() error
+}
+
+// GrpcStreamClientStream is the interface a "grpc_stream" endpoint client
+// stream must satisfy.
+type GrpcStreamClientStream interface {
+	// Send streams instances of "Fields".
+	Send(*Fields) error
+	// Recv reads instances of "Fields" from the stream.
+	Recv() (*Fields, error)
+	// Close closes the stream.
+	Close() error
+}
+
+// GrpcServerStreamServerStream is the interface a "grpc_server_stream"
+// endpoint server stream must satisfy.
+type GrpcServerStreamServerStream interface {
+	// Send streams instances of "Fields".
+	Send(*Fields) error
+	// Close closes the stream.
+	Close() error
+}
+
+// GrpcServerStreamClientStream is the interface a "grpc_server_stream"
+// endpoint client stream must satisfy.
+type GrpcServerStreamClientStream interface {
+	// Recv reads instances of "Fields" from the stream.
+	Recv() (*Fields, error)
+}
+
+// GrpcClientStreamServerStream is the interface a "grpc_client_stream"
+// endpoint server stream must satisfy.
+type GrpcClientStreamServerStream interface {
+	// SendAndClose streams instances of "Fields" and closes the stream.
+	SendAndClose(*Fields) error
+	// Recv reads instances of "Fields" from the stream.
+	Recv() (*Fields, error)
+}
+
+// GrpcClientStreamClientStream is the interface a "grpc_client_stream"
+// endpoint client stream must satisfy.
+type GrpcClientStreamClientStream interface {
+	// Send streams instances of "Fields".
+	Send(*Fields) error
+	// CloseAndRecv stops sending messages to the stream and reads instances of
+	// "Fields" from the stream.
+	CloseAndRecv() (*Fields, error)
+}
+
+// Fields is the payload type of the test service http_method method.
+type Fields struct {
+	// String operand
+	S *string
+	// Int operand
+	I *int
+}
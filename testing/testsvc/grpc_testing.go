@@ -5,10 +5,10 @@ import (
 	"net"
 	"testing"
 
-	"goa.design/clue/internal/testsvc/gen/grpc/test/client"
-	testpb "goa.design/clue/internal/testsvc/gen/grpc/test/pb"
-	"goa.design/clue/internal/testsvc/gen/grpc/test/server"
-	"goa.design/clue/internal/testsvc/gen/test"
+	"goa.design/clue/testing/testsvc/gen/grpc/test/client"
+	testpb "goa.design/clue/testing/testsvc/gen/grpc/test/pb"
+	"goa.design/clue/testing/testsvc/gen/grpc/test/server"
+	"goa.design/clue/testing/testsvc/gen/test"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 )
@@ -18,6 +18,8 @@ type (
 	GRPClient interface {
 		GRPCMethod(context.Context, *Fields) (*Fields, error)
 		GRPCStream(context.Context) (Stream, error)
+		GRPCServerStream(context.Context, *Fields) (ServerStreamReceiver, error)
+		GRPCClientStream(context.Context) (ClientStreamSender, error)
 	}
 
 	// GRPCOption is a function that can be used to configure the gRPC server.
@@ -28,10 +30,12 @@ type (
 	}
 
 	grpcOptions struct {
-		grpcfn        UnaryFunc
-		streamfn      StreamFunc
-		serverOptions []grpc.ServerOption
-		dialOptions   []grpc.DialOption
+		grpcfn         UnaryFunc
+		streamfn       StreamFunc
+		serverStreamfn ServerStreamFunc
+		clientStreamfn ClientStreamFunc
+		serverOptions  []grpc.ServerOption
+		dialOptions    []grpc.DialOption
 	}
 )
 
@@ -48,7 +52,12 @@ func SetupGRPC(t *testing.T, opts ...GRPCOption) (c GRPClient, stop func()) {
 	}
 
 	// Create test gRPC server
-	s := Service{GRPCFunc: options.grpcfn, StreamFunc: options.streamfn}
+	s := Service{
+		GRPCFunc:         options.grpcfn,
+		StreamFunc:       options.streamfn,
+		ServerStreamFunc: options.serverStreamfn,
+		ClientStreamFunc: options.clientStreamfn,
+	}
 	endpoints := test.NewEndpoints(&s)
 	svr := server.New(endpoints, nil, nil)
 	server := grpc.NewServer(options.serverOptions...)
@@ -86,13 +95,30 @@ func WithUnaryFunc(fn UnaryFunc) GRPCOption {
 	}
 }
 
-// WithStreamFunc provides the implementation for the gRPC streaming method.
+// WithStreamFunc provides the implementation for the gRPC bidirectional
+// streaming method.
 func WithStreamFunc(fn StreamFunc) GRPCOption {
 	return func(opt *grpcOptions) {
 		opt.streamfn = fn
 	}
 }
 
+// WithServerStreamFunc provides the implementation for the gRPC server
+// streaming method.
+func WithServerStreamFunc(fn ServerStreamFunc) GRPCOption {
+	return func(opt *grpcOptions) {
+		opt.serverStreamfn = fn
+	}
+}
+
+// WithClientStreamFunc provides the implementation for the gRPC client
+// streaming method.
+func WithClientStreamFunc(fn ClientStreamFunc) GRPCOption {
+	return func(opt *grpcOptions) {
+		opt.clientStreamfn = fn
+	}
+}
+
 // WithServerOptions configures the gRPC server.
 func WithServerOptions(opts ...grpc.ServerOption) GRPCOption {
 	return func(opt *grpcOptions) {
@@ -133,3 +159,25 @@ func (c grpcc) GRPCStream(ctx context.Context) (Stream, error) {
 	}
 	return adapter{res.(test.GrpcStreamClientStream)}, nil
 }
+
+// GRPCServerStream implements the gRPC server streaming method.
+func (c grpcc) GRPCServerStream(ctx context.Context, req *Fields) (ServerStreamReceiver, error) {
+	rq := &test.Fields{}
+	if req != nil {
+		*rq = test.Fields(*req)
+	}
+	res, err := c.genc.GrpcServerStream()(ctx, rq)
+	if err != nil {
+		return nil, err
+	}
+	return serverStreamReceiver{res.(test.GrpcServerStreamClientStream)}, nil
+}
+
+// GRPCClientStream implements the gRPC client streaming method.
+func (c grpcc) GRPCClientStream(ctx context.Context) (ClientStreamSender, error) {
+	res, err := c.genc.GrpcClientStream()(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	return clientStreamSender{res.(test.GrpcClientStreamClientStream)}, nil
+}
@@ -0,0 +1,111 @@
+package testsvc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestGRPCStream(t *testing.T) {
+	cli, stop := SetupGRPC(t, WithStreamFunc(func(_ context.Context, stream Stream) error {
+		for {
+			f, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				return stream.Close()
+			}
+			if err != nil {
+				return err
+			}
+			if err := stream.Send(f); err != nil {
+				return err
+			}
+		}
+	}))
+	defer stop()
+	stream, err := cli.GRPCStream(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		if err := stream.Send(&Fields{I: &i}); err != nil {
+			t.Fatal(err)
+		}
+		f, err := stream.Recv()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if f.I == nil || *f.I != i {
+			t.Errorf("got %v, expected %d", f.I, i)
+		}
+	}
+	if err := stream.Close(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestGRPCServerStream(t *testing.T) {
+	cli, stop := SetupGRPC(t, WithServerStreamFunc(func(_ context.Context, req *Fields, stream ServerStreamSender) error {
+		for i := 0; i < *req.I; i++ {
+			i := i
+			if err := stream.Send(&Fields{I: &i}); err != nil {
+				return err
+			}
+		}
+		return stream.Close()
+	}))
+	defer stop()
+	n := 3
+	stream, err := cli.GRPCServerStream(context.Background(), &Fields{I: &n})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []int
+	for {
+		f, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, *f.I)
+	}
+	if len(got) != n || got[0] != 0 || got[n-1] != n-1 {
+		t.Errorf("got %v, expected [0 1 2]", got)
+	}
+}
+
+func TestGRPCClientStream(t *testing.T) {
+	cli, stop := SetupGRPC(t, WithClientStreamFunc(func(_ context.Context, stream ClientStreamReceiver) error {
+		var sum int
+		for {
+			f, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				return stream.SendAndClose(&Fields{I: &sum})
+			}
+			if err != nil {
+				return err
+			}
+			sum += *f.I
+		}
+	}))
+	defer stop()
+	stream, err := cli.GRPCClientStream(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i <= 3; i++ {
+		i := i
+		if err := stream.Send(&Fields{I: &i}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	res, err := stream.CloseAndRecv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.I == nil || *res.I != 6 {
+		t.Errorf("got %v, expected 6", res.I)
+	}
+}
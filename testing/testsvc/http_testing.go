@@ -7,17 +7,19 @@ import (
 	"net/url"
 	"testing"
 
+	"github.com/gorilla/websocket"
 	goahttp "goa.design/goa/v3/http"
 
-	"goa.design/clue/internal/testsvc/gen/http/test/client"
-	"goa.design/clue/internal/testsvc/gen/http/test/server"
-	"goa.design/clue/internal/testsvc/gen/test"
+	"goa.design/clue/testing/testsvc/gen/http/test/client"
+	"goa.design/clue/testing/testsvc/gen/http/test/server"
+	"goa.design/clue/testing/testsvc/gen/test"
 )
 
 type (
 	// HTTPClient is a test service HTTP client.
 	HTTPClient interface {
 		HTTPMethod(ctx context.Context, req *Fields) (res *Fields, err error)
+		HTTPStream(ctx context.Context) (Stream, error)
 	}
 
 	// HTTPOption is a function that can be used to configure the HTTP server.
@@ -29,6 +31,7 @@ type (
 
 	httpOptions struct {
 		fn         UnaryFunc
+		streamfn   StreamFunc
 		middleware []func(http.Handler) http.Handler
 	}
 )
@@ -45,10 +48,10 @@ func SetupHTTP(t *testing.T, opts ...HTTPOption) (c HTTPClient, stop func()) {
 	}
 
 	// Create test HTTP server
-	svc := &Service{HTTPFunc: options.fn}
+	svc := &Service{HTTPFunc: options.fn, HTTPStreamFunc: options.streamfn}
 	endpoints := test.NewEndpoints(svc)
 	mux := goahttp.NewMuxer()
-	svr := server.New(endpoints, mux, goahttp.RequestDecoder, goahttp.ResponseEncoder, nil, nil)
+	svr := server.New(endpoints, mux, goahttp.RequestDecoder, goahttp.ResponseEncoder, nil, nil, &websocket.Upgrader{}, nil)
 	server.Mount(mux, svr)
 	var handler http.Handler = mux
 	for i := range options.middleware {
@@ -58,7 +61,7 @@ func SetupHTTP(t *testing.T, opts ...HTTPOption) (c HTTPClient, stop func()) {
 
 	// Create client
 	u, _ := url.Parse(httpsvr.URL)
-	c = httpc{client.NewClient("http", u.Host, http.DefaultClient, goahttp.RequestEncoder, goahttp.ResponseDecoder, false)}
+	c = httpc{client.NewClient("http", u.Host, http.DefaultClient, goahttp.RequestEncoder, goahttp.ResponseDecoder, false, websocket.DefaultDialer, nil)}
 
 	// Cleanup
 	stop = func() {
@@ -74,6 +77,14 @@ func WithHTTPFunc(fn UnaryFunc) HTTPOption {
 	}
 }
 
+// WithHTTPStreamFunc provides the implementation for the HTTP WebSocket
+// streaming method, see also Script.
+func WithHTTPStreamFunc(fn StreamFunc) HTTPOption {
+	return func(opt *httpOptions) {
+		opt.streamfn = fn
+	}
+}
+
 func WithHTTPMiddleware(fn ...func(http.Handler) http.Handler) HTTPOption {
 	return func(opt *httpOptions) {
 		opt.middleware = fn
@@ -96,3 +107,13 @@ func (c httpc) HTTPMethod(ctx context.Context, req *Fields) (res *Fields, err er
 	}
 	return
 }
+
+// HTTPStream implements HTTPClient.HTTPStream using the generated client, the
+// stream is a WebSocket connection.
+func (c httpc) HTTPStream(ctx context.Context) (Stream, error) {
+	res, err := c.genc.HTTPStream()(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	return adapter{res.(test.HTTPStreamClientStream)}, nil
+}
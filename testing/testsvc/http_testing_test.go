@@ -0,0 +1,73 @@
+package testsvc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestHTTPStreamScript(t *testing.T) {
+	var received []int
+	s := "hello"
+	cli, stop := SetupHTTP(t, WithHTTPStreamFunc(Script(
+		ScriptRecv(func(f *Fields) error {
+			received = append(received, *f.I)
+			return nil
+		}),
+		ScriptSend(&Fields{S: &s}),
+		ScriptSleep(10*time.Millisecond),
+		ScriptEcho(2),
+	)))
+	defer stop()
+	stream, err := cli.HTTPStream(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	one := 1
+	if err := stream.Send(&Fields{I: &one}); err != nil {
+		t.Fatal(err)
+	}
+	f, err := stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.S == nil || *f.S != s {
+		t.Errorf("got %v, expected %q", f.S, s)
+	}
+	for i := 2; i <= 3; i++ {
+		i := i
+		if err := stream.Send(&Fields{I: &i}); err != nil {
+			t.Fatal(err)
+		}
+		f, err := stream.Recv()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if f.I == nil || *f.I != i {
+			t.Errorf("got %v, expected %d", f.I, i)
+		}
+	}
+	if _, err := stream.Recv(); !errors.Is(err, io.EOF) {
+		t.Errorf("got error %v, expected EOF", err)
+	}
+	if len(received) != 1 || received[0] != 1 {
+		t.Errorf("got received %v, expected [1]", received)
+	}
+}
+
+func TestHTTPStreamScriptFail(t *testing.T) {
+	cli, stop := SetupHTTP(t, WithHTTPStreamFunc(Script(ScriptFail(fmt.Errorf("boom")))))
+	defer stop()
+	stream, err := cli.HTTPStream(context.Background())
+	if err == nil {
+		// The connection is upgraded lazily by the server so the error may
+		// only be returned when reading from the stream.
+		_, err = stream.Recv()
+	}
+	if err == nil {
+		t.Error("expected an error")
+	}
+}
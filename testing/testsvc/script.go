@@ -0,0 +1,101 @@
+package testsvc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+// ScriptStep is a step of a stream script, see Script.
+type ScriptStep func(ctx context.Context, stream Stream) error
+
+// Script returns a stream implementation that runs the given steps in order
+// then closes the stream. The script stops at the first step that returns an
+// error, the error is returned to the client. Scripts make it possible to
+// control the exact sequence of messages exchanged over the gRPC bidirectional
+// stream (see WithStreamFunc) or the HTTP WebSocket stream (see
+// WithHTTPStreamFunc).
+//
+// Example:
+//
+//	cli, stop := testsvc.SetupHTTP(t, testsvc.WithHTTPStreamFunc(testsvc.Script(
+//		testsvc.ScriptRecv(nil),
+//		testsvc.ScriptSend(&testsvc.Fields{S: &s}),
+//		testsvc.ScriptEcho(-1),
+//	)))
+func Script(steps ...ScriptStep) StreamFunc {
+	return func(ctx context.Context, stream Stream) error {
+		for _, step := range steps {
+			if err := step(ctx, stream); err != nil {
+				return err
+			}
+		}
+		return stream.Close()
+	}
+}
+
+// ScriptSend returns a step that sends f.
+func ScriptSend(f *Fields) ScriptStep {
+	return func(_ context.Context, stream Stream) error {
+		return stream.Send(f)
+	}
+}
+
+// ScriptRecv returns a step that receives a message and calls check with it if
+// check is not nil. The step fails if the error returned by check is not nil.
+func ScriptRecv(check func(*Fields) error) ScriptStep {
+	return func(_ context.Context, stream Stream) error {
+		f, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+		if check != nil {
+			return check(f)
+		}
+		return nil
+	}
+}
+
+// ScriptEcho returns a step that receives n messages and sends each one back.
+// If n is negative the step echoes messages until the client closes the
+// stream.
+func ScriptEcho(n int) ScriptStep {
+	return func(_ context.Context, stream Stream) error {
+		for i := 0; n < 0 || i < n; i++ {
+			f, err := stream.Recv()
+			if n < 0 && errors.Is(err, io.EOF) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			if err := stream.Send(f); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// ScriptSleep returns a step that waits for d or until the request context is
+// done.
+func ScriptSleep(d time.Duration) ScriptStep {
+	return func(ctx context.Context, _ Stream) error {
+		timer := time.NewTimer(d)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// ScriptFail returns a step that ends the stream with err.
+func ScriptFail(err error) ScriptStep {
+	return func(context.Context, Stream) error {
+		return err
+	}
+}
@@ -0,0 +1,223 @@
+package testsvc
+
+import (
+	"context"
+
+	"goa.design/clue/testing/testsvc/gen/test"
+)
+
+type (
+	UnaryFunc        func(context.Context, *Fields) (res *Fields, err error)
+	StreamFunc       func(context.Context, Stream) (err error)
+	ServerStreamFunc func(context.Context, *Fields, ServerStreamSender) (err error)
+	ClientStreamFunc func(context.Context, ClientStreamReceiver) (err error)
+
+	// Shadow generated type to avoid dependency creep.
+	Fields test.Fields
+
+	// Stream is the bidirectional stream of the gRPC stream method and of
+	// the HTTP WebSocket stream method.
+	Stream interface {
+		Send(*Fields) error
+		Recv() (*Fields, error)
+		Close() error
+	}
+
+	// ServerStreamSender is the server side of the gRPC server streaming
+	// method.
+	ServerStreamSender interface {
+		Send(*Fields) error
+		Close() error
+	}
+
+	// ServerStreamReceiver is the client side of the gRPC server streaming
+	// method.
+	ServerStreamReceiver interface {
+		Recv() (*Fields, error)
+	}
+
+	// ClientStreamReceiver is the server side of the gRPC client streaming
+	// method.
+	ClientStreamReceiver interface {
+		Recv() (*Fields, error)
+		SendAndClose(*Fields) error
+	}
+
+	// ClientStreamSender is the client side of the gRPC client streaming
+	// method.
+	ClientStreamSender interface {
+		Send(*Fields) error
+		CloseAndRecv() (*Fields, error)
+	}
+
+	Service struct {
+		HTTPFunc         UnaryFunc
+		HTTPStreamFunc   StreamFunc
+		GRPCFunc         UnaryFunc
+		StreamFunc       StreamFunc
+		ServerStreamFunc ServerStreamFunc
+		ClientStreamFunc ClientStreamFunc
+	}
+
+	adapter struct {
+		stream bidiStream
+	}
+
+	// bidiStream is implemented by the generated bidirectional streams.
+	bidiStream interface {
+		Send(*test.Fields) error
+		Recv() (*test.Fields, error)
+		Close() error
+	}
+
+	serverStreamSender struct {
+		stream test.GrpcServerStreamServerStream
+	}
+
+	serverStreamReceiver struct {
+		stream test.GrpcServerStreamClientStream
+	}
+
+	clientStreamReceiver struct {
+		stream test.GrpcClientStreamServerStream
+	}
+
+	clientStreamSender struct {
+		stream test.GrpcClientStreamClientStream
+	}
+)
+
+func (s *Service) HTTPMethod(ctx context.Context, req *test.Fields) (res *test.Fields, err error) {
+	if s.HTTPFunc == nil {
+		return
+	}
+
+	var r *Fields
+	if req != nil {
+		r = &Fields{}
+		*r = Fields(*req)
+	}
+	var resp *Fields
+	resp, err = s.HTTPFunc(ctx, r)
+	if resp != nil {
+		res = &test.Fields{}
+		*res = test.Fields(*resp)
+	}
+	return
+}
+
+func (s *Service) HTTPStream(ctx context.Context, stream test.HTTPStreamServerStream) (err error) {
+	if s.HTTPStreamFunc != nil {
+		return s.HTTPStreamFunc(ctx, adapter{stream})
+	}
+	return stream.Close()
+}
+
+func (s *Service) GrpcMethod(ctx context.Context, req *test.Fields) (res *test.Fields, err error) {
+	if s.GRPCFunc == nil {
+		return
+	}
+
+	var r *Fields
+	if req != nil {
+		r = &Fields{}
+		*r = Fields(*req)
+	}
+	var resp *Fields
+	resp, err = s.GRPCFunc(ctx, r)
+	if resp != nil {
+		res = &test.Fields{}
+		*res = test.Fields(*resp)
+	}
+	return
+}
+
+func (s *Service) GrpcStream(ctx context.Context, stream test.GrpcStreamServerStream) (err error) {
+	if s.StreamFunc != nil {
+		return s.StreamFunc(ctx, adapter{stream})
+	}
+	return nil
+}
+
+func (s *Service) GrpcServerStream(ctx context.Context, req *test.Fields, stream test.GrpcServerStreamServerStream) (err error) {
+	if s.ServerStreamFunc == nil {
+		return nil
+	}
+	return s.ServerStreamFunc(ctx, toFields(req), serverStreamSender{stream})
+}
+
+func (s *Service) GrpcClientStream(ctx context.Context, stream test.GrpcClientStreamServerStream) (err error) {
+	if s.ClientStreamFunc == nil {
+		return stream.SendAndClose(&test.Fields{})
+	}
+	return s.ClientStreamFunc(ctx, clientStreamReceiver{stream})
+}
+
+func (a adapter) Send(fields *Fields) error {
+	var f *test.Fields
+	if fields != nil {
+		f = &test.Fields{}
+		*f = test.Fields(*fields)
+	}
+	return a.stream.Send(f)
+}
+
+func (a adapter) Recv() (*Fields, error) {
+	f, err := a.stream.Recv()
+	return toFields(f), err
+}
+
+func (a adapter) Close() error {
+	return a.stream.Close()
+}
+
+func (s serverStreamSender) Send(fields *Fields) error {
+	return s.stream.Send(fromFields(fields))
+}
+
+func (s serverStreamSender) Close() error {
+	return s.stream.Close()
+}
+
+func (s serverStreamReceiver) Recv() (*Fields, error) {
+	f, err := s.stream.Recv()
+	return toFields(f), err
+}
+
+func (s clientStreamReceiver) Recv() (*Fields, error) {
+	f, err := s.stream.Recv()
+	return toFields(f), err
+}
+
+func (s clientStreamReceiver) SendAndClose(fields *Fields) error {
+	return s.stream.SendAndClose(fromFields(fields))
+}
+
+func (s clientStreamSender) Send(fields *Fields) error {
+	return s.stream.Send(fromFields(fields))
+}
+
+func (s clientStreamSender) CloseAndRecv() (*Fields, error) {
+	f, err := s.stream.CloseAndRecv()
+	return toFields(f), err
+}
+
+// toFields converts generated fields to test service fields.
+func toFields(f *test.Fields) *Fields {
+	if f == nil {
+		return nil
+	}
+	fields := &Fields{}
+	*fields = Fields(*f)
+	return fields
+}
+
+// fromFields converts test service fields to generated fields.
+func fromFields(fields *Fields) *test.Fields {
+	if fields == nil {
+		return nil
+	}
+	f := &test.Fields{}
+	*f = test.Fields(*fields)
+	return f
+}
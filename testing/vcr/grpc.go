@@ -0,0 +1,366 @@
+package vcr
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+type (
+	// GRPCRecorder records or replays gRPC interactions using client
+	// interceptors, see NewGRPCRecorder. GRPCRecorder is safe for
+	// concurrent use.
+	GRPCRecorder struct {
+		t       testing.TB
+		path    string
+		options *options
+
+		lock     sync.Mutex
+		cassette *GRPCCassette
+		used     []bool
+	}
+
+	// GRPCCassette is the content of a gRPC cassette file.
+	GRPCCassette struct {
+		// Interactions is the list of recorded calls in the order they
+		// were made.
+		Interactions []*GRPCInteraction `json:"interactions"`
+	}
+
+	// GRPCInteraction is a recorded unary or streaming gRPC call.
+	GRPCInteraction struct {
+		// Method is the full method name, e.g.
+		// "/weather.Forecaster/Forecast".
+		Method string `json:"method"`
+		// Metadata contains the redacted outgoing metadata.
+		Metadata metadata.MD `json:"metadata,omitempty"`
+		// Requests contains the proto-marshaled messages sent by the
+		// client, unary calls have exactly one request.
+		Requests [][]byte `json:"requests,omitempty"`
+		// Responses contains the proto-marshaled messages received by
+		// the client, unary calls that succeed have exactly one
+		// response.
+		Responses [][]byte `json:"responses,omitempty"`
+		// Code is the status code of the call.
+		Code codes.Code `json:"code"`
+		// Message is the status message of the call if it failed.
+		Message string `json:"message,omitempty"`
+	}
+
+	// recordStream records the messages of a client stream.
+	recordStream struct {
+		grpc.ClientStream
+		rec *GRPCRecorder
+		in  *GRPCInteraction
+	}
+
+	// replayStream is a client stream that replays a recorded interaction.
+	replayStream struct {
+		ctx  context.Context
+		rec  *GRPCRecorder
+		in   *GRPCInteraction
+		lock sync.Mutex
+		sent int
+		recv int
+	}
+)
+
+// marshalOptions makes marshaling deterministic so that requests can be
+// compared byte for byte.
+var marshalOptions = proto.MarshalOptions{Deterministic: true}
+
+// NewGRPCRecorder returns a recorder whose client interceptors record the
+// gRPC calls to the cassette file at path (ModeRecord) or replay them from it
+// (ModeReplay, the default). Messages are recorded proto-marshaled. In record
+// mode the calls are made normally and the cassette is written when the test
+// completes. In replay mode the cassette is loaded immediately and calls never
+// reach the server: unary calls are matched in order on their method and
+// request (see also WithMatchHeaders) and streaming calls on their method, the
+// messages sent on a replayed stream must match the recorded ones. Each
+// interaction is replayed at most once. Calls that do not match fail the test
+// and return codes.Unavailable. The test also fails if some interactions were
+// not replayed when it completes unless WithAllowUnused is used.
+//
+// Outgoing metadata is redacted like HTTP headers (see WithRedactedHeaders),
+// messages are not redacted.
+//
+// The interceptors should be the innermost so that the telemetry produced by
+// the other interceptors is the same during replay:
+//
+//	rec := vcr.NewGRPCRecorder(t, "testdata/forecaster.json")
+//	conn, err := grpc.Dial(addr,
+//		grpc.WithTransportCredentials(insecure.NewCredentials()),
+//		grpc.WithChainUnaryInterceptor(trace.UnaryClientInterceptor(ctx), rec.UnaryClientInterceptor()),
+//		grpc.WithChainStreamInterceptor(trace.StreamClientInterceptor(ctx), rec.StreamClientInterceptor()))
+func NewGRPCRecorder(t testing.TB, path string, opts ...Option) *GRPCRecorder {
+	t.Helper()
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(o)
+	}
+	r := &GRPCRecorder{t: t, path: path, options: o, cassette: &GRPCCassette{}}
+	switch o.mode {
+	case ModeRecord:
+		t.Cleanup(func() { save(t, path, r.Cassette()) })
+	default:
+		load(t, path, r.cassette)
+		r.used = make([]bool, len(r.cassette.Interactions))
+		if !o.allowUnused {
+			t.Cleanup(r.checkUnused)
+		}
+	}
+	return r
+}
+
+// Cassette returns a copy of the interactions recorded or loaded so far.
+func (r *GRPCRecorder) Cassette() *GRPCCassette {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return &GRPCCassette{Interactions: append([]*GRPCInteraction(nil), r.cassette.Interactions...)}
+}
+
+// UnaryClientInterceptor returns a unary client interceptor that records or
+// replays unary calls.
+func (r *GRPCRecorder) UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(
+		ctx context.Context,
+		method string,
+		req, reply interface{},
+		cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker,
+		opts ...grpc.CallOption,
+	) error {
+		reqBytes, err := marshal(req)
+		if err != nil {
+			return err
+		}
+		in := &GRPCInteraction{Method: method, Metadata: r.redactMetadata(ctx), Requests: [][]byte{reqBytes}}
+		if r.options.mode == ModeRecord {
+			err := invoker(ctx, method, req, reply, cc, opts...)
+			if err == nil {
+				b, merr := marshal(reply)
+				if merr != nil {
+					return merr
+				}
+				in.Responses = [][]byte{b}
+			}
+			st, _ := status.FromError(err)
+			in.Code, in.Message = st.Code(), st.Message()
+			r.lock.Lock()
+			r.cassette.Interactions = append(r.cassette.Interactions, in)
+			r.lock.Unlock()
+			return err
+		}
+		match := r.find(in, true)
+		if match == nil {
+			return r.noMatch(method)
+		}
+		if match.Code != codes.OK {
+			return status.Error(match.Code, match.Message)
+		}
+		if len(match.Responses) == 0 {
+			return status.Errorf(codes.Internal, "vcr: recorded call %s has no response", method)
+		}
+		return unmarshal(match.Responses[0], reply)
+	}
+}
+
+// StreamClientInterceptor returns a stream client interceptor that records or
+// replays streaming calls.
+func (r *GRPCRecorder) StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(
+		ctx context.Context,
+		desc *grpc.StreamDesc,
+		cc *grpc.ClientConn,
+		method string,
+		streamer grpc.Streamer,
+		opts ...grpc.CallOption,
+	) (grpc.ClientStream, error) {
+		in := &GRPCInteraction{Method: method, Metadata: r.redactMetadata(ctx)}
+		if r.options.mode == ModeRecord {
+			stream, err := streamer(ctx, desc, cc, method, opts...)
+			r.lock.Lock()
+			r.cassette.Interactions = append(r.cassette.Interactions, in)
+			r.lock.Unlock()
+			if err != nil {
+				st, _ := status.FromError(err)
+				r.lock.Lock()
+				in.Code, in.Message = st.Code(), st.Message()
+				r.lock.Unlock()
+				return nil, err
+			}
+			return &recordStream{ClientStream: stream, rec: r, in: in}, nil
+		}
+		match := r.find(in, false)
+		if match == nil {
+			return nil, r.noMatch(method)
+		}
+		return &replayStream{ctx: ctx, rec: r, in: match}, nil
+	}
+}
+
+// find returns the first unused interaction matching in and marks it as used.
+// The requests are compared only if matchRequests is true.
+func (r *GRPCRecorder) find(in *GRPCInteraction, matchRequests bool) *GRPCInteraction {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	for i, rec := range r.cassette.Interactions {
+		if r.used[i] || rec.Method != in.Method {
+			continue
+		}
+		if matchRequests && (len(rec.Requests) != 1 || !bytes.Equal(rec.Requests[0], in.Requests[0])) {
+			continue
+		}
+		if !r.matchesMetadata(in.Metadata, rec.Metadata) {
+			continue
+		}
+		r.used[i] = true
+		return rec
+	}
+	return nil
+}
+
+// matchesMetadata returns true if the metadata keys configured via
+// WithMatchHeaders have the same values in md and recorded.
+func (r *GRPCRecorder) matchesMetadata(md, recorded metadata.MD) bool {
+	for _, h := range r.options.matchHeaders {
+		k := strings.ToLower(h)
+		if fmt.Sprint(md.Get(k)) != fmt.Sprint(recorded.Get(k)) {
+			return false
+		}
+	}
+	return true
+}
+
+// noMatch fails the test and returns the error returned to the client when
+// no recorded interaction matches.
+func (r *GRPCRecorder) noMatch(method string) error {
+	err := fmt.Errorf("vcr: no recorded interaction matches %s in %q", method, r.path)
+	r.t.Error(err)
+	return status.Error(codes.Unavailable, err.Error())
+}
+
+// redactMetadata returns a redacted copy of the outgoing metadata of ctx.
+func (r *GRPCRecorder) redactMetadata(ctx context.Context) metadata.MD {
+	md, ok := metadata.FromOutgoingContext(ctx)
+	if !ok || md.Len() == 0 {
+		return nil
+	}
+	res := md.Copy()
+	for k, vals := range res {
+		if r.options.isRedacted(k) {
+			for i := range vals {
+				vals[i] = Redacted
+			}
+		}
+	}
+	return res
+}
+
+// checkUnused fails the test if some of the recorded interactions were not
+// replayed.
+func (r *GRPCRecorder) checkUnused() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	for i, used := range r.used {
+		if !used {
+			r.t.Errorf("vcr: recorded call %s in %q was not replayed", r.cassette.Interactions[i].Method, r.path)
+		}
+	}
+}
+
+func (s *recordStream) SendMsg(m interface{}) error {
+	b, err := marshal(m)
+	if err != nil {
+		return err
+	}
+	if err := s.ClientStream.SendMsg(m); err != nil {
+		return err
+	}
+	s.rec.lock.Lock()
+	s.in.Requests = append(s.in.Requests, b)
+	s.rec.lock.Unlock()
+	return nil
+}
+
+func (s *recordStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+	s.rec.lock.Lock()
+	defer s.rec.lock.Unlock()
+	if err != nil {
+		if !errors.Is(err, io.EOF) {
+			st, _ := status.FromError(err)
+			s.in.Code, s.in.Message = st.Code(), st.Message()
+		}
+		return err
+	}
+	b, merr := marshal(m)
+	if merr != nil {
+		return merr
+	}
+	s.in.Responses = append(s.in.Responses, b)
+	return nil
+}
+
+func (s *replayStream) SendMsg(m interface{}) error {
+	b, err := marshal(m)
+	if err != nil {
+		return err
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.sent >= len(s.in.Requests) || !bytes.Equal(s.in.Requests[s.sent], b) {
+		err := fmt.Errorf("vcr: message %d sent on %s does not match the recording in %q", s.sent, s.in.Method, s.rec.path)
+		s.rec.t.Error(err)
+		return status.Error(codes.Unavailable, err.Error())
+	}
+	s.sent++
+	return nil
+}
+
+func (s *replayStream) RecvMsg(m interface{}) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.recv < len(s.in.Responses) {
+		s.recv++
+		return unmarshal(s.in.Responses[s.recv-1], m)
+	}
+	if s.in.Code != codes.OK {
+		return status.Error(s.in.Code, s.in.Message)
+	}
+	return io.EOF
+}
+
+func (s *replayStream) Header() (metadata.MD, error) { return metadata.MD{}, nil }
+func (s *replayStream) Trailer() metadata.MD         { return metadata.MD{} }
+func (s *replayStream) CloseSend() error             { return nil }
+func (s *replayStream) Context() context.Context     { return s.ctx }
+
+// marshal marshals the proto message m deterministically.
+func marshal(m interface{}) ([]byte, error) {
+	msg, ok := m.(proto.Message)
+	if !ok {
+		return nil, status.Errorf(codes.Internal, "vcr: %T is not a proto message", m)
+	}
+	return marshalOptions.Marshal(msg)
+}
+
+// unmarshal unmarshals b into the proto message m.
+func unmarshal(b []byte, m interface{}) error {
+	msg, ok := m.(proto.Message)
+	if !ok {
+		return status.Errorf(codes.Internal, "vcr: %T is not a proto message", m)
+	}
+	return proto.Unmarshal(b, msg)
+}
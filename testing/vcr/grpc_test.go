@@ -0,0 +1,189 @@
+package vcr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"goa.design/clue/testing/testsvc"
+	testpb "goa.design/clue/testing/testsvc/gen/grpc/test/pb"
+	"goa.design/clue/trace"
+	"goa.design/clue/trace/tracetest"
+)
+
+func TestGRPCRecorderRecordReplay(t *testing.T) {
+	var calls int
+	unary := func(_ context.Context, f *testsvc.Fields) (*testsvc.Fields, error) {
+		calls++
+		if f.S != nil && *f.S == "missing" {
+			return nil, status.Error(codes.NotFound, "not found")
+		}
+		s := "echo " + *f.S
+		return &testsvc.Fields{S: &s}, nil
+	}
+	stream := func(ctx context.Context, s testsvc.Stream) error {
+		calls++
+		return testsvc.Script(testsvc.ScriptEcho(-1))(ctx, s)
+	}
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	var recorded []string
+
+	t.Run("record", func(t *testing.T) {
+		rec := NewGRPCRecorder(t, path, WithMode(ModeRecord))
+		cli, stop := testsvc.SetupGRPC(t,
+			testsvc.WithUnaryFunc(unary),
+			testsvc.WithStreamFunc(stream),
+			testsvc.WithDialOptions(
+				grpc.WithUnaryInterceptor(rec.UnaryClientInterceptor()),
+				grpc.WithStreamInterceptor(rec.StreamClientInterceptor())))
+		defer stop()
+		recorded = doCalls(t, cli)
+		if calls != 3 {
+			t.Errorf("got %d calls, want 3", calls)
+		}
+	})
+
+	var cassette GRPCCassette
+	load(t, path, &cassette)
+	if len(cassette.Interactions) != 3 {
+		t.Fatalf("got %d interactions, want 3", len(cassette.Interactions))
+	}
+	if got := cassette.Interactions[0].Metadata.Get("authorization"); len(got) != 1 || got[0] != Redacted {
+		t.Errorf("got authorization metadata %v, want %q", got, Redacted)
+	}
+	if got := cassette.Interactions[1].Code; got == codes.OK {
+		t.Errorf("expected failed call to record its status code")
+	}
+	if got := cassette.Interactions[2]; len(got.Requests) != 2 || len(got.Responses) != 2 {
+		t.Errorf("got %d requests and %d responses, want 2 and 2", len(got.Requests), len(got.Responses))
+	}
+
+	t.Run("replay", func(t *testing.T) {
+		ctx, spans := tracetest.Context(t, context.Background(), "svc")
+		rec := NewGRPCRecorder(t, path)
+		cli, stop := testsvc.SetupGRPC(t,
+			testsvc.WithUnaryFunc(unary),
+			testsvc.WithStreamFunc(stream),
+			testsvc.WithDialOptions(
+				grpc.WithChainUnaryInterceptor(trace.UnaryClientInterceptor(ctx), rec.UnaryClientInterceptor()),
+				grpc.WithChainStreamInterceptor(trace.StreamClientInterceptor(ctx), rec.StreamClientInterceptor())))
+		defer stop()
+		replayed := doCalls(t, cli)
+		if calls != 3 {
+			t.Errorf("got %d calls, want 3", calls)
+		}
+		if fmt.Sprint(replayed) != fmt.Sprint(recorded) {
+			t.Errorf("got replayed results %v, want %v", replayed, recorded)
+		}
+		spans.Spans().AssertCount(t, 3)
+	})
+}
+
+func TestGRPCRecorderReplayMismatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	req, _ := marshal(&testpb.GrpcMethodRequest{S: proto.String("a")})
+	save(t, path, &GRPCCassette{Interactions: []*GRPCInteraction{
+		{Method: "/test.Test/GrpcMethod", Requests: [][]byte{req}, Responses: [][]byte{req}},
+		{Method: "/test.Test/GrpcStream", Requests: [][]byte{req}},
+	}})
+	invoker := func(context.Context, string, interface{}, interface{}, *grpc.ClientConn, ...grpc.CallOption) error {
+		t.Fatal("unexpected call to invoker")
+		return nil
+	}
+
+	t.Run("unary", func(t *testing.T) {
+		ft := &fakeTB{TB: t}
+		rec := NewGRPCRecorder(ft, path, WithAllowUnused())
+		unary := rec.UnaryClientInterceptor()
+		if err := unary(context.Background(), "/test.Test/GrpcMethod", &testpb.GrpcMethodRequest{S: proto.String("b")}, &testpb.GrpcMethodResponse{}, nil, invoker); status.Code(err) != codes.Unavailable {
+			t.Errorf("got error %v, want unavailable", err)
+		}
+		var reply testpb.GrpcMethodResponse
+		if err := unary(context.Background(), "/test.Test/GrpcMethod", &testpb.GrpcMethodRequest{S: proto.String("a")}, &reply, nil, invoker); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if reply.GetS() != "a" {
+			t.Errorf("got reply %q, want a", reply.GetS())
+		}
+		if len(ft.errors) != 1 {
+			t.Errorf("got %d test errors, want 1", len(ft.errors))
+		}
+	})
+
+	t.Run("stream", func(t *testing.T) {
+		ft := &fakeTB{TB: t}
+		rec := NewGRPCRecorder(ft, path, WithAllowUnused())
+		s, err := rec.StreamClientInterceptor()(context.Background(), &grpc.StreamDesc{}, nil, "/test.Test/GrpcStream", nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := s.SendMsg(&testpb.GrpcStreamStreamingRequest{S: proto.String("b")}); err == nil {
+			t.Errorf("expected error")
+		}
+		if err := s.RecvMsg(&testpb.GrpcStreamResponse{}); !errors.Is(err, io.EOF) {
+			t.Errorf("got error %v, want EOF", err)
+		}
+		if len(ft.errors) != 1 {
+			t.Errorf("got %d test errors, want 1", len(ft.errors))
+		}
+	})
+}
+
+func TestGRPCRecorderUnused(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	save(t, path, &GRPCCassette{Interactions: []*GRPCInteraction{{Method: "/test.Test/GrpcMethod"}}})
+	ft := &fakeTB{TB: t}
+	rec := NewGRPCRecorder(ft, path)
+	rec.checkUnused()
+	if len(ft.errors) != 1 {
+		t.Errorf("got %d test errors, want 1", len(ft.errors))
+	}
+}
+
+// doCalls makes the calls used by the record/replay test and returns their
+// results.
+func doCalls(t *testing.T, cli testsvc.GRPClient) []string {
+	t.Helper()
+	var res []string
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer secret")
+	for _, s := range []string{"hello", "missing"} {
+		s := s
+		f, err := cli.GRPCMethod(ctx, &testsvc.Fields{S: &s})
+		if err != nil {
+			res = append(res, err.Error())
+			continue
+		}
+		res = append(res, *f.S)
+	}
+	stream, err := cli.GRPCStream(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, s := range []string{"a", "b"} {
+		s := s
+		if err := stream.Send(&testsvc.Fields{S: &s}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		f, err := stream.Recv()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		res = append(res, *f.S)
+	}
+	if err := stream.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := stream.Recv(); !errors.Is(err, io.EOF) {
+		t.Fatalf("got error %v, want EOF", err)
+	}
+	return res
+}
@@ -0,0 +1,269 @@
+package vcr
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"testing"
+	"unicode/utf8"
+)
+
+type (
+	// Transport is a http.RoundTripper that records or replays HTTP
+	// interactions, see NewTransport. Transport is safe for concurrent use.
+	Transport struct {
+		t       testing.TB
+		path    string
+		options *options
+		next    http.RoundTripper
+
+		lock     sync.Mutex
+		cassette *HTTPCassette
+		used     []bool
+	}
+
+	// HTTPCassette is the content of an HTTP cassette file.
+	HTTPCassette struct {
+		// Interactions is the list of recorded interactions in the
+		// order they occurred.
+		Interactions []*HTTPInteraction `json:"interactions"`
+	}
+
+	// HTTPInteraction is a recorded HTTP request and its response.
+	HTTPInteraction struct {
+		// Request is the recorded request.
+		Request *HTTPRequest `json:"request"`
+		// Response is the recorded response.
+		Response *HTTPResponse `json:"response"`
+	}
+
+	// HTTPRequest is a recorded HTTP request.
+	HTTPRequest struct {
+		// Method is the request method.
+		Method string `json:"method"`
+		// URL is the request URL.
+		URL string `json:"url"`
+		// Header contains the redacted request headers.
+		Header http.Header `json:"header,omitempty"`
+		// Body is the redacted request body if it is valid UTF-8.
+		Body string `json:"body,omitempty"`
+		// BodyBase64 is the base64 encoded request body if it is not
+		// valid UTF-8.
+		BodyBase64 string `json:"body_base64,omitempty"`
+	}
+
+	// HTTPResponse is a recorded HTTP response.
+	HTTPResponse struct {
+		// StatusCode is the response status code.
+		StatusCode int `json:"status_code"`
+		// Header contains the redacted response headers.
+		Header http.Header `json:"header,omitempty"`
+		// Body is the redacted response body if it is valid UTF-8.
+		Body string `json:"body,omitempty"`
+		// BodyBase64 is the base64 encoded response body if it is not
+		// valid UTF-8.
+		BodyBase64 string `json:"body_base64,omitempty"`
+	}
+)
+
+// NewTransport returns a roundtripper that records the HTTP interactions to
+// the cassette file at path (ModeRecord) or replays them from it (ModeReplay,
+// the default). In record mode the requests are sent using the roundtripper
+// given via WithTransport (http.DefaultTransport by default) and the cassette
+// is written when the test completes. In replay mode the cassette is loaded
+// immediately and requests are matched in order against the recorded
+// interactions on their method, URL and redacted body (see also
+// WithMatchHeaders); each interaction is replayed at most once. Requests that
+// do not match fail the test and return an error. The test also fails if
+// some interactions were not replayed when it completes unless
+// WithAllowUnused is used.
+//
+// The transport should be wrapped by the instrumented clients so that the
+// telemetry (spans, logs and metrics) is produced during replay exactly as it
+// is when calling the real dependency:
+//
+//	rt := vcr.NewTransport(t, "testdata/weather.json")
+//	c := &http.Client{Transport: trace.Client(ctx, log.Client(rt))}
+func NewTransport(t testing.TB, path string, opts ...Option) *Transport {
+	t.Helper()
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(o)
+	}
+	tr := &Transport{t: t, path: path, options: o, next: o.transport, cassette: &HTTPCassette{}}
+	if tr.next == nil {
+		tr.next = http.DefaultTransport
+	}
+	switch o.mode {
+	case ModeRecord:
+		t.Cleanup(func() { save(t, path, tr.Cassette()) })
+	default:
+		load(t, path, tr.cassette)
+		tr.used = make([]bool, len(tr.cassette.Interactions))
+		if !o.allowUnused {
+			t.Cleanup(tr.checkUnused)
+		}
+	}
+	return tr
+}
+
+// WithTransport sets the roundtripper used to send the requests in record
+// mode, http.DefaultTransport by default.
+func WithTransport(rt http.RoundTripper) Option {
+	return func(o *options) {
+		o.transport = rt
+	}
+}
+
+// Cassette returns a copy of the interactions recorded or loaded so far.
+func (tr *Transport) Cassette() *HTTPCassette {
+	tr.lock.Lock()
+	defer tr.lock.Unlock()
+	return &HTTPCassette{Interactions: append([]*HTTPInteraction(nil), tr.cassette.Interactions...)}
+}
+
+// RoundTrip records or replays req.
+func (tr *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		b, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		body = b
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	recorded := tr.recordRequest(req, body)
+	if tr.options.mode == ModeRecord {
+		return tr.record(req, recorded)
+	}
+	return tr.replay(req, recorded)
+}
+
+// record sends req and records the interaction.
+func (tr *Transport) record(req *http.Request, recorded *HTTPRequest) (*http.Response, error) {
+	resp, err := tr.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	r := &HTTPResponse{StatusCode: resp.StatusCode, Header: tr.redactHeader(resp.Header)}
+	r.Body, r.BodyBase64 = encodeBody(tr.options.redactBody(resp.Header.Get("Content-Type"), body))
+	tr.lock.Lock()
+	defer tr.lock.Unlock()
+	tr.cassette.Interactions = append(tr.cassette.Interactions, &HTTPInteraction{Request: recorded, Response: r})
+	return resp, nil
+}
+
+// replay returns the response of the first unused interaction matching req.
+func (tr *Transport) replay(req *http.Request, recorded *HTTPRequest) (*http.Response, error) {
+	tr.lock.Lock()
+	var match *HTTPInteraction
+	for i, in := range tr.cassette.Interactions {
+		if !tr.used[i] && tr.matches(recorded, in.Request) {
+			tr.used[i] = true
+			match = in
+			break
+		}
+	}
+	tr.lock.Unlock()
+	if match == nil {
+		err := fmt.Errorf("vcr: no recorded interaction matches %s %s in %q", req.Method, req.URL, tr.path)
+		tr.t.Error(err)
+		return nil, err
+	}
+	body, err := decodeBody(match.Response.Body, match.Response.BodyBase64)
+	if err != nil {
+		return nil, err
+	}
+	header := match.Response.Header.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", match.Response.StatusCode, http.StatusText(match.Response.StatusCode)),
+		StatusCode:    match.Response.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// recordRequest returns the redacted recording of req.
+func (tr *Transport) recordRequest(req *http.Request, body []byte) *HTTPRequest {
+	r := &HTTPRequest{Method: req.Method, URL: req.URL.String(), Header: tr.redactHeader(req.Header)}
+	r.Body, r.BodyBase64 = encodeBody(tr.options.redactBody(req.Header.Get("Content-Type"), body))
+	return r
+}
+
+// matches returns true if the request r matches the recorded request.
+func (tr *Transport) matches(r, recorded *HTTPRequest) bool {
+	if r.Method != recorded.Method || r.URL != recorded.URL || r.Body != recorded.Body || r.BodyBase64 != recorded.BodyBase64 {
+		return false
+	}
+	for _, h := range tr.options.matchHeaders {
+		if fmt.Sprint(r.Header.Values(h)) != fmt.Sprint(recorded.Header.Values(h)) {
+			return false
+		}
+	}
+	return true
+}
+
+// redactHeader returns a copy of h with the redacted header values replaced.
+func (tr *Transport) redactHeader(h http.Header) http.Header {
+	if len(h) == 0 {
+		return nil
+	}
+	res := h.Clone()
+	for k, vals := range res {
+		if tr.options.isRedacted(k) {
+			for i := range vals {
+				vals[i] = Redacted
+			}
+		}
+	}
+	return res
+}
+
+// checkUnused fails the test if some of the recorded interactions were not
+// replayed.
+func (tr *Transport) checkUnused() {
+	tr.lock.Lock()
+	defer tr.lock.Unlock()
+	for i, used := range tr.used {
+		if !used {
+			r := tr.cassette.Interactions[i].Request
+			tr.t.Errorf("vcr: recorded interaction %s %s in %q was not replayed", r.Method, r.URL, tr.path)
+		}
+	}
+}
+
+// encodeBody returns the body as a string if it is valid UTF-8 and base64
+// encoded otherwise.
+func encodeBody(body []byte) (string, string) {
+	if utf8.Valid(body) {
+		return string(body), ""
+	}
+	return "", base64.StdEncoding.EncodeToString(body)
+}
+
+// decodeBody returns the body encoded with encodeBody.
+func decodeBody(body, b64 string) ([]byte, error) {
+	if b64 != "" {
+		return base64.StdEncoding.DecodeString(b64)
+	}
+	return []byte(body), nil
+}
@@ -0,0 +1,186 @@
+package vcr
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"goa.design/clue/trace"
+	"goa.design/clue/trace/tracetest"
+)
+
+func TestTransportRecordReplay(t *testing.T) {
+	var calls int
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		switch r.URL.Path {
+		case "/json":
+			body, _ := io.ReadAll(r.Body)
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Set-Cookie", "session=secret")
+			w.Write([]byte(`{"token":"abc","echo":` + string(body) + `}`))
+		case "/binary":
+			w.Header().Set("Content-Type", "application/octet-stream")
+			w.Write([]byte{0xff, 0xfe, 0x00})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer svr.Close()
+	path := filepath.Join(t.TempDir(), "cassette.json")
+
+	t.Run("record", func(t *testing.T) {
+		rt := NewTransport(t, path, WithMode(ModeRecord))
+		c := &http.Client{Transport: rt}
+		doRequests(t, c, svr.URL)
+		if calls != 3 {
+			t.Errorf("got %d calls, want 3", calls)
+		}
+	})
+
+	var cassette HTTPCassette
+	load(t, path, &cassette)
+	if len(cassette.Interactions) != 3 {
+		t.Fatalf("got %d interactions, want 3", len(cassette.Interactions))
+	}
+	first := cassette.Interactions[0]
+	if got := first.Request.Header.Get("Authorization"); got != Redacted {
+		t.Errorf("got authorization header %q, want %q", got, Redacted)
+	}
+	if got := first.Response.Header.Get("Set-Cookie"); got != Redacted {
+		t.Errorf("got set-cookie header %q, want %q", got, Redacted)
+	}
+	if strings.Contains(first.Request.Body, "hunter2") || strings.Contains(first.Response.Body, "abc") {
+		t.Errorf("expected bodies to be redacted, got %q and %q", first.Request.Body, first.Response.Body)
+	}
+	if cassette.Interactions[1].Response.BodyBase64 == "" {
+		t.Errorf("expected binary body to be base64 encoded")
+	}
+
+	t.Run("replay", func(t *testing.T) {
+		svr.Close()
+		ctx, rec := tracetest.Context(t, context.Background(), "svc")
+		rt := NewTransport(t, path)
+		c := &http.Client{Transport: trace.Client(ctx, rt)}
+		resps := doRequests(t, c, svr.URL)
+		if calls != 3 {
+			t.Errorf("got %d calls, want 3", calls)
+		}
+		var res map[string]interface{}
+		if err := json.Unmarshal(resps[0], &res); err != nil {
+			t.Fatalf("invalid replayed body %q: %v", resps[0], err)
+		}
+		if res["token"] != Redacted {
+			t.Errorf("got token %v, want %q", res["token"], Redacted)
+		}
+		if !bytes.Equal(resps[1], []byte{0xff, 0xfe, 0x00}) {
+			t.Errorf("got binary body %v", resps[1])
+		}
+		rec.Spans().AssertCount(t, 3)
+	})
+}
+
+func TestTransportReplayMismatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	save(t, path, &HTTPCassette{Interactions: []*HTTPInteraction{{
+		Request:  &HTTPRequest{Method: "GET", URL: "http://example.com/a", Header: http.Header{"X-Tenant": {"acme"}}},
+		Response: &HTTPResponse{StatusCode: http.StatusOK, Body: "a"},
+	}}})
+	cases := []struct {
+		name    string
+		url     string
+		tenant  string
+		matched bool
+	}{
+		{"match", "http://example.com/a", "acme", true},
+		{"url", "http://example.com/b", "acme", false},
+		{"header", "http://example.com/a", "other", false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ft := &fakeTB{TB: t}
+			rt := NewTransport(ft, path, WithMatchHeaders("X-Tenant"), WithAllowUnused())
+			req, _ := http.NewRequest("GET", c.url, nil)
+			req.Header.Set("X-Tenant", c.tenant)
+			resp, err := rt.RoundTrip(req)
+			if c.matched {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if resp.StatusCode != http.StatusOK {
+					t.Errorf("got status %d, want 200", resp.StatusCode)
+				}
+				if _, err := rt.RoundTrip(req); err == nil {
+					t.Errorf("expected interactions to be replayed only once")
+				}
+				return
+			}
+			if err == nil {
+				t.Errorf("expected error")
+			}
+			if len(ft.errors) != 1 {
+				t.Errorf("got %d test errors, want 1", len(ft.errors))
+			}
+		})
+	}
+}
+
+func TestTransportUnused(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	save(t, path, &HTTPCassette{Interactions: []*HTTPInteraction{{
+		Request:  &HTTPRequest{Method: "GET", URL: "http://example.com/a"},
+		Response: &HTTPResponse{StatusCode: http.StatusOK},
+	}}})
+	ft := &fakeTB{TB: t}
+	rt := NewTransport(ft, path)
+	rt.checkUnused()
+	if len(ft.errors) != 1 {
+		t.Errorf("got %d test errors, want 1", len(ft.errors))
+	}
+}
+
+// doRequests sends the requests used by the record/replay test and returns
+// the response bodies.
+func doRequests(t *testing.T, c *http.Client, url string) [][]byte {
+	t.Helper()
+	var bodies [][]byte
+	send := func(req *http.Request) {
+		resp, err := c.Do(req)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		defer resp.Body.Close()
+		b, _ := io.ReadAll(resp.Body)
+		bodies = append(bodies, b)
+	}
+	req, _ := http.NewRequest("POST", url+"/json", strings.NewReader(`{"password":"hunter2"}`))
+	req.Header.Set("Authorization", "Bearer secret")
+	req.Header.Set("Content-Type", "application/json")
+	send(req)
+	req, _ = http.NewRequest("GET", url+"/binary", nil)
+	send(req)
+	req, _ = http.NewRequest("GET", url+"/missing", nil)
+	send(req)
+	return bodies
+}
+
+// fakeTB records test errors.
+type fakeTB struct {
+	testing.TB
+	errors []string
+}
+
+func (f *fakeTB) Error(args ...interface{}) {
+	f.errors = append(f.errors, fmt.Sprint(args...))
+}
+
+func (f *fakeTB) Errorf(format string, args ...interface{}) {
+	f.errors = append(f.errors, fmt.Sprintf(format, args...))
+}
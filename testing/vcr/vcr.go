@@ -0,0 +1,249 @@
+// Package vcr provides helpers that record the interactions of a client with
+// its downstream dependencies to files ("cassettes") and replay them
+// deterministically in tests. NewTransport records HTTP requests and
+// NewGRPCRecorder gRPC calls. Recorded headers and bodies are redacted so that
+// cassettes can be committed.
+//
+// Example:
+//
+//	func TestClient(t *testing.T) {
+//		mode := vcr.ModeReplay
+//		if *update {
+//			mode = vcr.ModeRecord
+//		}
+//		rt := vcr.NewTransport(t, "testdata/weather.json", vcr.WithMode(mode))
+//		c := &http.Client{Transport: trace.Client(ctx, log.Client(rt))}
+//		...
+//	}
+package vcr
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type (
+	// Mode is the mode of a recorder.
+	Mode int
+
+	// Option is a function that configures a recorder.
+	Option func(*options)
+
+	// BodyRedactor returns a redacted copy of a request or response body.
+	// contentType is the value of the Content-Type header if any.
+	BodyRedactor func(contentType string, body []byte) []byte
+
+	options struct {
+		mode         Mode
+		headers      []string
+		redactors    []BodyRedactor
+		matchHeaders []string
+		allowUnused  bool
+		transport    http.RoundTripper
+	}
+)
+
+const (
+	// ModeReplay replays the interactions recorded in the cassette and
+	// fails requests that do not match any of them.
+	ModeReplay Mode = iota
+	// ModeRecord performs the requests and records the interactions in the
+	// cassette, the cassette file is written when the test completes.
+	ModeRecord
+)
+
+// Redacted is the value that replaces redacted header values and fields.
+const Redacted = "[REDACTED]"
+
+var (
+	// DefaultRedactedHeaders is the default list of headers whose values
+	// are redacted, see WithRedactedHeaders.
+	DefaultRedactedHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie", "X-Api-Key"}
+
+	// DefaultRedactedFields is the default list of JSON body field name
+	// patterns whose values are redacted, see RedactJSONFields.
+	DefaultRedactedFields = []string{"password", "passwd", "secret", "token", "apikey", "api_key", "private", "credential"}
+)
+
+// String returns the name of the mode.
+func (m Mode) String() string {
+	switch m {
+	case ModeReplay:
+		return "replay"
+	case ModeRecord:
+		return "record"
+	default:
+		return fmt.Sprintf("Mode(%d)", int(m))
+	}
+}
+
+// WithMode sets the recorder mode, ModeReplay by default.
+func WithMode(m Mode) Option {
+	return func(o *options) {
+		o.mode = m
+	}
+}
+
+// WithRedactedHeaders adds headers whose values are replaced with Redacted in
+// the cassette. The headers listed in DefaultRedactedHeaders are always
+// redacted.
+func WithRedactedHeaders(headers ...string) Option {
+	return func(o *options) {
+		o.headers = append(o.headers, headers...)
+	}
+}
+
+// WithBodyRedactor adds a function used to redact the request and response
+// bodies before they are written to the cassette. Request bodies are also
+// redacted before being matched against the recorded requests so that
+// replaying works with redacted cassettes. By default the JSON fields matching
+// DefaultRedactedFields are redacted, see RedactJSONFields.
+func WithBodyRedactor(r BodyRedactor) Option {
+	return func(o *options) {
+		o.redactors = append(o.redactors, r)
+	}
+}
+
+// WithMatchHeaders adds headers whose values must be identical for a request
+// to match a recorded request. By default requests are matched on their
+// method, URL and redacted body only so that headers that change on each
+// request such as the trace context do not prevent replaying.
+func WithMatchHeaders(headers ...string) Option {
+	return func(o *options) {
+		o.matchHeaders = append(o.matchHeaders, headers...)
+	}
+}
+
+// WithAllowUnused disables the check that fails the test if some of the
+// recorded interactions were not replayed.
+func WithAllowUnused() Option {
+	return func(o *options) {
+		o.allowUnused = true
+	}
+}
+
+// RedactJSONFields returns a BodyRedactor that replaces the values of the
+// fields of JSON bodies whose names contain one of the given patterns (case
+// insensitive) with Redacted. Fields are redacted at any depth. Bodies that
+// are not JSON are returned unchanged.
+func RedactJSONFields(patterns ...string) BodyRedactor {
+	lower := make([]string, len(patterns))
+	for i, p := range patterns {
+		lower[i] = strings.ToLower(p)
+	}
+	return func(contentType string, body []byte) []byte {
+		if contentType != "" && !strings.Contains(contentType, "json") {
+			return body
+		}
+		var v interface{}
+		if err := json.Unmarshal(body, &v); err != nil {
+			return body
+		}
+		if !redactJSON(v, lower) {
+			return body
+		}
+		js, err := json.Marshal(v)
+		if err != nil {
+			return body
+		}
+		return js
+	}
+}
+
+// defaultOptions returns the default recorder options.
+func defaultOptions() *options {
+	return &options{
+		headers:   append([]string(nil), DefaultRedactedHeaders...),
+		redactors: []BodyRedactor{RedactJSONFields(DefaultRedactedFields...)},
+	}
+}
+
+// redactBody applies the body redactors to body.
+func (o *options) redactBody(contentType string, body []byte) []byte {
+	if len(body) == 0 {
+		return body
+	}
+	for _, r := range o.redactors {
+		body = r(contentType, body)
+	}
+	return body
+}
+
+// isRedacted returns true if the header with the given name must be
+// redacted.
+func (o *options) isRedacted(name string) bool {
+	for _, h := range o.headers {
+		if strings.EqualFold(h, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactJSON redacts the fields of v whose names match one of the patterns
+// and returns true if any field was redacted.
+func redactJSON(v interface{}, patterns []string) bool {
+	redacted := false
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for k, e := range val {
+			if matches(k, patterns) && e != nil {
+				val[k] = Redacted
+				redacted = true
+				continue
+			}
+			redacted = redactJSON(e, patterns) || redacted
+		}
+	case []interface{}:
+		for _, e := range val {
+			redacted = redactJSON(e, patterns) || redacted
+		}
+	}
+	return redacted
+}
+
+// matches returns true if key contains one of the patterns.
+func matches(key string, patterns []string) bool {
+	key = strings.ToLower(key)
+	for _, p := range patterns {
+		if strings.Contains(key, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// load reads the cassette at path into v.
+func load(t testing.TB, path string, v interface{}) {
+	t.Helper()
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("vcr: failed to read cassette, record it with vcr.ModeRecord: %v", err)
+	}
+	if err := json.Unmarshal(b, v); err != nil {
+		t.Fatalf("vcr: invalid cassette %q: %v", path, err)
+	}
+}
+
+// save writes v to the cassette at path, creating the parent directories if
+// needed.
+func save(t testing.TB, path string, v interface{}) {
+	t.Helper()
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		t.Errorf("vcr: failed to encode cassette: %v", err)
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Errorf("vcr: failed to create cassette directory: %v", err)
+		return
+	}
+	if err := os.WriteFile(path, append(b, '\n'), 0644); err != nil {
+		t.Errorf("vcr: failed to write cassette: %v", err)
+	}
+}
@@ -0,0 +1,172 @@
+package trace
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type (
+	// KafkaBatchHandler handles batches of messages consumed from Kafka.
+	KafkaBatchHandler func(ctx context.Context, msgs []*KafkaMessage) error
+
+	// MessageBatchHandler handles batches of messages consumed from GCP
+	// Pub/Sub or AWS SQS.
+	MessageBatchHandler func(ctx context.Context, msgs []*Message) error
+)
+
+// BatchLinks returns links to the span contexts stored in the given carriers
+// (e.g. message headers or attributes) using the propagators configured with
+// Context. Carriers that do not contain a valid span context are skipped.
+// BatchLinks makes it possible to create a single span for the processing of
+// a batch of messages that is linked to the span that produced each message,
+// rather than picking one of them as parent which would skew latencies. It
+// returns nil if ctx has not been initialized with Context.
+//
+// Example:
+//
+//	carriers := make([]propagation.TextMapCarrier, len(msgs))
+//	for i, msg := range msgs {
+//		carriers[i] = propagation.MapCarrier(msg.Attributes)
+//	}
+//	ctx, span := tracer.Start(ctx, "batch", trace.WithLinks(trace.BatchLinks(ctx, carriers...)...))
+func BatchLinks(ctx context.Context, carriers ...propagation.TextMapCarrier) []trace.Link {
+	s := ctx.Value(stateKey)
+	if s == nil {
+		return nil
+	}
+	propagator := s.(*stateBag).propagator
+	var links []trace.Link
+	for _, c := range carriers {
+		sc := trace.SpanContextFromContext(propagator.Extract(context.Background(), c))
+		if sc.IsValid() {
+			links = append(links, trace.Link{SpanContext: sc})
+		}
+	}
+	return links
+}
+
+// WrapKafkaBatchHandler returns a handler that creates a single consumer span
+// for each batch of messages before calling h. The span is linked to the
+// producer span of each message whose trace context is stored in the message
+// headers (see InjectKafka). The span is named after the topic ("orders
+// process") when all the messages share the same topic, "batch process"
+// otherwise, and records the number of messages in the
+// "messaging.batch.message_count" attribute. The context given to h is
+// initialized for tracing. WrapKafkaBatchHandler panics if traceCtx hasn't been
+// initialized with Context.
+func WrapKafkaBatchHandler(traceCtx context.Context, h KafkaBatchHandler) KafkaBatchHandler {
+	s := traceCtx.Value(stateKey)
+	if s == nil {
+		panic(errContextMissing)
+	}
+	tracer := s.(*stateBag).provider.Tracer(InstrumentationLibraryName)
+	return func(ctx context.Context, msgs []*KafkaMessage) error {
+		if len(msgs) == 0 {
+			return h(ctx, msgs)
+		}
+		sources := make([]string, len(msgs))
+		carriers := make([]propagation.TextMapCarrier, len(msgs))
+		for i, msg := range msgs {
+			sources[i] = msg.Topic
+			carriers[i] = &msg.Headers
+		}
+		attrs := []attribute.KeyValue{semconv.MessagingSystem(kafkaSystem), semconv.MessagingSourceKindTopic}
+		ctx, span := startBatchSpan(traceCtx, ctx, tracer, sources, carriers, attrs)
+		defer span.End()
+		return endBatchSpan(span, h(ctx, msgs))
+	}
+}
+
+// WrapPubSubBatchHandler returns a handler that creates a single consumer span
+// for each batch of GCP Pub/Sub messages before calling h. The span is linked
+// to the span that published each message whose trace context is stored in
+// the message attributes (see InjectPubSub), see WrapKafkaBatchHandler.
+// WrapPubSubBatchHandler panics if traceCtx hasn't been initialized with
+// Context.
+func WrapPubSubBatchHandler(traceCtx context.Context, h MessageBatchHandler) MessageBatchHandler {
+	return wrapMessageBatchHandler(traceCtx, pubSubSystem, semconv.MessagingSourceKindTopic, h)
+}
+
+// WrapSQSBatchHandler returns a handler that creates a single consumer span
+// for each batch of AWS SQS messages before calling h, see
+// WrapPubSubBatchHandler. WrapSQSBatchHandler panics if traceCtx hasn't been
+// initialized with Context.
+func WrapSQSBatchHandler(traceCtx context.Context, h MessageBatchHandler) MessageBatchHandler {
+	return wrapMessageBatchHandler(traceCtx, sqsSystem, semconv.MessagingSourceKindQueue, h)
+}
+
+// wrapMessageBatchHandler returns a handler that creates a consumer span for
+// each batch of messages before calling h. kind is the messaging.source.kind
+// attribute.
+func wrapMessageBatchHandler(traceCtx context.Context, system string, kind attribute.KeyValue, h MessageBatchHandler) MessageBatchHandler {
+	s := traceCtx.Value(stateKey)
+	if s == nil {
+		panic(errContextMissing)
+	}
+	tracer := s.(*stateBag).provider.Tracer(InstrumentationLibraryName)
+	return func(ctx context.Context, msgs []*Message) error {
+		if len(msgs) == 0 {
+			return h(ctx, msgs)
+		}
+		sources := make([]string, len(msgs))
+		carriers := make([]propagation.TextMapCarrier, len(msgs))
+		for i, msg := range msgs {
+			sources[i] = msg.Source
+			carriers[i] = propagation.MapCarrier(msg.Attributes)
+		}
+		attrs := []attribute.KeyValue{semconv.MessagingSystem(system), kind}
+		ctx, span := startBatchSpan(traceCtx, ctx, tracer, sources, carriers, attrs)
+		defer span.End()
+		return endBatchSpan(span, h(ctx, msgs))
+	}
+}
+
+// startBatchSpan starts a consumer span for a batch of messages consumed from
+// the given sources and linked to the span contexts stored in carriers. The
+// returned context is initialized for tracing if the span is recording.
+func startBatchSpan(
+	traceCtx, ctx context.Context,
+	tracer trace.Tracer,
+	sources []string,
+	carriers []propagation.TextMapCarrier,
+	attrs []attribute.KeyValue,
+) (context.Context, trace.Span) {
+	name := "batch process"
+	attrs = append(attrs, semconv.MessagingOperationProcess, semconv.MessagingBatchMessageCount(len(sources)))
+	if sameSource(sources) {
+		name = sources[0] + " process"
+		attrs = append(attrs, semconv.MessagingSourceName(sources[0]))
+	}
+	ctx, span := tracer.Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(attrs...),
+		trace.WithLinks(BatchLinks(traceCtx, carriers...)...))
+	if IsTraced(ctx) {
+		ctx = withTracing(traceCtx, ctx)
+	}
+	return ctx, span
+}
+
+// endBatchSpan records err in span if not nil and returns it.
+func endBatchSpan(span trace.Span, err error) error {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return err
+}
+
+// sameSource returns true if all the given sources are identical.
+func sameSource(sources []string) bool {
+	for _, s := range sources[1:] {
+		if s != sources[0] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,176 @@
+package trace
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestBatchLinks(t *testing.T) {
+	provider := sdktrace.NewTracerProvider()
+	ctx := testContext(provider)
+	spanCtx, span := provider.Tracer("test").Start(ctx, "producer")
+	defer span.End()
+	traced := InjectPubSub(spanCtx, nil)
+
+	links := BatchLinks(ctx, propagation.MapCarrier(traced), propagation.MapCarrier{}, propagation.MapCarrier(traced))
+	if len(links) != 2 {
+		t.Fatalf("got %d links, want 2", len(links))
+	}
+	for _, l := range links {
+		if l.SpanContext.SpanID() != span.SpanContext().SpanID() {
+			t.Errorf("got linked span %s, want %s", l.SpanContext.SpanID(), span.SpanContext().SpanID())
+		}
+	}
+	if links := BatchLinks(context.Background(), propagation.MapCarrier(traced)); links != nil {
+		t.Errorf("got links %v, want nil", links)
+	}
+}
+
+func TestWrapKafkaBatchHandler(t *testing.T) {
+	cases := []struct {
+		name         string
+		topics       []string
+		err          error
+		expectedName string
+	}{
+		{"same topic", []string{"orders", "orders"}, nil, "orders process"},
+		{"multiple topics", []string{"orders", "payments"}, nil, "batch process"},
+		{"error", []string{"orders"}, errors.New("boom"), "orders process"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			exporter := tracetest.NewInMemoryExporter()
+			provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+			traceCtx := testContext(provider)
+			var producers []trace.SpanContext
+			msgs := make([]*KafkaMessage, len(c.topics))
+			for i, topic := range c.topics {
+				ctx, span := provider.Tracer("test").Start(traceCtx, "producer")
+				msgs[i] = &KafkaMessage{Topic: topic}
+				InjectKafka(ctx, &msgs[i].Headers)
+				producers = append(producers, span.SpanContext())
+				span.End()
+			}
+			exporter.Reset()
+
+			var traced bool
+			handler := WrapKafkaBatchHandler(traceCtx, func(ctx context.Context, msgs []*KafkaMessage) error {
+				traced = IsTraced(ctx)
+				return c.err
+			})
+			if err := handler(context.Background(), msgs); err != c.err {
+				t.Errorf("got error %v, want %v", err, c.err)
+			}
+
+			if !traced {
+				t.Error("handler context not traced")
+			}
+			spans := exporter.GetSpans()
+			if len(spans) != 1 {
+				t.Fatalf("got %d spans, want 1", len(spans))
+			}
+			span := spans[0]
+			if span.Name != c.expectedName {
+				t.Errorf("got span name %q, want %q", span.Name, c.expectedName)
+			}
+			if span.Parent.IsValid() {
+				t.Error("batch span has a parent")
+			}
+			if len(span.Links) != len(producers) {
+				t.Fatalf("got %d links, want %d", len(span.Links), len(producers))
+			}
+			for i, l := range span.Links {
+				if l.SpanContext.SpanID() != producers[i].SpanID() {
+					t.Errorf("link %d: got span %s, want %s", i, l.SpanContext.SpanID(), producers[i].SpanID())
+				}
+			}
+			var count int64
+			for _, attr := range span.Attributes {
+				if attr.Key == semconv.MessagingBatchMessageCountKey {
+					count = attr.Value.AsInt64()
+				}
+			}
+			if count != int64(len(msgs)) {
+				t.Errorf("got message count %d, want %d", count, len(msgs))
+			}
+			if c.err != nil && span.Status.Code != codes.Error {
+				t.Errorf("got status %v, want error", span.Status.Code)
+			}
+		})
+	}
+}
+
+func TestWrapMessageBatchHandler(t *testing.T) {
+	cases := []struct {
+		name        string
+		wrap        func(context.Context, MessageBatchHandler) MessageBatchHandler
+		expectedSys string
+	}{
+		{"pubsub", WrapPubSubBatchHandler, "gcp_pubsub"},
+		{"sqs", WrapSQSBatchHandler, "aws_sqs"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			exporter := tracetest.NewInMemoryExporter()
+			provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+			traceCtx := testContext(provider)
+			ctx, producer := provider.Tracer("test").Start(traceCtx, "producer")
+			msgs := []*Message{
+				{ID: "1", Source: "orders", Attributes: InjectSQS(ctx, nil)},
+				{ID: "2", Source: "orders"},
+			}
+			producer.End()
+			exporter.Reset()
+
+			handler := c.wrap(traceCtx, func(context.Context, []*Message) error { return nil })
+			if err := handler(context.Background(), msgs); err != nil {
+				t.Fatal(err)
+			}
+
+			spans := exporter.GetSpans()
+			if len(spans) != 1 {
+				t.Fatalf("got %d spans, want 1", len(spans))
+			}
+			span := spans[0]
+			if span.Name != "orders process" {
+				t.Errorf("got span name %q, want %q", span.Name, "orders process")
+			}
+			if len(span.Links) != 1 || span.Links[0].SpanContext.SpanID() != producer.SpanContext().SpanID() {
+				t.Errorf("got links %v, want link to producer", span.Links)
+			}
+			var sys string
+			for _, attr := range span.Attributes {
+				if attr.Key == semconv.MessagingSystemKey {
+					sys = attr.Value.AsString()
+				}
+			}
+			if sys != c.expectedSys {
+				t.Errorf("got messaging system %q, want %q", sys, c.expectedSys)
+			}
+		})
+	}
+}
+
+func TestWrapBatchHandlerEmpty(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	var called bool
+	handler := WrapSQSBatchHandler(testContext(provider), func(context.Context, []*Message) error { called = true; return nil })
+	if err := handler(context.Background(), nil); err != nil {
+		t.Fatal(err)
+	}
+	if !called {
+		t.Error("handler not called")
+	}
+	if n := len(exporter.GetSpans()); n != 0 {
+		t.Errorf("got %d spans, want 0", n)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 
+	"go.opentelemetry.io/contrib/propagators/b3"
 	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/sdk/resource"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
@@ -22,6 +23,7 @@ type (
 		propagator propagation.TextMapPropagator
 		tracer     trace.Tracer
 		spans      []trace.Span
+		control    *Control
 	}
 )
 
@@ -37,9 +39,27 @@ const (
 const (
 	// stateKey is used to store the tracing state the context.
 	stateKey ctxKey = iota + 1
+	// retryAttemptKey is used to store the retry attempt number in the
+	// context.
+	retryAttemptKey
+	// routeKey is used to store the request path used by the route
+	// sampler in the context.
+	routeKey
+	// debugKey is used to mark the requests whose sampling is forced with
+	// the debug header in the context.
+	debugKey
+	// spanNamingKey is used to store the span naming convention used by
+	// the Endpoint middleware in the context.
+	spanNamingKey
+	// handledRequestKey is used to store the context of the request
+	// received by the handler wrapped by the HTTP middleware.
+	handledRequestKey
 )
 
-// Context initializes the context so it can be used to create traces.
+// Context initializes the context so it can be used to create traces. Context
+// creates a tracer provider that exports spans with the exporter given via
+// WithExporter unless a provider is given via WithTracerProvider. Use Shutdown
+// to export the pending spans before the process exits.
 func Context(ctx context.Context, svc string, opts ...TraceOption) (context.Context, error) {
 	options := defaultOptions()
 	for _, o := range opts {
@@ -49,8 +69,31 @@ func Context(ctx context.Context, svc string, opts ...TraceOption) (context.Cont
 		}
 	}
 
+	if options.b3 {
+		options.propagator = propagation.NewCompositeTextMapPropagator(
+			options.propagator,
+			b3.New(options.b3Options...),
+		)
+	}
+	if options.xray {
+		options.propagator = propagation.NewCompositeTextMapPropagator(
+			options.propagator,
+			XRayPropagator{},
+		)
+	}
+
+	control := options.control
+	if control == nil {
+		control = &Control{}
+	}
 	if options.disabled {
-		return withProvider(ctx, trace.NewNoopTracerProvider(), options.propagator, svc), nil
+		return withProvider(ctx, trace.NewNoopTracerProvider(), options.propagator, svc, control), nil
+	}
+	if options.provider != nil {
+		if configuresProvider(options) {
+			return nil, errors.New("exporter, sampling and resource options cannot be used with WithTracerProvider")
+		}
+		return withProvider(ctx, options.provider, options.propagator, svc, control), nil
 	}
 
 	if options.exporter == nil {
@@ -62,13 +105,39 @@ func Context(ctx context.Context, svc string, opts ...TraceOption) (context.Cont
 		res = resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceNameKey.String(svc))
 	}
 
-	rootSampler := adaptiveSampler(options.maxSamplingRate, options.sampleSize)
-	provider := sdktrace.NewTracerProvider(
-		sdktrace.WithSampler(sdktrace.ParentBased(rootSampler, options.parentSamplerOptions...)),
+	rootSampler := AdaptiveSampler(options.maxSamplingRate, options.sampleSize)
+	processor := sdktrace.NewBatchSpanProcessor(options.exporter)
+	if options.tailSampling {
+		rootSampler = sdktrace.AlwaysSample()
+		processor = NewTailSampler(processor, options.tailOptions...)
+	}
+	providerOptions := []sdktrace.TracerProviderOption{
+		sdktrace.WithSampler(controlSampler{
+			control: control,
+			next:    debugSampler{newRouteSampler(rootSampler, options.parentSamplerOptions, options.samplerOptions...)},
+		}),
 		sdktrace.WithResource(res),
-		sdktrace.WithBatcher(options.exporter),
-	)
-	return withProvider(ctx, provider, options.propagator, svc), nil
+		sdktrace.WithSpanProcessor(processor),
+	}
+	if options.xray {
+		providerOptions = append(providerOptions, sdktrace.WithIDGenerator(NewXRayIDGenerator()))
+	}
+	provider := sdktrace.NewTracerProvider(providerOptions...)
+	addProvider(provider)
+	return withProvider(ctx, provider, options.propagator, svc, control), nil
+}
+
+// configuresProvider returns true if options contain options that configure
+// the tracer provider created by Context.
+func configuresProvider(options *options) bool {
+	defaults := defaultOptions()
+	return options.exporter != nil ||
+		options.resource != nil ||
+		options.tailSampling ||
+		len(options.samplerOptions) > 0 ||
+		len(options.parentSamplerOptions) > 0 ||
+		options.maxSamplingRate != defaults.maxSamplingRate ||
+		options.sampleSize != defaults.sampleSize
 }
 
 // IsTraced returns true if the current request is traced.
@@ -84,8 +153,8 @@ func TraceProvider(ctx context.Context) trace.TracerProvider {
 }
 
 // withProvider stores the tracer provider in the context.
-func withProvider(ctx context.Context, provider trace.TracerProvider, propagator propagation.TextMapPropagator, svc string) context.Context {
-	return context.WithValue(ctx, stateKey, &stateBag{provider: provider, propagator: propagator, svc: svc})
+func withProvider(ctx context.Context, provider trace.TracerProvider, propagator propagation.TextMapPropagator, svc string, control *Control) context.Context {
+	return context.WithValue(ctx, stateKey, &stateBag{provider: provider, propagator: propagator, svc: svc, control: control})
 }
 
 // withTracing initializes the tracing context, ctx must have been initialized
@@ -95,6 +164,7 @@ func withTracing(traceCtx, ctx context.Context) context.Context {
 	svc := state.svc
 	provider := state.provider
 	propagator := state.propagator
+	control := state.control
 	tracer := provider.Tracer(InstrumentationLibraryName)
 	spans := []trace.Span{trace.SpanFromContext(ctx)}
 	return context.WithValue(ctx, stateKey, &stateBag{
@@ -103,5 +173,6 @@ func withTracing(traceCtx, ctx context.Context) context.Context {
 		propagator: propagator,
 		tracer:     tracer,
 		spans:      spans,
+		control:    control,
 	})
 }
@@ -2,8 +2,10 @@ package trace
 
 import (
 	"context"
+	"net/http"
 	"testing"
 
+	"go.opentelemetry.io/contrib/propagators/b3"
 	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/sdk/resource"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
@@ -12,7 +14,7 @@ import (
 )
 
 func testContext(provider trace.TracerProvider) context.Context {
-	return withProvider(context.Background(), provider, propagation.TraceContext{}, "test")
+	return withProvider(context.Background(), provider, propagation.TraceContext{}, "test", &Control{})
 }
 
 func TestContext(t *testing.T) {
@@ -38,6 +40,42 @@ func TestContext(t *testing.T) {
 	}
 }
 
+func TestContextTracerProvider(t *testing.T) {
+	provider := sdktrace.NewTracerProvider()
+	control := &Control{}
+	ctx, err := Context(context.Background(), "test", WithTracerProvider(provider), WithControl(control))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if TraceProvider(ctx) != provider {
+		t.Error("expected given provider in tracing context")
+	}
+	if TracingControl(ctx) != control {
+		t.Error("expected given control in tracing context")
+	}
+}
+
+func TestContextTracerProviderConflict(t *testing.T) {
+	cases := []struct {
+		name string
+		opt  TraceOption
+	}{
+		{"exporter", WithExporter(tracetest.NewInMemoryExporter())},
+		{"sampler", WithSampler(ParentRatio(1))},
+		{"tail sampling", WithTailSampling()},
+		{"resource", WithResource(&resource.Resource{})},
+		{"max sampling rate", WithMaxSamplingRate(10)},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := Context(context.Background(), "test", WithTracerProvider(sdktrace.NewTracerProvider()), c.opt)
+			if err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
 func TestDisabled(t *testing.T) {
 	ctx, err := Context(context.Background(), "test", WithDisabled())
 	if err != nil {
@@ -81,3 +119,42 @@ func TestIsTraced(t *testing.T) {
 	}
 	EndSpan(ctx)
 }
+
+func TestB3Propagation(t *testing.T) {
+	cases := []struct {
+		name     string
+		opts     []b3.Option
+		expected []string
+	}{
+		{"default", nil, []string{"Traceparent", "B3"}},
+		{"multi", []b3.Option{b3.WithInjectEncoding(b3.B3MultipleHeader)}, []string{"Traceparent", "X-B3-Traceid", "X-B3-Spanid", "X-B3-Sampled"}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctx, err := Context(context.Background(), "test",
+				WithExporter(tracetest.NewInMemoryExporter()), WithB3Propagation(c.opts...))
+			if err != nil {
+				t.Fatal(err)
+			}
+			sc := trace.NewSpanContext(trace.SpanContextConfig{
+				TraceID:    trace.TraceID{1},
+				SpanID:     trace.SpanID{1},
+				TraceFlags: trace.FlagsSampled,
+			})
+			header := http.Header{}
+			propagator := ctx.Value(stateKey).(*stateBag).propagator
+			propagator.Inject(trace.ContextWithSpanContext(ctx, sc), propagation.HeaderCarrier(header))
+			for _, h := range c.expected {
+				if header.Get(h) == "" {
+					t.Errorf("missing header %q in %v", h, header)
+				}
+			}
+
+			header = http.Header{"B3": []string{"01000000000000000000000000000000-0100000000000000-1"}}
+			extracted := trace.SpanContextFromContext(propagator.Extract(ctx, propagation.HeaderCarrier(header)))
+			if !extracted.IsValid() || extracted.TraceID() != sc.TraceID() {
+				t.Errorf("got extracted span context %v, want trace ID %v", extracted, sc.TraceID())
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package trace
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type (
+	// Control makes it possible to change the tracing configuration at
+	// runtime, for example to investigate an incident without redeploying.
+	// Tracing can be disabled altogether, the sampling ratio of requests
+	// without a parent can be overridden and the next requests can be forced
+	// to be sampled. The debug package exposes an HTTP endpoint that uses
+	// Control, see debug.MountTraceControl. Control is safe for concurrent
+	// use.
+	Control struct {
+		disabled atomic.Bool
+		ratio    atomic.Pointer[float64]
+		forced   atomic.Int64
+	}
+
+	// controlSampler applies the runtime configuration of a Control
+	// before delegating to the configured sampler.
+	controlSampler struct {
+		control *Control
+		next    sdktrace.Sampler
+	}
+)
+
+// TracingControl returns the runtime control of the tracing configured with
+// Context. It panics if ctx hasn't been initialized with Context.
+//
+// Example:
+//
+//	// Sample the next 100 requests
+//	trace.TracingControl(ctx).ForceSample(100)
+func TracingControl(ctx context.Context) *Control {
+	s := ctx.Value(stateKey)
+	if s == nil {
+		panic(errContextMissing)
+	}
+	return s.(*stateBag).control
+}
+
+// ControlSampler returns a sampler that applies the runtime configuration of
+// control before delegating to next. Context wraps the sampler of the tracer
+// provider it creates with ControlSampler, providers given via
+// WithTracerProvider must do so for control to have an effect.
+func ControlSampler(control *Control, next sdktrace.Sampler) sdktrace.Sampler {
+	return controlSampler{control: control, next: next}
+}
+
+// SetEnabled enables or disables tracing. No span is sampled while tracing is
+// disabled.
+func (c *Control) SetEnabled(enabled bool) {
+	c.disabled.Store(!enabled)
+}
+
+// Enabled returns true if tracing is enabled.
+func (c *Control) Enabled() bool {
+	return !c.disabled.Load()
+}
+
+// SetSamplingRatio overrides the sampler configured with Context for the
+// requests that do not have a parent: the given fraction of these requests are
+// sampled using the trace ID. A negative fraction removes the override.
+func (c *Control) SetSamplingRatio(fraction float64) {
+	if fraction < 0 {
+		c.ratio.Store(nil)
+		return
+	}
+	c.ratio.Store(&fraction)
+}
+
+// SamplingRatio returns the sampling ratio set with SetSamplingRatio and true
+// if there is one, 0 and false otherwise.
+func (c *Control) SamplingRatio() (float64, bool) {
+	if r := c.ratio.Load(); r != nil {
+		return *r, true
+	}
+	return 0, false
+}
+
+// ForceSample forces the sampling of the next n requests regardless of the
+// sampler configuration and of the parent sampling decision. Requests are
+// the spans that do not have a parent or whose parent is remote. ForceSample
+// replaces the number of remaining forced samples, n = 0 cancels them.
+func (c *Control) ForceSample(n int) {
+	c.forced.Store(int64(n))
+}
+
+// ForcedSamples returns the number of requests that remain to be forcibly
+// sampled.
+func (c *Control) ForcedSamples() int {
+	return int(c.forced.Load())
+}
+
+// takeForced returns true and decrements the number of remaining forced
+// samples if it is positive.
+func (c *Control) takeForced() bool {
+	for {
+		n := c.forced.Load()
+		if n <= 0 {
+			return false
+		}
+		if c.forced.CompareAndSwap(n, n-1) {
+			return true
+		}
+	}
+}
+
+// Description returns the description of the sampler.
+func (s controlSampler) Description() string {
+	return fmt.Sprintf("Control{next:%s}", s.next.Description())
+}
+
+// ShouldSample returns the sampling decision for the given parameters.
+func (s controlSampler) ShouldSample(p sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	if !s.control.Enabled() {
+		return sdktrace.SamplingResult{Decision: sdktrace.Drop}
+	}
+	psc := trace.SpanContextFromContext(p.ParentContext)
+	if (!psc.IsValid() || psc.IsRemote()) && s.control.takeForced() {
+		return sdktrace.SamplingResult{
+			Decision:   sdktrace.RecordAndSample,
+			Tracestate: psc.TraceState(),
+		}
+	}
+	if !psc.IsValid() {
+		if ratio, ok := s.control.SamplingRatio(); ok {
+			return sdktrace.TraceIDRatioBased(ratio).ShouldSample(p)
+		}
+	}
+	return s.next.ShouldSample(p)
+}
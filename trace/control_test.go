@@ -0,0 +1,104 @@
+package trace
+
+import (
+	"context"
+	"testing"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestControlSampler(t *testing.T) {
+	remote := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{1},
+		SpanID:     trace.SpanID{1},
+		TraceFlags: trace.FlagsSampled,
+		Remote:     true,
+	})
+	cases := []struct {
+		name     string
+		setup    func(*Control)
+		next     sdktrace.Sampler
+		parent   trace.SpanContext
+		expected []sdktrace.SamplingDecision
+	}{
+		{"default", func(*Control) {}, sdktrace.AlwaysSample(), trace.SpanContext{},
+			[]sdktrace.SamplingDecision{sdktrace.RecordAndSample}},
+		{"disabled", func(c *Control) { c.SetEnabled(false) }, sdktrace.AlwaysSample(), trace.SpanContext{},
+			[]sdktrace.SamplingDecision{sdktrace.Drop}},
+		{"disabled with parent", func(c *Control) { c.SetEnabled(false) }, sdktrace.ParentBased(sdktrace.AlwaysSample()), remote,
+			[]sdktrace.SamplingDecision{sdktrace.Drop}},
+		{"re-enabled", func(c *Control) { c.SetEnabled(false); c.SetEnabled(true) }, sdktrace.AlwaysSample(), trace.SpanContext{},
+			[]sdktrace.SamplingDecision{sdktrace.RecordAndSample}},
+		{"ratio zero", func(c *Control) { c.SetSamplingRatio(0) }, sdktrace.AlwaysSample(), trace.SpanContext{},
+			[]sdktrace.SamplingDecision{sdktrace.Drop}},
+		{"ratio one", func(c *Control) { c.SetSamplingRatio(1) }, sdktrace.NeverSample(), trace.SpanContext{},
+			[]sdktrace.SamplingDecision{sdktrace.RecordAndSample}},
+		{"ratio reset", func(c *Control) { c.SetSamplingRatio(1); c.SetSamplingRatio(-1) }, sdktrace.NeverSample(), trace.SpanContext{},
+			[]sdktrace.SamplingDecision{sdktrace.Drop}},
+		{"ratio ignores parent", func(c *Control) { c.SetSamplingRatio(0) }, sdktrace.ParentBased(sdktrace.NeverSample()), remote,
+			[]sdktrace.SamplingDecision{sdktrace.RecordAndSample}},
+		{"forced", func(c *Control) { c.ForceSample(2) }, sdktrace.NeverSample(), trace.SpanContext{},
+			[]sdktrace.SamplingDecision{sdktrace.RecordAndSample, sdktrace.RecordAndSample, sdktrace.Drop}},
+		{"forced remote parent", func(c *Control) { c.ForceSample(1) }, sdktrace.NeverSample(), remote,
+			[]sdktrace.SamplingDecision{sdktrace.RecordAndSample, sdktrace.Drop}},
+		{"forced cancelled", func(c *Control) { c.ForceSample(2); c.ForceSample(0) }, sdktrace.NeverSample(), trace.SpanContext{},
+			[]sdktrace.SamplingDecision{sdktrace.Drop}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			control := &Control{}
+			c.setup(control)
+			s := controlSampler{control: control, next: c.next}
+			ctx := trace.ContextWithSpanContext(context.Background(), c.parent)
+			for i, expected := range c.expected {
+				res := s.ShouldSample(sdktrace.SamplingParameters{ParentContext: ctx, TraceID: trace.TraceID{1}, Name: "test"})
+				if res.Decision != expected {
+					t.Errorf("request %d: got decision %v, want %v", i, res.Decision, expected)
+				}
+			}
+		})
+	}
+}
+
+func TestControlLocalParent(t *testing.T) {
+	control := &Control{}
+	control.ForceSample(1)
+	s := controlSampler{control: control, next: sdktrace.ParentBased(sdktrace.NeverSample())}
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSampler(s))
+	ctx, span := provider.Tracer("test").Start(context.Background(), "request")
+	defer span.End()
+	_, child := provider.Tracer("test").Start(ctx, "child")
+	defer child.End()
+	if !child.SpanContext().IsSampled() {
+		t.Error("child span not sampled")
+	}
+	if n := control.ForcedSamples(); n != 0 {
+		t.Errorf("got %d forced samples, want 0", n)
+	}
+}
+
+func TestTracingControl(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	ctx, err := Context(context.Background(), "test", WithExporter(exporter), WithSampler(ParentRatio(0)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	control := TracingControl(ctx)
+	control.ForceSample(1)
+	_, span := TraceProvider(ctx).Tracer("test").Start(ctx, "request")
+	span.End()
+	if !span.SpanContext().IsSampled() {
+		t.Error("forced span not sampled")
+	}
+	if !TracingControl(withTracing(ctx, context.Background())).Enabled() {
+		t.Error("control not propagated to request context")
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic")
+		}
+	}()
+	TracingControl(context.Background())
+}
@@ -0,0 +1,132 @@
+package trace
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+
+	"goa.design/clue/log"
+)
+
+// debugSampler samples the requests marked by the debug header middleware
+// before delegating to the configured sampler.
+type debugSampler struct {
+	next sdktrace.Sampler
+}
+
+const (
+	// DefaultDebugHeader is the name of the request header used to force
+	// the sampling of a request, see WithDebugHeader.
+	DefaultDebugHeader = "X-Debug-Trace"
+
+	// DebugHeaderTTL is the validity period of signed debug header values,
+	// see SignDebugHeader.
+	DebugHeaderTTL = 15 * time.Minute
+)
+
+// WithDebugHeader returns an option that forces the sampling of requests that
+// have the given header and elevates the log level of these requests so that
+// all log entries, including debug entries, are written. header defaults to
+// DefaultDebugHeader if empty. If key is nil the header value must be "1" or
+// "true", otherwise it must be a value created with SignDebugHeader using the
+// same key less than DebugHeaderTTL ago. Signing makes it possible to expose
+// the debug mode to support engineers without letting any client force the
+// sampling of its requests. The log level is only elevated if the log
+// middleware is mounted before the trace middleware.
+//
+// Example:
+//
+//	handler := trace.HTTP(ctx, trace.WithDebugHeader("", []byte(secret)))(mux)
+//	handler = log.HTTP(ctx)(handler)
+//
+// and to reproduce a problem with full telemetry:
+//
+//	curl -H "X-Debug-Trace: $(signer)" https://svc.example.com/orders
+func WithDebugHeader(header string, key []byte) HTTPOption {
+	if header == "" {
+		header = DefaultDebugHeader
+	}
+	return func(o *httpOptions) {
+		o.debugHeader = header
+		o.debugKey = key
+	}
+}
+
+// SignDebugHeader returns a value for the debug header that is valid for
+// DebugHeaderTTL from t when the middleware is configured with the same key,
+// see WithDebugHeader.
+func SignDebugHeader(key []byte, t time.Time) string {
+	ts := strconv.FormatInt(t.Unix(), 10)
+	return ts + "." + debugSignature(key, ts)
+}
+
+// IsDebugRequest returns true if ctx belongs to a request whose sampling was
+// forced with the debug header, see WithDebugHeader.
+func IsDebugRequest(ctx context.Context) bool {
+	debug, _ := ctx.Value(debugKey).(bool)
+	return debug
+}
+
+// Description returns the description of the sampler.
+func (s debugSampler) Description() string {
+	return fmt.Sprintf("Debug{next:%s}", s.next.Description())
+}
+
+// ShouldSample returns the sampling decision for the given parameters.
+func (s debugSampler) ShouldSample(p sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	if IsDebugRequest(p.ParentContext) {
+		return sdktrace.SamplingResult{
+			Decision:   sdktrace.RecordAndSample,
+			Tracestate: trace.SpanContextFromContext(p.ParentContext).TraceState(),
+		}
+	}
+	return s.next.ShouldSample(p)
+}
+
+// debugHeaderHTTP is a middleware that marks the context of requests with a
+// valid debug header so that they get sampled and elevates their log level.
+func debugHeaderHTTP(h http.Handler, options *httpOptions) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if v := req.Header.Get(options.debugHeader); v != "" && validDebugHeader(v, options.debugKey, time.Now()) {
+			ctx := context.WithValue(req.Context(), debugKey, true)
+			req = req.WithContext(log.ForceDebug(ctx))
+		}
+		h.ServeHTTP(w, req)
+	})
+}
+
+// validDebugHeader returns true if v is a valid debug header value at time now
+// for the given key.
+func validDebugHeader(v string, key []byte, now time.Time) bool {
+	if key == nil {
+		return v == "1" || v == "true"
+	}
+	ts, sig, ok := strings.Cut(v, ".")
+	if !ok {
+		return false
+	}
+	sec, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return false
+	}
+	if d := now.Sub(time.Unix(sec, 0)); d > DebugHeaderTTL || d < -DebugHeaderTTL {
+		return false
+	}
+	return hmac.Equal([]byte(sig), []byte(debugSignature(key, ts)))
+}
+
+// debugSignature returns the hex encoded HMAC-SHA256 of ts using key.
+func debugSignature(key []byte, ts string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(ts))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,102 @@
+package trace
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"goa.design/clue/log"
+)
+
+func TestValidDebugHeader(t *testing.T) {
+	key := []byte("secret")
+	now := time.Unix(1700000000, 0)
+	cases := []struct {
+		name     string
+		value    string
+		key      []byte
+		expected bool
+	}{
+		{"unsigned one", "1", nil, true},
+		{"unsigned true", "true", nil, true},
+		{"unsigned other", "yes", nil, false},
+		{"signed", SignDebugHeader(key, now), key, true},
+		{"signed recently", SignDebugHeader(key, now.Add(-DebugHeaderTTL+time.Second)), key, true},
+		{"signed expired", SignDebugHeader(key, now.Add(-DebugHeaderTTL-time.Second)), key, false},
+		{"signed future", SignDebugHeader(key, now.Add(DebugHeaderTTL+time.Second)), key, false},
+		{"signed other key", SignDebugHeader([]byte("other"), now), key, false},
+		{"unsigned with key", "1", key, false},
+		{"invalid timestamp", "abc.def", key, false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := validDebugHeader(c.value, c.key, now); got != c.expected {
+				t.Errorf("got %v, want %v", got, c.expected)
+			}
+		})
+	}
+}
+
+func TestHTTPDebugHeader(t *testing.T) {
+	key := []byte("secret")
+	cases := []struct {
+		name     string
+		header   string
+		value    string
+		expected bool
+	}{
+		{"no header", "", "", false},
+		{"default header", DefaultDebugHeader, SignDebugHeader(key, time.Now()), true},
+		{"invalid signature", DefaultDebugHeader, SignDebugHeader([]byte("other"), time.Now()), false},
+		{"other header", "X-Other", SignDebugHeader(key, time.Now()), false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			exporter := tracetest.NewInMemoryExporter()
+			provider := sdktrace.NewTracerProvider(
+				sdktrace.WithSampler(RouteSampler(sdktrace.NeverSample())),
+				sdktrace.WithSyncer(exporter))
+			ctx := testContext(provider)
+			var buf bytes.Buffer
+			logCtx := log.Context(context.Background(), log.WithOutput(&buf))
+			var debug, logDebug bool
+			handler := HTTP(ctx, WithDebugHeader("", key))(http.HandlerFunc(func(_ http.ResponseWriter, req *http.Request) {
+				debug = IsDebugRequest(req.Context())
+				logDebug = log.DebugEnabled(req.Context())
+			}))
+			req := httptest.NewRequest("GET", "/", nil)
+			if c.header != "" {
+				req.Header.Set(c.header, c.value)
+			}
+			req = req.WithContext(logCtx)
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+			if debug != c.expected {
+				t.Errorf("got debug request %v, want %v", debug, c.expected)
+			}
+			if logDebug != c.expected {
+				t.Errorf("got debug logs %v, want %v", logDebug, c.expected)
+			}
+			var expected int
+			if c.expected {
+				expected = 1
+			}
+			if got := len(exporter.GetSpans()); got != expected {
+				t.Errorf("got %d spans, want %d", got, expected)
+			}
+		})
+	}
+}
+
+func TestDebugSamplerDescription(t *testing.T) {
+	s := RouteSampler(sdktrace.AlwaysSample())
+	expected := "Debug{next:ParentBased{root:AlwaysOnSampler,remoteParentSampled:AlwaysOnSampler,remoteParentNotSampled:AlwaysOffSampler,localParentSampled:AlwaysOnSampler,localParentNotSampled:AlwaysOffSampler}}"
+	if s.Description() != expected {
+		t.Errorf("got description %q, want %q", s.Description(), expected)
+	}
+}
@@ -0,0 +1,72 @@
+package trace
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// EventRetry is the name of the span event recorded by RecordRetry.
+	EventRetry = "retry"
+	// EventDeadlineExceeded is the name of the span event recorded by
+	// RecordDeadlineExceeded.
+	EventDeadlineExceeded = "deadline exceeded"
+
+	// AttributeRetryAttempt is the name of the retry event attribute that
+	// contains the retry attempt number.
+	AttributeRetryAttempt = "retry.attempt"
+	// AttributeRetryBackoff is the name of the retry event attribute that
+	// contains the duration waited before the retry.
+	AttributeRetryBackoff = "retry.backoff"
+	// AttributeTimeout is the name of the deadline exceeded event attribute
+	// that contains the duration between the start of the span and the
+	// deadline if known.
+	AttributeTimeout = "timeout"
+)
+
+// RecordRetry records a retry event in the current span with the attempt
+// number (the first retry is attempt 1) and the backoff duration waited before
+// the attempt. Retry logic should call RecordRetry prior to each retry so that
+// traces explain where latency went. See also WithRetryAttempt for HTTP
+// clients.
+func RecordRetry(ctx context.Context, attempt int, backoff time.Duration) {
+	span := currentSpan(ctx)
+	if !span.IsRecording() {
+		return
+	}
+	span.AddEvent(EventRetry, trace.WithAttributes(
+		attribute.Int(AttributeRetryAttempt, attempt),
+		attribute.String(AttributeRetryBackoff, backoff.String()),
+	))
+}
+
+// RecordDeadlineExceeded records a deadline exceeded event in the current
+// span. The event records the timeout if ctx has a deadline. The HTTP and gRPC
+// middlewares and the HTTP client record the event automatically when a
+// request fails because its deadline is exceeded.
+func RecordDeadlineExceeded(ctx context.Context) {
+	span := currentSpan(ctx)
+	if !span.IsRecording() {
+		return
+	}
+	var opts []trace.EventOption
+	if deadline, ok := ctx.Deadline(); ok {
+		if ro, ok := span.(interface{ StartTime() time.Time }); ok {
+			opts = append(opts, trace.WithAttributes(
+				attribute.String(AttributeTimeout, deadline.Sub(ro.StartTime()).String())))
+		}
+	}
+	span.AddEvent(EventDeadlineExceeded, opts...)
+}
+
+// currentSpan returns the active span created with StartSpan if any, the span
+// stored in ctx by OpenTelemetry otherwise.
+func currentSpan(ctx context.Context) trace.Span {
+	if span := activeSpan(ctx); span != nil {
+		return span
+	}
+	return trace.SpanFromContext(ctx)
+}
@@ -0,0 +1,95 @@
+package trace
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestRecordRetry(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	ctx := testContext(provider)
+	ctx = StartTrace(ctx, "test")
+	RecordRetry(ctx, 2, 200*time.Millisecond)
+	EndTrace(ctx)
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("got %d spans, want 1", len(spans))
+	}
+	events := spans[0].Events
+	if len(events) != 1 || events[0].Name != EventRetry {
+		t.Fatalf("got events %v, want retry event", events)
+	}
+	attrs := make(map[string]string)
+	for _, att := range events[0].Attributes {
+		attrs[string(att.Key)] = att.Value.Emit()
+	}
+	if attrs[AttributeRetryAttempt] != "2" {
+		t.Errorf("got attempt %q, want 2", attrs[AttributeRetryAttempt])
+	}
+	if attrs[AttributeRetryBackoff] != "200ms" {
+		t.Errorf("got backoff %q, want 200ms", attrs[AttributeRetryBackoff])
+	}
+}
+
+func TestRecordRetryNotTraced(t *testing.T) {
+	RecordRetry(context.Background(), 1, time.Second)
+	RecordDeadlineExceeded(context.Background())
+}
+
+func TestRecordDeadlineExceeded(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	ctx := testContext(provider)
+	ctx = StartTrace(ctx, "test")
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+	RecordDeadlineExceeded(ctx)
+	EndTrace(ctx)
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("got %d spans, want 1", len(spans))
+	}
+	events := spans[0].Events
+	if len(events) != 1 || events[0].Name != EventDeadlineExceeded {
+		t.Fatalf("got events %v, want deadline exceeded event", events)
+	}
+	if len(events[0].Attributes) != 1 || string(events[0].Attributes[0].Key) != AttributeTimeout {
+		t.Errorf("got attributes %v, want timeout", events[0].Attributes)
+	}
+}
+
+func TestHTTPDeadlineExceeded(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	ctx := testContext(provider)
+	handler := HTTP(ctx)(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		<-req.Context().Done()
+	}))
+	reqCtx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	req := httptest.NewRequest("GET", "/", nil).WithContext(reqCtx)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("got %d spans, want 1", len(spans))
+	}
+	found := false
+	for _, e := range spans[0].Events {
+		if e.Name == EventDeadlineExceeded {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("got events %v, want deadline exceeded event", spans[0].Events)
+	}
+}
@@ -0,0 +1,60 @@
+package trace
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	goa "goa.design/goa/v3/pkg"
+)
+
+const (
+	// AttributeGoaService is the name of the span attribute that contains
+	// the Goa service name.
+	AttributeGoaService = "goa.service"
+
+	// AttributeGoaMethod is the name of the span attribute that contains
+	// the Goa method name.
+	AttributeGoaMethod = "goa.method"
+)
+
+// Endpoint is a Goa endpoint middleware that adds the service and method names
+// to the attributes of the current span. This makes it possible to filter
+// traces using the design level names rather than URL paths or gRPC method
+// names. Endpoint also names the span after the Goa method when the HTTP
+// middleware is configured to do so, see WithSpanNaming, and makes the context
+// of the request available to the route resolver, see CaptureRoute. Use
+// log.Endpoint to add the same names to log entries.
+//
+// Example:
+//
+//	endpoints := genfront.NewEndpoints(svc)
+//	endpoints.Use(trace.Endpoint)
+//	endpoints.Use(log.Endpoint)
+func Endpoint(e goa.Endpoint) goa.Endpoint {
+	return func(ctx context.Context, req interface{}) (interface{}, error) {
+		recordHandledRequest(ctx)
+		span := trace.SpanFromContext(ctx)
+		if span.IsRecording() {
+			s, hasService := ctx.Value(goa.ServiceKey).(string)
+			if hasService {
+				span.SetAttributes(attribute.String(AttributeGoaService, s))
+			}
+			m, hasMethod := ctx.Value(goa.MethodKey).(string)
+			if hasMethod {
+				span.SetAttributes(attribute.String(AttributeGoaMethod, m))
+			}
+			if name, ok := ctx.Value(spanNamingKey).(*goaSpanName); ok && hasMethod {
+				switch {
+				case name.naming == SpanNamingGoaMethod:
+					span.SetName(m)
+					name.named = true
+				case name.naming == SpanNamingServiceMethod && hasService:
+					span.SetName(s + "." + m)
+					name.named = true
+				}
+			}
+		}
+		return e(ctx, req)
+	}
+}
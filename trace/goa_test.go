@@ -0,0 +1,68 @@
+package trace
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	goa "goa.design/goa/v3/pkg"
+)
+
+func TestEndpoint(t *testing.T) {
+	cases := []struct {
+		name     string
+		sname    string
+		mname    string
+		expected map[attribute.Key]string
+	}{
+		{"service and method name", "Service", "Method", map[attribute.Key]string{AttributeGoaService: "Service", AttributeGoaMethod: "Method"}},
+		{"no service name", "", "Method", map[attribute.Key]string{AttributeGoaMethod: "Method"}},
+		{"no method name", "Service", "", map[attribute.Key]string{AttributeGoaService: "Service"}},
+		{"no service or method name", "", "", map[attribute.Key]string{}},
+	}
+	endpoint := func(ctx context.Context, req interface{}) (interface{}, error) { return nil, nil }
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			exporter := tracetest.NewInMemoryExporter()
+			provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+			ctx, span := provider.Tracer("test").Start(context.Background(), "test")
+			if c.sname != "" {
+				ctx = context.WithValue(ctx, goa.ServiceKey, c.sname)
+			}
+			if c.mname != "" {
+				ctx = context.WithValue(ctx, goa.MethodKey, c.mname)
+			}
+
+			Endpoint(endpoint)(ctx, nil)
+			span.End()
+
+			spans := exporter.GetSpans()
+			if len(spans) != 1 {
+				t.Fatalf("got %d spans, want 1", len(spans))
+			}
+			attrs := spans[0].Attributes
+			if len(attrs) != len(c.expected) {
+				t.Errorf("got %d attributes, want %d", len(attrs), len(c.expected))
+			}
+			for _, attr := range attrs {
+				if c.expected[attr.Key] != attr.Value.AsString() {
+					t.Errorf("got attribute %s=%s, want %q", attr.Key, attr.Value.AsString(), c.expected[attr.Key])
+				}
+			}
+		})
+	}
+}
+
+func TestEndpointNotTraced(t *testing.T) {
+	ctx := context.WithValue(context.Background(), goa.ServiceKey, "Service")
+	var called bool
+	Endpoint(func(ctx context.Context, req interface{}) (interface{}, error) {
+		called = true
+		return nil, nil
+	})(ctx, nil)
+	if !called {
+		t.Error("expected endpoint to be called")
+	}
+}
@@ -2,55 +2,63 @@ package trace
 
 import (
 	"context"
+	"errors"
 
 	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
 	"goa.design/goa/v3/middleware"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
-// UnaryServerInterceptor returns an OpenTelemetry UnaryServerInterceptor. It
-// panics if the context has not been initialized with Context.
+// UnaryServerInterceptor returns an OpenTelemetry UnaryServerInterceptor. The
+// spans record the RPC semantic convention attributes and the gRPC status
+// code. Errors with a server error status code (Unknown, DeadlineExceeded,
+// Unimplemented, Internal, Unavailable or DataLoss) are recorded as exception
+// events. It panics if the context has not been initialized with Context.
 func UnaryServerInterceptor(traceCtx context.Context) grpc.UnaryServerInterceptor {
 	state := traceCtx.Value(stateKey)
 	if state == nil {
 		panic(errContextMissing)
 	}
+	ui := otelgrpc.UnaryServerInterceptor(
+		otelgrpc.WithTracerProvider(state.(*stateBag).provider),
+		otelgrpc.WithPropagators(state.(*stateBag).propagator))
 	return func(
 		ctx context.Context,
 		req interface{},
 		info *grpc.UnaryServerInfo,
 		handler grpc.UnaryHandler,
 	) (interface{}, error) {
+		handler = recordErrorGRPCUnary(handler)
 		handler = initTracingContextGRPCUnary(traceCtx, handler)
 		handler = addRequestIDGRPCUnary(handler)
-		ui := otelgrpc.UnaryServerInterceptor(
-			otelgrpc.WithTracerProvider(state.(*stateBag).provider),
-			otelgrpc.WithPropagators(state.(*stateBag).propagator))
 		return ui(ctx, req, info, handler)
 	}
 }
 
-// StreamServerInterceptor returns an OpenTelemetry StreamServerInterceptor. It
-// panics if the context has not been initialized with Context.
+// StreamServerInterceptor returns an OpenTelemetry StreamServerInterceptor.
+// See UnaryServerInterceptor for details on the recorded attributes and
+// errors. It panics if the context has not been initialized with Context.
 func StreamServerInterceptor(traceCtx context.Context) grpc.StreamServerInterceptor {
 	state := traceCtx.Value(stateKey)
 	if state == nil {
 		panic(errContextMissing)
 	}
+	si := otelgrpc.StreamServerInterceptor(
+		otelgrpc.WithTracerProvider(state.(*stateBag).provider),
+		otelgrpc.WithPropagators(state.(*stateBag).propagator))
 	return func(
 		srv interface{},
 		stream grpc.ServerStream,
 		info *grpc.StreamServerInfo,
 		handler grpc.StreamHandler,
 	) error {
+		handler = recordErrorGRPCStream(handler)
 		handler = initTracingContextGRPCStream(traceCtx, handler)
 		handler = addRequestIDGRPCStream(handler)
-		si := otelgrpc.StreamServerInterceptor(
-			otelgrpc.WithTracerProvider(state.(*stateBag).provider),
-			otelgrpc.WithPropagators(state.(*stateBag).propagator),
-		)
 		return si(srv, stream, info, handler)
 	}
 }
@@ -130,6 +138,43 @@ func initTracingContextGRPCStream(traceCtx context.Context, h grpc.StreamHandler
 	}
 }
 
+// recordErrorGRPCUnary is a unary handler middleware that records server
+// errors in the current span.
+func recordErrorGRPCUnary(h grpc.UnaryHandler) grpc.UnaryHandler {
+	return func(ctx context.Context, req interface{}) (interface{}, error) {
+		res, err := h(ctx, req)
+		recordServerError(ctx, err)
+		return res, err
+	}
+}
+
+// recordErrorGRPCStream is a stream handler middleware that records server
+// errors in the current span.
+func recordErrorGRPCStream(h grpc.StreamHandler) grpc.StreamHandler {
+	return func(srv interface{}, stream grpc.ServerStream) error {
+		err := h(srv, stream)
+		recordServerError(stream.Context(), err)
+		return err
+	}
+}
+
+// recordServerError records err as an exception event in the span contained
+// in ctx if its status code denotes a server error. It also records a deadline
+// exceeded event if the request deadline is exceeded.
+func recordServerError(ctx context.Context, err error) {
+	if err == nil {
+		return
+	}
+	if status.Code(err) == codes.DeadlineExceeded || errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		RecordDeadlineExceeded(ctx)
+	}
+	switch status.Code(err) {
+	case codes.Unknown, codes.DeadlineExceeded, codes.Unimplemented,
+		codes.Internal, codes.Unavailable, codes.DataLoss:
+		trace.SpanFromContext(ctx).RecordError(err)
+	}
+}
+
 type streamWithContext struct {
 	grpc.ServerStream
 	ctx context.Context
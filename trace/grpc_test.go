@@ -6,9 +6,11 @@ import (
 
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	"go.opentelemetry.io/otel/sdk/trace/tracetest"
-	"goa.design/clue/internal/testsvc"
+	"goa.design/clue/testing/testsvc"
 	"goa.design/goa/v3/grpc/middleware"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 // NOTE: We are not testing otel here, just make sure a span exists and that the
@@ -184,3 +186,43 @@ func echoMethod(_ context.Context, stream testsvc.Stream) (err error) {
 	}
 	return stream.Close()
 }
+
+func TestUnaryServerTraceError(t *testing.T) {
+	cases := []struct {
+		name       string
+		err        error
+		exceptions int
+	}{
+		{"server error", status.Error(codes.Internal, "boom"), 1},
+		{"client error", status.Error(codes.InvalidArgument, "invalid"), 0},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			exporter := tracetest.NewInMemoryExporter()
+			provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+			traceInterceptor := UnaryServerInterceptor(testContext(provider))
+			cli, stop := testsvc.SetupGRPC(t,
+				testsvc.WithServerOptions(grpc.UnaryInterceptor(traceInterceptor)),
+				testsvc.WithUnaryFunc(func(context.Context, *testsvc.Fields) (*testsvc.Fields, error) {
+					return nil, c.err
+				}))
+			if _, err := cli.GRPCMethod(context.Background(), &testsvc.Fields{}); err == nil {
+				t.Error("expected error")
+			}
+			stop()
+			spans := exporter.GetSpans()
+			if len(spans) != 1 {
+				t.Fatalf("got %d spans, want 1", len(spans))
+			}
+			var exceptions int
+			for _, e := range spans[0].Events {
+				if e.Name == "exception" {
+					exceptions++
+				}
+			}
+			if exceptions != c.exceptions {
+				t.Errorf("got %d exception events, want %d", exceptions, c.exceptions)
+			}
+		})
+	}
+}
@@ -2,55 +2,302 @@ package trace
 
 import (
 	"context"
+	"errors"
 	"net/http"
+	"regexp"
+	"strings"
 
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
 	"go.opentelemetry.io/otel/trace"
+	httpmdlwr "goa.design/goa/v3/http/middleware"
 	"goa.design/goa/v3/middleware"
 )
 
+type (
+	// HTTPOption is a function that applies a configuration option to the
+	// HTTP middleware.
+	HTTPOption func(*httpOptions)
+
+	// RouteResolver is a function that resolves the route of a request used
+	// to name spans. As an example services using the
+	// github.com/dimfeld/httptreemux muxer can use
+	// httptreemux.ContextRoute(r.Context()). This is the same resolver
+	// used by the metrics package HTTP middleware. The resolver is given
+	// the request received by the router handler when it is recorded by the
+	// Endpoint or CaptureRoute middleware, see CaptureRoute.
+	RouteResolver func(r *http.Request) string
+
+	// SpanNaming is the convention used to name the server spans created
+	// by the HTTP middleware, see WithSpanNaming.
+	SpanNaming int
+
+	httpOptions struct {
+		resolver     RouteResolver
+		patterns     []*routePattern
+		headers      []string
+		headerMaxLen int
+		suppressed   []*routePattern
+		synthetic    []SyntheticDetector
+		debugHeader  string
+		debugKey     []byte
+		naming       SpanNaming
+		echoHeader   string
+	}
+
+	// handledRequest records the context of the request received by the
+	// handler wrapped by the HTTP middleware. Routers such as httptreemux
+	// and chi store the route in that context, not in the context of the
+	// request given to the middleware.
+	handledRequest struct {
+		ctx context.Context
+	}
+
+	// goaSpanName records whether the Endpoint middleware named the
+	// current span using the Goa service and method names.
+	goaSpanName struct {
+		naming SpanNaming
+		named  bool
+	}
+
+	// routePattern is a route pattern and the corresponding regular
+	// expression.
+	routePattern struct {
+		pattern string
+		re      *regexp.Regexp
+	}
+)
+
+const (
+	// SpanNamingRoute names spans after the request method and route, e.g.
+	// "GET /users/{id}". This is the default.
+	SpanNamingRoute SpanNaming = iota
+	// SpanNamingGoaMethod names spans after the Goa method, e.g. "show".
+	SpanNamingGoaMethod
+	// SpanNamingServiceMethod names spans after the Goa service and method
+	// separated by a dot, e.g. "users.show".
+	SpanNamingServiceMethod
+)
+
+// DefaultSuppressedPaths lists the paths of the health check and metrics
+// endpoints exposed by the health and metrics packages, see
+// WithSuppressedPaths.
+var DefaultSuppressedPaths = []string{"/healthz", "/livez", "/readyz", "/startupz", "/metrics"}
+
+const (
+	// DefaultTraceIDHeader is the name of the response header that contains
+	// the trace ID, see WithTraceIDResponseHeader.
+	DefaultTraceIDHeader = "X-Trace-Id"
+
+	// TraceResponseHeader is the name of the W3C Trace Context Level 2
+	// response header, see WithTraceIDResponseHeader.
+	TraceResponseHeader = "traceresponse"
+)
+
+// DefaultHeaderMaxLength is the default maximum length of the header values
+// recorded in span attributes, see WithRequestHeaders.
+const DefaultHeaderMaxLength = 128
+
+// wildSeg matches the wildcards of route patterns such as "/users/{id}".
+var wildSeg = regexp.MustCompile(`/{([a-zA-Z0-9_]+)}`)
+
+// WithRouteResolver returns an option that sets the route resolver used to
+// name spans.
+func WithRouteResolver(resolver RouteResolver) HTTPOption {
+	return func(o *httpOptions) {
+		o.resolver = resolver
+	}
+}
+
+// WithRoutePatterns returns an option that sets the route patterns matched
+// against the request path to name spans when no route resolver is set or
+// when the resolver returns an empty string. Patterns use the same syntax as
+// the metrics package, e.g. "/users/{id}".
+func WithRoutePatterns(patterns ...string) HTTPOption {
+	return func(o *httpOptions) {
+		o.patterns = append(o.patterns, compileRoutes(patterns)...)
+	}
+}
+
+// WithSpanNaming returns an option that sets the convention used to name server
+// spans. The Goa method based conventions require the Endpoint middleware,
+// spans of requests that are not handled by a Goa endpoint are named after the
+// route. The route is recorded in the "http.route" attribute regardless of the
+// convention.
+//
+// Example:
+//
+//	handler := trace.HTTP(ctx, trace.WithSpanNaming(trace.SpanNamingServiceMethod))(mux)
+//	endpoints.Use(trace.Endpoint)
+func WithSpanNaming(naming SpanNaming) HTTPOption {
+	return func(o *httpOptions) {
+		o.naming = naming
+	}
+}
+
+// WithTraceIDResponseHeader returns an option that writes the trace ID of
+// sampled requests in the given response header so that clients and support
+// tickets can reference the exact trace. header defaults to
+// DefaultTraceIDHeader if empty. If header is TraceResponseHeader the value
+// uses the W3C traceresponse format ("00-{trace-id}-{span-id}-01") instead of
+// the bare trace ID.
+//
+// Example:
+//
+//	handler := trace.HTTP(ctx, trace.WithTraceIDResponseHeader(""))(mux)
+func WithTraceIDResponseHeader(header string) HTTPOption {
+	if header == "" {
+		header = DefaultTraceIDHeader
+	}
+	return func(o *httpOptions) {
+		o.echoHeader = header
+	}
+}
+
+// WithRequestHeaders returns an option that records the values of the given
+// request headers in server span attributes. The attributes are named after
+// the OpenTelemetry semantic conventions, for example the values of the
+// "X-Client-Version" header are recorded in the
+// "http.request.header.x_client_version" attribute. Values longer than the
+// maximum length (DefaultHeaderMaxLength unless set with
+// WithHeaderMaxLength) are truncated. Headers that are absent from the
+// request are not recorded.
+func WithRequestHeaders(headers ...string) HTTPOption {
+	return func(o *httpOptions) {
+		o.headers = append(o.headers, headers...)
+	}
+}
+
+// WithHeaderMaxLength returns an option that sets the maximum length of the
+// header values recorded with WithRequestHeaders.
+func WithHeaderMaxLength(max int) HTTPOption {
+	return func(o *httpOptions) {
+		o.headerMaxLen = max
+	}
+}
+
+// WithSuppressedPaths returns an option that disables tracing for requests made
+// to the given paths. Unlike NeverRoute the middleware does not create spans for
+// these requests at all, even when the caller traces them, and the trace
+// context is not propagated to downstream requests. Paths use the route
+// pattern syntax, e.g. "/health/{check}". DefaultSuppressedPaths is used when
+// no path is given.
+//
+// Example:
+//
+//	handler := trace.HTTP(ctx, trace.WithSuppressedPaths())(mux)
+func WithSuppressedPaths(paths ...string) HTTPOption {
+	if len(paths) == 0 {
+		paths = DefaultSuppressedPaths
+	}
+	return func(o *httpOptions) {
+		o.suppressed = append(o.suppressed, compileRoutes(paths)...)
+	}
+}
+
 // Message printed by panic when using a method with a non-initialized context.
 const errContextMissing = "context not initialized for tracing, use trace.Context to set it up"
 
 // HTTP returns a tracing middleware that uses a parent based sampler (i.e.
-// traces if the parent request traces) and an adaptive root sampler (i.e.  when
+// traces if the parent request traces) and an adaptive root sampler (i.e. when
 // there is no parent uses a target number of requests per second to trace).
 // The implementation leverages the OpenTelemetry SDK and can thus be configured
 // to send traces to an OpenTelemetry remote collector. It is aware of the Goa
 // RequestID middleware and will use it to propagate the request ID to the
 // trace. HTTP panics if the context hasn't been initialized with Context.
 //
-// Example:
+// Spans are named after the request method and route ("GET /users/{id}") when
+// the route can be resolved, see WithRouteResolver and WithRoutePatterns, or
+// after the Goa method, see WithSpanNaming. The route is also recorded in the
+// "http.route" attribute. Responses with a 5xx status code are recorded as
+// errors.
 //
-//      // Connect to remote trace collector.
-//      conn, err := grpc.DialContext(ctx, collectorAddr,
-//          grpc.WithTransportCrendentials(insecure.Credentials()))
-//      if err != nil {
-//          log.Error(ctx, err)
-//          os.Exit(1)
-//      }
-//      // Initialize context for tracing
-//      ctx := trace.Context(ctx, svcgen.ServiceName, trace.WithGRPCExporter(conn))
-//      // Mount middleware
-// 	handler := trace.HTTP(ctx)(mux)
+// The values of selected request headers can be recorded as span attributes
+// with WithRequestHeaders. Requests made to health check and metrics endpoints
+// can be excluded from tracing with WithSuppressedPaths. Requests made by
+// health checkers, uptime probes and load tests can be tagged with
+// WithSyntheticDetection. The trace ID of sampled requests can be returned to
+// clients with WithTraceIDResponseHeader. Support engineers can force the
+// sampling of a single request with WithDebugHeader.
 //
-func HTTP(ctx context.Context) func(http.Handler) http.Handler {
+// Example:
+//
+//	// Connect to remote trace collector.
+//	conn, err := grpc.DialContext(ctx, collectorAddr,
+//		grpc.WithTransportCredentials(insecure.NewCredentials()))
+//	if err != nil {
+//		log.Error(ctx, err)
+//		os.Exit(1)
+//	}
+//	// Initialize context for tracing
+//	ctx, err := trace.Context(ctx, svcgen.ServiceName, trace.WithExporter(exporter))
+//	// Mount middleware
+//	handler := trace.HTTP(ctx)(mux)
+func HTTP(ctx context.Context, opts ...HTTPOption) func(http.Handler) http.Handler {
 	s := ctx.Value(stateKey)
 	if s == nil {
 		panic(errContextMissing)
 	}
+	options := httpOptions{headerMaxLen: DefaultHeaderMaxLength}
+	for _, o := range opts {
+		o(&options)
+	}
 	return func(h http.Handler) http.Handler {
+		h = nameAndStatusHTTP(h, &options)
 		h = initTracingContext(ctx, h)
 		h = addRequestIDHTTP(h)
-		return otelhttp.NewHandler(h, s.(*stateBag).svc,
+		if len(options.headers) > 0 {
+			h = addHeadersHTTP(h, &options)
+		}
+		if len(options.synthetic) > 0 {
+			h = addSyntheticHTTP(h, &options)
+		}
+		if options.echoHeader != "" {
+			h = traceIDResponseHTTP(h, &options)
+		}
+		otelOpts := []otelhttp.Option{
 			otelhttp.WithTracerProvider(s.(*stateBag).provider),
-			otelhttp.WithPropagators(s.(*stateBag).propagator))
+			otelhttp.WithPropagators(s.(*stateBag).propagator),
+		}
+		if len(options.suppressed) > 0 {
+			otelOpts = append(otelOpts, otelhttp.WithFilter(options.filter))
+		}
+		h = otelhttp.NewHandler(h, s.(*stateBag).svc, otelOpts...)
+		if options.debugHeader != "" {
+			h = debugHeaderHTTP(h, &options)
+		}
+		return withRoute(h, &options)
 	}
 }
 
-// Client returns a roundtripper that wraps t and creates spans for each request.
-// It panics if the context hasn't been initialized with Context.
+// CaptureRoute is a HTTP middleware that records the request received by the
+// router handlers so that the route resolver configured with WithRouteResolver
+// resolves the route from it. It must be mounted on the router so that it runs
+// after routing, e.g. with the Use method of chi routers or of Goa muxers.
+// Requests handled by Goa endpoints that use the Endpoint middleware do not
+// require it.
+//
+// Example:
+//
+//	r := chi.NewRouter()
+//	r.Use(trace.CaptureRoute)
+//	resolver := func(r *http.Request) string { return chi.RouteContext(r.Context()).RoutePattern() }
+//	handler := trace.HTTP(ctx, trace.WithRouteResolver(resolver))(r)
+func CaptureRoute(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		recordHandledRequest(req.Context())
+		h.ServeHTTP(w, req)
+	})
+}
+
+// Client returns a roundtripper that wraps t and creates client spans for each
+// request. The spans record the peer name and port, the response status code
+// and the retry attempt number if any (see WithRetryAttempt). The roundtripper
+// injects the trace context in the request headers using the propagators
+// configured in ctx. It panics if the context hasn't been initialized with
+// Context.
 func Client(ctx context.Context, t http.RoundTripper, opts ...otelhttp.Option) http.RoundTripper {
 	s := ctx.Value(stateKey)
 	if s == nil {
@@ -59,7 +306,33 @@ func Client(ctx context.Context, t http.RoundTripper, opts ...otelhttp.Option) h
 	opts = append(opts,
 		otelhttp.WithTracerProvider(s.(*stateBag).provider),
 		otelhttp.WithPropagators(s.(*stateBag).propagator))
-	return otelhttp.NewTransport(t, opts...)
+	return otelhttp.NewTransport(&retryTransport{t}, opts...)
+}
+
+// WithRetryAttempt returns a context that records the given retry attempt
+// number. Retry logic should call WithRetryAttempt prior to resending a
+// request so that the corresponding client span records the attempt in the
+// "http.resend_count" attribute. The first retry is attempt 1.
+func WithRetryAttempt(ctx context.Context, attempt int) context.Context {
+	return context.WithValue(ctx, retryAttemptKey, attempt)
+}
+
+// retryTransport is a roundtripper that adds the retry attempt number to the
+// current span attributes.
+type retryTransport struct {
+	http.RoundTripper
+}
+
+// RoundTrip records the retry attempt number if any and executes the request.
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if attempt, ok := req.Context().Value(retryAttemptKey).(int); ok && attempt > 0 {
+		trace.SpanFromContext(req.Context()).SetAttributes(semconv.HTTPResendCount(attempt))
+	}
+	resp, err := t.RoundTripper.RoundTrip(req)
+	if err != nil && errors.Is(req.Context().Err(), context.DeadlineExceeded) {
+		RecordDeadlineExceeded(req.Context())
+	}
+	return resp, err
 }
 
 // addRequestIDHTTP is a middleware that adds the request ID to the current span
@@ -77,6 +350,69 @@ func addRequestIDHTTP(h http.Handler) http.Handler {
 	})
 }
 
+// traceIDResponseHTTP is a middleware that writes the trace ID of sampled
+// requests in the configured response header.
+func traceIDResponseHTTP(h http.Handler, options *httpOptions) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		sc := trace.SpanContextFromContext(req.Context())
+		if sc.IsSampled() {
+			v := sc.TraceID().String()
+			if strings.EqualFold(options.echoHeader, TraceResponseHeader) {
+				v = "00-" + v + "-" + sc.SpanID().String() + "-" + sc.TraceFlags().String()
+			}
+			w.Header().Set(options.echoHeader, v)
+		}
+		h.ServeHTTP(w, req)
+	})
+}
+
+// addHeadersHTTP is a middleware that adds the values of the configured request
+// headers to the current span attributes.
+func addHeadersHTTP(h http.Handler, options *httpOptions) http.Handler {
+	keys := make([]attribute.Key, len(options.headers))
+	for i, header := range options.headers {
+		name := strings.ReplaceAll(strings.ToLower(header), "-", "_")
+		keys[i] = attribute.Key("http.request.header." + name)
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		span := trace.SpanFromContext(req.Context())
+		if !span.IsRecording() {
+			h.ServeHTTP(w, req)
+			return
+		}
+		for i, header := range options.headers {
+			vals := req.Header.Values(header)
+			if len(vals) == 0 {
+				continue
+			}
+			capped := make([]string, len(vals))
+			for j, v := range vals {
+				if options.headerMaxLen > 0 && len(v) > options.headerMaxLen {
+					v = v[:options.headerMaxLen]
+				}
+				capped[j] = v
+			}
+			span.SetAttributes(keys[i].StringSlice(capped))
+		}
+		h.ServeHTTP(w, req)
+	})
+}
+
+// withRoute is a middleware that stores the request route in the request
+// context so that it is available to samplers configured with WithSampler.
+// The route is resolved using the route patterns if any, and defaults to the
+// request path.
+func withRoute(h http.Handler, options *httpOptions) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		route := options.patternRoute(req)
+		if route == "" {
+			route = req.URL.Path
+		}
+		ctx := context.WithValue(req.Context(), routeKey, route)
+		h.ServeHTTP(w, req.WithContext(ctx))
+	})
+}
+
 // initTracingContext is a middleware that adds the tracing state to the request
 // context.
 func initTracingContext(traceCtx context.Context, h http.Handler) http.Handler {
@@ -88,3 +424,104 @@ func initTracingContext(traceCtx context.Context, h http.Handler) http.Handler {
 		h.ServeHTTP(w, req)
 	})
 }
+
+// nameAndStatusHTTP is a middleware that names the current span after the
+// request route unless already named by the Endpoint middleware, records 5xx
+// responses as errors and records requests whose deadline is exceeded.
+func nameAndStatusHTTP(h http.Handler, options *httpOptions) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		rw := httpmdlwr.CaptureResponse(w)
+		var name *goaSpanName
+		if options.naming != SpanNamingRoute {
+			name = &goaSpanName{naming: options.naming}
+			req = req.WithContext(context.WithValue(req.Context(), spanNamingKey, name))
+		}
+		var handled *handledRequest
+		if options.resolver != nil {
+			handled = &handledRequest{}
+			req = req.WithContext(context.WithValue(req.Context(), handledRequestKey, handled))
+		}
+		h.ServeHTTP(rw, req)
+		span := trace.SpanFromContext(req.Context())
+		if !span.IsRecording() {
+			return
+		}
+		if route := options.route(req, handled); route != "" {
+			if name == nil || !name.named {
+				span.SetName(req.Method + " " + route)
+			}
+			span.SetAttributes(semconv.HTTPRoute(route))
+		}
+		if errors.Is(req.Context().Err(), context.DeadlineExceeded) {
+			RecordDeadlineExceeded(req.Context())
+		}
+		if rw.StatusCode >= http.StatusInternalServerError {
+			msg := http.StatusText(rw.StatusCode)
+			span.RecordError(errors.New(strings.ToLower(msg)))
+			span.SetStatus(codes.Error, msg)
+		}
+	})
+}
+
+// compileRoute returns the regular expression that matches the paths of the
+// given route pattern.
+func compileRoute(pattern string) *regexp.Regexp {
+	var b strings.Builder
+	b.WriteByte('^')
+	last := 0
+	for _, m := range wildSeg.FindAllStringIndex(pattern, -1) {
+		b.WriteString(regexp.QuoteMeta(pattern[last:m[0]]))
+		b.WriteString("/[^/]+")
+		last = m[1]
+	}
+	rest := pattern[last:]
+	if strings.HasSuffix(rest, "/*") {
+		b.WriteString(regexp.QuoteMeta(strings.TrimSuffix(rest, "/*")))
+		b.WriteString("(/.*)?$")
+		return regexp.MustCompile(b.String())
+	}
+	b.WriteString(regexp.QuoteMeta(rest))
+	b.WriteByte('$')
+	return regexp.MustCompile(b.String())
+}
+
+// route returns the route of the given request, empty string if it cannot be
+// resolved. The resolver is given the request received by the router handler
+// if recorded in handled, req otherwise.
+func (o *httpOptions) route(req *http.Request, handled *handledRequest) string {
+	if o.resolver != nil {
+		if handled != nil && handled.ctx != nil {
+			if route := o.resolver(req.WithContext(handled.ctx)); route != "" {
+				return route
+			}
+		}
+		if route := o.resolver(req); route != "" {
+			return route
+		}
+	}
+	return o.patternRoute(req)
+}
+
+// recordHandledRequest records ctx as the context of the request received by
+// the handler wrapped by the HTTP middleware if the middleware resolves routes.
+func recordHandledRequest(ctx context.Context) {
+	if handled, ok := ctx.Value(handledRequestKey).(*handledRequest); ok {
+		handled.ctx = ctx
+	}
+}
+
+// filter returns false if the request is made to a suppressed path.
+func (o *httpOptions) filter(req *http.Request) bool {
+	return !matchRoute(o.suppressed, req.URL.Path)
+}
+
+// patternRoute returns the first route pattern that matches the request path,
+// empty string if there is none.
+func (o *httpOptions) patternRoute(req *http.Request) string {
+	for _, p := range o.patterns {
+		if p.re.MatchString(req.URL.Path) {
+			return p.pattern
+		}
+	}
+	return ""
+}
@@ -2,13 +2,20 @@ package trace
 
 import (
 	"context"
+	"errors"
 	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	"go.opentelemetry.io/otel/sdk/trace/tracetest"
-	"goa.design/clue/internal/testsvc"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+	"goa.design/clue/testing/testsvc"
 	"goa.design/goa/v3/http/middleware"
 )
 
@@ -75,3 +82,304 @@ func TestClient(t *testing.T) {
 		t.Errorf("got %T, want %T", c.Transport, otelt)
 	}
 }
+
+func TestHTTPSpanName(t *testing.T) {
+	cases := []struct {
+		name     string
+		opts     []HTTPOption
+		expected string
+	}{
+		{"default", nil, "test"},
+		{"pattern", []HTTPOption{WithRoutePatterns("/other/{id}", "/{i}")}, "POST /{i}"},
+		{"no match", []HTTPOption{WithRoutePatterns("/other/{id}")}, "test"},
+		{"resolver", []HTTPOption{WithRouteResolver(func(*http.Request) string { return "/resolved" })}, "POST /resolved"},
+		{"empty resolver", []HTTPOption{WithRouteResolver(func(*http.Request) string { return "" }), WithRoutePatterns("/{i}")}, "POST /{i}"},
+		{"route naming", []HTTPOption{WithSpanNaming(SpanNamingRoute), WithRoutePatterns("/{i}")}, "POST /{i}"},
+		{"goa method naming", []HTTPOption{WithSpanNaming(SpanNamingGoaMethod), WithRoutePatterns("/{i}")}, "http_method"},
+		{"service method naming", []HTTPOption{WithSpanNaming(SpanNamingServiceMethod), WithRoutePatterns("/{i}")}, "test.http_method"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			exporter := tracetest.NewInMemoryExporter()
+			provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+			ctx := testContext(provider)
+			cli, stop := testsvc.SetupHTTP(t,
+				testsvc.WithHTTPMiddleware(HTTP(ctx, c.opts...)),
+				testsvc.WithHTTPFunc(func(ctx context.Context, f *testsvc.Fields) (*testsvc.Fields, error) {
+					res, err := Endpoint(func(ctx context.Context, req interface{}) (interface{}, error) {
+						return addEventUnaryMethod(ctx, req.(*testsvc.Fields))
+					})(ctx, f)
+					return res.(*testsvc.Fields), err
+				}))
+			if _, err := cli.HTTPMethod(context.Background(), &testsvc.Fields{}); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			stop()
+			spans := exporter.GetSpans()
+			if len(spans) != 1 {
+				t.Fatalf("got %d spans, want 1", len(spans))
+			}
+			if spans[0].Name != c.expected {
+				t.Errorf("got span name %q, want %q", spans[0].Name, c.expected)
+			}
+		})
+	}
+}
+
+func TestHTTPInnerRoute(t *testing.T) {
+	type routeKey struct{}
+	resolver := func(r *http.Request) string {
+		route, _ := r.Context().Value(routeKey{}).(string)
+		return route
+	}
+	endpoint := func(w http.ResponseWriter, r *http.Request) {
+		Endpoint(func(context.Context, interface{}) (interface{}, error) { return nil, nil })(r.Context(), nil) // nolint: errcheck
+	}
+	cases := []struct {
+		name     string
+		handler  http.Handler
+		expected string
+	}{
+		{"not captured", http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}), "test"},
+		{"capture route", CaptureRoute(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})), "GET /users/{id}"},
+		{"endpoint", http.HandlerFunc(endpoint), "GET /users/{id}"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			exporter := tracetest.NewInMemoryExporter()
+			provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+			ctx := testContext(provider)
+			// router stores the route in the context of the request given
+			// to the handler like httptreemux and chi do.
+			router := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				c.handler.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), routeKey{}, "/users/{id}")))
+			})
+			handler := HTTP(ctx, WithRouteResolver(resolver))(router)
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/users/42", nil))
+			spans := exporter.GetSpans()
+			if len(spans) != 1 {
+				t.Fatalf("got %d spans, want 1", len(spans))
+			}
+			if spans[0].Name != c.expected {
+				t.Errorf("got span name %q, want %q", spans[0].Name, c.expected)
+			}
+		})
+	}
+}
+
+func TestHTTPSampler(t *testing.T) {
+	cases := []struct {
+		name     string
+		opts     []SamplerOption
+		expected int
+	}{
+		{"default", []SamplerOption{ParentRatio(1)}, 1},
+		{"never", []SamplerOption{ParentRatio(1), NeverRoute("/{i}")}, 0},
+		{"always", []SamplerOption{ParentRatio(0), AlwaysRoute("/{i}")}, 1},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			exporter := tracetest.NewInMemoryExporter()
+			provider := sdktrace.NewTracerProvider(
+				sdktrace.WithSampler(newRouteSampler(nil, nil, c.opts...)),
+				sdktrace.WithSyncer(exporter))
+			ctx := testContext(provider)
+			cli, stop := testsvc.SetupHTTP(t,
+				testsvc.WithHTTPMiddleware(HTTP(ctx)),
+				testsvc.WithHTTPFunc(addEventUnaryMethod))
+			if _, err := cli.HTTPMethod(context.Background(), &testsvc.Fields{}); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			stop()
+			if got := len(exporter.GetSpans()); got != c.expected {
+				t.Errorf("got %d spans, want %d", got, c.expected)
+			}
+		})
+	}
+}
+
+func TestHTTPServerError(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	ctx := testContext(provider)
+	cli, stop := testsvc.SetupHTTP(t,
+		testsvc.WithHTTPMiddleware(HTTP(ctx)),
+		testsvc.WithHTTPFunc(func(context.Context, *testsvc.Fields) (*testsvc.Fields, error) {
+			return nil, errors.New("boom")
+		}))
+	if _, err := cli.HTTPMethod(context.Background(), &testsvc.Fields{}); err == nil {
+		t.Error("expected error")
+	}
+	stop()
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("got %d spans, want 1", len(spans))
+	}
+	if spans[0].Status.Code != codes.Error {
+		t.Errorf("got status %v, want %v", spans[0].Status.Code, codes.Error)
+	}
+	if len(spans[0].Events) != 1 || spans[0].Events[0].Name != "exception" {
+		t.Errorf("got events %v, want exception event", spans[0].Events)
+	}
+}
+
+func TestClientRetryAttempt(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	ctx := testContext(provider)
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("traceparent") == "" {
+			t.Error("missing traceparent header")
+		}
+	}))
+	defer svr.Close()
+	c := http.Client{Transport: Client(ctx, http.DefaultTransport)}
+
+	for attempt := 0; attempt < 2; attempt++ {
+		req, _ := http.NewRequestWithContext(WithRetryAttempt(context.Background(), attempt), "GET", svr.URL, nil)
+		resp, err := c.Do(req)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		resp.Body.Close()
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) != 2 {
+		t.Fatalf("got %d spans, want 2", len(spans))
+	}
+	for i, span := range spans {
+		var count int64
+		for _, att := range span.Attributes {
+			if att.Key == semconv.HTTPResendCountKey {
+				count = att.Value.AsInt64()
+			}
+		}
+		if count != int64(i) {
+			t.Errorf("span %d: got resend count %d, want %d", i, count, i)
+		}
+	}
+}
+
+func TestHTTPRequestHeaders(t *testing.T) {
+	cases := []struct {
+		name     string
+		opts     []HTTPOption
+		header   http.Header
+		expected map[attribute.Key][]string
+	}{
+		{"none", nil, http.Header{"X-Client-Version": {"1.0"}}, nil},
+		{"header", []HTTPOption{WithRequestHeaders("X-Client-Version")}, http.Header{"X-Client-Version": {"1.0"}},
+			map[attribute.Key][]string{"http.request.header.x_client_version": {"1.0"}}},
+		{"missing", []HTTPOption{WithRequestHeaders("X-Device-Id")}, http.Header{"X-Client-Version": {"1.0"}}, nil},
+		{"multiple values", []HTTPOption{WithRequestHeaders("x-device-id")}, http.Header{"X-Device-Id": {"a", "b"}},
+			map[attribute.Key][]string{"http.request.header.x_device_id": {"a", "b"}}},
+		{"capped", []HTTPOption{WithRequestHeaders("X-Device-Id"), WithHeaderMaxLength(3)}, http.Header{"X-Device-Id": {"abcdef"}},
+			map[attribute.Key][]string{"http.request.header.x_device_id": {"abc"}}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			exporter := tracetest.NewInMemoryExporter()
+			provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+			ctx := testContext(provider)
+			handler := HTTP(ctx, c.opts...)(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+			req := httptest.NewRequest("GET", "/", nil)
+			req.Header = c.header
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+			spans := exporter.GetSpans()
+			if len(spans) != 1 {
+				t.Fatalf("got %d spans, want 1", len(spans))
+			}
+			got := make(map[attribute.Key][]string)
+			for _, att := range spans[0].Attributes {
+				if strings.HasPrefix(string(att.Key), "http.request.header.") {
+					got[att.Key] = att.Value.AsStringSlice()
+				}
+			}
+			if len(got) != len(c.expected) {
+				t.Fatalf("got header attributes %v, want %v", got, c.expected)
+			}
+			for k, v := range c.expected {
+				if strings.Join(got[k], ",") != strings.Join(v, ",") {
+					t.Errorf("got %s=%v, want %v", k, got[k], v)
+				}
+			}
+		})
+	}
+}
+
+func TestHTTPSuppressedPaths(t *testing.T) {
+	cases := []struct {
+		name     string
+		opts     []HTTPOption
+		path     string
+		expected int
+	}{
+		{"none", nil, "/livez", 1},
+		{"default", []HTTPOption{WithSuppressedPaths()}, "/livez", 0},
+		{"default metrics", []HTTPOption{WithSuppressedPaths()}, "/metrics", 0},
+		{"default other", []HTTPOption{WithSuppressedPaths()}, "/users", 1},
+		{"custom", []HTTPOption{WithSuppressedPaths("/health/{check}")}, "/health/db", 0},
+		{"custom other", []HTTPOption{WithSuppressedPaths("/health/{check}")}, "/livez", 1},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			exporter := tracetest.NewInMemoryExporter()
+			provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+			ctx := testContext(provider)
+			var called bool
+			handler := HTTP(ctx, c.opts...)(http.HandlerFunc(func(http.ResponseWriter, *http.Request) { called = true }))
+			req := httptest.NewRequest("GET", c.path, nil)
+			req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+			if !called {
+				t.Error("handler not called")
+			}
+			if got := len(exporter.GetSpans()); got != c.expected {
+				t.Errorf("got %d spans, want %d", got, c.expected)
+			}
+		})
+	}
+}
+
+func TestHTTPTraceIDResponseHeader(t *testing.T) {
+	const traceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	cases := []struct {
+		name        string
+		header      string
+		traceparent string
+		expected    string
+	}{
+		{"default", "", traceparent, DefaultTraceIDHeader},
+		{"custom", "X-Request-Trace", traceparent, "X-Request-Trace"},
+		{"traceresponse", TraceResponseHeader, traceparent, TraceResponseHeader},
+		{"not sampled", "", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00", ""},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			provider := sdktrace.NewTracerProvider(sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.NeverSample())))
+			ctx := testContext(provider)
+			var sc trace.SpanContext
+			handler := HTTP(ctx, WithTraceIDResponseHeader(c.header))(http.HandlerFunc(func(_ http.ResponseWriter, req *http.Request) {
+				sc = trace.SpanContextFromContext(req.Context())
+			}))
+			req := httptest.NewRequest("GET", "/", nil)
+			req.Header.Set("traceparent", c.traceparent)
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+			if c.expected == "" {
+				if v := w.Header().Get(DefaultTraceIDHeader); v != "" {
+					t.Errorf("got header value %q, want none", v)
+				}
+				return
+			}
+			expected := "4bf92f3577b34da6a3ce929d0e0e4736"
+			if c.expected == TraceResponseHeader {
+				expected = "00-" + expected + "-" + sc.SpanID().String() + "-01"
+			}
+			if v := w.Header().Get(c.expected); v != expected {
+				t.Errorf("got header value %q, want %q", v, expected)
+			}
+		})
+	}
+}
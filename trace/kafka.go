@@ -0,0 +1,220 @@
+package trace
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type (
+	// KafkaHeader is a Kafka record header. Client library headers such as
+	// sarama.RecordHeader or kafka.Header (kafka-go, confluent-kafka-go)
+	// convert to and from KafkaHeader by copying the key and value.
+	KafkaHeader struct {
+		// Key is the header key.
+		Key string
+		// Value is the header value.
+		Value []byte
+	}
+
+	// KafkaHeaders is a list of Kafka record headers. *KafkaHeaders
+	// implements the OpenTelemetry TextMapCarrier interface.
+	KafkaHeaders []KafkaHeader
+
+	// KafkaMessage is a Kafka message produced or consumed.
+	KafkaMessage struct {
+		// Topic is the name of the Kafka topic.
+		Topic string
+		// Partition is the partition of the message, only used by
+		// consumers.
+		Partition int32
+		// Offset is the offset of the message, only used by consumers.
+		Offset int64
+		// Key is the message key, nil if the message has no key.
+		Key []byte
+		// Value is the message value.
+		Value []byte
+		// Headers is the list of message headers.
+		Headers KafkaHeaders
+	}
+
+	// KafkaProducer publishes messages to Kafka. Implementations typically
+	// wrap the producer of a Kafka client library (e.g. sarama or kafka-go).
+	KafkaProducer interface {
+		// Produce publishes the given message.
+		Produce(ctx context.Context, msg *KafkaMessage) error
+	}
+
+	// KafkaProducerFunc is a function that implements KafkaProducer.
+	KafkaProducerFunc func(ctx context.Context, msg *KafkaMessage) error
+
+	// KafkaHandler handles messages consumed from Kafka.
+	KafkaHandler func(ctx context.Context, msg *KafkaMessage) error
+
+	// kafkaProducer is a producer that creates producer spans.
+	kafkaProducer struct {
+		producer   KafkaProducer
+		tracer     trace.Tracer
+		propagator propagation.TextMapPropagator
+	}
+)
+
+// kafkaSystem is the value of the messaging.system attribute for Kafka.
+const kafkaSystem = "kafka"
+
+// Get returns the value of the header with the given key, empty string if
+// there is none.
+func (h *KafkaHeaders) Get(key string) string {
+	for _, header := range *h {
+		if header.Key == key {
+			return string(header.Value)
+		}
+	}
+	return ""
+}
+
+// Set sets the value of the header with the given key, replacing any existing
+// value.
+func (h *KafkaHeaders) Set(key, value string) {
+	for i, header := range *h {
+		if header.Key == key {
+			(*h)[i].Value = []byte(value)
+			return
+		}
+	}
+	*h = append(*h, KafkaHeader{Key: key, Value: []byte(value)})
+}
+
+// Keys returns the keys of the headers.
+func (h *KafkaHeaders) Keys() []string {
+	keys := make([]string, len(*h))
+	for i, header := range *h {
+		keys[i] = header.Key
+	}
+	return keys
+}
+
+// Produce calls f.
+func (f KafkaProducerFunc) Produce(ctx context.Context, msg *KafkaMessage) error {
+	return f(ctx, msg)
+}
+
+// InjectKafka injects the trace context of ctx into the given headers using the
+// propagators configured with Context. It does nothing if ctx has not been
+// initialized with Context.
+func InjectKafka(ctx context.Context, headers *KafkaHeaders) {
+	s := ctx.Value(stateKey)
+	if s == nil {
+		return
+	}
+	s.(*stateBag).propagator.Inject(ctx, headers)
+}
+
+// ExtractKafka returns a context containing the remote span context stored in
+// the given headers using the propagators configured with Context. It returns
+// ctx unchanged if ctx has not been initialized with Context.
+func ExtractKafka(ctx context.Context, headers KafkaHeaders) context.Context {
+	s := ctx.Value(stateKey)
+	if s == nil {
+		return ctx
+	}
+	return s.(*stateBag).propagator.Extract(ctx, &headers)
+}
+
+// WrapKafkaProducer returns a producer that creates a producer span for each
+// message published by p when the request is traced. The trace context is
+// injected in the message headers so that consumers may link their spans to
+// the producer span, see WrapKafkaHandler. The spans are named after the topic
+// ("orders publish") and record the messaging semantic convention attributes.
+// WrapKafkaProducer panics if traceCtx hasn't been initialized with Context.
+func WrapKafkaProducer(traceCtx context.Context, p KafkaProducer) KafkaProducer {
+	s := traceCtx.Value(stateKey)
+	if s == nil {
+		panic(errContextMissing)
+	}
+	return &kafkaProducer{
+		producer:   p,
+		tracer:     s.(*stateBag).provider.Tracer(InstrumentationLibraryName),
+		propagator: s.(*stateBag).propagator,
+	}
+}
+
+// Produce creates the producer span, injects the trace context in the message
+// headers and publishes the message.
+func (p *kafkaProducer) Produce(ctx context.Context, msg *KafkaMessage) error {
+	if !trace.SpanFromContext(ctx).IsRecording() {
+		return p.producer.Produce(ctx, msg)
+	}
+	opts := []trace.SpanStartOption{
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			semconv.MessagingSystem(kafkaSystem),
+			semconv.MessagingOperationPublish,
+			semconv.MessagingDestinationKindTopic,
+			semconv.MessagingDestinationName(msg.Topic),
+		),
+	}
+	if msg.Key != nil {
+		opts = append(opts, trace.WithAttributes(semconv.MessagingKafkaMessageKey(string(msg.Key))))
+	}
+	ctx, span := p.tracer.Start(ctx, msg.Topic+" publish", opts...)
+	defer span.End()
+	p.propagator.Inject(ctx, &msg.Headers)
+	err := p.producer.Produce(ctx, msg)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return err
+}
+
+// WrapKafkaHandler returns a handler that creates a consumer span for each
+// message before calling h. The span is linked to the producer span whose
+// trace context is stored in the message headers (see InjectKafka) rather
+// than being its child so that consumers processing messages long after they
+// were produced start their own traces. The spans are named after the topic
+// ("orders process") and record the messaging semantic convention attributes.
+// The context given to h is initialized for tracing so that StartSpan and
+// the other functions of this package may be used. WrapKafkaHandler panics
+// if traceCtx hasn't been initialized with Context.
+func WrapKafkaHandler(traceCtx context.Context, h KafkaHandler) KafkaHandler {
+	s := traceCtx.Value(stateKey)
+	if s == nil {
+		panic(errContextMissing)
+	}
+	tracer := s.(*stateBag).provider.Tracer(InstrumentationLibraryName)
+	return func(ctx context.Context, msg *KafkaMessage) error {
+		opts := []trace.SpanStartOption{
+			trace.WithSpanKind(trace.SpanKindConsumer),
+			trace.WithAttributes(
+				semconv.MessagingSystem(kafkaSystem),
+				semconv.MessagingOperationProcess,
+				semconv.MessagingSourceKindTopic,
+				semconv.MessagingSourceName(msg.Topic),
+				semconv.MessagingKafkaSourcePartition(int(msg.Partition)),
+				semconv.MessagingKafkaMessageOffset(int(msg.Offset)),
+			),
+		}
+		if msg.Key != nil {
+			opts = append(opts, trace.WithAttributes(semconv.MessagingKafkaMessageKey(string(msg.Key))))
+		}
+		remote := trace.SpanContextFromContext(ExtractKafka(traceCtx, msg.Headers))
+		if remote.IsValid() {
+			opts = append(opts, trace.WithLinks(trace.Link{SpanContext: remote}))
+		}
+		ctx, span := tracer.Start(ctx, msg.Topic+" process", opts...)
+		defer span.End()
+		if IsTraced(ctx) {
+			ctx = withTracing(traceCtx, ctx)
+		}
+		err := h(ctx, msg)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		return err
+	}
+}
@@ -0,0 +1,140 @@
+package trace
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestKafkaHeaders(t *testing.T) {
+	var headers KafkaHeaders
+	headers.Set("a", "1")
+	headers.Set("b", "2")
+	headers.Set("a", "3")
+	if got := headers.Get("a"); got != "3" {
+		t.Errorf("got %q, want 3", got)
+	}
+	if got := headers.Get("c"); got != "" {
+		t.Errorf("got %q, want empty", got)
+	}
+	if keys := headers.Keys(); len(keys) != 2 || keys[0] != "a" || keys[1] != "b" {
+		t.Errorf("got keys %v, want [a b]", keys)
+	}
+}
+
+func TestInjectExtractKafka(t *testing.T) {
+	provider := sdktrace.NewTracerProvider()
+	ctx := testContext(provider)
+	spanCtx, span := provider.Tracer("test").Start(ctx, "test")
+	defer span.End()
+
+	var headers KafkaHeaders
+	InjectKafka(spanCtx, &headers)
+	if headers.Get("traceparent") == "" {
+		t.Fatal("missing traceparent header")
+	}
+	sc := trace.SpanContextFromContext(ExtractKafka(ctx, headers))
+	if sc.TraceID() != span.SpanContext().TraceID() || sc.SpanID() != span.SpanContext().SpanID() {
+		t.Errorf("got span context %v, want %v", sc, span.SpanContext())
+	}
+	if !sc.IsRemote() {
+		t.Error("extracted span context is not remote")
+	}
+}
+
+func TestInjectExtractKafkaNotInitialized(t *testing.T) {
+	var headers KafkaHeaders
+	InjectKafka(context.Background(), &headers)
+	if len(headers) != 0 {
+		t.Errorf("got headers %v, want none", headers)
+	}
+	ctx := context.Background()
+	if got := ExtractKafka(ctx, KafkaHeaders{{Key: "traceparent", Value: []byte("x")}}); got != ctx {
+		t.Error("expected context to be unchanged")
+	}
+}
+
+func TestKafkaProducerAndHandler(t *testing.T) {
+	cases := []struct {
+		name string
+		err  error
+	}{
+		{"success", nil},
+		{"error", errors.New("boom")},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			exporter := tracetest.NewInMemoryExporter()
+			provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+			traceCtx := testContext(provider)
+			var produced *KafkaMessage
+			producer := WrapKafkaProducer(traceCtx, KafkaProducerFunc(func(_ context.Context, msg *KafkaMessage) error {
+				produced = msg
+				return c.err
+			}))
+			var handled bool
+			handler := WrapKafkaHandler(traceCtx, func(ctx context.Context, msg *KafkaMessage) error {
+				handled = true
+				ctx = StartSpan(ctx, "child")
+				EndSpan(ctx)
+				return c.err
+			})
+
+			ctx, parent := provider.Tracer("test").Start(context.Background(), "parent")
+			msg := &KafkaMessage{Topic: "orders", Key: []byte("k"), Value: []byte("v")}
+			if err := producer.Produce(ctx, msg); err != c.err {
+				t.Errorf("got error %v, want %v", err, c.err)
+			}
+			parent.End()
+			if err := handler(context.Background(), produced); err != c.err {
+				t.Errorf("got error %v, want %v", err, c.err)
+			}
+			if !handled {
+				t.Fatal("handler not called")
+			}
+
+			spans := exporter.GetSpans()
+			if len(spans) != 4 {
+				t.Fatalf("got %d spans, want 4", len(spans))
+			}
+			pub, child, proc := spans[0], spans[2], spans[3]
+			if pub.Name != "orders publish" || pub.SpanKind != trace.SpanKindProducer {
+				t.Errorf("got producer span %q (%v)", pub.Name, pub.SpanKind)
+			}
+			if proc.Name != "orders process" || proc.SpanKind != trace.SpanKindConsumer {
+				t.Errorf("got consumer span %q (%v)", proc.Name, proc.SpanKind)
+			}
+			if len(proc.Links) != 1 || proc.Links[0].SpanContext.SpanID() != pub.SpanContext.SpanID() {
+				t.Errorf("consumer span is not linked to producer span")
+			}
+			if child.Parent.SpanID() != proc.SpanContext.SpanID() {
+				t.Errorf("handler span is not a child of the consumer span")
+			}
+			wantCode := codes.Unset
+			if c.err != nil {
+				wantCode = codes.Error
+			}
+			if pub.Status.Code != wantCode || proc.Status.Code != wantCode {
+				t.Errorf("got status %v and %v, want %v", pub.Status.Code, proc.Status.Code, wantCode)
+			}
+		})
+	}
+}
+
+func TestKafkaProducerNotTraced(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	producer := WrapKafkaProducer(testContext(provider), KafkaProducerFunc(func(context.Context, *KafkaMessage) error { return nil }))
+	msg := &KafkaMessage{Topic: "orders"}
+	if err := producer.Produce(context.Background(), msg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(exporter.GetSpans()) != 0 || len(msg.Headers) != 0 {
+		t.Errorf("got %d spans and headers %v, want none", len(exporter.GetSpans()), msg.Headers)
+	}
+}
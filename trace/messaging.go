@@ -0,0 +1,165 @@
+package trace
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type (
+	// Message is a message consumed from a GCP Pub/Sub subscription or an
+	// AWS SQS queue.
+	Message struct {
+		// ID is the message ID.
+		ID string
+		// Source is the name of the Pub/Sub subscription or SQS queue
+		// the message was received from.
+		Source string
+		// Attributes contains the message attributes. SQS string
+		// message attributes map to their StringValue.
+		Attributes map[string]string
+		// Data is the message payload.
+		Data []byte
+	}
+
+	// MessageHandler handles messages consumed from GCP Pub/Sub or AWS SQS.
+	MessageHandler func(ctx context.Context, msg *Message) error
+)
+
+const (
+	// pubSubSystem is the value of the messaging.system attribute for GCP
+	// Pub/Sub.
+	pubSubSystem = "gcp_pubsub"
+	// sqsSystem is the value of the messaging.system attribute for AWS SQS.
+	sqsSystem = "aws_sqs"
+)
+
+// InjectPubSub injects the trace context of ctx into the given GCP Pub/Sub
+// message attributes using the propagators configured with Context and
+// returns the attributes. A new map is created if attrs is nil. It returns
+// attrs unchanged if ctx has not been initialized with Context.
+//
+// Example:
+//
+//	msg := &pubsub.Message{Data: data}
+//	msg.Attributes = trace.InjectPubSub(ctx, msg.Attributes)
+func InjectPubSub(ctx context.Context, attrs map[string]string) map[string]string {
+	return injectAttributes(ctx, attrs)
+}
+
+// ExtractPubSub returns a context containing the remote span context stored in
+// the given GCP Pub/Sub message attributes. It returns ctx unchanged if ctx
+// has not been initialized with Context.
+func ExtractPubSub(ctx context.Context, attrs map[string]string) context.Context {
+	return extractAttributes(ctx, attrs)
+}
+
+// InjectSQS injects the trace context of ctx into the given AWS SQS message
+// attributes, see InjectPubSub. Each key/value pair must be sent as a String
+// message attribute. Note that SQS limits messages to 10 attributes.
+func InjectSQS(ctx context.Context, attrs map[string]string) map[string]string {
+	return injectAttributes(ctx, attrs)
+}
+
+// ExtractSQS returns a context containing the remote span context stored in
+// the given AWS SQS message attributes, see ExtractPubSub.
+func ExtractSQS(ctx context.Context, attrs map[string]string) context.Context {
+	return extractAttributes(ctx, attrs)
+}
+
+// WrapPubSubHandler returns a handler that creates a consumer span for each
+// GCP Pub/Sub message before calling h. The span is a child of the span whose
+// context is stored in the message attributes (see InjectPubSub) so that
+// asynchronous workflows show up as connected traces. The spans are named
+// after the subscription ("orders process") and record the messaging semantic
+// convention attributes. The context given to h is initialized for tracing so
+// that StartSpan and the other functions of this package may be used.
+// WrapPubSubHandler panics if traceCtx hasn't been initialized with Context.
+//
+// Example:
+//
+//	handler := trace.WrapPubSubHandler(ctx, processOrder)
+//	err := sub.Receive(ctx, func(ctx context.Context, m *pubsub.Message) {
+//		msg := &trace.Message{ID: m.ID, Source: sub.ID(), Attributes: m.Attributes, Data: m.Data}
+//		if err := handler(ctx, msg); err != nil {
+//			m.Nack()
+//			return
+//		}
+//		m.Ack()
+//	})
+func WrapPubSubHandler(traceCtx context.Context, h MessageHandler) MessageHandler {
+	return wrapMessageHandler(traceCtx, pubSubSystem, semconv.MessagingSourceKindTopic, h)
+}
+
+// WrapSQSHandler returns a handler that creates a consumer span for each AWS
+// SQS message before calling h. The span is a child of the span whose context
+// is stored in the message attributes (see InjectSQS), see WrapPubSubHandler.
+// The spans are named after the queue. WrapSQSHandler panics if traceCtx
+// hasn't been initialized with Context.
+func WrapSQSHandler(traceCtx context.Context, h MessageHandler) MessageHandler {
+	return wrapMessageHandler(traceCtx, sqsSystem, semconv.MessagingSourceKindQueue, h)
+}
+
+// wrapMessageHandler returns a handler that creates a consumer span for each
+// message before calling h. kind is the messaging.source.kind attribute.
+func wrapMessageHandler(traceCtx context.Context, system string, kind attribute.KeyValue, h MessageHandler) MessageHandler {
+	s := traceCtx.Value(stateKey)
+	if s == nil {
+		panic(errContextMissing)
+	}
+	tracer := s.(*stateBag).provider.Tracer(InstrumentationLibraryName)
+	propagator := s.(*stateBag).propagator
+	return func(ctx context.Context, msg *Message) error {
+		ctx = propagator.Extract(ctx, propagation.MapCarrier(msg.Attributes))
+		opts := []trace.SpanStartOption{
+			trace.WithSpanKind(trace.SpanKindConsumer),
+			trace.WithAttributes(
+				semconv.MessagingSystem(system),
+				semconv.MessagingOperationProcess,
+				kind,
+				semconv.MessagingSourceName(msg.Source),
+			),
+		}
+		if msg.ID != "" {
+			opts = append(opts, trace.WithAttributes(semconv.MessagingMessageID(msg.ID)))
+		}
+		ctx, span := tracer.Start(ctx, msg.Source+" process", opts...)
+		defer span.End()
+		if IsTraced(ctx) {
+			ctx = withTracing(traceCtx, ctx)
+		}
+		err := h(ctx, msg)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		return err
+	}
+}
+
+// injectAttributes injects the trace context of ctx into attrs.
+func injectAttributes(ctx context.Context, attrs map[string]string) map[string]string {
+	s := ctx.Value(stateKey)
+	if s == nil {
+		return attrs
+	}
+	if attrs == nil {
+		attrs = make(map[string]string)
+	}
+	s.(*stateBag).propagator.Inject(ctx, propagation.MapCarrier(attrs))
+	return attrs
+}
+
+// extractAttributes returns a context containing the remote span context
+// stored in attrs.
+func extractAttributes(ctx context.Context, attrs map[string]string) context.Context {
+	s := ctx.Value(stateKey)
+	if s == nil {
+		return ctx
+	}
+	return s.(*stateBag).propagator.Extract(ctx, propagation.MapCarrier(attrs))
+}
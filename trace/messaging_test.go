@@ -0,0 +1,110 @@
+package trace
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestInjectExtractAttributes(t *testing.T) {
+	cases := []struct {
+		name    string
+		inject  func(context.Context, map[string]string) map[string]string
+		extract func(context.Context, map[string]string) context.Context
+	}{
+		{"pubsub", InjectPubSub, ExtractPubSub},
+		{"sqs", InjectSQS, ExtractSQS},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			provider := sdktrace.NewTracerProvider()
+			ctx := testContext(provider)
+			spanCtx, span := provider.Tracer("test").Start(ctx, "test")
+			defer span.End()
+
+			attrs := c.inject(spanCtx, nil)
+			if attrs["traceparent"] == "" {
+				t.Fatal("missing traceparent attribute")
+			}
+			sc := trace.SpanContextFromContext(c.extract(ctx, attrs))
+			if sc.SpanID() != span.SpanContext().SpanID() || !sc.IsRemote() {
+				t.Errorf("got span context %v, want remote %v", sc, span.SpanContext())
+			}
+
+			if got := c.inject(context.Background(), nil); got != nil {
+				t.Errorf("got attributes %v, want nil", got)
+			}
+		})
+	}
+}
+
+func TestWrapMessageHandler(t *testing.T) {
+	cases := []struct {
+		name         string
+		wrap         func(context.Context, MessageHandler) MessageHandler
+		inject       func(context.Context, map[string]string) map[string]string
+		expectedSys  string
+		expectedKind string
+		err          error
+	}{
+		{"pubsub", WrapPubSubHandler, InjectPubSub, "gcp_pubsub", "topic", nil},
+		{"sqs", WrapSQSHandler, InjectSQS, "aws_sqs", "queue", nil},
+		{"error", WrapSQSHandler, InjectSQS, "aws_sqs", "queue", errors.New("boom")},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			exporter := tracetest.NewInMemoryExporter()
+			provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+			traceCtx := testContext(provider)
+			handler := c.wrap(traceCtx, func(ctx context.Context, msg *Message) error {
+				ctx = StartSpan(ctx, "child")
+				EndSpan(ctx)
+				return c.err
+			})
+
+			ctx, parent := provider.Tracer("test").Start(traceCtx, "parent")
+			msg := &Message{ID: "1", Source: "orders", Attributes: c.inject(ctx, map[string]string{"a": "b"})}
+			parent.End()
+			if err := handler(context.Background(), msg); err != c.err {
+				t.Errorf("got error %v, want %v", err, c.err)
+			}
+
+			spans := exporter.GetSpans()
+			if len(spans) != 3 {
+				t.Fatalf("got %d spans, want 3", len(spans))
+			}
+			p, child, proc := spans[0], spans[1], spans[2]
+			if proc.Name != "orders process" || proc.SpanKind != trace.SpanKindConsumer {
+				t.Errorf("got consumer span %q (%v)", proc.Name, proc.SpanKind)
+			}
+			if proc.Parent.SpanID() != p.SpanContext.SpanID() || !proc.Parent.IsRemote() {
+				t.Error("consumer span is not a child of the remote parent span")
+			}
+			if child.Parent.SpanID() != proc.SpanContext.SpanID() {
+				t.Error("handler span is not a child of the consumer span")
+			}
+			attrs := make(map[string]string)
+			for _, att := range proc.Attributes {
+				attrs[string(att.Key)] = att.Value.Emit()
+			}
+			if attrs[string(semconv.MessagingSystemKey)] != c.expectedSys {
+				t.Errorf("got system %q, want %q", attrs[string(semconv.MessagingSystemKey)], c.expectedSys)
+			}
+			if attrs[string(semconv.MessagingSourceKindKey)] != c.expectedKind {
+				t.Errorf("got kind %q, want %q", attrs[string(semconv.MessagingSourceKindKey)], c.expectedKind)
+			}
+			if attrs[string(semconv.MessagingMessageIDKey)] != "1" {
+				t.Errorf("got message ID %q, want 1", attrs[string(semconv.MessagingMessageIDKey)])
+			}
+			if (c.err != nil) != (proc.Status.Code == codes.Error) {
+				t.Errorf("got status %v, want error %v", proc.Status.Code, c.err != nil)
+			}
+		})
+	}
+}
@@ -3,10 +3,12 @@ package trace
 import (
 	"context"
 
+	"go.opentelemetry.io/contrib/propagators/b3"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
 	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/sdk/resource"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/grpc"
 )
 
@@ -16,8 +18,16 @@ type (
 		sampleSize           int
 		exporter             sdktrace.SpanExporter
 		propagator           propagation.TextMapPropagator
+		b3                   bool
+		b3Options            []b3.Option
+		xray                 bool
 		parentSamplerOptions []sdktrace.ParentBasedSamplerOption
+		samplerOptions       []SamplerOption
+		tailSampling         bool
+		tailOptions          []TailOption
 		resource             *resource.Resource
+		provider             trace.TracerProvider
+		control              *Control
 		disabled             bool
 	}
 
@@ -67,6 +77,29 @@ func WithExporter(exporter sdktrace.SpanExporter) TraceOption {
 	}
 }
 
+// WithTracerProvider makes Context use the given tracer provider instead of
+// creating one, typically the provider created by clue.NewConfig. Sampling,
+// exporters and the resource are then configured with the provider: Context
+// returns an error if options that configure the provider it creates (e.g.
+// WithExporter, WithSampler or WithResource) are also given. See
+// clue.Config.TraceOptions.
+func WithTracerProvider(provider trace.TracerProvider) TraceOption {
+	return func(ctx context.Context, opts *options) error {
+		opts.provider = provider
+		return nil
+	}
+}
+
+// WithControl sets the runtime control returned by TracingControl. This is
+// useful when the tracer provider is given via WithTracerProvider and its
+// sampler uses the control, see ControlSampler.
+func WithControl(control *Control) TraceOption {
+	return func(ctx context.Context, opts *options) error {
+		opts.control = control
+		return nil
+	}
+}
+
 // WithParentSamplerOptions to set the options for sdktrace.ParentBased sampler.
 func WithParentSamplerOptions(samplerOptions ...sdktrace.ParentBasedSamplerOption) TraceOption {
 	return func(ctx context.Context, opts *options) error {
@@ -75,6 +108,35 @@ func WithParentSamplerOptions(samplerOptions ...sdktrace.ParentBasedSamplerOptio
 	}
 }
 
+// WithSampler configures the sampler used to decide which traces are
+// recorded. Options compose so that for example:
+//
+//	trace.WithSampler(
+//		trace.ParentRatio(0.1),
+//		trace.AlwaysRoute("/checkout"),
+//		trace.NeverRoute("/healthz", "/livez", "/metrics"),
+//	)
+//
+// samples all checkout requests, none of the health check and metrics
+// requests and 10% of the other requests that do not have a parent.
+func WithSampler(samplerOptions ...SamplerOption) TraceOption {
+	return func(ctx context.Context, opts *options) error {
+		opts.samplerOptions = append(opts.samplerOptions, samplerOptions...)
+		return nil
+	}
+}
+
+// WithTailSampling enables tail sampling, see NewTailSampler. The default root
+// sampler samples all traces when tail sampling is enabled so that all traces
+// are candidates, use WithSampler to override.
+func WithTailSampling(tailOptions ...TailOption) TraceOption {
+	return func(ctx context.Context, opts *options) error {
+		opts.tailSampling = true
+		opts.tailOptions = append(opts.tailOptions, tailOptions...)
+		return nil
+	}
+}
+
 // WithResource sets the underlying opentelemetry resource.
 func WithResource(res *resource.Resource) TraceOption {
 	return func(ctx context.Context, opts *options) error {
@@ -91,6 +153,28 @@ func WithPropagator(propagator propagation.TextMapPropagator) TraceOption {
 	}
 }
 
+// WithB3Propagation adds B3 propagation alongside the propagator set with
+// WithPropagator (W3C tracecontext by default). B3 headers are extracted using
+// both the single and multiple header encodings. They are injected using the
+// single header encoding by default, use b3.WithInjectEncoding to change this.
+func WithB3Propagation(b3Options ...b3.Option) TraceOption {
+	return func(ctx context.Context, opts *options) error {
+		opts.b3 = true
+		opts.b3Options = append(opts.b3Options, b3Options...)
+		return nil
+	}
+}
+
+// WithXRay adds AWS X-Ray propagation alongside the propagator set with
+// WithPropagator and generates X-Ray compatible trace IDs so that services
+// behind AWS load balancers and API gateways join the existing X-Ray traces.
+func WithXRay() TraceOption {
+	return func(ctx context.Context, opts *options) error {
+		opts.xray = true
+		return nil
+	}
+}
+
 func WithGRPCExporter(conn *grpc.ClientConn) TraceOption {
 	return func(ctx context.Context, opts *options) error {
 		exporter, err := otlptracegrpc.New(ctx, otlptracegrpc.WithGRPCConn(conn))
@@ -43,4 +43,23 @@ func TestOptions(t *testing.T) {
 	if total := len(options.parentSamplerOptions); total != 1 {
 		t.Errorf("got %d parent sampler options, expected 1", total)
 	}
+	WithSampler(ParentRatio(0.1), AlwaysRoute("/checkout"))(ctx, options)
+	if total := len(options.samplerOptions); total != 2 {
+		t.Errorf("got %d sampler options, expected 2", total)
+	}
+	WithB3Propagation()(ctx, options)
+	if !options.b3 {
+		t.Error("expected b3 to be true")
+	}
+	WithXRay()(ctx, options)
+	if !options.xray {
+		t.Error("expected xray to be true")
+	}
+	WithTailSampling(WithTailRatio(0.5))(ctx, options)
+	if !options.tailSampling {
+		t.Error("expected tail sampling to be true")
+	}
+	if total := len(options.tailOptions); total != 1 {
+		t.Errorf("got %d tail options, expected 1", total)
+	}
 }
@@ -0,0 +1,156 @@
+package trace
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// redisHook is a go-redis hook that creates spans for commands and pipelines.
+type redisHook struct {
+	tracer trace.Tracer
+}
+
+const (
+	// AttributeRedisKeyCount is the name of the span attribute that
+	// contains the number of keys used by a Redis command or pipeline.
+	AttributeRedisKeyCount = "db.redis.key_count"
+	// AttributeRedisPipelineLength is the name of the span attribute that
+	// contains the number of commands in a Redis pipeline.
+	AttributeRedisPipelineLength = "db.redis.pipeline_length"
+)
+
+var (
+	// redisMultiKeyCommands lists the commands whose arguments are all keys.
+	redisMultiKeyCommands = map[string]bool{
+		"del": true, "exists": true, "mget": true, "touch": true,
+		"unlink": true, "watch": true, "sinter": true, "sunion": true,
+		"sdiff": true, "pfcount": true,
+	}
+	// redisKeyValueCommands lists the commands whose arguments are
+	// alternating keys and values.
+	redisKeyValueCommands = map[string]bool{"mset": true, "msetnx": true}
+	// redisNoKeyCommands lists the commands that do not use keys.
+	redisNoKeyCommands = map[string]bool{
+		"ping": true, "echo": true, "info": true, "auth": true, "select": true,
+		"hello": true, "client": true, "cluster": true, "command": true,
+		"config": true, "dbsize": true, "flushall": true, "flushdb": true,
+		"keys": true, "scan": true, "time": true, "publish": true,
+		"multi": true, "exec": true, "discard": true, "script": true,
+		"readonly": true, "quit": true,
+	}
+)
+
+// RedisHook returns a go-redis hook that creates client spans for each command
+// and pipeline. Command spans are named after the command (e.g. "GET") and
+// pipeline spans are named "pipeline". Spans record the number of keys used,
+// the number of commands for pipelines and errors. redis.Nil is not recorded as
+// an error. Dialing new connections creates "redis.dial" spans. Spans are only
+// created for requests that are traced. RedisHook panics if the context
+// hasn't been initialized with Context.
+//
+// Example:
+//
+//	rdb := redis.NewClient(&redis.Options{Addr: addr})
+//	rdb.AddHook(trace.RedisHook(ctx))
+func RedisHook(ctx context.Context) redis.Hook {
+	s := ctx.Value(stateKey)
+	if s == nil {
+		panic(errContextMissing)
+	}
+	return &redisHook{tracer: s.(*stateBag).provider.Tracer(InstrumentationLibraryName)}
+}
+
+// DialHook traces connection dials.
+func (h *redisHook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		if !trace.SpanFromContext(ctx).IsRecording() {
+			return next(ctx, network, addr)
+		}
+		ctx, span := h.start(ctx, "redis.dial")
+		conn, err := next(ctx, network, addr)
+		endRedisSpan(span, err)
+		return conn, err
+	}
+}
+
+// ProcessHook traces commands.
+func (h *redisHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		if !trace.SpanFromContext(ctx).IsRecording() {
+			return next(ctx, cmd)
+		}
+		op := strings.ToUpper(cmd.FullName())
+		ctx, span := h.start(ctx, op,
+			semconv.DBOperation(op),
+			attribute.Int(AttributeRedisKeyCount, redisKeyCount(cmd)))
+		err := next(ctx, cmd)
+		endRedisSpan(span, err)
+		return err
+	}
+}
+
+// ProcessPipelineHook traces pipelines.
+func (h *redisHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		if !trace.SpanFromContext(ctx).IsRecording() {
+			return next(ctx, cmds)
+		}
+		var keys int
+		for _, cmd := range cmds {
+			keys += redisKeyCount(cmd)
+		}
+		ctx, span := h.start(ctx, "pipeline",
+			attribute.Int(AttributeRedisPipelineLength, len(cmds)),
+			attribute.Int(AttributeRedisKeyCount, keys))
+		err := next(ctx, cmds)
+		if err == nil {
+			for _, cmd := range cmds {
+				if cerr := cmd.Err(); cerr != nil && !errors.Is(cerr, redis.Nil) {
+					err = cerr
+					break
+				}
+			}
+		}
+		endRedisSpan(span, err)
+		return err
+	}
+}
+
+// start starts a Redis client span.
+func (h *redisHook) start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	attrs = append(attrs, semconv.DBSystemRedis)
+	return h.tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+}
+
+// endRedisSpan records err if not nil or redis.Nil and ends the span.
+func endRedisSpan(span trace.Span, err error) {
+	if err != nil && !errors.Is(err, redis.Nil) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// redisKeyCount returns the number of keys used by the given command.
+func redisKeyCount(cmd redis.Cmder) int {
+	name := cmd.Name()
+	args := len(cmd.Args()) - 1
+	switch {
+	case args <= 0 || redisNoKeyCommands[name]:
+		return 0
+	case redisMultiKeyCommands[name]:
+		return args
+	case redisKeyValueCommands[name]:
+		return args / 2
+	default:
+		return 1
+	}
+}
@@ -0,0 +1,105 @@
+package trace
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestRedisHook(t *testing.T) {
+	cases := []struct {
+		name         string
+		run          func(ctx context.Context, h redis.Hook) error
+		expectedName string
+		expectedKeys int64
+		expectedErr  bool
+	}{
+		{"get", func(ctx context.Context, h redis.Hook) error {
+			return h.ProcessHook(noopProcess(nil))(ctx, redis.NewStringCmd(ctx, "get", "k"))
+		}, "GET", 1, false},
+		{"mget", func(ctx context.Context, h redis.Hook) error {
+			return h.ProcessHook(noopProcess(nil))(ctx, redis.NewSliceCmd(ctx, "mget", "k1", "k2", "k3"))
+		}, "MGET", 3, false},
+		{"mset", func(ctx context.Context, h redis.Hook) error {
+			return h.ProcessHook(noopProcess(nil))(ctx, redis.NewStatusCmd(ctx, "mset", "k1", "v1", "k2", "v2"))
+		}, "MSET", 2, false},
+		{"ping", func(ctx context.Context, h redis.Hook) error {
+			return h.ProcessHook(noopProcess(nil))(ctx, redis.NewStatusCmd(ctx, "ping"))
+		}, "PING", 0, false},
+		{"nil", func(ctx context.Context, h redis.Hook) error {
+			return h.ProcessHook(noopProcess(redis.Nil))(ctx, redis.NewStringCmd(ctx, "get", "k"))
+		}, "GET", 1, false},
+		{"error", func(ctx context.Context, h redis.Hook) error {
+			return h.ProcessHook(noopProcess(errors.New("boom")))(ctx, redis.NewStringCmd(ctx, "get", "k"))
+		}, "GET", 1, true},
+		{"pipeline", func(ctx context.Context, h redis.Hook) error {
+			cmds := []redis.Cmder{redis.NewStringCmd(ctx, "get", "k1"), redis.NewStatusCmd(ctx, "set", "k2", "v")}
+			return h.ProcessPipelineHook(func(context.Context, []redis.Cmder) error { return nil })(ctx, cmds)
+		}, "pipeline", 2, false},
+		{"pipeline error", func(ctx context.Context, h redis.Hook) error {
+			cmd := redis.NewStringCmd(ctx, "get", "k1")
+			cmd.SetErr(errors.New("boom"))
+			return h.ProcessPipelineHook(func(context.Context, []redis.Cmder) error { return nil })(ctx, []redis.Cmder{cmd})
+		}, "pipeline", 1, true},
+		{"dial", func(ctx context.Context, h redis.Hook) error {
+			_, err := h.DialHook(func(context.Context, string, string) (net.Conn, error) { return nil, nil })(ctx, "tcp", "addr")
+			return err
+		}, "redis.dial", -1, false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			exporter := tracetest.NewInMemoryExporter()
+			provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+			hook := RedisHook(testContext(provider))
+			ctx, span := provider.Tracer("test").Start(context.Background(), "parent")
+			_ = c.run(ctx, hook)
+			span.End()
+
+			spans := exporter.GetSpans()
+			if len(spans) != 2 {
+				t.Fatalf("got %d spans, want 2", len(spans))
+			}
+			s := spans[0]
+			if s.Name != c.expectedName {
+				t.Errorf("got span name %q, want %q", s.Name, c.expectedName)
+			}
+			if c.expectedKeys >= 0 {
+				var keys int64 = -1
+				for _, att := range s.Attributes {
+					if att.Key == AttributeRedisKeyCount {
+						keys = att.Value.AsInt64()
+					}
+				}
+				if keys != c.expectedKeys {
+					t.Errorf("got key count %d, want %d", keys, c.expectedKeys)
+				}
+			}
+			if c.expectedErr != (s.Status.Code == codes.Error) {
+				t.Errorf("got status %v, want error %v", s.Status.Code, c.expectedErr)
+			}
+		})
+	}
+}
+
+func TestRedisHookNotTraced(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	hook := RedisHook(testContext(provider))
+	ctx := context.Background()
+	if err := hook.ProcessHook(noopProcess(nil))(ctx, redis.NewStringCmd(ctx, "get", "k")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := len(exporter.GetSpans()); got != 0 {
+		t.Errorf("got %d spans, want 0", got)
+	}
+}
+
+func noopProcess(err error) redis.ProcessHook {
+	return func(context.Context, redis.Cmder) error { return err }
+}
@@ -2,8 +2,11 @@ package trace
 
 import (
 	"fmt"
+	"strings"
+	"sync"
 
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
 	"go.opentelemetry.io/otel/trace"
 	"goa.design/goa/v3/middleware"
 )
@@ -15,15 +18,16 @@ type sampler struct {
 	sampleSize      int
 }
 
-// adaptiveSampler computes the interval for sampling for tracing middleware.
-// it can also be used by non-web go routines to trace internal API calls.
+// AdaptiveSampler returns a sampler that computes the interval for sampling
+// for tracing middleware. It can also be used by non-web go routines to trace
+// internal API calls.
 //
 // maxSamplingRate is the desired maximum sampling rate in requests per second.
 //
 // sampleSize sets the number of requests between two adjustments of the
 // sampling rate when MaxSamplingRate is set. the sample rate cannot be adjusted
 // until the sample size is reached at least once.
-func adaptiveSampler(maxSamplingRate, sampleSize int) sdktrace.Sampler {
+func AdaptiveSampler(maxSamplingRate, sampleSize int) sdktrace.Sampler {
 	return sampler{
 		s:               middleware.NewAdaptiveSampler(maxSamplingRate, sampleSize),
 		maxSamplingRate: maxSamplingRate,
@@ -48,3 +52,266 @@ func (s sampler) ShouldSample(p sdktrace.SamplingParameters) sdktrace.SamplingRe
 		Tracestate: psc.TraceState(),
 	}
 }
+
+type (
+	// SamplerOption is a function that configures the sampler created by
+	// WithSampler.
+	SamplerOption func(*samplerOptions)
+
+	samplerOptions struct {
+		root   sdktrace.Sampler
+		always []*routePattern
+		never  []*routePattern
+		ratios []*routeRatio
+	}
+
+	// routeRatio is the sampling ratio of the requests made to a set of
+	// routes.
+	routeRatio struct {
+		fraction float64
+		routes   []*routePattern
+		sampler  sdktrace.Sampler
+	}
+
+	// adaptiveRouteSampler uses one adaptive sampler per route.
+	adaptiveRouteSampler struct {
+		maxSamplingRate int
+		sampleSize      int
+		lock            sync.Mutex
+		samplers        map[string]middleware.Sampler
+	}
+
+	// routeSampler samples the spans of specific routes unconditionally and
+	// delegates to a parent based sampler otherwise.
+	routeSampler struct {
+		always []*routePattern
+		never  []*routePattern
+		ratios []*routeRatio
+		next   sdktrace.Sampler
+	}
+)
+
+// ParentRatio returns a sampler option that samples the given fraction of the
+// traces that do not have a parent using the trace ID. Spans with a parent are
+// sampled if the parent is. The default root sampler is the adaptive sampler
+// configured with WithMaxSamplingRate and WithSampleSize.
+func ParentRatio(fraction float64) SamplerOption {
+	return func(o *samplerOptions) {
+		o.root = sdktrace.TraceIDRatioBased(fraction)
+	}
+}
+
+// AdaptiveRoute returns a sampler option that samples the traces that do not
+// have a parent using one adaptive sampler per route. Each sampler targets at
+// most maxSamplingRate sampled traces per second for its route and adjusts its
+// sampling probability every sampleSize requests, so that traffic spikes on one
+// route neither blow up tracing costs nor starve the other routes. See
+// AlwaysRoute for how routes are determined.
+func AdaptiveRoute(maxSamplingRate, sampleSize int) SamplerOption {
+	return func(o *samplerOptions) {
+		o.root = &adaptiveRouteSampler{
+			maxSamplingRate: maxSamplingRate,
+			sampleSize:      sampleSize,
+			samplers:        make(map[string]middleware.Sampler),
+		}
+	}
+}
+
+// AlwaysRoute returns a sampler option that samples all the requests made to
+// the given routes regardless of the parent sampling decision. Routes are
+// matched against the HTTP request path and use the same syntax as
+// WithRoutePatterns, e.g. "/users/{id}". A trailing "/*" matches any path
+// under the prefix, e.g. "/payments/*". Spans that are not created by the
+// HTTP middleware are matched using their name, e.g.
+// "grpc.health.v1.Health/Check" for gRPC requests.
+func AlwaysRoute(routes ...string) SamplerOption {
+	return func(o *samplerOptions) {
+		o.always = append(o.always, compileRoutes(routes)...)
+	}
+}
+
+// NeverRoute returns a sampler option that never samples the requests made to
+// the given routes, typically health check and metrics endpoints. See
+// AlwaysRoute for the route syntax. NeverRoute takes precedence over
+// AlwaysRoute.
+func NeverRoute(routes ...string) SamplerOption {
+	return func(o *samplerOptions) {
+		o.never = append(o.never, compileRoutes(routes)...)
+	}
+}
+
+// RouteRatio returns a sampler option that samples the given fraction of the
+// requests made to the given routes that do not have a parent using the trace
+// ID, for example to sample all payment requests and only a small fraction of
+// search requests. Requests with a parent are sampled if the parent is. See
+// AlwaysRoute for the route syntax. When a route matches the routes of
+// multiple RouteRatio options the first option applies. AlwaysRoute and
+// NeverRoute take precedence over RouteRatio.
+//
+// Example:
+//
+//	trace.WithSampler(
+//		trace.RouteRatio(1, "/payments/*"),
+//		trace.RouteRatio(0.01, "/search"),
+//	)
+func RouteRatio(fraction float64, routes ...string) SamplerOption {
+	return func(o *samplerOptions) {
+		o.ratios = append(o.ratios, &routeRatio{
+			fraction: fraction,
+			routes:   compileRoutes(routes),
+			sampler:  sdktrace.TraceIDRatioBased(fraction),
+		})
+	}
+}
+
+// RouteSampler returns a parent based sampler configured with the given
+// options, see WithSampler. root is the sampler used for requests that do not
+// have a parent unless overridden via the options. Requests whose sampling is
+// forced with the debug header are always sampled, see WithDebugHeader.
+// RouteSampler makes it possible to use the sampler options and the debug
+// header with tracer providers that are not created by Context.
+//
+// Example:
+//
+//	sampler := trace.RouteSampler(trace.AdaptiveSampler(2, 10),
+//		trace.RouteRatio(1, "/payments/*"))
+//	provider := sdktrace.NewTracerProvider(sdktrace.WithSampler(sampler))
+func RouteSampler(root sdktrace.Sampler, opts ...SamplerOption) sdktrace.Sampler {
+	return debugSampler{newRouteSampler(root, nil, opts...)}
+}
+
+// newRouteSampler returns a sampler configured with the given options. root
+// is the root sampler used when none is set via the options.
+func newRouteSampler(root sdktrace.Sampler, pbOpts []sdktrace.ParentBasedSamplerOption, opts ...SamplerOption) sdktrace.Sampler {
+	o := &samplerOptions{root: root}
+	for _, opt := range opts {
+		opt(o)
+	}
+	next := sdktrace.ParentBased(o.root, pbOpts...)
+	if len(o.always) == 0 && len(o.never) == 0 && len(o.ratios) == 0 {
+		return next
+	}
+	return routeSampler{always: o.always, never: o.never, ratios: o.ratios, next: next}
+}
+
+// Description returns the description of the sampler.
+func (s routeSampler) Description() string {
+	var ratios string
+	if len(s.ratios) > 0 {
+		rs := make([]string, len(s.ratios))
+		for i, r := range s.ratios {
+			rs[i] = fmt.Sprintf("%v:%g", patterns(r.routes), r.fraction)
+		}
+		ratios = fmt.Sprintf(",ratios:%v", rs)
+	}
+	return fmt.Sprintf("Route{always:%v,never:%v%s,next:%s}",
+		patterns(s.always), patterns(s.never), ratios, s.next.Description())
+}
+
+// ShouldSample returns the sampling decision for the given parameters.
+func (s routeSampler) ShouldSample(p sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	route := spanRoute(p)
+	psc := trace.SpanContextFromContext(p.ParentContext)
+	if matchRoute(s.never, route) {
+		return sdktrace.SamplingResult{Decision: sdktrace.Drop, Tracestate: psc.TraceState()}
+	}
+	if matchRoute(s.always, route) {
+		return sdktrace.SamplingResult{Decision: sdktrace.RecordAndSample, Tracestate: psc.TraceState()}
+	}
+	if !psc.IsValid() {
+		for _, r := range s.ratios {
+			if matchRoute(r.routes, route) {
+				return r.sampler.ShouldSample(p)
+			}
+		}
+	}
+	return s.next.ShouldSample(p)
+}
+
+// maxRoutes is the maximum number of routes tracked by the adaptive route
+// sampler, additional routes share the same sampler.
+const maxRoutes = 1000
+
+// Description returns the description of the sampler.
+func (s *adaptiveRouteSampler) Description() string {
+	return fmt.Sprintf("AdaptiveRoute{maxSamplingRate:%d,sampleSize:%d}", s.maxSamplingRate, s.sampleSize)
+}
+
+// ShouldSample returns the sampling decision for the given parameters.
+func (s *adaptiveRouteSampler) ShouldSample(p sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	if !s.sampler(spanRoute(p)).Sample() {
+		return sdktrace.SamplingResult{Decision: sdktrace.Drop}
+	}
+	psc := trace.SpanContextFromContext(p.ParentContext)
+	return sdktrace.SamplingResult{
+		Decision:   sdktrace.RecordAndSample,
+		Tracestate: psc.TraceState(),
+	}
+}
+
+// sampler returns the adaptive sampler for the given route, creating it if
+// needed.
+func (s *adaptiveRouteSampler) sampler(route string) middleware.Sampler {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if sampler, ok := s.samplers[route]; ok {
+		return sampler
+	}
+	if len(s.samplers) >= maxRoutes {
+		route = ""
+		if sampler, ok := s.samplers[route]; ok {
+			return sampler
+		}
+	}
+	sampler := middleware.NewAdaptiveSampler(s.maxSamplingRate, s.sampleSize)
+	s.samplers[route] = sampler
+	return sampler
+}
+
+// spanRoute returns the route of the request that created the span being
+// sampled: the route stored in the context by the HTTP middleware if any, the
+// path of the "http.target" attribute set by the OpenTelemetry HTTP
+// instrumentation if any, the span name otherwise.
+func spanRoute(p sdktrace.SamplingParameters) string {
+	if route, ok := p.ParentContext.Value(routeKey).(string); ok {
+		return route
+	}
+	for _, attr := range p.Attributes {
+		if attr.Key == semconv.HTTPTargetKey {
+			target := attr.Value.AsString()
+			if i := strings.IndexByte(target, '?'); i >= 0 {
+				target = target[:i]
+			}
+			return target
+		}
+	}
+	return p.Name
+}
+
+// compileRoutes compiles the given route patterns.
+func compileRoutes(routes []string) []*routePattern {
+	res := make([]*routePattern, len(routes))
+	for i, r := range routes {
+		res[i] = &routePattern{pattern: r, re: compileRoute(r)}
+	}
+	return res
+}
+
+// matchRoute returns true if route matches one of the given patterns.
+func matchRoute(patterns []*routePattern, route string) bool {
+	for _, p := range patterns {
+		if p.re.MatchString(route) {
+			return true
+		}
+	}
+	return false
+}
+
+// patterns returns the patterns of the given route patterns.
+func patterns(rps []*routePattern) []string {
+	res := make([]string, len(rps))
+	for i, rp := range rps {
+		res[i] = rp.pattern
+	}
+	return res
+}
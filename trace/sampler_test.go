@@ -1,14 +1,18 @@
 package trace
 
 import (
+	"context"
 	"testing"
 
+	"go.opentelemetry.io/otel/attribute"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
 func TestAdaptiveSampler(t *testing.T) {
 	// We don't need to test Goa, keep it simple...
-	s := adaptiveSampler(2, 10)
+	s := AdaptiveSampler(2, 10)
 	expected := "Adaptive{maxSamplingRate:2,sampleSize:10}"
 	if s.Description() != expected {
 		t.Fatalf("got description %q, expected %q", s.Description(), expected)
@@ -18,7 +22,7 @@ func TestAdaptiveSampler(t *testing.T) {
 		t.Error("expected sampling")
 	}
 
-	s2 := adaptiveSampler(1, 2)
+	s2 := AdaptiveSampler(1, 2)
 	expected = "Adaptive{maxSamplingRate:1,sampleSize:2}"
 	if s2.Description() != expected {
 		t.Fatalf("got description %q, expected %q", s2.Description(), expected)
@@ -32,3 +36,111 @@ func TestAdaptiveSampler(t *testing.T) {
 		t.Error("expected no sampling")
 	}
 }
+
+func TestRouteSampler(t *testing.T) {
+	sampled := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{1},
+		SpanID:     trace.SpanID{1},
+		TraceFlags: trace.FlagsSampled,
+	})
+	parentCtx := trace.ContextWithSpanContext(context.Background(), sampled)
+	routeCtx := func(ctx context.Context, route string) context.Context {
+		return context.WithValue(ctx, routeKey, route)
+	}
+	cases := []struct {
+		name     string
+		opts     []SamplerOption
+		ctx      context.Context
+		span     string
+		expected sdktrace.SamplingDecision
+	}{
+		{"ratio zero", []SamplerOption{ParentRatio(0)}, context.Background(), "span", sdktrace.Drop},
+		{"ratio one", []SamplerOption{ParentRatio(1)}, context.Background(), "span", sdktrace.RecordAndSample},
+		{"ratio sampled parent", []SamplerOption{ParentRatio(0)}, parentCtx, "span", sdktrace.RecordAndSample},
+		{"always route", []SamplerOption{ParentRatio(0), AlwaysRoute("/checkout")}, routeCtx(context.Background(), "/checkout"), "span", sdktrace.RecordAndSample},
+		{"always pattern", []SamplerOption{ParentRatio(0), AlwaysRoute("/users/{id}")}, routeCtx(context.Background(), "/users/42"), "span", sdktrace.RecordAndSample},
+		{"always other route", []SamplerOption{ParentRatio(0), AlwaysRoute("/checkout")}, routeCtx(context.Background(), "/cart"), "span", sdktrace.Drop},
+		{"always span name", []SamplerOption{ParentRatio(0), AlwaysRoute("svc.Service/Method")}, context.Background(), "svc.Service/Method", sdktrace.RecordAndSample},
+		{"never route", []SamplerOption{ParentRatio(1), NeverRoute("/healthz", "/metrics")}, routeCtx(context.Background(), "/metrics"), "span", sdktrace.Drop},
+		{"never sampled parent", []SamplerOption{ParentRatio(1), NeverRoute("/healthz")}, routeCtx(parentCtx, "/healthz"), "span", sdktrace.Drop},
+		{"never precedence", []SamplerOption{AlwaysRoute("/healthz"), NeverRoute("/healthz")}, routeCtx(context.Background(), "/healthz"), "span", sdktrace.Drop},
+		{"always prefix", []SamplerOption{ParentRatio(0), AlwaysRoute("/payments/*")}, routeCtx(context.Background(), "/payments/42/refund"), "span", sdktrace.RecordAndSample},
+		{"always prefix root", []SamplerOption{ParentRatio(0), AlwaysRoute("/payments/*")}, routeCtx(context.Background(), "/payments"), "span", sdktrace.RecordAndSample},
+		{"always prefix other", []SamplerOption{ParentRatio(0), AlwaysRoute("/payments/*")}, routeCtx(context.Background(), "/paymentsx"), "span", sdktrace.Drop},
+		{"route ratio one", []SamplerOption{ParentRatio(0), RouteRatio(1, "/payments/*")}, routeCtx(context.Background(), "/payments/42"), "span", sdktrace.RecordAndSample},
+		{"route ratio zero", []SamplerOption{ParentRatio(1), RouteRatio(0, "/search")}, routeCtx(context.Background(), "/search"), "span", sdktrace.Drop},
+		{"route ratio other route", []SamplerOption{ParentRatio(1), RouteRatio(0, "/search")}, routeCtx(context.Background(), "/cart"), "span", sdktrace.RecordAndSample},
+		{"route ratio sampled parent", []SamplerOption{RouteRatio(0, "/search")}, routeCtx(parentCtx, "/search"), "span", sdktrace.RecordAndSample},
+		{"route ratio first match", []SamplerOption{RouteRatio(1, "/search"), RouteRatio(0, "/search")}, routeCtx(context.Background(), "/search"), "span", sdktrace.RecordAndSample},
+		{"route ratio never precedence", []SamplerOption{RouteRatio(1, "/healthz"), NeverRoute("/healthz")}, routeCtx(context.Background(), "/healthz"), "span", sdktrace.Drop},
+		{"route ratio span name", []SamplerOption{ParentRatio(1), RouteRatio(0, "svc.Service/*")}, context.Background(), "svc.Service/Method", sdktrace.Drop},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			s := newRouteSampler(AdaptiveSampler(1, 1), nil, c.opts...)
+			res := s.ShouldSample(sdktrace.SamplingParameters{
+				ParentContext: c.ctx,
+				TraceID:       trace.TraceID{1},
+				Name:          c.span,
+			})
+			if res.Decision != c.expected {
+				t.Errorf("got decision %v, want %v", res.Decision, c.expected)
+			}
+		})
+	}
+}
+
+func TestRouteSamplerDescription(t *testing.T) {
+	s := newRouteSampler(sdktrace.AlwaysSample(), nil, AlwaysRoute("/a"), NeverRoute("/b"))
+	expected := "Route{always:[/a],never:[/b],next:ParentBased{root:AlwaysOnSampler,remoteParentSampled:AlwaysOnSampler,remoteParentNotSampled:AlwaysOffSampler,localParentSampled:AlwaysOnSampler,localParentNotSampled:AlwaysOffSampler}}"
+	if s.Description() != expected {
+		t.Errorf("got description %q, want %q", s.Description(), expected)
+	}
+	s = newRouteSampler(sdktrace.AlwaysSample(), nil, RouteRatio(0.5, "/a", "/b"))
+	expected = "Route{always:[],never:[],ratios:[[/a /b]:0.5],next:ParentBased{root:AlwaysOnSampler,remoteParentSampled:AlwaysOnSampler,remoteParentNotSampled:AlwaysOffSampler,localParentSampled:AlwaysOnSampler,localParentNotSampled:AlwaysOffSampler}}"
+	if s.Description() != expected {
+		t.Errorf("got description %q, want %q", s.Description(), expected)
+	}
+	s = newRouteSampler(sdktrace.AlwaysSample(), nil)
+	if _, ok := s.(routeSampler); ok {
+		t.Error("expected parent based sampler when no route is configured")
+	}
+}
+
+func TestRouteSamplerHTTPTarget(t *testing.T) {
+	s := RouteSampler(sdktrace.AlwaysSample(), RouteRatio(0, "/search"))
+	res := s.ShouldSample(sdktrace.SamplingParameters{
+		ParentContext: context.Background(),
+		TraceID:       trace.TraceID{1},
+		Name:          "svc",
+		Attributes:    []attribute.KeyValue{semconv.HTTPTarget("/search?q=clue")},
+	})
+	if res.Decision != sdktrace.Drop {
+		t.Errorf("got decision %v, want %v", res.Decision, sdktrace.Drop)
+	}
+}
+
+func TestAdaptiveRouteSampler(t *testing.T) {
+	var o samplerOptions
+	AdaptiveRoute(1, 2)(&o)
+	s := o.root
+	expected := "AdaptiveRoute{maxSamplingRate:1,sampleSize:2}"
+	if s.Description() != expected {
+		t.Fatalf("got description %q, expected %q", s.Description(), expected)
+	}
+	params := func(route string) sdktrace.SamplingParameters {
+		return sdktrace.SamplingParameters{ParentContext: context.WithValue(context.Background(), routeKey, route)}
+	}
+	if res := s.ShouldSample(params("/a")); res.Decision != sdktrace.RecordAndSample {
+		t.Error("expected sampling of first /a request")
+	}
+	if res := s.ShouldSample(params("/a")); res.Decision != sdktrace.Drop {
+		t.Error("expected no sampling of second /a request")
+	}
+	if res := s.ShouldSample(params("/b")); res.Decision != sdktrace.RecordAndSample {
+		t.Error("expected sampling of first /b request")
+	}
+	if res := s.ShouldSample(sdktrace.SamplingParameters{ParentContext: context.Background(), Name: "svc.Service/Method"}); res.Decision != sdktrace.RecordAndSample {
+		t.Error("expected sampling of first gRPC request")
+	}
+}
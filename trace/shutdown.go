@@ -0,0 +1,73 @@
+package trace
+
+import (
+	"context"
+	"sync"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+var (
+	// providersLock protects providers.
+	providersLock sync.Mutex
+	// providers contains the tracer providers created by Context that
+	// haven't been shut down yet.
+	providers []*sdktrace.TracerProvider
+)
+
+// ForceFlush exports the pending spans of the tracer providers created by
+// Context, including the traces buffered by tail sampling. It returns the first
+// error encountered, the remaining providers are still flushed.
+func ForceFlush(ctx context.Context) error {
+	var firstErr error
+	for _, p := range activeProviders() {
+		if err := p.ForceFlush(ctx); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// Shutdown exports the pending spans of the tracer providers created by
+// Context and shuts them down. Spans created with these providers afterwards
+// are not recorded. Tracer providers given via WithTracerProvider are not shut
+// down, clue.Shutdown shuts down both. Shutdown returns the first error
+// encountered, the remaining providers are still shut down.
+//
+// Shutdown is meant to be called once the servers have stopped accepting
+// requests so that the spans of the last requests are not dropped:
+//
+//	if err := httpsvr.Shutdown(ctx); err != nil {
+//	        log.Error(ctx, err)
+//	}
+//	if err := trace.Shutdown(ctx); err != nil {
+//	        log.Error(ctx, err)
+//	}
+func Shutdown(ctx context.Context) error {
+	providersLock.Lock()
+	ps := providers
+	providers = nil
+	providersLock.Unlock()
+	var firstErr error
+	for _, p := range ps {
+		if err := p.Shutdown(ctx); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// addProvider records a tracer provider created by Context so that it is
+// flushed by ForceFlush and Shutdown.
+func addProvider(p *sdktrace.TracerProvider) {
+	providersLock.Lock()
+	defer providersLock.Unlock()
+	providers = append(providers, p)
+}
+
+// activeProviders returns the tracer providers that haven't been shut down.
+func activeProviders() []*sdktrace.TracerProvider {
+	providersLock.Lock()
+	defer providersLock.Unlock()
+	return append([]*sdktrace.TracerProvider(nil), providers...)
+}
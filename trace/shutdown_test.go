@@ -0,0 +1,43 @@
+package trace
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestShutdown(t *testing.T) {
+	exporter := &shutdownExporter{tracetest.NewInMemoryExporter()}
+	ctx, err := Context(context.Background(), "test", WithExporter(exporter), WithSampler(ParentRatio(1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, span := TraceProvider(ctx).Tracer("test").Start(ctx, "span")
+	span.End()
+
+	if err := ForceFlush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := len(exporter.GetSpans()); got != 1 {
+		t.Fatalf("got %d exported spans after flush, expected 1", got)
+	}
+	_, span = TraceProvider(ctx).Tracer("test").Start(ctx, "span")
+	span.End()
+	if err := Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := len(exporter.GetSpans()); got != 2 {
+		t.Errorf("got %d exported spans after shutdown, expected 2", got)
+	}
+	if got := len(activeProviders()); got != 0 {
+		t.Errorf("got %d active providers after shutdown, expected 0", got)
+	}
+}
+
+// shutdownExporter keeps the exported spans on shutdown.
+type shutdownExporter struct {
+	*tracetest.InMemoryExporter
+}
+
+func (*shutdownExporter) Shutdown(context.Context) error { return nil }
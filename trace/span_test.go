@@ -16,7 +16,7 @@ import (
 func newTestTracingContext() (context.Context, *tracetest.InMemoryExporter) {
 	exporter := tracetest.NewInMemoryExporter()
 	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
-	ctx := withProvider(context.Background(), provider, propagation.TraceContext{}, "test")
+	ctx := withProvider(context.Background(), provider, propagation.TraceContext{}, "test", &Control{})
 	return ctx, exporter
 }
 
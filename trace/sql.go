@@ -0,0 +1,513 @@
+package trace
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type (
+	// SQLOption is a function that configures the database/sql driver
+	// wrappers.
+	SQLOption func(*sqlOptions)
+
+	sqlOptions struct {
+		system       string
+		name         string
+		rawStatement bool
+	}
+
+	// sqlTracer creates the spans for a database.
+	sqlTracer struct {
+		tracer  trace.Tracer
+		options *sqlOptions
+	}
+
+	// sqlDriver wraps a driver.Driver.
+	sqlDriver struct {
+		driver.Driver
+		tracer *sqlTracer
+	}
+
+	// sqlConnector wraps a driver.Connector.
+	sqlConnector struct {
+		connector driver.Connector
+		driver    driver.Driver
+		tracer    *sqlTracer
+	}
+
+	// dsnConnector is a driver.Connector for drivers that do not implement
+	// driver.DriverContext.
+	dsnConnector struct {
+		dsn    string
+		driver driver.Driver
+	}
+
+	// sqlConn wraps a driver.Conn.
+	sqlConn struct {
+		driver.Conn
+		tracer *sqlTracer
+	}
+
+	// sqlStmt wraps a driver.Stmt.
+	sqlStmt struct {
+		driver.Stmt
+		query  string
+		tracer *sqlTracer
+	}
+
+	// sqlRows wraps a driver.Rows and ends the query span when closed.
+	sqlRows struct {
+		driver.Rows
+		span trace.Span
+		rows int
+	}
+)
+
+var (
+	// sqlStringLiteral matches SQL string literals.
+	sqlStringLiteral = regexp.MustCompile(`'(?:[^']|'')*'`)
+	// sqlNumberLiteral matches SQL numeric literals, it does not match
+	// positional placeholders such as "$1".
+	sqlNumberLiteral = regexp.MustCompile(`(^|[^\w$])\d+(?:\.\d+)?\b`)
+)
+
+const (
+	// AttributeDBRowsReturned is the name of the span attribute that
+	// contains the number of rows returned by a query.
+	AttributeDBRowsReturned = "db.rows_returned"
+	// AttributeDBRowsAffected is the name of the span attribute that
+	// contains the number of rows affected by a statement.
+	AttributeDBRowsAffected = "db.rows_affected"
+)
+
+// WithDBSystem returns an option that sets the "db.system" span attribute,
+// e.g. "postgresql" or "mysql". Defaults to "other_sql".
+func WithDBSystem(system string) SQLOption {
+	return func(o *sqlOptions) {
+		o.system = system
+	}
+}
+
+// WithDBName returns an option that sets the "db.name" span attribute.
+func WithDBName(name string) SQLOption {
+	return func(o *sqlOptions) {
+		o.name = name
+	}
+}
+
+// WithRawStatements returns an option that records SQL statements as is in
+// the "db.statement" span attribute. By default string and numeric literals
+// are replaced with "?" so that values do not leak into traces.
+func WithRawStatements() SQLOption {
+	return func(o *sqlOptions) {
+		o.rawStatement = true
+	}
+}
+
+// SQLDriver returns a database/sql driver that wraps d and creates client
+// spans for each query and statement execution. The spans are named after the
+// SQL operation (e.g. "SELECT") and record the sanitized statement, the number
+// of rows returned or affected and errors. Opening new connections is also
+// traced so that connection establishment delays show up in traces. Spans are
+// only created for requests that are traced. SQLDriver panics if the context
+// hasn't been initialized with Context.
+//
+// Example:
+//
+//	sql.Register("traced-postgres", trace.SQLDriver(ctx, &pq.Driver{}, trace.WithDBSystem("postgresql")))
+//	db, err := sql.Open("traced-postgres", dsn)
+func SQLDriver(ctx context.Context, d driver.Driver, opts ...SQLOption) driver.Driver {
+	return &sqlDriver{Driver: d, tracer: newSQLTracer(ctx, opts)}
+}
+
+// SQLConnector returns a database/sql connector that wraps c, see SQLDriver.
+// SQLConnector panics if the context hasn't been initialized with Context.
+//
+// Example:
+//
+//	db := sql.OpenDB(trace.SQLConnector(ctx, connector, trace.WithDBSystem("postgresql")))
+func SQLConnector(ctx context.Context, c driver.Connector, opts ...SQLOption) driver.Connector {
+	return &sqlConnector{connector: c, tracer: newSQLTracer(ctx, opts)}
+}
+
+// newSQLTracer creates the SQL tracer using the provider stored in ctx.
+func newSQLTracer(ctx context.Context, opts []SQLOption) *sqlTracer {
+	s := ctx.Value(stateKey)
+	if s == nil {
+		panic(errContextMissing)
+	}
+	options := &sqlOptions{system: semconv.DBSystemOtherSQL.Value.AsString()}
+	for _, o := range opts {
+		o(options)
+	}
+	return &sqlTracer{
+		tracer:  s.(*stateBag).provider.Tracer(InstrumentationLibraryName),
+		options: options,
+	}
+}
+
+// Open opens a traced connection.
+func (d *sqlDriver) Open(name string) (driver.Conn, error) {
+	conn, err := d.Driver.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return &sqlConn{Conn: conn, tracer: d.tracer}, nil
+}
+
+// OpenConnector returns a traced connector.
+func (d *sqlDriver) OpenConnector(name string) (driver.Connector, error) {
+	if dc, ok := d.Driver.(driver.DriverContext); ok {
+		c, err := dc.OpenConnector(name)
+		if err != nil {
+			return nil, err
+		}
+		return &sqlConnector{connector: c, driver: d, tracer: d.tracer}, nil
+	}
+	return &sqlConnector{connector: &dsnConnector{dsn: name, driver: d.Driver}, driver: d, tracer: d.tracer}, nil
+}
+
+// Connect opens a traced connection and records the time it takes in a span.
+func (c *sqlConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	ctx, span := c.tracer.start(ctx, "sql.connect", "")
+	conn, err := c.connector.Connect(ctx)
+	endSQLSpan(span, err)
+	if err != nil {
+		return nil, err
+	}
+	return &sqlConn{Conn: conn, tracer: c.tracer}, nil
+}
+
+// Driver returns the traced driver.
+func (c *sqlConnector) Driver() driver.Driver {
+	if c.driver != nil {
+		return c.driver
+	}
+	return &sqlDriver{Driver: c.connector.Driver(), tracer: c.tracer}
+}
+
+// Connect opens a connection using the DSN.
+func (c *dsnConnector) Connect(context.Context) (driver.Conn, error) {
+	return c.driver.Open(c.dsn)
+}
+
+// Driver returns the underlying driver.
+func (c *dsnConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+// QueryContext traces the query if the underlying connection implements
+// driver.QueryerContext.
+func (c *sqlConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	q, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	ctx, span := c.tracer.start(ctx, "", query)
+	rows, err := q.QueryContext(ctx, query, args)
+	if err != nil {
+		endSQLSpan(span, err)
+		return nil, err
+	}
+	return newSQLRows(rows, span), nil
+}
+
+// ExecContext traces the statement if the underlying connection implements
+// driver.ExecerContext.
+func (c *sqlConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	e, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	ctx, span := c.tracer.start(ctx, "", query)
+	res, err := e.ExecContext(ctx, query, args)
+	recordRowsAffected(span, res)
+	endSQLSpan(span, err)
+	return res, err
+}
+
+// PrepareContext prepares a traced statement.
+func (c *sqlConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	var stmt driver.Stmt
+	var err error
+	if p, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		stmt, err = p.PrepareContext(ctx, query)
+	} else {
+		stmt, err = c.Conn.Prepare(query)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &sqlStmt{Stmt: stmt, query: query, tracer: c.tracer}, nil
+}
+
+// Prepare prepares a traced statement.
+func (c *sqlConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+// BeginTx starts a transaction.
+func (c *sqlConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if b, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return b.BeginTx(ctx, opts)
+	}
+	if opts.Isolation != 0 || opts.ReadOnly {
+		return nil, errors.New("sql: driver does not support non-default transaction options")
+	}
+	return c.Conn.Begin() // nolint: staticcheck
+}
+
+// Ping pings the database if the underlying connection implements
+// driver.Pinger.
+func (c *sqlConn) Ping(ctx context.Context) error {
+	if p, ok := c.Conn.(driver.Pinger); ok {
+		return p.Ping(ctx)
+	}
+	return nil
+}
+
+// ResetSession resets the session if the underlying connection implements
+// driver.SessionResetter.
+func (c *sqlConn) ResetSession(ctx context.Context) error {
+	if r, ok := c.Conn.(driver.SessionResetter); ok {
+		return r.ResetSession(ctx)
+	}
+	return nil
+}
+
+// IsValid returns false if the underlying connection implements
+// driver.Validator and is not valid.
+func (c *sqlConn) IsValid() bool {
+	if v, ok := c.Conn.(driver.Validator); ok {
+		return v.IsValid()
+	}
+	return true
+}
+
+// CheckNamedValue checks the value using the underlying connection if it
+// implements driver.NamedValueChecker.
+func (c *sqlConn) CheckNamedValue(nv *driver.NamedValue) error {
+	if n, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return n.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+// QueryContext traces the prepared statement query.
+func (s *sqlStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	ctx, span := s.tracer.start(ctx, "", s.query)
+	var rows driver.Rows
+	var err error
+	if q, ok := s.Stmt.(driver.StmtQueryContext); ok {
+		rows, err = q.QueryContext(ctx, args)
+	} else {
+		var vals []driver.Value
+		if vals, err = namedValues(args); err == nil {
+			rows, err = s.Stmt.Query(vals) // nolint: staticcheck
+		}
+	}
+	if err != nil {
+		endSQLSpan(span, err)
+		return nil, err
+	}
+	return newSQLRows(rows, span), nil
+}
+
+// ExecContext traces the prepared statement execution.
+func (s *sqlStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	ctx, span := s.tracer.start(ctx, "", s.query)
+	var res driver.Result
+	var err error
+	if e, ok := s.Stmt.(driver.StmtExecContext); ok {
+		res, err = e.ExecContext(ctx, args)
+	} else {
+		var vals []driver.Value
+		if vals, err = namedValues(args); err == nil {
+			res, err = s.Stmt.Exec(vals) // nolint: staticcheck
+		}
+	}
+	recordRowsAffected(span, res)
+	endSQLSpan(span, err)
+	return res, err
+}
+
+// CheckNamedValue checks the value using the underlying statement if it
+// implements driver.NamedValueChecker.
+func (s *sqlStmt) CheckNamedValue(nv *driver.NamedValue) error {
+	if n, ok := s.Stmt.(driver.NamedValueChecker); ok {
+		return n.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+// newSQLRows wraps rows so that span ends when the rows are closed.
+func newSQLRows(rows driver.Rows, span trace.Span) driver.Rows {
+	if !span.IsRecording() {
+		return rows
+	}
+	return &sqlRows{Rows: rows, span: span}
+}
+
+// Next counts the rows returned.
+func (r *sqlRows) Next(dest []driver.Value) error {
+	err := r.Rows.Next(dest)
+	if err == nil {
+		r.rows++
+	}
+	return err
+}
+
+// Close closes the rows and ends the query span.
+func (r *sqlRows) Close() error {
+	err := r.Rows.Close()
+	r.span.SetAttributes(attribute.Int(AttributeDBRowsReturned, r.rows))
+	endSQLSpan(r.span, err)
+	return err
+}
+
+// HasNextResultSet implements driver.RowsNextResultSet.
+func (r *sqlRows) HasNextResultSet() bool {
+	if n, ok := r.Rows.(driver.RowsNextResultSet); ok {
+		return n.HasNextResultSet()
+	}
+	return false
+}
+
+// NextResultSet implements driver.RowsNextResultSet.
+func (r *sqlRows) NextResultSet() error {
+	if n, ok := r.Rows.(driver.RowsNextResultSet); ok {
+		return n.NextResultSet()
+	}
+	return io.EOF
+}
+
+// ColumnTypeScanType implements driver.RowsColumnTypeScanType.
+func (r *sqlRows) ColumnTypeScanType(index int) reflect.Type {
+	if c, ok := r.Rows.(driver.RowsColumnTypeScanType); ok {
+		return c.ColumnTypeScanType(index)
+	}
+	return reflect.TypeOf(new(interface{})).Elem()
+}
+
+// ColumnTypeDatabaseTypeName implements
+// driver.RowsColumnTypeDatabaseTypeName.
+func (r *sqlRows) ColumnTypeDatabaseTypeName(index int) string {
+	if c, ok := r.Rows.(driver.RowsColumnTypeDatabaseTypeName); ok {
+		return c.ColumnTypeDatabaseTypeName(index)
+	}
+	return ""
+}
+
+// ColumnTypeLength implements driver.RowsColumnTypeLength.
+func (r *sqlRows) ColumnTypeLength(index int) (int64, bool) {
+	if c, ok := r.Rows.(driver.RowsColumnTypeLength); ok {
+		return c.ColumnTypeLength(index)
+	}
+	return 0, false
+}
+
+// ColumnTypeNullable implements driver.RowsColumnTypeNullable.
+func (r *sqlRows) ColumnTypeNullable(index int) (bool, bool) {
+	if c, ok := r.Rows.(driver.RowsColumnTypeNullable); ok {
+		return c.ColumnTypeNullable(index)
+	}
+	return false, false
+}
+
+// ColumnTypePrecisionScale implements driver.RowsColumnTypePrecisionScale.
+func (r *sqlRows) ColumnTypePrecisionScale(index int) (int64, int64, bool) {
+	if c, ok := r.Rows.(driver.RowsColumnTypePrecisionScale); ok {
+		return c.ColumnTypePrecisionScale(index)
+	}
+	return 0, 0, false
+}
+
+// start starts a client span for the given query if the request is traced.
+// The span is named after the SQL operation unless name is not empty.
+func (t *sqlTracer) start(ctx context.Context, name, query string) (context.Context, trace.Span) {
+	if !trace.SpanFromContext(ctx).IsRecording() {
+		return ctx, trace.SpanFromContext(context.Background())
+	}
+	attrs := []attribute.KeyValue{semconv.DBSystemKey.String(t.options.system)}
+	if t.options.name != "" {
+		attrs = append(attrs, semconv.DBName(t.options.name))
+	}
+	if query != "" {
+		op := sqlOperation(query)
+		if name == "" {
+			name = op
+		}
+		if op != "" {
+			attrs = append(attrs, semconv.DBOperation(op))
+		}
+		stmt := query
+		if !t.options.rawStatement {
+			stmt = SanitizeSQL(query)
+		}
+		attrs = append(attrs, semconv.DBStatement(stmt))
+	}
+	if name == "" {
+		name = "sql.query"
+	}
+	return t.tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+}
+
+// SanitizeSQL replaces the string and numeric literals of the given SQL
+// statement with "?".
+func SanitizeSQL(query string) string {
+	query = sqlStringLiteral.ReplaceAllString(query, "?")
+	return sqlNumberLiteral.ReplaceAllString(query, "${1}?")
+}
+
+// sqlOperation returns the SQL operation of the given query in upper case,
+// e.g. "SELECT".
+func sqlOperation(query string) string {
+	fields := strings.Fields(query)
+	if len(fields) == 0 {
+		return ""
+	}
+	return strings.ToUpper(fields[0])
+}
+
+// recordRowsAffected records the number of rows affected by a statement.
+func recordRowsAffected(span trace.Span, res driver.Result) {
+	if res == nil || !span.IsRecording() {
+		return
+	}
+	if n, err := res.RowsAffected(); err == nil {
+		span.SetAttributes(attribute.Int64(AttributeDBRowsAffected, n))
+	}
+}
+
+// endSQLSpan records err if not nil and ends the span. driver.ErrSkip and
+// io.EOF are not recorded as they do not denote failures.
+func endSQLSpan(span trace.Span, err error) {
+	if err != nil && !errors.Is(err, driver.ErrSkip) && !errors.Is(err, io.EOF) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// namedValues converts named values to values, it returns an error if any of
+// the values is named.
+func namedValues(named []driver.NamedValue) ([]driver.Value, error) {
+	vals := make([]driver.Value, len(named))
+	for i, nv := range named {
+		if nv.Name != "" {
+			return nil, errors.New("sql: driver does not support the use of Named Parameters")
+		}
+		vals[i] = nv.Value
+	}
+	return vals, nil
+}
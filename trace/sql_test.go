@@ -0,0 +1,184 @@
+package trace
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"testing"
+
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+)
+
+type (
+	// fakeDriver is a database/sql driver whose queries return two rows
+	// and whose statements affect one row. Queries starting with "FAIL"
+	// return an error.
+	fakeDriver struct{}
+	fakeConn   struct{}
+	fakeStmt   struct{ query string }
+	fakeRows   struct{ n int }
+)
+
+var errFake = errors.New("fake error")
+
+func (fakeDriver) Open(string) (driver.Conn, error)           { return fakeConn{}, nil }
+func (fakeConn) Prepare(q string) (driver.Stmt, error)        { return fakeStmt{q}, nil }
+func (fakeConn) Close() error                                 { return nil }
+func (fakeConn) Begin() (driver.Tx, error)                    { return nil, errFake }
+func (s fakeStmt) Close() error                               { return nil }
+func (s fakeStmt) NumInput() int                              { return -1 }
+func (s fakeStmt) Exec([]driver.Value) (driver.Result, error) { return s.result() }
+func (s fakeStmt) Query([]driver.Value) (driver.Rows, error)  { return s.rows() }
+func (r *fakeRows) Columns() []string                         { return []string{"a"} }
+func (r *fakeRows) Close() error                              { return nil }
+
+func (s fakeStmt) result() (driver.Result, error) {
+	if len(s.query) >= 4 && s.query[:4] == "FAIL" {
+		return nil, errFake
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (s fakeStmt) rows() (driver.Rows, error) {
+	if len(s.query) >= 4 && s.query[:4] == "FAIL" {
+		return nil, errFake
+	}
+	return &fakeRows{}, nil
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.n == 2 {
+		return io.EOF
+	}
+	r.n++
+	dest[0] = int64(r.n)
+	return nil
+}
+
+func TestSQL(t *testing.T) {
+	cases := []struct {
+		name          string
+		opts          []SQLOption
+		query         string
+		exec          bool
+		expectedName  string
+		expectedStmt  string
+		expectedError bool
+	}{
+		{"query", nil, "SELECT a FROM t WHERE b = 'x' AND c = 42", false, "SELECT", "SELECT a FROM t WHERE b = ? AND c = ?", false},
+		{"exec", nil, "update t set a = 1", true, "UPDATE", "update t set a = ?", false},
+		{"raw", []SQLOption{WithRawStatements()}, "SELECT a FROM t WHERE b = 'x'", false, "SELECT", "SELECT a FROM t WHERE b = 'x'", false},
+		{"query error", nil, "FAIL", false, "FAIL", "FAIL", true},
+		{"exec error", nil, "FAIL", true, "FAIL", "FAIL", true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			exporter := tracetest.NewInMemoryExporter()
+			provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+			traceCtx := testContext(provider)
+			opts := append([]SQLOption{WithDBSystem("fake"), WithDBName("db")}, c.opts...)
+			connector, err := SQLDriver(traceCtx, fakeDriver{}, opts...).(driver.DriverContext).OpenConnector("dsn")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			db := sql.OpenDB(connector)
+			defer db.Close()
+
+			ctx, span := provider.Tracer("test").Start(context.Background(), "parent")
+			if c.exec {
+				_, err = db.ExecContext(ctx, c.query)
+			} else {
+				var rows *sql.Rows
+				rows, err = db.QueryContext(ctx, c.query)
+				if err == nil {
+					for rows.Next() {
+					}
+					rows.Close()
+				}
+			}
+			span.End()
+			if c.expectedError && err == nil {
+				t.Error("expected error")
+			}
+			if !c.expectedError && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			spans := exporter.GetSpans()
+			if len(spans) != 3 {
+				t.Fatalf("got %d spans, want 3 (connect, query, parent)", len(spans))
+			}
+			if spans[0].Name != "sql.connect" {
+				t.Errorf("got span name %q, want sql.connect", spans[0].Name)
+			}
+			s := spans[1]
+			if s.Name != c.expectedName {
+				t.Errorf("got span name %q, want %q", s.Name, c.expectedName)
+			}
+			if s.Parent.SpanID() != spans[2].SpanContext.SpanID() {
+				t.Error("query span is not a child of the parent span")
+			}
+			attrs := make(map[string]string)
+			for _, att := range s.Attributes {
+				attrs[string(att.Key)] = att.Value.Emit()
+			}
+			if got := attrs[string(semconv.DBStatementKey)]; got != c.expectedStmt {
+				t.Errorf("got statement %q, want %q", got, c.expectedStmt)
+			}
+			if got := attrs[string(semconv.DBSystemKey)]; got != "fake" {
+				t.Errorf("got system %q, want fake", got)
+			}
+			if got := attrs[string(semconv.DBNameKey)]; got != "db" {
+				t.Errorf("got name %q, want db", got)
+			}
+			if c.expectedError {
+				if s.Status.Code != codes.Error {
+					t.Errorf("got status %v, want error", s.Status.Code)
+				}
+				return
+			}
+			if c.exec {
+				if got := attrs[AttributeDBRowsAffected]; got != "1" {
+					t.Errorf("got rows affected %q, want 1", got)
+				}
+			} else if got := attrs[AttributeDBRowsReturned]; got != "2" {
+				t.Errorf("got rows returned %q, want 2", got)
+			}
+		})
+	}
+}
+
+func TestSQLNotTraced(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	db := sql.OpenDB(SQLConnector(testContext(provider), &dsnConnector{dsn: "dsn", driver: fakeDriver{}}))
+	defer db.Close()
+	if _, err := db.ExecContext(context.Background(), "DELETE FROM t"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := len(exporter.GetSpans()); got != 0 {
+		t.Errorf("got %d spans, want 0", got)
+	}
+}
+
+func TestSanitizeSQL(t *testing.T) {
+	cases := []struct {
+		query    string
+		expected string
+	}{
+		{"SELECT * FROM t", "SELECT * FROM t"},
+		{"SELECT 1", "SELECT ?"},
+		{"SELECT * FROM t WHERE a = 'it''s'", "SELECT * FROM t WHERE a = ?"},
+		{"SELECT * FROM t2 WHERE a = 1.5 AND b = $1", "SELECT * FROM t2 WHERE a = ? AND b = $1"},
+	}
+	for _, c := range cases {
+		if got := SanitizeSQL(c.query); got != c.expected {
+			t.Errorf("SanitizeSQL(%q) = %q, want %q", c.query, got, c.expected)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package trace
+
+import (
+	"net/http"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// SyntheticDetector is a function that returns true if the given request
+// originates from a synthetic source such as a health checker, an uptime probe
+// or a load test.
+type SyntheticDetector func(r *http.Request) bool
+
+// AttributeSynthetic is the name of the span attribute set to true for
+// requests made by synthetic sources.
+const AttributeSynthetic = "synthetic"
+
+var (
+	// SyntheticUserAgents lists the user agent prefixes of well known health
+	// checkers, uptime probes and load testing tools used by
+	// DetectSynthetic. The comparison is case insensitive.
+	SyntheticUserAgents = []string{
+		"kube-probe/",
+		"GoogleHC/",
+		"ELB-HealthChecker/",
+		"Amazon-Route53-Health-Check-Service",
+		"Pingdom.com_bot",
+		"UptimeRobot/",
+		"StatusCake",
+		"Site24x7",
+		"Datadog/Synthetics",
+		"DatadogSynthetics",
+		"NewRelicSynthetics/",
+		"grpc-health-probe/",
+		"k6/",
+		"Gatling/",
+		"Apache-JMeter/",
+		"Locust/",
+	}
+
+	// SyntheticHeaders lists the request headers used by DetectSynthetic to
+	// identify synthetic traffic, for example headers set by load tests.
+	// Requests that have one of these headers with a value other than
+	// "false" or "0" are synthetic.
+	SyntheticHeaders = []string{"X-Synthetic", "X-Load-Test"}
+)
+
+// DetectSynthetic is the default synthetic detector. It returns true if the
+// request user agent starts with one of SyntheticUserAgents or if the request
+// has one of SyntheticHeaders.
+func DetectSynthetic(r *http.Request) bool {
+	if ua := strings.ToLower(r.UserAgent()); ua != "" {
+		for _, prefix := range SyntheticUserAgents {
+			if strings.HasPrefix(ua, strings.ToLower(prefix)) {
+				return true
+			}
+		}
+	}
+	for _, h := range SyntheticHeaders {
+		if v := r.Header.Get(h); v != "" && v != "false" && v != "0" {
+			return true
+		}
+	}
+	return false
+}
+
+// WithSyntheticDetection returns an option that sets the "synthetic" span
+// attribute to true on the spans of requests made by synthetic sources so that
+// SLO calculations can exclude them. The request is synthetic if any of the
+// given detectors returns true. DetectSynthetic is used when no detector is
+// given. The metrics package provides the corresponding label, see
+// metrics.WithSyntheticLabel.
+func WithSyntheticDetection(detectors ...SyntheticDetector) HTTPOption {
+	if len(detectors) == 0 {
+		detectors = []SyntheticDetector{DetectSynthetic}
+	}
+	return func(o *httpOptions) {
+		o.synthetic = append(o.synthetic, detectors...)
+	}
+}
+
+// IsSynthetic returns true if any of the given detectors returns true for r.
+func IsSynthetic(r *http.Request, detectors ...SyntheticDetector) bool {
+	for _, d := range detectors {
+		if d(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// addSyntheticHTTP is a middleware that marks the current span as synthetic
+// when the request is made by a synthetic source.
+func addSyntheticHTTP(h http.Handler, options *httpOptions) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		span := trace.SpanFromContext(req.Context())
+		if span.IsRecording() && IsSynthetic(req, options.synthetic...) {
+			span.SetAttributes(attribute.Bool(AttributeSynthetic, true))
+		}
+		h.ServeHTTP(w, req)
+	})
+}
@@ -0,0 +1,77 @@
+package trace
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestDetectSynthetic(t *testing.T) {
+	cases := []struct {
+		name     string
+		header   http.Header
+		expected bool
+	}{
+		{"none", http.Header{}, false},
+		{"browser", http.Header{"User-Agent": {"Mozilla/5.0"}}, false},
+		{"kubernetes", http.Header{"User-Agent": {"kube-probe/1.27"}}, true},
+		{"google", http.Header{"User-Agent": {"GoogleHC/1.0"}}, true},
+		{"case insensitive", http.Header{"User-Agent": {"elb-healthchecker/2.0"}}, true},
+		{"load test", http.Header{"User-Agent": {"k6/0.45.0 (https://k6.io/)"}}, true},
+		{"header", http.Header{"X-Synthetic": {"true"}}, true},
+		{"load test header", http.Header{"X-Load-Test": {"run-42"}}, true},
+		{"header false", http.Header{"X-Synthetic": {"false"}}, false},
+		{"header zero", http.Header{"X-Synthetic": {"0"}}, false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			req.Header = c.header
+			if got := DetectSynthetic(req); got != c.expected {
+				t.Errorf("got %v, want %v", got, c.expected)
+			}
+		})
+	}
+}
+
+func TestHTTPSyntheticDetection(t *testing.T) {
+	canary := func(r *http.Request) bool { return r.Header.Get("X-Canary") != "" }
+	cases := []struct {
+		name     string
+		opts     []HTTPOption
+		header   http.Header
+		expected bool
+	}{
+		{"disabled", nil, http.Header{"User-Agent": {"kube-probe/1.27"}}, false},
+		{"default", []HTTPOption{WithSyntheticDetection()}, http.Header{"User-Agent": {"kube-probe/1.27"}}, true},
+		{"default not synthetic", []HTTPOption{WithSyntheticDetection()}, http.Header{"User-Agent": {"Mozilla/5.0"}}, false},
+		{"custom", []HTTPOption{WithSyntheticDetection(canary)}, http.Header{"X-Canary": {"1"}}, true},
+		{"custom ignores default", []HTTPOption{WithSyntheticDetection(canary)}, http.Header{"User-Agent": {"kube-probe/1.27"}}, false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			exporter := tracetest.NewInMemoryExporter()
+			provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+			handler := HTTP(testContext(provider), c.opts...)(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+			req := httptest.NewRequest("GET", "/", nil)
+			req.Header = c.header
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+			spans := exporter.GetSpans()
+			if len(spans) != 1 {
+				t.Fatalf("got %d spans, want 1", len(spans))
+			}
+			var got bool
+			for _, attr := range spans[0].Attributes {
+				if attr.Key == AttributeSynthetic {
+					got = attr.Value.AsBool()
+				}
+			}
+			if got != c.expected {
+				t.Errorf("got synthetic %v, want %v", got, c.expected)
+			}
+		})
+	}
+}
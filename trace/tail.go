@@ -0,0 +1,311 @@
+package trace
+
+import (
+	"container/list"
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type (
+	// TailSampler is a span processor that buffers the spans of local
+	// traces until they complete and forwards the traces that are worth
+	// keeping to the next processor. Traces are kept if they contain a
+	// span with an error status or if they last longer than the latency
+	// threshold. A configurable fraction of the other traces is also kept.
+	// The decision made for a trace is remembered for the duration of the
+	// timeout so that the spans that end after the trace was sampled, e.g.
+	// because it timed out or was evicted, follow the same decision.
+	TailSampler struct {
+		next      sdktrace.SpanProcessor
+		options   *tailOptions
+		lock      sync.Mutex
+		traces    map[trace.TraceID]*tailTrace
+		order     *list.List // trace IDs, oldest first
+		decisions map[trace.TraceID]*tailDecision
+		decided   *list.List // decisions, oldest first
+	}
+
+	// TailOption is a function that configures a tail sampler.
+	TailOption func(*tailOptions)
+
+	tailOptions struct {
+		latency   time.Duration
+		ratio     float64
+		timeout   time.Duration
+		maxTraces int
+	}
+
+	// tailTrace records the spans of a local trace.
+	tailTrace struct {
+		created time.Time
+		open    int
+		spans   []sdktrace.ReadOnlySpan
+		elem    *list.Element // element of the trace ID in order
+	}
+
+	// tailDecision records whether the spans of a sampled trace are kept.
+	tailDecision struct {
+		id      trace.TraceID
+		keep    bool
+		decided time.Time
+	}
+)
+
+// Be kind to tests
+var (
+	timeNow     = time.Now
+	randFloat64 = rand.Float64
+)
+
+// NewTailSampler returns a tail sampling span processor that forwards the
+// spans of the traces that are kept to next, typically a batch span processor.
+// The head sampler should sample all the traces that are candidates for tail
+// sampling. Spans that were not sampled by the head sampler are ignored.
+//
+// Example:
+//
+//	tail := trace.NewTailSampler(sdktrace.NewBatchSpanProcessor(exporter),
+//		trace.WithTailLatency(500*time.Millisecond))
+//	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(tail))
+func NewTailSampler(next sdktrace.SpanProcessor, opts ...TailOption) *TailSampler {
+	options := defaultTailOptions()
+	for _, o := range opts {
+		o(options)
+	}
+	return &TailSampler{
+		next:      next,
+		options:   options,
+		traces:    make(map[trace.TraceID]*tailTrace),
+		order:     list.New(),
+		decisions: make(map[trace.TraceID]*tailDecision),
+		decided:   list.New(),
+	}
+}
+
+// WithTailLatency sets the duration above which traces are always kept.
+// Defaults to 1s, 0 disables latency based sampling.
+func WithTailLatency(latency time.Duration) TailOption {
+	return func(o *tailOptions) {
+		o.latency = latency
+	}
+}
+
+// WithTailRatio sets the fraction of the traces that have no error and are
+// faster than the latency threshold that are kept. Defaults to 0.1.
+func WithTailRatio(ratio float64) TailOption {
+	return func(o *tailOptions) {
+		o.ratio = ratio
+	}
+}
+
+// WithTailTimeout sets the maximum duration traces are buffered. Traces that
+// are still incomplete after that duration are sampled using the spans
+// recorded so far. The decision made for a trace is also remembered for that
+// duration. Defaults to 30s.
+func WithTailTimeout(timeout time.Duration) TailOption {
+	return func(o *tailOptions) {
+		o.timeout = timeout
+	}
+}
+
+// WithTailMaxTraces sets the maximum number of traces buffered. The oldest
+// trace is sampled using the spans recorded so far when the limit is reached.
+// It also bounds the number of decisions remembered. Defaults to 10000.
+func WithTailMaxTraces(max int) TailOption {
+	return func(o *tailOptions) {
+		o.maxTraces = max
+	}
+}
+
+// OnStart records the start of a span.
+func (s *TailSampler) OnStart(_ context.Context, span sdktrace.ReadWriteSpan) {
+	sc := span.SpanContext()
+	if !sc.IsSampled() {
+		return
+	}
+	var done [][]sdktrace.ReadOnlySpan
+	s.lock.Lock()
+	if _, ok := s.decisions[sc.TraceID()]; ok {
+		// Trace was already sampled, OnEnd applies the decision.
+		s.lock.Unlock()
+		return
+	}
+	t, ok := s.traces[sc.TraceID()]
+	if !ok {
+		if len(s.traces) >= s.options.maxTraces && s.order.Len() > 0 {
+			done = append(done, s.sample(s.oldest()))
+		}
+		t = &tailTrace{created: timeNow(), elem: s.order.PushBack(sc.TraceID())}
+		s.traces[sc.TraceID()] = t
+	}
+	t.open++
+	s.lock.Unlock()
+	s.forward(done)
+}
+
+// OnEnd buffers the span and samples the trace if it is complete.
+func (s *TailSampler) OnEnd(span sdktrace.ReadOnlySpan) {
+	sc := span.SpanContext()
+	if !sc.IsSampled() {
+		return
+	}
+	var done [][]sdktrace.ReadOnlySpan
+	s.lock.Lock()
+	if t, ok := s.traces[sc.TraceID()]; ok {
+		t.spans = append(t.spans, span)
+		t.open--
+		if t.open <= 0 {
+			done = append(done, s.sample(sc.TraceID()))
+		}
+	} else if d, ok := s.decisions[sc.TraceID()]; ok {
+		// Trace was already sampled, apply the same decision.
+		if d.keep {
+			done = append(done, []sdktrace.ReadOnlySpan{span})
+		}
+	} else {
+		// Decision was forgotten, sample the span on its own.
+		done = append(done, s.decide(sc.TraceID(), []sdktrace.ReadOnlySpan{span}))
+	}
+	done = append(done, s.expire()...)
+	s.lock.Unlock()
+	s.forward(done)
+}
+
+// Shutdown samples the buffered traces and shuts down the next processor.
+func (s *TailSampler) Shutdown(ctx context.Context) error {
+	s.forward(s.flush())
+	return s.next.Shutdown(ctx)
+}
+
+// ForceFlush samples the buffered traces and flushes the next processor.
+func (s *TailSampler) ForceFlush(ctx context.Context) error {
+	s.forward(s.flush())
+	return s.next.ForceFlush(ctx)
+}
+
+// forward sends the spans of the traces that are kept to the next processor.
+func (s *TailSampler) forward(traces [][]sdktrace.ReadOnlySpan) {
+	for _, spans := range traces {
+		for _, span := range spans {
+			s.next.OnEnd(span)
+		}
+	}
+}
+
+// keep returns true if the trace made of the given spans should be kept.
+func (s *TailSampler) keep(spans []sdktrace.ReadOnlySpan) bool {
+	if len(spans) == 0 {
+		return false
+	}
+	start, end := spans[0].StartTime(), spans[0].EndTime()
+	for _, span := range spans {
+		if span.Status().Code == codes.Error {
+			return true
+		}
+		if span.StartTime().Before(start) {
+			start = span.StartTime()
+		}
+		if span.EndTime().After(end) {
+			end = span.EndTime()
+		}
+	}
+	if s.options.latency > 0 && end.Sub(start) >= s.options.latency {
+		return true
+	}
+	return randFloat64() < s.options.ratio
+}
+
+// flush removes all the buffered traces and returns their spans.
+func (s *TailSampler) flush() [][]sdktrace.ReadOnlySpan {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	var done [][]sdktrace.ReadOnlySpan
+	for s.order.Len() > 0 {
+		done = append(done, s.sample(s.oldest()))
+	}
+	return done
+}
+
+// expire samples the traces buffered for longer than the timeout, forgets the
+// decisions older than the timeout and returns the spans of the traces that
+// are kept, s.lock must be held.
+func (s *TailSampler) expire() [][]sdktrace.ReadOnlySpan {
+	var done [][]sdktrace.ReadOnlySpan
+	now := timeNow()
+	for s.order.Len() > 0 {
+		id := s.oldest()
+		if now.Sub(s.traces[id].created) < s.options.timeout {
+			break
+		}
+		done = append(done, s.sample(id))
+	}
+	for s.decided.Len() > 0 {
+		d := s.decided.Front().Value.(*tailDecision)
+		if now.Sub(d.decided) < s.options.timeout {
+			break
+		}
+		s.forget()
+	}
+	return done
+}
+
+// sample removes the trace with the given ID and returns its spans if the
+// trace is kept, s.lock must be held.
+func (s *TailSampler) sample(id trace.TraceID) []sdktrace.ReadOnlySpan {
+	return s.decide(id, s.remove(id))
+}
+
+// decide records whether the trace with the given ID made of the given spans
+// is kept and returns the spans if it is, s.lock must be held.
+func (s *TailSampler) decide(id trace.TraceID, spans []sdktrace.ReadOnlySpan) []sdktrace.ReadOnlySpan {
+	if s.decided.Len() > 0 && s.decided.Len() >= s.options.maxTraces {
+		s.forget()
+	}
+	d := &tailDecision{id: id, keep: s.keep(spans), decided: timeNow()}
+	s.decisions[id] = d
+	s.decided.PushBack(d)
+	if !d.keep {
+		return nil
+	}
+	return spans
+}
+
+// forget forgets the oldest decision, s.lock must be held and at least one
+// decision must be recorded.
+func (s *TailSampler) forget() {
+	d := s.decided.Remove(s.decided.Front()).(*tailDecision)
+	if s.decisions[d.id] == d {
+		delete(s.decisions, d.id)
+	}
+}
+
+// remove removes the trace with the given ID and returns its spans, s.lock
+// must be held.
+func (s *TailSampler) remove(id trace.TraceID) []sdktrace.ReadOnlySpan {
+	t := s.traces[id]
+	delete(s.traces, id)
+	s.order.Remove(t.elem)
+	return t.spans
+}
+
+// oldest returns the ID of the oldest buffered trace, s.lock must be held and
+// at least one trace must be buffered.
+func (s *TailSampler) oldest() trace.TraceID {
+	return s.order.Front().Value.(trace.TraceID)
+}
+
+// defaultTailOptions returns the default tail sampler options.
+func defaultTailOptions() *tailOptions {
+	return &tailOptions{
+		latency:   time.Second,
+		ratio:     0.1,
+		timeout:   30 * time.Second,
+		maxTraces: 10000,
+	}
+}
@@ -0,0 +1,239 @@
+package trace
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTailSampler(t *testing.T) {
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	cases := []struct {
+		name     string
+		opts     []TailOption
+		duration time.Duration
+		err      bool
+		expected int
+	}{
+		{"dropped", []TailOption{WithTailRatio(0)}, time.Millisecond, false, 0},
+		{"error", []TailOption{WithTailRatio(0)}, time.Millisecond, true, 2},
+		{"slow", []TailOption{WithTailRatio(0), WithTailLatency(time.Second)}, 2 * time.Second, false, 2},
+		{"latency disabled", []TailOption{WithTailRatio(0), WithTailLatency(0)}, 2 * time.Second, false, 0},
+		{"ratio", []TailOption{WithTailRatio(1)}, time.Millisecond, false, 2},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			exporter := tracetest.NewInMemoryExporter()
+			tail := NewTailSampler(sdktrace.NewSimpleSpanProcessor(exporter), c.opts...)
+			provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(tail))
+			tracer := provider.Tracer("test")
+
+			ctx, root := tracer.Start(context.Background(), "root", trace.WithTimestamp(start))
+			_, child := tracer.Start(ctx, "child", trace.WithTimestamp(start))
+			if c.err {
+				child.SetStatus(codes.Error, "error")
+			}
+			child.End(trace.WithTimestamp(start.Add(c.duration)))
+			if got := len(exporter.GetSpans()); got != 0 {
+				t.Fatalf("got %d spans before trace completes, want 0", got)
+			}
+			root.End(trace.WithTimestamp(start.Add(c.duration)))
+
+			if got := len(exporter.GetSpans()); got != c.expected {
+				t.Errorf("got %d spans, want %d", got, c.expected)
+			}
+		})
+	}
+}
+
+func TestTailSamplerTimeout(t *testing.T) {
+	now := time.Now()
+	timeNow = func() time.Time { return now }
+	defer func() { timeNow = time.Now }()
+	exporter := tracetest.NewInMemoryExporter()
+	tail := NewTailSampler(sdktrace.NewSimpleSpanProcessor(exporter), WithTailRatio(1), WithTailTimeout(time.Second))
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(tail))
+	tracer := provider.Tracer("test")
+
+	ctx, root := tracer.Start(context.Background(), "root")
+	defer root.End()
+	_, child := tracer.Start(ctx, "child")
+	child.End()
+	if got := len(exporter.GetSpans()); got != 0 {
+		t.Fatalf("got %d spans before timeout, want 0", got)
+	}
+
+	now = now.Add(2 * time.Second)
+	_, other := tracer.Start(context.Background(), "other")
+	other.End()
+	if got := len(exporter.GetSpans()); got != 2 {
+		t.Errorf("got %d spans after timeout, want 2", got)
+	}
+}
+
+func TestTailSamplerMaxTraces(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tail := NewTailSampler(sdktrace.NewSimpleSpanProcessor(exporter), WithTailRatio(1), WithTailMaxTraces(1))
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(tail))
+	tracer := provider.Tracer("test")
+
+	ctx, first := tracer.Start(context.Background(), "first")
+	_, child := tracer.Start(ctx, "child")
+	child.End()
+	_, second := tracer.Start(context.Background(), "second")
+	if got := len(exporter.GetSpans()); got != 1 {
+		t.Fatalf("got %d spans after eviction, want 1", got)
+	}
+	first.End()
+	if got := len(exporter.GetSpans()); got != 2 {
+		t.Errorf("got %d spans after evicted trace ends, want 2", got)
+	}
+	second.End()
+	if got := len(exporter.GetSpans()); got != 3 {
+		t.Errorf("got %d spans, want 3", got)
+	}
+}
+
+func TestTailSamplerLateSpans(t *testing.T) {
+	cases := []struct {
+		name     string
+		evict    bool
+		childErr bool
+		expected int
+	}{
+		{"timeout kept", false, true, 3},
+		{"timeout dropped", false, false, 0},
+		{"evicted kept", true, true, 3},
+		{"evicted dropped", true, false, 0},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			now := time.Now()
+			timeNow = func() time.Time { return now }
+			defer func() { timeNow = time.Now }()
+			exporter := tracetest.NewInMemoryExporter()
+			tail := NewTailSampler(sdktrace.NewSimpleSpanProcessor(exporter),
+				WithTailRatio(0), WithTailTimeout(time.Second), WithTailMaxTraces(2))
+			provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(tail))
+			tracer := provider.Tracer("test")
+
+			ctx, root := tracer.Start(context.Background(), "root")
+			_, child := tracer.Start(ctx, "child")
+			if c.childErr {
+				child.SetStatus(codes.Error, "error")
+			}
+			child.End()
+			if c.evict {
+				_, first := tracer.Start(context.Background(), "first")
+				defer first.End()
+				_, second := tracer.Start(context.Background(), "second")
+				defer second.End()
+			} else {
+				now = now.Add(2 * time.Second)
+				_, other := tracer.Start(context.Background(), "other")
+				other.End()
+			}
+
+			// Late spans follow the decision made for the trace regardless
+			// of their own status.
+			_, late := tracer.Start(ctx, "late")
+			if !c.childErr {
+				late.SetStatus(codes.Error, "error")
+			}
+			late.End()
+			root.End()
+			if got := len(exporter.GetSpans()); got != c.expected {
+				t.Errorf("got %d spans, want %d", got, c.expected)
+			}
+		})
+	}
+
+	t.Run("forgotten", func(t *testing.T) {
+		now := time.Now()
+		timeNow = func() time.Time { return now }
+		defer func() { timeNow = time.Now }()
+		exporter := tracetest.NewInMemoryExporter()
+		tail := NewTailSampler(sdktrace.NewSimpleSpanProcessor(exporter), WithTailRatio(0), WithTailTimeout(time.Second))
+		provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(tail))
+		tracer := provider.Tracer("test")
+
+		ctx, root := tracer.Start(context.Background(), "root")
+		_, child := tracer.Start(ctx, "child")
+		child.End()
+		now = now.Add(2 * time.Second)
+		_, other := tracer.Start(context.Background(), "other")
+		other.End()
+		now = now.Add(2 * time.Second)
+		_, other = tracer.Start(context.Background(), "other")
+		other.End()
+
+		root.SetStatus(codes.Error, "error")
+		root.End()
+		if got := len(exporter.GetSpans()); got != 1 {
+			t.Errorf("got %d spans, want the span of the forgotten trace to be sampled on its own", got)
+		}
+	})
+}
+
+func TestTailSamplerEvictionOrder(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tail := NewTailSampler(sdktrace.NewSimpleSpanProcessor(exporter), WithTailRatio(1), WithTailMaxTraces(3))
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(tail))
+	tracer := provider.Tracer("test")
+	start := func(name string) trace.Span {
+		ctx, root := tracer.Start(context.Background(), name)
+		_, child := tracer.Start(ctx, name+"-child")
+		child.End()
+		return root
+	}
+
+	a := start("a")
+	defer a.End()
+	b := start("b")
+	c := start("c")
+	defer c.End()
+	b.End()
+	if got := len(exporter.GetSpans()); got != 2 {
+		t.Fatalf("got %d spans after b completes, want 2", got)
+	}
+	d := start("d")
+	defer d.End()
+	start("e").End()
+	spans := exporter.GetSpans()
+	if len(spans) != 5 || spans[2].Name != "a-child" {
+		t.Fatalf("got %d spans, want a to be evicted first", len(spans))
+	}
+	g := start("g")
+	defer g.End()
+	start("f").End()
+	spans = exporter.GetSpans()
+	if len(spans) != 8 || spans[5].Name != "c-child" {
+		t.Errorf("got %d spans, want c to be evicted second", len(spans))
+	}
+}
+
+func TestTailSamplerFlush(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tail := NewTailSampler(sdktrace.NewSimpleSpanProcessor(exporter), WithTailRatio(1))
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(tail))
+	tracer := provider.Tracer("test")
+
+	ctx, root := tracer.Start(context.Background(), "root")
+	_, child := tracer.Start(ctx, "child")
+	child.End()
+	if err := provider.ForceFlush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := len(exporter.GetSpans()); got != 1 {
+		t.Errorf("got %d spans after flush, want 1", got)
+	}
+	root.End()
+	if err := provider.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
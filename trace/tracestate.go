@@ -0,0 +1,170 @@
+package trace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// TraceStateKey is the key of the clue vendor entry in the W3C
+	// tracestate header. The entry value holds the fields set with
+	// WithTraceStateEntry, e.g. "clue=priority:high;tenant:acme".
+	TraceStateKey = "clue"
+
+	// MaxTraceStateValueLength is the maximum length of the clue vendor
+	// entry value as specified by the W3C Trace Context specification.
+	MaxTraceStateValueLength = 256
+)
+
+// ErrTraceStateTooLarge is returned when setting a field would make the clue
+// vendor entry value longer than MaxTraceStateValueLength.
+var ErrTraceStateTooLarge = fmt.Errorf("tracestate: %s entry exceeds %d characters", TraceStateKey, MaxTraceStateValueLength)
+
+// TraceStateEntry returns the value of the given field of the clue vendor
+// entry in the tracestate of the span context stored in ctx and true if
+// the field is set, an empty string and false otherwise.
+func TraceStateEntry(ctx context.Context, field string) (string, bool) {
+	return TraceStateValue(trace.SpanContextFromContext(ctx).TraceState(), field)
+}
+
+// WithTraceStateEntry returns a copy of ctx whose span context tracestate has
+// the given field of the clue vendor entry set to value. Spans created with
+// the returned context and requests made with it (see Client) carry the
+// updated tracestate. Note that the span of the returned context is not
+// recording: spans must be created before calling WithTraceStateEntry to be
+// updated with the context. WithTraceStateEntry returns an error if ctx does
+// not contain a valid span context or if the entry would be too large, see
+// InsertTraceStateValue.
+//
+// Example:
+//
+//	ctx, err := trace.WithTraceStateEntry(ctx, "tenant", tenantID)
+//	if err != nil {
+//		log.Error(ctx, err)
+//	}
+func WithTraceStateEntry(ctx context.Context, field, value string) (context.Context, error) {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return ctx, errors.New("tracestate: missing span context")
+	}
+	ts, err := InsertTraceStateValue(sc.TraceState(), field, value)
+	if err != nil {
+		return ctx, err
+	}
+	return trace.ContextWithSpanContext(ctx, sc.WithTraceState(ts)), nil
+}
+
+// TraceStateValue returns the value of the given field of the clue vendor
+// entry in ts and true if the field is set, an empty string and false
+// otherwise.
+func TraceStateValue(ts trace.TraceState, field string) (string, bool) {
+	for _, f := range parseTraceStateFields(ts.Get(TraceStateKey)) {
+		if f[0] == field {
+			return f[1], true
+		}
+	}
+	return "", false
+}
+
+// InsertTraceStateValue returns a copy of ts where the given field of the clue
+// vendor entry is set to value. The field name and value are escaped so they
+// may contain any character. The entry is moved to the beginning of the
+// tracestate as required by the W3C Trace Context specification. It returns
+// ErrTraceStateTooLarge if the resulting entry value would be longer than
+// MaxTraceStateValueLength.
+func InsertTraceStateValue(ts trace.TraceState, field, value string) (trace.TraceState, error) {
+	if field == "" {
+		return ts, errors.New("tracestate: empty field name")
+	}
+	fields := parseTraceStateFields(ts.Get(TraceStateKey))
+	found := false
+	for i, f := range fields {
+		if f[0] == field {
+			fields[i][1] = value
+			found = true
+			break
+		}
+	}
+	if !found {
+		fields = append(fields, [2]string{field, value})
+	}
+	encoded := make([]string, len(fields))
+	for i, f := range fields {
+		encoded[i] = escapeTraceState(f[0]) + ":" + escapeTraceState(f[1])
+	}
+	v := strings.Join(encoded, ";")
+	if len(v) > MaxTraceStateValueLength {
+		return ts, ErrTraceStateTooLarge
+	}
+	return ts.Insert(TraceStateKey, v)
+}
+
+// parseTraceStateFields returns the unescaped name and value of the fields
+// encoded in v. Malformed fields are ignored.
+func parseTraceStateFields(v string) [][2]string {
+	if v == "" {
+		return nil
+	}
+	var fields [][2]string
+	for _, f := range strings.Split(v, ";") {
+		name, val, ok := strings.Cut(f, ":")
+		if !ok {
+			continue
+		}
+		name, err := unescapeTraceState(name)
+		if err != nil || name == "" {
+			continue
+		}
+		val, err = unescapeTraceState(val)
+		if err != nil {
+			continue
+		}
+		fields = append(fields, [2]string{name, val})
+	}
+	return fields
+}
+
+// escapeTraceState percent-encodes the characters of s that are not allowed
+// in tracestate values (see the W3C Trace Context specification) as well as
+// the characters used to separate fields.
+func escapeTraceState(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c <= ' ' || c > '~' || c == ',' || c == '=' || c == ';' || c == ':' || c == '%' {
+			fmt.Fprintf(&b, "%%%02X", c)
+			continue
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+// unescapeTraceState decodes a string encoded with escapeTraceState.
+func unescapeTraceState(s string) (string, error) {
+	if !strings.Contains(s, "%") {
+		return s, nil
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '%' {
+			b.WriteByte(s[i])
+			continue
+		}
+		if i+2 >= len(s) {
+			return "", fmt.Errorf("tracestate: invalid escape sequence in %q", s)
+		}
+		c, err := strconv.ParseUint(s[i+1:i+3], 16, 8)
+		if err != nil {
+			return "", fmt.Errorf("tracestate: invalid escape sequence in %q", s)
+		}
+		b.WriteByte(byte(c))
+		i += 2
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,100 @@
+package trace
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestInsertTraceStateValue(t *testing.T) {
+	other, err := trace.ParseTraceState("vendor=value")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		name     string
+		ts       trace.TraceState
+		fields   [][2]string
+		expected string
+		err      error
+	}{
+		{"empty", trace.TraceState{}, [][2]string{{"priority", "high"}}, "clue=priority:high", nil},
+		{"moved first", other, [][2]string{{"priority", "high"}}, "clue=priority:high,vendor=value", nil},
+		{"multiple", trace.TraceState{}, [][2]string{{"priority", "high"}, {"tenant", "acme"}}, "clue=priority:high;tenant:acme", nil},
+		{"update", trace.TraceState{}, [][2]string{{"priority", "high"}, {"tenant", "acme"}, {"priority", "low"}}, "clue=priority:low;tenant:acme", nil},
+		{"escaped", trace.TraceState{}, [][2]string{{"t:1", "a=b, c;d%"}}, "clue=t%3A1:a%3Db%2C%20c%3Bd%25", nil},
+		{"too large", trace.TraceState{}, [][2]string{{"k", strings.Repeat("a", MaxTraceStateValueLength)}}, "", ErrTraceStateTooLarge},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ts := c.ts
+			var err error
+			for _, f := range c.fields {
+				ts, err = InsertTraceStateValue(ts, f[0], f[1])
+				if err != nil {
+					break
+				}
+			}
+			if !errors.Is(err, c.err) {
+				t.Fatalf("got error %v, want %v", err, c.err)
+			}
+			if c.err != nil {
+				return
+			}
+			if got := ts.String(); got != c.expected {
+				t.Errorf("got tracestate %q, want %q", got, c.expected)
+			}
+			for _, f := range c.fields[len(c.fields)-1:] {
+				if v, ok := TraceStateValue(ts, f[0]); !ok || v != f[1] {
+					t.Errorf("got value %q (%v), want %q", v, ok, f[1])
+				}
+			}
+		})
+	}
+}
+
+func TestTraceStateValue(t *testing.T) {
+	ts, err := trace.ParseTraceState("clue=priority:high;malformed;bad%:x;tenant:a%2Cb,vendor=value")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := TraceStateValue(ts, "priority"); !ok || v != "high" {
+		t.Errorf("got priority %q (%v), want high", v, ok)
+	}
+	if v, ok := TraceStateValue(ts, "tenant"); !ok || v != "a,b" {
+		t.Errorf("got tenant %q (%v), want a,b", v, ok)
+	}
+	if _, ok := TraceStateValue(ts, "missing"); ok {
+		t.Error("expected missing field")
+	}
+	if _, err := InsertTraceStateValue(ts, "", "v"); err == nil {
+		t.Error("expected error for empty field name")
+	}
+}
+
+func TestWithTraceStateEntry(t *testing.T) {
+	if _, err := WithTraceStateEntry(context.Background(), "tenant", "acme"); err == nil {
+		t.Error("expected error without span context")
+	}
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{1},
+		SpanID:     trace.SpanID{1},
+		TraceFlags: trace.FlagsSampled,
+	})
+	ctx := trace.ContextWithSpanContext(context.Background(), sc)
+
+	ctx, err := WithTraceStateEntry(ctx, "tenant", "acme")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := TraceStateEntry(ctx, "tenant"); !ok || v != "acme" {
+		t.Errorf("got tenant %q (%v), want acme", v, ok)
+	}
+	if got := trace.SpanContextFromContext(ctx).TraceID(); got != sc.TraceID() {
+		t.Errorf("got trace ID %v, want %v", got, sc.TraceID())
+	}
+}
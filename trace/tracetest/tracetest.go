@@ -0,0 +1,147 @@
+// Package tracetest provides helpers to test that code creates the expected
+// spans. It records spans in memory and exposes assertions on the span names,
+// parent/child relationships and attributes.
+//
+// Example:
+//
+//	func TestHandler(t *testing.T) {
+//		ctx, rec := tracetest.Context(t, context.Background(), "svc")
+//		handler := trace.HTTP(ctx)(newHandler())
+//		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+//		spans := rec.Spans()
+//		spans.AssertCount(t, 2)
+//		spans.AssertChildOf(t, "db.query", "svc")
+//		spans.AssertAttr(t, "db.query", "db.system", "postgresql")
+//	}
+package tracetest
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"goa.design/clue/trace"
+)
+
+type (
+	// Recorder records the spans created using a context initialized with
+	// Context.
+	Recorder struct {
+		t        testing.TB
+		ctx      context.Context
+		exporter *tracetest.InMemoryExporter
+	}
+
+	// Spans is a list of recorded spans.
+	Spans []tracetest.SpanStub
+)
+
+// Context returns a context initialized with trace.Context that samples all
+// requests and records the spans in memory, and the corresponding recorder.
+// The options are appended to the default options and may override them. It
+// fails the test if the context cannot be initialized.
+func Context(t testing.TB, ctx context.Context, svc string, opts ...trace.TraceOption) (context.Context, *Recorder) {
+	t.Helper()
+	exporter := tracetest.NewInMemoryExporter()
+	opts = append([]trace.TraceOption{
+		trace.WithExporter(exporter),
+		trace.WithSampler(trace.ParentRatio(1)),
+	}, opts...)
+	ctx, err := trace.Context(ctx, svc, opts...)
+	if err != nil {
+		t.Fatalf("failed to initialize tracing context: %v", err)
+	}
+	return ctx, &Recorder{t: t, ctx: ctx, exporter: exporter}
+}
+
+// Spans flushes the pending spans and returns all the spans that ended since
+// the recorder was created or last reset.
+func (r *Recorder) Spans() Spans {
+	r.t.Helper()
+	if p, ok := trace.TraceProvider(r.ctx).(*sdktrace.TracerProvider); ok {
+		if err := p.ForceFlush(r.ctx); err != nil {
+			r.t.Errorf("failed to flush spans: %v", err)
+		}
+	}
+	return Spans(r.exporter.GetSpans())
+}
+
+// Reset discards the recorded spans.
+func (r *Recorder) Reset() {
+	r.exporter.Reset()
+}
+
+// Find returns the first span with the given name, nil if there is none.
+func (s Spans) Find(name string) *tracetest.SpanStub {
+	for i := range s {
+		if s[i].Name == name {
+			return &s[i]
+		}
+	}
+	return nil
+}
+
+// Names returns the names of the spans.
+func (s Spans) Names() []string {
+	names := make([]string, len(s))
+	for i, span := range s {
+		names[i] = span.Name
+	}
+	return names
+}
+
+// AssertCount fails the test if the number of spans is not n.
+func (s Spans) AssertCount(t testing.TB, n int) {
+	t.Helper()
+	if len(s) != n {
+		t.Errorf("got %d spans %v, want %d", len(s), s.Names(), n)
+	}
+}
+
+// AssertExists fails the test if there is no span with the given name.
+func (s Spans) AssertExists(t testing.TB, name string) {
+	t.Helper()
+	if s.Find(name) == nil {
+		t.Errorf("span %q not found in %v", name, s.Names())
+	}
+}
+
+// AssertChildOf fails the test if the span named child is not a direct child
+// of the span named parent.
+func (s Spans) AssertChildOf(t testing.TB, child, parent string) {
+	t.Helper()
+	c, p := s.Find(child), s.Find(parent)
+	if c == nil || p == nil {
+		t.Errorf("span %q or %q not found in %v", child, parent, s.Names())
+		return
+	}
+	if c.Parent.SpanID() != p.SpanContext.SpanID() || c.Parent.TraceID() != p.SpanContext.TraceID() {
+		t.Errorf("span %q is not a child of span %q", child, parent)
+	}
+}
+
+// AssertAttr fails the test if the span with the given name does not have an
+// attribute with the given key and value. Values are compared using their
+// default string representation so that for example int and int64 values
+// match.
+func (s Spans) AssertAttr(t testing.TB, name, key string, value interface{}) {
+	t.Helper()
+	span := s.Find(name)
+	if span == nil {
+		t.Errorf("span %q not found in %v", name, s.Names())
+		return
+	}
+	for _, att := range span.Attributes {
+		if string(att.Key) != key {
+			continue
+		}
+		if got, want := fmt.Sprint(att.Value.AsInterface()), fmt.Sprint(value); got != want {
+			t.Errorf("span %q attribute %q is %s, want %s", name, key, got, want)
+		}
+		return
+	}
+	t.Errorf("span %q has no attribute %q", name, key)
+}
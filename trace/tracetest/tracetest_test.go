@@ -0,0 +1,74 @@
+package tracetest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"goa.design/clue/trace"
+)
+
+// recordingT records the test failures.
+type recordingT struct {
+	testing.TB
+	errors []string
+}
+
+func (t *recordingT) Helper() {}
+
+func (t *recordingT) Errorf(format string, args ...interface{}) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+func TestRecorder(t *testing.T) {
+	ctx, rec := Context(t, context.Background(), "svc")
+	handler := trace.HTTP(ctx)(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		ctx := trace.StartSpan(req.Context(), "child", "key", "value")
+		trace.EndSpan(ctx)
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+
+	spans := rec.Spans()
+	spans.AssertCount(t, 2)
+	spans.AssertExists(t, "svc")
+	spans.AssertChildOf(t, "child", "svc")
+	spans.AssertAttr(t, "child", "key", "value")
+	spans.AssertAttr(t, "svc", "http.status_code", 200)
+
+	rec.Reset()
+	rec.Spans().AssertCount(t, 0)
+}
+
+func TestAssertionFailures(t *testing.T) {
+	ctx, rec := Context(t, context.Background(), "svc")
+	handler := trace.HTTP(ctx)(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		ctx := trace.StartSpan(req.Context(), "child", "key", "value")
+		trace.EndSpan(ctx)
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	spans := rec.Spans()
+
+	cases := []struct {
+		name   string
+		assert func(testing.TB)
+	}{
+		{"count", func(t testing.TB) { spans.AssertCount(t, 1) }},
+		{"exists", func(t testing.TB) { spans.AssertExists(t, "missing") }},
+		{"child of missing", func(t testing.TB) { spans.AssertChildOf(t, "child", "missing") }},
+		{"not child of", func(t testing.TB) { spans.AssertChildOf(t, "svc", "child") }},
+		{"attr missing span", func(t testing.TB) { spans.AssertAttr(t, "missing", "key", "value") }},
+		{"attr missing key", func(t testing.TB) { spans.AssertAttr(t, "child", "other", "value") }},
+		{"attr value", func(t testing.TB) { spans.AssertAttr(t, "child", "key", "other") }},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			rt := &recordingT{TB: t}
+			c.assert(rt)
+			if len(rt.errors) != 1 {
+				t.Errorf("got %d errors, want 1", len(rt.errors))
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package trace
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gorilla/websocket"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	goahttp "goa.design/goa/v3/http"
+)
+
+type (
+	// webSocketUpgrader is a Goa websocket upgrader that traces the
+	// handshake.
+	webSocketUpgrader struct {
+		upgrader goahttp.Upgrader
+	}
+)
+
+const (
+	// AttributeWebSocketDirection is the name of the span attribute that
+	// contains the direction of a websocket message ("send" or "receive").
+	AttributeWebSocketDirection = "websocket.direction"
+	// AttributeWebSocketSubprotocol is the name of the span attribute that
+	// contains the websocket subprotocol negotiated during the handshake.
+	AttributeWebSocketSubprotocol = "websocket.subprotocol"
+)
+
+// WebSocketUpgrader returns a Goa websocket upgrader that wraps u and records
+// the upgrade handshake in a "websocket.upgrade" span, child of the request
+// span. The request must be traced by the HTTP middleware for the span to be
+// created.
+//
+// Example:
+//
+//	upgrader := trace.WebSocketUpgrader(&websocket.Upgrader{})
+//	server := genserver.New(endpoints, mux, dec, enc, eh, nil, upgrader, nil)
+func WebSocketUpgrader(u goahttp.Upgrader) goahttp.Upgrader {
+	return &webSocketUpgrader{upgrader: u}
+}
+
+// Upgrade upgrades the HTTP connection to the websocket protocol.
+func (u *webSocketUpgrader) Upgrade(w http.ResponseWriter, r *http.Request, responseHeader http.Header) (*websocket.Conn, error) {
+	tracer := stateTracer(r.Context())
+	if tracer == nil {
+		return u.upgrader.Upgrade(w, r, responseHeader)
+	}
+	_, span := tracer.Start(r.Context(), "websocket.upgrade")
+	defer span.End()
+	conn, err := u.upgrader.Upgrade(w, r, responseHeader)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	if p := conn.Subprotocol(); p != "" {
+		span.SetAttributes(attribute.String(AttributeWebSocketSubprotocol, p))
+	}
+	return conn, nil
+}
+
+// WebSocketSend calls send and records it in a "websocket send" span, child of
+// the request span. send typically calls the Send method of a Goa server
+// stream. The span records the given attributes and the error returned by
+// send if any. keyvals must be a list of alternating keys and values.
+// WebSocketSend simply calls send if the request is not traced. Unlike
+// StartSpan it is safe to call concurrently with WebSocketReceive.
+//
+// Example:
+//
+//	err := trace.WebSocketSend(ctx, func() error { return stream.Send(res) }, "event", res.Kind)
+func WebSocketSend(ctx context.Context, send func() error, keyvals ...string) error {
+	return webSocketMessage(ctx, "send", send, keyvals)
+}
+
+// WebSocketReceive calls recv and records it in a "websocket receive" span,
+// see WebSocketSend.
+//
+// Example:
+//
+//	var req *genservice.Request
+//	err := trace.WebSocketReceive(ctx, func() (err error) { req, err = stream.Recv(); return })
+func WebSocketReceive(ctx context.Context, recv func() error, keyvals ...string) error {
+	return webSocketMessage(ctx, "receive", recv, keyvals)
+}
+
+// webSocketMessage calls fn and records it in a span.
+func webSocketMessage(ctx context.Context, direction string, fn func() error, keyvals []string) error {
+	tracer := stateTracer(ctx)
+	if tracer == nil {
+		return fn()
+	}
+	attrs := append(toKeyVal(keyvals), attribute.String(AttributeWebSocketDirection, direction))
+	_, span := tracer.Start(ctx, "websocket "+direction, trace.WithAttributes(attrs...))
+	defer span.End()
+	err := fn()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return err
+}
+
+// stateTracer returns the tracer of the tracing state in ctx if the request is
+// traced, nil otherwise.
+func stateTracer(ctx context.Context) trace.Tracer {
+	s := ctx.Value(stateKey)
+	if s == nil {
+		return nil
+	}
+	return s.(*stateBag).tracer
+}
@@ -0,0 +1,171 @@
+package trace
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestWebSocketUpgrader(t *testing.T) {
+	cases := []struct {
+		name           string
+		upgrade        bool
+		expectedStatus codes.Code
+		expectedProto  string
+	}{
+		{"success", true, codes.Unset, "chat"},
+		{"error", false, codes.Error, ""},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			exporter := tracetest.NewInMemoryExporter()
+			provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+			traceCtx := testContext(provider)
+			upgrader := WebSocketUpgrader(&websocket.Upgrader{Subprotocols: []string{"chat"}})
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				ctx, span := provider.Tracer("test").Start(r.Context(), "request")
+				defer span.End()
+				conn, err := upgrader.Upgrade(w, r.WithContext(withTracing(traceCtx, ctx)), nil)
+				if err == nil {
+					_ = conn.Close()
+				}
+			})
+			svr := httptest.NewServer(handler)
+			defer svr.Close()
+
+			if c.upgrade {
+				dialer := websocket.Dialer{Subprotocols: []string{"chat"}}
+				conn, _, err := dialer.Dial("ws"+strings.TrimPrefix(svr.URL, "http"), nil)
+				if err != nil {
+					t.Fatal(err)
+				}
+				_ = conn.Close()
+			} else {
+				resp, err := http.Get(svr.URL)
+				if err != nil {
+					t.Fatal(err)
+				}
+				_ = resp.Body.Close()
+			}
+			svr.Close()
+
+			spans := exporter.GetSpans()
+			if len(spans) != 2 {
+				t.Fatalf("got %d spans, want 2", len(spans))
+			}
+			span := spans[0]
+			if span.Name != "websocket.upgrade" {
+				t.Errorf("got span name %q, want websocket.upgrade", span.Name)
+			}
+			if span.Parent.SpanID() != spans[1].SpanContext.SpanID() {
+				t.Errorf("upgrade span is not a child of the request span")
+			}
+			if span.Status.Code != c.expectedStatus {
+				t.Errorf("got status %v, want %v", span.Status.Code, c.expectedStatus)
+			}
+			var proto string
+			for _, attr := range span.Attributes {
+				if attr.Key == AttributeWebSocketSubprotocol {
+					proto = attr.Value.AsString()
+				}
+			}
+			if proto != c.expectedProto {
+				t.Errorf("got subprotocol %q, want %q", proto, c.expectedProto)
+			}
+		})
+	}
+}
+
+func TestWebSocketMessage(t *testing.T) {
+	cases := []struct {
+		name         string
+		fn           func(ctx context.Context, f func() error, keyvals ...string) error
+		err          error
+		expectedName string
+		expectedDir  string
+	}{
+		{"send", WebSocketSend, nil, "websocket send", "send"},
+		{"receive", WebSocketReceive, nil, "websocket receive", "receive"},
+		{"error", WebSocketSend, errors.New("boom"), "websocket send", "send"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			exporter := tracetest.NewInMemoryExporter()
+			provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+			ctx, span := provider.Tracer("test").Start(testContext(provider), "request")
+			ctx = withTracing(testContext(provider), ctx)
+
+			var called bool
+			err := c.fn(ctx, func() error { called = true; return c.err }, "key", "val")
+			span.End()
+
+			if !called {
+				t.Error("function not called")
+			}
+			if err != c.err {
+				t.Errorf("got error %v, want %v", err, c.err)
+			}
+			spans := exporter.GetSpans()
+			if len(spans) != 2 {
+				t.Fatalf("got %d spans, want 2", len(spans))
+			}
+			msg := spans[0]
+			if msg.Name != c.expectedName {
+				t.Errorf("got span name %q, want %q", msg.Name, c.expectedName)
+			}
+			if msg.Parent.SpanID() != spans[1].SpanContext.SpanID() {
+				t.Errorf("message span is not a child of the request span")
+			}
+			attrs := make(map[string]string)
+			for _, attr := range msg.Attributes {
+				attrs[string(attr.Key)] = attr.Value.AsString()
+			}
+			if attrs[AttributeWebSocketDirection] != c.expectedDir {
+				t.Errorf("got direction %q, want %q", attrs[AttributeWebSocketDirection], c.expectedDir)
+			}
+			if attrs["key"] != "val" {
+				t.Errorf("got attribute %q, want val", attrs["key"])
+			}
+			if c.err != nil && msg.Status.Code != codes.Error {
+				t.Errorf("got status %v, want error", msg.Status.Code)
+			}
+		})
+	}
+}
+
+func TestWebSocketMessageConcurrent(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	ctx, span := provider.Tracer("test").Start(testContext(provider), "request")
+	ctx = withTracing(testContext(provider), ctx)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() { defer wg.Done(); _ = WebSocketSend(ctx, func() error { return nil }) }()
+		go func() { defer wg.Done(); _ = WebSocketReceive(ctx, func() error { return nil }) }()
+	}
+	wg.Wait()
+	span.End()
+	if got := len(exporter.GetSpans()); got != 21 {
+		t.Errorf("got %d spans, want 21", got)
+	}
+}
+
+func TestWebSocketMessageNotTraced(t *testing.T) {
+	var called bool
+	if err := WebSocketSend(context.Background(), func() error { called = true; return nil }); err != nil {
+		t.Fatal(err)
+	}
+	if !called {
+		t.Error("function not called")
+	}
+}
@@ -0,0 +1,142 @@
+package trace
+
+import (
+	"context"
+	crand "crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"math/rand"
+	"strings"
+	"sync"
+
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type (
+	// XRayPropagator propagates trace context using the AWS X-Ray
+	// "X-Amzn-Trace-Id" header format.
+	XRayPropagator struct{}
+
+	// xrayIDGenerator generates X-Ray compatible trace IDs: the first 4
+	// bytes contain the Unix epoch time in seconds.
+	xrayIDGenerator struct {
+		lock sync.Mutex
+		rand *rand.Rand
+	}
+)
+
+const (
+	// XRayHeader is the name of the AWS X-Ray trace header.
+	XRayHeader = "X-Amzn-Trace-Id"
+
+	xrayRootKey    = "Root"
+	xrayParentKey  = "Parent"
+	xraySampledKey = "Sampled"
+	xrayVersion    = "1"
+)
+
+var _ propagation.TextMapPropagator = XRayPropagator{}
+
+// NewXRayIDGenerator returns an ID generator that creates trace IDs compatible
+// with AWS X-Ray.
+func NewXRayIDGenerator() sdktrace.IDGenerator {
+	var seed int64
+	_ = binary.Read(crand.Reader, binary.LittleEndian, &seed)
+	return &xrayIDGenerator{rand: rand.New(rand.NewSource(seed))}
+}
+
+// Inject sets the X-Ray trace header in carrier using the span context in ctx.
+func (XRayPropagator) Inject(ctx context.Context, carrier propagation.TextMapCarrier) {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.TraceID().IsValid() || !sc.SpanID().IsValid() {
+		return
+	}
+	tid := sc.TraceID().String()
+	sampled := "0"
+	if sc.IsSampled() {
+		sampled = "1"
+	}
+	carrier.Set(XRayHeader, xrayRootKey+"="+xrayVersion+"-"+tid[:8]+"-"+tid[8:]+
+		";"+xrayParentKey+"="+sc.SpanID().String()+
+		";"+xraySampledKey+"="+sampled)
+}
+
+// Extract returns a copy of ctx containing the remote span context read from
+// the X-Ray trace header in carrier if valid, ctx otherwise.
+func (XRayPropagator) Extract(ctx context.Context, carrier propagation.TextMapCarrier) context.Context {
+	sc, ok := parseXRayHeader(carrier.Get(XRayHeader))
+	if !ok {
+		return ctx
+	}
+	return trace.ContextWithRemoteSpanContext(ctx, sc)
+}
+
+// Fields returns the name of the X-Ray trace header.
+func (XRayPropagator) Fields() []string {
+	return []string{XRayHeader}
+}
+
+// NewIDs returns a new X-Ray compatible trace ID and span ID.
+func (g *xrayIDGenerator) NewIDs(ctx context.Context) (trace.TraceID, trace.SpanID) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	var tid trace.TraceID
+	binary.BigEndian.PutUint32(tid[:4], uint32(timeNow().Unix()))
+	g.rand.Read(tid[4:])
+	var sid trace.SpanID
+	g.rand.Read(sid[:])
+	return tid, sid
+}
+
+// NewSpanID returns a new span ID.
+func (g *xrayIDGenerator) NewSpanID(ctx context.Context, traceID trace.TraceID) trace.SpanID {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	var sid trace.SpanID
+	g.rand.Read(sid[:])
+	return sid
+}
+
+// parseXRayHeader parses the value of an X-Ray trace header, e.g.
+// "Root=1-5759e988-bd862e3fe1be46a994272793;Parent=53995c3f42cd8ad8;Sampled=1".
+func parseXRayHeader(header string) (trace.SpanContext, bool) {
+	if header == "" {
+		return trace.SpanContext{}, false
+	}
+	var cfg trace.SpanContextConfig
+	for _, part := range strings.Split(header, ";") {
+		key, val, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case xrayRootKey:
+			elems := strings.Split(val, "-")
+			if len(elems) != 3 || elems[0] != xrayVersion || len(elems[1]) != 8 || len(elems[2]) != 24 {
+				return trace.SpanContext{}, false
+			}
+			b, err := hex.DecodeString(elems[1] + elems[2])
+			if err != nil {
+				return trace.SpanContext{}, false
+			}
+			copy(cfg.TraceID[:], b)
+		case xrayParentKey:
+			if len(val) != 16 {
+				return trace.SpanContext{}, false
+			}
+			b, err := hex.DecodeString(val)
+			if err != nil {
+				return trace.SpanContext{}, false
+			}
+			copy(cfg.SpanID[:], b)
+		case xraySampledKey:
+			if val == "1" {
+				cfg.TraceFlags = trace.FlagsSampled
+			}
+		}
+	}
+	sc := trace.NewSpanContext(cfg)
+	return sc, sc.IsValid()
+}
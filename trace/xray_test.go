@@ -0,0 +1,110 @@
+package trace
+
+import (
+	"context"
+	"encoding/binary"
+	"net/http"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestXRayPropagator(t *testing.T) {
+	cases := []struct {
+		name    string
+		header  string
+		valid   bool
+		sampled bool
+	}{
+		{"sampled", "Root=1-5759e988-bd862e3fe1be46a994272793;Parent=53995c3f42cd8ad8;Sampled=1", true, true},
+		{"not sampled", "Root=1-5759e988-bd862e3fe1be46a994272793;Parent=53995c3f42cd8ad8;Sampled=0", true, false},
+		{"deferred", "Root=1-5759e988-bd862e3fe1be46a994272793;Parent=53995c3f42cd8ad8;Sampled=?", true, false},
+		{"extra fields", "Self=1-67891234-12456789abcdef012345678;Root=1-5759e988-bd862e3fe1be46a994272793;Parent=53995c3f42cd8ad8;Sampled=1", true, true},
+		{"empty", "", false, false},
+		{"no parent", "Root=1-5759e988-bd862e3fe1be46a994272793;Sampled=1", false, false},
+		{"invalid version", "Root=2-5759e988-bd862e3fe1be46a994272793;Parent=53995c3f42cd8ad8;Sampled=1", false, false},
+		{"invalid trace ID", "Root=1-5759e988-zz862e3fe1be46a994272793;Parent=53995c3f42cd8ad8;Sampled=1", false, false},
+		{"invalid span ID", "Root=1-5759e988-bd862e3fe1be46a994272793;Parent=53995c3f42cd8ad;Sampled=1", false, false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			p := XRayPropagator{}
+			header := http.Header{}
+			header.Set(XRayHeader, c.header)
+			sc := trace.SpanContextFromContext(p.Extract(context.Background(), propagation.HeaderCarrier(header)))
+			if sc.IsValid() != c.valid {
+				t.Fatalf("got valid %v, want %v", sc.IsValid(), c.valid)
+			}
+			if !c.valid {
+				return
+			}
+			if sc.TraceID().String() != "5759e988bd862e3fe1be46a994272793" {
+				t.Errorf("got trace ID %s", sc.TraceID())
+			}
+			if sc.SpanID().String() != "53995c3f42cd8ad8" {
+				t.Errorf("got span ID %s", sc.SpanID())
+			}
+			if sc.IsSampled() != c.sampled {
+				t.Errorf("got sampled %v, want %v", sc.IsSampled(), c.sampled)
+			}
+			if !sc.IsRemote() {
+				t.Error("expected remote span context")
+			}
+
+			injected := http.Header{}
+			p.Inject(trace.ContextWithSpanContext(context.Background(), sc), propagation.HeaderCarrier(injected))
+			expected := "Root=1-5759e988-bd862e3fe1be46a994272793;Parent=53995c3f42cd8ad8;Sampled=0"
+			if c.sampled {
+				expected = "Root=1-5759e988-bd862e3fe1be46a994272793;Parent=53995c3f42cd8ad8;Sampled=1"
+			}
+			if got := injected.Get(XRayHeader); got != expected {
+				t.Errorf("got injected header %q, want %q", got, expected)
+			}
+		})
+	}
+}
+
+func TestXRayPropagatorInjectInvalid(t *testing.T) {
+	header := http.Header{}
+	XRayPropagator{}.Inject(context.Background(), propagation.HeaderCarrier(header))
+	if len(header) != 0 {
+		t.Errorf("got headers %v, want none", header)
+	}
+}
+
+func TestXRayIDGenerator(t *testing.T) {
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	timeNow = func() time.Time { return now }
+	defer func() { timeNow = time.Now }()
+	g := NewXRayIDGenerator()
+	tid, sid := g.NewIDs(context.Background())
+	if !tid.IsValid() || !sid.IsValid() {
+		t.Fatalf("got invalid IDs %s %s", tid, sid)
+	}
+	if epoch := binary.BigEndian.Uint32(tid[:4]); int64(epoch) != now.Unix() {
+		t.Errorf("got epoch %d, want %d", epoch, now.Unix())
+	}
+	if sid2 := g.NewSpanID(context.Background(), tid); !sid2.IsValid() || sid2 == sid {
+		t.Errorf("got span ID %s, want valid and different from %s", sid2, sid)
+	}
+}
+
+func TestWithXRay(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	ctx, err := Context(context.Background(), "test", WithExporter(exporter), WithXRay())
+	if err != nil {
+		t.Fatal(err)
+	}
+	state := ctx.Value(stateKey).(*stateBag)
+	header := http.Header{}
+	header.Set(XRayHeader, "Root=1-5759e988-bd862e3fe1be46a994272793;Parent=53995c3f42cd8ad8;Sampled=1")
+	ctx = state.propagator.Extract(ctx, propagation.HeaderCarrier(header))
+	_, span := state.provider.Tracer("test").Start(ctx, "test")
+	defer span.End()
+	if got := span.SpanContext().TraceID().String(); got != "5759e988bd862e3fe1be46a994272793" {
+		t.Errorf("got trace ID %s, want X-Ray trace ID", got)
+	}
+}